@@ -32,6 +32,8 @@ import (
 	"vitess.io/vitess/go/vt/logutil"
 	"vitess.io/vitess/go/vt/servenv"
 	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/vtadvisor"
+	"vitess.io/vitess/go/vt/vtexplain"
 
 	// Include deprecation warnings for soon-to-be-unsupported flag invocations.
 	_flag "vitess.io/vitess/go/internal/flag"
@@ -49,8 +51,30 @@ var (
 	}
 	bindIndex = 0
 	queries   = make(map[string]int)
+
+	schemaFlag             string
+	schemaFileFlag         string
+	vschemaFlag            string
+	vschemaFileFlag        string
+	ksShardMapFlag         string
+	ksShardMapFileFlag     string
+	numShards              = 2
+	outputMode             = "text"
+	maxReferenceWriteRatio = vtadvisor.DefaultMaxReferenceWriteRatio
 )
 
+func registerFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&schemaFlag, "schema", schemaFlag, "The SQL table schema. Required to plan queries against a VSchema")
+	fs.StringVar(&schemaFileFlag, "schema-file", schemaFileFlag, "Identifies the file that contains the SQL table schema")
+	fs.StringVar(&vschemaFlag, "vschema", vschemaFlag, "Identifies the VTGate routing schema. When set, the queries are planned against it to report scatter queries and recommend vindexes and reference tables")
+	fs.StringVar(&vschemaFileFlag, "vschema-file", vschemaFileFlag, "Identifies the VTGate routing schema file")
+	fs.StringVar(&ksShardMapFlag, "ks-shard-map", ksShardMapFlag, "JSON map of keyspace name -> shard name -> ShardReference object. The inner map is the same as the output of FindAllShardsInKeyspace")
+	fs.StringVar(&ksShardMapFileFlag, "ks-shard-map-file", ksShardMapFileFlag, "File containing json blob of keyspace name -> shard name -> ShardReference object")
+	fs.IntVar(&numShards, "shards", numShards, "Number of shards per keyspace. Passing --ks-shard-map/--ks-shard-map-file causes this flag to be ignored.")
+	fs.StringVar(&outputMode, "output-mode", outputMode, "Output the recommendations in human-friendly text or json")
+	fs.Float64Var(&maxReferenceWriteRatio, "max-reference-write-ratio", maxReferenceWriteRatio, "Highest ratio of writes among the queries that use a table for it to be recommended as a reference table")
+}
+
 type stat struct {
 	Query string
 	Count int
@@ -69,8 +93,18 @@ func main() {
 	logutil.RegisterFlags(fs)
 	acl.RegisterFlags(fs)
 	servenv.RegisterMySQLServerFlags(fs)
+	registerFlags(fs)
 	_flag.Parse(fs)
 	logutil.PurgeLogs()
+
+	if vschemaFlag != "" || vschemaFileFlag != "" {
+		if err := advise(_flag.Args()); err != nil {
+			log.Errorf("advise error: %v", err)
+			exit.Return(1)
+		}
+		return
+	}
+
 	for _, filename := range _flag.Args() {
 		fmt.Printf("processing: %s\n", filename)
 		if err := processFile(filename); err != nil {
@@ -88,6 +122,61 @@ func main() {
 	}
 }
 
+// advise plans the queries of the given query logs against the vschema and
+// prints the recommendations of the workload advisor
+func advise(filenames []string) error {
+	schema, err := vtexplain.GetFileParam(schemaFlag, schemaFileFlag, "schema", true)
+	if err != nil {
+		return err
+	}
+	vschema, err := vtexplain.GetFileParam(vschemaFlag, vschemaFileFlag, "vschema", true)
+	if err != nil {
+		return err
+	}
+	ksShardMap, err := vtexplain.GetFileParam(ksShardMapFlag, ksShardMapFileFlag, "ks-shard-map", false)
+	if err != nil {
+		return err
+	}
+
+	vte, err := vtexplain.Init(vschema, schema, ksShardMap, &vtexplain.Options{
+		ExecutionMode:   vtexplain.ModeMulti,
+		ReplicationMode: "ROW",
+		NumShards:       numShards,
+		Normalize:       true,
+	})
+	if err != nil {
+		return err
+	}
+	defer vte.Stop()
+
+	advisor, err := vtadvisor.New(vte, schema, &vtadvisor.Options{MaxReferenceWriteRatio: maxReferenceWriteRatio})
+	if err != nil {
+		return err
+	}
+	for _, filename := range filenames {
+		f, err := os.Open(filename)
+		if err != nil {
+			return err
+		}
+		err = advisor.ReadLog(f)
+		f.Close()
+		if err != nil {
+			return fmt.Errorf("%s: %v", filename, err)
+		}
+	}
+
+	report, err := advisor.Analyze()
+	if err != nil {
+		return err
+	}
+	if outputMode == "text" {
+		fmt.Print(vtadvisor.ReportAsText(report))
+	} else {
+		fmt.Print(vtadvisor.ReportAsJSON(report))
+	}
+	return nil
+}
+
 func processFile(filename string) error {
 	f, err := os.Open(filename)
 	if err != nil {
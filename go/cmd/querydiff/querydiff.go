@@ -0,0 +1,193 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// querydiff runs a corpus of queries against a reference database, usually
+// a plain MySQL database or another version of Vitess, and against a Vitess
+// cluster, and writes every query whose results don't match, simplified to a
+// minimal reproducer, as a planner test case.
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/pflag"
+
+	"vitess.io/vitess/go/acl"
+	"vitess.io/vitess/go/exit"
+	"vitess.io/vitess/go/json2"
+	"vitess.io/vitess/go/mysql"
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/logutil"
+	"vitess.io/vitess/go/vt/querydiff"
+	"vitess.io/vitess/go/vt/servenv"
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/vtgate/vindexes"
+
+	vschemapb "vitess.io/vitess/go/vt/proto/vschema"
+
+	// Include deprecation warnings for soon-to-be-unsupported flag invocations.
+	_flag "vitess.io/vitess/go/internal/flag"
+)
+
+var (
+	referenceParams      mysql.ConnParams
+	testParams           mysql.ConnParams
+	keyspace             string
+	vschemaFile          string
+	outputFile           string
+	maxRows              = 10000
+	compareErrorMessages bool
+	simplify             = true
+	allowDML             bool
+)
+
+func registerConnFlags(fs *pflag.FlagSet, params *mysql.ConnParams, name, description string) {
+	fs.StringVar(&params.Host, name+"-host", params.Host, fmt.Sprintf("Host of the %s", description))
+	fs.IntVar(&params.Port, name+"-port", params.Port, fmt.Sprintf("Port of the %s", description))
+	fs.StringVar(&params.UnixSocket, name+"-socket", params.UnixSocket, fmt.Sprintf("Unix socket of the %s", description))
+	fs.StringVar(&params.Uname, name+"-user", params.Uname, fmt.Sprintf("Username to connect to the %s", description))
+	fs.StringVar(&params.Pass, name+"-password", params.Pass, fmt.Sprintf("Password to connect to the %s", description))
+	fs.StringVar(&params.DbName, name+"-db", params.DbName, fmt.Sprintf("Database of the %s to run the queries in", description))
+}
+
+func registerFlags(fs *pflag.FlagSet) {
+	registerConnFlags(fs, &referenceParams, "reference", "reference database, e.g. MySQL")
+	registerConnFlags(fs, &testParams, "test", "tested database, e.g. VTGate")
+	fs.StringVar(&keyspace, "keyspace", keyspace, "Default keyspace of the queries. Defaults to --test-db")
+	fs.StringVar(&vschemaFile, "vschema-file", vschemaFile, "Identifies the VTGate routing schema file, used to resolve the tables of the queries while they're simplified")
+	fs.StringVar(&outputFile, "output", outputFile, "File to write the planner test cases of the mismatching queries to. Defaults to stdout")
+	fs.IntVar(&maxRows, "max-rows", maxRows, "Maximum number of rows a query can return")
+	fs.BoolVar(&compareErrorMessages, "compare-error-messages", compareErrorMessages, "Report queries that fail on both sides with different errors. Only useful when both sides are Vitess")
+	fs.BoolVar(&simplify, "simplify", simplify, "Simplify the mismatching queries to minimal reproducers")
+	fs.BoolVar(&allowDML, "allow-dml", allowDML, "Also compare INSERT, UPDATE and DELETE statements, running each of them in a transaction that is rolled back. Otherwise they are skipped")
+}
+
+func main() {
+	defer exit.Recover()
+	fs := pflag.NewFlagSet("querydiff", pflag.ExitOnError)
+	log.RegisterFlags(fs)
+	logutil.RegisterFlags(fs)
+	acl.RegisterFlags(fs)
+	servenv.RegisterMySQLServerFlags(fs)
+	registerFlags(fs)
+	_flag.Parse(fs)
+	logutil.PurgeLogs()
+
+	if err := run(_flag.Args()); err != nil {
+		log.Errorf("querydiff error: %v", err)
+		exit.Return(1)
+	}
+}
+
+func run(corpusFiles []string) error {
+	if len(corpusFiles) == 0 {
+		return fmt.Errorf("no query corpus given")
+	}
+
+	opts := &querydiff.Options{
+		Keyspace:             keyspace,
+		MaxRows:              maxRows,
+		CompareErrorMessages: compareErrorMessages,
+		Simplify:             simplify,
+		AllowDML:             allowDML,
+	}
+	if opts.Keyspace == "" {
+		opts.Keyspace = testParams.DbName
+	}
+	if vschemaFile != "" {
+		vschema, err := loadVSchema(vschemaFile)
+		if err != nil {
+			return err
+		}
+		opts.VSchema = vschema
+	}
+
+	ctx := context.Background()
+	reference, err := mysql.Connect(ctx, &referenceParams)
+	if err != nil {
+		return fmt.Errorf("cannot connect to the reference database: %v", err)
+	}
+	defer reference.Close()
+	test, err := mysql.Connect(ctx, &testParams)
+	if err != nil {
+		return fmt.Errorf("cannot connect to the tested database: %v", err)
+	}
+	defer test.Close()
+
+	differ := querydiff.NewDiffer(reference, test, opts)
+	var mismatches []*querydiff.Mismatch
+	for _, filename := range corpusFiles {
+		queries, err := readCorpus(filename)
+		if err != nil {
+			return err
+		}
+		for _, query := range queries {
+			mismatch, err := differ.Compare(query)
+			if err != nil {
+				log.Warningf("skipping %s: %v", query, err)
+				continue
+			}
+			if mismatch != nil {
+				log.Infof("mismatch: %s: %s", mismatch.Simplified, mismatch.Reason)
+				mismatches = append(mismatches, mismatch)
+			}
+		}
+	}
+	fmt.Fprintf(os.Stderr, "%d mismatching queries\n", len(mismatches))
+
+	var w io.Writer = os.Stdout
+	if outputFile != "" {
+		f, err := os.Create(outputFile)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+	return querydiff.WritePlanTests(w, differ.PlanTests(mismatches))
+}
+
+// readCorpus returns the semicolon-delimited queries of a file
+func readCorpus(filename string) ([]string, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	return sqlparser.SplitStatementToPieces(string(data))
+}
+
+func loadVSchema(filename string) (*vindexes.VSchema, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	// We have to use proto's custom json loader so it can
+	// handle string->enum conversion correctly.
+	var srvVSchema vschemapb.SrvVSchema
+	if err := json2.Unmarshal([]byte(fmt.Sprintf(`{"keyspaces": %s}`, data)), &srvVSchema); err != nil {
+		return nil, err
+	}
+	vschema := vindexes.BuildVSchema(&srvVSchema)
+	for ks, ksSchema := range vschema.Keyspaces {
+		if ksSchema.Error != nil {
+			return nil, fmt.Errorf("vschema failed to load on keyspace [%s]: %v", ks, ksSchema.Error)
+		}
+	}
+	return vschema, nil
+}
@@ -70,7 +70,7 @@ If no replica-type tablet can be found, the backup can be taken on the primary i
 	}
 	// RestoreFromBackup makes a RestoreFromBackup gRPC call to a vtctld.
 	RestoreFromBackup = &cobra.Command{
-		Use:                   "RestoreFromBackup [--backup-timestamp|-t <YYYY-mm-DD.HHMMSS>] <tablet_alias>",
+		Use:                   "RestoreFromBackup [--backup-timestamp|-t <YYYY-mm-DD.HHMMSS>] [--restore-to-pos <pos>|--restore-to-timestamp <RFC3339 time>] [--dry-run] <tablet_alias>",
 		Short:                 "Stops mysqld on the specified tablet and restores the data from either the latest backup or closest before `backup-timestamp`.",
 		DisableFlagsInUseLine: true,
 		Args:                  cobra.ExactArgs(1),
@@ -210,7 +210,10 @@ func commandRemoveBackup(cmd *cobra.Command, args []string) error {
 }
 
 var restoreFromBackupOptions = struct {
-	BackupTimestamp string
+	BackupTimestamp    string
+	RestoreToPos       string
+	RestoreToTimestamp string
+	DryRun             bool
 }{}
 
 func commandRestoreFromBackup(cmd *cobra.Command, args []string) error {
@@ -219,8 +222,14 @@ func commandRestoreFromBackup(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	if restoreFromBackupOptions.RestoreToPos != "" && restoreFromBackupOptions.RestoreToTimestamp != "" {
+		return fmt.Errorf("--restore-to-pos and --restore-to-timestamp are mutually exclusive")
+	}
+
 	req := &vtctldatapb.RestoreFromBackupRequest{
-		TabletAlias: alias,
+		TabletAlias:  alias,
+		RestoreToPos: restoreFromBackupOptions.RestoreToPos,
+		DryRun:       restoreFromBackupOptions.DryRun,
 	}
 
 	if restoreFromBackupOptions.BackupTimestamp != "" {
@@ -232,6 +241,15 @@ func commandRestoreFromBackup(cmd *cobra.Command, args []string) error {
 		req.BackupTime = protoutil.TimeToProto(t)
 	}
 
+	if restoreFromBackupOptions.RestoreToTimestamp != "" {
+		t, err := time.Parse(time.RFC3339, restoreFromBackupOptions.RestoreToTimestamp)
+		if err != nil {
+			return err
+		}
+
+		req.RestoreToTimestamp = protoutil.TimeToProto(t)
+	}
+
 	cli.FinishedParsing(cmd)
 
 	stream, err := client.RestoreFromBackup(commandCtx, req)
@@ -268,5 +286,8 @@ func init() {
 	Root.AddCommand(RemoveBackup)
 
 	RestoreFromBackup.Flags().StringVarP(&restoreFromBackupOptions.BackupTimestamp, "backup-timestamp", "t", "", "Use the backup taken at, or closest before, this timestamp. Omit to use the latest backup. Timestamp format is \"YYYY-mm-DD.HHMMSS\".")
+	RestoreFromBackup.Flags().StringVar(&restoreFromBackupOptions.RestoreToPos, "restore-to-pos", "", "Run a point in time recovery that ends with the given position. This will attempt to use one full backup followed by zero or more incremental backups.")
+	RestoreFromBackup.Flags().StringVar(&restoreFromBackupOptions.RestoreToTimestamp, "restore-to-timestamp", "", "Run a point in time recovery that ends with the given timestamp, in RFC 3339 format (e.g. \"2006-01-02T15:04:05Z\"). This will attempt to use one full backup followed by zero or more incremental backups.")
+	RestoreFromBackup.Flags().BoolVar(&restoreFromBackupOptions.DryRun, "dry-run", false, "Only validate restore steps, do not actually restore data.")
 	Root.AddCommand(RestoreFromBackup)
 }
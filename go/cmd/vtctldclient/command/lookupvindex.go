@@ -0,0 +1,103 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package command
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"vitess.io/vitess/go/cmd/vtctldclient/cli"
+	"vitess.io/vitess/go/json2"
+
+	vschemapb "vitess.io/vitess/go/vt/proto/vschema"
+	vtctldatapb "vitess.io/vitess/go/vt/proto/vtctldata"
+)
+
+var (
+	// LookupVindex is the parent command for the LookupVindex subcommands.
+	LookupVindex = &cobra.Command{
+		Use:                   "LookupVindex [command] [command-flags]",
+		Short:                 "Perform commands related to creating and backfilling lookup vindexes.",
+		DisableFlagsInUseLine: true,
+		Aliases:               []string{"lookupvindex"},
+	}
+
+	// LookupVindexCreate makes a LookupVindexCreate gRPC call to a vtctld.
+	LookupVindexCreate = &cobra.Command{
+		Use:                   "create",
+		Short:                 "Create a lookup vindex, and a VReplication workflow that backfills its backing table.",
+		Example:               `vtctldclient --server localhost:15999 lookupvindex create --keyspace customer --vindex-spec '{"sharded": true, "vindexes": {"corder_lookup": {"type": "consistent_lookup_unique", "params": {"table": "customer.corder_lookup", "from": "sku", "to": "keyspace_id"}, "owner": "corder"}}, "tables": {"corder": {"column_vindexes": [{"column": "sku", "name": "corder_lookup"}]}}}'`,
+		DisableFlagsInUseLine: true,
+		Aliases:               []string{"Create"},
+		Args:                  cobra.NoArgs,
+		RunE:                  commandLookupVindexCreate,
+	}
+)
+
+var lookupVindexCreateOptions = struct {
+	Keyspace                   string
+	VindexSpec                 string
+	ContinueAfterCopyWithOwner bool
+}{}
+
+func commandLookupVindexCreate(cmd *cobra.Command, args []string) error {
+	tabletTypes, err := parseTabletTypes(createOptions.TabletTypes)
+	if err != nil {
+		return err
+	}
+	vindex := &vschemapb.Keyspace{}
+	if err := json2.Unmarshal([]byte(lookupVindexCreateOptions.VindexSpec), vindex); err != nil {
+		return fmt.Errorf("invalid --vindex-spec value: %w", err)
+	}
+
+	cli.FinishedParsing(cmd)
+
+	resp, err := client.LookupVindexCreate(commandCtx, &vtctldatapb.LookupVindexCreateRequest{
+		Keyspace:                     lookupVindexCreateOptions.Keyspace,
+		Vindex:                       vindex,
+		Cells:                        createOptions.Cells,
+		TabletTypes:                  tabletTypes,
+		TabletTypesInPreferenceOrder: createOptions.TabletTypesInPreferenceOrder,
+		ContinueAfterCopyWithOwner:   lookupVindexCreateOptions.ContinueAfterCopyWithOwner,
+	})
+	if err != nil {
+		return err
+	}
+
+	return printJSON(resp)
+}
+
+func init() {
+	Root.AddCommand(LookupVindex)
+
+	LookupVindexCreate.Flags().StringVar(&lookupVindexCreateOptions.Keyspace, "keyspace", "", "Keyspace of the table the lookup vindex is created for (required)")
+	LookupVindexCreate.MarkFlagRequired("keyspace")
+	LookupVindexCreate.Flags().StringVar(&lookupVindexCreateOptions.VindexSpec, "vindex-spec", "", "A JSON vschema spec of the lookup vindex, its owner table and its backing table (required)")
+	LookupVindexCreate.MarkFlagRequired("vindex-spec")
+	LookupVindexCreate.Flags().StringSliceVarP(&createOptions.Cells, "cells", "c", nil, "Cell(s) or CellAlias(es) (comma-separated) to replicate from")
+	LookupVindexCreate.Flags().StringSliceVar(&createOptions.TabletTypes, "tablet-types", nil, "Source tablet types to replicate from (e.g. PRIMARY,REPLICA,RDONLY). Defaults to the --vreplication_tablet_type value of the tablets")
+	LookupVindexCreate.Flags().BoolVar(&createOptions.TabletTypesInPreferenceOrder, "tablet-types-in-preference-order", true, "When performing source tablet selection, look for candidates in the type order as they are listed in the --tablet-types flag")
+	LookupVindexCreate.Flags().BoolVar(&lookupVindexCreateOptions.ContinueAfterCopyWithOwner, "continue-after-copy-with-owner", false, "Vindex will continue materialization after the backfill completes when an owner is provided")
+	LookupVindex.AddCommand(LookupVindexCreate)
+
+	// The workflow of a lookup vindex is named after its backing table, with
+	// a "_vdx" suffix, in the keyspace of the backing table.
+	show := newShowCommand()
+	show.Example = `vtctldclient --server localhost:15999 lookupvindex show --workflow corder_lookup_vdx --target-keyspace customer`
+	addBaseFlags(show)
+	LookupVindex.AddCommand(show)
+}
@@ -0,0 +1,123 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package command
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"vitess.io/vitess/go/cmd/vtctldclient/cli"
+	"vitess.io/vitess/go/json2"
+	"vitess.io/vitess/go/vt/topo/topoproto"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vtctldatapb "vitess.io/vitess/go/vt/proto/vtctldata"
+)
+
+var (
+	// Materialize is the parent command for the Materialize subcommands.
+	Materialize = &cobra.Command{
+		Use:                   "Materialize --workflow <workflow> --target-keyspace <keyspace> [command] [command-flags]",
+		Short:                 "Perform commands related to materializing query results from a source keyspace into tables in a target keyspace.",
+		DisableFlagsInUseLine: true,
+		Aliases:               []string{"materialize"},
+	}
+
+	// MaterializeCreate makes a MaterializeCreate gRPC call to a vtctld.
+	MaterializeCreate = &cobra.Command{
+		Use:                   "create",
+		Short:                 "Create and run a Materialize VReplication workflow.",
+		Example:               `vtctldclient --server localhost:15999 materialize --workflow product_sales --target-keyspace commerce create --source-keyspace commerce --table-settings '[{"target_table": "sales_by_sku", "create_ddl": "create table sales_by_sku (sku varbinary(128) not null primary key, orders bigint, revenue bigint)", "source_expression": "select sku, count(*) as orders, sum(price) as revenue from corder group by sku"}]'`,
+		DisableFlagsInUseLine: true,
+		Aliases:               []string{"Create"},
+		Args:                  cobra.NoArgs,
+		RunE:                  commandMaterializeCreate,
+	}
+)
+
+var materializeCreateOptions = struct {
+	SourceKeyspace string
+	TableSettings  string
+}{}
+
+func commandMaterializeCreate(cmd *cobra.Command, args []string) error {
+	tabletTypes, onDDL, err := validateCreateOptions()
+	if err != nil {
+		return err
+	}
+
+	settings := &vtctldatapb.MaterializeSettings{}
+	tableSettings := fmt.Sprintf(`{"table_settings": %s}`, materializeCreateOptions.TableSettings)
+	if err := json2.Unmarshal([]byte(tableSettings), settings); err != nil {
+		return fmt.Errorf("invalid --table-settings value: %w", err)
+	}
+	if len(settings.TableSettings) == 0 {
+		return fmt.Errorf("no tables to materialize in --table-settings")
+	}
+
+	cli.FinishedParsing(cmd)
+
+	settings.Workflow = baseOptions.Workflow
+	settings.SourceKeyspace = materializeCreateOptions.SourceKeyspace
+	settings.TargetKeyspace = baseOptions.TargetKeyspace
+	settings.Cell = strings.Join(createOptions.Cells, ",")
+	settings.TabletTypes = tabletTypesString(tabletTypes, createOptions.TabletTypesInPreferenceOrder)
+	settings.OnDdl = onDDL.String()
+	settings.StopAfterCopy = createOptions.StopAfterCopy
+	settings.DeferSecondaryKeys = createOptions.DeferSecondaryKeys
+
+	resp, err := client.MaterializeCreate(commandCtx, &vtctldatapb.MaterializeCreateRequest{
+		Settings: settings,
+	})
+	if err != nil {
+		return err
+	}
+
+	return printJSON(resp)
+}
+
+// tabletTypesString returns the tablet types in the format of the
+// tablet_types option of a workflow.
+func tabletTypesString(tabletTypes []topodatapb.TabletType, inOrder bool) string {
+	if len(tabletTypes) == 0 {
+		return ""
+	}
+	names := make([]string, 0, len(tabletTypes))
+	for _, tabletType := range tabletTypes {
+		names = append(names, topoproto.TabletTypeLString(tabletType))
+	}
+	s := strings.Join(names, ",")
+	if inOrder {
+		s = "in_order:" + s
+	}
+	return s
+}
+
+func init() {
+	addBaseFlags(Materialize)
+	Root.AddCommand(Materialize)
+
+	addCreateFlags(MaterializeCreate)
+	MaterializeCreate.Flags().StringVar(&materializeCreateOptions.SourceKeyspace, "source-keyspace", "", "Keyspace where the tables queried in the 'source_expression' values within table-settings live (required)")
+	MaterializeCreate.MarkFlagRequired("source-keyspace")
+	MaterializeCreate.Flags().StringVar(&materializeCreateOptions.TableSettings, "table-settings", "", "A JSON array defining what tables to materialize using what select statements (required)")
+	MaterializeCreate.MarkFlagRequired("table-settings")
+	Materialize.AddCommand(MaterializeCreate)
+
+	Materialize.AddCommand(newShowCommand())
+}
@@ -0,0 +1,115 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package command
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"vitess.io/vitess/go/cmd/vtctldclient/cli"
+
+	vtctldatapb "vitess.io/vitess/go/vt/proto/vtctldata"
+)
+
+var (
+	// Migrate is the parent command for the Migrate subcommands.
+	Migrate = &cobra.Command{
+		Use:                   "Migrate --workflow <workflow> --target-keyspace <keyspace> [command] [command-flags]",
+		Short:                 "Perform commands related to importing the tables of a keyspace of an external cluster.",
+		DisableFlagsInUseLine: true,
+		Aliases:               []string{"migrate"},
+	}
+
+	// MigrateCreate makes a MigrateCreate gRPC call to a vtctld.
+	MigrateCreate = &cobra.Command{
+		Use:                   "create",
+		Short:                 "Create and optionally run a Migrate VReplication workflow.",
+		Example:               `vtctldclient --server localhost:15999 migrate --workflow import --target-keyspace customer create --source-keyspace commerce --mount-name ext1 --all-tables`,
+		DisableFlagsInUseLine: true,
+		Aliases:               []string{"Create"},
+		Args:                  cobra.NoArgs,
+		RunE:                  commandMigrateCreate,
+	}
+)
+
+var migrateCreateOptions = struct {
+	SourceKeyspace  string
+	MountName       string
+	AllTables       bool
+	IncludeTables   []string
+	ExcludeTables   []string
+	SourceTimeZone  string
+	DropForeignKeys bool
+}{}
+
+func commandMigrateCreate(cmd *cobra.Command, args []string) error {
+	tabletTypes, onDDL, err := validateCreateOptions()
+	if err != nil {
+		return err
+	}
+	if !migrateCreateOptions.AllTables && len(migrateCreateOptions.IncludeTables) == 0 {
+		return fmt.Errorf("either --all-tables or --tables must be specified")
+	}
+
+	cli.FinishedParsing(cmd)
+
+	resp, err := client.MigrateCreate(commandCtx, &vtctldatapb.MigrateCreateRequest{
+		Workflow:                     baseOptions.Workflow,
+		SourceKeyspace:               migrateCreateOptions.SourceKeyspace,
+		TargetKeyspace:               baseOptions.TargetKeyspace,
+		MountName:                    migrateCreateOptions.MountName,
+		Cells:                        createOptions.Cells,
+		TabletTypes:                  tabletTypes,
+		TabletTypesInPreferenceOrder: createOptions.TabletTypesInPreferenceOrder,
+		AllTables:                    migrateCreateOptions.AllTables,
+		IncludeTables:                migrateCreateOptions.IncludeTables,
+		ExcludeTables:                migrateCreateOptions.ExcludeTables,
+		SourceTimeZone:               migrateCreateOptions.SourceTimeZone,
+		OnDdl:                        onDDL,
+		StopAfterCopy:                createOptions.StopAfterCopy,
+		DropForeignKeys:              migrateCreateOptions.DropForeignKeys,
+		DeferSecondaryKeys:           createOptions.DeferSecondaryKeys,
+		AutoStart:                    createOptions.AutoStart,
+	})
+	if err != nil {
+		return err
+	}
+
+	return printJSON(resp)
+}
+
+func init() {
+	addBaseFlags(Migrate)
+	Root.AddCommand(Migrate)
+
+	addCreateFlags(MigrateCreate)
+	MigrateCreate.Flags().StringVar(&migrateCreateOptions.SourceKeyspace, "source-keyspace", "", "Keyspace of the external cluster where the tables are being imported from (required)")
+	MigrateCreate.MarkFlagRequired("source-keyspace")
+	MigrateCreate.Flags().StringVar(&migrateCreateOptions.MountName, "mount-name", "", "Name the external cluster was mounted with (required)")
+	MigrateCreate.MarkFlagRequired("mount-name")
+	MigrateCreate.Flags().BoolVar(&migrateCreateOptions.AllTables, "all-tables", false, "Import all tables from the source")
+	MigrateCreate.Flags().StringSliceVar(&migrateCreateOptions.IncludeTables, "tables", nil, "Source tables to import")
+	MigrateCreate.Flags().StringSliceVar(&migrateCreateOptions.ExcludeTables, "exclude-tables", nil, "Source tables to exclude from importing")
+	MigrateCreate.Flags().StringVar(&migrateCreateOptions.SourceTimeZone, "source-time-zone", "", "Specifying this causes any DATETIME fields to be converted from the given time zone into UTC")
+	MigrateCreate.Flags().BoolVar(&migrateCreateOptions.DropForeignKeys, "drop-foreign-keys", false, "If true, tables in the target keyspace will be created without foreign keys")
+	Migrate.AddCommand(MigrateCreate)
+
+	Migrate.AddCommand(newShowCommand())
+	Migrate.AddCommand(newStatusCommand())
+	Migrate.AddCommand(newCompleteCommand(false))
+	Migrate.AddCommand(newCancelCommand())
+}
@@ -0,0 +1,126 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"vitess.io/vitess/go/cmd/vtctldclient/cli"
+
+	vtctldatapb "vitess.io/vitess/go/vt/proto/vtctldata"
+)
+
+var (
+	// MoveTables is the parent command for the MoveTables subcommands.
+	MoveTables = &cobra.Command{
+		Use:                   "MoveTables --workflow <workflow> --target-keyspace <keyspace> [command] [command-flags]",
+		Short:                 "Perform commands related to moving tables from a source keyspace to a target keyspace.",
+		DisableFlagsInUseLine: true,
+		Aliases:               []string{"movetables"},
+	}
+
+	// MoveTablesCreate makes a MoveTablesCreate gRPC call to a vtctld.
+	MoveTablesCreate = &cobra.Command{
+		Use:                   "create",
+		Short:                 "Create and optionally run a MoveTables VReplication workflow.",
+		Example:               `vtctldclient --server localhost:15999 movetables --workflow commerce2customer --target-keyspace customer create --source-keyspace commerce --cells zone1 --cells zone2 --tablet-types replica`,
+		DisableFlagsInUseLine: true,
+		Aliases:               []string{"Create"},
+		Args:                  cobra.NoArgs,
+		RunE:                  commandMoveTablesCreate,
+	}
+)
+
+var moveTablesCreateOptions = struct {
+	SourceKeyspace  string
+	SourceShards    []string
+	AllTables       bool
+	IncludeTables   []string
+	ExcludeTables   []string
+	SourceTimeZone  string
+	DropForeignKeys bool
+	TenantColumn    string
+	TenantValue     string
+}{}
+
+func commandMoveTablesCreate(cmd *cobra.Command, args []string) error {
+	tabletTypes, onDDL, err := validateCreateOptions()
+	if err != nil {
+		return err
+	}
+	if !moveTablesCreateOptions.AllTables && len(moveTablesCreateOptions.IncludeTables) == 0 {
+		return fmt.Errorf("either --all-tables or --tables must be specified")
+	}
+	if (moveTablesCreateOptions.TenantColumn == "") != (moveTablesCreateOptions.TenantValue == "") {
+		return fmt.Errorf("--tenant-column and --tenant-value must be specified together")
+	}
+
+	cli.FinishedParsing(cmd)
+
+	resp, err := client.MoveTablesCreate(commandCtx, &vtctldatapb.MoveTablesCreateRequest{
+		Workflow:                     baseOptions.Workflow,
+		SourceKeyspace:               moveTablesCreateOptions.SourceKeyspace,
+		TargetKeyspace:               baseOptions.TargetKeyspace,
+		Cells:                        createOptions.Cells,
+		TabletTypes:                  tabletTypes,
+		TabletTypesInPreferenceOrder: createOptions.TabletTypesInPreferenceOrder,
+		SourceShards:                 moveTablesCreateOptions.SourceShards,
+		TenantColumn:                 moveTablesCreateOptions.TenantColumn,
+		TenantValue:                  moveTablesCreateOptions.TenantValue,
+		AllTables:                    moveTablesCreateOptions.AllTables,
+		IncludeTables:                moveTablesCreateOptions.IncludeTables,
+		ExcludeTables:                moveTablesCreateOptions.ExcludeTables,
+		SourceTimeZone:               moveTablesCreateOptions.SourceTimeZone,
+		OnDdl:                        onDDL,
+		StopAfterCopy:                createOptions.StopAfterCopy,
+		DropForeignKeys:              moveTablesCreateOptions.DropForeignKeys,
+		DeferSecondaryKeys:           createOptions.DeferSecondaryKeys,
+		AutoStart:                    createOptions.AutoStart,
+	})
+	if err != nil {
+		return err
+	}
+
+	return printJSON(resp)
+}
+
+func init() {
+	addBaseFlags(MoveTables)
+	Root.AddCommand(MoveTables)
+
+	addCreateFlags(MoveTablesCreate)
+	MoveTablesCreate.Flags().StringVar(&moveTablesCreateOptions.SourceKeyspace, "source-keyspace", "", "Keyspace where the tables are being moved from (required)")
+	MoveTablesCreate.MarkFlagRequired("source-keyspace")
+	MoveTablesCreate.Flags().StringSliceVar(&moveTablesCreateOptions.SourceShards, "source-shards", nil, "Source shards to copy data from when performing a partial MoveTables (experimental)")
+	MoveTablesCreate.Flags().BoolVar(&moveTablesCreateOptions.AllTables, "all-tables", false, "Copy all tables from the source")
+	MoveTablesCreate.Flags().StringSliceVar(&moveTablesCreateOptions.IncludeTables, "tables", nil, "Source tables to copy")
+	MoveTablesCreate.Flags().StringSliceVar(&moveTablesCreateOptions.ExcludeTables, "exclude-tables", nil, "Source tables to exclude from copying")
+	MoveTablesCreate.Flags().StringVar(&moveTablesCreateOptions.SourceTimeZone, "source-time-zone", "", "Specifying this causes any DATETIME fields to be converted from the given time zone into UTC")
+	MoveTablesCreate.Flags().BoolVar(&moveTablesCreateOptions.DropForeignKeys, "drop-foreign-keys", false, "If true, tables in the target keyspace will be created without foreign keys")
+	MoveTablesCreate.Flags().StringVar(&moveTablesCreateOptions.TenantColumn, "tenant-column", "", "Column identifying the tenant in every table; only the rows of the tenant given by --tenant-value are moved. While tenants of the source keyspace are switched or switching, vtgate rejects the UPDATEs and DELETEs without a <tenant-column> = <value> predicate and the INSERTs without the column, and filters the rows of the switched tenants out of the SELECTs without such a predicate")
+	MoveTablesCreate.Flags().StringVar(&moveTablesCreateOptions.TenantValue, "tenant-value", "", "Value of --tenant-column for the tenant being moved")
+	MoveTables.AddCommand(MoveTablesCreate)
+
+	MoveTables.AddCommand(newShowCommand())
+	MoveTables.AddCommand(newStatusCommand())
+	MoveTables.AddCommand(newSwitchTrafficCommand(false))
+	MoveTables.AddCommand(newSwitchTrafficCommand(true))
+	MoveTables.AddCommand(newCompleteCommand(true))
+	MoveTables.AddCommand(newCancelCommand())
+}
@@ -0,0 +1,224 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package command
+
+import (
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"vitess.io/vitess/go/cmd/vtctldclient/cli"
+	"vitess.io/vitess/go/protoutil"
+
+	vtctldatapb "vitess.io/vitess/go/vt/proto/vtctldata"
+)
+
+var (
+	// VDiff is the parent command for the VDiff subcommands.
+	VDiff = &cobra.Command{
+		Use:                   "VDiff --workflow <workflow> --target-keyspace <keyspace> [command] [command-flags]",
+		Short:                 "Perform commands related to diffing tables involved in a VReplication workflow between the source and target.",
+		DisableFlagsInUseLine: true,
+		Aliases:               []string{"vdiff"},
+	}
+
+	// VDiffCreate makes a VDiffCreate gRPC call to a vtctld.
+	VDiffCreate = &cobra.Command{
+		Use:                   "create",
+		Short:                 "Create and run a VDiff to compare the tables involved in a VReplication workflow between the source and target.",
+		Example:               `vtctldclient --server localhost:15999 vdiff --workflow commerce2customer --target-keyspace customer create --tables corder,customer`,
+		DisableFlagsInUseLine: true,
+		Aliases:               []string{"Create"},
+		Args:                  cobra.NoArgs,
+		RunE:                  commandVDiffCreate,
+	}
+
+	// VDiffDelete makes a VDiffDelete gRPC call to a vtctld.
+	VDiffDelete = &cobra.Command{
+		Use:                   "delete <all|uuid>",
+		Short:                 "Delete the VDiffs for the workflow: all of them, or the one with the given UUID.",
+		DisableFlagsInUseLine: true,
+		Aliases:               []string{"Delete"},
+		Args:                  cobra.ExactArgs(1),
+		RunE:                  commandVDiffDelete,
+	}
+
+	// VDiffResume makes a VDiffResume gRPC call to a vtctld.
+	VDiffResume = &cobra.Command{
+		Use:                   "resume <uuid>",
+		Short:                 "Resume the stopped VDiff with the given UUID.",
+		DisableFlagsInUseLine: true,
+		Aliases:               []string{"Resume"},
+		Args:                  cobra.ExactArgs(1),
+		RunE:                  commandVDiffResume,
+	}
+
+	// VDiffShow makes a VDiffShow gRPC call to a vtctld.
+	VDiffShow = &cobra.Command{
+		Use:                   "show <all|last|uuid>",
+		Short:                 "Show the status and results of the VDiffs for the workflow: all of them, the most recent one, or the one with the given UUID.",
+		DisableFlagsInUseLine: true,
+		Aliases:               []string{"Show"},
+		Args:                  cobra.ExactArgs(1),
+		RunE:                  commandVDiffShow,
+	}
+
+	// VDiffStop makes a VDiffStop gRPC call to a vtctld.
+	VDiffStop = &cobra.Command{
+		Use:                   "stop <uuid>",
+		Short:                 "Stop the running VDiff with the given UUID.",
+		DisableFlagsInUseLine: true,
+		Aliases:               []string{"Stop"},
+		Args:                  cobra.ExactArgs(1),
+		RunE:                  commandVDiffStop,
+	}
+)
+
+var vdiffCreateOptions = struct {
+	UUID                         string
+	SourceCells                  []string
+	TargetCells                  []string
+	TabletTypes                  []string
+	TabletTypesInPreferenceOrder bool
+	Tables                       []string
+	Limit                        int64
+	FilteredReplicationWaitTime  time.Duration
+	DebugQuery                   bool
+	OnlyPKs                      bool
+	UpdateTableStats             bool
+	MaxExtraRowsToCompare        int64
+	AutoRetry                    bool
+	Checksum                     bool
+}{}
+
+func commandVDiffCreate(cmd *cobra.Command, args []string) error {
+	tabletTypes, err := parseTabletTypes(vdiffCreateOptions.TabletTypes)
+	if err != nil {
+		return err
+	}
+
+	cli.FinishedParsing(cmd)
+
+	resp, err := client.VDiffCreate(commandCtx, &vtctldatapb.VDiffCreateRequest{
+		Workflow:                     baseOptions.Workflow,
+		TargetKeyspace:               baseOptions.TargetKeyspace,
+		Uuid:                         vdiffCreateOptions.UUID,
+		SourceCells:                  vdiffCreateOptions.SourceCells,
+		TargetCells:                  vdiffCreateOptions.TargetCells,
+		TabletTypes:                  tabletTypes,
+		TabletTypesInPreferenceOrder: vdiffCreateOptions.TabletTypesInPreferenceOrder,
+		Tables:                       vdiffCreateOptions.Tables,
+		Limit:                        vdiffCreateOptions.Limit,
+		FilteredReplicationWaitTime:  protoutil.DurationToProto(vdiffCreateOptions.FilteredReplicationWaitTime),
+		DebugQuery:                   vdiffCreateOptions.DebugQuery,
+		OnlyPKs:                      vdiffCreateOptions.OnlyPKs,
+		UpdateTableStats:             vdiffCreateOptions.UpdateTableStats,
+		MaxExtraRowsToCompare:        vdiffCreateOptions.MaxExtraRowsToCompare,
+		AutoRetry:                    vdiffCreateOptions.AutoRetry,
+		Checksum:                     vdiffCreateOptions.Checksum,
+	})
+	if err != nil {
+		return err
+	}
+
+	return printJSON(resp)
+}
+
+func commandVDiffDelete(cmd *cobra.Command, args []string) error {
+	cli.FinishedParsing(cmd)
+
+	resp, err := client.VDiffDelete(commandCtx, &vtctldatapb.VDiffDeleteRequest{
+		Workflow:       baseOptions.Workflow,
+		TargetKeyspace: baseOptions.TargetKeyspace,
+		Arg:            cmd.Flags().Arg(0),
+	})
+	if err != nil {
+		return err
+	}
+
+	return printJSON(resp)
+}
+
+func commandVDiffResume(cmd *cobra.Command, args []string) error {
+	cli.FinishedParsing(cmd)
+
+	resp, err := client.VDiffResume(commandCtx, &vtctldatapb.VDiffResumeRequest{
+		Workflow:       baseOptions.Workflow,
+		TargetKeyspace: baseOptions.TargetKeyspace,
+		Uuid:           cmd.Flags().Arg(0),
+	})
+	if err != nil {
+		return err
+	}
+
+	return printJSON(resp)
+}
+
+func commandVDiffShow(cmd *cobra.Command, args []string) error {
+	cli.FinishedParsing(cmd)
+
+	resp, err := client.VDiffShow(commandCtx, &vtctldatapb.VDiffShowRequest{
+		Workflow:       baseOptions.Workflow,
+		TargetKeyspace: baseOptions.TargetKeyspace,
+		Arg:            cmd.Flags().Arg(0),
+	})
+	if err != nil {
+		return err
+	}
+
+	return printJSON(resp)
+}
+
+func commandVDiffStop(cmd *cobra.Command, args []string) error {
+	cli.FinishedParsing(cmd)
+
+	resp, err := client.VDiffStop(commandCtx, &vtctldatapb.VDiffStopRequest{
+		Workflow:       baseOptions.Workflow,
+		TargetKeyspace: baseOptions.TargetKeyspace,
+		Uuid:           cmd.Flags().Arg(0),
+	})
+	if err != nil {
+		return err
+	}
+
+	return printJSON(resp)
+}
+
+func init() {
+	addBaseFlags(VDiff)
+	Root.AddCommand(VDiff)
+
+	VDiffCreate.Flags().StringVar(&vdiffCreateOptions.UUID, "uuid", "", "Use the provided UUID rather than generating a new one")
+	VDiffCreate.Flags().StringSliceVar(&vdiffCreateOptions.SourceCells, "source-cells", nil, "The source cell(s) to compare from; default is any available cell")
+	VDiffCreate.Flags().StringSliceVar(&vdiffCreateOptions.TargetCells, "target-cells", nil, "The target cell(s) to compare with; default is any available cell")
+	VDiffCreate.Flags().StringSliceVar(&vdiffCreateOptions.TabletTypes, "tablet-types", nil, "Tablet types to use on the source and target (default RDONLY,REPLICA,PRIMARY)")
+	VDiffCreate.Flags().BoolVar(&vdiffCreateOptions.TabletTypesInPreferenceOrder, "tablet-types-in-preference-order", true, "When performing source tablet selection, look for candidates in the type order as they are listed in the --tablet-types flag")
+	VDiffCreate.Flags().StringSliceVar(&vdiffCreateOptions.Tables, "tables", nil, "Only run vdiff for these tables in the workflow")
+	VDiffCreate.Flags().Int64Var(&vdiffCreateOptions.Limit, "limit", 0, "Max rows to stop comparing after; 0 compares all rows")
+	VDiffCreate.Flags().DurationVar(&vdiffCreateOptions.FilteredReplicationWaitTime, "filtered-replication-wait-time", 30*time.Second, "Specifies the maximum time to wait, in seconds, for replication to catch up when syncing tablet streams")
+	VDiffCreate.Flags().BoolVar(&vdiffCreateOptions.DebugQuery, "debug-query", false, "Adds a mysql query to the report that can be used for further debugging")
+	VDiffCreate.Flags().BoolVar(&vdiffCreateOptions.OnlyPKs, "only-pks", false, "When reporting missing rows, only show primary keys in the report")
+	VDiffCreate.Flags().BoolVar(&vdiffCreateOptions.UpdateTableStats, "update-table-stats", false, "Update the table statistics, using ANALYZE TABLE, on each table involved in the VDiff during initialization. This will ensure that progress estimates are as accurate as possible -- but it does involve locks and can potentially impact query processing on the target keyspace")
+	VDiffCreate.Flags().Int64Var(&vdiffCreateOptions.MaxExtraRowsToCompare, "max-extra-rows-to-compare", 1000, "If there are collation differences between the source and target, you can have rows that are identical but simply returned in a different order from MySQL. We will do a second pass to compare the rows for any actual differences in this case and this flag allows you to control the resources used for this operation")
+	VDiffCreate.Flags().BoolVar(&vdiffCreateOptions.AutoRetry, "auto-retry", true, "Should this vdiff automatically retry and continue in case of recoverable errors")
+	VDiffCreate.Flags().BoolVar(&vdiffCreateOptions.Checksum, "checksum", false, "Compare checksums of primary key ranges, and only compare the rows of the ranges whose checksums differ. Not supported for workflows that filter rows by keyrange, such as Reshard")
+	VDiff.AddCommand(VDiffCreate)
+
+	VDiff.AddCommand(VDiffDelete)
+	VDiff.AddCommand(VDiffResume)
+	VDiff.AddCommand(VDiffShow)
+	VDiff.AddCommand(VDiffStop)
+}
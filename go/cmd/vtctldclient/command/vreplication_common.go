@@ -0,0 +1,309 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"vitess.io/vitess/go/cmd/vtctldclient/cli"
+	"vitess.io/vitess/go/protoutil"
+	"vitess.io/vitess/go/vt/topo/topoproto"
+	"vitess.io/vitess/go/vt/vtctl/workflow"
+
+	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vtctldatapb "vitess.io/vitess/go/vt/proto/vtctldata"
+)
+
+// The MoveTables, Reshard, Migrate, Materialize and LookupVindex commands
+// share the subcommands that act on an existing workflow, which is given by
+// the --workflow and --target-keyspace flags of the parent command.
+
+const defaultWorkflowTimeout = 30 * time.Second
+
+var (
+	baseOptions = struct {
+		Workflow       string
+		TargetKeyspace string
+	}{}
+
+	createOptions = struct {
+		Cells                        []string
+		TabletTypes                  []string
+		TabletTypesInPreferenceOrder bool
+		OnDDL                        string
+		StopAfterCopy                bool
+		DeferSecondaryKeys           bool
+		AutoStart                    bool
+	}{}
+
+	switchTrafficOptions = struct {
+		Cells                    []string
+		TabletTypes              []string
+		MaxReplicationLagAllowed time.Duration
+		EnableReverseReplication bool
+		Timeout                  time.Duration
+		DryRun                   bool
+	}{}
+
+	completeOptions = struct {
+		KeepData         bool
+		KeepRoutingRules bool
+		RenameTables     bool
+		DryRun           bool
+	}{}
+
+	cancelOptions = struct {
+		KeepData         bool
+		KeepRoutingRules bool
+	}{}
+)
+
+// addBaseFlags adds the flags that select the workflow to a parent command.
+func addBaseFlags(cmd *cobra.Command) {
+	cmd.PersistentFlags().StringVarP(&baseOptions.Workflow, "workflow", "w", "", "The workflow you want to perform the command on (required)")
+	cmd.MarkPersistentFlagRequired("workflow")
+	cmd.PersistentFlags().StringVarP(&baseOptions.TargetKeyspace, "target-keyspace", "k", "", "Target keyspace of the workflow (required)")
+	cmd.MarkPersistentFlagRequired("target-keyspace")
+}
+
+// addCreateFlags adds the flags that all the create subcommands share.
+func addCreateFlags(cmd *cobra.Command) {
+	cmd.Flags().StringSliceVarP(&createOptions.Cells, "cells", "c", nil, "Cell(s) or CellAlias(es) (comma-separated) to replicate from")
+	cmd.Flags().StringSliceVar(&createOptions.TabletTypes, "tablet-types", nil, "Source tablet types to replicate from (e.g. PRIMARY,REPLICA,RDONLY). Defaults to the --vreplication_tablet_type value of the tablets")
+	cmd.Flags().BoolVar(&createOptions.TabletTypesInPreferenceOrder, "tablet-types-in-preference-order", true, "When performing source tablet selection, look for candidates in the type order as they are listed in the --tablet-types flag")
+	cmd.Flags().StringVar(&createOptions.OnDDL, "on-ddl", binlogdatapb.OnDDLAction_IGNORE.String(), "What to do when DDL is encountered in the VReplication stream. Possible values are IGNORE, STOP, EXEC, and EXEC_IGNORE")
+	cmd.Flags().BoolVar(&createOptions.StopAfterCopy, "stop-after-copy", false, "Stop the workflow after it's finished copying the existing rows and before it starts replicating changes")
+	cmd.Flags().BoolVar(&createOptions.DeferSecondaryKeys, "defer-secondary-keys", false, "Defer secondary index creation for a table until after it has been copied")
+	cmd.Flags().BoolVar(&createOptions.AutoStart, "auto-start", true, "Start the workflow after creating it")
+}
+
+// validateCreateOptions validates the values of the create flags, and returns
+// the parsed tablet types and on-ddl action.
+func validateCreateOptions() ([]topodatapb.TabletType, binlogdatapb.OnDDLAction, error) {
+	tabletTypes, err := parseTabletTypes(createOptions.TabletTypes)
+	if err != nil {
+		return nil, 0, err
+	}
+	onDDL, ok := binlogdatapb.OnDDLAction_value[strings.ToUpper(createOptions.OnDDL)]
+	if !ok {
+		return nil, 0, fmt.Errorf("invalid on-ddl value: %s", createOptions.OnDDL)
+	}
+	return tabletTypes, binlogdatapb.OnDDLAction(onDDL), nil
+}
+
+func parseTabletTypes(names []string) ([]topodatapb.TabletType, error) {
+	tabletTypes := make([]topodatapb.TabletType, 0, len(names))
+	for _, name := range names {
+		tabletType, err := topoproto.ParseTabletType(strings.TrimSpace(name))
+		if err != nil {
+			return nil, err
+		}
+		tabletTypes = append(tabletTypes, tabletType)
+	}
+	return tabletTypes, nil
+}
+
+func printJSON(resp any) error {
+	data, err := cli.MarshalJSON(resp)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s\n", data)
+
+	return nil
+}
+
+func newShowCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:                   "show",
+		Short:                 "Show the details of the workflow.",
+		DisableFlagsInUseLine: true,
+		Aliases:               []string{"Show"},
+		Args:                  cobra.NoArgs,
+		RunE:                  commandWorkflowShow,
+	}
+}
+
+func commandWorkflowShow(cmd *cobra.Command, args []string) error {
+	cli.FinishedParsing(cmd)
+
+	resp, err := client.GetWorkflows(commandCtx, &vtctldatapb.GetWorkflowsRequest{
+		Keyspace: baseOptions.TargetKeyspace,
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, wf := range resp.Workflows {
+		if wf.Name == baseOptions.Workflow {
+			return printJSON(wf)
+		}
+	}
+
+	return fmt.Errorf("workflow %s does not exist in keyspace %s", baseOptions.Workflow, baseOptions.TargetKeyspace)
+}
+
+func newStatusCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:                   "status",
+		Short:                 "Show the copy progress, the streams and the traffic state of the workflow.",
+		DisableFlagsInUseLine: true,
+		Aliases:               []string{"Status", "progress", "Progress"},
+		Args:                  cobra.NoArgs,
+		RunE:                  commandWorkflowStatus,
+	}
+}
+
+func commandWorkflowStatus(cmd *cobra.Command, args []string) error {
+	cli.FinishedParsing(cmd)
+
+	resp, err := client.WorkflowStatus(commandCtx, &vtctldatapb.WorkflowStatusRequest{
+		Keyspace: baseOptions.TargetKeyspace,
+		Workflow: baseOptions.Workflow,
+	})
+	if err != nil {
+		return err
+	}
+
+	return printJSON(resp)
+}
+
+func newSwitchTrafficCommand(reverse bool) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:                   "switchtraffic",
+		Short:                 "Switch traffic to the target of the workflow.",
+		DisableFlagsInUseLine: true,
+		Aliases:               []string{"SwitchTraffic"},
+		Args:                  cobra.NoArgs,
+		RunE:                  commandWorkflowSwitchTraffic,
+	}
+	if reverse {
+		cmd.Use = "reversetraffic"
+		cmd.Short = "Switch traffic back to the source of the workflow."
+		cmd.Aliases = []string{"ReverseTraffic"}
+	}
+	cmd.Flags().StringSliceVarP(&switchTrafficOptions.Cells, "cells", "c", nil, "Cells and/or CellAliases to switch traffic in")
+	cmd.Flags().StringSliceVar(&switchTrafficOptions.TabletTypes, "tablet-types", nil, "Tablet types to switch traffic for. Defaults to all of them")
+	cmd.Flags().DurationVar(&switchTrafficOptions.MaxReplicationLagAllowed, "max-replication-lag-allowed", defaultWorkflowTimeout, "Allow traffic to be switched only if VReplication lag is below this")
+	cmd.Flags().BoolVar(&switchTrafficOptions.EnableReverseReplication, "enable-reverse-replication", true, "Setup replication going back to the original source keyspace to support rolling back the traffic cutover")
+	cmd.Flags().DurationVar(&switchTrafficOptions.Timeout, "timeout", defaultWorkflowTimeout, "Specifies the maximum time to wait, in seconds, for VReplication to catch up on primary tablets. The traffic switch will be cancelled on timeout")
+	cmd.Flags().BoolVar(&switchTrafficOptions.DryRun, "dry-run", false, "Print the actions that would be taken and report any known errors that would have occurred")
+	return cmd
+}
+
+func commandWorkflowSwitchTraffic(cmd *cobra.Command, args []string) error {
+	tabletTypes, err := parseTabletTypes(switchTrafficOptions.TabletTypes)
+	if err != nil {
+		return err
+	}
+
+	cli.FinishedParsing(cmd)
+
+	direction := workflow.DirectionForward
+	if cmd.Name() == "reversetraffic" {
+		direction = workflow.DirectionBackward
+	}
+
+	resp, err := client.WorkflowSwitchTraffic(commandCtx, &vtctldatapb.WorkflowSwitchTrafficRequest{
+		Keyspace:                 baseOptions.TargetKeyspace,
+		Workflow:                 baseOptions.Workflow,
+		Cells:                    switchTrafficOptions.Cells,
+		TabletTypes:              tabletTypes,
+		MaxReplicationLagAllowed: protoutil.DurationToProto(switchTrafficOptions.MaxReplicationLagAllowed),
+		EnableReverseReplication: switchTrafficOptions.EnableReverseReplication,
+		Direction:                int32(direction),
+		Timeout:                  protoutil.DurationToProto(switchTrafficOptions.Timeout),
+		DryRun:                   switchTrafficOptions.DryRun,
+	})
+	if err != nil {
+		return err
+	}
+
+	return printJSON(resp)
+}
+
+func newCompleteCommand(renameTables bool) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:                   "complete",
+		Short:                 "Complete the workflow, once all of its traffic has been switched, and clean up its source.",
+		DisableFlagsInUseLine: true,
+		Aliases:               []string{"Complete"},
+		Args:                  cobra.NoArgs,
+		RunE:                  commandWorkflowComplete,
+	}
+	cmd.Flags().BoolVar(&completeOptions.KeepData, "keep-data", false, "Keep the original source data that was copied by the workflow")
+	cmd.Flags().BoolVar(&completeOptions.KeepRoutingRules, "keep-routing-rules", false, "Keep the routing rules in place that direct table traffic from the source keyspace to the target keyspace of the workflow")
+	if renameTables {
+		cmd.Flags().BoolVar(&completeOptions.RenameTables, "rename-tables", false, "Keep the original source table data that was copied by the workflow, but rename each table to '_<tablename>_old'")
+	}
+	cmd.Flags().BoolVar(&completeOptions.DryRun, "dry-run", false, "Print the actions that would be taken and report any known errors that would have occurred")
+	return cmd
+}
+
+func commandWorkflowComplete(cmd *cobra.Command, args []string) error {
+	cli.FinishedParsing(cmd)
+
+	resp, err := client.WorkflowComplete(commandCtx, &vtctldatapb.WorkflowCompleteRequest{
+		Keyspace:         baseOptions.TargetKeyspace,
+		Workflow:         baseOptions.Workflow,
+		KeepData:         completeOptions.KeepData,
+		KeepRoutingRules: completeOptions.KeepRoutingRules,
+		RenameTables:     completeOptions.RenameTables,
+		DryRun:           completeOptions.DryRun,
+	})
+	if err != nil {
+		return err
+	}
+
+	return printJSON(resp)
+}
+
+func newCancelCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:                   "cancel",
+		Short:                 "Cancel the workflow, before any of its traffic has been switched, and clean up its target.",
+		DisableFlagsInUseLine: true,
+		Aliases:               []string{"Cancel"},
+		Args:                  cobra.NoArgs,
+		RunE:                  commandWorkflowCancel,
+	}
+	cmd.Flags().BoolVar(&cancelOptions.KeepData, "keep-data", false, "Keep the partially copied table data from the workflow in the target keyspace")
+	cmd.Flags().BoolVar(&cancelOptions.KeepRoutingRules, "keep-routing-rules", false, "Keep the routing rules created for the workflow")
+	return cmd
+}
+
+func commandWorkflowCancel(cmd *cobra.Command, args []string) error {
+	cli.FinishedParsing(cmd)
+
+	resp, err := client.WorkflowCancel(commandCtx, &vtctldatapb.WorkflowCancelRequest{
+		Keyspace:         baseOptions.TargetKeyspace,
+		Workflow:         baseOptions.Workflow,
+		KeepData:         cancelOptions.KeepData,
+		KeepRoutingRules: cancelOptions.KeepRoutingRules,
+	})
+	if err != nil {
+		return err
+	}
+
+	return printJSON(resp)
+}
@@ -87,6 +87,18 @@ var (
 					return fmt.Errorf("invalid on-ddl value: %s", workflowUpdateOptions.OnDDL)
 				}
 			} // Simulated NULL will need to be handled in command
+			for _, name := range []string{"window-start", "window-end", "copy-only", "max-rows-per-second", "max-bytes-per-second"} {
+				if cmd.Flags().Lookup(name).Changed {
+					if workflowUpdateOptions.ClearSchedule {
+						return fmt.Errorf("--%s cannot be used with --clear-schedule", name)
+					}
+					changes = true
+					workflowUpdateOptions.Schedule = true
+				}
+			}
+			if workflowUpdateOptions.ClearSchedule {
+				changes = true
+			}
 			if !changes {
 				return fmt.Errorf("no configuration options specified to update")
 			}
@@ -133,6 +145,16 @@ var (
 		Cells       []string
 		TabletTypes []string
 		OnDDL       string
+
+		// Schedule is set if any of the schedule options is provided. The
+		// schedule of the workflow is then replaced as a whole.
+		Schedule          bool
+		ClearSchedule     bool
+		WindowStart       string
+		WindowEnd         string
+		CopyOnly          bool
+		MaxRowsPerSecond  int64
+		MaxBytesPerSecond int64
 	}{}
 )
 
@@ -156,6 +178,18 @@ func commandWorkflowUpdate(cmd *cobra.Command, args []string) error {
 			OnDdl:       binlogdatapb.OnDDLAction(onddl),
 		},
 	}
+	switch {
+	case workflowUpdateOptions.ClearSchedule:
+		req.TabletRequest.Schedule = &binlogdatapb.VReplicationSchedule{}
+	case workflowUpdateOptions.Schedule:
+		req.TabletRequest.Schedule = &binlogdatapb.VReplicationSchedule{
+			WindowStart:       workflowUpdateOptions.WindowStart,
+			WindowEnd:         workflowUpdateOptions.WindowEnd,
+			CopyOnly:          workflowUpdateOptions.CopyOnly,
+			MaxRowsPerSecond:  workflowUpdateOptions.MaxRowsPerSecond,
+			MaxBytesPerSecond: workflowUpdateOptions.MaxBytesPerSecond,
+		}
+	}
 
 	resp, err := client.WorkflowUpdate(commandCtx, req)
 	if err != nil {
@@ -189,5 +223,11 @@ func init() {
 	WorkflowUpdate.Flags().StringSliceVarP(&workflowUpdateOptions.Cells, "cells", "c", nil, "New Cell(s) or CellAlias(es) (comma-separated) to replicate from")
 	WorkflowUpdate.Flags().StringSliceVarP(&workflowUpdateOptions.TabletTypes, "tablet-types", "t", nil, "New source tablet types to replicate from (e.g. PRIMARY,REPLICA,RDONLY)")
 	WorkflowUpdate.Flags().StringVar(&workflowUpdateOptions.OnDDL, "on-ddl", "", "New instruction on what to do when DDL is encountered in the VReplication stream. Possible values are IGNORE, STOP, EXEC, and EXEC_IGNORE")
+	WorkflowUpdate.Flags().StringVar(&workflowUpdateOptions.WindowStart, "window-start", "", "Start of the daily maintenance window, as HH:MM in UTC, outside of which the workflow is paused")
+	WorkflowUpdate.Flags().StringVar(&workflowUpdateOptions.WindowEnd, "window-end", "", "End of the daily maintenance window, as HH:MM in UTC. The window wraps around midnight if it is before --window-start")
+	WorkflowUpdate.Flags().BoolVar(&workflowUpdateOptions.CopyOnly, "copy-only", false, "Only pause the copy phase of the workflow outside of the maintenance window")
+	WorkflowUpdate.Flags().Int64Var(&workflowUpdateOptions.MaxRowsPerSecond, "max-rows-per-second", 0, "Maximum number of rows per second the workflow copies and applies (0 means no limit)")
+	WorkflowUpdate.Flags().Int64Var(&workflowUpdateOptions.MaxBytesPerSecond, "max-bytes-per-second", 0, "Maximum number of bytes per second the workflow copies and applies (0 means no limit)")
+	WorkflowUpdate.Flags().BoolVar(&workflowUpdateOptions.ClearSchedule, "clear-schedule", false, "Remove the maintenance window and the rate limits of the workflow")
 	Workflow.AddCommand(WorkflowUpdate)
 }
@@ -18,7 +18,6 @@ package main
 
 import (
 	"fmt"
-	"os"
 
 	"vitess.io/vitess/go/acl"
 	"vitess.io/vitess/go/exit"
@@ -44,6 +43,7 @@ var (
 	normalize          bool
 	dbName             string
 	plannerVersionStr  string
+	batch              bool
 
 	numShards       = 2
 	replicationMode = "ROW"
@@ -54,9 +54,9 @@ var (
 func registerFlags(fs *pflag.FlagSet) {
 	fs.StringVar(&sqlFlag, "sql", sqlFlag, "A list of semicolon-delimited SQL commands to analyze")
 	fs.StringVar(&sqlFileFlag, "sql-file", sqlFileFlag, "Identifies the file that contains the SQL commands to analyze")
-	fs.StringVar(&schemaFlag, "schema", schemaFlag, "The SQL table schema")
+	fs.StringVar(&schemaFlag, "schema", schemaFlag, "The SQL table schema, or a JSON map of keyspace name -> SQL table schema of the keyspace. The schema of the empty keyspace name applies to all keyspaces")
 	fs.StringVar(&schemaFileFlag, "schema-file", schemaFileFlag, "Identifies the file that contains the SQL table schema")
-	fs.StringVar(&vschemaFlag, "vschema", vschemaFlag, "Identifies the VTGate routing schema")
+	fs.StringVar(&vschemaFlag, "vschema", vschemaFlag, "Identifies the VTGate routing schema: a JSON map of keyspace name -> keyspace vschema, or a SrvVSchema with keyspaces, routing_rules and shard_routing_rules")
 	fs.StringVar(&vschemaFileFlag, "vschema-file", vschemaFileFlag, "Identifies the VTGate routing schema file")
 	fs.StringVar(&ksShardMapFlag, "ks-shard-map", ksShardMapFlag, "JSON map of keyspace name -> shard name -> ShardReference object. The inner map is the same as the output of FindAllShardsInKeyspace")
 	fs.StringVar(&ksShardMapFileFlag, "ks-shard-map-file", ksShardMapFileFlag, "File containing json blob of keyspace name -> shard name -> ShardReference object")
@@ -66,7 +66,8 @@ func registerFlags(fs *pflag.FlagSet) {
 	fs.StringVar(&plannerVersionStr, "planner-version", plannerVersionStr, "Sets the query planner version to use when generating the explain output. Valid values are V3 and Gen4. An empty value will use VTGate's default planner")
 	fs.IntVar(&numShards, "shards", numShards, "Number of shards per keyspace. Passing --ks-shard-map/--ks-shard-map-file causes this flag to be ignored.")
 	fs.StringVar(&executionMode, "execution-mode", executionMode, "The execution mode to simulate -- must be set to multi, legacy-autocommit, or twopc")
-	fs.StringVar(&outputMode, "output-mode", outputMode, "Output in human-friendly text or json")
+	fs.StringVar(&outputMode, "output-mode", outputMode, "Output in human-friendly text, json, or json-report which lists the queries and transactions of each shard")
+	fs.BoolVar(&batch, "batch", batch, "Explain all the SQL commands even if some of them fail, reporting the errors in the output, and exit with an error if any failed")
 
 	acl.RegisterFlags(fs)
 }
@@ -75,30 +76,6 @@ func init() {
 	servenv.OnParse(registerFlags)
 }
 
-// getFileParam returns a string containing either flag is not "",
-// or the content of the file named flagFile
-func getFileParam(flag, flagFile, name string, required bool) (string, error) {
-	if flag != "" {
-		if flagFile != "" {
-			return "", fmt.Errorf("action requires only one of %v or %v-file", name, name)
-		}
-		return flag, nil
-	}
-
-	if flagFile == "" {
-		if required {
-			return "", fmt.Errorf("action requires one of %v or %v-file", name, name)
-		}
-
-		return "", nil
-	}
-	data, err := os.ReadFile(flagFile)
-	if err != nil {
-		return "", fmt.Errorf("cannot read file %v: %v", flagFile, err)
-	}
-	return string(data), nil
-}
-
 func main() {
 	defer exit.RecoverAll()
 	defer logutil.Flush()
@@ -112,27 +89,31 @@ func main() {
 }
 
 func parseAndRun() error {
+	if outputMode != "text" && outputMode != "json" && outputMode != "json-report" {
+		return fmt.Errorf("invalid value specified for output-mode of '%s' -- valid values are text, json and json-report", outputMode)
+	}
+
 	plannerVersion, _ := plancontext.PlannerNameToVersion(plannerVersionStr)
 	if plannerVersionStr != "" && plannerVersion != querypb.ExecuteOptions_V3 && plannerVersion != querypb.ExecuteOptions_Gen4 {
 		return fmt.Errorf("invalid value specified for planner-version of '%s' -- valid values are V3 and Gen4 or an empty value to use the default planner", plannerVersionStr)
 	}
 
-	sql, err := getFileParam(sqlFlag, sqlFileFlag, "sql", true)
+	sql, err := vtexplain.GetFileParam(sqlFlag, sqlFileFlag, "sql", true)
 	if err != nil {
 		return err
 	}
 
-	schema, err := getFileParam(schemaFlag, schemaFileFlag, "schema", true)
+	schema, err := vtexplain.GetFileParam(schemaFlag, schemaFileFlag, "schema", true)
 	if err != nil {
 		return err
 	}
 
-	vschema, err := getFileParam(vschemaFlag, vschemaFileFlag, "vschema", true)
+	vschema, err := vtexplain.GetFileParam(vschemaFlag, vschemaFileFlag, "vschema", true)
 	if err != nil {
 		return err
 	}
 
-	ksShardMap, err := getFileParam(ksShardMapFlag, ksShardMapFileFlag, "ks-shard-map", false)
+	ksShardMap, err := vtexplain.GetFileParam(ksShardMapFlag, ksShardMapFileFlag, "ks-shard-map", false)
 	if err != nil {
 		return err
 	}
@@ -152,16 +133,37 @@ func parseAndRun() error {
 	}
 	defer vte.Stop()
 
-	plans, err := vte.Run(sql)
+	var plans []*vtexplain.Explain
+	if batch {
+		plans, err = vte.RunBatch(sql)
+	} else {
+		plans, err = vte.Run(sql)
+	}
 	if err != nil {
 		return err
 	}
 
-	if outputMode == "text" {
-		fmt.Print(vte.ExplainsAsText(plans))
-	} else {
+	switch outputMode {
+	case "text":
+		out, err := vte.ExplainsAsText(plans)
+		if err != nil {
+			return err
+		}
+		fmt.Print(out)
+	case "json":
 		fmt.Print(vtexplain.ExplainsAsJSON(plans))
+	case "json-report":
+		out, err := vte.ExplainsAsJSONReport(plans)
+		if err != nil {
+			return err
+		}
+		fmt.Print(out)
 	}
 
+	for _, plan := range plans {
+		if plan.Error != "" {
+			return fmt.Errorf("failed to explain some queries")
+		}
+	}
 	return nil
 }
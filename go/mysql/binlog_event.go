@@ -96,6 +96,13 @@ type BinlogEvent interface {
 	// also serves as a BEGIN statement.
 	// This is only valid if IsGTID() returns true.
 	GTID(BinlogFormat) (GTID, bool, error)
+	// LogicalClock returns the last_committed and sequence_number of
+	// the transaction that starts with this event. A transaction doesn't
+	// depend on the earlier transactions of the same binary log whose
+	// sequence_number is greater than its last_committed, so they can be
+	// applied in parallel. ok is false if the event has no logical clock.
+	// This is only valid if IsGTID() returns true.
+	LogicalClock(BinlogFormat) (lastCommitted, sequenceNumber int64, ok bool)
 	// Query returns a Query struct representing data from a QUERY_EVENT.
 	// This is only valid if IsQuery() returns true.
 	Query(BinlogFormat) (Query, error)
@@ -196,6 +196,12 @@ func (ev binlogEvent) IsDeleteRows() bool {
 		ev.Type() == eDeleteRowsEventV2
 }
 
+// LogicalClock implements BinlogEvent.LogicalClock(). Only MySQL 5.7 and
+// later GTID events have a logical clock.
+func (ev binlogEvent) LogicalClock(BinlogFormat) (int64, int64, bool) {
+	return 0, 0, false
+}
+
 // IsPseudo is always false for a native binlogEvent.
 func (ev binlogEvent) IsPseudo() bool {
 	return false
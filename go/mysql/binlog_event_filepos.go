@@ -217,6 +217,10 @@ func (ev filePosFakeEvent) GTID(BinlogFormat) (GTID, bool, error) {
 	return nil, false, nil
 }
 
+func (ev filePosFakeEvent) LogicalClock(BinlogFormat) (int64, int64, bool) {
+	return 0, 0, false
+}
+
 func (ev filePosFakeEvent) Query(BinlogFormat) (Query, error) {
 	return Query{}, nil
 }
@@ -67,6 +67,28 @@ func (ev mysql56BinlogEvent) GTID(f BinlogFormat) (GTID, bool, error) {
 	return Mysql56GTID{Server: sid, Sequence: gno}, false /* hasBegin */, nil
 }
 
+// logicalTimestampTypeCode marks the logical clock of a GTID event.
+const logicalTimestampTypeCode = 2
+
+// LogicalClock implements BinlogEvent.LogicalClock().
+//
+// Expected format, following the GTID:
+//
+//	# bytes   field
+//	1         logical timestamp type code
+//	8         last_committed
+//	8         sequence_number
+func (ev mysql56BinlogEvent) LogicalClock(f BinlogFormat) (int64, int64, bool) {
+	data := ev.Bytes()[f.HeaderLength:]
+	const pos = 1 + 16 + 8
+	if len(data) < pos+1+8+8 || data[pos] != logicalTimestampTypeCode {
+		return 0, 0, false
+	}
+	lastCommitted := int64(binary.LittleEndian.Uint64(data[pos+1 : pos+1+8]))
+	sequenceNumber := int64(binary.LittleEndian.Uint64(data[pos+1+8 : pos+1+8+8]))
+	return lastCommitted, sequenceNumber, true
+}
+
 // PreviousGTIDs implements BinlogEvent.PreviousGTIDs().
 func (ev mysql56BinlogEvent) PreviousGTIDs(f BinlogFormat) (Position, error) {
 	data := ev.Bytes()[f.HeaderLength:]
@@ -17,6 +17,7 @@ limitations under the License.
 package mysql
 
 import (
+	"encoding/binary"
 	"fmt"
 	"reflect"
 	"testing"
@@ -87,6 +88,26 @@ func TestMysql56GTID(t *testing.T) {
 
 }
 
+func TestMysql56LogicalClock(t *testing.T) {
+	format, err := mysql56FormatEvent.Format()
+	require.NoError(t, err)
+	input, _, err := mysql56GTIDEvent.StripChecksum(format)
+	require.NoError(t, err)
+
+	// MySQL 5.6 GTID events have no logical clock
+	_, _, ok := input.LogicalClock(format)
+	assert.False(t, ok)
+
+	data := append([]byte{}, input.Bytes()...)
+	data = append(data, logicalTimestampTypeCode)
+	data = binary.LittleEndian.AppendUint64(data, 7)
+	data = binary.LittleEndian.AppendUint64(data, 9)
+	lastCommitted, sequenceNumber, ok := NewMysql56BinlogEvent(data).LogicalClock(format)
+	require.True(t, ok)
+	assert.EqualValues(t, 7, lastCommitted)
+	assert.EqualValues(t, 9, sequenceNumber)
+}
+
 func TestMysql56ParseGTID(t *testing.T) {
 	input := "00010203-0405-0607-0809-0A0B0C0D0E0F:56789"
 	want := Mysql56GTID{
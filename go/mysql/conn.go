@@ -71,6 +71,15 @@ type Getter interface {
 	Get() *querypb.VTGateCallerID
 }
 
+// An ExpiringGetter is a Getter whose credentials are only valid until
+// a point in time, e.g. because they were derived from a bearer token.
+// Connections authenticated with such credentials are closed once
+// the credentials expire.
+type ExpiringGetter interface {
+	Getter
+	Expiry() time.Time
+}
+
 // Conn is a connection between a client and a server, using the MySQL
 // binary protocol. It is built on top of an existing net.Conn, that
 // has already been established.
@@ -881,6 +890,17 @@ func (c *Conn) writeEOFPacket(flags uint16, warnings uint16) error {
 	return c.writeEphemeralPacket()
 }
 
+// credentialsExpired returns true if the connection was authenticated
+// with credentials that have an expiry, and that expiry has passed.
+func (c *Conn) credentialsExpired() bool {
+	eg, ok := c.UserData.(ExpiringGetter)
+	if !ok {
+		return false
+	}
+	expiry := eg.Expiry()
+	return !expiry.IsZero() && time.Now().After(expiry)
+}
+
 // handleNextCommand is called in the server loop to process
 // incoming packets.
 func (c *Conn) handleNextCommand(handler Handler) bool {
@@ -897,6 +917,12 @@ func (c *Conn) handleNextCommand(handler Handler) bool {
 		return false
 	}
 
+	if data[0] != ComQuit && c.credentialsExpired() {
+		c.recycleReadPacket()
+		c.writeErrorAndLog(ERAccessDeniedError, SSAccessDeniedError, "Credentials for user '%v' have expired", c.User)
+		return false
+	}
+
 	switch data[0] {
 	case ComQuit:
 		c.recycleReadPacket()
@@ -933,6 +933,44 @@ func TestInitDbAgainstWrongDbDoesNotDropConnection(t *testing.T) {
 	require.EqualValues(t, data[0], ErrPacket) // we should see the error here
 }
 
+type expiringUserData struct {
+	StaticUserData
+	expiry time.Time
+}
+
+func (eud *expiringUserData) Expiry() time.Time {
+	return eud.expiry
+}
+
+func TestExpiredCredentialsCloseConnection(t *testing.T) {
+	listener, sConn, cConn := createSocketPair(t)
+	defer func() {
+		listener.Close()
+		sConn.Close()
+		cConn.Close()
+	}()
+
+	userData := &expiringUserData{StaticUserData: StaticUserData{Username: "user1"}, expiry: time.Now().Add(time.Hour)}
+	sConn.User = "user1"
+	sConn.UserData = userData
+
+	handler := &testRun{t: t}
+	err := cConn.WriteComQuery("select 1")
+	require.NoError(t, err)
+	res := sConn.handleNextCommand(handler)
+	require.True(t, res, "credentials have not expired yet")
+	_, _, _, err = cConn.ReadQueryResult(100, true)
+	require.NoError(t, err)
+
+	userData.expiry = time.Now().Add(-time.Second)
+	err = cConn.WriteComQuery("select 1")
+	require.NoError(t, err)
+	res = sConn.handleNextCommand(handler)
+	require.False(t, res, "we should close the connection once the credentials expire")
+	_, _, _, err = cConn.ReadQueryResult(100, true)
+	require.EqualError(t, err, "Credentials for user 'user1' have expired (errno 1045) (sqlstate 28000)")
+}
+
 func TestConnectionErrorWhileWritingComQuery(t *testing.T) {
 	// Set the conn for the server connection to the simulated connection which always returns an error on writing
 	sConn := newConn(testConn{
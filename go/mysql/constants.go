@@ -564,6 +564,7 @@ const (
 	ERInvalidCastToJSON            = ErrorCode(3147)
 	ERJSONValueTooBig              = ErrorCode(3150)
 	ERJSONDocumentTooDeep          = ErrorCode(3157)
+	ERCharacterSetMismatch         = ErrorCode(3995)
 
 	// max execution time exceeded
 	ERQueryTimeout = ErrorCode(3024)
@@ -576,6 +577,9 @@ const (
 
 	// server not available
 	ERServerIsntAvailable = ErrorCode(3168)
+
+	// server not restarted after a clone, because it is not managed by a supervisor process
+	ERRestartServerFailed = ErrorCode(3707)
 )
 
 // Sql states for errors.
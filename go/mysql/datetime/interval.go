@@ -0,0 +1,477 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package datetime
+
+import (
+	"math"
+	"strings"
+
+	"vitess.io/vitess/go/mysql/decimal"
+)
+
+// IntervalType is the unit of a temporal interval, as used by DATE_ADD,
+// DATE_SUB, TIMESTAMPADD and TIMESTAMPDIFF. The values are declared in
+// the same order as in MySQL.
+type IntervalType uint8
+
+const (
+	IntervalNone IntervalType = iota
+	IntervalYear
+	IntervalQuarter
+	IntervalMonth
+	IntervalWeek
+	IntervalDay
+	IntervalHour
+	IntervalMinute
+	IntervalSecond
+	IntervalMicrosecond
+	IntervalYearMonth
+	IntervalDayHour
+	IntervalDayMinute
+	IntervalDaySecond
+	IntervalHourMinute
+	IntervalHourSecond
+	IntervalMinuteSecond
+	IntervalDayMicrosecond
+	IntervalHourMicrosecond
+	IntervalMinuteMicrosecond
+	IntervalSecondMicrosecond
+)
+
+// HasTimeParts returns true if the interval modifies the time part
+// (hours or smaller) of a temporal value.
+func (itv IntervalType) HasTimeParts() bool {
+	switch itv {
+	case IntervalNone, IntervalYear, IntervalQuarter, IntervalMonth, IntervalWeek, IntervalDay, IntervalYearMonth:
+		return false
+	default:
+		return true
+	}
+}
+
+// HasDateParts returns true if the interval modifies the date part
+// (days or larger) of a temporal value.
+func (itv IntervalType) HasDateParts() bool {
+	switch itv {
+	case IntervalYear, IntervalQuarter, IntervalMonth, IntervalWeek, IntervalDay, IntervalYearMonth,
+		IntervalDayHour, IntervalDayMinute, IntervalDaySecond, IntervalDayMicrosecond:
+		return true
+	default:
+		return false
+	}
+}
+
+// IsTimeOnly returns true if adding the interval to a TIME value yields
+// another TIME value instead of a DATETIME. Note that, like in MySQL,
+// DAY_MICROSECOND is considered a time unit but DAY_HOUR is not.
+func (itv IntervalType) IsTimeOnly() bool {
+	switch {
+	case itv >= IntervalHour && itv <= IntervalMicrosecond:
+		return true
+	case itv >= IntervalHourMinute && itv <= IntervalSecondMicrosecond:
+		return true
+	default:
+		return false
+	}
+}
+
+// NeedsPrecision returns true if the interval always has fractional seconds.
+func (itv IntervalType) NeedsPrecision() bool {
+	switch itv {
+	case IntervalMicrosecond, IntervalDayMicrosecond, IntervalHourMicrosecond, IntervalMinuteMicrosecond, IntervalSecondMicrosecond:
+		return true
+	default:
+		return false
+	}
+}
+
+// IsCompound returns true if the interval is made out of several parts,
+// e.g. DAY_HOUR. Compound intervals are always parsed from strings.
+func (itv IntervalType) IsCompound() bool {
+	return itv >= IntervalYearMonth
+}
+
+// partCount returns the number of fields in a compound interval and
+// whether the last field holds microseconds.
+func (itv IntervalType) partCount() (int, bool) {
+	switch itv {
+	case IntervalYearMonth, IntervalDayHour, IntervalHourMinute, IntervalMinuteSecond:
+		return 2, false
+	case IntervalSecondMicrosecond:
+		return 2, true
+	case IntervalDayMinute, IntervalHourSecond:
+		return 3, false
+	case IntervalMinuteMicrosecond:
+		return 3, true
+	case IntervalDaySecond:
+		return 4, false
+	case IntervalHourMicrosecond:
+		return 4, true
+	case IntervalDayMicrosecond:
+		return 5, true
+	default:
+		return 1, false
+	}
+}
+
+// Interval is a parsed temporal interval that can be added
+// to a DATETIME or a TIME value.
+type Interval struct {
+	year, month, day int
+	hour, min, sec   int
+	usec             int
+	unit             IntervalType
+	neg              bool
+}
+
+// maxIntervalValue is the largest interval component that MySQL
+// accepts before considering the interval as overflowing.
+const maxIntervalValue = (math.MaxInt64 - 10) / 10
+
+// Unit returns the unit this interval was parsed with.
+func (itv *Interval) Unit() IntervalType {
+	return itv.unit
+}
+
+func (itv *Interval) setSimple(unit IntervalType, val int64) {
+	switch unit {
+	case IntervalYear:
+		itv.year = int(val)
+	case IntervalQuarter:
+		itv.month = int(val) * 3
+	case IntervalMonth:
+		itv.month = int(val)
+	case IntervalWeek:
+		itv.day = int(val) * 7
+	case IntervalDay:
+		itv.day = int(val)
+	case IntervalHour:
+		itv.hour = int(val)
+	case IntervalMinute:
+		itv.min = int(val)
+	case IntervalSecond:
+		itv.sec = int(val)
+	case IntervalMicrosecond:
+		itv.usec = int(val)
+	}
+}
+
+// ParseIntervalInt64 returns an interval for a simple (non-compound) unit
+// out of an integer amount. If negate is set, the sign of the interval is
+// flipped, as DATE_SUB does.
+func ParseIntervalInt64(n int64, unit IntervalType, negate bool) *Interval {
+	itv := &Interval{unit: unit, neg: negate}
+	if n < 0 {
+		itv.neg = !itv.neg
+		if n == math.MinInt64 {
+			n = math.MaxInt64
+		} else {
+			n = -n
+		}
+	}
+	itv.setSimple(unit, n)
+	return itv
+}
+
+// ParseIntervalDecimal returns a SECOND interval out of a decimal amount,
+// keeping its fractional part with microsecond precision. If negate is set,
+// the sign of the interval is flipped, as DATE_SUB does.
+func ParseIntervalDecimal(dec decimal.Decimal, unit IntervalType, negate bool) *Interval {
+	if unit != IntervalSecond {
+		n, _ := dec.Round(0).Int64()
+		return ParseIntervalInt64(n, unit, negate)
+	}
+
+	itv := &Interval{unit: unit, neg: negate}
+	if dec.Sign() < 0 {
+		itv.neg = !itv.neg
+		dec = dec.Neg()
+	}
+
+	sec, frac := dec.QuoRem(decimal.New(1, 0), 0)
+	s, ok := sec.Int64()
+	if !ok {
+		s = math.MaxInt64
+	}
+	usec, _ := frac.Mul(decimal.New(1, 6)).Int64()
+	itv.sec = int(s)
+	itv.usec = int(usec)
+	return itv
+}
+
+// ParseInterval parses an interval out of a string, as MySQL does for
+// compound units like DAY_HOUR ('1 10') or SECOND_MICROSECOND ('1.5').
+// Any non-digit characters are accepted as separators between the parts
+// of the interval and if there are fewer parts than the unit requires, the
+// given parts are assigned to the smallest fields. It returns nil if the
+// string is not a valid interval. If negate is set, the sign of the interval is flipped,
+// as DATE_SUB does.
+func ParseInterval(s string, unit IntervalType, negate bool) *Interval {
+	itv := &Interval{unit: unit, neg: negate}
+
+	for len(s) > 0 && isSpace(s[0]) {
+		s = s[1:]
+	}
+	if len(s) > 0 && s[0] == '-' {
+		itv.neg = !itv.neg
+		s = s[1:]
+	}
+
+	count, msec := unit.partCount()
+	var values [5]int64
+	var fieldLength int
+
+	for len(s) > 0 && !isDigitByte(s[0]) {
+		s = s[1:]
+	}
+	for i := 0; i < count; i++ {
+		var val int64
+		start := len(s)
+		for len(s) > 0 && isDigitByte(s[0]) {
+			if val > maxIntervalValue {
+				return nil
+			}
+			val = val*10 + int64(s[0]-'0')
+			s = s[1:]
+		}
+		if msec && i == count-1 {
+			fieldLength = 6 - (start - len(s))
+		}
+		values[i] = val
+
+		for len(s) > 0 && !isDigitByte(s[0]) {
+			s = s[1:]
+		}
+		if len(s) == 0 && i != count-1 {
+			// Not enough parts: right-align the ones we found.
+			i++
+			shift := count - i
+			copy(values[shift:count], values[:i])
+			for j := 0; j < shift; j++ {
+				values[j] = 0
+			}
+			break
+		}
+	}
+	if len(s) > 0 {
+		return nil
+	}
+	if msec && fieldLength > 0 {
+		for ; fieldLength > 0; fieldLength-- {
+			values[count-1] *= 10
+		}
+	}
+
+	switch unit {
+	case IntervalYearMonth:
+		itv.year, itv.month = int(values[0]), int(values[1])
+	case IntervalDayHour:
+		itv.day, itv.hour = int(values[0]), int(values[1])
+	case IntervalDayMinute:
+		itv.day, itv.hour, itv.min = int(values[0]), int(values[1]), int(values[2])
+	case IntervalDaySecond:
+		itv.day, itv.hour, itv.min, itv.sec = int(values[0]), int(values[1]), int(values[2]), int(values[3])
+	case IntervalDayMicrosecond:
+		itv.day, itv.hour, itv.min, itv.sec, itv.usec = int(values[0]), int(values[1]), int(values[2]), int(values[3]), int(values[4])
+	case IntervalHourMinute:
+		itv.hour, itv.min = int(values[0]), int(values[1])
+	case IntervalHourSecond:
+		itv.hour, itv.min, itv.sec = int(values[0]), int(values[1]), int(values[2])
+	case IntervalHourMicrosecond:
+		itv.hour, itv.min, itv.sec, itv.usec = int(values[0]), int(values[1]), int(values[2]), int(values[3])
+	case IntervalMinuteSecond:
+		itv.min, itv.sec = int(values[0]), int(values[1])
+	case IntervalMinuteMicrosecond:
+		itv.min, itv.sec, itv.usec = int(values[0]), int(values[1]), int(values[2])
+	case IntervalSecondMicrosecond:
+		itv.sec, itv.usec = int(values[0]), int(values[1])
+	default:
+		itv.setSimple(unit, values[0])
+	}
+	return itv
+}
+
+func isDigitByte(c byte) bool {
+	return '0' <= c && c <= '9'
+}
+
+// inRange checks whether the time parts of the interval can be added to
+// a DATETIME without overflowing.
+func (itv *Interval) inRange() bool {
+	return itv.day <= maxDayNumber &&
+		itv.hour <= maxDayNumber*24 &&
+		itv.min <= maxDayNumber*24*60 &&
+		itv.sec <= maxDayNumber*24*60*60
+}
+
+// AddInterval adds the interval to this DATETIME, following MySQL's
+// date_add_interval. It returns false if the result is out of range
+// for a DATETIME.
+func (dt DateTime) AddInterval(itv *Interval) (DateTime, bool) {
+	sign := 1
+	if itv.neg {
+		sign = -1
+	}
+
+	year, month, day := dt.Date.Year(), dt.Date.Month(), dt.Date.Day()
+
+	switch itv.unit {
+	case IntervalYear:
+		if itv.year >= 10000 {
+			return DateTime{}, false
+		}
+		year += sign * itv.year
+		if year < 0 || year >= 10000 {
+			return DateTime{}, false
+		}
+		if month == 2 && day == 29 && !isLeap(year) {
+			day = 28
+		}
+		dt.Date = Date{year: uint16(year), month: uint8(month), day: uint8(day)}
+		return dt, true
+
+	case IntervalQuarter, IntervalMonth, IntervalYearMonth:
+		if itv.year >= 10000 || itv.month >= 120000 {
+			return DateTime{}, false
+		}
+		period := year*12 + sign*itv.year*12 + month - 1 + sign*itv.month
+		if period < 0 || period >= 120000 {
+			return DateTime{}, false
+		}
+		year = period / 12
+		month = period%12 + 1
+		if dim := daysInMonth[month-1]; day > dim {
+			day = dim
+			if month == 2 && isLeap(year) {
+				day++
+			}
+		}
+		dt.Date = Date{year: uint16(year), month: uint8(month), day: uint8(day)}
+		return dt, true
+
+	case IntervalWeek, IntervalDay:
+		if itv.day > maxDayNumber {
+			return DateTime{}, false
+		}
+		daynr := MysqlDayNumber(year, month, day) + sign*itv.day
+		if daynr < 0 || daynr > maxDayNumber {
+			return DateTime{}, false
+		}
+		dt.Date = DateFromDayNumber(daynr)
+		return dt, true
+
+	case IntervalNone:
+		return DateTime{}, false
+	}
+
+	if !itv.inRange() {
+		return DateTime{}, false
+	}
+
+	usec := int64(dt.Time.Nanosecond()/1000) + int64(sign)*int64(itv.usec)
+	extra := usec / 1e6
+	usec = usec % 1e6
+
+	sec := int64(day-1)*86400 + int64(dt.Time.Hour())*3600 + int64(dt.Time.Minute())*60 + int64(dt.Time.Second()) +
+		int64(sign)*(int64(itv.day)*86400+int64(itv.hour)*3600+int64(itv.min)*60+int64(itv.sec)) + extra
+	if usec < 0 {
+		usec += 1e6
+		sec--
+	}
+
+	days := sec / 86400
+	sec -= days * 86400
+	if sec < 0 {
+		days--
+		sec += 86400
+	}
+
+	daynr := int64(MysqlDayNumber(year, month, 1)) + days
+	if daynr < 0 || daynr > maxDayNumber {
+		return DateTime{}, false
+	}
+
+	return DateTime{
+		Date: DateFromDayNumber(int(daynr)),
+		Time: Time{
+			hour:       uint16(sec / 3600),
+			minute:     uint8(sec / 60 % 60),
+			second:     uint8(sec % 60),
+			nanosecond: uint32(usec * 1000),
+		},
+	}, true
+}
+
+// AddInterval adds the interval to this TIME, as MySQL does when adding
+// a time-only interval to a TIME value. It returns false if the interval
+// has date parts or if the result is out of range for a TIME.
+func (t Time) AddInterval(itv *Interval) (Time, bool) {
+	if itv.year != 0 || itv.month != 0 || !itv.inRange() {
+		return Time{}, false
+	}
+
+	usec := (((int64(itv.day)*24+int64(itv.hour))*60+int64(itv.min))*60+int64(itv.sec))*1e6 + int64(itv.usec)
+	if itv.neg {
+		usec = -usec
+	}
+	return newTimeFromMicroseconds(t.microseconds() + usec)
+}
+
+var intervalNames = [...]string{
+	IntervalYear:              "YEAR",
+	IntervalQuarter:           "QUARTER",
+	IntervalMonth:             "MONTH",
+	IntervalWeek:              "WEEK",
+	IntervalDay:               "DAY",
+	IntervalHour:              "HOUR",
+	IntervalMinute:            "MINUTE",
+	IntervalSecond:            "SECOND",
+	IntervalMicrosecond:       "MICROSECOND",
+	IntervalYearMonth:         "YEAR_MONTH",
+	IntervalDayHour:           "DAY_HOUR",
+	IntervalDayMinute:         "DAY_MINUTE",
+	IntervalDaySecond:         "DAY_SECOND",
+	IntervalHourMinute:        "HOUR_MINUTE",
+	IntervalHourSecond:        "HOUR_SECOND",
+	IntervalMinuteSecond:      "MINUTE_SECOND",
+	IntervalDayMicrosecond:    "DAY_MICROSECOND",
+	IntervalHourMicrosecond:   "HOUR_MICROSECOND",
+	IntervalMinuteMicrosecond: "MINUTE_MICROSECOND",
+	IntervalSecondMicrosecond: "SECOND_MICROSECOND",
+}
+
+// String returns the SQL name of the interval unit.
+func (itv IntervalType) String() string {
+	if int(itv) < len(intervalNames) && intervalNames[itv] != "" {
+		return intervalNames[itv]
+	}
+	return "[UNKNOWN INTERVAL]"
+}
+
+// ParseIntervalType returns the simple interval unit with the given SQL
+// name, as accepted by TIMESTAMPADD and TIMESTAMPDIFF. The legacy
+// SQL_TSI_ prefix is also accepted.
+func ParseIntervalType(name string) (IntervalType, bool) {
+	name = strings.ToUpper(name)
+	name = strings.TrimPrefix(name, "SQL_TSI_")
+	for itv := IntervalYear; itv <= IntervalMicrosecond; itv++ {
+		if intervalNames[itv] == name {
+			return itv, true
+		}
+	}
+	return IntervalNone, false
+}
@@ -0,0 +1,182 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package datetime
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDayNumber(t *testing.T) {
+	tests := []struct {
+		date  string
+		daynr int
+	}{
+		{"0001-01-01", 366},
+		{"1970-01-01", 719528},
+		{"2000-02-29", 730544},
+		{"2023-03-01", 738945},
+		{"9999-12-31", maxDayNumber},
+	}
+
+	for _, test := range tests {
+		t.Run(test.date, func(t *testing.T) {
+			d, ok := ParseDate(test.date)
+			require.True(t, ok)
+			assert.Equal(t, test.daynr, d.DayNumber())
+			assert.Equal(t, d, DateFromDayNumber(test.daynr))
+		})
+	}
+
+	assert.True(t, DateFromDayNumber(365).IsZero())
+	assert.True(t, DateFromDayNumber(3652500).IsZero())
+}
+
+func TestAddInterval(t *testing.T) {
+	tests := []struct {
+		input    string
+		interval string
+		unit     IntervalType
+		output   string
+	}{
+		{"2023-01-31 00:00:00", "1", IntervalMonth, "2023-02-28 00:00:00"},
+		{"2024-01-31 00:00:00", "1", IntervalMonth, "2024-02-29 00:00:00"},
+		{"2024-02-29 00:00:00", "1", IntervalYear, "2025-02-28 00:00:00"},
+		{"2023-12-31 23:59:59", "1", IntervalSecond, "2024-01-01 00:00:00"},
+		{"2023-01-01 00:00:00", "-1", IntervalSecond, "2022-12-31 23:59:59"},
+		{"2023-01-01 00:00:00", "1 2", IntervalDayHour, "2023-01-02 02:00:00"},
+		{"2023-01-01 00:00:00", "2", IntervalDayHour, "2023-01-01 02:00:00"},
+		{"2023-01-01 00:00:00", "1:1:1.5", IntervalHourMicrosecond, "2023-01-01 01:01:01.500000"},
+		{"2023-01-01 00:00:00", "1.000005", IntervalSecondMicrosecond, "2023-01-01 00:00:01.000005"},
+		{"2023-01-01 00:00:00", "5", IntervalSecondMicrosecond, "2023-01-01 00:00:00.000005"},
+		{"2023-01-01 00:00:00", "-1-2", IntervalYearMonth, "2021-11-01 00:00:00"},
+		{"2023-01-01 00:00:00", "2", IntervalQuarter, "2023-07-01 00:00:00"},
+		{"2023-01-01 00:00:00", "3", IntervalWeek, "2023-01-22 00:00:00"},
+		{"9999-12-31 00:00:00", "1", IntervalDay, ""},
+		{"0001-01-01 00:00:00", "-1", IntervalYear, "0000-01-01 00:00:00"},
+		{"0001-01-01 00:00:00", "-2", IntervalYear, ""},
+		{"2023-01-01 00:00:00", "1 2 3", IntervalDayHour, ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.input+" + "+test.interval, func(t *testing.T) {
+			dt, _, ok := ParseDateTime(test.input, -1)
+			require.True(t, ok)
+
+			var itv *Interval
+			if test.unit.IsCompound() {
+				itv = ParseInterval(test.interval, test.unit, false)
+			} else {
+				n, ok := atoi(test.interval)
+				require.True(t, ok)
+				itv = ParseIntervalInt64(int64(n), test.unit, false)
+			}
+			if itv == nil {
+				assert.Empty(t, test.output)
+				return
+			}
+
+			got, ok := dt.AddInterval(itv)
+			if test.output == "" {
+				assert.False(t, ok)
+				return
+			}
+			require.True(t, ok)
+			prec := uint8(0)
+			if got.Time.Nanosecond() != 0 {
+				prec = 6
+			}
+			assert.Equal(t, test.output, string(got.Format(prec)))
+		})
+	}
+}
+
+func TestTimestampDiff(t *testing.T) {
+	tests := []struct {
+		begin, end string
+		unit       IntervalType
+		diff       int64
+	}{
+		{"2023-01-31 00:00:00", "2023-02-28 00:00:00", IntervalMonth, 0},
+		{"2023-01-28 00:00:00", "2023-02-28 00:00:00", IntervalMonth, 1},
+		{"2023-01-28 10:00:00", "2023-02-28 09:59:59", IntervalMonth, 0},
+		{"2024-02-29 00:00:00", "2023-02-28 00:00:00", IntervalYear, -1},
+		{"2020-01-01 00:00:00", "2023-06-01 00:00:00", IntervalQuarter, 13},
+		{"2023-01-01 00:00:00", "2023-01-08 00:00:00", IntervalWeek, 1},
+		{"2023-01-01 12:00:00", "2023-01-02 11:59:59", IntervalDay, 0},
+		{"2023-01-01 00:00:01", "2023-01-01 00:00:00", IntervalSecond, -1},
+		{"2023-01-01 00:00:00", "2023-01-01 00:00:00.5", IntervalMicrosecond, 500000},
+	}
+
+	for _, test := range tests {
+		t.Run(test.begin+" "+test.end, func(t *testing.T) {
+			begin, _, ok := ParseDateTime(test.begin, -1)
+			require.True(t, ok)
+			end, _, ok := ParseDateTime(test.end, -1)
+			require.True(t, ok)
+			assert.Equal(t, test.diff, TimestampDiff(test.unit, begin, end))
+		})
+	}
+}
+
+func TestStrToDate(t *testing.T) {
+	tests := []struct {
+		value, format string
+		output        string
+	}{
+		{"2023-01-02", "%Y-%m-%d", "2023-01-02 00:00:00"},
+		{"  2023 - 1 - 2", "%Y-%m-%d", "2023-01-02 00:00:00"},
+		{"23-01-02", "%Y-%m-%d", "2023-01-02 00:00:00"},
+		{"01/02/99", "%m/%d/%y", "1999-01-02 00:00:00"},
+		{"May 3rd, 2013", "%M %D, %Y", "2013-05-03 00:00:00"},
+		{"sep 3 2013", "%b %e %Y", "2013-09-03 00:00:00"},
+		{"2023-01-02 10:11:12 PM", "%Y-%m-%d %r", "2023-01-02 22:11:12"},
+		{"2023-01-02 12:11:12 AM", "%Y-%m-%d %r", "2023-01-02 00:11:12"},
+		{"2023-01-02 10:11:12.5", "%Y-%m-%d %T.%f", "2023-01-02 10:11:12.500000"},
+		{"2023 032", "%Y %j", "2023-02-01 00:00:00"},
+		{"2023 1 Monday", "%Y %u %W", "2023-01-02 00:00:00"},
+		{"2023-01-02 trailing", "%Y-%m-%d", "2023-01-02 00:00:00"},
+		{"2023-01", "%Y-%m-%d", ""},
+		{"2023-02-30", "%Y-%m-%d", ""},
+		{"2023-13-01", "%Y-%m-%d", ""},
+		{"2023/01/02", "%Y-%m-%d", ""},
+		{"13:00:00 PM", "%h:%i:%s %p", ""},
+		{"Ju 1 2023", "%M %d %Y", ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.value+" "+test.format, func(t *testing.T) {
+			got, ok := StrToDate(test.value, test.format)
+			if test.output == "" {
+				assert.False(t, ok)
+				return
+			}
+			require.True(t, ok)
+			prec := uint8(0)
+			if got.Time.Nanosecond() != 0 {
+				prec = 6
+			}
+			assert.Equal(t, test.output, string(got.Format(prec)))
+		})
+	}
+
+	tm, ok := StrToTime("1 10:00:00", "%d %H:%i:%s")
+	require.True(t, ok)
+	assert.Equal(t, "34:00:00", string(tm.Format(0)))
+}
@@ -0,0 +1,304 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package datetime
+
+import "vitess.io/vitess/go/mysql/decimal"
+
+// maxDayNumber is the day number for 9999-12-31, the largest
+// date that MySQL can represent.
+const maxDayNumber = 3652424
+
+// MysqlDayNumber converts a date into an absolute day number.
+// This is an algorithm that has been reverse engineered from MySQL;
+// it counts the days since year 0 in the proleptic Gregorian calendar,
+// with MySQL's own handling of the (non-existent) leap years before
+// the calendar reform. It is used by TO_DAYS, DATEDIFF and all the
+// date arithmetic functions.
+func MysqlDayNumber(year, month, day int) int {
+	if year == 0 && month == 0 {
+		return 0
+	}
+
+	days := 365*year + 31*(month-1) + day
+	switch month {
+	case 1, 2:
+		year = year - 1
+	default:
+		days = days - (month*4+23)/10
+	}
+
+	divBy100 := (year/100 + 1) * 3 / 4
+	return days + year/4 - divBy100
+}
+
+// DateFromDayNumber converts an absolute day number, as returned by
+// MysqlDayNumber, back into a date. Day numbers outside the range
+// that MySQL supports are returned as the zero date.
+func DateFromDayNumber(daynr int) Date {
+	if daynr <= 365 || daynr >= 3652500 {
+		return Date{}
+	}
+
+	year := daynr * 100 / 36525
+	leapAdjust := ((year-1)/100 + 1) * 3 / 4
+	yday := (daynr - year*365) - (year-1)/4 + leapAdjust
+
+	diy := daysInYear(year)
+	for yday > diy {
+		yday -= diy
+		year++
+		diy = daysInYear(year)
+	}
+
+	leapDay := 0
+	if diy == 366 && yday > 31+28 {
+		yday--
+		if yday == 31+28 {
+			leapDay = 1
+		}
+	}
+
+	month := 1
+	for _, days := range daysInMonth {
+		if yday <= days {
+			break
+		}
+		yday -= days
+		month++
+	}
+
+	return Date{
+		year:  uint16(year),
+		month: uint8(month),
+		day:   uint8(yday + leapDay),
+	}
+}
+
+var daysInMonth = []int{31, 28, 31, 30, 31, 30, 31, 31, 30, 31, 30, 31}
+
+func daysInYear(year int) int {
+	if isLeap(year) {
+		return 366
+	}
+	return 365
+}
+
+// DayNumber returns the absolute day number for this date,
+// as returned by MysqlDayNumber.
+func (d Date) DayNumber() int {
+	return MysqlDayNumber(d.Year(), d.Month(), d.Day())
+}
+
+// LastDay returns the last day of the month for this date.
+// It returns false if the date has no month.
+func (d Date) LastDay() (Date, bool) {
+	if d.month == 0 || d.month > 12 {
+		return Date{}, false
+	}
+	d.day = uint8(daysInMonth[d.month-1])
+	if d.month == 2 && isLeap(int(d.year)) {
+		d.day = 29
+	}
+	return d, true
+}
+
+// Seconds returns the total number of seconds in this time value,
+// ignoring its fractional part. Negative times return a negative
+// number of seconds.
+func (t Time) Seconds() int64 {
+	s := int64(t.Hour())*3600 + int64(t.Minute())*60 + int64(t.Second())
+	if t.Neg() {
+		return -s
+	}
+	return s
+}
+
+// maxTimeSeconds is the number of seconds in 838:59:59, the
+// largest value that a MySQL TIME can hold.
+const maxTimeSeconds = 838*3600 + 59*60 + 59
+
+// maxTime returns the largest TIME value with the given sign.
+func maxTime(neg bool) Time {
+	t := Time{hour: 838, minute: 59, second: 59}
+	if neg {
+		t.hour |= negMask
+	}
+	return t
+}
+
+// newTimeFromMicroseconds builds a TIME value out of a signed number of
+// microseconds. It returns false if the value does not fit in a TIME.
+func newTimeFromMicroseconds(usec int64) (Time, bool) {
+	var neg bool
+	if usec < 0 {
+		neg = true
+		usec = -usec
+	}
+
+	secs := usec / 1e6
+	frac := usec % 1e6
+	if secs > maxTimeSeconds || (secs == maxTimeSeconds && frac > 0) {
+		return maxTime(neg), false
+	}
+
+	t := Time{
+		hour:       uint16(secs / 3600),
+		minute:     uint8(secs / 60 % 60),
+		second:     uint8(secs % 60),
+		nanosecond: uint32(frac * 1000),
+	}
+	if neg && usec != 0 {
+		t.hour |= negMask
+	}
+	return t, true
+}
+
+// microseconds returns the signed number of microseconds in this time value.
+func (t Time) microseconds() int64 {
+	usec := (int64(t.Hour())*3600+int64(t.Minute())*60+int64(t.Second()))*1e6 + int64(t.Nanosecond())/1000
+	if t.Neg() {
+		return -usec
+	}
+	return usec
+}
+
+// microseconds returns the number of microseconds since year 0 for this datetime.
+func (dt DateTime) microseconds() int64 {
+	return int64(dt.Date.DayNumber())*86400*1e6 + dt.Time.microseconds()
+}
+
+// newDateTimeFromMicroseconds is the inverse of DateTime.microseconds.
+// It returns false if the resulting date is out of range.
+func newDateTimeFromMicroseconds(usec int64) (DateTime, bool) {
+	if usec < 0 {
+		return DateTime{}, false
+	}
+	days := usec / (86400 * 1e6)
+	usec = usec % (86400 * 1e6)
+	if days > maxDayNumber {
+		return DateTime{}, false
+	}
+
+	d := DateFromDayNumber(int(days))
+	if d.IsZero() {
+		return DateTime{}, false
+	}
+	t, _ := newTimeFromMicroseconds(usec)
+	return DateTime{Date: d, Time: t}, true
+}
+
+// NewTimeFromSeconds returns a TIME value for the given number of seconds,
+// as SEC_TO_TIME does. Values out of range are clamped to the largest
+// possible TIME with the same sign.
+func NewTimeFromSeconds(seconds decimal.Decimal) Time {
+	sec, frac := seconds.QuoRem(decimal.New(1, 0), 0)
+	s, ok := sec.Int64()
+	if !ok || s > maxTimeSeconds || s < -maxTimeSeconds {
+		return maxTime(seconds.Sign() < 0)
+	}
+
+	ns, _ := frac.Mul(decimal.New(1, 9)).Int64()
+	neg := s < 0 || ns < 0
+	if s < 0 {
+		s = -s
+	}
+	if ns < 0 {
+		ns = -ns
+	}
+
+	t := Time{
+		hour:       uint16(s / 3600),
+		minute:     uint8(s / 60 % 60),
+		second:     uint8(s % 60),
+		nanosecond: uint32(ns),
+	}
+	if neg {
+		t.hour |= negMask
+	}
+	return t
+}
+
+// AddTime adds the TIME value t2 to t, or subtracts it if sub is set,
+// like MySQL's ADDTIME and SUBTIME do. Results out of range are clamped
+// to the largest TIME with the same sign.
+func (t Time) AddTime(t2 Time, sub bool) Time {
+	usec2 := t2.microseconds()
+	if sub {
+		usec2 = -usec2
+	}
+	r, _ := newTimeFromMicroseconds(t.microseconds() + usec2)
+	return r
+}
+
+// AddTime adds the TIME value t2 to dt, or subtracts it if sub is set,
+// like MySQL's ADDTIME and SUBTIME do. It returns false if the result
+// does not fit in a DATETIME.
+func (dt DateTime) AddTime(t2 Time, sub bool) (DateTime, bool) {
+	usec2 := t2.microseconds()
+	if sub {
+		usec2 = -usec2
+	}
+	return newDateTimeFromMicroseconds(dt.microseconds() + usec2)
+}
+
+// TimestampDiff returns the difference end - begin in the given unit,
+// with the same semantics as MySQL's TIMESTAMPDIFF: only complete
+// units are counted, and the result is truncated towards zero.
+func TimestampDiff(unit IntervalType, begin, end DateTime) int64 {
+	usec := end.microseconds() - begin.microseconds()
+	sign := int64(1)
+	if usec < 0 {
+		sign = -1
+		usec = -usec
+		begin, end = end, begin
+	}
+	seconds := usec / 1e6
+
+	switch unit {
+	case IntervalYear, IntervalQuarter, IntervalMonth:
+		months := int64(end.Date.Month() - begin.Date.Month())
+		months += 12 * int64(end.Date.Year()-begin.Date.Year())
+
+		day1, day2 := begin.Date.Day(), end.Date.Day()
+		if day2 < day1 || (day2 == day1 && end.Time.microseconds() < begin.Time.microseconds()) {
+			months--
+		}
+
+		switch unit {
+		case IntervalYear:
+			return sign * (months / 12)
+		case IntervalQuarter:
+			return sign * (months / 3)
+		default:
+			return sign * months
+		}
+	case IntervalWeek:
+		return sign * (seconds / 86400 / 7)
+	case IntervalDay:
+		return sign * (seconds / 86400)
+	case IntervalHour:
+		return sign * (seconds / 3600)
+	case IntervalMinute:
+		return sign * (seconds / 60)
+	case IntervalSecond:
+		return sign * seconds
+	case IntervalMicrosecond:
+		return sign * usec
+	default:
+		return 0
+	}
+}
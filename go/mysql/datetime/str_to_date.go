@@ -0,0 +1,431 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package datetime
+
+import (
+	"strings"
+	"time"
+)
+
+// StrToDateType returns the parts of a temporal value that a STR_TO_DATE
+// format string can fill in. This is used to decide the return type of
+// STR_TO_DATE: a format with date parts returns a DATE or a DATETIME,
+// and a format with only time parts returns a TIME. If the format uses
+// %f, the result has microsecond precision.
+func StrToDateType(format string) (hasDate, hasTime, hasFrac bool) {
+	const timeSpecs = "HISThiklrs"
+	const dateSpecs = "MVUXYWabcjmvuxyw"
+
+	for i := 0; i < len(format)-1; i++ {
+		if format[i] != '%' {
+			continue
+		}
+		i++
+		switch c := format[i]; {
+		case c == 'f':
+			hasFrac = true
+			hasTime = true
+		case strings.IndexByte(timeSpecs, c) >= 0:
+			hasTime = true
+		case strings.IndexByte(dateSpecs, c) >= 0:
+			hasDate = true
+		}
+	}
+	return
+}
+
+// strtodate holds the intermediate state while parsing
+// a value with a STR_TO_DATE format.
+type strtodate struct {
+	timeparts
+
+	weekday        int
+	yearday        int
+	daypart        int
+	weekNumber     int
+	weekYear       int
+	usaTime        bool
+	sundayFirst    bool
+	strictWeek     bool
+	strictWeekYear bool
+}
+
+var ampmFormat = "%I:%i:%S %p"
+var hours24Format = "%H:%i:%S"
+
+// strtoll parses an unsigned integer of at most width digits.
+func strtoll(s string, width int) (int, string, bool) {
+	if width > len(s) {
+		width = len(s)
+	}
+	var n, i int
+	for ; i < width && isDigitByte(s[i]); i++ {
+		n = n*10 + int(s[i]-'0')
+	}
+	if i == 0 {
+		return 0, s, false
+	}
+	return n, s[i:], true
+}
+
+// checkWord looks up the alphabetic word at the start of s in the given
+// list of names, accepting any unique prefix. It returns the 1-based
+// position of the matching name.
+func checkWord(names []string, s string) (int, string, bool) {
+	var end int
+	for end < len(s) && isAlpha(s[end]) {
+		end++
+	}
+	word := s[:end]
+	if len(word) == 0 {
+		return 0, s, false
+	}
+
+	found := 0
+	for i, name := range names {
+		if len(name) < len(word) || !match(name[:len(word)], word) {
+			continue
+		}
+		if len(name) == len(word) {
+			return i + 1, s[end:], true
+		}
+		if found != 0 {
+			// ambiguous prefix
+			return 0, s, false
+		}
+		found = i + 1
+	}
+	if found == 0 {
+		return 0, s, false
+	}
+	return found, s[end:], true
+}
+
+func isAlpha(c byte) bool {
+	return ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z')
+}
+
+func isPunct(c byte) bool {
+	return c > ' ' && c < 0x7f && !isAlpha(c) && !isDigitByte(c)
+}
+
+var weekdayNames = []string{"Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday", "Sunday"}
+var weekdayAbbrev = []string{"Mon", "Tue", "Wed", "Thu", "Fri", "Sat", "Sun"}
+
+var monthNames []string
+var monthAbbrev []string
+
+func init() {
+	for m := time.January; m <= time.December; m++ {
+		monthNames = append(monthNames, m.String())
+		monthAbbrev = append(monthAbbrev, m.String()[:3])
+	}
+}
+
+func year2000(y int) int {
+	if y < 70 {
+		return y + 2000
+	}
+	return y + 1900
+}
+
+// extract parses value using format, following MySQL's extract_date_time.
+// It returns the unparsed remainder of value.
+func (st *strtodate) extract(format, value string) (string, bool) {
+	var ok bool
+
+	for len(format) > 0 && len(value) > 0 {
+		for len(value) > 0 && isSpace(value[0]) {
+			value = value[1:]
+		}
+		if len(value) == 0 {
+			break
+		}
+
+		if format[0] != '%' || len(format) == 1 {
+			if !isSpace(format[0]) {
+				if value[0] != format[0] {
+					return value, false
+				}
+				value = value[1:]
+			}
+			format = format[1:]
+			continue
+		}
+
+		spec := format[1]
+		format = format[2:]
+
+		switch spec {
+		case 'Y':
+			start := len(value)
+			if st.year, value, ok = strtoll(value, 4); !ok {
+				return value, false
+			}
+			if start-len(value) <= 2 {
+				st.year = year2000(st.year)
+			}
+		case 'y':
+			if st.year, value, ok = strtoll(value, 2); !ok {
+				return value, false
+			}
+			st.year = year2000(st.year)
+		case 'm', 'c':
+			if st.month, value, ok = strtoll(value, 2); !ok {
+				return value, false
+			}
+		case 'M':
+			if st.month, value, ok = checkWord(monthNames, value); !ok {
+				return value, false
+			}
+		case 'b':
+			if st.month, value, ok = checkWord(monthAbbrev, value); !ok {
+				return value, false
+			}
+		case 'd', 'e':
+			if st.day, value, ok = strtoll(value, 2); !ok {
+				return value, false
+			}
+		case 'D':
+			if st.day, value, ok = strtoll(value, 2); !ok {
+				return value, false
+			}
+			// skip the 'st', 'nd', 'th' suffix
+			if len(value) > 2 {
+				value = value[2:]
+			} else {
+				value = ""
+			}
+		case 'h', 'I', 'l':
+			st.usaTime = true
+			fallthrough
+		case 'k', 'H':
+			if st.hour, value, ok = strtoll(value, 2); !ok {
+				return value, false
+			}
+		case 'i':
+			if st.min, value, ok = strtoll(value, 2); !ok {
+				return value, false
+			}
+		case 's', 'S':
+			if st.sec, value, ok = strtoll(value, 2); !ok {
+				return value, false
+			}
+		case 'f':
+			start := len(value)
+			var usec int
+			if usec, value, ok = strtoll(value, 6); !ok {
+				return value, false
+			}
+			for digits := start - len(value); digits < 6; digits++ {
+				usec *= 10
+			}
+			st.nsec = usec * 1000
+		case 'p':
+			if len(value) < 2 || !st.usaTime {
+				return value, false
+			}
+			switch {
+			case match(value[:2], "PM"):
+				st.daypart = 12
+			case match(value[:2], "AM"):
+			default:
+				return value, false
+			}
+			value = value[2:]
+		case 'W':
+			if st.weekday, value, ok = checkWord(weekdayNames, value); !ok {
+				return value, false
+			}
+		case 'a':
+			if st.weekday, value, ok = checkWord(weekdayAbbrev, value); !ok {
+				return value, false
+			}
+		case 'w':
+			if st.weekday, value, ok = strtoll(value, 1); !ok || st.weekday >= 7 {
+				return value, false
+			}
+			if st.weekday == 0 {
+				st.weekday = 7
+			}
+		case 'j':
+			if st.yearday, value, ok = strtoll(value, 3); !ok {
+				return value, false
+			}
+		case 'V', 'U', 'v', 'u':
+			st.sundayFirst = spec == 'U' || spec == 'V'
+			st.strictWeek = spec == 'V' || spec == 'v'
+			if st.weekNumber, value, ok = strtoll(value, 2); !ok {
+				return value, false
+			}
+			if (st.strictWeek && st.weekNumber == 0) || st.weekNumber > 53 {
+				return value, false
+			}
+		case 'X', 'x':
+			st.strictWeekYear = spec == 'X'
+			if st.weekYear, value, ok = strtoll(value, 4); !ok {
+				return value, false
+			}
+		case 'r':
+			if value, ok = st.extract(ampmFormat, value); !ok {
+				return value, false
+			}
+		case 'T':
+			if value, ok = st.extract(hours24Format, value); !ok {
+				return value, false
+			}
+		case '.':
+			for len(value) > 0 && isPunct(value[0]) {
+				value = value[1:]
+			}
+		case '@':
+			for len(value) > 0 && isAlpha(value[0]) {
+				value = value[1:]
+			}
+		case '#':
+			for len(value) > 0 && isDigitByte(value[0]) {
+				value = value[1:]
+			}
+		default:
+			return value, false
+		}
+	}
+
+	if st.usaTime {
+		if st.hour > 12 || st.hour < 1 {
+			return value, false
+		}
+		st.hour = st.hour%12 + st.daypart
+		st.usaTime = false
+	}
+	return value, true
+}
+
+// calcWeekday returns the day of the week for the given day number,
+// with 0 being the first day of the week.
+func calcWeekday(daynr int, sundayFirst bool) int {
+	daynr += 5
+	if sundayFirst {
+		daynr++
+	}
+	return daynr % 7
+}
+
+func (st *strtodate) resolve() bool {
+	if st.yearday > 0 {
+		days := MysqlDayNumber(st.year, 1, 1) + st.yearday - 1
+		if days <= 0 || days > maxDayNumber {
+			return false
+		}
+		st.setDate(DateFromDayNumber(days))
+	}
+
+	if st.weekNumber >= 0 && st.weekday > 0 {
+		if st.strictWeek && (st.weekYear < 0 || st.strictWeekYear != st.sundayFirst) {
+			return false
+		}
+		if !st.strictWeek && st.weekYear >= 0 {
+			return false
+		}
+
+		year := st.year
+		if st.strictWeek {
+			year = st.weekYear
+		}
+		days := MysqlDayNumber(year, 1, 1)
+		weekdayB := calcWeekday(days, st.sundayFirst)
+
+		if st.sundayFirst {
+			if weekdayB != 0 {
+				days += 7
+			}
+			days += -weekdayB + (st.weekNumber-1)*7 + st.weekday%7
+		} else {
+			if weekdayB > 3 {
+				days += 7
+			}
+			days += -weekdayB + (st.weekNumber-1)*7 + (st.weekday - 1)
+		}
+
+		if days <= 0 || days > maxDayNumber {
+			return false
+		}
+		st.setDate(DateFromDayNumber(days))
+	}
+
+	return st.month <= 12 && st.day <= 31 && st.hour <= 23 && st.min <= 59 && st.sec <= 59
+}
+
+func (st *strtodate) setDate(d Date) {
+	st.year = d.Year()
+	st.month = d.Month()
+	st.day = d.Day()
+}
+
+func parseStrToDate(value, format string) (*strtodate, bool) {
+	st := &strtodate{weekNumber: -1, weekYear: -1}
+	if _, ok := st.extract(format, value); !ok {
+		return nil, false
+	}
+	if !st.resolve() {
+		return nil, false
+	}
+	return st, true
+}
+
+// StrToDate parses value as a DATETIME following the given STR_TO_DATE
+// format. Like MySQL, parsing stops when the value is exhausted, and any
+// trailing characters in the value that don't match the format are ignored.
+// It returns false if the value doesn't match the format, or if the
+// resulting date has zero parts or is not a valid calendar date.
+func StrToDate(value, format string) (DateTime, bool) {
+	st, ok := parseStrToDate(value, format)
+	if !ok {
+		return DateTime{}, false
+	}
+	if st.year > 9999 || st.month == 0 || st.day == 0 || st.day > daysIn(time.Month(st.month), st.year) {
+		return DateTime{}, false
+	}
+	return DateTime{
+		Date: Date{
+			year:  uint16(st.year),
+			month: uint8(st.month),
+			day:   uint8(st.day),
+		},
+		Time: Time{
+			hour:       uint16(st.hour),
+			minute:     uint8(st.min),
+			second:     uint8(st.sec),
+			nanosecond: uint32(st.nsec),
+		},
+	}, true
+}
+
+// StrToTime parses value as a TIME following the given STR_TO_DATE
+// format. Any days in the value are added to the hours of the result.
+func StrToTime(value, format string) (Time, bool) {
+	st, ok := parseStrToDate(value, format)
+	if !ok {
+		return Time{}, false
+	}
+	return Time{
+		hour:       uint16(st.day*24 + st.hour),
+		minute:     uint8(st.min),
+		second:     uint8(st.sec),
+		nanosecond: uint32(st.nsec),
+	}, true
+}
@@ -40,7 +40,8 @@ func AppendFloat(buf []byte, f float64) []byte {
 	// redundant positive sign manually
 	// e.g. 1.234E+56789 -> 1.234E56789
 	fstr := strconv.AppendFloat(buf, f, format, -1, 64)
-	if idx := bytes.IndexByte(fstr, 'e'); idx >= 0 {
+	if idx := bytes.IndexByte(fstr[len(buf):], 'e'); idx >= 0 {
+		idx += len(buf)
 		if fstr[idx+1] == '+' {
 			fstr = append(fstr[:idx+1], fstr[idx+2:]...)
 		}
@@ -218,41 +218,62 @@ func (jp *Path) Match(doc *Value, wrap bool, match func(value *Value)) {
 	m.value(jp, doc)
 }
 
-func (jp *Path) transform(v *Value, t func(pp *Path, vv *Value)) {
-	if v == nil {
-		return
+// Search returns the paths to all the string values in doc for which match
+// returns true, in document order, as JSON_SEARCH does. If scopes is not empty,
+// only the values located under any of the given paths are considered. If one
+// is set, the search stops after the first match.
+func Search(doc *Value, scopes []*Path, one bool, match func(s []byte) bool) []string {
+	s := searcher{match: match, one: one}
+	if len(scopes) > 0 {
+		s.scopes = make(map[*Value]struct{})
+		for _, jp := range scopes {
+			jp.Match(doc, false, func(v *Value) {
+				s.scopes[v] = struct{}{}
+			})
+		}
 	}
-	if jp.next == nil {
-		t(jp, v)
-		return
+	s.search(doc, []byte{'$'}, len(scopes) == 0)
+	return s.paths
+}
+
+type searcher struct {
+	scopes map[*Value]struct{}
+	match  func(s []byte) bool
+	one    bool
+	paths  []string
+}
+
+func (s *searcher) search(v *Value, path []byte, inScope bool) bool {
+	if !inScope {
+		_, inScope = s.scopes[v]
 	}
-	switch jp.kind {
-	case jpDocumentRoot:
-		jp.next.transform(v, t)
-	case jpMember:
-		if obj, ok := v.Object(); ok {
-			jp.next.transform(obj.Get(jp.name), t)
+	switch v.Type() {
+	case TypeString:
+		if b, _ := v.StringBytes(); inScope && s.match(b) {
+			s.paths = append(s.paths, string(path))
+			return s.one
 		}
-	case jpArrayLocation:
-		if ary, ok := v.Array(); ok {
-			from, to := jp.arrayOffsets(ary)
-			if from != to {
-				panic("range in transformation path expression")
+	case TypeArray:
+		for i, elem := range v.a {
+			p := append(strconv.AppendInt(append(path, '['), int64(i), 10), ']')
+			if s.search(elem, p, inScope) {
+				return true
 			}
-			if from >= 0 && from < len(ary) {
-				jp.next.transform(ary[from], t)
+		}
+	case TypeObject:
+		for _, kv := range v.o.kvs {
+			p := append(path, '.')
+			if jpIsIdentifier(kv.k) {
+				p = append(p, kv.k...)
+			} else {
+				p = strconv.AppendQuote(p, kv.k)
+			}
+			if s.search(kv.v, p, inScope) {
+				return true
 			}
-		} else if jp.offset0 == 0 || jp.offset0 == -1 {
-			/*
-				If the path is evaluated against a value that is not an array,
-				the result of the evaluation is the same as if the value had been
-				wrapped in a single-element array:
-			*/
-			jp.next.transform(v, t)
 		}
-	case jpMemberAny, jpArrayLocationAny, jpAny:
-		panic("wildcard in transformation path expression")
 	}
+	return false
 }
 
 type Transformation int
@@ -262,42 +283,10 @@ const (
 	Insert
 	Replace
 	Remove
+	ArrayAppend
+	ArrayInsert
 )
 
-func ApplyTransform(t Transformation, doc *Value, paths []*Path, values []*Value) error {
-	if t != Remove && len(paths) != len(values) {
-		panic("missing Values for transformation")
-	}
-	for i, p := range paths {
-		transform := func(pp *Path, vv *Value) {
-			switch pp.kind {
-			case jpArrayLocation:
-				if ary, ok := vv.Array(); ok {
-					from, to := pp.arrayOffsets(ary)
-					if from != to {
-						return
-					}
-					if t == Remove {
-						vv.DelArrayItem(from)
-					} else {
-						vv.SetArrayItem(from, values[i], t)
-					}
-				}
-			case jpMember:
-				if obj, ok := vv.Object(); ok {
-					if t == Remove {
-						obj.Del(pp.name)
-					} else {
-						obj.Set(pp.name, values[i], t)
-					}
-				}
-			}
-		}
-		p.transform(doc, transform)
-	}
-	return nil
-}
-
 func MatchPath(rawJSON, rawPath []byte, match func(value *Value)) error {
 	var p1 Parser
 	doc, err := p1.ParseBytes(rawJSON)
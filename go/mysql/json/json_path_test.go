@@ -177,24 +177,144 @@ func TestTransformations(t *testing.T) {
 	for _, tc := range cases {
 		doc := json(t, tc.Document)
 
-		var paths []*Path
-		for _, p := range tc.Paths {
-			paths = append(paths, path(t, p))
+		for i, p := range tc.Paths {
+			var value *Value
+			if i < len(tc.Values) {
+				value = json(t, tc.Values[i])
+			}
+
+			var err error
+			doc, err = Transform(tc.T, doc, path(t, p), value)
+			if err != nil {
+				t.Fatal(err)
+			}
 		}
 
-		var values []*Value
-		for _, v := range tc.Values {
-			values = append(values, json(t, v))
+		result := string(doc.MarshalTo(nil))
+		if result != tc.Expected {
+			t.Errorf("bad transformation (%v)\nwant: %s\ngot:  %s", tc.T, tc.Expected, result)
+		}
+	}
+}
+
+func TestTransform(t *testing.T) {
+	cases := []struct {
+		T        Transformation
+		Document string
+		Path     string
+		Value    string
+		Expected string
+		Error    string
+	}{
+		{T: Set, Document: `{"a": 1}`, Path: `$.a`, Value: `10`, Expected: `{"a": 10}`},
+		{T: Set, Document: `{"a": 1}`, Path: `$.b`, Value: `[true]`, Expected: `{"a": 1, "b": [true]}`},
+		{T: Insert, Document: `{"a": 1}`, Path: `$.a`, Value: `10`, Expected: `{"a": 1}`},
+		{T: Insert, Document: `{"a": 1}`, Path: `$.c`, Value: `10`, Expected: `{"a": 1, "c": 10}`},
+		{T: Replace, Document: `{"a": 1}`, Path: `$.c`, Value: `10`, Expected: `{"a": 1}`},
+		{T: Replace, Document: `{"a": 1}`, Path: `$`, Value: `10`, Expected: `10`},
+		{T: Set, Document: `{"a": {"b": 1}}`, Path: `$.a.c.d`, Value: `10`, Expected: `{"a": {"b": 1}}`},
+		{T: Set, Document: `[1, 2]`, Path: `$[5]`, Value: `3`, Expected: `[1, 2, 3]`},
+		{T: Set, Document: `[1, 2]`, Path: `$[last]`, Value: `3`, Expected: `[1, 3]`},
+		{T: Insert, Document: `[1, 2]`, Path: `$[0]`, Value: `3`, Expected: `[1, 2]`},
+		{T: Replace, Document: `[1, 2]`, Path: `$[2]`, Value: `3`, Expected: `[1, 2]`},
+		{T: Set, Document: `1`, Path: `$[1]`, Value: `2`, Expected: `[1, 2]`},
+		{T: Set, Document: `1`, Path: `$[0]`, Value: `2`, Expected: `2`},
+		{T: Insert, Document: `{"a": 1}`, Path: `$[1]`, Value: `2`, Expected: `[{"a": 1}, 2]`},
+		{T: Set, Document: `{"a": [1, {"b": 2}]}`, Path: `$.a[1].c`, Value: `3`, Expected: `{"a": [1, {"b": 2, "c": 3}]}`},
+		{T: Remove, Document: `{"a": [1, 2, 3]}`, Path: `$.a[1]`, Expected: `{"a": [1, 3]}`},
+		{T: Remove, Document: `{"a": 1, "b": 2}`, Path: `$.a`, Expected: `{"b": 2}`},
+		{T: Remove, Document: `{"a": 1}`, Path: `$`, Error: "The path expression '$' is not allowed in this context."},
+		{T: ArrayAppend, Document: `["a", ["b", "c"], "d"]`, Path: `$[1]`, Value: `1`, Expected: `["a", ["b", "c", 1], "d"]`},
+		{T: ArrayAppend, Document: `["a", ["b", "c"], "d"]`, Path: `$[0]`, Value: `2`, Expected: `[["a", 2], ["b", "c"], "d"]`},
+		{T: ArrayAppend, Document: `{"a": 1}`, Path: `$`, Value: `2`, Expected: `[{"a": 1}, 2]`},
+		{T: ArrayInsert, Document: `["a", {"b": [1, 2]}, [3, 4]]`, Path: `$[1]`, Value: `"x"`, Expected: `["a", "x", {"b": [1, 2]}, [3, 4]]`},
+		{T: ArrayInsert, Document: `["a", {"b": [1, 2]}, [3, 4]]`, Path: `$[100]`, Value: `"x"`, Expected: `["a", {"b": [1, 2]}, [3, 4], "x"]`},
+		{T: ArrayInsert, Document: `["a", {"b": [1, 2]}, [3, 4]]`, Path: `$[1].b[0]`, Value: `"x"`, Expected: `["a", {"b": ["x", 1, 2]}, [3, 4]]`},
+		{T: ArrayInsert, Document: `["a"]`, Path: `$.b`, Value: `"x"`, Error: "A path expression is not a path to a cell in an array."},
+	}
+
+	for _, tc := range cases {
+		doc := json(t, tc.Document)
+		before := string(doc.MarshalTo(nil))
+
+		var value *Value
+		if tc.Value != "" {
+			value = json(t, tc.Value)
 		}
 
-		err := ApplyTransform(tc.T, doc, paths, values)
+		result, err := Transform(tc.T, doc, path(t, tc.Path), value)
+		if tc.Error != "" {
+			if err == nil || err.Error() != tc.Error {
+				t.Errorf("bad error for %s: want %q, got %v", tc.Path, tc.Error, err)
+			}
+			continue
+		}
 		if err != nil {
 			t.Fatal(err)
 		}
 
-		result := string(doc.MarshalTo(nil))
-		if result != tc.Expected {
-			t.Errorf("bad transformation (%v)\nwant: %s\ngot:  %s", tc.T, tc.Expected, result)
+		if got := string(result.MarshalTo(nil)); got != tc.Expected {
+			t.Errorf("bad transformation (%v) %s\nwant: %s\ngot:  %s", tc.T, tc.Path, tc.Expected, got)
+		}
+		if after := string(doc.MarshalTo(nil)); after != before {
+			t.Errorf("transformation modified the input document\nbefore: %s\nafter:  %s", before, after)
+		}
+	}
+}
+
+func TestMerge(t *testing.T) {
+	cases := []struct {
+		A, B     string
+		Preserve string
+		Patch    string
+	}{
+		{A: `[1, 2]`, B: `[true, false]`, Preserve: `[1, 2, true, false]`, Patch: `[true, false]`},
+		{A: `{"name": "x"}`, B: `{"id": 47}`, Preserve: `{"id": 47, "name": "x"}`, Patch: `{"id": 47, "name": "x"}`},
+		{A: `1`, B: `true`, Preserve: `[1, true]`, Patch: `true`},
+		{A: `[1, 2]`, B: `{"id": 47}`, Preserve: `[1, 2, {"id": 47}]`, Patch: `{"id": 47}`},
+		{A: `{"a": 1, "b": 2}`, B: `{"a": 3, "c": 4}`, Preserve: `{"a": [1, 3], "b": 2, "c": 4}`, Patch: `{"a": 3, "b": 2, "c": 4}`},
+		{A: `{"a": 1, "b": 2}`, B: `{"b": null}`, Preserve: `{"a": 1, "b": [2, null]}`, Patch: `{"a": 1}`},
+		{A: `{"a": {"x": 1}}`, B: `{"a": {"y": 2, "z": null}}`, Preserve: `{"a": {"x": 1, "y": 2, "z": null}}`, Patch: `{"a": {"x": 1, "y": 2}}`},
+	}
+
+	for _, tc := range cases {
+		if got := string(MergePreserve(json(t, tc.A), json(t, tc.B)).MarshalTo(nil)); got != tc.Preserve {
+			t.Errorf("MergePreserve(%s, %s): want %s, got %s", tc.A, tc.B, tc.Preserve, got)
+		}
+		if got := string(MergePatch(json(t, tc.A), json(t, tc.B)).MarshalTo(nil)); got != tc.Patch {
+			t.Errorf("MergePatch(%s, %s): want %s, got %s", tc.A, tc.B, tc.Patch, got)
+		}
+	}
+}
+
+func TestSearch(t *testing.T) {
+	doc := json(t, `["abc", [{"k": "10"}, "def"], {"x": "abc"}, {"y": "bcd"}, {"a b": "abc"}]`)
+	equals := func(want string) func([]byte) bool {
+		return func(s []byte) bool { return string(s) == want }
+	}
+
+	cases := []struct {
+		Match    func([]byte) bool
+		Scopes   []string
+		One      bool
+		Expected []string
+	}{
+		{Match: equals("abc"), One: true, Expected: []string{`$[0]`}},
+		{Match: equals("abc"), Expected: []string{`$[0]`, `$[2].x`, `$[4]."a b"`}},
+		{Match: equals("10"), Expected: []string{`$[1][0].k`}},
+		{Match: equals("abc"), Scopes: []string{`$[2]`}, Expected: []string{`$[2].x`}},
+		{Match: equals("abc"), Scopes: []string{`$[*].x`, `$[0]`}, Expected: []string{`$[0]`, `$[2].x`}},
+		{Match: equals("def"), Scopes: []string{`$**.k`}},
+	}
+
+	for _, tc := range cases {
+		var scopes []*Path
+		for _, s := range tc.Scopes {
+			scopes = append(scopes, path(t, s))
+		}
+		got := Search(doc, scopes, tc.One, tc.Match)
+		if !slices.Equal(got, tc.Expected) {
+			t.Errorf("bad search result (scopes %v): want %v, got %v", tc.Scopes, tc.Expected, got)
 		}
 	}
 }
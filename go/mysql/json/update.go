@@ -17,7 +17,12 @@ limitations under the License.
 
 package json
 
-import "golang.org/x/exp/slices"
+import (
+	"golang.org/x/exp/slices"
+
+	"vitess.io/vitess/go/vt/proto/vtrpc"
+	"vitess.io/vitess/go/vt/vterrors"
+)
 
 // Del deletes the entry with the given key from o.
 func (o *Object) Del(key string) {
@@ -96,3 +101,245 @@ func (v *Value) DelArrayItem(n int) {
 	}
 	v.a = append(v.a[:n], v.a[n+1:]...)
 }
+
+var errVacuousPath = vterrors.Errorf(vtrpc.Code_INVALID_ARGUMENT, "The path expression '$' is not allowed in this context.")
+var errNotArrayCell = vterrors.Errorf(vtrpc.Code_INVALID_ARGUMENT, "A path expression is not a path to a cell in an array.")
+
+// Transform applies the transformation t with the given value at the location
+// of jp in doc, and returns the resulting document. doc is never modified:
+// the values along the path are copied as needed, so the input document can
+// be safely shared. The path must not contain wildcards.
+//
+// The semantics follow MySQL's JSON_SET, JSON_INSERT, JSON_REPLACE, JSON_REMOVE,
+// JSON_ARRAY_APPEND and JSON_ARRAY_INSERT: paths that do not exist in the
+// document are ignored, and scalars or objects are treated as single-element
+// arrays when the last leg of the path is an array location.
+func Transform(t Transformation, doc *Value, jp *Path, value *Value) (*Value, error) {
+	if value == nil {
+		value = ValueNull
+	}
+	if t == ArrayAppend {
+		return rewrite(doc, jp, func(v *Value) *Value {
+			if ary, ok := v.Array(); ok {
+				return NewArray(append(slices.Clip(ary), value))
+			}
+			return NewArray([]*Value{v, value})
+		}), nil
+	}
+
+	parent, last := jp.splitLast()
+	if last == nil {
+		switch t {
+		case Set, Replace:
+			return value, nil
+		case Remove:
+			return nil, errVacuousPath
+		case ArrayInsert:
+			return nil, errNotArrayCell
+		default:
+			return doc, nil
+		}
+	}
+	if t == ArrayInsert && last.kind != jpArrayLocation {
+		return nil, errNotArrayCell
+	}
+
+	return rewrite(doc, parent, func(v *Value) *Value {
+		switch last.kind {
+		case jpMember:
+			return transformMember(t, v, last.name, value)
+		case jpArrayLocation:
+			return transformArrayItem(t, v, last, value)
+		default:
+			return v
+		}
+	}), nil
+}
+
+// splitLast returns a copy of jp without its last leg, together with the last
+// leg. If jp only contains the document root, the returned leg is nil.
+func (jp *Path) splitLast() (*Path, *Path) {
+	root := &Path{kind: jp.kind}
+	cur := root
+	for p := jp.next; p != nil; p = p.next {
+		if p.next == nil {
+			return root, p
+		}
+		cur = cur.push(&Path{kind: p.kind, offset0: p.offset0, offset1: p.offset1, name: p.name})
+	}
+	return root, nil
+}
+
+// rewrite follows jp in v and replaces the value at the end of the path with
+// the result of f. The values along the path are copied, so v is not modified.
+// If the path does not exist in v, or f returns its input, v is returned as is.
+func rewrite(v *Value, jp *Path, f func(v *Value) *Value) *Value {
+	if jp == nil {
+		return f(v)
+	}
+	switch jp.kind {
+	case jpDocumentRoot:
+		return rewrite(v, jp.next, f)
+	case jpMember:
+		obj, ok := v.Object()
+		if !ok {
+			return v
+		}
+		child := obj.Get(jp.name)
+		if child == nil {
+			return v
+		}
+		if updated := rewrite(child, jp.next, f); updated != child {
+			return withMember(obj, jp.name, updated)
+		}
+	case jpArrayLocation:
+		ary, ok := v.Array()
+		if !ok {
+			if jp.offset0 == 0 || jp.offset0 == -1 {
+				return rewrite(v, jp.next, f)
+			}
+			return v
+		}
+		idx, _ := jp.arrayOffsets(ary)
+		if idx < 0 || idx >= len(ary) {
+			return v
+		}
+		if updated := rewrite(ary[idx], jp.next, f); updated != ary[idx] {
+			ary = slices.Clone(ary)
+			ary[idx] = updated
+			return NewArray(ary)
+		}
+	}
+	return v
+}
+
+func withMember(obj *Object, key string, value *Value) *Value {
+	cpy := Object{kvs: slices.Clone(obj.kvs)}
+	cpy.Set(key, value, Set)
+	return &Value{o: cpy, t: TypeObject}
+}
+
+func transformMember(t Transformation, v *Value, key string, value *Value) *Value {
+	obj, ok := v.Object()
+	if !ok {
+		return v
+	}
+	_, found := obj.find(key)
+	switch {
+	case t == Set, t == Insert && !found, t == Replace && found:
+		return withMember(obj, key, value)
+	case t == Remove && found:
+		cpy := Object{kvs: slices.Clone(obj.kvs)}
+		cpy.Del(key)
+		return &Value{o: cpy, t: TypeObject}
+	default:
+		return v
+	}
+}
+
+func transformArrayItem(t Transformation, v *Value, jp *Path, value *Value) *Value {
+	ary, ok := v.Array()
+	if !ok {
+		// Any value that is not an array is treated as a single-element array
+		// containing the value itself.
+		idx, _ := jp.arrayOffsets([]*Value{v})
+		switch {
+		case idx < 0, t == Remove, t == ArrayInsert:
+			return v
+		case idx == 0 && (t == Set || t == Replace):
+			return value
+		case idx > 0 && (t == Set || t == Insert):
+			return NewArray([]*Value{v, value})
+		default:
+			return v
+		}
+	}
+
+	idx, _ := jp.arrayOffsets(ary)
+	if idx < 0 {
+		return v
+	}
+	switch t {
+	case Set, Replace, Insert:
+		if idx < len(ary) {
+			if t == Insert {
+				return v
+			}
+			ary = slices.Clone(ary)
+			ary[idx] = value
+			return NewArray(ary)
+		}
+		if t == Replace {
+			return v
+		}
+		return NewArray(append(slices.Clip(ary), value))
+	case Remove:
+		if idx >= len(ary) {
+			return v
+		}
+		return NewArray(slices.Delete(slices.Clone(ary), idx, idx+1))
+	case ArrayInsert:
+		if idx > len(ary) {
+			idx = len(ary)
+		}
+		return NewArray(slices.Insert(slices.Clone(ary), idx, value))
+	default:
+		return v
+	}
+}
+
+// MergePreserve merges two JSON documents like JSON_MERGE_PRESERVE does:
+// adjacent arrays are concatenated, adjacent objects are merged by merging the
+// values of their common keys, and any other value is wrapped in an array
+// before being concatenated.
+func MergePreserve(a, b *Value) *Value {
+	ao, aok := a.Object()
+	bo, bok := b.Object()
+	if aok && bok {
+		obj := Object{kvs: slices.Clone(ao.kvs)}
+		bo.Visit(func(key string, v *Value) {
+			if i, found := obj.find(key); found {
+				obj.kvs[i].v = MergePreserve(obj.kvs[i].v, v)
+			} else {
+				obj.Set(key, v, Set)
+			}
+		})
+		return &Value{o: obj, t: TypeObject}
+	}
+
+	var ary []*Value
+	for _, v := range []*Value{a, b} {
+		if elems, ok := v.Array(); ok {
+			ary = append(ary, elems...)
+		} else {
+			ary = append(ary, v)
+		}
+	}
+	return NewArray(ary)
+}
+
+// MergePatch merges two JSON documents like JSON_MERGE_PATCH does, following
+// the semantics of RFC 7396: if patch is not an object, it replaces target;
+// otherwise its members are merged recursively into target, and members with
+// a null value are removed from it.
+func MergePatch(target, patch *Value) *Value {
+	po, ok := patch.Object()
+	if !ok {
+		return patch
+	}
+
+	var obj Object
+	if target != nil {
+		if to, ok := target.Object(); ok {
+			obj.kvs = slices.Clone(to.kvs)
+		}
+	}
+	po.Visit(func(key string, v *Value) {
+		if v.Type() == TypeNull {
+			obj.Del(key)
+			return
+		}
+		obj.Set(key, MergePatch(obj.Get(key), v), Set)
+	})
+	return &Value{o: obj, t: TypeObject}
+}
@@ -0,0 +1,246 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package jwtauthserver implements a MySQL AuthServer that authenticates
+// clients with a JSON Web Token, such as an OIDC ID token, sent as the
+// password through mysql_clear_password.
+package jwtauthserver
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/spf13/pflag"
+
+	"vitess.io/vitess/go/mysql"
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/servenv"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+)
+
+var (
+	jwtJWKSFile      string
+	jwtJWKSURL       string
+	jwtJWKSCacheTTL  = time.Hour
+	jwtJWKSTimeout   = 10 * time.Second
+	jwtIssuer        string
+	jwtAudience      string
+	jwtUsernameClaim = "sub"
+	jwtGroupsClaim   = "groups"
+	jwtClockSkew     = time.Minute
+)
+
+func init() {
+	servenv.OnParseFor("vtgate", func(fs *pflag.FlagSet) {
+		fs.StringVar(&jwtJWKSFile, "mysql_auth_jwt_jwks_file", jwtJWKSFile, "Path to a JWKS file with the public keys used to verify JWTs.")
+		fs.StringVar(&jwtJWKSURL, "mysql_auth_jwt_jwks_url", jwtJWKSURL, "URL from which to fetch the JWKS with the public keys used to verify JWTs, e.g. the jwks_uri of an OIDC provider.")
+		fs.DurationVar(&jwtJWKSCacheTTL, "mysql_auth_jwt_jwks_cache_ttl", jwtJWKSCacheTTL, "How long to cache the JWKS fetched from --mysql_auth_jwt_jwks_url.")
+		fs.DurationVar(&jwtJWKSTimeout, "mysql_auth_jwt_jwks_timeout", jwtJWKSTimeout, "Timeout for fetching the JWKS from --mysql_auth_jwt_jwks_url.")
+		fs.StringVar(&jwtIssuer, "mysql_auth_jwt_issuer", jwtIssuer, "If set, JWTs must carry this value in their iss claim.")
+		fs.StringVar(&jwtAudience, "mysql_auth_jwt_audience", jwtAudience, "If set, JWTs must contain this value in their aud claim.")
+		fs.StringVar(&jwtUsernameClaim, "mysql_auth_jwt_username_claim", jwtUsernameClaim, "JWT claim that is mapped to the Vitess username.")
+		fs.StringVar(&jwtGroupsClaim, "mysql_auth_jwt_groups_claim", jwtGroupsClaim, "JWT claim that is mapped to the Vitess groups.")
+		fs.DurationVar(&jwtClockSkew, "mysql_auth_jwt_clock_skew", jwtClockSkew, "Clock skew tolerated when validating the exp and nbf claims of JWTs.")
+	})
+}
+
+// AuthServerJWT implements AuthServer by validating a JWT sent by the
+// client as its clear text password. The token must be signed by one of
+// the keys of a JSON Web Key Set. The claims of the token are mapped to
+// the username and groups of the Vitess caller ID.
+//
+// Connections are closed once the token they were authenticated with
+// expires. Since the token is sent in clear text, the listener should
+// only accept TLS connections.
+type AuthServerJWT struct {
+	methods []mysql.AuthMethod
+	keys    keyProvider
+
+	issuer        string
+	audience      string
+	usernameClaim string
+	groupsClaim   string
+	clockSkew     time.Duration
+	timeNow       func() time.Time
+}
+
+// JWTUserData holds the username and groups mapped from the claims of a
+// JWT, together with the expiry of that token.
+type JWTUserData struct {
+	Username  string
+	Groups    []string
+	ExpiresAt time.Time
+}
+
+// Get returns the wrapped username and groups.
+func (jud *JWTUserData) Get() *querypb.VTGateCallerID {
+	return &querypb.VTGateCallerID{Username: jud.Username, Groups: jud.Groups}
+}
+
+// Expiry is part of the mysql.ExpiringGetter interface.
+func (jud *JWTUserData) Expiry() time.Time {
+	return jud.ExpiresAt
+}
+
+// Init is public so it can be called from plugin_auth_jwt.go (go/cmd/vtgate)
+func Init() {
+	if jwtJWKSFile == "" && jwtJWKSURL == "" {
+		log.Infof("Not configuring AuthServerJWT because mysql_auth_jwt_jwks_file and mysql_auth_jwt_jwks_url are empty")
+		return
+	}
+	if jwtJWKSFile != "" && jwtJWKSURL != "" {
+		log.Exitf("Both mysql_auth_jwt_jwks_file and mysql_auth_jwt_jwks_url are non-empty, can only use one.")
+	}
+	if jwtUsernameClaim == "" {
+		log.Exitf("mysql_auth_jwt_username_claim cannot be empty")
+	}
+
+	var keys keyProvider
+	if jwtJWKSFile != "" {
+		sk, err := loadKeySetFile(jwtJWKSFile)
+		if err != nil {
+			log.Exitf("Failed to load mysql_auth_jwt_jwks_file: %v", err)
+		}
+		keys = sk
+	} else {
+		keys = newRemoteKeys(jwtJWKSURL, jwtJWKSCacheTTL, jwtJWKSTimeout)
+	}
+
+	mysql.RegisterAuthServer("jwt", newAuthServerJWT(keys, jwtIssuer, jwtAudience, jwtUsernameClaim, jwtGroupsClaim, jwtClockSkew))
+}
+
+func newAuthServerJWT(keys keyProvider, issuer, audience, usernameClaim, groupsClaim string, clockSkew time.Duration) *AuthServerJWT {
+	a := &AuthServerJWT{
+		keys:          keys,
+		issuer:        issuer,
+		audience:      audience,
+		usernameClaim: usernameClaim,
+		groupsClaim:   groupsClaim,
+		clockSkew:     clockSkew,
+		timeNow:       time.Now,
+	}
+	a.methods = []mysql.AuthMethod{mysql.NewMysqlClearAuthMethod(a, a)}
+	return a
+}
+
+// AuthMethods returns the list of registered auth methods
+// implemented by this auth server.
+func (a *AuthServerJWT) AuthMethods() []mysql.AuthMethod {
+	return a.methods
+}
+
+// DefaultAuthMethodDescription returns MysqlNativePassword as the default
+// authentication method for the auth server implementation.
+func (a *AuthServerJWT) DefaultAuthMethodDescription() mysql.AuthMethodDescription {
+	return mysql.MysqlNativePassword
+}
+
+// HandleUser is part of the UserValidator interface. We
+// handle any user here since we don't check up front.
+func (a *AuthServerJWT) HandleUser(user string) bool {
+	return true
+}
+
+// UserEntryWithPassword is part of the PlaintextStorage interface
+// and called after the token is sent by the client as its password.
+func (a *AuthServerJWT) UserEntryWithPassword(conn *mysql.Conn, user string, password string, remoteAddr net.Addr) (mysql.Getter, error) {
+	userData, err := a.validate(context.Background(), user, password)
+	if err != nil {
+		log.Warningf("Rejecting JWT for user '%v': %v", user, err)
+		return nil, mysql.NewSQLError(mysql.ERAccessDeniedError, mysql.SSAccessDeniedError, "Access denied for user '%v'", user)
+	}
+	return userData, nil
+}
+
+func (a *AuthServerJWT) validate(ctx context.Context, user, token string) (*JWTUserData, error) {
+	keys, err := a.keys.Keys(ctx, tokenKid(token))
+	if err != nil {
+		return nil, err
+	}
+	c, err := verifyToken(token, keys)
+	if err != nil {
+		return nil, err
+	}
+
+	now := a.timeNow()
+	exp, ok, err := c.time("exp")
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, fmt.Errorf("token has no exp claim")
+	}
+	if now.After(exp.Add(a.clockSkew)) {
+		return nil, fmt.Errorf("token expired at %v", exp)
+	}
+	nbf, ok, err := c.time("nbf")
+	if err != nil {
+		return nil, err
+	}
+	if ok && now.Add(a.clockSkew).Before(nbf) {
+		return nil, fmt.Errorf("token is not valid before %v", nbf)
+	}
+
+	if a.issuer != "" {
+		iss, err := c.string("iss")
+		if err != nil {
+			return nil, err
+		}
+		if iss != a.issuer {
+			return nil, fmt.Errorf("unexpected issuer %q", iss)
+		}
+	}
+	if a.audience != "" {
+		aud, err := c.strings("aud")
+		if err != nil {
+			return nil, err
+		}
+		found := false
+		for _, v := range aud {
+			if v == a.audience {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("token audience %v does not contain %q", aud, a.audience)
+		}
+	}
+
+	username, err := c.string(a.usernameClaim)
+	if err != nil {
+		return nil, err
+	}
+	if user != "" && user != username {
+		return nil, fmt.Errorf("MySQL connection username '%v' does not match token claim %s '%v'", user, a.usernameClaim, username)
+	}
+	var groups []string
+	if a.groupsClaim != "" {
+		groups, err = c.strings(a.groupsClaim)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &JWTUserData{
+		Username:  username,
+		Groups:    groups,
+		ExpiresAt: exp.Add(a.clockSkew),
+	}, nil
+}
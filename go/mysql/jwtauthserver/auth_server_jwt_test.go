@@ -0,0 +1,355 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package jwtauthserver
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/mysql"
+)
+
+func b64(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func rsaJWK(kid string, pub *rsa.PublicKey) map[string]string {
+	return map[string]string{
+		"kty": "RSA",
+		"kid": kid,
+		"use": "sig",
+		"n":   b64(pub.N.Bytes()),
+		"e":   b64(big.NewInt(int64(pub.E)).Bytes()),
+	}
+}
+
+func ecJWK(kid string, pub *ecdsa.PublicKey) map[string]string {
+	return map[string]string{
+		"kty": "EC",
+		"kid": kid,
+		"crv": "P-256",
+		"x":   b64(pub.X.FillBytes(make([]byte, 32))),
+		"y":   b64(pub.Y.FillBytes(make([]byte, 32))),
+	}
+}
+
+func jwks(t *testing.T, keys ...map[string]string) []byte {
+	data, err := json.Marshal(map[string]any{"keys": keys})
+	require.NoError(t, err)
+	return data
+}
+
+func signRS256(t *testing.T, key *rsa.PrivateKey, kid string, c map[string]any) string {
+	signingInput := encodeSigningInput(t, "RS256", kid, c)
+	digest := sha256.Sum256([]byte(signingInput))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	require.NoError(t, err)
+	return signingInput + "." + b64(sig)
+}
+
+func signES256(t *testing.T, key *ecdsa.PrivateKey, kid string, c map[string]any) string {
+	signingInput := encodeSigningInput(t, "ES256", kid, c)
+	digest := sha256.Sum256([]byte(signingInput))
+	r, s, err := ecdsa.Sign(rand.Reader, key, digest[:])
+	require.NoError(t, err)
+	sig := append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+	return signingInput + "." + b64(sig)
+}
+
+func encodeSigningInput(t *testing.T, alg, kid string, c map[string]any) string {
+	header, err := json.Marshal(map[string]string{"alg": alg, "kid": kid, "typ": "JWT"})
+	require.NoError(t, err)
+	payload, err := json.Marshal(c)
+	require.NoError(t, err)
+	return b64(header) + "." + b64(payload)
+}
+
+func TestValidateToken(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	keys, err := parseKeySet(jwks(t, rsaJWK("rsa1", &rsaKey.PublicKey), ecJWK("ec1", &ecKey.PublicKey)))
+	require.NoError(t, err)
+
+	now := time.Unix(1700000000, 0)
+	a := newAuthServerJWT(staticKeys(keys), "https://issuer.example.com", "vitess", "email", "groups", time.Minute)
+	a.timeNow = func() time.Time { return now }
+
+	validClaims := func() map[string]any {
+		return map[string]any{
+			"iss":    "https://issuer.example.com",
+			"aud":    []string{"other", "vitess"},
+			"email":  "alice@example.com",
+			"groups": []string{"dev", "admin"},
+			"exp":    now.Add(time.Hour).Unix(),
+			"nbf":    now.Add(-time.Minute).Unix(),
+		}
+	}
+	withClaim := func(name string, value any) map[string]any {
+		c := validClaims()
+		if value == nil {
+			delete(c, name)
+		} else {
+			c[name] = value
+		}
+		return c
+	}
+
+	testcases := []struct {
+		name  string
+		user  string
+		token string
+		err   string
+	}{{
+		name:  "valid RS256",
+		user:  "alice@example.com",
+		token: signRS256(t, rsaKey, "rsa1", validClaims()),
+	}, {
+		name:  "valid ES256",
+		user:  "alice@example.com",
+		token: signES256(t, ecKey, "ec1", validClaims()),
+	}, {
+		name:  "empty user",
+		token: signRS256(t, rsaKey, "rsa1", validClaims()),
+	}, {
+		name:  "user mismatch",
+		user:  "bob@example.com",
+		token: signRS256(t, rsaKey, "rsa1", validClaims()),
+		err:   "does not match token claim",
+	}, {
+		name:  "unknown signing key",
+		token: signRS256(t, otherKey, "rsa1", validClaims()),
+		err:   "signature could not be verified",
+	}, {
+		name:  "wrong kid",
+		token: signRS256(t, rsaKey, "ec1", validClaims()),
+		err:   "signature could not be verified",
+	}, {
+		name:  "unsigned",
+		token: encodeSigningInput(t, "none", "", validClaims()) + ".",
+		err:   `unsupported signing algorithm "none"`,
+	}, {
+		name:  "expired",
+		token: signRS256(t, rsaKey, "rsa1", withClaim("exp", now.Add(-2*time.Minute).Unix())),
+		err:   "token expired",
+	}, {
+		name:  "expired within clock skew",
+		token: signRS256(t, rsaKey, "rsa1", withClaim("exp", now.Add(-30*time.Second).Unix())),
+	}, {
+		name:  "missing exp",
+		token: signRS256(t, rsaKey, "rsa1", withClaim("exp", nil)),
+		err:   "no exp claim",
+	}, {
+		name:  "not yet valid",
+		token: signRS256(t, rsaKey, "rsa1", withClaim("nbf", now.Add(time.Hour).Unix())),
+		err:   "not valid before",
+	}, {
+		name:  "wrong issuer",
+		token: signRS256(t, rsaKey, "rsa1", withClaim("iss", "https://evil.example.com")),
+		err:   "unexpected issuer",
+	}, {
+		name:  "wrong audience",
+		token: signRS256(t, rsaKey, "rsa1", withClaim("aud", "other")),
+		err:   "does not contain",
+	}, {
+		name:  "missing username claim",
+		token: signRS256(t, rsaKey, "rsa1", withClaim("email", nil)),
+		err:   `missing claim "email"`,
+	}, {
+		name:  "malformed",
+		token: "not-a-jwt",
+		err:   "malformed token",
+	}}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			ud, err := a.validate(context.Background(), tc.user, tc.token)
+			if tc.err != "" {
+				require.ErrorContains(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			callerID := ud.Get()
+			assert.Equal(t, "alice@example.com", callerID.Username)
+			assert.Equal(t, []string{"dev", "admin"}, callerID.Groups)
+		})
+	}
+
+	t.Run("access denied", func(t *testing.T) {
+		_, err := a.UserEntryWithPassword(nil, "alice@example.com", "not-a-jwt", nil)
+		require.Error(t, err)
+		sqlErr, ok := err.(*mysql.SQLError)
+		require.True(t, ok)
+		assert.Equal(t, mysql.ERAccessDeniedError, sqlErr.Number())
+	})
+
+	t.Run("expiry", func(t *testing.T) {
+		token := signRS256(t, rsaKey, "rsa1", validClaims())
+		getter, err := a.UserEntryWithPassword(nil, "alice@example.com", token, nil)
+		require.NoError(t, err)
+		eg, ok := getter.(mysql.ExpiringGetter)
+		require.True(t, ok)
+		assert.Equal(t, now.Add(time.Hour+time.Minute), eg.Expiry())
+	})
+}
+
+func TestParseKeySet(t *testing.T) {
+	_, err := parseKeySet([]byte(`{"keys": [{"kty": "oct", "k": "c2VjcmV0"}]}`))
+	require.ErrorContains(t, err, "no usable signature verification keys")
+
+	_, err = parseKeySet([]byte(`{"keys": [{"kty": "EC", "crv": "P-256", "x": "AQ", "y": "AQ"}]}`))
+	require.ErrorContains(t, err, "not on curve")
+
+	_, err = parseKeySet([]byte(`not json`))
+	require.ErrorContains(t, err, "error parsing JWKS")
+}
+
+func TestRemoteKeys(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	body := jwks(t, rsaJWK("rsa1", &key.PublicKey))
+
+	var requests, failing atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if failing.Load() != 0 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Write(body)
+	}))
+	defer server.Close()
+
+	now := time.Unix(1700000000, 0)
+	rk := newRemoteKeys(server.URL, time.Hour, 10*time.Second)
+	rk.timeNow = func() time.Time { return now }
+
+	keys, err := rk.Keys(context.Background(), "rsa1")
+	require.NoError(t, err)
+	require.Len(t, keys, 1)
+	assert.Equal(t, "rsa1", keys[0].kid)
+
+	// Served from the cache.
+	_, err = rk.Keys(context.Background(), "rsa1")
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, requests.Load())
+
+	// A failed refresh keeps the cached keys.
+	failing.Store(1)
+	now = now.Add(2 * time.Hour)
+	keys, err = rk.Keys(context.Background(), "rsa1")
+	require.NoError(t, err)
+	require.Len(t, keys, 1)
+	assert.EqualValues(t, 2, requests.Load())
+
+	// Without cached keys, a failure is reported.
+	rk = newRemoteKeys(server.URL, time.Hour, 10*time.Second)
+	_, err = rk.Keys(context.Background(), "rsa1")
+	require.Error(t, err)
+	assert.True(t, strings.Contains(err.Error(), fmt.Sprintf("%d", http.StatusInternalServerError)))
+}
+
+func TestRemoteKeysUnknownKid(t *testing.T) {
+	key1, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	key2, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	var requests atomic.Int32
+	var body atomic.Value
+	body.Store(jwks(t, rsaJWK("rsa1", &key1.PublicKey)))
+	release := make(chan struct{})
+	var blocking atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if blocking.Load() {
+			<-release
+		}
+		w.Write(body.Load().([]byte))
+	}))
+	defer server.Close()
+
+	now := time.Unix(1700000000, 0)
+	rk := newRemoteKeys(server.URL, time.Hour, 10*time.Second)
+	rk.timeNow = func() time.Time { return now }
+
+	_, err = rk.Keys(context.Background(), "rsa1")
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, requests.Load())
+
+	// The key set is rotated: an unknown kid triggers a refresh before the cache expires.
+	body.Store(jwks(t, rsaJWK("rsa1", &key1.PublicKey), rsaJWK("rsa2", &key2.PublicKey)))
+	keys, err := rk.Keys(context.Background(), "rsa2")
+	require.NoError(t, err)
+	assert.Len(t, keys, 2)
+	assert.EqualValues(t, 2, requests.Load())
+
+	// Refreshes for unknown kids are rate limited.
+	_, err = rk.Keys(context.Background(), "rsa3")
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, requests.Load())
+
+	// A slow refresh doesn't block the callers that can use the cached keys.
+	now = now.Add(unknownKidRefreshInterval)
+	blocking.Store(true)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_, err := rk.Keys(context.Background(), "rsa3")
+		assert.NoError(t, err)
+	}()
+	require.Eventually(t, func() bool { return requests.Load() == 3 }, 10*time.Second, time.Millisecond)
+	keys, err = rk.Keys(context.Background(), "rsa1")
+	require.NoError(t, err)
+	assert.Len(t, keys, 2)
+
+	// A caller whose context expires during a refresh uses the cached keys.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	rk.mu.Lock()
+	rk.unknownKidFetchAt = time.Time{}
+	rk.mu.Unlock()
+	keys, err = rk.Keys(ctx, "rsa4")
+	require.NoError(t, err)
+	assert.Len(t, keys, 2)
+
+	close(release)
+	<-done
+	assert.EqualValues(t, 3, requests.Load())
+}
@@ -0,0 +1,290 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package jwtauthserver
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+
+	"vitess.io/vitess/go/vt/log"
+)
+
+// jsonWebKey is a single entry of a JSON Web Key Set, as described
+// in RFC 7517. Only the fields needed for signature verification
+// with RSA and EC keys are decoded.
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+
+	// RSA public key parameters.
+	N string `json:"n"`
+	E string `json:"e"`
+
+	// EC public key parameters.
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+type jsonWebKeySet struct {
+	Keys []jsonWebKey `json:"keys"`
+}
+
+// verificationKey is a decoded public key from a key set.
+type verificationKey struct {
+	kid string
+	alg string
+	key crypto.PublicKey
+}
+
+// parseKeySet decodes a JWKS document. Keys that are not meant for
+// signature verification or that use an unsupported key type are skipped.
+func parseKeySet(data []byte) ([]*verificationKey, error) {
+	var set jsonWebKeySet
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("error parsing JWKS: %v", err)
+	}
+
+	var keys []*verificationKey
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		var (
+			pub crypto.PublicKey
+			err error
+		)
+		switch jwk.Kty {
+		case "RSA":
+			pub, err = jwk.rsaPublicKey()
+		case "EC":
+			pub, err = jwk.ecdsaPublicKey()
+		default:
+			log.Warningf("Skipping JWKS key %q with unsupported key type %q", jwk.Kid, jwk.Kty)
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid JWKS key %q: %v", jwk.Kid, err)
+		}
+		keys = append(keys, &verificationKey{kid: jwk.Kid, alg: jwk.Alg, key: pub})
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("JWKS contains no usable signature verification keys")
+	}
+	return keys, nil
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) == 0 {
+		return nil, fmt.Errorf("empty value")
+	}
+	return new(big.Int).SetBytes(b), nil
+}
+
+func (jwk *jsonWebKey) rsaPublicKey() (*rsa.PublicKey, error) {
+	n, err := decodeBigInt(jwk.N)
+	if err != nil {
+		return nil, fmt.Errorf("invalid modulus: %v", err)
+	}
+	e, err := decodeBigInt(jwk.E)
+	if err != nil {
+		return nil, fmt.Errorf("invalid exponent: %v", err)
+	}
+	if !e.IsInt64() || e.Int64() > 1<<31-1 {
+		return nil, fmt.Errorf("exponent too large")
+	}
+	return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+}
+
+func (jwk *jsonWebKey) ecdsaPublicKey() (*ecdsa.PublicKey, error) {
+	var curve elliptic.Curve
+	switch jwk.Crv {
+	case "P-256":
+		curve = elliptic.P256()
+	case "P-384":
+		curve = elliptic.P384()
+	case "P-521":
+		curve = elliptic.P521()
+	default:
+		return nil, fmt.Errorf("unsupported curve %q", jwk.Crv)
+	}
+	x, err := decodeBigInt(jwk.X)
+	if err != nil {
+		return nil, fmt.Errorf("invalid x coordinate: %v", err)
+	}
+	y, err := decodeBigInt(jwk.Y)
+	if err != nil {
+		return nil, fmt.Errorf("invalid y coordinate: %v", err)
+	}
+	if !curve.IsOnCurve(x, y) {
+		return nil, fmt.Errorf("point is not on curve %s", jwk.Crv)
+	}
+	return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+}
+
+// keyProvider returns the keys that can be used to verify
+// the signature of a token signed with the key kid, if set.
+type keyProvider interface {
+	Keys(ctx context.Context, kid string) ([]*verificationKey, error)
+}
+
+// staticKeys is a keyProvider for a key set read from a local file.
+type staticKeys []*verificationKey
+
+// Keys is part of the keyProvider interface.
+func (sk staticKeys) Keys(ctx context.Context, kid string) ([]*verificationKey, error) {
+	return sk, nil
+}
+
+func loadKeySetFile(path string) (staticKeys, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return parseKeySet(data)
+}
+
+// unknownKidRefreshInterval is the minimum time between two refreshes of
+// a remote key set triggered by tokens signed with an unknown key.
+const unknownKidRefreshInterval = time.Minute
+
+// remoteKeys is a keyProvider that fetches a key set from a URL
+// and caches it for a configurable amount of time. When a refresh
+// fails, the previously fetched keys keep being used.
+//
+// A token signed with a key that is not in the cached set triggers a
+// refresh, at most once per unknownKidRefreshInterval, so that rotated
+// keys are picked up before the cache expires.
+type remoteKeys struct {
+	url     string
+	ttl     time.Duration
+	client  *http.Client
+	timeNow func() time.Time
+
+	// fetches makes concurrent refreshes share a single request, which
+	// is made without holding mu.
+	fetches singleflight.Group
+
+	mu                sync.Mutex
+	keys              []*verificationKey
+	fetchedAt         time.Time
+	unknownKidFetchAt time.Time
+}
+
+func newRemoteKeys(url string, ttl, timeout time.Duration) *remoteKeys {
+	return &remoteKeys{
+		url:     url,
+		ttl:     ttl,
+		client:  &http.Client{Timeout: timeout},
+		timeNow: time.Now,
+	}
+}
+
+// Keys is part of the keyProvider interface.
+func (rk *remoteKeys) Keys(ctx context.Context, kid string) ([]*verificationKey, error) {
+	rk.mu.Lock()
+	keys := rk.keys
+	now := rk.timeNow()
+	refresh := keys == nil || now.Sub(rk.fetchedAt) >= rk.ttl
+	if !refresh && kid != "" && !hasKid(keys, kid) && now.Sub(rk.unknownKidFetchAt) >= unknownKidRefreshInterval {
+		rk.unknownKidFetchAt = now
+		refresh = true
+	}
+	rk.mu.Unlock()
+	if !refresh {
+		return keys, nil
+	}
+
+	// The request is shared with concurrent callers, so it must not
+	// be canceled with the context of this one.
+	ch := rk.fetches.DoChan("", func() (any, error) {
+		keys, err := rk.fetch(context.Background())
+		if err != nil {
+			return nil, err
+		}
+		rk.mu.Lock()
+		defer rk.mu.Unlock()
+		rk.keys = keys
+		rk.fetchedAt = rk.timeNow()
+		return keys, nil
+	})
+	select {
+	case <-ctx.Done():
+		if keys != nil {
+			return keys, nil
+		}
+		return nil, ctx.Err()
+	case res := <-ch:
+		if res.Err != nil {
+			if keys != nil {
+				log.Warningf("Failed to refresh JWKS from %s, using cached keys: %v", rk.url, res.Err)
+				return keys, nil
+			}
+			return nil, res.Err
+		}
+		return res.Val.([]*verificationKey), nil
+	}
+}
+
+func hasKid(keys []*verificationKey, kid string) bool {
+	for _, k := range keys {
+		if k.kid == kid {
+			return true
+		}
+	}
+	return false
+}
+
+func (rk *remoteKeys) fetch(ctx context.Context) ([]*verificationKey, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rk.url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := rk.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching JWKS from %s: %v", rk.url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("error fetching JWKS from %s: unexpected status %s", rk.url, resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, fmt.Errorf("error reading JWKS from %s: %v", rk.url, err)
+	}
+	return parseKeySet(data)
+}
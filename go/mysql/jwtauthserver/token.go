@@ -0,0 +1,233 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package jwtauthserver
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	_ "crypto/sha256" // register SHA-256 for crypto.Hash
+	_ "crypto/sha512" // register SHA-384 and SHA-512 for crypto.Hash
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+)
+
+// tokenHeader is the JOSE header of a compact serialized JWT.
+type tokenHeader struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+	Typ string `json:"typ"`
+}
+
+// claims holds the decoded payload of a JWT.
+type claims map[string]any
+
+// signingAlgorithm describes how to verify a signature for a given JWS "alg".
+type signingAlgorithm struct {
+	hash   crypto.Hash
+	verify func(key crypto.PublicKey, hash crypto.Hash, digest, sig []byte) error
+}
+
+var signingAlgorithms = map[string]signingAlgorithm{
+	"RS256": {hash: crypto.SHA256, verify: verifyPKCS1v15},
+	"RS384": {hash: crypto.SHA384, verify: verifyPKCS1v15},
+	"RS512": {hash: crypto.SHA512, verify: verifyPKCS1v15},
+	"PS256": {hash: crypto.SHA256, verify: verifyPSS},
+	"PS384": {hash: crypto.SHA384, verify: verifyPSS},
+	"PS512": {hash: crypto.SHA512, verify: verifyPSS},
+	"ES256": {hash: crypto.SHA256, verify: verifyECDSA},
+	"ES384": {hash: crypto.SHA384, verify: verifyECDSA},
+	"ES512": {hash: crypto.SHA512, verify: verifyECDSA},
+}
+
+var errUnexpectedKeyType = errors.New("key type does not match signing algorithm")
+
+func verifyPKCS1v15(key crypto.PublicKey, hash crypto.Hash, digest, sig []byte) error {
+	pub, ok := key.(*rsa.PublicKey)
+	if !ok {
+		return errUnexpectedKeyType
+	}
+	return rsa.VerifyPKCS1v15(pub, hash, digest, sig)
+}
+
+func verifyPSS(key crypto.PublicKey, hash crypto.Hash, digest, sig []byte) error {
+	pub, ok := key.(*rsa.PublicKey)
+	if !ok {
+		return errUnexpectedKeyType
+	}
+	return rsa.VerifyPSS(pub, hash, digest, sig, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash})
+}
+
+func verifyECDSA(key crypto.PublicKey, hash crypto.Hash, digest, sig []byte) error {
+	pub, ok := key.(*ecdsa.PublicKey)
+	if !ok {
+		return errUnexpectedKeyType
+	}
+	// JWS encodes ECDSA signatures as the fixed-size concatenation of R and S.
+	size := (pub.Curve.Params().BitSize + 7) / 8
+	if len(sig) != 2*size {
+		return fmt.Errorf("invalid ECDSA signature length %d", len(sig))
+	}
+	r := new(big.Int).SetBytes(sig[:size])
+	s := new(big.Int).SetBytes(sig[size:])
+	if !ecdsa.Verify(pub, digest, r, s) {
+		return errors.New("ECDSA verification failure")
+	}
+	return nil
+}
+
+// tokenKid returns the "kid" header of a compact serialized JWT, or an
+// empty string if the token has no such header or cannot be decoded.
+func tokenKid(token string) string {
+	header, _, ok := strings.Cut(token, ".")
+	if !ok {
+		return ""
+	}
+	headerJSON, err := base64.RawURLEncoding.DecodeString(header)
+	if err != nil {
+		return ""
+	}
+	var h tokenHeader
+	if err := json.Unmarshal(headerJSON, &h); err != nil {
+		return ""
+	}
+	return h.Kid
+}
+
+// verifyToken checks the signature of a compact serialized JWT against
+// the given keys, and returns its decoded claims. It does not validate
+// any of the claims themselves.
+func verifyToken(token string, keys []*verificationKey) (claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, errors.New("malformed token")
+	}
+
+	headerJSON, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, fmt.Errorf("malformed token header: %v", err)
+	}
+	var header tokenHeader
+	if err := json.Unmarshal(headerJSON, &header); err != nil {
+		return nil, fmt.Errorf("malformed token header: %v", err)
+	}
+	alg, ok := signingAlgorithms[header.Alg]
+	if !ok {
+		return nil, fmt.Errorf("unsupported signing algorithm %q", header.Alg)
+	}
+
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("malformed token signature: %v", err)
+	}
+	h := alg.hash.New()
+	h.Write([]byte(parts[0]))
+	h.Write([]byte{'.'})
+	h.Write([]byte(parts[1]))
+	digest := h.Sum(nil)
+
+	verified := false
+	for _, k := range keys {
+		if header.Kid != "" && k.kid != header.Kid {
+			continue
+		}
+		if k.alg != "" && k.alg != header.Alg {
+			continue
+		}
+		if alg.verify(k.key, alg.hash, digest, sig) == nil {
+			verified = true
+			break
+		}
+	}
+	if !verified {
+		return nil, errors.New("token signature could not be verified")
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("malformed token payload: %v", err)
+	}
+	dec := json.NewDecoder(bytes.NewReader(payload))
+	dec.UseNumber()
+	var c claims
+	if err := dec.Decode(&c); err != nil {
+		return nil, fmt.Errorf("malformed token payload: %v", err)
+	}
+	return c, nil
+}
+
+// time returns the value of a NumericDate claim such as "exp" or "nbf".
+func (c claims) time(name string) (time.Time, bool, error) {
+	v, ok := c[name]
+	if !ok {
+		return time.Time{}, false, nil
+	}
+	n, ok := v.(json.Number)
+	if !ok {
+		return time.Time{}, false, fmt.Errorf("claim %q is not a number", name)
+	}
+	f, err := n.Float64()
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("claim %q is not a number", name)
+	}
+	sec := int64(f)
+	return time.Unix(sec, int64((f-float64(sec))*float64(time.Second))), true, nil
+}
+
+// string returns the value of a claim that must be a string.
+func (c claims) string(name string) (string, error) {
+	v, ok := c[name]
+	if !ok {
+		return "", fmt.Errorf("missing claim %q", name)
+	}
+	s, ok := v.(string)
+	if !ok {
+		return "", fmt.Errorf("claim %q is not a string", name)
+	}
+	return s, nil
+}
+
+// strings returns the value of a claim that can be either a single string
+// or an array of strings, like "aud" or a groups claim.
+func (c claims) strings(name string) ([]string, error) {
+	v, ok := c[name]
+	if !ok {
+		return nil, nil
+	}
+	switch v := v.(type) {
+	case string:
+		return []string{v}, nil
+	case []any:
+		res := make([]string, 0, len(v))
+		for _, e := range v {
+			s, ok := e.(string)
+			if !ok {
+				return nil, fmt.Errorf("claim %q contains a non-string value", name)
+			}
+			res = append(res, s)
+		}
+		return res, nil
+	default:
+		return nil, fmt.Errorf("claim %q is neither a string nor an array of strings", name)
+	}
+}
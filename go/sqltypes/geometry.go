@@ -0,0 +1,580 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sqltypes
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+
+	"vitess.io/vitess/go/mysql/format"
+)
+
+// ErrInvalidGeometry is returned when a spatial value cannot be decoded
+// from its WKB, WKT or internal MySQL representation.
+var ErrInvalidGeometry = errors.New("invalid GIS data")
+
+// GeomType is the kind of a spatial value. The numeric values are the
+// geometry type codes used by the OGC Well-Known Binary (WKB) format.
+type GeomType uint32
+
+const (
+	GeomPoint GeomType = iota + 1
+	GeomLineString
+	GeomPolygon
+	GeomMultiPoint
+	GeomMultiLineString
+	GeomMultiPolygon
+	GeomCollection
+)
+
+var geomTypeNames = [...]string{
+	GeomPoint:           "POINT",
+	GeomLineString:      "LINESTRING",
+	GeomPolygon:         "POLYGON",
+	GeomMultiPoint:      "MULTIPOINT",
+	GeomMultiLineString: "MULTILINESTRING",
+	GeomMultiPolygon:    "MULTIPOLYGON",
+	GeomCollection:      "GEOMETRYCOLLECTION",
+}
+
+// String returns the name of the geometry type as used in WKT.
+func (t GeomType) String() string {
+	if t >= GeomPoint && t <= GeomCollection {
+		return geomTypeNames[t]
+	}
+	return "GEOMETRY"
+}
+
+// member returns the type of the members of a MULTI* geometry type.
+func (t GeomType) member() GeomType {
+	switch t {
+	case GeomMultiPoint:
+		return GeomPoint
+	case GeomMultiLineString:
+		return GeomLineString
+	case GeomMultiPolygon:
+		return GeomPolygon
+	}
+	return 0
+}
+
+// Coord is a single position in a spatial value.
+type Coord struct {
+	X, Y float64
+}
+
+// Geom is a decoded spatial value. Only the fields that apply to its Type are set.
+type Geom struct {
+	// SRID is the spatial reference system the coordinates belong to.
+	SRID uint32
+	Type GeomType
+	// Coords holds the position of a POINT, or the vertices of a LINESTRING.
+	Coords []Coord
+	// Rings holds the rings of a POLYGON: the exterior ring first, followed by its holes.
+	Rings [][]Coord
+	// Members holds the geometries of a MULTI* value or a GEOMETRYCOLLECTION.
+	Members []*Geom
+}
+
+// NewGeometry returns a GEOMETRY Value holding g in MySQL's internal storage format.
+func NewGeometry(g *Geom) Value {
+	return MakeTrusted(Geometry, g.Bytes())
+}
+
+// ToGeom decodes the value as a spatial value in MySQL's internal storage format.
+func (v Value) ToGeom() (*Geom, error) {
+	raw, err := v.ToBytes()
+	if err != nil {
+		return nil, err
+	}
+	return ParseGeom(raw)
+}
+
+// ParseGeom decodes a spatial value in MySQL's internal storage format,
+// which is a little-endian 4-byte SRID followed by the WKB of the geometry.
+func ParseGeom(raw []byte) (*Geom, error) {
+	if len(raw) < 4 {
+		return nil, ErrInvalidGeometry
+	}
+	return ParseWKB(raw[4:], binary.LittleEndian.Uint32(raw), 0)
+}
+
+// ParseWKB decodes a geometry in Well-Known Binary format. If typ is not zero,
+// the geometry must be of that type.
+func ParseWKB(wkb []byte, srid uint32, typ GeomType) (*Geom, error) {
+	p := wkbParser{buf: wkb}
+	g, ok := p.geometry(srid, typ)
+	if !ok || len(p.buf) != 0 {
+		return nil, ErrInvalidGeometry
+	}
+	return g, nil
+}
+
+// ParseWKT decodes a geometry in Well-Known Text format. If typ is not zero,
+// the geometry must be of that type.
+func ParseWKT(wkt string, srid uint32, typ GeomType) (*Geom, error) {
+	p := wktParser{s: wkt}
+	g, ok := p.geometry(srid, typ)
+	if !ok {
+		return nil, ErrInvalidGeometry
+	}
+	p.skipSpace()
+	if p.pos != len(p.s) {
+		return nil, ErrInvalidGeometry
+	}
+	return g, nil
+}
+
+// Bytes returns the geometry in MySQL's internal storage format.
+func (g *Geom) Bytes() []byte {
+	dst := binary.LittleEndian.AppendUint32(make([]byte, 0, 32), g.SRID)
+	return g.AppendWKB(dst)
+}
+
+// AppendWKB appends the little-endian Well-Known Binary encoding of the geometry to dst.
+func (g *Geom) AppendWKB(dst []byte) []byte {
+	dst = append(dst, 1)
+	dst = binary.LittleEndian.AppendUint32(dst, uint32(g.Type))
+	switch g.Type {
+	case GeomPoint:
+		dst = appendWKBCoord(dst, g.Coords[0])
+	case GeomLineString:
+		dst = appendWKBCoords(dst, g.Coords)
+	case GeomPolygon:
+		dst = binary.LittleEndian.AppendUint32(dst, uint32(len(g.Rings)))
+		for _, ring := range g.Rings {
+			dst = appendWKBCoords(dst, ring)
+		}
+	default:
+		dst = binary.LittleEndian.AppendUint32(dst, uint32(len(g.Members)))
+		for _, m := range g.Members {
+			dst = m.AppendWKB(dst)
+		}
+	}
+	return dst
+}
+
+func appendWKBCoord(dst []byte, c Coord) []byte {
+	dst = binary.LittleEndian.AppendUint64(dst, math.Float64bits(c.X))
+	return binary.LittleEndian.AppendUint64(dst, math.Float64bits(c.Y))
+}
+
+func appendWKBCoords(dst []byte, coords []Coord) []byte {
+	dst = binary.LittleEndian.AppendUint32(dst, uint32(len(coords)))
+	for _, c := range coords {
+		dst = appendWKBCoord(dst, c)
+	}
+	return dst
+}
+
+// AppendWKT appends the Well-Known Text representation of the geometry to dst,
+// formatted the same way as MySQL's ST_AsText.
+func (g *Geom) AppendWKT(dst []byte) []byte {
+	dst = append(dst, g.Type.String()...)
+	if g.Type == GeomCollection && len(g.Members) == 0 {
+		return append(dst, " EMPTY"...)
+	}
+	return g.appendWKTBody(dst)
+}
+
+func (g *Geom) appendWKTBody(dst []byte) []byte {
+	switch g.Type {
+	case GeomPoint:
+		dst = append(dst, '(')
+		dst = appendWKTCoord(dst, g.Coords[0])
+		return append(dst, ')')
+	case GeomLineString:
+		return appendWKTCoords(dst, g.Coords)
+	case GeomPolygon:
+		dst = append(dst, '(')
+		for i, ring := range g.Rings {
+			if i > 0 {
+				dst = append(dst, ',')
+			}
+			dst = appendWKTCoords(dst, ring)
+		}
+		return append(dst, ')')
+	default:
+		dst = append(dst, '(')
+		for i, m := range g.Members {
+			if i > 0 {
+				dst = append(dst, ',')
+			}
+			if g.Type == GeomCollection {
+				dst = m.AppendWKT(dst)
+			} else {
+				dst = m.appendWKTBody(dst)
+			}
+		}
+		return append(dst, ')')
+	}
+}
+
+func appendWKTCoord(dst []byte, c Coord) []byte {
+	dst = format.AppendFloat(dst, c.X)
+	dst = append(dst, ' ')
+	return format.AppendFloat(dst, c.Y)
+}
+
+func appendWKTCoords(dst []byte, coords []Coord) []byte {
+	dst = append(dst, '(')
+	for i, c := range coords {
+		if i > 0 {
+			dst = append(dst, ',')
+		}
+		dst = appendWKTCoord(dst, c)
+	}
+	return append(dst, ')')
+}
+
+// SwapXY exchanges the X and Y coordinates of every position in the geometry.
+// This converts between the longitude-latitude order MySQL uses for storage
+// and the latitude-longitude order of geographic reference systems like EPSG 4326.
+func (g *Geom) SwapXY() {
+	g.Walk(func(c *Coord) {
+		c.X, c.Y = c.Y, c.X
+	})
+}
+
+// Walk calls fn for every position in the geometry.
+func (g *Geom) Walk(fn func(c *Coord)) {
+	for i := range g.Coords {
+		fn(&g.Coords[i])
+	}
+	for _, ring := range g.Rings {
+		for i := range ring {
+			fn(&ring[i])
+		}
+	}
+	for _, m := range g.Members {
+		m.Walk(fn)
+	}
+}
+
+func validRing(ring []Coord) bool {
+	return len(ring) >= 4 && ring[0] == ring[len(ring)-1]
+}
+
+type wkbParser struct {
+	buf   []byte
+	order binary.ByteOrder
+}
+
+func (p *wkbParser) uint32() (uint32, bool) {
+	if len(p.buf) < 4 {
+		return 0, false
+	}
+	v := p.order.Uint32(p.buf)
+	p.buf = p.buf[4:]
+	return v, true
+}
+
+// count reads an element count and checks that the remaining buffer is large
+// enough to hold that many elements of at least size bytes each.
+func (p *wkbParser) count(min uint32, size int) (uint32, bool) {
+	n, ok := p.uint32()
+	if !ok || n < min || uint64(n)*uint64(size) > uint64(len(p.buf)) {
+		return 0, false
+	}
+	return n, true
+}
+
+func (p *wkbParser) coord() (Coord, bool) {
+	if len(p.buf) < 16 {
+		return Coord{}, false
+	}
+	c := Coord{
+		X: math.Float64frombits(p.order.Uint64(p.buf)),
+		Y: math.Float64frombits(p.order.Uint64(p.buf[8:])),
+	}
+	p.buf = p.buf[16:]
+	return c, validCoord(c)
+}
+
+func (p *wkbParser) coords(min uint32) ([]Coord, bool) {
+	n, ok := p.count(min, 16)
+	if !ok {
+		return nil, false
+	}
+	coords := make([]Coord, n)
+	for i := range coords {
+		if coords[i], ok = p.coord(); !ok {
+			return nil, false
+		}
+	}
+	return coords, true
+}
+
+func (p *wkbParser) geometry(srid uint32, want GeomType) (*Geom, bool) {
+	if len(p.buf) < 5 {
+		return nil, false
+	}
+	switch p.buf[0] {
+	case 0:
+		p.order = binary.BigEndian
+	case 1:
+		p.order = binary.LittleEndian
+	default:
+		return nil, false
+	}
+	p.buf = p.buf[1:]
+
+	t, _ := p.uint32()
+	g := &Geom{SRID: srid, Type: GeomType(t)}
+	if want != 0 && g.Type != want {
+		return nil, false
+	}
+
+	switch g.Type {
+	case GeomPoint:
+		c, ok := p.coord()
+		if !ok {
+			return nil, false
+		}
+		g.Coords = []Coord{c}
+	case GeomLineString:
+		var ok bool
+		if g.Coords, ok = p.coords(2); !ok {
+			return nil, false
+		}
+	case GeomPolygon:
+		n, ok := p.count(1, 4)
+		if !ok {
+			return nil, false
+		}
+		g.Rings = make([][]Coord, n)
+		for i := range g.Rings {
+			if g.Rings[i], ok = p.coords(4); !ok || !validRing(g.Rings[i]) {
+				return nil, false
+			}
+		}
+	case GeomMultiPoint, GeomMultiLineString, GeomMultiPolygon, GeomCollection:
+		var least uint32 = 1
+		if g.Type == GeomCollection {
+			least = 0
+		}
+		n, ok := p.count(least, 5)
+		if !ok {
+			return nil, false
+		}
+		for ; n > 0; n-- {
+			m, ok := p.geometry(srid, g.Type.member())
+			if !ok {
+				return nil, false
+			}
+			g.Members = append(g.Members, m)
+		}
+	default:
+		return nil, false
+	}
+	return g, true
+}
+
+func validCoord(c Coord) bool {
+	return !math.IsNaN(c.X) && !math.IsInf(c.X, 0) && !math.IsNaN(c.Y) && !math.IsInf(c.Y, 0)
+}
+
+type wktParser struct {
+	s   string
+	pos int
+}
+
+func (p *wktParser) skipSpace() {
+	for p.pos < len(p.s) {
+		switch p.s[p.pos] {
+		case ' ', '\t', '\n', '\r':
+			p.pos++
+		default:
+			return
+		}
+	}
+}
+
+func (p *wktParser) peek(c byte) bool {
+	p.skipSpace()
+	return p.pos < len(p.s) && p.s[p.pos] == c
+}
+
+func (p *wktParser) expect(c byte) bool {
+	if p.peek(c) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *wktParser) word() string {
+	p.skipSpace()
+	start := p.pos
+	for p.pos < len(p.s) {
+		c := p.s[p.pos]
+		if (c < 'a' || c > 'z') && (c < 'A' || c > 'Z') {
+			break
+		}
+		p.pos++
+	}
+	return strings.ToUpper(p.s[start:p.pos])
+}
+
+func (p *wktParser) number() (float64, bool) {
+	p.skipSpace()
+	start := p.pos
+	for p.pos < len(p.s) {
+		c := p.s[p.pos]
+		if (c < '0' || c > '9') && c != '.' && c != '-' && c != '+' && c != 'e' && c != 'E' {
+			break
+		}
+		p.pos++
+	}
+	f, err := strconv.ParseFloat(p.s[start:p.pos], 64)
+	return f, err == nil && !math.IsInf(f, 0)
+}
+
+func (p *wktParser) coord() (c Coord, ok bool) {
+	if c.X, ok = p.number(); !ok {
+		return
+	}
+	c.Y, ok = p.number()
+	return
+}
+
+func (p *wktParser) coords(min int) ([]Coord, bool) {
+	if !p.expect('(') {
+		return nil, false
+	}
+	var coords []Coord
+	for {
+		c, ok := p.coord()
+		if !ok {
+			return nil, false
+		}
+		coords = append(coords, c)
+		if !p.expect(',') {
+			break
+		}
+	}
+	return coords, p.expect(')') && len(coords) >= min
+}
+
+func (p *wktParser) rings() ([][]Coord, bool) {
+	if !p.expect('(') {
+		return nil, false
+	}
+	var rings [][]Coord
+	for {
+		ring, ok := p.coords(4)
+		if !ok || !validRing(ring) {
+			return nil, false
+		}
+		rings = append(rings, ring)
+		if !p.expect(',') {
+			break
+		}
+	}
+	return rings, p.expect(')')
+}
+
+// member parses a single member of a MULTI* or GEOMETRYCOLLECTION value.
+func (p *wktParser) member(srid uint32, t GeomType) (*Geom, bool) {
+	g := &Geom{SRID: srid, Type: t}
+	var ok bool
+	switch t {
+	case GeomPoint:
+		// MySQL accepts both MULTIPOINT(1 1,2 2) and MULTIPOINT((1 1),(2 2))
+		paren := p.expect('(')
+		var c Coord
+		if c, ok = p.coord(); ok && paren {
+			ok = p.expect(')')
+		}
+		g.Coords = []Coord{c}
+	case GeomLineString:
+		g.Coords, ok = p.coords(2)
+	case GeomPolygon:
+		g.Rings, ok = p.rings()
+	default:
+		return p.geometry(srid, 0)
+	}
+	return g, ok
+}
+
+func (p *wktParser) geometry(srid uint32, want GeomType) (*Geom, bool) {
+	var t GeomType
+	switch p.word() {
+	case "POINT":
+		t = GeomPoint
+	case "LINESTRING":
+		t = GeomLineString
+	case "POLYGON":
+		t = GeomPolygon
+	case "MULTIPOINT":
+		t = GeomMultiPoint
+	case "MULTILINESTRING":
+		t = GeomMultiLineString
+	case "MULTIPOLYGON":
+		t = GeomMultiPolygon
+	case "GEOMETRYCOLLECTION", "GEOMCOLLECTION":
+		t = GeomCollection
+	default:
+		return nil, false
+	}
+	if want != 0 && t != want {
+		return nil, false
+	}
+
+	switch t {
+	case GeomPoint:
+		if !p.expect('(') {
+			return nil, false
+		}
+		g, ok := p.member(srid, GeomPoint)
+		return g, ok && p.expect(')')
+	case GeomLineString, GeomPolygon:
+		return p.member(srid, t)
+	}
+
+	g := &Geom{SRID: srid, Type: t}
+	if t == GeomCollection {
+		save := p.pos
+		if p.word() == "EMPTY" {
+			return g, true
+		}
+		p.pos = save
+		if !p.expect('(') {
+			return nil, false
+		}
+		if p.expect(')') {
+			return g, true
+		}
+	} else if !p.expect('(') {
+		return nil, false
+	}
+
+	for {
+		m, ok := p.member(srid, t.member())
+		if !ok {
+			return nil, false
+		}
+		g.Members = append(g.Members, m)
+		if !p.expect(',') {
+			break
+		}
+	}
+	return g, p.expect(')')
+}
@@ -0,0 +1,121 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sqltypes
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseWKT(t *testing.T) {
+	tcases := []struct {
+		in  string
+		out string
+	}{
+		{in: "POINT(1 2)", out: "POINT(1 2)"},
+		{in: " point ( -1.5   2e3 ) ", out: "POINT(-1.5 2000)"},
+		{in: "LINESTRING(0 0,1 1, 2 2)", out: "LINESTRING(0 0,1 1,2 2)"},
+		{in: "POLYGON((0 0,10 0,10 10,0 10,0 0),(5 5,7 5,7 7,5 7,5 5))", out: "POLYGON((0 0,10 0,10 10,0 10,0 0),(5 5,7 5,7 7,5 7,5 5))"},
+		{in: "MULTIPOINT(1 1, 2 2)", out: "MULTIPOINT((1 1),(2 2))"},
+		{in: "MULTIPOINT((1 1),(2 2))", out: "MULTIPOINT((1 1),(2 2))"},
+		{in: "MULTILINESTRING((0 0,1 1),(2 2,3 3))", out: "MULTILINESTRING((0 0,1 1),(2 2,3 3))"},
+		{in: "MULTIPOLYGON(((0 0,1 0,1 1,0 0)),((2 2,3 2,3 3,2 2)))", out: "MULTIPOLYGON(((0 0,1 0,1 1,0 0)),((2 2,3 2,3 3,2 2)))"},
+		{in: "GEOMETRYCOLLECTION(POINT(1 1),LINESTRING(0 0,1 1))", out: "GEOMETRYCOLLECTION(POINT(1 1),LINESTRING(0 0,1 1))"},
+		{in: "GEOMCOLLECTION(GEOMETRYCOLLECTION(POINT(1 1)))", out: "GEOMETRYCOLLECTION(GEOMETRYCOLLECTION(POINT(1 1)))"},
+		{in: "GEOMETRYCOLLECTION EMPTY", out: "GEOMETRYCOLLECTION EMPTY"},
+		{in: "GEOMETRYCOLLECTION()", out: "GEOMETRYCOLLECTION EMPTY"},
+		{in: "POINT(1e20 0.000000000000000001)", out: "POINT(1e20 1e-18)"},
+	}
+	for _, tc := range tcases {
+		t.Run(tc.in, func(t *testing.T) {
+			g, err := ParseWKT(tc.in, 0, 0)
+			require.NoError(t, err)
+			assert.Equal(t, tc.out, string(g.AppendWKT(nil)))
+
+			// the internal storage format must round-trip
+			g2, err := ParseGeom(g.Bytes())
+			require.NoError(t, err)
+			assert.Equal(t, g, g2)
+		})
+	}
+}
+
+func TestParseWKTErrors(t *testing.T) {
+	tcases := []string{
+		"",
+		"POINT",
+		"POINT()",
+		"POINT(1)",
+		"POINT(1 2",
+		"POINT(1 2) x",
+		"POINT EMPTY",
+		"LINESTRING(0 0)",
+		"POLYGON((0 0,1 0,1 1))",
+		"POLYGON((0 0,1 0,1 1,0 1))",
+		"MULTIPOINT()",
+		"GEOMETRYCOLLECTION(1 1)",
+		"CIRCLE(1 1)",
+	}
+	for _, tc := range tcases {
+		t.Run(tc, func(t *testing.T) {
+			_, err := ParseWKT(tc, 0, 0)
+			assert.ErrorIs(t, err, ErrInvalidGeometry)
+		})
+	}
+
+	_, err := ParseWKT("LINESTRING(0 0,1 1)", 0, GeomPoint)
+	assert.ErrorIs(t, err, ErrInvalidGeometry)
+}
+
+func TestParseWKB(t *testing.T) {
+	// POINT(1 -1) in big endian WKB
+	wkb, _ := hex.DecodeString("00000000013ff0000000000000bff0000000000000")
+	g, err := ParseWKB(wkb, 4326, 0)
+	require.NoError(t, err)
+	assert.Equal(t, &Geom{SRID: 4326, Type: GeomPoint, Coords: []Coord{{X: 1, Y: -1}}}, g)
+	assert.Equal(t, "e61000000101000000000000000000f03f000000000000f0bf", hex.EncodeToString(g.Bytes()))
+
+	for _, bad := range []string{
+		"",
+		"02010000000000000000000000000000000000000000",
+		"0108000000",
+		"0101000000000000000000f87f0000000000000000",
+		"010200000001000000000000000000000000000000000000000000000000",
+		"010400000000000000",
+		"0101000000000000000000000000000000000000000000",
+	} {
+		wkb, _ := hex.DecodeString(bad)
+		_, err := ParseWKB(wkb, 0, 0)
+		assert.ErrorIsf(t, err, ErrInvalidGeometry, "WKB %s", bad)
+	}
+}
+
+func TestGeomSwapXY(t *testing.T) {
+	g, err := ParseWKT("GEOMETRYCOLLECTION(POINT(1 2),LINESTRING(3 4,5 6))", 4326, 0)
+	require.NoError(t, err)
+	g.SwapXY()
+	assert.Equal(t, "GEOMETRYCOLLECTION(POINT(2 1),LINESTRING(4 3,6 5))", string(g.AppendWKT(nil)))
+
+	v := NewGeometry(g)
+	assert.Equal(t, Geometry, v.Type())
+	g2, err := v.ToGeom()
+	require.NoError(t, err)
+	assert.Equal(t, g, g2)
+}
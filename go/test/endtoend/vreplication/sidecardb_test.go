@@ -39,7 +39,7 @@ var ddls1, ddls2 []string
 func init() {
 	sidecarDBTables = []string{"copy_state", "dt_participant", "dt_state", "heartbeat", "post_copy_action", "redo_state",
 		"redo_statement", "reparent_journal", "resharding_journal", "schema_migrations", "schema_version", "schemacopy", "tables",
-		"vdiff", "vdiff_log", "vdiff_table", "views", "vreplication", "vreplication_log"}
+		"vdiff", "vdiff_log", "vdiff_repair", "vdiff_table", "views", "vreplication", "vreplication_log"}
 	numSidecarDBTables = len(sidecarDBTables)
 	ddls1 = []string{
 		"drop table _vt.vreplication_log",
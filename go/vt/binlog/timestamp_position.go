@@ -0,0 +1,154 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package binlog
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"vitess.io/vitess/go/mysql"
+)
+
+// TimestampPositionPrefix is the prefix of the start positions of streams
+// that start at a point in time instead of at a GTID position. The time is
+// either a unix timestamp or an RFC 3339 time, like "timestamp:1685584800"
+// or "timestamp:2023-06-01T02:00:00Z".
+const TimestampPositionPrefix = "timestamp:"
+
+// ParseTimestampPosition returns the unix timestamp of a start position that
+// is a point in time. It returns false if the position is not a timestamp.
+func ParseTimestampPosition(pos string) (int64, bool, error) {
+	value, ok := strings.CutPrefix(pos, TimestampPositionPrefix)
+	if !ok {
+		return 0, false, nil
+	}
+	if ts, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return ts, true, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return 0, true, fmt.Errorf("invalid timestamp position %q: expected a unix timestamp or an RFC 3339 time", pos)
+	}
+	return t.Unix(), true, nil
+}
+
+// PositionAtTimestamp returns the position a stream has to start from to get
+// all the transactions committed at or after the given timestamp. It walks
+// the binlogs from the last file that started before the timestamp, and
+// returns ErrBinlogUnavailable if the binlogs no longer go back that far.
+// If no transaction was committed since the timestamp, it returns the
+// current position.
+//
+// The connection is used to dump the binlogs, so it can't be used for
+// anything else afterwards.
+func (bc *BinlogConnection) PositionAtTimestamp(ctx context.Context, timestamp int64) (mysql.Position, error) {
+	current, err := bc.Conn.PrimaryPosition()
+	if err != nil {
+		return mysql.Position{}, fmt.Errorf("failed to get the current position: %v", err)
+	}
+	filename, err := bc.findFileBeforeTimestamp(ctx, timestamp)
+	if err != nil {
+		if err == ErrBinlogUnavailable {
+			return mysql.Position{}, fmt.Errorf("binlogs don't go back to %v: %w", time.Unix(timestamp, 0).UTC().Format(time.RFC3339), err)
+		}
+		return mysql.Position{}, err
+	}
+
+	// Start at '4' to skip the Binlog File Header, as in
+	// StartBinlogDumpFromBinlogBeforeTimestamp.
+	if err := bc.Conn.WriteComBinlogDump(bc.serverID, filename, 4, 0); err != nil {
+		return mysql.Position{}, fmt.Errorf("failed to send the ComBinlogDump command: %v", err)
+	}
+
+	// Reads block when the dump waits for new events, so we close the
+	// connection to unblock them if the context is canceled.
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			bc.Conn.Close()
+		case <-done:
+		}
+	}()
+
+	pos, err := positionAtTimestamp(bc.Conn.ReadBinlogEvent, timestamp, current)
+	if err != nil && ctx.Err() != nil {
+		return mysql.Position{}, ctx.Err()
+	}
+	return pos, err
+}
+
+// positionAtTimestamp reads binlog events from the start of a binlog file,
+// and builds the position of the transactions committed before the first
+// one at or after the given timestamp. It stops once the position reaches
+// the current position, since there's no later transaction to wait for.
+func positionAtTimestamp(readEvent func() (mysql.BinlogEvent, error), timestamp int64, current mysql.Position) (mysql.Position, error) {
+	var (
+		format mysql.BinlogFormat
+		pos    mysql.Position
+	)
+	for {
+		ev, err := readEvent()
+		if err != nil {
+			return mysql.Position{}, fmt.Errorf("error reading binlog event: %v", err)
+		}
+		if !ev.IsValid() {
+			return mysql.Position{}, fmt.Errorf("can't parse binlog event: invalid data: %#v", ev)
+		}
+
+		if ev.IsFormatDescription() {
+			if format, err = ev.Format(); err != nil {
+				return mysql.Position{}, fmt.Errorf("can't parse FORMAT_DESCRIPTION_EVENT: %v, event data: %#v", err, ev)
+			}
+			continue
+		}
+		// Only a fake ROTATE_EVENT comes before the FORMAT_DESCRIPTION_EVENT.
+		if format.IsZero() {
+			continue
+		}
+		if ev, _, err = ev.StripChecksum(format); err != nil {
+			return mysql.Position{}, fmt.Errorf("can't strip checksum from binlog event: %v, event data: %#v", err, ev)
+		}
+
+		switch {
+		case ev.IsPreviousGTIDs():
+			// We start from the beginning of a binlog file, so this is the
+			// position before its first transaction.
+			if pos, err = ev.PreviousGTIDs(format); err != nil {
+				return mysql.Position{}, fmt.Errorf("can't parse PREVIOUS_GTIDS_EVENT: %v, event data: %#v", err, ev)
+			}
+		case ev.IsGTID():
+			if int64(ev.Timestamp()) >= timestamp {
+				return pos, nil
+			}
+			gtid, _, err := ev.GTID(format)
+			if err != nil {
+				return mysql.Position{}, fmt.Errorf("can't get GTID from binlog event: %v, event data: %#v", err, ev)
+			}
+			pos = mysql.AppendGTID(pos, gtid)
+		default:
+			continue
+		}
+		if !pos.IsZero() && pos.AtLeast(current) {
+			return pos, nil
+		}
+	}
+}
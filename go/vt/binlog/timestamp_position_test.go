@@ -0,0 +1,131 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package binlog
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/mysql"
+)
+
+func TestParseTimestampPosition(t *testing.T) {
+	testcases := []struct {
+		pos    string
+		ts     int64
+		isTime bool
+		err    string
+	}{{
+		pos: "MySQL56/16b1039f-22b6-11ed-b765-0a43f95f28a3:1-615",
+	}, {
+		pos: "current",
+	}, {
+		pos:    "timestamp:1685584800",
+		ts:     1685584800,
+		isTime: true,
+	}, {
+		pos:    "timestamp:2023-06-01T02:00:00Z",
+		ts:     1685584800,
+		isTime: true,
+	}, {
+		pos:    "timestamp:2023-06-01T04:00:00+02:00",
+		ts:     1685584800,
+		isTime: true,
+	}, {
+		pos:    "timestamp:yesterday",
+		isTime: true,
+		err:    `invalid timestamp position "timestamp:yesterday": expected a unix timestamp or an RFC 3339 time`,
+	}}
+	for _, tc := range testcases {
+		t.Run(tc.pos, func(t *testing.T) {
+			ts, isTime, err := ParseTimestampPosition(tc.pos)
+			assert.Equal(t, tc.isTime, isTime)
+			if tc.err != "" {
+				require.EqualError(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.ts, ts)
+		})
+	}
+}
+
+func TestPositionAtTimestamp(t *testing.T) {
+	f := mysql.NewMariaDBBinlogFormat()
+	s := mysql.NewFakeBinlogStream()
+
+	// Three transactions, committed at 100, 200 and 300.
+	var events []mysql.BinlogEvent
+	events = append(events,
+		mysql.NewFakeRotateEvent(f, s, "binlog.000001"),
+		mysql.NewFormatDescriptionEvent(f, s),
+	)
+	for seq := uint64(1); seq <= 3; seq++ {
+		s.Timestamp = uint32(seq * 100)
+		events = append(events,
+			mysql.NewMariaDBGTIDEvent(f, s, mysql.MariadbGTID{Domain: 0, Server: 1, Sequence: seq}, true /* hasBegin */),
+			mysql.NewXIDEvent(f, s),
+		)
+	}
+
+	position := func(seq uint64) mysql.Position {
+		if seq == 0 {
+			return mysql.Position{}
+		}
+		return mysql.AppendGTID(mysql.Position{}, mysql.MariadbGTID{Domain: 0, Server: 1, Sequence: seq})
+	}
+
+	testcases := []struct {
+		name      string
+		timestamp int64
+		want      mysql.Position
+	}{{
+		name:      "before the first transaction",
+		timestamp: 50,
+		want:      position(0),
+	}, {
+		name:      "at a transaction",
+		timestamp: 200,
+		want:      position(1),
+	}, {
+		name:      "between transactions",
+		timestamp: 250,
+		want:      position(2),
+	}, {
+		name:      "after the last transaction",
+		timestamp: 1000,
+		want:      position(3),
+	}}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			next := 0
+			readEvent := func() (mysql.BinlogEvent, error) {
+				if next == len(events) {
+					return nil, fmt.Errorf("no more events")
+				}
+				next++
+				return events[next-1], nil
+			}
+			pos, err := positionAtTimestamp(readEvent, tc.timestamp, position(3))
+			require.NoError(t, err)
+			assert.True(t, tc.want.Equal(pos), "want %v, got %v", tc.want, pos)
+		})
+	}
+}
@@ -17,9 +17,12 @@ limitations under the License.
 package mysqlctl
 
 import (
+	"bytes"
 	"context"
+	"encoding/binary"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"path"
 	"path/filepath"
@@ -41,6 +44,9 @@ import (
 var (
 	// backupEngineImplementation is the implementation to use for BackupEngine
 	backupEngineImplementation = builtinBackupEngineName
+
+	// binlogFileMagic is the magic number at the beginning of every binlog file
+	binlogFileMagic = []byte{0xfe, 'b', 'i', 'n'}
 )
 
 // BackupEngine is the interface to take a backup with a given engine.
@@ -117,6 +123,9 @@ type RestoreParams struct {
 	// RestoreToPos hints that a point in time recovery is requested, to recover up to the specific given pos.
 	// When empty, the restore is a normal from full backup
 	RestoreToPos mysql.Position
+	// RestoreToTimestamp hints that a point in time recovery is requested, to recover up to the specific given time.
+	// It is mutually exclusive with RestoreToPos.
+	RestoreToTimestamp time.Time
 	// When DryRun is set, no restore actually takes place; but some of its steps are validated.
 	DryRun bool
 	// Stats let's restore engines report detailed restore timings.
@@ -136,13 +145,14 @@ func (p RestoreParams) Copy() RestoreParams {
 		p.Shard,
 		p.StartTime,
 		p.RestoreToPos,
+		p.RestoreToTimestamp,
 		p.DryRun,
 		p.Stats,
 	}
 }
 
 func (p *RestoreParams) IsIncrementalRecovery() bool {
-	return !p.RestoreToPos.IsZero()
+	return !p.RestoreToPos.IsZero() || !p.RestoreToTimestamp.IsZero()
 }
 
 // RestoreEngine is the interface to restore a backup with a given engine.
@@ -173,7 +183,7 @@ func isIncrementalBackup(params BackupParams) bool {
 }
 
 func registerBackupEngineFlags(fs *pflag.FlagSet) {
-	fs.StringVar(&backupEngineImplementation, "backup_engine_implementation", backupEngineImplementation, "Specifies which implementation to use for creating new backups (builtin or xtrabackup). Restores will always be done with whichever engine created a given backup.")
+	fs.StringVar(&backupEngineImplementation, "backup_engine_implementation", backupEngineImplementation, "Specifies which implementation to use for creating new backups (builtin, xtrabackup or mysqlclone). Restores will always be done with whichever engine created a given backup.")
 }
 
 // GetBackupEngine returns the BackupEngine implementation that should be used
@@ -258,6 +268,13 @@ type BackupManifest struct {
 	// Incremental indicates whether this is an incremental backup
 	Incremental bool
 
+	// FromTimestamp and ToTimestamp are only applicable to incremental backups. They are the times
+	// (in RFC 3339 format, UTC) between which the binary log events of the backup happened: the creation
+	// of the first binary log, and the rotation of the last one. They are necessary for a point in time
+	// recovery to a timestamp, and are empty if the binary logs could not be read.
+	FromTimestamp string
+	ToTimestamp   string
+
 	// BackupTime is when the backup was taken in UTC time (RFC 3339 format)
 	BackupTime string
 
@@ -279,6 +296,25 @@ func (m *BackupManifest) HashKey() string {
 	return fmt.Sprintf("%v/%v/%v/%t/%v", m.BackupMethod, m.Position, m.FromPosition, m.Incremental, m.BackupTime)
 }
 
+// RestoresUpTo returns the time up to which the backup is known to restore the data. For a full backup,
+// this is when the backup finished. For an incremental backup, this is the rotation of its last binary log.
+// A zero time is returned if the manifest does not tell.
+func (m *BackupManifest) RestoresUpTo() time.Time {
+	value := m.ToTimestamp
+	if !m.Incremental {
+		value = m.FinishedTime
+		if value == "" {
+			// Backups created before FinishedTime was introduced
+			value = m.BackupTime
+		}
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
+
 // ManifestHandleMap is a utility container to map manifests to handles, making it possible to search for, and iterate, handles based on manifests.
 type ManifestHandleMap struct {
 	mp map[string]backupstorage.BackupHandle
@@ -431,6 +467,12 @@ func FindBackupToRestore(ctx context.Context, params RestoreParams, bhs []backup
 					// this is the most recent backup which is <= desired position
 					return index
 				}
+			case !params.RestoreToTimestamp.IsZero():
+				// restore to specific time
+				if upTo := bm.RestoresUpTo(); !upTo.IsZero() && !upTo.After(params.RestoreToTimestamp) {
+					// this is the most recent backup which is <= desired time
+					return index
+				}
 			default:
 				// restore latest full backup
 				params.Logger.Infof("Restore: found latest backup %v %v to restore", bh.Directory(), bh.Name())
@@ -453,15 +495,20 @@ func FindBackupToRestore(ctx context.Context, params RestoreParams, bhs []backup
 	restorePath := &RestorePath{
 		manifestHandleMap: manifestHandleMap,
 	}
-	if params.RestoreToPos.IsZero() {
+	if !params.IsIncrementalRecovery() {
 		// restoring from a single full backup:
 		restorePath.Add(manifests[0])
 		return restorePath, nil
 	}
-	// restore to a position (using incremental backups):
+	// restore to a position or to a time (using incremental backups):
 	// we calculate a possible restore path based on the manifests. The resulting manifests are
 	// a sorted subsequence, with the full backup first, and zero or more incremental backups to follow.
-	manifests, err := FindPITRPath(params.RestoreToPos.GTIDSet, manifests)
+	var err error
+	if params.RestoreToTimestamp.IsZero() {
+		manifests, err = FindPITRPath(params.RestoreToPos.GTIDSet, manifests)
+	} else {
+		manifests, err = FindPITRToTimestampPath(params.RestoreToTimestamp, manifests)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -691,3 +738,23 @@ func binlogFilesToBackup(cnf *Mycnf, binlogFiles []string) (result []FileEntry,
 	}
 	return result, totalSize, nil
 }
+
+// binlogFileTimestamp returns the time at which the given binlog file (identified by file name, no path)
+// was created, which is the timestamp of its first event.
+func binlogFileTimestamp(cnf *Mycnf, binlogFile string) (time.Time, error) {
+	f, err := os.Open(filepath.Join(filepath.Dir(cnf.BinLogPath), binlogFile))
+	if err != nil {
+		return time.Time{}, err
+	}
+	defer f.Close()
+	// A binlog file begins with a 4 bytes magic number, followed by the header of the
+	// format description event. The header begins with the timestamp of the event.
+	var header [8]byte
+	if _, err := io.ReadFull(f, header[:]); err != nil {
+		return time.Time{}, vterrors.Wrapf(err, "cannot read header of binlog file %v", binlogFile)
+	}
+	if !bytes.Equal(header[:4], binlogFileMagic) {
+		return time.Time{}, vterrors.Errorf(vtrpc.Code_FAILED_PRECONDITION, "%v is not a binlog file, or is encrypted", binlogFile)
+	}
+	return time.Unix(int64(binary.LittleEndian.Uint32(header[4:])), 0).UTC(), nil
+}
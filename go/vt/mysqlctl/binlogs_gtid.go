@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"sort"
 	"strings"
+	"time"
 
 	"vitess.io/vitess/go/mysql"
 	"vitess.io/vitess/go/vt/proto/vtrpc"
@@ -160,15 +161,7 @@ func IsValidIncrementalBakcup(baseGTIDSet mysql.GTIDSet, purgedGTIDSet mysql.GTI
 // The path ends with restoreToGTIDSet or goes beyond it. No shorter path will do the same.
 // The function returns an error when a path cannot be found.
 func FindPITRPath(restoreToGTIDSet mysql.GTIDSet, manifests [](*BackupManifest)) (shortestPath [](*BackupManifest), err error) {
-	sortedManifests := make([](*BackupManifest), 0, len(manifests))
-	for _, m := range manifests {
-		if m != nil {
-			sortedManifests = append(sortedManifests, m)
-		}
-	}
-	sort.SliceStable(sortedManifests, func(i, j int) bool {
-		return sortedManifests[j].Position.GTIDSet.Union(sortedManifests[i].PurgedPosition.GTIDSet).Contains(sortedManifests[i].Position.GTIDSet)
-	})
+	sortedManifests := sortManifestsByPosition(manifests)
 	mostRelevantFullBackupIndex := -1 // an invalid value
 	for i, manifest := range sortedManifests {
 		if manifest.Incremental {
@@ -244,3 +237,71 @@ func FindPITRPath(restoreToGTIDSet mysql.GTIDSet, manifests [](*BackupManifest))
 	}
 	return shortestPath, nil
 }
+
+// FindPITRToTimestampPath evaluates a path to recover up to restoreToTimestamp. The path is composed of:
+// - the most recent full backup that finished at or before restoreToTimestamp, followed by:
+// - zero or more incremental backups, the last of which covers restoreToTimestamp
+// Binary log events that happened after restoreToTimestamp are to be discarded by the restore.
+// The function returns an error when a path cannot be found.
+func FindPITRToTimestampPath(restoreToTimestamp time.Time, manifests [](*BackupManifest)) (path [](*BackupManifest), err error) {
+	sortedManifests := sortManifestsByPosition(manifests)
+	mostRelevantFullBackupIndex := -1 // an invalid value
+	for i, manifest := range sortedManifests {
+		if manifest.Incremental {
+			continue
+		}
+		if upTo := manifest.RestoresUpTo(); !upTo.IsZero() && !upTo.After(restoreToTimestamp) {
+			// This backup is <= desired restore time, therefore it's valid
+			mostRelevantFullBackupIndex = i
+		}
+	}
+	if mostRelevantFullBackupIndex < 0 {
+		// No full backup prior to desired restore time...
+		return nil, vterrors.Errorf(vtrpc.Code_FAILED_PRECONDITION, "no full backup found before timestamp %v", restoreToTimestamp.UTC().Format(time.RFC3339))
+	}
+	// Incremental backups taken before the full backup are not of interest.
+	sortedManifests = sortedManifests[mostRelevantFullBackupIndex:]
+	fullBackup := sortedManifests[0]
+	purgedGTIDSet := fullBackup.PurgedPosition.GTIDSet
+
+	// findPath returns the first path found. A path ends as soon as its last backup covers the desired restore time.
+	var findPath func(baseGTIDSet mysql.GTIDSet, pathManifests []*BackupManifest, remainingManifests []*BackupManifest) []*BackupManifest
+	findPath = func(baseGTIDSet mysql.GTIDSet, pathManifests []*BackupManifest, remainingManifests []*BackupManifest) []*BackupManifest {
+		if upTo := pathManifests[len(pathManifests)-1].RestoresUpTo(); !upTo.IsZero() && !upTo.Before(restoreToTimestamp) {
+			// successful end of path
+			return pathManifests
+		}
+		if len(remainingManifests) == 0 {
+			// end of the road. No possibilities from here.
+			return nil
+		}
+		// if the next manifest is eligible to be part of the path, try it out
+		if IsValidIncrementalBakcup(baseGTIDSet, purgedGTIDSet, remainingManifests[0]) {
+			nextGTIDSet := baseGTIDSet.Union(remainingManifests[0].Position.GTIDSet)
+			if path := findPath(nextGTIDSet, append(pathManifests, remainingManifests[0]), remainingManifests[1:]); path != nil {
+				return path
+			}
+		}
+		// also, try without the next manifest
+		return findPath(baseGTIDSet, pathManifests, remainingManifests[1:])
+	}
+	path = findPath(fullBackup.Position.GTIDSet, []*BackupManifest{fullBackup}, sortedManifests[1:])
+	if path == nil {
+		return nil, vterrors.Errorf(vtrpc.Code_FAILED_PRECONDITION, "no path found that leads to timestamp %v", restoreToTimestamp.UTC().Format(time.RFC3339))
+	}
+	return path, nil
+}
+
+// sortManifestsByPosition returns the non-nil manifests, sorted by their position, ascending.
+func sortManifestsByPosition(manifests [](*BackupManifest)) [](*BackupManifest) {
+	sortedManifests := make([](*BackupManifest), 0, len(manifests))
+	for _, m := range manifests {
+		if m != nil {
+			sortedManifests = append(sortedManifests, m)
+		}
+	}
+	sort.SliceStable(sortedManifests, func(i, j int) bool {
+		return sortedManifests[j].Position.GTIDSet.Union(sortedManifests[i].PurgedPosition.GTIDSet).Contains(sortedManifests[i].Position.GTIDSet)
+	})
+	return sortedManifests
+}
@@ -20,6 +20,7 @@ import (
 	"context"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -578,3 +579,124 @@ func TestFindPITRPath(t *testing.T) {
 		})
 	}
 }
+
+func TestFindPITRToTimestampPath(t *testing.T) {
+	generatePosition := func(posRange string) mysql.Position {
+		return mysql.MustParsePosition(mysql.Mysql56FlavorID, fmt.Sprintf("16b1039f-22b6-11ed-b765-0a43f95f28a3:%s", posRange))
+	}
+	at := func(hour, min int) time.Time {
+		return time.Date(2023, 5, 1, hour, min, 0, 0, time.UTC)
+	}
+	fullManifest := func(backupPos string, finishedTime time.Time) *BackupManifest {
+		return &BackupManifest{
+			Position:     generatePosition(backupPos),
+			BackupTime:   manifestTimestamp(finishedTime.Add(-time.Minute)),
+			FinishedTime: manifestTimestamp(finishedTime),
+		}
+	}
+	incrementalManifest := func(backupPos string, backupFromPos string, fromTimestamp, toTimestamp time.Time) *BackupManifest {
+		return &BackupManifest{
+			Position:      generatePosition(backupPos),
+			FromPosition:  generatePosition(backupFromPos),
+			Incremental:   true,
+			FromTimestamp: manifestTimestamp(fromTimestamp),
+			ToTimestamp:   manifestTimestamp(toTimestamp),
+		}
+	}
+	fullBackups := []*BackupManifest{
+		fullManifest("1-50", at(10, 50)),
+		fullManifest("1-5", at(10, 5)),
+		fullManifest("1-80", at(11, 20)),
+	}
+	incrementalBackups := []*BackupManifest{
+		incrementalManifest("1-34", "1-5", at(10, 5), at(10, 34)),
+		incrementalManifest("1-52", "1-34", at(10, 34), at(10, 52)),
+		incrementalManifest("1-70", "1-52", at(10, 52), at(11, 10)),
+		incrementalManifest("1-90", "1-70", at(11, 10), at(11, 30)),
+	}
+	tt := []struct {
+		name                       string
+		restoreToTimestamp         time.Time
+		incrementalBackups         []*BackupManifest
+		expectFullManifest         *BackupManifest
+		expectIncrementalManifests []*BackupManifest
+		expectError                string
+	}{
+		{
+			name:               "10:40",
+			restoreToTimestamp: at(10, 40),
+			expectFullManifest: fullManifest("1-5", at(10, 5)),
+			expectIncrementalManifests: []*BackupManifest{
+				incrementalManifest("1-34", "1-5", at(10, 5), at(10, 34)),
+				incrementalManifest("1-52", "1-34", at(10, 34), at(10, 52)),
+			},
+		},
+		{
+			name:               "10:50",
+			restoreToTimestamp: at(10, 50),
+			expectFullManifest: fullManifest("1-50", at(10, 50)),
+		},
+		{
+			name:               "11:00",
+			restoreToTimestamp: at(11, 0),
+			expectFullManifest: fullManifest("1-50", at(10, 50)),
+			expectIncrementalManifests: []*BackupManifest{
+				incrementalManifest("1-52", "1-34", at(10, 34), at(10, 52)),
+				incrementalManifest("1-70", "1-52", at(10, 52), at(11, 10)),
+			},
+		},
+		{
+			name:               "11:25",
+			restoreToTimestamp: at(11, 25),
+			expectFullManifest: fullManifest("1-80", at(11, 20)),
+			expectIncrementalManifests: []*BackupManifest{
+				incrementalManifest("1-90", "1-70", at(11, 10), at(11, 30)),
+			},
+		},
+		{
+			name:               "fail 10:00",
+			restoreToTimestamp: at(10, 0),
+			expectError:        "no full backup",
+		},
+		{
+			name:               "fail 11:45",
+			restoreToTimestamp: at(11, 45),
+			expectError:        "no path found",
+		},
+		{
+			name:               "fail 10:40 without timestamps",
+			restoreToTimestamp: at(10, 40),
+			incrementalBackups: []*BackupManifest{
+				incrementalManifest("1-34", "1-5", at(10, 5), at(10, 34)),
+				incrementalManifest("1-52", "1-34", time.Time{}, time.Time{}),
+			},
+			expectError: "no path found",
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.incrementalBackups == nil {
+				tc.incrementalBackups = incrementalBackups
+			}
+			var manifests []*BackupManifest
+			manifests = append(manifests, fullBackups...)
+			manifests = append(manifests, tc.incrementalBackups...)
+
+			path, err := FindPITRToTimestampPath(tc.restoreToTimestamp, manifests)
+			if tc.expectError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.expectError)
+				return
+			}
+			require.NoErrorf(t, err, "%v", err)
+			require.NotEmpty(t, path)
+			assert.Equal(t, tc.expectFullManifest, path[0])
+			if tc.expectIncrementalManifests == nil {
+				tc.expectIncrementalManifests = []*BackupManifest{}
+			}
+			expected := BackupManifestPath(tc.expectIncrementalManifests)
+			got := BackupManifestPath(path[1:])
+			assert.Equal(t, expected, got, "expected: %s, got: %s", expected.String(), got.String())
+		})
+	}
+}
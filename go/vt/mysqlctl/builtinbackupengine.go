@@ -328,7 +328,19 @@ func (be *BuiltinBackupEngine) executeIncrementalBackup(ctx context.Context, par
 	// incrementalBackupFromGTID is the "previous GTIDs" of the first binlog file we back up.
 	// It is a fact that incrementalBackupFromGTID is earlier or equal to params.IncrementalFromPos.
 	// In the backup manifest file, we document incrementalBackupFromGTID, not the user's requested position.
-	if err := be.backupFiles(ctx, params, bh, incrementalBackupToPosition, gtidPurged, incrementalBackupFromPosition, binaryLogsToBackup, serverUUID); err != nil {
+	//
+	// Similarly, the backup covers the time between the creation of the first binlog file we back up, and the
+	// creation of the binlog file that follows the last one we back up, which happened when the latter was rotated.
+	var incrementalBackupFromTimestamp, incrementalBackupToTimestamp time.Time
+	incrementalBackupFromTimestamp, err = binlogFileTimestamp(params.Cnf, binaryLogsToBackup[0])
+	if err == nil {
+		incrementalBackupToTimestamp, err = binlogFileTimestamp(params.Cnf, binaryLogs[len(binaryLogs)-1])
+	}
+	if err != nil {
+		params.Logger.Warningf("cannot read binlog timestamps, the incremental backup cannot be used to restore to a timestamp: %v", err)
+		incrementalBackupFromTimestamp, incrementalBackupToTimestamp = time.Time{}, time.Time{}
+	}
+	if err := be.backupFiles(ctx, params, bh, incrementalBackupToPosition, gtidPurged, incrementalBackupFromPosition, incrementalBackupFromTimestamp, incrementalBackupToTimestamp, binaryLogsToBackup, serverUUID); err != nil {
 		return false, err
 	}
 	return true, nil
@@ -427,7 +439,7 @@ func (be *BuiltinBackupEngine) executeFullBackup(ctx context.Context, params Bac
 	}
 
 	// Backup everything, capture the error.
-	backupErr := be.backupFiles(ctx, params, bh, replicationPosition, gtidPurgedPosition, mysql.Position{}, nil, serverUUID)
+	backupErr := be.backupFiles(ctx, params, bh, replicationPosition, gtidPurgedPosition, mysql.Position{}, time.Time{}, time.Time{}, nil, serverUUID)
 	usable := backupErr == nil
 
 	// Try to restart mysqld, use background context in case we timed out the original context
@@ -510,6 +522,8 @@ func (be *BuiltinBackupEngine) backupFiles(
 	replicationPosition mysql.Position,
 	purgedPosition mysql.Position,
 	fromPosition mysql.Position,
+	fromTimestamp time.Time,
+	toTimestamp time.Time,
 	binlogFiles []string,
 	serverUUID string,
 ) (finalErr error) {
@@ -600,6 +614,8 @@ func (be *BuiltinBackupEngine) backupFiles(
 			PurgedPosition: purgedPosition,
 			FromPosition:   fromPosition,
 			Incremental:    !fromPosition.IsZero(),
+			FromTimestamp:  manifestTimestamp(fromTimestamp),
+			ToTimestamp:    manifestTimestamp(toTimestamp),
 			ServerUUID:     serverUUID,
 			TabletAlias:    params.TabletAlias,
 			Keyspace:       params.Keyspace,
@@ -625,6 +641,14 @@ func (be *BuiltinBackupEngine) backupFiles(
 	return nil
 }
 
+// manifestTimestamp formats a time for the manifest, in RFC 3339 format, UTC. A zero time is left empty.
+func manifestTimestamp(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
 type backupPipe struct {
 	filename string
 	maxSize  int64
@@ -832,7 +856,8 @@ func (be *BuiltinBackupEngine) executeRestoreFullBackup(ctx context.Context, par
 
 // executeRestoreIncrementalBackup executes a restore of an incremental backup, and expect to run on top of a full backup's restore.
 // It restores any (zero or more) binary log files and applies them onto the underlying database one at a time, but only applies those transactions
-// that fall within params.RestoreToPos.GTIDSet. The rest (typically a suffix of the last binary log) are discarded.
+// that fall within params.RestoreToPos.GTIDSet, or that happened at or before params.RestoreToTimestamp. The rest (typically a suffix of the
+// last binary log) are discarded.
 // The underlying mysql database is expected to be up and running.
 func (be *BuiltinBackupEngine) executeRestoreIncrementalBackup(ctx context.Context, params RestoreParams, bh backupstorage.BackupHandle, bm builtinBackupManifest) error {
 	params.Logger.Infof("Restoring incremental backup to position: %v", bm.Position)
@@ -848,7 +873,7 @@ func (be *BuiltinBackupEngine) executeRestoreIncrementalBackup(ctx context.Conte
 		if err != nil {
 			return vterrors.Wrap(err, "failed to restore file")
 		}
-		if err := mysqld.ApplyBinlogFile(ctx, binlogFile, params.RestoreToPos); err != nil {
+		if err := mysqld.ApplyBinlogFile(ctx, binlogFile, params.RestoreToPos, params.RestoreToTimestamp); err != nil {
 			return vterrors.Wrapf(err, "failed to apply binlog file %v", binlogFile)
 		}
 		defer os.Remove(binlogFile)
@@ -32,6 +32,7 @@ import (
 	"vitess.io/vitess/go/vt/mysqlctl/mysqlctlclient"
 
 	mysqlctlpb "vitess.io/vitess/go/vt/proto/mysqlctl"
+	vttimepb "vitess.io/vitess/go/vt/proto/vttime"
 )
 
 type client struct {
@@ -84,10 +85,11 @@ func (c *client) RunMysqlUpgrade(ctx context.Context) error {
 }
 
 // ApplyBinlogFile is part of the MysqlctlClient interface.
-func (c *client) ApplyBinlogFile(ctx context.Context, binlogFileName, binlogRestorePosition string) error {
+func (c *client) ApplyBinlogFile(ctx context.Context, binlogFileName, binlogRestorePosition string, binlogRestoreDatetime *vttimepb.Time) error {
 	req := &mysqlctlpb.ApplyBinlogFileRequest{
 		BinlogFileName:        binlogFileName,
 		BinlogRestorePosition: binlogRestorePosition,
+		BinlogRestoreDatetime: binlogRestoreDatetime,
 	}
 	return c.withRetry(ctx, func() error {
 		_, err := c.c.ApplyBinlogFile(ctx, req)
@@ -18,6 +18,7 @@ package mysqlctl
 
 import (
 	"context"
+	"time"
 
 	"vitess.io/vitess/go/mysql"
 	"vitess.io/vitess/go/sqltypes"
@@ -34,7 +35,7 @@ type MysqlDaemon interface {
 	Start(ctx context.Context, cnf *Mycnf, mysqldArgs ...string) error
 	Shutdown(ctx context.Context, cnf *Mycnf, waitForMysqld bool) error
 	RunMysqlUpgrade(ctx context.Context) error
-	ApplyBinlogFile(ctx context.Context, binlogFile string, restorePos mysql.Position) error
+	ApplyBinlogFile(ctx context.Context, binlogFile string, restorePos mysql.Position, restoreTime time.Time) error
 	ReinitConfig(ctx context.Context, cnf *Mycnf) error
 	Wait(ctx context.Context, cnf *Mycnf) error
 
@@ -0,0 +1,422 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mysqlctl
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"net"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/pflag"
+	"golang.org/x/sync/semaphore"
+
+	"vitess.io/vitess/go/mysql"
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/dbconfigs"
+	"vitess.io/vitess/go/vt/mysqlctl/backupstorage"
+	"vitess.io/vitess/go/vt/proto/vtrpc"
+	"vitess.io/vitess/go/vt/servenv"
+	"vitess.io/vitess/go/vt/vterrors"
+)
+
+// MySQLCloneEngine encapsulates the logic of the mysqlclone engine.
+// It implements the BackupEngine interface by cloning the local data with the
+// MySQL CLONE plugin into a directory, which is then copied to the backup
+// storage. The copy is restored like a builtin backup. It can also provision
+// a new replica with a remote clone of a donor, see CloneFromDonor.
+type MySQLCloneEngine struct {
+}
+
+var (
+	// user and password that the recipient of a remote clone connects to the donor with.
+	// The user must have the BACKUP_ADMIN privilege on the donor. The password is read
+	// from the db credentials server when the user is known to it.
+	mysqlCloneUser     string
+	mysqlClonePassword string
+)
+
+const (
+	mysqlCloneEngineName = "mysqlclone"
+
+	// innodbRedoDir is the directory of the redo log files in MySQL 8.0.30 and later
+	innodbRedoDir = "#innodb_redo"
+)
+
+func init() {
+	for _, cmd := range []string{"vtcombo", "vttablet", "vtbackup", "vttestserver"} {
+		servenv.OnParseFor(cmd, registerMySQLCloneEngineFlags)
+	}
+}
+
+func registerMySQLCloneEngineFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&mysqlCloneUser, "mysql_clone_user", mysqlCloneUser, "User to connect to the donor of a remote MySQL clone with. This user must have the BACKUP_ADMIN privilege on the donor.")
+	fs.StringVar(&mysqlClonePassword, "mysql_clone_password", mysqlClonePassword, "Password of --mysql_clone_user, if the user is not found by the db credentials server (see --db-credentials-server).")
+}
+
+// ExecuteBackup runs a backup based on given params. Only full backups are supported.
+// The function returns a boolean that indicates if the backup is usable, and an overall error.
+func (be *MySQLCloneEngine) ExecuteBackup(ctx context.Context, params BackupParams, bh backupstorage.BackupHandle) (complete bool, finalErr error) {
+	params.Logger.Infof("Executing Backup at %v for keyspace/shard %v/%v on tablet %v, concurrency: %v, compress: %v",
+		params.BackupTime, params.Keyspace, params.Shard, params.TabletAlias, params.Concurrency, backupStorageCompress)
+
+	if params.IncrementalFromPos != "" {
+		return false, vterrors.New(vtrpc.Code_INVALID_ARGUMENT, "incremental backups not supported in mysqlclone engine.")
+	}
+	// an extension is required when using an external compressor
+	if backupStorageCompress && ExternalCompressorCmd != "" && ExternalCompressorExt == "" {
+		return false, vterrors.New(vtrpc.Code_INVALID_ARGUMENT,
+			"flag --external-compressor-extension not provided when using an external compressor")
+	}
+	if err := enableClonePlugin(ctx, params.Mysqld); err != nil {
+		return false, err
+	}
+	gtidPurgedPosition, err := params.Mysqld.GetGTIDPurged(ctx)
+	if err != nil {
+		return false, vterrors.Wrap(err, "can't get gtid_purged")
+	}
+	serverUUID, err := params.Mysqld.GetServerUUID(ctx)
+	if err != nil {
+		return false, vterrors.Wrap(err, "can't get server uuid")
+	}
+
+	// The clone directory must not exist: mysqld creates it, and must be able to write in it.
+	cloneDir := path.Join(params.Cnf.TmpDir, "clone-"+params.BackupTime.UTC().Format(BackupTimestampFormat))
+	defer os.RemoveAll(cloneDir)
+	params.Logger.Infof("Cloning the local data into %v", cloneDir)
+	if _, err := params.Mysqld.FetchSuperQuery(ctx, "CLONE LOCAL DATA DIRECTORY = "+sqltypes.EncodeStringSQL(cloneDir)); err != nil {
+		return false, vterrors.Wrap(err, "failed to clone the local data")
+	}
+	replicationPosition, err := cloneStatusPosition(ctx, params.Mysqld)
+	if err != nil {
+		return false, err
+	}
+	params.Logger.Infof("Cloned the local data at position %v", replicationPosition)
+
+	fes, err := cloneFilesToBackup(cloneDir)
+	if err != nil {
+		return false, vterrors.Wrap(err, "can't find files to backup")
+	}
+	params.Logger.Infof("found %v files to backup", len(fes))
+	if err := be.backupFiles(ctx, params, bh, cloneDir, fes); err != nil {
+		return false, err
+	}
+
+	// open the MANIFEST
+	wc, err := bh.AddFile(ctx, backupManifestFileName, backupstorage.FileSizeUnknown)
+	if err != nil {
+		return false, vterrors.Wrapf(err, "cannot add %v to backup", backupManifestFileName)
+	}
+	defer closeFile(wc, backupManifestFileName, params.Logger, &finalErr)
+
+	// JSON-encode and write the MANIFEST. The clone is restored like a builtin backup.
+	bm := &builtinBackupManifest{
+		// Common base fields
+		BackupManifest: BackupManifest{
+			BackupMethod:   mysqlCloneEngineName,
+			Position:       replicationPosition,
+			PurgedPosition: gtidPurgedPosition,
+			ServerUUID:     serverUUID,
+			TabletAlias:    params.TabletAlias,
+			Keyspace:       params.Keyspace,
+			Shard:          params.Shard,
+			BackupTime:     params.BackupTime.UTC().Format(time.RFC3339),
+			FinishedTime:   time.Now().UTC().Format(time.RFC3339),
+		},
+
+		// Builtin-specific fields
+		FileEntries:          fes,
+		SkipCompress:         !backupStorageCompress,
+		CompressionEngine:    CompressionEngineName,
+		ExternalDecompressor: ManifestExternalDecompressorCmd,
+	}
+	data, err := json.MarshalIndent(bm, "", "  ")
+	if err != nil {
+		return false, vterrors.Wrapf(err, "cannot JSON encode %v", backupManifestFileName)
+	}
+	if _, err := wc.Write([]byte(data)); err != nil {
+		return false, vterrors.Wrapf(err, "cannot write %v", backupManifestFileName)
+	}
+
+	params.Logger.Infof("Backup completed")
+	return true, nil
+}
+
+// backupFiles copies the files of the clone directory to the backup storage.
+func (be *MySQLCloneEngine) backupFiles(ctx context.Context, params BackupParams, bh backupstorage.BackupHandle, cloneDir string, fes []FileEntry) error {
+	// The files are all found in the clone directory, whatever their base.
+	cloneCnf := *params.Cnf
+	cloneCnf.DataDir = cloneDir
+	cloneCnf.InnodbDataHomeDir = cloneDir
+	cloneCnf.InnodbLogGroupHomeDir = cloneDir
+	params = params.Copy()
+	params.Cnf = &cloneCnf
+
+	builtin := &BuiltinBackupEngine{}
+	sema := semaphore.NewWeighted(int64(params.Concurrency))
+	wg := sync.WaitGroup{}
+	for i := range fes {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			fe := &fes[i]
+			// Wait until we are ready to go, return if we encounter an error
+			if err := sema.Acquire(ctx, 1); err != nil {
+				bh.RecordError(vterrors.Wrapf(err, "unable to acquire semaphore needed to backup file %s", fe.Name))
+				return
+			}
+			defer sema.Release(1)
+			if bh.HasErrors() {
+				return
+			}
+			// Backup the individual file.
+			name := fmt.Sprintf("%v", i)
+			bh.RecordError(builtin.backupFile(ctx, params, bh, fe, name))
+		}(i)
+	}
+	wg.Wait()
+
+	if bh.HasErrors() {
+		return bh.Error()
+	}
+	return nil
+}
+
+// cloneFilesToBackup returns the file entries of a clone directory. The files go to the
+// same directories as the files of a builtin backup when restored.
+func cloneFilesToBackup(cloneDir string) (result []FileEntry, err error) {
+	err = filepath.WalkDir(cloneDir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		name, err := filepath.Rel(cloneDir, p)
+		if err != nil {
+			return err
+		}
+		base := backupData
+		switch {
+		case strings.HasPrefix(name, "ibdata"):
+			base = backupInnodbDataHomeDir
+		case strings.HasPrefix(name, "ib_logfile"), strings.HasPrefix(name, innodbRedoDir+string(filepath.Separator)):
+			base = backupInnodbLogGroupHomeDir
+		}
+		result = append(result, FileEntry{
+			Base: base,
+			Name: name,
+		})
+		return nil
+	})
+	return result, err
+}
+
+// ExecuteRestore restores from a backup. If the restore is successful
+// we return the position from which replication should start
+// otherwise an error is returned
+func (be *MySQLCloneEngine) ExecuteRestore(ctx context.Context, params RestoreParams, bh backupstorage.BackupHandle) (*BackupManifest, error) {
+	var bm builtinBackupManifest
+	if err := getBackupManifestInto(ctx, bh, &bm); err != nil {
+		return nil, err
+	}
+
+	// mark restore as in progress
+	if err := createStateFile(params.Cnf); err != nil {
+		return nil, err
+	}
+
+	builtin := &BuiltinBackupEngine{}
+	if err := builtin.executeRestoreFullBackup(ctx, params, bh, bm); err != nil {
+		return nil, err
+	}
+	params.Logger.Infof("Restore: returning replication position %v", bm.Position)
+	return &bm.BackupManifest, nil
+}
+
+// ShouldDrainForBackup satisfies the BackupEngine interface
+// MySQL clones online, hence false
+func (be *MySQLCloneEngine) ShouldDrainForBackup() bool {
+	return false
+}
+
+// CloneFromDonor replaces the data of the local mysqld with a physical copy of the data
+// of the donor, using the MySQL CLONE plugin. The clone plugin must be active on the donor,
+// see CheckClonePlugin. It returns the position of the copy.
+func CloneFromDonor(ctx context.Context, params RestoreParams, donorHost string, donorPort int32) (mysql.Position, error) {
+	user, password, err := cloneUserAndPassword()
+	if err != nil {
+		return mysql.Position{}, err
+	}
+	donor := net.JoinHostPort(donorHost, strconv.Itoa(int(donorPort)))
+
+	params.Logger.Infof("Clone: enabling the clone plugin")
+	if err := enableClonePlugin(ctx, params.Mysqld); err != nil {
+		return mysql.Position{}, err
+	}
+	if _, err := params.Mysqld.FetchSuperQuery(ctx, "SET GLOBAL clone_valid_donor_list = "+sqltypes.EncodeStringSQL(donor)); err != nil {
+		return mysql.Position{}, vterrors.Wrapf(err, "failed to allow %v as a clone donor", donor)
+	}
+
+	// mark restore as in progress
+	if err := createStateFile(params.Cnf); err != nil {
+		return mysql.Position{}, err
+	}
+
+	params.Logger.Infof("Clone: cloning the data of %v", donor)
+	query := fmt.Sprintf("CLONE INSTANCE FROM %s@%s:%d IDENTIFIED BY %s",
+		sqltypes.EncodeStringSQL(user), sqltypes.EncodeStringSQL(donorHost), donorPort, sqltypes.EncodeStringSQL(password))
+	_, err = params.Mysqld.FetchSuperQuery(ctx, query)
+	// Once the data is cloned, mysqld restarts if it is managed by a supervisor process.
+	// Either way, the connection that ran the clone is lost.
+	if err != nil {
+		sqlErr, ok := mysql.NewSQLErrorFromError(err).(*mysql.SQLError)
+		switch {
+		case !ok:
+			return mysql.Position{}, vterrors.Wrapf(err, "failed to clone the data of %v", donor)
+		case sqlErr.Number() == mysql.ERRestartServerFailed:
+			params.Logger.Infof("Clone: restarting mysqld")
+			if err := params.Mysqld.Shutdown(ctx, params.Cnf, true); err != nil {
+				return mysql.Position{}, err
+			}
+			if err := params.Mysqld.Start(ctx, params.Cnf); err != nil {
+				return mysql.Position{}, err
+			}
+		case sqlErr.Number() == mysql.CRServerLost, sqlErr.Number() == mysql.CRServerGone:
+			params.Logger.Infof("Clone: waiting for mysqld to restart")
+		default:
+			// Do not return the error as is: it holds the query, and the password in it.
+			return mysql.Position{}, vterrors.Errorf(vtrpc.Code_UNKNOWN, "failed to clone the data of %v: %v (errno %v)", donor, sqlErr.Message, sqlErr.Number())
+		}
+	}
+	if err := params.Mysqld.Wait(ctx, params.Cnf); err != nil {
+		return mysql.Position{}, err
+	}
+	pos, err := cloneStatusPosition(ctx, params.Mysqld)
+	if err != nil {
+		return mysql.Position{}, err
+	}
+	params.Logger.Infof("Clone: cloned the data of %v at position %v", donor, pos)
+
+	if err := removeStateFile(params.Cnf); err != nil {
+		return mysql.Position{}, err
+	}
+	return pos, nil
+}
+
+// cloneUserAndPassword returns the user and password to connect to the donor of a
+// remote clone with. Like the db users, the user is looked up in the credentials
+// server, and --mysql_clone_password is used if it is unknown to it.
+func cloneUserAndPassword() (string, string, error) {
+	if mysqlCloneUser == "" {
+		return "", "", vterrors.New(vtrpc.Code_INVALID_ARGUMENT, "--mysql_clone_user must be specified.")
+	}
+	user, password, err := dbconfigs.GetCredentialsServer().GetUserAndPassword(mysqlCloneUser)
+	switch err {
+	case nil:
+		return user, password, nil
+	case dbconfigs.ErrUnknownUser:
+		return mysqlCloneUser, mysqlClonePassword, nil
+	default:
+		return "", "", vterrors.Wrapf(err, "failed to get the password of %v", mysqlCloneUser)
+	}
+}
+
+// enableClonePlugin installs the MySQL clone plugin on the local mysqld, unless it is
+// already active.
+func enableClonePlugin(ctx context.Context, mysqld MysqlDaemon) error {
+	status, err := clonePluginStatus(ctx, mysqld.FetchSuperQuery)
+	if err != nil {
+		return err
+	}
+	if status == "" {
+		if _, err := mysqld.FetchSuperQuery(ctx, "INSTALL PLUGIN clone SONAME 'mysql_clone.so'"); err != nil {
+			return vterrors.Wrap(err, "failed to install the clone plugin, load it with plugin-load-add=mysql_clone.so")
+		}
+		return nil
+	}
+	if status != "ACTIVE" {
+		return vterrors.Errorf(vtrpc.Code_FAILED_PRECONDITION, "the clone plugin is %v", status)
+	}
+	return nil
+}
+
+// CheckClonePlugin checks that the MySQL clone plugin is active on the server that
+// execute runs the queries on. It does not install the plugin: a donor is usually a
+// replica, on which super_read_only prevents it. The plugin must be loaded at startup
+// instead, with plugin-load-add=mysql_clone.so.
+func CheckClonePlugin(ctx context.Context, execute func(ctx context.Context, query string) (*sqltypes.Result, error)) error {
+	status, err := clonePluginStatus(ctx, execute)
+	if err != nil {
+		return err
+	}
+	switch status {
+	case "ACTIVE":
+		return nil
+	case "":
+		return vterrors.New(vtrpc.Code_FAILED_PRECONDITION, "the clone plugin is not installed, load it with plugin-load-add=mysql_clone.so")
+	default:
+		return vterrors.Errorf(vtrpc.Code_FAILED_PRECONDITION, "the clone plugin is %v", status)
+	}
+}
+
+// clonePluginStatus returns the status of the clone plugin, or an empty string if it
+// is not installed.
+func clonePluginStatus(ctx context.Context, execute func(ctx context.Context, query string) (*sqltypes.Result, error)) (string, error) {
+	qr, err := execute(ctx, "SELECT PLUGIN_STATUS FROM information_schema.PLUGINS WHERE PLUGIN_NAME = 'clone'")
+	if err != nil {
+		return "", vterrors.Wrap(err, "failed to read the status of the clone plugin")
+	}
+	if len(qr.Rows) == 0 {
+		return "", nil
+	}
+	return qr.Rows[0][0].ToString(), nil
+}
+
+// cloneStatusPosition returns the position of the last clone, which must have completed.
+func cloneStatusPosition(ctx context.Context, mysqld MysqlDaemon) (mysql.Position, error) {
+	qr, err := mysqld.FetchSuperQuery(ctx, "SELECT STATE, ERROR_NO, ERROR_MESSAGE, GTID_EXECUTED FROM performance_schema.clone_status")
+	if err != nil {
+		return mysql.Position{}, vterrors.Wrap(err, "failed to read the status of the clone")
+	}
+	if len(qr.Rows) == 0 {
+		return mysql.Position{}, vterrors.New(vtrpc.Code_FAILED_PRECONDITION, "no clone status found")
+	}
+	row := qr.Named().Row()
+	if state := row.AsString("STATE", ""); state != "Completed" {
+		return mysql.Position{}, vterrors.Errorf(vtrpc.Code_FAILED_PRECONDITION, "clone is %v: %v (errno %v)", state, row.AsString("ERROR_MESSAGE", ""), row.AsString("ERROR_NO", ""))
+	}
+	// GTID_EXECUTED may span several lines
+	gtidExecuted := strings.ReplaceAll(row.AsString("GTID_EXECUTED", ""), "\n", "")
+	pos, err := mysql.ParsePosition(mysql.Mysql56FlavorID, gtidExecuted)
+	if err != nil {
+		return mysql.Position{}, vterrors.Wrapf(err, "cannot parse position %v of the clone", gtidExecuted)
+	}
+	return pos, nil
+}
+
+func init() {
+	BackupRestoreEngineMap[mysqlCloneEngineName] = &MySQLCloneEngine{}
+}
@@ -0,0 +1,180 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mysqlctl
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/mysql"
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/logutil"
+)
+
+const (
+	clonePluginQuery   = "SELECT PLUGIN_STATUS FROM information_schema.PLUGINS WHERE PLUGIN_NAME = 'clone'"
+	cloneStatusQuery   = "SELECT STATE, ERROR_NO, ERROR_MESSAGE, GTID_EXECUTED FROM performance_schema.clone_status"
+	cloneDonorQuery    = "SET GLOBAL clone_valid_donor_list = 'donor:3306'"
+	cloneInstanceQuery = "CLONE INSTANCE FROM 'vt_clone'@'donor':3306 IDENTIFIED BY 'secret'"
+	cloneGTIDExecuted  = "8bc65c84-3fe4-11ed-a912-257f0fcdd6c9:1-12"
+)
+
+func TestCloneFilesToBackup(t *testing.T) {
+	cloneDir := t.TempDir()
+	for _, name := range []string{
+		"ibdata1",
+		"undo_001",
+		"mysql.ibd",
+		filepath.Join(innodbRedoDir, "#ib_redo0"),
+		filepath.Join("vt_commerce", "customer.ibd"),
+	} {
+		require.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(cloneDir, name)), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(cloneDir, name), []byte(name), 0644))
+	}
+
+	fes, err := cloneFilesToBackup(cloneDir)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []FileEntry{
+		{Base: backupInnodbLogGroupHomeDir, Name: filepath.Join(innodbRedoDir, "#ib_redo0")},
+		{Base: backupInnodbDataHomeDir, Name: "ibdata1"},
+		{Base: backupData, Name: "mysql.ibd"},
+		{Base: backupData, Name: "undo_001"},
+		{Base: backupData, Name: filepath.Join("vt_commerce", "customer.ibd")},
+	}, fes)
+}
+
+func clonePluginResult(status string) *sqltypes.Result {
+	return sqltypes.MakeTestResult(sqltypes.MakeTestFields("PLUGIN_STATUS", "varchar"), status)
+}
+
+func cloneStatusResult(row string) *sqltypes.Result {
+	return sqltypes.MakeTestResult(sqltypes.MakeTestFields("STATE|ERROR_NO|ERROR_MESSAGE|GTID_EXECUTED", "varchar|int64|varchar|text"), row)
+}
+
+func TestCloneFromDonor(t *testing.T) {
+	oldUser, oldPassword := mysqlCloneUser, mysqlClonePassword
+	defer func() {
+		mysqlCloneUser, mysqlClonePassword = oldUser, oldPassword
+	}()
+	mysqlCloneUser, mysqlClonePassword = "vt_clone", "secret"
+
+	tests := []struct {
+		name         string
+		pluginStatus string
+		cloneErr     error
+		cloneStatus  string
+		wantLog      string
+		wantErr      string
+	}{{
+		name:         "restarted by the supervisor",
+		pluginStatus: "ACTIVE",
+		cloneErr:     mysql.NewSQLError(mysql.CRServerLost, mysql.SSUnknownSQLState, "Lost connection to MySQL server during query"),
+		cloneStatus:  "Completed|0||" + cloneGTIDExecuted,
+		wantLog:      "Clone: waiting for mysqld to restart",
+	}, {
+		name:         "not managed by a supervisor",
+		pluginStatus: "ACTIVE",
+		cloneErr:     mysql.NewSQLError(mysql.ERRestartServerFailed, mysql.SSUnknownSQLState, "Restart server failed (mysqld is not managed by supervisor process)."),
+		cloneStatus:  "Completed|0||" + cloneGTIDExecuted,
+		wantLog:      "Clone: restarting mysqld",
+	}, {
+		name:         "failed clone",
+		pluginStatus: "ACTIVE",
+		cloneErr:     mysql.NewSQLError(mysql.CRServerLost, mysql.SSUnknownSQLState, "Lost connection to MySQL server during query"),
+		cloneStatus:  "Failed|3862|Clone Donor Error: 1158 : Got an error reading communication packets.|",
+		wantErr:      "clone is Failed: Clone Donor Error: 1158 : Got an error reading communication packets. (errno 3862)",
+	}, {
+		name:         "rejected clone",
+		pluginStatus: "ACTIVE",
+		cloneErr:     mysql.NewSQLError(mysql.ERAccessDeniedError, mysql.SSAccessDeniedError, "Access denied for user 'vt_clone'@'localhost' (using password: YES)"),
+		wantErr:      "failed to clone the data of donor:3306: Access denied for user 'vt_clone'@'localhost' (using password: YES) (errno 1045)",
+	}, {
+		name:         "disabled plugin",
+		pluginStatus: "DISABLED",
+		wantErr:      "the clone plugin is DISABLED",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fmd := NewFakeMysqlDaemon(nil)
+			fmd.FetchSuperQueryMap = map[string]*sqltypes.Result{
+				clonePluginQuery:   clonePluginResult(tt.pluginStatus),
+				cloneDonorQuery:    {},
+				cloneInstanceQuery: {},
+				cloneStatusQuery:   cloneStatusResult(tt.cloneStatus),
+			}
+			if tt.cloneErr != nil {
+				fmd.FetchSuperQueryErrors = map[string]error{cloneInstanceQuery: tt.cloneErr}
+			}
+			logger := logutil.NewMemoryLogger()
+			params := RestoreParams{
+				Cnf:    &Mycnf{DataDir: filepath.Join(t.TempDir(), "data")},
+				Mysqld: fmd,
+				Logger: logger,
+			}
+
+			pos, err := CloneFromDonor(context.Background(), params, "donor", 3306)
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+				assert.NotContains(t, err.Error(), mysqlClonePassword)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "MySQL56/"+cloneGTIDExecuted, mysql.EncodePosition(pos))
+			assert.Contains(t, logger.String(), tt.wantLog)
+			assert.True(t, fmd.Running)
+			assert.False(t, RestoreWasInterrupted(params.Cnf))
+		})
+	}
+}
+
+func TestCheckClonePlugin(t *testing.T) {
+	tests := []struct {
+		name    string
+		result  *sqltypes.Result
+		wantErr string
+	}{{
+		name:   "active",
+		result: clonePluginResult("ACTIVE"),
+	}, {
+		name:    "not installed",
+		result:  &sqltypes.Result{},
+		wantErr: "the clone plugin is not installed, load it with plugin-load-add=mysql_clone.so",
+	}, {
+		name:    "disabled",
+		result:  clonePluginResult("DISABLED"),
+		wantErr: "the clone plugin is DISABLED",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fmd := NewFakeMysqlDaemon(nil)
+			fmd.FetchSuperQueryMap = map[string]*sqltypes.Result{
+				clonePluginQuery: tt.result,
+			}
+			err := CheckClonePlugin(context.Background(), fmd.FetchSuperQuery)
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
@@ -26,6 +26,8 @@ import (
 
 	"vitess.io/vitess/go/vt/log"
 	"vitess.io/vitess/go/vt/servenv"
+
+	vttimepb "vitess.io/vitess/go/vt/proto/vttime"
 )
 
 var protocol = "grpc"
@@ -50,7 +52,7 @@ type MysqlctlClient interface {
 	RunMysqlUpgrade(ctx context.Context) error
 
 	// ApplyBinlogFile calls Mysqld.ApplyBinlogFile remotely.
-	ApplyBinlogFile(ctx context.Context, binlogFileName, binlogRestorePosition string) error
+	ApplyBinlogFile(ctx context.Context, binlogFileName, binlogRestorePosition string, binlogRestoreDatetime *vttimepb.Time) error
 
 	// ReinitConfig calls Mysqld.ReinitConfig remotely.
 	ReinitConfig(ctx context.Context) error
@@ -48,6 +48,7 @@ import (
 	"vitess.io/vitess/go/vt/dbconnpool"
 	"vitess.io/vitess/go/vt/hook"
 	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/logutil"
 	"vitess.io/vitess/go/vt/mysqlctl/mysqlctlclient"
 	"vitess.io/vitess/go/vt/servenv"
 	"vitess.io/vitess/go/vt/vterrors"
@@ -86,6 +87,9 @@ var (
 // How many bytes from MySQL error log to sample for error messages
 const maxLogFileSampleSize = 4096
 
+// binlogDatetimeFormat is the format of the datetime options of mysqlbinlog
+const binlogDatetimeFormat = "2006-01-02 15:04:05"
+
 // Mysqld is the object that represents a mysqld daemon running on this server.
 type Mysqld struct {
 	dbcfgs  *dbconfigs.DBConfigs
@@ -1164,7 +1168,9 @@ func (mysqld *Mysqld) GetVersionComment(ctx context.Context) string {
 
 // ApplyBinlogFile extracts a binary log file and applies it to MySQL. It is the equivalent of:
 // $ mysqlbinlog --include-gtids binlog.file | mysql
-func (mysqld *Mysqld) ApplyBinlogFile(ctx context.Context, binlogFile string, restorePos mysql.Position) error {
+// When restoreTime is non-zero, the events that happened after that time are not applied:
+// $ mysqlbinlog --include-gtids --stop-datetime binlog.file | mysql
+func (mysqld *Mysqld) ApplyBinlogFile(ctx context.Context, binlogFile string, restorePos mysql.Position, restoreTime time.Time) error {
 	if socketFile != "" {
 		log.Infof("executing Mysqld.ApplyBinlogFile() remotely via mysqlctld server: %v", socketFile)
 		client, err := mysqlctlclient.New("unix", socketFile)
@@ -1172,7 +1178,7 @@ func (mysqld *Mysqld) ApplyBinlogFile(ctx context.Context, binlogFile string, re
 			return fmt.Errorf("can't dial mysqlctld: %v", err)
 		}
 		defer client.Close()
-		return client.ApplyBinlogFile(ctx, binlogFile, mysql.EncodePosition(restorePos))
+		return client.ApplyBinlogFile(ctx, binlogFile, mysql.EncodePosition(restorePos), logutil.TimeToProto(restoreTime))
 	}
 	var pipe io.ReadCloser
 	var mysqlbinlogCmd *exec.Cmd
@@ -1192,10 +1198,18 @@ func (mysqld *Mysqld) ApplyBinlogFile(ctx context.Context, binlogFile string, re
 			return err
 		}
 		args := []string{}
-		if gtids := restorePos.GTIDSet.String(); gtids != "" {
+		if !restorePos.IsZero() && restorePos.GTIDSet.String() != "" {
 			args = append(args,
 				"--include-gtids",
-				gtids,
+				restorePos.GTIDSet.String(),
+			)
+		}
+		if !restoreTime.IsZero() {
+			// --stop-datetime is exclusive and has a resolution of one second: the events
+			// of the restore time itself are applied by stopping at the next second.
+			args = append(args,
+				"--stop-datetime",
+				restoreTime.UTC().Add(time.Second).Truncate(time.Second).Format(binlogDatetimeFormat),
 			)
 		}
 
@@ -1204,6 +1218,10 @@ func (mysqld *Mysqld) ApplyBinlogFile(ctx context.Context, binlogFile string, re
 		mysqlbinlogCmd = exec.Command(name, args...)
 		mysqlbinlogCmd.Dir = dir
 		mysqlbinlogCmd.Env = env
+		if !restoreTime.IsZero() {
+			// mysqlbinlog interprets --stop-datetime in its local time zone.
+			mysqlbinlogCmd.Env = append(mysqlbinlogCmd.Env, "TZ=UTC")
+		}
 		log.Infof("ApplyBinlogFile: running mysqlbinlog command: %#v", mysqlbinlogCmd)
 		pipe, err = mysqlbinlogCmd.StdoutPipe() // to be piped into mysql
 		if err != nil {
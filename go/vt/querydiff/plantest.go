@@ -0,0 +1,100 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package querydiff
+
+import (
+	"encoding/json"
+	"io"
+
+	"vitess.io/vitess/go/jsonutil"
+	"vitess.io/vitess/go/vt/sqlparser"
+)
+
+// PlanTest is a test case in the format of the planner tests, see
+// go/vt/vtgate/planbuilder/testdata
+type PlanTest struct {
+	Comment string          `json:"comment,omitempty"`
+	Query   string          `json:"query,omitempty"`
+	Plan    json.RawMessage `json:"plan,omitempty"`
+	// CurrentPlan is the plan the test database used for the query. It is the
+	// plan that returned the wrong results, so it is only there for reference
+	// and is ignored by the planner tests.
+	CurrentPlan json.RawMessage `json:"current-plan,omitempty"`
+}
+
+// PlanPlaceholder is the expected plan of the generated test cases. It never
+// matches the output of the planner, so the test cases fail until the correct
+// plan has been reviewed and filled in.
+const PlanPlaceholder = "TODO: fill in the correct plan, see current-plan for the plan that returned the wrong results"
+
+// PlanTests turns the mismatches into planner test cases. The expected plan of
+// each test case is PlanPlaceholder. When the test database is a Vitess cluster
+// that supports VEXPLAIN, the plan it used is added as the known-bad current plan.
+func (d *Differ) PlanTests(mismatches []*Mismatch) []PlanTest {
+	placeholder, _ := json.Marshal(PlanPlaceholder)
+	tests := make([]PlanTest, 0, len(mismatches))
+	for _, m := range mismatches {
+		tests = append(tests, PlanTest{
+			Comment:     m.Reason,
+			Query:       m.Simplified,
+			Plan:        placeholder,
+			CurrentPlan: d.plan(m.Simplified),
+		})
+	}
+	return tests
+}
+
+// plan returns the plan of a query on the test database in the format of
+// engine.Plan, or nil if it cannot be explained
+func (d *Differ) plan(query string) json.RawMessage {
+	stmt, err := sqlparser.Parse(query)
+	if err != nil {
+		return nil
+	}
+	qr, err := d.test.ExecuteFetch("vexplain plan "+query, 1, false)
+	if err != nil || len(qr.Rows) != 1 || len(qr.Rows[0]) != 1 {
+		return nil
+	}
+	instructions := json.RawMessage(qr.Rows[0][0].ToString())
+	if !json.Valid(instructions) {
+		return nil
+	}
+
+	plan, err := json.Marshal(struct {
+		QueryType    string
+		Original     string
+		Instructions json.RawMessage
+	}{
+		QueryType:    sqlparser.ASTToStatementType(stmt).String(),
+		Original:     query,
+		Instructions: instructions,
+	})
+	if err != nil {
+		return nil
+	}
+	return plan
+}
+
+// WritePlanTests writes test cases as a planner test file
+func WritePlanTests(w io.Writer, tests []PlanTest) error {
+	out, err := jsonutil.MarshalIndentNoEscape(tests, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(out)
+	return err
+}
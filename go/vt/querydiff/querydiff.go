@@ -0,0 +1,249 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package querydiff runs queries against two databases, usually a Vitess
+// cluster and a plain MySQL database, and reports the queries whose results
+// don't match, simplified to minimal reproducers.
+package querydiff
+
+import (
+	"fmt"
+
+	"vitess.io/vitess/go/mysql/collations"
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/key"
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/vtgate/engine"
+	"vitess.io/vitess/go/vt/vtgate/semantics"
+	"vitess.io/vitess/go/vt/vtgate/simplifier"
+	"vitess.io/vitess/go/vt/vtgate/vindexes"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+)
+
+type (
+	// Executor runs queries against one of the compared databases.
+	// It's implemented by *mysql.Conn.
+	Executor interface {
+		ExecuteFetch(query string, maxrows int, wantfields bool) (*sqltypes.Result, error)
+	}
+
+	// Options control how queries are compared
+	Options struct {
+		// Keyspace is the default keyspace, or database, of the queries
+		Keyspace string
+
+		// VSchema is used to resolve the tables of the queries while they're
+		// simplified. Tables that are not in the VSchema are considered to be
+		// unsharded tables of Keyspace.
+		VSchema *vindexes.VSchema
+
+		// MaxRows is the maximum number of rows a query can return
+		MaxRows int
+
+		// CompareErrorMessages makes queries that fail on both sides with
+		// different errors mismatch. It's only useful when both sides are
+		// Vitess, since Vitess and MySQL errors are worded differently.
+		CompareErrorMessages bool
+
+		// Simplify enables the simplification of the mismatching queries
+		Simplify bool
+
+		// AllowDML allows comparing INSERT, UPDATE and DELETE statements.
+		// They're run inside a transaction that is rolled back right away,
+		// so that neither database is modified. Without it, only SELECT
+		// statements can be compared.
+		AllowDML bool
+	}
+
+	// Differ compares the results of queries run against a reference
+	// database and against the database under test
+	Differ struct {
+		reference Executor
+		test      Executor
+		opts      *Options
+	}
+
+	// Mismatch is a query whose results differ between the two databases
+	Mismatch struct {
+		// Query is the original query
+		Query string
+		// Simplified is the smallest query that still mismatches, or the
+		// original query if it could not be simplified
+		Simplified string
+
+		// Reason describes how the results of Simplified differ
+		Reason string
+	}
+)
+
+// NewDiffer creates a Differ that compares the results of test against those
+// of reference
+func NewDiffer(reference, test Executor, opts *Options) *Differ {
+	return &Differ{
+		reference: reference,
+		test:      test,
+		opts:      opts,
+	}
+}
+
+// Compare runs a query against both databases and returns the mismatch, if
+// any, with its simplified reproducer
+func (d *Differ) Compare(query string) (*Mismatch, error) {
+	stmt, err := sqlparser.Parse(query)
+	if err != nil {
+		return nil, err
+	}
+	switch stmt.(type) {
+	case sqlparser.SelectStatement:
+	case *sqlparser.Insert, *sqlparser.Update, *sqlparser.Delete:
+		if !d.opts.AllowDML {
+			return nil, fmt.Errorf("DML statements can only be compared when AllowDML is set: %s", query)
+		}
+	default:
+		return nil, fmt.Errorf("only SELECT and DML statements can be compared: %s", query)
+	}
+
+	reason, err := d.compare(stmt)
+	if err != nil || reason == "" {
+		return nil, err
+	}
+
+	mismatch := &Mismatch{Query: query, Simplified: query, Reason: reason}
+	sel, ok := stmt.(sqlparser.SelectStatement)
+	if !d.opts.Simplify || !ok {
+		return mismatch, nil
+	}
+
+	simplified := d.simplify(sel)
+	mismatch.Simplified = sqlparser.String(simplified)
+	mismatch.Reason, err = d.compare(simplified)
+	if err != nil {
+		return nil, err
+	}
+	return mismatch, nil
+}
+
+// compare runs a statement against both databases and describes how their
+// results differ, or returns an empty string if they match. It only returns
+// an error if the transaction of a DML statement can't be rolled back.
+func (d *Differ) compare(stmt sqlparser.Statement) (string, error) {
+	refResult, refErr, err := d.execute(d.reference, stmt)
+	if err != nil {
+		return "", fmt.Errorf("reference: %v", err)
+	}
+	testResult, testErr, err := d.execute(d.test, stmt)
+	if err != nil {
+		return "", fmt.Errorf("test: %v", err)
+	}
+
+	switch {
+	case refErr != nil && testErr != nil:
+		if d.opts.CompareErrorMessages && refErr.Error() != testErr.Error() {
+			return fmt.Sprintf("reference and test failed with different errors: reference: [%s], test: [%s]", refErr.Error(), testErr.Error()), nil
+		}
+		return "", nil
+	case refErr != nil:
+		return fmt.Sprintf("reference failed while test did not: %s", refErr.Error()), nil
+	case testErr != nil:
+		return fmt.Sprintf("test failed while reference did not: %s", testErr.Error()), nil
+	}
+
+	var ordered bool
+	if sel, ok := stmt.(sqlparser.SelectStatement); ok {
+		ordered = len(sel.GetOrderBy()) > 0
+	}
+	if engine.CompareResults(refResult, testResult, ordered) {
+		return "", nil
+	}
+	return fmt.Sprintf("results did not match: reference: %v, test: %v", formatRows(refResult), formatRows(testResult)), nil
+}
+
+// execute runs a statement against one of the databases. SELECT statements
+// are run as is, while DML statements are run inside a transaction that is
+// always rolled back. queryErr is the error of the statement itself, while
+// err is returned if the transaction could not be started or rolled back.
+func (d *Differ) execute(exec Executor, stmt sqlparser.Statement) (qr *sqltypes.Result, queryErr, err error) {
+	query := sqlparser.String(stmt)
+	if _, ok := stmt.(sqlparser.SelectStatement); ok {
+		qr, queryErr = exec.ExecuteFetch(query, d.opts.MaxRows, false)
+		return qr, queryErr, nil
+	}
+
+	if _, err := exec.ExecuteFetch("begin", 0, false); err != nil {
+		return nil, nil, err
+	}
+	qr, queryErr = exec.ExecuteFetch(query, d.opts.MaxRows, false)
+	if _, err := exec.ExecuteFetch("rollback", 0, false); err != nil {
+		return nil, nil, err
+	}
+	return qr, queryErr, nil
+}
+
+func formatRows(qr *sqltypes.Result) string {
+	if len(qr.Rows) == 0 && qr.RowsAffected > 0 {
+		return fmt.Sprintf("[rows affected: %d]", qr.RowsAffected)
+	}
+	return fmt.Sprintf("%v", qr.Rows)
+}
+
+// simplify returns the smallest version of a query that still mismatches
+func (d *Differ) simplify(stmt sqlparser.SelectStatement) (simplified sqlparser.SelectStatement) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Warningf("failed to simplify %s: %v", sqlparser.String(stmt), r)
+			simplified = stmt
+		}
+	}()
+
+	si := &schemaInformation{vschema: d.opts.VSchema, keyspace: d.opts.Keyspace}
+	return simplifier.SimplifyStatement(stmt, d.opts.Keyspace, si, func(stmt sqlparser.SelectStatement) bool {
+		reason, err := d.compare(stmt)
+		return err == nil && reason != ""
+	})
+}
+
+// schemaInformation resolves the tables of the simplified queries
+type schemaInformation struct {
+	vschema  *vindexes.VSchema
+	keyspace string
+}
+
+var _ semantics.SchemaInformation = (*schemaInformation)(nil)
+
+// FindTableOrVindex implements the SchemaInformation interface
+func (si *schemaInformation) FindTableOrVindex(tablename sqlparser.TableName) (*vindexes.Table, vindexes.Vindex, string, topodatapb.TabletType, key.Destination, error) {
+	keyspace := si.keyspace
+	if !tablename.Qualifier.IsEmpty() {
+		keyspace = tablename.Qualifier.String()
+	}
+	if si.vschema != nil {
+		table, vindex, err := si.vschema.FindTableOrVindex(keyspace, tablename.Name.String(), topodatapb.TabletType_PRIMARY)
+		if err == nil && (table != nil || vindex != nil) {
+			return table, vindex, keyspace, topodatapb.TabletType_PRIMARY, nil, nil
+		}
+	}
+	return &vindexes.Table{
+		Name:     tablename.Name,
+		Keyspace: &vindexes.Keyspace{Name: keyspace},
+	}, nil, keyspace, topodatapb.TabletType_PRIMARY, nil, nil
+}
+
+// ConnCollation implements the SchemaInformation interface
+func (si *schemaInformation) ConnCollation() collations.ID {
+	return collations.Default()
+}
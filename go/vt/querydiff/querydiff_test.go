@@ -0,0 +1,208 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package querydiff
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/sqltypes"
+)
+
+// fakeExecutor returns the result of the first matching rule for each query,
+// and records the queries it runs
+type fakeExecutor struct {
+	rules   []fakeRule
+	queries []string
+}
+
+type fakeRule struct {
+	contains string
+	result   *sqltypes.Result
+	err      error
+}
+
+func (f *fakeExecutor) ExecuteFetch(query string, maxrows int, wantfields bool) (*sqltypes.Result, error) {
+	f.queries = append(f.queries, query)
+	for _, rule := range f.rules {
+		if strings.Contains(query, rule.contains) {
+			return rule.result, rule.err
+		}
+	}
+	return sqltypes.MakeTestResult(sqltypes.MakeTestFields("a", "int64"), "1", "2"), nil
+}
+
+func TestCompare(t *testing.T) {
+	rows := sqltypes.MakeTestResult(sqltypes.MakeTestFields("a", "int64"), "1", "2")
+	reversed := sqltypes.MakeTestResult(sqltypes.MakeTestFields("a", "int64"), "2", "1")
+
+	testcases := []struct {
+		name      string
+		query     string
+		reference fakeRule
+		test      fakeRule
+		opts      Options
+		reason    string
+	}{{
+		name:      "same rows",
+		query:     "select a from t",
+		reference: fakeRule{result: rows},
+		test:      fakeRule{result: rows},
+	}, {
+		name:      "unordered rows",
+		query:     "select a from t",
+		reference: fakeRule{result: rows},
+		test:      fakeRule{result: reversed},
+	}, {
+		name:      "ordered rows",
+		query:     "select a from t order by a",
+		reference: fakeRule{result: rows},
+		test:      fakeRule{result: reversed},
+		reason:    "results did not match: reference: [[INT64(1)] [INT64(2)]], test: [[INT64(2)] [INT64(1)]]",
+	}, {
+		name:      "test error",
+		query:     "select a from t",
+		reference: fakeRule{result: rows},
+		test:      fakeRule{err: errors.New("unsupported")},
+		reason:    "test failed while reference did not: unsupported",
+	}, {
+		name:      "reference error",
+		query:     "select a from t",
+		reference: fakeRule{err: errors.New("syntax error")},
+		test:      fakeRule{result: rows},
+		reason:    "reference failed while test did not: syntax error",
+	}, {
+		name:      "different errors",
+		query:     "select a from t",
+		reference: fakeRule{err: errors.New("a")},
+		test:      fakeRule{err: errors.New("b")},
+	}, {
+		name:      "different error messages",
+		query:     "select a from t",
+		reference: fakeRule{err: errors.New("a")},
+		test:      fakeRule{err: errors.New("b")},
+		opts:      Options{CompareErrorMessages: true},
+		reason:    "reference and test failed with different errors: reference: [a], test: [b]",
+	}, {
+		name:      "rows affected",
+		query:     "update t set a = 1",
+		reference: fakeRule{contains: "update", result: &sqltypes.Result{RowsAffected: 2}},
+		test:      fakeRule{contains: "update", result: &sqltypes.Result{RowsAffected: 1}},
+		opts:      Options{AllowDML: true},
+		reason:    "results did not match: reference: [rows affected: 2], test: [rows affected: 1]",
+	}}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			reference := &fakeExecutor{rules: []fakeRule{tc.reference}}
+			test := &fakeExecutor{rules: []fakeRule{tc.test}}
+			d := NewDiffer(reference, test, &tc.opts)
+
+			mismatch, err := d.Compare(tc.query)
+			require.NoError(t, err)
+			if tc.reason == "" {
+				assert.Nil(t, mismatch)
+				return
+			}
+			require.NotNil(t, mismatch)
+			assert.Equal(t, tc.query, mismatch.Query)
+			assert.Equal(t, tc.query, mismatch.Simplified)
+			assert.Equal(t, tc.reason, mismatch.Reason)
+		})
+	}
+}
+
+func TestCompareDML(t *testing.T) {
+	for _, query := range []string{"update t set a = 1", "insert into t(a) values (1)", "delete from t"} {
+		reference := &fakeExecutor{}
+		test := &fakeExecutor{}
+
+		// DML statements are not run unless they're explicitly allowed
+		_, err := NewDiffer(reference, test, &Options{}).Compare(query)
+		require.ErrorContains(t, err, "DML statements can only be compared when AllowDML is set")
+		assert.Empty(t, reference.queries)
+		assert.Empty(t, test.queries)
+
+		// and when they are, both sides roll them back
+		mismatch, err := NewDiffer(reference, test, &Options{AllowDML: true}).Compare(query)
+		require.NoError(t, err)
+		assert.Nil(t, mismatch)
+		assert.Equal(t, []string{"begin", query, "rollback"}, reference.queries)
+		assert.Equal(t, []string{"begin", query, "rollback"}, test.queries)
+	}
+
+	// the transaction is rolled back even when the statement fails
+	reference := &fakeExecutor{rules: []fakeRule{{contains: "update", err: errors.New("duplicate entry")}}}
+	test := &fakeExecutor{}
+	mismatch, err := NewDiffer(reference, test, &Options{AllowDML: true}).Compare("update t set a = 1")
+	require.NoError(t, err)
+	require.NotNil(t, mismatch)
+	assert.Equal(t, "reference failed while test did not: duplicate entry", mismatch.Reason)
+	assert.Equal(t, []string{"begin", "update t set a = 1", "rollback"}, reference.queries)
+
+	// failing to roll back is an error rather than a mismatch
+	test = &fakeExecutor{rules: []fakeRule{{contains: "rollback", err: errors.New("connection lost")}}}
+	_, err = NewDiffer(&fakeExecutor{}, test, &Options{AllowDML: true}).Compare("delete from t")
+	require.EqualError(t, err, "test: connection lost")
+
+	// statements other than SELECT and DML are never run
+	_, err = NewDiffer(reference, test, &Options{AllowDML: true}).Compare("drop table t")
+	require.EqualError(t, err, "only SELECT and DML statements can be compared: drop table t")
+}
+
+func TestCompareSimplify(t *testing.T) {
+	reference := &fakeExecutor{}
+	test := &fakeExecutor{rules: []fakeRule{{
+		contains: "vexplain plan",
+		result:   sqltypes.MakeTestResult(sqltypes.MakeTestFields("JSON", "varchar"), `{"OperatorType": "Route", "Variant": "Scatter"}`),
+	}, {
+		// the bug: the predicate on b is lost
+		contains: "b = 2",
+		result:   sqltypes.MakeTestResult(sqltypes.MakeTestFields("a", "int64"), "1", "2", "3"),
+	}}}
+
+	d := NewDiffer(reference, test, &Options{Keyspace: "ks", Simplify: true})
+	mismatch, err := d.Compare("select t.a, u.c from t join u on t.a = u.a where t.a > 0 and t.b = 2 and u.c = 3")
+	require.NoError(t, err)
+	require.NotNil(t, mismatch)
+	assert.Equal(t, "select t.a, u.c from t join u on t.a = u.a where t.a > 0 and t.b = 2 and u.c = 3", mismatch.Query)
+	assert.Equal(t, "select 0 from t where t.b = 2", mismatch.Simplified)
+	assert.Equal(t, "results did not match: reference: [[INT64(1)] [INT64(2)]], test: [[INT64(1)] [INT64(2)] [INT64(3)]]", mismatch.Reason)
+
+	var buf bytes.Buffer
+	require.NoError(t, WritePlanTests(&buf, d.PlanTests([]*Mismatch{mismatch})))
+
+	var tests []map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &tests))
+	require.Len(t, tests, 1)
+	assert.Equal(t, mismatch.Reason, tests[0]["comment"])
+	assert.Equal(t, mismatch.Simplified, tests[0]["query"])
+	assert.Equal(t, PlanPlaceholder, tests[0]["plan"])
+	assert.Equal(t, map[string]any{
+		"QueryType": "SELECT",
+		"Original":  mismatch.Simplified,
+		"Instructions": map[string]any{
+			"OperatorType": "Route",
+			"Variant":      "Scatter",
+		},
+	}, tests[0]["current-plan"])
+}
@@ -0,0 +1,31 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+CREATE TABLE IF NOT EXISTS vdiff_repair
+(
+    `id`           bigint(20)     NOT NULL AUTO_INCREMENT,
+    `vdiff_id`     int(11)        NOT NULL,
+    `table_name`   varbinary(128) NOT NULL,
+    `kind`         varbinary(16)  NOT NULL,
+    `statement`    longblob       NOT NULL,
+    `source_check` longblob       NOT NULL,
+    `target_check` longblob       NOT NULL,
+    `state`        varbinary(64)  NOT NULL DEFAULT 'pending',
+    `created_at`   timestamp      NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `applied_at`   timestamp      NULL     DEFAULT NULL,
+    PRIMARY KEY (`id`),
+    KEY `vdiff_id_state_idx` (`vdiff_id`, `state`)
+) ENGINE = InnoDB
@@ -16,10 +16,12 @@ limitations under the License.
 
 CREATE TABLE IF NOT EXISTS copy_state
 (
-    `id` bigint unsigned NOT NULL AUTO_INCREMENT,
-    `vrepl_id`   int            NOT NULL,
-    `table_name` varbinary(128) NOT NULL,
-    `lastpk`     varbinary(2000) DEFAULT NULL,
+    `id`              bigint unsigned  NOT NULL AUTO_INCREMENT,
+    `vrepl_id`        int              NOT NULL,
+    `table_name`      varbinary(128)   NOT NULL,
+    `lastpk`          varbinary(2000)  DEFAULT NULL,
+    `snapshot_pos`    varbinary(10000) DEFAULT NULL,
+    `snapshot_lastpk` varbinary(2000)  DEFAULT NULL,
     PRIMARY KEY (`id`),
     KEY `vrepl_id` (`vrepl_id`,`table_name`)
 ) ENGINE = InnoDB
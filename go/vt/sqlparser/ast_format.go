@@ -1013,7 +1013,11 @@ func (node *ExplainStmt) Format(buf *TrackedBuffer) {
 
 // Format formats the node.
 func (node *VExplainStmt) Format(buf *TrackedBuffer) {
-	buf.astPrintf(node, "vexplain %v%s %v", node.Comments, node.Type.ToString(), node.Statement)
+	format := node.Type.ToString()
+	if node.Type == JSONVExplainType {
+		format = "format = " + format
+	}
+	buf.astPrintf(node, "vexplain %v%s %v", node.Comments, format, node.Statement)
 }
 
 // Format formats the node.
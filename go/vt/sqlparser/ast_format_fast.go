@@ -1338,9 +1338,13 @@ func (node *ExplainStmt) formatFast(buf *TrackedBuffer) {
 
 // formatFast formats the node.
 func (node *VExplainStmt) formatFast(buf *TrackedBuffer) {
+	format := node.Type.ToString()
+	if node.Type == JSONVExplainType {
+		format = "format = " + format
+	}
 	buf.WriteString("vexplain ")
 	node.Comments.formatFast(buf)
-	buf.WriteString(node.Type.ToString())
+	buf.WriteString(format)
 	buf.WriteByte(' ')
 	node.Statement.formatFast(buf)
 }
@@ -1709,6 +1709,8 @@ func (ty VExplainType) ToString() string {
 		return QueriesStr
 	case AllVExplainType:
 		return AllVExplainStr
+	case JSONVExplainType:
+		return JSONStr
 	default:
 		return "Unknown VExplainType"
 	}
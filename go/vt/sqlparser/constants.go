@@ -790,6 +790,7 @@ const (
 	QueriesVExplainType VExplainType = iota
 	PlanVExplainType
 	AllVExplainType
+	JSONVExplainType
 )
 
 // Constant for Enum Type - SelectIntoType
@@ -2475,6 +2475,11 @@ var (
 		input: "vexplain all select * from t",
 	}, {
 		input: "vexplain plan select * from t",
+	}, {
+		input: "vexplain format = json select * from t",
+	}, {
+		input:  "vexplain FORMAT=JSON select * from t",
+		output: "vexplain format = json select * from t",
 	}, {
 		input:  "vexplain select * from t",
 		output: "vexplain plan select * from t",
@@ -4430,6 +4430,10 @@ vexplain_type_opt:
   {
     $$ = QueriesVExplainType
   }
+| FORMAT '=' JSON
+  {
+    $$ = JSONVExplainType
+  }
 
 explain_synonyms:
   EXPLAIN
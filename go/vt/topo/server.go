@@ -67,18 +67,19 @@ const (
 
 // Filenames for all object types.
 const (
-	CellInfoFile          = "CellInfo"
-	CellsAliasFile        = "CellsAlias"
-	KeyspaceFile          = "Keyspace"
-	ShardFile             = "Shard"
-	VSchemaFile           = "VSchema"
-	ShardReplicationFile  = "ShardReplication"
-	TabletFile            = "Tablet"
-	SrvVSchemaFile        = "SrvVSchema"
-	SrvKeyspaceFile       = "SrvKeyspace"
-	RoutingRulesFile      = "RoutingRules"
-	ExternalClustersFile  = "ExternalClusters"
-	ShardRoutingRulesFile = "ShardRoutingRules"
+	CellInfoFile           = "CellInfo"
+	CellsAliasFile         = "CellsAlias"
+	KeyspaceFile           = "Keyspace"
+	ShardFile              = "Shard"
+	VSchemaFile            = "VSchema"
+	ShardReplicationFile   = "ShardReplication"
+	TabletFile             = "Tablet"
+	SrvVSchemaFile         = "SrvVSchema"
+	SrvKeyspaceFile        = "SrvKeyspace"
+	RoutingRulesFile       = "RoutingRules"
+	ExternalClustersFile   = "ExternalClusters"
+	ShardRoutingRulesFile  = "ShardRoutingRules"
+	TenantRoutingRulesFile = "TenantRoutingRules"
 )
 
 // Path for all object types.
@@ -204,6 +204,12 @@ func (ts *Server) RebuildSrvVSchema(ctx context.Context, cells []string) error {
 	}
 	srvVSchema.ShardRoutingRules = srr
 
+	trr, err := ts.GetTenantRoutingRules(ctx)
+	if err != nil {
+		return fmt.Errorf("GetTenantRoutingRules failed: %v", err)
+	}
+	srvVSchema.TenantRoutingRules = trr
+
 	// now save the SrvVSchema in all cells in parallel
 	for _, cell := range cells {
 		wg.Add(1)
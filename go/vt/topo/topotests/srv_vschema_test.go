@@ -30,8 +30,9 @@ import (
 func TestRebuildVSchema(t *testing.T) {
 	ctx := context.Background()
 	emptySrvVSchema := &vschemapb.SrvVSchema{
-		RoutingRules:      &vschemapb.RoutingRules{},
-		ShardRoutingRules: &vschemapb.ShardRoutingRules{},
+		RoutingRules:       &vschemapb.RoutingRules{},
+		ShardRoutingRules:  &vschemapb.ShardRoutingRules{},
+		TenantRoutingRules: &vschemapb.TenantRoutingRules{},
 	}
 
 	// Set up topology.
@@ -50,8 +51,9 @@ func TestRebuildVSchema(t *testing.T) {
 
 	// create a keyspace, rebuild, should see an empty entry
 	emptyKs1SrvVSchema := &vschemapb.SrvVSchema{
-		RoutingRules:      &vschemapb.RoutingRules{},
-		ShardRoutingRules: &vschemapb.ShardRoutingRules{},
+		RoutingRules:       &vschemapb.RoutingRules{},
+		ShardRoutingRules:  &vschemapb.ShardRoutingRules{},
+		TenantRoutingRules: &vschemapb.TenantRoutingRules{},
 		Keyspaces: map[string]*vschemapb.Keyspace{
 			"ks1": {},
 		},
@@ -79,8 +81,9 @@ func TestRebuildVSchema(t *testing.T) {
 		t.Errorf("RebuildVSchema failed: %v", err)
 	}
 	wanted1 := &vschemapb.SrvVSchema{
-		RoutingRules:      &vschemapb.RoutingRules{},
-		ShardRoutingRules: &vschemapb.ShardRoutingRules{},
+		RoutingRules:       &vschemapb.RoutingRules{},
+		ShardRoutingRules:  &vschemapb.ShardRoutingRules{},
+		TenantRoutingRules: &vschemapb.TenantRoutingRules{},
 		Keyspaces: map[string]*vschemapb.Keyspace{
 			"ks1": keyspace1,
 		},
@@ -120,8 +123,9 @@ func TestRebuildVSchema(t *testing.T) {
 		t.Errorf("RebuildVSchema failed: %v", err)
 	}
 	wanted2 := &vschemapb.SrvVSchema{
-		RoutingRules:      &vschemapb.RoutingRules{},
-		ShardRoutingRules: &vschemapb.ShardRoutingRules{},
+		RoutingRules:       &vschemapb.RoutingRules{},
+		ShardRoutingRules:  &vschemapb.ShardRoutingRules{},
+		TenantRoutingRules: &vschemapb.TenantRoutingRules{},
 		Keyspaces: map[string]*vschemapb.Keyspace{
 			"ks1": keyspace1,
 			"ks2": keyspace2,
@@ -158,8 +162,9 @@ func TestRebuildVSchema(t *testing.T) {
 		t.Errorf("RebuildVSchema failed: %v", err)
 	}
 	wanted3 := &vschemapb.SrvVSchema{
-		RoutingRules:      rr,
-		ShardRoutingRules: &vschemapb.ShardRoutingRules{},
+		RoutingRules:       rr,
+		ShardRoutingRules:  &vschemapb.ShardRoutingRules{},
+		TenantRoutingRules: &vschemapb.TenantRoutingRules{},
 		Keyspaces: map[string]*vschemapb.Keyspace{
 			"ks1": keyspace1,
 			"ks2": keyspace2,
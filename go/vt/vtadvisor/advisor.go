@@ -0,0 +1,592 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package vtadvisor analyzes a query workload against a VSchema and
+// recommends the vindexes and reference tables that minimize the number of
+// shards each query has to be sent to.
+package vtadvisor
+
+import (
+	"bufio"
+	"io"
+	"sort"
+	"strings"
+
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/vtexplain"
+	"vitess.io/vitess/go/vt/vtgate/engine"
+	"vitess.io/vitess/go/vt/vtgate/vindexes"
+)
+
+// DefaultMaxReferenceWriteRatio is the default for Options.MaxReferenceWriteRatio
+const DefaultMaxReferenceWriteRatio = 0.01
+
+type (
+	// Options control the recommendations of the advisor
+	Options struct {
+		// MaxReferenceWriteRatio is the highest ratio of writes over all the
+		// queries that use a table for it to be recommended as a reference table
+		MaxReferenceWriteRatio float64
+	}
+
+	// Advisor collects the queries of a workload and plans them with vtexplain
+	// to recommend changes to the VSchema
+	Advisor struct {
+		vte     *vtexplain.VTExplain
+		opts    *Options
+		columns map[string]map[string]bool
+
+		queries map[string]*workloadQuery
+		total   int
+		ignored int
+	}
+
+	// workloadQuery is a query of the workload, with its literals and bind
+	// variables replaced by placeholders
+	workloadQuery struct {
+		fingerprint string
+		stmt        sqlparser.Statement
+		count       int
+	}
+
+	columnKey struct {
+		table  string
+		column string
+	}
+
+	// joinQuery is a query that joins tables of different routes in vtgate,
+	// or scatters
+	joinQuery struct {
+		count int
+		pairs [][2]columnKey
+	}
+
+	// workloadStats are the statistics used to recommend changes to the
+	// VSchema; all of them are weighted by the number of times each query
+	// appears in the workload
+	workloadStats struct {
+		filters        map[columnKey]int
+		joins          map[columnKey]int
+		scatterFilters map[columnKey]int
+		joinQueries    []joinQuery
+		reads          map[string]int
+		writes         map[string]int
+	}
+)
+
+// New creates an Advisor that plans queries with the given vtexplain
+// environment. The SQL schema used to create the environment is needed to
+// resolve the tables of unqualified columns.
+func New(vte *vtexplain.VTExplain, sqlSchema string, opts *Options) (*Advisor, error) {
+	if opts == nil {
+		opts = &Options{MaxReferenceWriteRatio: DefaultMaxReferenceWriteRatio}
+	}
+	columns, err := parseColumns(sqlSchema)
+	if err != nil {
+		return nil, err
+	}
+	return &Advisor{
+		vte:     vte,
+		opts:    opts,
+		columns: columns,
+		queries: make(map[string]*workloadQuery),
+	}, nil
+}
+
+// parseColumns returns the columns of every table created by the schema
+func parseColumns(sqlSchema string) (map[string]map[string]bool, error) {
+	pieces, err := sqlparser.SplitStatementToPieces(sqlSchema)
+	if err != nil {
+		return nil, err
+	}
+	columns := make(map[string]map[string]bool)
+	for _, piece := range pieces {
+		stmt, err := sqlparser.Parse(piece)
+		if err != nil {
+			continue
+		}
+		create, ok := stmt.(*sqlparser.CreateTable)
+		if !ok || create.TableSpec == nil {
+			continue
+		}
+		cols := make(map[string]bool, len(create.TableSpec.Columns))
+		for _, col := range create.TableSpec.Columns {
+			cols[col.Name.Lowered()] = true
+		}
+		columns[create.Table.Name.String()] = cols
+	}
+	return columns, nil
+}
+
+// ReadLog adds all the queries of a vtgate or vttablet query log, or of a
+// file with one SQL statement per line. Lines that cannot be parsed are
+// ignored, and reported as such by Analyze.
+func (a *Advisor) ReadLog(r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 64*1024*1024)
+	for scanner.Scan() {
+		sql, err := ParseQueryLogLine(scanner.Text())
+		if err != nil {
+			a.ignored++
+			continue
+		}
+		if sql == "" {
+			continue
+		}
+		if err := a.AddQuery(sql); err != nil {
+			a.ignored++
+		}
+	}
+	return scanner.Err()
+}
+
+// AddQuery adds a query to the workload. Only SELECT, INSERT, UPDATE and
+// DELETE statements are analyzed; other statements are silently skipped.
+func (a *Advisor) AddQuery(sql string) error {
+	stmt, err := sqlparser.Parse(sql)
+	if err != nil {
+		return err
+	}
+	switch stmt.(type) {
+	case sqlparser.SelectStatement, *sqlparser.Insert, *sqlparser.Update, *sqlparser.Delete:
+	default:
+		return nil
+	}
+
+	a.total++
+	fingerprint := fingerprintQuery(stmt)
+	if q, ok := a.queries[fingerprint]; ok {
+		q.count++
+		return nil
+	}
+	a.queries[fingerprint] = &workloadQuery{
+		fingerprint: fingerprint,
+		stmt:        stmt,
+		count:       1,
+	}
+	return nil
+}
+
+// fingerprintQuery formats a statement with all its literals and bind
+// variables replaced by '?', so queries that only differ by their values
+// are analyzed together
+func fingerprintQuery(stmt sqlparser.Statement) string {
+	buf := sqlparser.NewTrackedBuffer(func(buf *sqlparser.TrackedBuffer, node sqlparser.SQLNode) {
+		switch node := node.(type) {
+		case *sqlparser.Literal, *sqlparser.Argument:
+			buf.WriteString("?")
+		case sqlparser.ListArg:
+			buf.WriteString("(?)")
+		case sqlparser.ValTuple:
+			for _, expr := range node {
+				if !isValue(expr) {
+					node.Format(buf)
+					return
+				}
+			}
+			buf.WriteString("(?)")
+		default:
+			node.Format(buf)
+		}
+	})
+	buf.Myprintf("%v", stmt)
+	return buf.String()
+}
+
+// isValue returns true for the expressions a vindex can route with
+func isValue(expr sqlparser.Expr) bool {
+	switch expr := expr.(type) {
+	case *sqlparser.Literal, *sqlparser.Argument, sqlparser.ListArg:
+		return true
+	case sqlparser.ValTuple:
+		for _, e := range expr {
+			if !isValue(e) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// executableQuery returns the query with its bind variables replaced by
+// literals, so it can be executed by vtexplain. The values don't matter
+// since they are only used to pick the shards of a single shard query.
+func executableQuery(stmt sqlparser.Statement) string {
+	stmt = sqlparser.CloneStatement(stmt)
+	stmt = sqlparser.Rewrite(stmt, nil, func(cursor *sqlparser.Cursor) bool {
+		switch cursor.Node().(type) {
+		case *sqlparser.Argument:
+			cursor.Replace(sqlparser.NewIntLiteral("1"))
+		case sqlparser.ListArg:
+			cursor.Replace(sqlparser.ValTuple{sqlparser.NewIntLiteral("1")})
+		}
+		return true
+	}).(sqlparser.Statement)
+	return sqlparser.String(stmt)
+}
+
+// Analyze plans all the queries of the workload and returns the report with
+// the scatter queries and the recommended changes to the VSchema
+func (a *Advisor) Analyze() (*Report, error) {
+	report := &Report{
+		TotalQueries:   a.total,
+		UniqueQueries:  len(a.queries),
+		IgnoredQueries: a.ignored,
+	}
+	stats := &workloadStats{
+		filters:        make(map[columnKey]int),
+		joins:          make(map[columnKey]int),
+		scatterFilters: make(map[columnKey]int),
+		reads:          make(map[string]int),
+		writes:         make(map[string]int),
+	}
+
+	fingerprints := make([]string, 0, len(a.queries))
+	for fingerprint := range a.queries {
+		fingerprints = append(fingerprints, fingerprint)
+	}
+	sort.Strings(fingerprints)
+
+	for _, fingerprint := range fingerprints {
+		q := a.queries[fingerprint]
+		explains, err := a.vte.Run(executableQuery(q.stmt))
+		if err != nil {
+			report.Errors = append(report.Errors, QueryError{Query: q.fingerprint, Count: q.count, Error: err.Error()})
+			continue
+		}
+
+		var scatter, crossShard bool
+		shards := make(map[string]bool)
+		for _, explain := range explains {
+			for _, plan := range explain.Plans {
+				s, c := analyzePlan(plan.Instructions)
+				scatter = scatter || s
+				crossShard = crossShard || c
+			}
+			for tablet := range explain.TabletActions {
+				shards[tablet] = true
+			}
+		}
+		if scatter {
+			report.ScatterQueries = append(report.ScatterQueries, QueryReport{Query: q.fingerprint, Count: q.count, Shards: len(shards)})
+		}
+		a.collectStats(stats, q, scatter || crossShard)
+	}
+
+	sort.SliceStable(report.ScatterQueries, func(i, j int) bool {
+		return report.ScatterQueries[i].Count > report.ScatterQueries[j].Count
+	})
+	sort.SliceStable(report.Errors, func(i, j int) bool {
+		return report.Errors[i].Count > report.Errors[j].Count
+	})
+	a.recommend(report, stats)
+	return report, nil
+}
+
+// analyzePlan returns whether a plan sends a query to all the shards of a
+// keyspace, and whether it joins the rows of different routes in vtgate
+func analyzePlan(primitive engine.Primitive) (scatter, crossShard bool) {
+	if primitive == nil {
+		return false, false
+	}
+	var params *engine.RoutingParameters
+	switch p := primitive.(type) {
+	case *engine.Route:
+		params = p.RoutingParameters
+	case *engine.Update:
+		params = p.RoutingParameters
+	case *engine.Delete:
+		params = p.RoutingParameters
+	case *engine.Join, *engine.HashJoin:
+		crossShard = true
+	}
+	if params != nil && params.Opcode == engine.Scatter && params.Keyspace != nil && params.Keyspace.Sharded {
+		scatter = true
+	}
+	for _, input := range primitive.Inputs() {
+		s, c := analyzePlan(input)
+		scatter = scatter || s
+		crossShard = crossShard || c
+	}
+	return scatter, crossShard
+}
+
+// collectStats adds the columns a query filters and joins on to the
+// statistics of the workload
+func (a *Advisor) collectStats(stats *workloadStats, q *workloadQuery, multiShard bool) {
+	tables := a.tablesOf(q.stmt)
+
+	switch stmt := q.stmt.(type) {
+	case *sqlparser.Insert:
+		stats.writes[sqlparser.GetTableName(stmt.Table.Expr).String()] += q.count
+		return
+	case *sqlparser.Update, *sqlparser.Delete:
+		for _, table := range tables {
+			stats.writes[table] += q.count
+		}
+	default:
+		for _, table := range tables {
+			stats.reads[table] += q.count
+		}
+	}
+
+	filtered := make(map[columnKey]bool)
+	joined := make(map[columnKey]bool)
+	join := joinQuery{count: q.count}
+	_ = sqlparser.Walk(func(node sqlparser.SQLNode) (bool, error) {
+		var cond sqlparser.Expr
+		switch node := node.(type) {
+		case *sqlparser.Where:
+			cond = node.Expr
+		case *sqlparser.JoinCondition:
+			cond = node.On
+		}
+		for _, expr := range sqlparser.SplitAndExpression(nil, cond) {
+			cmp, ok := expr.(*sqlparser.ComparisonExpr)
+			if !ok || (cmp.Operator != sqlparser.EqualOp && cmp.Operator != sqlparser.InOp) {
+				continue
+			}
+			left, leftOK := cmp.Left.(*sqlparser.ColName)
+			right, rightOK := cmp.Right.(*sqlparser.ColName)
+			switch {
+			case leftOK && rightOK:
+				l, r := a.resolve(tables, left), a.resolve(tables, right)
+				if l.table == "" || r.table == "" || l.table == r.table {
+					continue
+				}
+				join.pairs = append(join.pairs, [2]columnKey{l, r})
+				for _, key := range []columnKey{l, r} {
+					if !joined[key] {
+						joined[key] = true
+						stats.joins[key] += q.count
+					}
+				}
+			case leftOK && isValue(cmp.Right):
+				key := a.resolve(tables, left)
+				if key.table == "" || filtered[key] {
+					continue
+				}
+				filtered[key] = true
+				stats.filters[key] += q.count
+				if multiShard {
+					stats.scatterFilters[key] += q.count
+				}
+			}
+		}
+		return true, nil
+	}, q.stmt)
+
+	if multiShard && len(join.pairs) > 0 {
+		stats.joinQueries = append(stats.joinQueries, join)
+	}
+}
+
+// tablesOf returns the tables used by a statement, by alias
+func (a *Advisor) tablesOf(stmt sqlparser.Statement) map[string]string {
+	tables := make(map[string]string)
+	_ = sqlparser.Walk(func(node sqlparser.SQLNode) (bool, error) {
+		ate, ok := node.(*sqlparser.AliasedTableExpr)
+		if !ok {
+			return true, nil
+		}
+		tbl, ok := ate.Expr.(sqlparser.TableName)
+		if !ok {
+			return true, nil
+		}
+		alias := tbl.Name.String()
+		if !ate.As.IsEmpty() {
+			alias = ate.As.String()
+		}
+		tables[alias] = tbl.Name.String()
+		return true, nil
+	}, stmt)
+	return tables
+}
+
+// resolve returns the table and the column a column name refers to, or an
+// empty table if it's ambiguous
+func (a *Advisor) resolve(tables map[string]string, col *sqlparser.ColName) columnKey {
+	column := col.Name.Lowered()
+	if !col.Qualifier.IsEmpty() {
+		return columnKey{table: tables[col.Qualifier.Name.String()], column: column}
+	}
+
+	var found columnKey
+	for _, table := range tables {
+		if len(tables) > 1 && !a.columns[table][column] {
+			continue
+		}
+		if found.table != "" && found.table != table {
+			return columnKey{}
+		}
+		found = columnKey{table: table, column: column}
+	}
+	return found
+}
+
+// shardedTable returns the VSchema of a table of a sharded keyspace, or nil
+// for any other table
+func (a *Advisor) shardedTable(name string) *vindexes.Table {
+	table, err := a.vte.VSchema().FindTable("", name)
+	if err != nil || table == nil || table.Keyspace == nil || !table.Keyspace.Sharded || table.Type != "" {
+		return nil
+	}
+	return table
+}
+
+// primaryVindexColumn returns the first column of the primary vindex of a
+// table of a sharded keyspace
+func primaryVindexColumn(table *vindexes.Table) string {
+	if len(table.ColumnVindexes) == 0 || len(table.ColumnVindexes[0].Columns) == 0 {
+		return ""
+	}
+	return table.ColumnVindexes[0].Columns[0].Lowered()
+}
+
+// isVindexColumn returns true if a column is the first column of any vindex
+// of a table
+func isVindexColumn(table *vindexes.Table, column string) bool {
+	for _, cv := range table.ColumnVindexes {
+		if len(cv.Columns) > 0 && cv.Columns[0].Lowered() == column {
+			return true
+		}
+	}
+	return false
+}
+
+// recommend adds the recommended changes to the VSchema to the report
+func (a *Advisor) recommend(report *Report, stats *workloadStats) {
+	// the best primary vindex column of a table is the one most queries
+	// filter or join on
+	primary := make(map[string]string)
+	candidates := make(map[string][]string)
+	for _, m := range []map[columnKey]int{stats.filters, stats.joins} {
+		for key := range m {
+			candidates[key.table] = append(candidates[key.table], key.column)
+		}
+	}
+	for name, columns := range candidates {
+		table := a.shardedTable(name)
+		if table == nil {
+			continue
+		}
+		current := primaryVindexColumn(table)
+		primary[name] = current
+		score := func(column string) int {
+			key := columnKey{table: name, column: column}
+			return stats.filters[key] + stats.joins[key]
+		}
+
+		sort.Strings(columns)
+		best := current
+		for _, column := range columns {
+			if score(column) > score(best) {
+				best = column
+			}
+		}
+		if best == current {
+			continue
+		}
+		primary[name] = best
+		report.PrimaryVindexes = append(report.PrimaryVindexes, PrimaryVindexRecommendation{
+			Keyspace:       table.Keyspace.Name,
+			Table:          name,
+			CurrentColumn:  current,
+			Column:         best,
+			Queries:        score(best),
+			CurrentQueries: score(current),
+		})
+	}
+
+	// the columns that scatter queries filter on need a lookup vindex, unless
+	// they become the primary vindex
+	for key, count := range stats.scatterFilters {
+		table := a.shardedTable(key.table)
+		if table == nil || primary[key.table] == key.column || isVindexColumn(table, key.column) {
+			continue
+		}
+		report.LookupVindexes = append(report.LookupVindexes, LookupVindexRecommendation{
+			Keyspace: table.Keyspace.Name,
+			Table:    key.table,
+			Column:   key.column,
+			Queries:  count,
+		})
+	}
+
+	// tables that are rarely written and joined with other tables on columns
+	// that aren't both their (recommended) primary vindex are better off as
+	// reference tables
+	colocated := func(key columnKey) bool {
+		if column, ok := primary[key.table]; ok {
+			return column == key.column
+		}
+		table := a.shardedTable(key.table)
+		return table == nil || primaryVindexColumn(table) == key.column
+	}
+	crossShardJoins := make(map[string]int)
+	for _, join := range stats.joinQueries {
+		tables := make(map[string]bool)
+		for _, pair := range join.pairs {
+			if colocated(pair[0]) && colocated(pair[1]) {
+				continue
+			}
+			tables[pair[0].table] = true
+			tables[pair[1].table] = true
+		}
+		for table := range tables {
+			crossShardJoins[table] += join.count
+		}
+	}
+	for name, count := range crossShardJoins {
+		table := a.shardedTable(name)
+		if table == nil {
+			continue
+		}
+		writes := stats.writes[name]
+		if float64(writes) > a.opts.MaxReferenceWriteRatio*float64(writes+stats.reads[name]) {
+			continue
+		}
+		report.ReferenceTables = append(report.ReferenceTables, ReferenceTableRecommendation{
+			Keyspace: table.Keyspace.Name,
+			Table:    name,
+			Queries:  count,
+			Writes:   writes,
+		})
+	}
+
+	sort.Slice(report.PrimaryVindexes, func(i, j int) bool {
+		a, b := report.PrimaryVindexes[i], report.PrimaryVindexes[j]
+		if a.Queries != b.Queries {
+			return a.Queries > b.Queries
+		}
+		return a.Keyspace+"."+a.Table < b.Keyspace+"."+b.Table
+	})
+	sort.Slice(report.LookupVindexes, func(i, j int) bool {
+		a, b := report.LookupVindexes[i], report.LookupVindexes[j]
+		if a.Queries != b.Queries {
+			return a.Queries > b.Queries
+		}
+		return strings.Join([]string{a.Keyspace, a.Table, a.Column}, ".") < strings.Join([]string{b.Keyspace, b.Table, b.Column}, ".")
+	})
+	sort.Slice(report.ReferenceTables, func(i, j int) bool {
+		a, b := report.ReferenceTables[i], report.ReferenceTables[j]
+		if a.Queries != b.Queries {
+			return a.Queries > b.Queries
+		}
+		return a.Keyspace+"."+a.Table < b.Keyspace+"."+b.Table
+	})
+}
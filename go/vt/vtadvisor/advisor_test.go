@@ -0,0 +1,109 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtadvisor
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/vtexplain"
+)
+
+func initTest(t *testing.T) *Advisor {
+	schema, err := os.ReadFile("testdata/schema.sql")
+	require.NoError(t, err)
+	vschema, err := os.ReadFile("testdata/vschema.json")
+	require.NoError(t, err)
+
+	vte, err := vtexplain.Init(string(vschema), string(schema), "", &vtexplain.Options{
+		ExecutionMode:   vtexplain.ModeMulti,
+		ReplicationMode: "ROW",
+		NumShards:       4,
+		Normalize:       true,
+	})
+	require.NoError(t, err)
+	t.Cleanup(vte.Stop)
+
+	advisor, err := New(vte, string(schema), nil)
+	require.NoError(t, err)
+	return advisor
+}
+
+func TestAdvisor(t *testing.T) {
+	advisor := initTest(t)
+
+	f, err := os.Open("testdata/querylog.txt")
+	require.NoError(t, err)
+	defer f.Close()
+	require.NoError(t, advisor.ReadLog(f))
+
+	report, err := advisor.Analyze()
+	require.NoError(t, err)
+
+	assert.Equal(t, 47, report.TotalQueries)
+	assert.Equal(t, 6, report.UniqueQueries)
+	assert.Equal(t, 1, report.IgnoredQueries)
+	assert.Empty(t, report.Errors)
+
+	assert.Equal(t, []QueryReport{
+		{Query: "select * from corder where customer_id = ?", Count: 20, Shards: 4},
+		{Query: "select `name` from customer where email = ?", Count: 10, Shards: 4},
+		{Query: "select c.`name`, o.price from customer as c join corder as o on c.customer_id = o.customer_id where c.customer_id = ?", Count: 5, Shards: 4},
+	}, report.ScatterQueries)
+	assert.Equal(t, []PrimaryVindexRecommendation{
+		{Keyspace: "commerce", Table: "corder", CurrentColumn: "order_id", Column: "customer_id", Queries: 25, CurrentQueries: 11},
+	}, report.PrimaryVindexes)
+	assert.Equal(t, []LookupVindexRecommendation{
+		{Keyspace: "commerce", Table: "customer", Column: "email", Queries: 10},
+	}, report.LookupVindexes)
+	assert.Equal(t, []ReferenceTableRecommendation{
+		{Keyspace: "commerce", Table: "product", Queries: 8},
+	}, report.ReferenceTables)
+
+	assert.Contains(t, ReportAsText(report), "commerce.corder: shard by customer_id instead of order_id (25 queries instead of 11)")
+	assert.Contains(t, ReportAsJSON(report), `"Column": "email"`)
+}
+
+func TestAdvisorBindVariables(t *testing.T) {
+	advisor := initTest(t)
+
+	require.NoError(t, advisor.AddQuery("select * from customer where customer_id = :vtg1"))
+	require.NoError(t, advisor.AddQuery("select * from customer where customer_id = 42"))
+	require.NoError(t, advisor.AddQuery("select * from product where product_id in ::vtg1"))
+	require.NoError(t, advisor.AddQuery("select * from product where product_id in (1, 2, 3)"))
+	require.NoError(t, advisor.AddQuery("set @x = 1"))
+	require.Error(t, advisor.AddQuery("selec"))
+
+	report, err := advisor.Analyze()
+	require.NoError(t, err)
+	assert.Equal(t, 4, report.TotalQueries)
+	assert.Equal(t, 2, report.UniqueQueries)
+	assert.Empty(t, report.Errors)
+	assert.Empty(t, report.ScatterQueries)
+	assert.Empty(t, report.PrimaryVindexes)
+}
+
+func TestExecutableQuery(t *testing.T) {
+	stmt, err := sqlparser.Parse("select * from t where a = :vtg1 and b in ::vtg2")
+	require.NoError(t, err)
+	assert.Equal(t, "select * from t where a = 1 and b in (1)", executableQuery(stmt))
+	assert.Equal(t, "select * from t where a = ? and b in (?)", fingerprintQuery(stmt))
+}
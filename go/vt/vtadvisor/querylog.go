@@ -0,0 +1,78 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtadvisor
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Field positions of the SQL statement in the text format of the query logs,
+// see the Logf methods of the vtgate and vttablet LogStats
+const (
+	vtgateTextSQLField    = 12
+	vttabletTextSQLField  = 9
+	minQueryLogTextFields = 13
+)
+
+// ParseQueryLogLine extracts the SQL statement from a line of a vtgate or
+// vttablet query log, in either the text or the JSON streamlog format. A line
+// that doesn't look like a query log entry is returned as is, so plain SQL
+// files with one statement per line can be analyzed as well. Empty lines
+// return an empty string.
+func ParseQueryLogLine(line string) (string, error) {
+	line = strings.TrimRight(line, "\r\n")
+	if strings.TrimSpace(line) == "" {
+		return "", nil
+	}
+
+	if strings.HasPrefix(line, "{") {
+		var entry struct {
+			SQL         *string
+			OriginalSQL *string
+		}
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			return "", fmt.Errorf("invalid JSON query log entry: %v", err)
+		}
+		switch {
+		case entry.SQL != nil:
+			return *entry.SQL, nil
+		case entry.OriginalSQL != nil:
+			return *entry.OriginalSQL, nil
+		}
+		return "", fmt.Errorf("JSON query log entry has no SQL field")
+	}
+
+	fields := strings.Split(line, "\t")
+	if len(fields) < minQueryLogTextFields {
+		return line, nil
+	}
+
+	// the vttablet log has the quoted SQL statement where vtgate logs its
+	// execution time
+	pos := vtgateTextSQLField
+	if strings.HasPrefix(fields[vttabletTextSQLField], `"`) {
+		pos = vttabletTextSQLField
+	}
+	sql, err := strconv.Unquote(fields[pos])
+	if err != nil {
+		return "", fmt.Errorf("invalid text query log entry: cannot unquote SQL %s: %v", fields[pos], err)
+	}
+	return sql, nil
+}
@@ -0,0 +1,76 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtadvisor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseQueryLogLine(t *testing.T) {
+	testcases := []struct {
+		name string
+		line string
+		sql  string
+		err  string
+	}{{
+		name: "vtgate text",
+		line: "Execute\t127.0.0.1:5000\tuser\t'user'\t''\t2023-06-01 10:00:00.000000\t2023-06-01 10:00:00.001000\t0.001000\t0.000100\t0.000800\t0.000000\tSELECT\t\"select * from t where a = \\\"x\\\"\"\tmap[]\t2\t0\t\"\"\t\"PRIMARY\"\t\"\"\tfalse\t[]\t\"ks\"\n",
+		sql:  `select * from t where a = "x"`,
+	}, {
+		name: "vttablet text",
+		line: "Execute\t127.0.0.1:5000\tuser\t'user'\t''\t2023-06-01 10:00:00.000000\t2023-06-01 10:00:00.001000\t0.001000\tPASS_SELECT\t\"select 1 from t\"\tmap[]\t1\t\"select 1 from t limit 10001\"\t\"mysql\"\t0.000800\t0.000000\t0\t0\t10\t\"\"\t\n",
+		sql:  "select 1 from t",
+	}, {
+		name: "vtgate json",
+		line: `{"Method": "Execute", "StmtType": "SELECT", "SQL": "select a from t", "BindVars": {}}`,
+		sql:  "select a from t",
+	}, {
+		name: "vttablet json",
+		line: `{"Method": "Execute", "PlanType": "PASS_SELECT", "OriginalSQL": "select b from t", "RewrittenSQL": "select b from t limit 10001"}`,
+		sql:  "select b from t",
+	}, {
+		name: "sql",
+		line: "select c from t\n",
+		sql:  "select c from t",
+	}, {
+		name: "empty",
+		line: "  \n",
+		sql:  "",
+	}, {
+		name: "invalid json",
+		line: `{"SQL": `,
+		err:  "invalid JSON query log entry: unexpected end of JSON input",
+	}, {
+		name: "json without sql",
+		line: `{"Method": "Execute"}`,
+		err:  "JSON query log entry has no SQL field",
+	}}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			sql, err := ParseQueryLogLine(tc.line)
+			if tc.err != "" {
+				require.EqualError(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.sql, sql)
+		})
+	}
+}
@@ -0,0 +1,142 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtadvisor
+
+import (
+	"bytes"
+	"fmt"
+
+	"vitess.io/vitess/go/jsonutil"
+)
+
+type (
+	// Report is the result of the analysis of a workload. All the lists are
+	// ranked by the number of queries of the workload they apply to.
+	Report struct {
+		// TotalQueries is the number of analyzed statements
+		TotalQueries int
+		// UniqueQueries is the number of distinct analyzed statements, once
+		// their values are ignored
+		UniqueQueries int
+		// IgnoredQueries is the number of statements that could not be parsed
+		IgnoredQueries int
+
+		ScatterQueries  []QueryReport
+		PrimaryVindexes []PrimaryVindexRecommendation
+		LookupVindexes  []LookupVindexRecommendation
+		ReferenceTables []ReferenceTableRecommendation
+		Errors          []QueryError
+	}
+
+	// QueryReport describes a query that is sent to all the shards of a keyspace
+	QueryReport struct {
+		Query string
+		Count int
+		// Shards is the number of tablets vtexplain sent the query to
+		Shards int
+	}
+
+	// PrimaryVindexRecommendation recommends sharding a table by another column
+	PrimaryVindexRecommendation struct {
+		Keyspace      string
+		Table         string
+		CurrentColumn string
+		Column        string
+		// Queries is the number of queries that filter or join on Column,
+		// CurrentQueries the number of queries that filter or join on
+		// CurrentColumn
+		Queries        int
+		CurrentQueries int
+	}
+
+	// LookupVindexRecommendation recommends a lookup vindex for the column of
+	// a table that scatter queries filter on
+	LookupVindexRecommendation struct {
+		Keyspace string
+		Table    string
+		Column   string
+		Queries  int
+	}
+
+	// ReferenceTableRecommendation recommends turning a rarely written table
+	// into a reference table, so it can be joined on any shard
+	ReferenceTableRecommendation struct {
+		Keyspace string
+		Table    string
+		Queries  int
+		Writes   int
+	}
+
+	// QueryError describes a query that vtexplain failed to plan
+	QueryError struct {
+		Query string
+		Count int
+		Error string
+	}
+)
+
+// ReportAsJSON returns a json representation of the report
+func ReportAsJSON(report *Report) string {
+	reportJSON, _ := jsonutil.MarshalIndentNoEscape(report, "", "    ")
+	return string(reportJSON)
+}
+
+// ReportAsText returns a human-friendly representation of the report
+func ReportAsText(report *Report) string {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "Analyzed %d queries (%d unique, %d ignored)\n", report.TotalQueries, report.UniqueQueries, report.IgnoredQueries)
+
+	fmt.Fprintf(&b, "\nScatter queries:\n")
+	if len(report.ScatterQueries) == 0 {
+		fmt.Fprintf(&b, "  none\n")
+	}
+	for _, q := range report.ScatterQueries {
+		fmt.Fprintf(&b, "  %d: %s (%d shards)\n", q.Count, q.Query, q.Shards)
+	}
+
+	fmt.Fprintf(&b, "\nPrimary vindexes:\n")
+	if len(report.PrimaryVindexes) == 0 {
+		fmt.Fprintf(&b, "  no change\n")
+	}
+	for _, r := range report.PrimaryVindexes {
+		fmt.Fprintf(&b, "  %s.%s: shard by %s instead of %s (%d queries instead of %d)\n", r.Keyspace, r.Table, r.Column, r.CurrentColumn, r.Queries, r.CurrentQueries)
+	}
+
+	fmt.Fprintf(&b, "\nLookup vindexes:\n")
+	if len(report.LookupVindexes) == 0 {
+		fmt.Fprintf(&b, "  none\n")
+	}
+	for _, r := range report.LookupVindexes {
+		fmt.Fprintf(&b, "  %s.%s(%s): %d queries\n", r.Keyspace, r.Table, r.Column, r.Queries)
+	}
+
+	fmt.Fprintf(&b, "\nReference tables:\n")
+	if len(report.ReferenceTables) == 0 {
+		fmt.Fprintf(&b, "  none\n")
+	}
+	for _, r := range report.ReferenceTables {
+		fmt.Fprintf(&b, "  %s.%s: %d queries, %d writes\n", r.Keyspace, r.Table, r.Queries, r.Writes)
+	}
+
+	if len(report.Errors) > 0 {
+		fmt.Fprintf(&b, "\nQueries that could not be planned:\n")
+		for _, e := range report.Errors {
+			fmt.Fprintf(&b, "  %d: %s: %s\n", e.Count, e.Query, e.Error)
+		}
+	}
+	return b.String()
+}
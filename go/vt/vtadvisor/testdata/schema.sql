@@ -0,0 +1,21 @@
+create table customer (
+	customer_id bigint,
+	email varchar(128),
+	name varchar(128),
+	primary key (customer_id)
+);
+
+create table corder (
+	order_id bigint,
+	customer_id bigint,
+	product_id bigint,
+	price bigint,
+	primary key (order_id)
+);
+
+create table product (
+	product_id bigint,
+	sku varchar(128),
+	description varchar(128),
+	primary key (product_id)
+);
@@ -64,8 +64,8 @@ func init() {
 	addCommand("Tablets", command{
 		name:   "RestoreFromBackup",
 		method: commandRestoreFromBackup,
-		params: "[--backup_timestamp=yyyy-MM-dd.HHmmss] [--restore_to_pos=<pos>] [--dry_run] <tablet alias>",
-		help:   "Stops mysqld and restores the data from the latest backup or if a timestamp is specified then the most recent backup at or before that time. If '--restore_to_pos' is given, then a point in time restore based on one full backup followed by zero or more incremental backups. dry-run only validates restore steps without actually restoring data",
+		params: "[--backup_timestamp=yyyy-MM-dd.HHmmss] [--restore_to_pos=<pos>|--restore_to_timestamp=<RFC3339 time>] [--dry_run] <tablet alias>",
+		help:   "Stops mysqld and restores the data from the latest backup or if a timestamp is specified then the most recent backup at or before that time. If '--restore_to_pos' or '--restore_to_timestamp' is given, then a point in time restore based on one full backup followed by zero or more incremental backups. dry-run only validates restore steps without actually restoring data",
 	})
 }
 
@@ -202,6 +202,7 @@ func (b *backupRestoreEventStreamLogger) Send(resp *vtctldatapb.RestoreFromBacku
 func commandRestoreFromBackup(ctx context.Context, wr *wrangler.Wrangler, subFlags *pflag.FlagSet, args []string) error {
 	backupTimestampStr := subFlags.String("backup_timestamp", "", "Use the backup taken at or before this timestamp rather than using the latest backup.")
 	restoreToPos := subFlags.String("restore_to_pos", "", "Run a point in time recovery that ends with the given position. This will attempt to use one full backup followed by zero or more incremental backups")
+	restoreToTimestampStr := subFlags.String("restore_to_timestamp", "", "Run a point in time recovery that ends with the given timestamp, in RFC 3339 format. This will attempt to use one full backup followed by zero or more incremental backups")
 	dryRun := subFlags.Bool("dry_run", false, "Only validate restore steps, do not actually restore data")
 	if err := subFlags.Parse(args); err != nil {
 		return err
@@ -222,6 +223,18 @@ func commandRestoreFromBackup(ctx context.Context, wr *wrangler.Wrangler, subFla
 		}
 	}
 
+	restoreToTimestamp := time.Time{}
+	if *restoreToTimestampStr != "" {
+		if *restoreToPos != "" {
+			return vterrors.New(vtrpcpb.Code_INVALID_ARGUMENT, "--restore_to_pos and --restore_to_timestamp are mutually exclusive")
+		}
+		var err error
+		restoreToTimestamp, err = time.Parse(time.RFC3339, *restoreToTimestampStr)
+		if err != nil {
+			return vterrors.New(vtrpcpb.Code_INVALID_ARGUMENT, fmt.Sprintf("unable to parse the restore timestamp value provided of '%s'", *restoreToTimestampStr))
+		}
+	}
+
 	tabletAlias, err := topoproto.ParseTabletAlias(subFlags.Arg(0))
 	if err != nil {
 		return err
@@ -236,6 +249,9 @@ func commandRestoreFromBackup(ctx context.Context, wr *wrangler.Wrangler, subFla
 	if !backupTime.IsZero() {
 		req.BackupTime = protoutil.TimeToProto(backupTime)
 	}
+	if !restoreToTimestamp.IsZero() {
+		req.RestoreToTimestamp = protoutil.TimeToProto(restoreToTimestamp)
+	}
 
 	return wr.VtctldServer().RestoreFromBackup(req, &backupRestoreEventStreamLogger{logger: wr.Logger(), ctx: ctx})
 }
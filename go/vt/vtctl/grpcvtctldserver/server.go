@@ -2243,6 +2243,139 @@ func (s *VtctldServer) InitShardPrimaryLocked(
 	return nil
 }
 
+// LookupVindexCreate is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) LookupVindexCreate(ctx context.Context, req *vtctldatapb.LookupVindexCreateRequest) (resp *vtctldatapb.LookupVindexCreateResponse, err error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.LookupVindexCreate")
+	defer span.Finish()
+
+	defer panicHandler(&err)
+
+	if req.Keyspace == "" {
+		err = vterrors.Errorf(vtrpc.Code_INVALID_ARGUMENT, "keyspace field is required")
+		return nil, err
+	}
+
+	if req.Vindex == nil {
+		err = vterrors.Errorf(vtrpc.Code_INVALID_ARGUMENT, "vindex field is required")
+		return nil, err
+	}
+
+	span.Annotate("keyspace", req.Keyspace)
+	span.Annotate("cells", req.Cells)
+	span.Annotate("tablet_types", req.TabletTypes)
+	span.Annotate("continue_after_copy_with_owner", req.ContinueAfterCopyWithOwner)
+
+	resp, err = s.ws.LookupVindexCreate(ctx, req)
+	return resp, err
+}
+
+// MaterializeCreate is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) MaterializeCreate(ctx context.Context, req *vtctldatapb.MaterializeCreateRequest) (resp *vtctldatapb.MaterializeCreateResponse, err error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.MaterializeCreate")
+	defer span.Finish()
+
+	defer panicHandler(&err)
+
+	if req.Settings == nil {
+		err = vterrors.Errorf(vtrpc.Code_INVALID_ARGUMENT, "settings field is required")
+		return nil, err
+	}
+
+	if req.Settings.Workflow == "" {
+		err = vterrors.Errorf(vtrpc.Code_INVALID_ARGUMENT, "settings.workflow field is required")
+		return nil, err
+	}
+
+	if req.Settings.TargetKeyspace == "" {
+		err = vterrors.Errorf(vtrpc.Code_INVALID_ARGUMENT, "settings.target_keyspace field is required")
+		return nil, err
+	}
+
+	span.Annotate("workflow", req.Settings.Workflow)
+	span.Annotate("source_keyspace", req.Settings.SourceKeyspace)
+	span.Annotate("target_keyspace", req.Settings.TargetKeyspace)
+
+	resp, err = s.ws.MaterializeCreate(ctx, req)
+	return resp, err
+}
+
+// MigrateCreate is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) MigrateCreate(ctx context.Context, req *vtctldatapb.MigrateCreateRequest) (resp *vtctldatapb.MigrateCreateResponse, err error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.MigrateCreate")
+	defer span.Finish()
+
+	defer panicHandler(&err)
+
+	if req.Workflow == "" {
+		err = vterrors.Errorf(vtrpc.Code_INVALID_ARGUMENT, "workflow field is required")
+		return nil, err
+	}
+
+	if req.SourceKeyspace == "" {
+		err = vterrors.Errorf(vtrpc.Code_INVALID_ARGUMENT, "source_keyspace field is required")
+		return nil, err
+	}
+
+	if req.TargetKeyspace == "" {
+		err = vterrors.Errorf(vtrpc.Code_INVALID_ARGUMENT, "target_keyspace field is required")
+		return nil, err
+	}
+
+	if req.MountName == "" {
+		err = vterrors.Errorf(vtrpc.Code_INVALID_ARGUMENT, "mount_name field is required")
+		return nil, err
+	}
+
+	if !req.AllTables && len(req.IncludeTables) == 0 {
+		err = vterrors.Errorf(vtrpc.Code_INVALID_ARGUMENT, "either all_tables or include_tables is required")
+		return nil, err
+	}
+
+	span.Annotate("workflow", req.Workflow)
+	span.Annotate("source_keyspace", req.SourceKeyspace)
+	span.Annotate("target_keyspace", req.TargetKeyspace)
+	span.Annotate("mount_name", req.MountName)
+
+	resp, err = s.ws.MigrateCreate(ctx, req)
+	return resp, err
+}
+
+// MoveTablesCreate is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) MoveTablesCreate(ctx context.Context, req *vtctldatapb.MoveTablesCreateRequest) (resp *vtctldatapb.MoveTablesCreateResponse, err error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.MoveTablesCreate")
+	defer span.Finish()
+
+	defer panicHandler(&err)
+
+	if req.Workflow == "" {
+		err = vterrors.Errorf(vtrpc.Code_INVALID_ARGUMENT, "workflow field is required")
+		return nil, err
+	}
+
+	if req.SourceKeyspace == "" {
+		err = vterrors.Errorf(vtrpc.Code_INVALID_ARGUMENT, "source_keyspace field is required")
+		return nil, err
+	}
+
+	if req.TargetKeyspace == "" {
+		err = vterrors.Errorf(vtrpc.Code_INVALID_ARGUMENT, "target_keyspace field is required")
+		return nil, err
+	}
+
+	if !req.AllTables && len(req.IncludeTables) == 0 {
+		err = vterrors.Errorf(vtrpc.Code_INVALID_ARGUMENT, "either all_tables or include_tables is required")
+		return nil, err
+	}
+
+	span.Annotate("workflow", req.Workflow)
+	span.Annotate("source_keyspace", req.SourceKeyspace)
+	span.Annotate("target_keyspace", req.TargetKeyspace)
+	span.Annotate("all_tables", req.AllTables)
+
+	resp, err = s.ws.MoveTablesCreate(ctx, req)
+	return resp, err
+}
+
 // PingTablet is part of the vtctlservicepb.VtctldServer interface.
 func (s *VtctldServer) PingTablet(ctx context.Context, req *vtctldatapb.PingTabletRequest) (resp *vtctldatapb.PingTabletResponse, err error) {
 	span, ctx := trace.NewSpan(ctx, "VtctldServer.PingTablet")
@@ -2702,6 +2835,37 @@ func (s *VtctldServer) ReparentTablet(ctx context.Context, req *vtctldatapb.Repa
 	}, nil
 }
 
+// ReshardCreate is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) ReshardCreate(ctx context.Context, req *vtctldatapb.ReshardCreateRequest) (resp *vtctldatapb.ReshardCreateResponse, err error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.ReshardCreate")
+	defer span.Finish()
+
+	defer panicHandler(&err)
+
+	if req.Workflow == "" {
+		err = vterrors.Errorf(vtrpc.Code_INVALID_ARGUMENT, "workflow field is required")
+		return nil, err
+	}
+
+	if req.Keyspace == "" {
+		err = vterrors.Errorf(vtrpc.Code_INVALID_ARGUMENT, "keyspace field is required")
+		return nil, err
+	}
+
+	if len(req.SourceShards) == 0 || len(req.TargetShards) == 0 {
+		err = vterrors.Errorf(vtrpc.Code_INVALID_ARGUMENT, "source_shards and target_shards fields are required")
+		return nil, err
+	}
+
+	span.Annotate("workflow", req.Workflow)
+	span.Annotate("keyspace", req.Keyspace)
+	span.Annotate("source_shards", req.SourceShards)
+	span.Annotate("target_shards", req.TargetShards)
+
+	resp, err = s.ws.ReshardCreate(ctx, req)
+	return resp, err
+}
+
 func (s *VtctldServer) RestoreFromBackup(req *vtctldatapb.RestoreFromBackupRequest, stream vtctlservicepb.Vtctld_RestoreFromBackupServer) (err error) {
 	span, ctx := trace.NewSpan(stream.Context(), "VtctldServer.RestoreFromBackup")
 	defer span.Finish()
@@ -2723,9 +2887,10 @@ func (s *VtctldServer) RestoreFromBackup(req *vtctldatapb.RestoreFromBackupReque
 	span.Annotate("shard", ti.Shard)
 
 	r := &tabletmanagerdatapb.RestoreFromBackupRequest{
-		BackupTime:   req.BackupTime,
-		RestoreToPos: req.RestoreToPos,
-		DryRun:       req.DryRun,
+		BackupTime:         req.BackupTime,
+		RestoreToPos:       req.RestoreToPos,
+		DryRun:             req.DryRun,
+		RestoreToTimestamp: req.RestoreToTimestamp,
 	}
 	logStream, err := s.tmc.RestoreFromBackup(ctx, ti.Tablet, r)
 	if err != nil {
@@ -2754,7 +2919,7 @@ func (s *VtctldServer) RestoreFromBackup(req *vtctldatapb.RestoreFromBackupReque
 			if mysqlctl.DisableActiveReparents {
 				return nil
 			}
-			if req.RestoreToPos != "" && !req.DryRun {
+			if (req.RestoreToPos != "" || req.RestoreToTimestamp != nil) && !req.DryRun {
 				// point in time recovery. Do not restore replication
 				return nil
 			}
@@ -4342,6 +4507,259 @@ func (s *VtctldServer) ValidateVSchema(ctx context.Context, req *vtctldatapb.Val
 	return resp, err
 }
 
+// VDiffCreate is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) VDiffCreate(ctx context.Context, req *vtctldatapb.VDiffCreateRequest) (resp *vtctldatapb.VDiffCreateResponse, err error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.VDiffCreate")
+	defer span.Finish()
+
+	defer panicHandler(&err)
+
+	if req.Workflow == "" {
+		err = vterrors.Errorf(vtrpc.Code_INVALID_ARGUMENT, "workflow field is required")
+		return nil, err
+	}
+
+	if req.TargetKeyspace == "" {
+		err = vterrors.Errorf(vtrpc.Code_INVALID_ARGUMENT, "target_keyspace field is required")
+		return nil, err
+	}
+
+	span.Annotate("workflow", req.Workflow)
+	span.Annotate("target_keyspace", req.TargetKeyspace)
+	span.Annotate("uuid", req.Uuid)
+	span.Annotate("tables", req.Tables)
+	span.Annotate("checksum", req.Checksum)
+
+	resp, err = s.ws.VDiffCreate(ctx, req)
+	return resp, err
+}
+
+// VDiffDelete is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) VDiffDelete(ctx context.Context, req *vtctldatapb.VDiffDeleteRequest) (resp *vtctldatapb.VDiffDeleteResponse, err error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.VDiffDelete")
+	defer span.Finish()
+
+	defer panicHandler(&err)
+
+	if req.Workflow == "" {
+		err = vterrors.Errorf(vtrpc.Code_INVALID_ARGUMENT, "workflow field is required")
+		return nil, err
+	}
+
+	if req.TargetKeyspace == "" {
+		err = vterrors.Errorf(vtrpc.Code_INVALID_ARGUMENT, "target_keyspace field is required")
+		return nil, err
+	}
+
+	if req.Arg == "" {
+		err = vterrors.Errorf(vtrpc.Code_INVALID_ARGUMENT, "arg field is required")
+		return nil, err
+	}
+
+	span.Annotate("workflow", req.Workflow)
+	span.Annotate("target_keyspace", req.TargetKeyspace)
+	span.Annotate("arg", req.Arg)
+
+	resp, err = s.ws.VDiffDelete(ctx, req)
+	return resp, err
+}
+
+// VDiffResume is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) VDiffResume(ctx context.Context, req *vtctldatapb.VDiffResumeRequest) (resp *vtctldatapb.VDiffResumeResponse, err error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.VDiffResume")
+	defer span.Finish()
+
+	defer panicHandler(&err)
+
+	if req.Workflow == "" {
+		err = vterrors.Errorf(vtrpc.Code_INVALID_ARGUMENT, "workflow field is required")
+		return nil, err
+	}
+
+	if req.TargetKeyspace == "" {
+		err = vterrors.Errorf(vtrpc.Code_INVALID_ARGUMENT, "target_keyspace field is required")
+		return nil, err
+	}
+
+	if req.Uuid == "" {
+		err = vterrors.Errorf(vtrpc.Code_INVALID_ARGUMENT, "uuid field is required")
+		return nil, err
+	}
+
+	span.Annotate("workflow", req.Workflow)
+	span.Annotate("target_keyspace", req.TargetKeyspace)
+	span.Annotate("uuid", req.Uuid)
+
+	resp, err = s.ws.VDiffResume(ctx, req)
+	return resp, err
+}
+
+// VDiffShow is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) VDiffShow(ctx context.Context, req *vtctldatapb.VDiffShowRequest) (resp *vtctldatapb.VDiffShowResponse, err error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.VDiffShow")
+	defer span.Finish()
+
+	defer panicHandler(&err)
+
+	if req.Workflow == "" {
+		err = vterrors.Errorf(vtrpc.Code_INVALID_ARGUMENT, "workflow field is required")
+		return nil, err
+	}
+
+	if req.TargetKeyspace == "" {
+		err = vterrors.Errorf(vtrpc.Code_INVALID_ARGUMENT, "target_keyspace field is required")
+		return nil, err
+	}
+
+	if req.Arg == "" {
+		err = vterrors.Errorf(vtrpc.Code_INVALID_ARGUMENT, "arg field is required")
+		return nil, err
+	}
+
+	span.Annotate("workflow", req.Workflow)
+	span.Annotate("target_keyspace", req.TargetKeyspace)
+	span.Annotate("arg", req.Arg)
+
+	resp, err = s.ws.VDiffShow(ctx, req)
+	return resp, err
+}
+
+// VDiffStop is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) VDiffStop(ctx context.Context, req *vtctldatapb.VDiffStopRequest) (resp *vtctldatapb.VDiffStopResponse, err error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.VDiffStop")
+	defer span.Finish()
+
+	defer panicHandler(&err)
+
+	if req.Workflow == "" {
+		err = vterrors.Errorf(vtrpc.Code_INVALID_ARGUMENT, "workflow field is required")
+		return nil, err
+	}
+
+	if req.TargetKeyspace == "" {
+		err = vterrors.Errorf(vtrpc.Code_INVALID_ARGUMENT, "target_keyspace field is required")
+		return nil, err
+	}
+
+	if req.Uuid == "" {
+		err = vterrors.Errorf(vtrpc.Code_INVALID_ARGUMENT, "uuid field is required")
+		return nil, err
+	}
+
+	span.Annotate("workflow", req.Workflow)
+	span.Annotate("target_keyspace", req.TargetKeyspace)
+	span.Annotate("uuid", req.Uuid)
+
+	resp, err = s.ws.VDiffStop(ctx, req)
+	return resp, err
+}
+
+// WorkflowCancel is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) WorkflowCancel(ctx context.Context, req *vtctldatapb.WorkflowCancelRequest) (resp *vtctldatapb.WorkflowCancelResponse, err error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.WorkflowCancel")
+	defer span.Finish()
+
+	defer panicHandler(&err)
+
+	if req.Keyspace == "" {
+		err = vterrors.Errorf(vtrpc.Code_INVALID_ARGUMENT, "keyspace field is required")
+		return nil, err
+	}
+
+	if req.Workflow == "" {
+		err = vterrors.Errorf(vtrpc.Code_INVALID_ARGUMENT, "workflow field is required")
+		return nil, err
+	}
+
+	span.Annotate("keyspace", req.Keyspace)
+	span.Annotate("workflow", req.Workflow)
+	span.Annotate("keep_data", req.KeepData)
+	span.Annotate("keep_routing_rules", req.KeepRoutingRules)
+
+	resp, err = s.ws.WorkflowCancel(ctx, req)
+	return resp, err
+}
+
+// WorkflowComplete is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) WorkflowComplete(ctx context.Context, req *vtctldatapb.WorkflowCompleteRequest) (resp *vtctldatapb.WorkflowCompleteResponse, err error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.WorkflowComplete")
+	defer span.Finish()
+
+	defer panicHandler(&err)
+
+	if req.Keyspace == "" {
+		err = vterrors.Errorf(vtrpc.Code_INVALID_ARGUMENT, "keyspace field is required")
+		return nil, err
+	}
+
+	if req.Workflow == "" {
+		err = vterrors.Errorf(vtrpc.Code_INVALID_ARGUMENT, "workflow field is required")
+		return nil, err
+	}
+
+	span.Annotate("keyspace", req.Keyspace)
+	span.Annotate("workflow", req.Workflow)
+	span.Annotate("keep_data", req.KeepData)
+	span.Annotate("keep_routing_rules", req.KeepRoutingRules)
+	span.Annotate("rename_tables", req.RenameTables)
+	span.Annotate("dry_run", req.DryRun)
+
+	resp, err = s.ws.WorkflowComplete(ctx, req)
+	return resp, err
+}
+
+// WorkflowStatus is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) WorkflowStatus(ctx context.Context, req *vtctldatapb.WorkflowStatusRequest) (resp *vtctldatapb.WorkflowStatusResponse, err error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.WorkflowStatus")
+	defer span.Finish()
+
+	defer panicHandler(&err)
+
+	if req.Keyspace == "" {
+		err = vterrors.Errorf(vtrpc.Code_INVALID_ARGUMENT, "keyspace field is required")
+		return nil, err
+	}
+
+	if req.Workflow == "" {
+		err = vterrors.Errorf(vtrpc.Code_INVALID_ARGUMENT, "workflow field is required")
+		return nil, err
+	}
+
+	span.Annotate("keyspace", req.Keyspace)
+	span.Annotate("workflow", req.Workflow)
+
+	resp, err = s.ws.WorkflowStatus(ctx, req)
+	return resp, err
+}
+
+// WorkflowSwitchTraffic is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) WorkflowSwitchTraffic(ctx context.Context, req *vtctldatapb.WorkflowSwitchTrafficRequest) (resp *vtctldatapb.WorkflowSwitchTrafficResponse, err error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.WorkflowSwitchTraffic")
+	defer span.Finish()
+
+	defer panicHandler(&err)
+
+	if req.Keyspace == "" {
+		err = vterrors.Errorf(vtrpc.Code_INVALID_ARGUMENT, "keyspace field is required")
+		return nil, err
+	}
+
+	if req.Workflow == "" {
+		err = vterrors.Errorf(vtrpc.Code_INVALID_ARGUMENT, "workflow field is required")
+		return nil, err
+	}
+
+	span.Annotate("keyspace", req.Keyspace)
+	span.Annotate("workflow", req.Workflow)
+	span.Annotate("cells", req.Cells)
+	span.Annotate("tablet_types", req.TabletTypes)
+	span.Annotate("direction", req.Direction)
+	span.Annotate("dry_run", req.DryRun)
+
+	resp, err = s.ws.WorkflowSwitchTraffic(ctx, req)
+	return resp, err
+}
+
 // WorkflowUpdate is part of the vtctlservicepb.VtctldServer interface.
 func (s *VtctldServer) WorkflowUpdate(ctx context.Context, req *vtctldatapb.WorkflowUpdateRequest) (resp *vtctldatapb.WorkflowUpdateResponse, err error) {
 	span, ctx := trace.NewSpan(ctx, "VtctldServer.WorkflowUpdate")
@@ -44,9 +44,12 @@ import (
 	"vitess.io/vitess/go/vt/topo/topoproto"
 	"vitess.io/vitess/go/vt/vtctl/grpcvtctldserver/testutil"
 	"vitess.io/vitess/go/vt/vtctl/localvtctldclient"
+	"vitess.io/vitess/go/vt/vtctl/workflow"
+	"vitess.io/vitess/go/vt/vterrors"
 	"vitess.io/vitess/go/vt/vttablet/tmclient"
 	"vitess.io/vitess/go/vt/vttablet/tmclienttest"
 
+	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
 	logutilpb "vitess.io/vitess/go/vt/proto/logutil"
 	mysqlctlpb "vitess.io/vitess/go/vt/proto/mysqlctl"
 	querypb "vitess.io/vitess/go/vt/proto/query"
@@ -56,6 +59,7 @@ import (
 	vschemapb "vitess.io/vitess/go/vt/proto/vschema"
 	vtctldatapb "vitess.io/vitess/go/vt/proto/vtctldata"
 	vtctlservicepb "vitess.io/vitess/go/vt/proto/vtctlservice"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
 	"vitess.io/vitess/go/vt/proto/vttime"
 )
 
@@ -500,6 +504,9 @@ func TestApplyVSchema(t *testing.T) {
 					ShardRoutingRules: &vschemapb.ShardRoutingRules{
 						Rules: []*vschemapb.ShardRoutingRule{},
 					},
+					TenantRoutingRules: &vschemapb.TenantRoutingRules{
+						Rules: []*vschemapb.TenantRoutingRule{},
+					},
 				}
 				utils.MustMatch(t, changedSrvVSchema, finalSrvVSchema)
 			}
@@ -6098,6 +6105,146 @@ func TestGetVSchema(t *testing.T) {
 	})
 }
 
+func TestMigrateCreate(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	tests := []struct {
+		name     string
+		req      *vtctldatapb.MigrateCreateRequest
+		wantCode vtrpcpb.Code
+	}{
+		{
+			name: "missing workflow",
+			req: &vtctldatapb.MigrateCreateRequest{
+				SourceKeyspace: "source",
+				TargetKeyspace: "target",
+				MountName:      "ext1",
+				AllTables:      true,
+			},
+			wantCode: vtrpcpb.Code_INVALID_ARGUMENT,
+		},
+		{
+			name: "missing mount name",
+			req: &vtctldatapb.MigrateCreateRequest{
+				Workflow:       "wf",
+				SourceKeyspace: "source",
+				TargetKeyspace: "target",
+				AllTables:      true,
+			},
+			wantCode: vtrpcpb.Code_INVALID_ARGUMENT,
+		},
+		{
+			name: "no tables",
+			req: &vtctldatapb.MigrateCreateRequest{
+				Workflow:       "wf",
+				SourceKeyspace: "source",
+				TargetKeyspace: "target",
+				MountName:      "ext1",
+			},
+			wantCode: vtrpcpb.Code_INVALID_ARGUMENT,
+		},
+		{
+			name: "target keyspace not found",
+			req: &vtctldatapb.MigrateCreateRequest{
+				Workflow:       "wf",
+				SourceKeyspace: "source",
+				TargetKeyspace: "doesnotexist",
+				MountName:      "ext1",
+				AllTables:      true,
+			},
+			wantCode: vtrpcpb.Code_NOT_FOUND,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ts, tmc := newTestMoveTablesWorkflowEnv(ctx, t, false, false)
+			vtctld := testutil.NewVtctldServerWithTabletManagerClient(t, ts, tmc, func(ts *topo.Server) vtctlservicepb.VtctldServer {
+				return NewVtctldServer(ts)
+			})
+
+			resp, err := vtctld.MigrateCreate(ctx, tt.req)
+			require.Error(t, err)
+			assert.Nil(t, resp)
+			assert.Equal(t, tt.wantCode, vterrors.Code(err), "unexpected error: %v", err)
+		})
+	}
+}
+
+func TestMoveTablesCreate(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	tests := []struct {
+		name     string
+		frozen   bool
+		exists   bool
+		req      *vtctldatapb.MoveTablesCreateRequest
+		wantCode vtrpcpb.Code
+	}{
+		{
+			name: "missing workflow",
+			req: &vtctldatapb.MoveTablesCreateRequest{
+				SourceKeyspace: "source",
+				TargetKeyspace: "target",
+				AllTables:      true,
+			},
+			wantCode: vtrpcpb.Code_INVALID_ARGUMENT,
+		},
+		{
+			name: "no tables",
+			req: &vtctldatapb.MoveTablesCreateRequest{
+				Workflow:       "wf",
+				SourceKeyspace: "source",
+				TargetKeyspace: "target",
+			},
+			wantCode: vtrpcpb.Code_INVALID_ARGUMENT,
+		},
+		{
+			name: "source keyspace not found",
+			req: &vtctldatapb.MoveTablesCreateRequest{
+				Workflow:       "wf",
+				SourceKeyspace: "doesnotexist",
+				TargetKeyspace: "target",
+				AllTables:      true,
+			},
+			wantCode: vtrpcpb.Code_NOT_FOUND,
+		},
+		{
+			name:   "workflow already exists",
+			exists: true,
+			req: &vtctldatapb.MoveTablesCreateRequest{
+				Workflow:       "wf",
+				SourceKeyspace: "source",
+				TargetKeyspace: "target",
+				AllTables:      true,
+			},
+			wantCode: vtrpcpb.Code_ALREADY_EXISTS,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ts, tmc := newTestMoveTablesWorkflowEnv(ctx, t, tt.exists, tt.frozen)
+			vtctld := testutil.NewVtctldServerWithTabletManagerClient(t, ts, tmc, func(ts *topo.Server) vtctlservicepb.VtctldServer {
+				return NewVtctldServer(ts)
+			})
+
+			resp, err := vtctld.MoveTablesCreate(ctx, tt.req)
+			require.Error(t, err)
+			assert.Nil(t, resp)
+			assert.Equal(t, tt.wantCode, vterrors.Code(err), "unexpected error: %v", err)
+		})
+	}
+}
+
 func TestPingTablet(t *testing.T) {
 	t.Parallel()
 
@@ -11925,6 +12072,276 @@ func TestValidateShard(t *testing.T) {
 		})
 	}
 }
+
+// newTestMoveTablesWorkflowEnv sets up a source and a target keyspace with an
+// unsharded primary each, and a fake tmclient whose target primary lists a
+// MoveTables workflow named "wf" of table t1 if exists is set, frozen if
+// frozen is set.
+func newTestMoveTablesWorkflowEnv(ctx context.Context, t *testing.T, exists bool, frozen bool) (*topo.Server, *testutil.TabletManagerClient) {
+	t.Helper()
+
+	ts := memorytopo.NewServer("zone1")
+	testutil.AddTablets(ctx, t, ts, &testutil.AddTabletOptions{
+		AlsoSetShardPrimary: true,
+	}, &topodatapb.Tablet{
+		Alias: &topodatapb.TabletAlias{
+			Cell: "zone1",
+			Uid:  100,
+		},
+		Keyspace: "source",
+		Shard:    "0",
+		Type:     topodatapb.TabletType_PRIMARY,
+	}, &topodatapb.Tablet{
+		Alias: &topodatapb.TabletAlias{
+			Cell: "zone1",
+			Uid:  200,
+		},
+		Keyspace: "target",
+		Shard:    "0",
+		Type:     topodatapb.TabletType_PRIMARY,
+	})
+	require.NoError(t, ts.SaveVSchema(ctx, "source", &vschemapb.Keyspace{}))
+	require.NoError(t, ts.SaveVSchema(ctx, "target", &vschemapb.Keyspace{}))
+	require.NoError(t, ts.RebuildSrvVSchema(ctx, nil))
+
+	result := sqltypes.MakeTestResult(sqltypes.MakeTestFields(
+		"id|source|message|cell|tablet_types|workflow_type|workflow_sub_type|defer_secondary_keys",
+		"int64|varchar|varchar|varchar|varchar|int64|int64|int64",
+	))
+	if exists {
+		message := ""
+		if frozen {
+			message = workflow.Frozen
+		}
+		result.Rows = append(result.Rows, []sqltypes.Value{
+			sqltypes.NewInt64(1),
+			sqltypes.NewVarChar(`keyspace:"source" shard:"0" filter:{rules:{match:"t1"}}`),
+			sqltypes.NewVarChar(message),
+			sqltypes.NewVarChar("zone1"),
+			sqltypes.NewVarChar("primary"),
+			sqltypes.NewInt64(int64(binlogdatapb.VReplicationWorkflowType_MoveTables)),
+			sqltypes.NewInt64(0),
+			sqltypes.NewInt64(0),
+		})
+	}
+
+	tmc := &testutil.TabletManagerClient{
+		VReplicationExecResults: map[string]map[string]struct {
+			Result *querypb.QueryResult
+			Error  error
+		}{
+			"zone1-0000000200": {
+				"select id, source, message, cell, tablet_types, workflow_type, workflow_sub_type, defer_secondary_keys from _vt.vreplication where workflow = 'wf' and db_name = 'vt_target'": {
+					Result: sqltypes.ResultToProto3(result),
+				},
+			},
+		},
+	}
+	return ts, tmc
+}
+
+func TestVDiffCreate(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	tests := []struct {
+		name     string
+		exists   bool
+		frozen   bool
+		req      *vtctldatapb.VDiffCreateRequest
+		wantCode vtrpcpb.Code
+	}{
+		{
+			name: "missing workflow",
+			req: &vtctldatapb.VDiffCreateRequest{
+				TargetKeyspace: "target",
+			},
+			wantCode: vtrpcpb.Code_INVALID_ARGUMENT,
+		},
+		{
+			name: "invalid uuid",
+			req: &vtctldatapb.VDiffCreateRequest{
+				Workflow:       "wf",
+				TargetKeyspace: "target",
+				Uuid:           "not-a-uuid",
+			},
+			wantCode: vtrpcpb.Code_INVALID_ARGUMENT,
+		},
+		{
+			name:   "writes already switched",
+			exists: true,
+			frozen: true,
+			req: &vtctldatapb.VDiffCreateRequest{
+				Workflow:       "wf",
+				TargetKeyspace: "target",
+			},
+			wantCode: vtrpcpb.Code_FAILED_PRECONDITION,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ts, tmc := newTestMoveTablesWorkflowEnv(ctx, t, tt.exists, tt.frozen)
+			vtctld := testutil.NewVtctldServerWithTabletManagerClient(t, ts, tmc, func(ts *topo.Server) vtctlservicepb.VtctldServer {
+				return NewVtctldServer(ts)
+			})
+
+			resp, err := vtctld.VDiffCreate(ctx, tt.req)
+			require.Error(t, err)
+			assert.Nil(t, resp)
+			assert.Equal(t, tt.wantCode, vterrors.Code(err), "unexpected error: %v", err)
+		})
+	}
+}
+
+func TestWorkflowCancel(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	ts, tmc := newTestMoveTablesWorkflowEnv(ctx, t, false, false)
+	vtctld := testutil.NewVtctldServerWithTabletManagerClient(t, ts, tmc, func(ts *topo.Server) vtctlservicepb.VtctldServer {
+		return NewVtctldServer(ts)
+	})
+
+	resp, err := vtctld.WorkflowCancel(ctx, &vtctldatapb.WorkflowCancelRequest{
+		Keyspace: "target",
+		Workflow: "wf",
+	})
+	require.Error(t, err)
+	assert.Nil(t, resp)
+	assert.Equal(t, vtrpcpb.Code_NOT_FOUND, vterrors.Code(err), "unexpected error: %v", err)
+}
+
+func TestWorkflowComplete(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	tests := []struct {
+		name     string
+		exists   bool
+		req      *vtctldatapb.WorkflowCompleteRequest
+		wantCode vtrpcpb.Code
+	}{
+		{
+			name: "missing keyspace",
+			req: &vtctldatapb.WorkflowCompleteRequest{
+				Workflow: "wf",
+			},
+			wantCode: vtrpcpb.Code_INVALID_ARGUMENT,
+		},
+		{
+			name: "workflow not found",
+			req: &vtctldatapb.WorkflowCompleteRequest{
+				Keyspace: "target",
+				Workflow: "wf",
+			},
+			wantCode: vtrpcpb.Code_NOT_FOUND,
+		},
+		{
+			name:   "traffic not switched",
+			exists: true,
+			req: &vtctldatapb.WorkflowCompleteRequest{
+				Keyspace: "target",
+				Workflow: "wf",
+			},
+			wantCode: vtrpcpb.Code_FAILED_PRECONDITION,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ts, tmc := newTestMoveTablesWorkflowEnv(ctx, t, tt.exists, false)
+			vtctld := testutil.NewVtctldServerWithTabletManagerClient(t, ts, tmc, func(ts *topo.Server) vtctlservicepb.VtctldServer {
+				return NewVtctldServer(ts)
+			})
+
+			resp, err := vtctld.WorkflowComplete(ctx, tt.req)
+			require.Error(t, err)
+			assert.Nil(t, resp)
+			assert.Equal(t, tt.wantCode, vterrors.Code(err), "unexpected error: %v", err)
+		})
+	}
+}
+
+func TestWorkflowStatus(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	ts, tmc := newTestMoveTablesWorkflowEnv(ctx, t, false, false)
+	vtctld := testutil.NewVtctldServerWithTabletManagerClient(t, ts, tmc, func(ts *topo.Server) vtctlservicepb.VtctldServer {
+		return NewVtctldServer(ts)
+	})
+
+	resp, err := vtctld.WorkflowStatus(ctx, &vtctldatapb.WorkflowStatusRequest{
+		Keyspace: "target",
+		Workflow: "wf",
+	})
+	require.Error(t, err)
+	assert.Nil(t, resp)
+	assert.Equal(t, vtrpcpb.Code_NOT_FOUND, vterrors.Code(err), "unexpected error: %v", err)
+}
+
+func TestWorkflowSwitchTraffic(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	tests := []struct {
+		name     string
+		req      *vtctldatapb.WorkflowSwitchTrafficRequest
+		wantCode vtrpcpb.Code
+	}{
+		{
+			name: "invalid direction",
+			req: &vtctldatapb.WorkflowSwitchTrafficRequest{
+				Keyspace:  "target",
+				Workflow:  "wf",
+				Direction: 2,
+			},
+			wantCode: vtrpcpb.Code_INVALID_ARGUMENT,
+		},
+		{
+			name: "invalid tablet type",
+			req: &vtctldatapb.WorkflowSwitchTrafficRequest{
+				Keyspace:    "target",
+				Workflow:    "wf",
+				TabletTypes: []topodatapb.TabletType{topodatapb.TabletType_BACKUP},
+			},
+			wantCode: vtrpcpb.Code_INVALID_ARGUMENT,
+		},
+		{
+			name: "workflow not found",
+			req: &vtctldatapb.WorkflowSwitchTrafficRequest{
+				Keyspace: "target",
+				Workflow: "wf",
+			},
+			wantCode: vtrpcpb.Code_NOT_FOUND,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ts, tmc := newTestMoveTablesWorkflowEnv(ctx, t, false, false)
+			vtctld := testutil.NewVtctldServerWithTabletManagerClient(t, ts, tmc, func(ts *topo.Server) vtctlservicepb.VtctldServer {
+				return NewVtctldServer(ts)
+			})
+
+			resp, err := vtctld.WorkflowSwitchTraffic(ctx, tt.req)
+			require.Error(t, err)
+			assert.Nil(t, resp)
+			assert.Equal(t, tt.wantCode, vterrors.Code(err), "unexpected error: %v", err)
+		})
+	}
+}
+
 func TestMain(m *testing.M) {
 	_flag.ParseFlagsForTest()
 	os.Exit(m.Run())
@@ -0,0 +1,143 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schematools
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"text/template"
+	"time"
+
+	"golang.org/x/sync/semaphore"
+
+	"vitess.io/vitess/go/vt/logutil"
+	"vitess.io/vitess/go/vt/mysqlctl/tmutils"
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/vttablet/tabletmanager/vreplication"
+	"vitess.io/vitess/go/vt/vttablet/tmclient"
+
+	tabletmanagerdatapb "vitess.io/vitess/go/vt/proto/tabletmanagerdata"
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+)
+
+// CopySchemaShard copies the schema from a source tablet to the
+// specified shard.  The schema is applied directly on the primary of
+// the destination shard, and is propagated to the replicas through
+// binlogs.
+func CopySchemaShard(ctx context.Context, ts *topo.Server, tmc tmclient.TabletManagerClient, logger logutil.Logger, sourceTabletAlias *topodatapb.TabletAlias, tables, excludeTables []string, includeViews bool, destKeyspace, destShard string, waitReplicasTimeout time.Duration, skipVerify bool) error {
+	destShardInfo, err := ts.GetShard(ctx, destKeyspace, destShard)
+	if err != nil {
+		return fmt.Errorf("GetShard(%v, %v) failed: %v", destKeyspace, destShard, err)
+	}
+
+	if destShardInfo.PrimaryAlias == nil {
+		return fmt.Errorf("no primary in shard record %v/%v. Consider running 'vtctl InitShardPrimary' in case of a new shard or reparenting the shard to fix the topology data", destKeyspace, destShard)
+	}
+
+	diffs, err := CompareSchemas(ctx, ts, tmc, sourceTabletAlias, destShardInfo.PrimaryAlias, tables, excludeTables, includeViews)
+	if err != nil {
+		return fmt.Errorf("CopySchemaShard failed because schemas could not be compared initially: %v", err)
+	}
+	if diffs == nil {
+		// Return early because dest has already the same schema as source.
+		return nil
+	}
+
+	req := &tabletmanagerdatapb.GetSchemaRequest{Tables: tables, ExcludeTables: excludeTables, IncludeViews: includeViews}
+	sourceSd, err := GetSchema(ctx, ts, tmc, sourceTabletAlias, req)
+	if err != nil {
+		return fmt.Errorf("GetSchema(%v, %v, %v, %v) failed: %v", sourceTabletAlias, tables, excludeTables, includeViews, err)
+	}
+
+	createSQLstmts := tmutils.SchemaDefinitionToSQLStrings(sourceSd)
+
+	destTabletInfo, err := ts.GetTablet(ctx, destShardInfo.PrimaryAlias)
+	if err != nil {
+		return fmt.Errorf("GetTablet(%v) failed: %v", destShardInfo.PrimaryAlias, err)
+	}
+	for _, createSQL := range createSQLstmts {
+		err = applySQLShard(ctx, tmc, destTabletInfo, createSQL)
+		if err != nil {
+			return fmt.Errorf("creating a table failed."+
+				" Most likely some tables already exist on the destination and differ from the source."+
+				" Please remove all to be copied tables from the destination manually and run this command again."+
+				" Full error: %v", err)
+		}
+	}
+
+	// Remember the replication position after all the above were applied.
+	destPrimaryPos, err := tmc.PrimaryPosition(ctx, destTabletInfo.Tablet)
+	if err != nil {
+		return fmt.Errorf("CopySchemaShard: can't get replication position after schema applied: %v", err)
+	}
+
+	// Although the copy was successful, we have to verify it to catch the case
+	// where the database already existed on the destination, but with different
+	// options e.g. a different character set.
+	// In that case, MySQL would have skipped our CREATE DATABASE IF NOT EXISTS
+	// statement.
+	if !skipVerify {
+		diffs, err = CompareSchemas(ctx, ts, tmc, sourceTabletAlias, destShardInfo.PrimaryAlias, tables, excludeTables, includeViews)
+		if err != nil {
+			return fmt.Errorf("CopySchemaShard failed because schemas could not be compared finally: %v", err)
+		}
+		if diffs != nil {
+			return fmt.Errorf("CopySchemaShard was not successful because the schemas between the two tablets %v and %v differ: %v", sourceTabletAlias, destShardInfo.PrimaryAlias, diffs)
+		}
+	}
+
+	// Notify Replicass to reload schema. This is best-effort.
+	reloadCtx, cancel := context.WithTimeout(ctx, waitReplicasTimeout)
+	defer cancel()
+	ReloadShard(reloadCtx, ts, tmc, logger, destKeyspace, destShard, destPrimaryPos, semaphore.NewWeighted(10), true /* includePrimary */)
+	return nil
+}
+
+// applySQLShard applies a given SQL change on a given tablet alias. It allows executing arbitrary
+// SQL statements, but doesn't return any results, so it's only useful for SQL statements
+// that would be run for their effects (e.g., CREATE).
+// It works by applying the SQL statement on the shard's primary tablet with replication turned on.
+// Thus it should be used only for changes that can be applied on a live instance without causing issues;
+// it shouldn't be used for anything that will require a pivot.
+// The SQL statement string is expected to have {{.DatabaseName}} in place of the actual db name.
+func applySQLShard(ctx context.Context, tmc tmclient.TabletManagerClient, tabletInfo *topo.TabletInfo, change string) error {
+	filledChange, err := fillStringTemplate(change, map[string]string{"DatabaseName": tabletInfo.DbName()})
+	if err != nil {
+		return fmt.Errorf("fillStringTemplate failed: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	// Need to make sure that replication is enabled since we're only applying the statement on primaries
+	_, err = tmc.ApplySchema(ctx, tabletInfo.Tablet, &tmutils.SchemaChange{
+		SQL:              filledChange,
+		Force:            false,
+		AllowReplication: true,
+		SQLMode:          vreplication.SQLMode,
+	})
+	return err
+}
+
+// fillStringTemplate returns the string template filled
+func fillStringTemplate(tmpl string, vars any) (string, error) {
+	myTemplate := template.Must(template.New("").Parse(tmpl))
+	data := new(bytes.Buffer)
+	if err := myTemplate.Execute(data, vars); err != nil {
+		return "", err
+	}
+	return data.String(), nil
+}
@@ -62,7 +62,7 @@ func commandVDiff2(ctx context.Context, wr *wrangler.Wrangler, subFlags *pflag.F
 	maxExtraRowsToCompare := subFlags.Int64("max_extra_rows_to_compare", 1000, "If there are collation differences between the source and target, you can have rows that are identical but simply returned in a different order from MySQL. We will do a second pass to compare the rows for any actual differences in this case and this flag allows you to control the resources used for this operation.")
 
 	autoRetry := subFlags.Bool("auto-retry", true, "Should this vdiff automatically retry and continue in case of recoverable errors")
-	checksum := subFlags.Bool("checksum", false, "Use row-level checksums to compare, not yet implemented")
+	checksum := subFlags.Bool("checksum", false, "Compare checksums of primary key ranges, and only compare the rows of the ranges whose checksums differ. Not supported for workflows that filter rows by keyrange, such as Reshard")
 	samplePct := subFlags.Int64("sample_pct", 100, "How many rows to sample, not yet implemented")
 	verbose := subFlags.Bool("verbose", false, "Show verbose vdiff output in summaries")
 	wait := subFlags.Bool("wait", false, "When creating or resuming a vdiff, wait for it to finish before exiting")
@@ -147,7 +147,7 @@ func commandVDiff2(ctx context.Context, wr *wrangler.Wrangler, subFlags *pflag.F
 				return fmt.Errorf("can only show a specific vdiff, please provide a valid UUID; view all with: VDiff -- %s.%s show all", keyspace, workflowName)
 			}
 		}
-	case vdiff.StopAction, vdiff.ResumeAction:
+	case vdiff.StopAction, vdiff.ResumeAction, vdiff.RepairPlanAction, vdiff.RepairAction:
 		vdiffUUID, err = uuid.Parse(actionArg)
 		if err != nil {
 			return fmt.Errorf("can only %s a specific vdiff, please provide a valid UUID; view all with: VDiff -- %s.%s show all", action, keyspace, workflowName)
@@ -214,6 +214,10 @@ func commandVDiff2(ctx context.Context, wr *wrangler.Wrangler, subFlags *pflag.F
 			uuidToDisplay = vdiffUUID.String()
 		}
 		displayVDiff2ActionStatusResponse(wr, format, uuidToDisplay, action, vdiff.CompletedState)
+	case vdiff.RepairPlanAction:
+		displayVDiff2RepairPlanResponse(wr, format, output)
+	case vdiff.RepairAction:
+		displayVDiff2RepairResponse(wr, format, vdiffUUID.String(), output)
 	default:
 		return fmt.Errorf("invalid action %s; %s", action, usage)
 	}
@@ -233,7 +237,11 @@ type vdiffTableSummary struct {
 	MismatchedRows  int64
 	ExtraRowsSource int64
 	ExtraRowsTarget int64
-	LastUpdated     string `json:"LastUpdated,omitempty"`
+	// DuplicateRowsSource and DuplicateRowsTarget count the duplicate rows
+	// found in tables without a primary key.
+	DuplicateRowsSource int64  `json:",omitempty"`
+	DuplicateRowsTarget int64  `json:",omitempty"`
+	LastUpdated         string `json:"LastUpdated,omitempty"`
 }
 type vdiffSummary struct {
 	Workflow, Keyspace string
@@ -561,6 +569,8 @@ func buildVDiff2SingleSummary(wr *wrangler.Wrangler, keyspace, workflow, uuid st
 						ts.MatchingRows += dr.MatchingRows
 						ts.ExtraRowsTarget += dr.ExtraRowsTarget
 						ts.ExtraRowsSource += dr.ExtraRowsSource
+						ts.DuplicateRowsSource += dr.DuplicateRowsSource
+						ts.DuplicateRowsTarget += dr.DuplicateRowsTarget
 					}
 					if _, ok := reports[table]; !ok {
 						reports[table] = make(map[string]vdiff.DiffReport)
@@ -657,6 +667,78 @@ func displayVDiff2ActionStatusResponse(wr *wrangler.Wrangler, format, uuid strin
 	}
 }
 
+// displayVDiff2RepairPlanResponse displays the repair plan of each shard. In
+// text format, the statements that are still pending are output as a script.
+func displayVDiff2RepairPlanResponse(wr *wrangler.Wrangler, format string, output *wrangler.VDiffOutput) {
+	type repairStatement struct {
+		Table, Statement, State string
+	}
+	plans := make(map[string][]repairStatement)
+	for shard, resp := range output.Responses {
+		if resp == nil || resp.Output == nil {
+			continue
+		}
+		for _, row := range sqltypes.Proto3ToResult(resp.Output).Named().Rows {
+			plans[shard] = append(plans[shard], repairStatement{
+				Table:     row.AsString("table_name", ""),
+				Statement: row.AsString("statement", ""),
+				State:     row.AsString("state", ""),
+			})
+		}
+	}
+	if format == "json" {
+		jsonText, _ := json.MarshalIndent(plans, "", "\t")
+		wr.Logger().Printf("%s\n", jsonText)
+		return
+	}
+	shards := make([]string, 0, len(plans))
+	for shard := range plans {
+		shards = append(shards, shard)
+	}
+	sort.Strings(shards)
+	for _, shard := range shards {
+		wr.Logger().Printf("-- shard %s\n", shard)
+		for _, stmt := range plans[shard] {
+			if stmt.State == "pending" {
+				wr.Logger().Printf("%s;\n", stmt.Statement)
+			}
+		}
+	}
+}
+
+func displayVDiff2RepairResponse(wr *wrangler.Wrangler, format, uuid string, output *wrangler.VDiffOutput) {
+	applied := make(map[string]uint64)
+	skipped := make(map[string]int64)
+	for shard, resp := range output.Responses {
+		if resp != nil && resp.Output != nil {
+			qr := sqltypes.Proto3ToResult(resp.Output)
+			applied[shard] = qr.RowsAffected
+			if len(qr.Rows) == 1 {
+				skipped[shard], _ = qr.Named().Row().ToInt64("skipped")
+			}
+		}
+	}
+	if format == "json" {
+		type RepairResponse struct {
+			UUID    string
+			Applied map[string]uint64
+			Skipped map[string]int64
+		}
+		jsonText, _ := json.MarshalIndent(&RepairResponse{UUID: uuid, Applied: applied, Skipped: skipped}, "", "\t")
+		wr.Logger().Printf("%s\n", jsonText)
+		return
+	}
+	shards := make([]string, 0, len(applied))
+	for shard := range applied {
+		shards = append(shards, shard)
+	}
+	sort.Strings(shards)
+	for _, shard := range shards {
+		wr.Logger().Printf("VDiff %s applied %d repair statements on shard %s, and skipped %d whose rows changed since the diff\n",
+			uuid, applied[shard], shard, skipped[shard])
+	}
+}
+
 func buildProgressReport(summary *vdiffSummary, rowsToCompare int64) {
 	report := &vdiff.ProgressReport{}
 	if summary.RowsCompared >= 1 {
@@ -0,0 +1,257 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workflow
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/logutil"
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/topo/memorytopo"
+	"vitess.io/vitess/go/vt/vttablet/tmclient"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	tabletmanagerdatapb "vitess.io/vitess/go/vt/proto/tabletmanagerdata"
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vtctldatapb "vitess.io/vitess/go/vt/proto/vtctldata"
+)
+
+type testMaterializerEnv struct {
+	ws       *Server
+	ms       *vtctldatapb.MaterializeSettings
+	sources  []string
+	targets  []string
+	tablets  map[int]*topodatapb.Tablet
+	topoServ *topo.Server
+	cell     string
+	tmc      *testMaterializerTMClient
+}
+
+//----------------------------------------------
+// testMaterializerEnv
+
+func newTestMaterializerEnv(t *testing.T, ms *vtctldatapb.MaterializeSettings, sources, targets []string) *testMaterializerEnv {
+	t.Helper()
+	env := &testMaterializerEnv{
+		ms:       ms,
+		sources:  sources,
+		targets:  targets,
+		tablets:  make(map[int]*topodatapb.Tablet),
+		topoServ: memorytopo.NewServer("cell"),
+		cell:     "cell",
+		tmc:      newTestMaterializerTMClient(),
+	}
+	env.ws = NewServer(env.topoServ, env.tmc)
+	env.ws.SetLogger(logutil.NewMemoryLogger())
+	tabletID := 100
+	for _, shard := range sources {
+		_ = env.addTablet(tabletID, env.ms.SourceKeyspace, shard, topodatapb.TabletType_PRIMARY)
+		tabletID += 10
+	}
+	if ms.SourceKeyspace != ms.TargetKeyspace {
+		tabletID = 200
+		for _, shard := range targets {
+			_ = env.addTablet(tabletID, env.ms.TargetKeyspace, shard, topodatapb.TabletType_PRIMARY)
+			tabletID += 10
+		}
+	}
+	return env
+}
+
+func (env *testMaterializerEnv) close() {
+	for _, t := range env.tablets {
+		env.deleteTablet(t)
+	}
+}
+
+func (env *testMaterializerEnv) addTablet(id int, keyspace, shard string, tabletType topodatapb.TabletType) *topodatapb.Tablet {
+	return env.addTabletToTopo(env.topoServ, id, keyspace, shard, tabletType)
+}
+
+func (env *testMaterializerEnv) addTabletToTopo(ts *topo.Server, id int, keyspace, shard string, tabletType topodatapb.TabletType) *topodatapb.Tablet {
+	tablet := &topodatapb.Tablet{
+		Alias: &topodatapb.TabletAlias{
+			Cell: env.cell,
+			Uid:  uint32(id),
+		},
+		Keyspace: keyspace,
+		Shard:    shard,
+		KeyRange: &topodatapb.KeyRange{},
+		Type:     tabletType,
+		PortMap: map[string]int32{
+			"test": int32(id),
+		},
+	}
+	env.tablets[id] = tablet
+	if err := ts.InitTablet(context.Background(), tablet, false /* allowPrimaryOverride */, true /* createShardAndKeyspace */, false /* allowUpdate */); err != nil {
+		panic(err)
+	}
+	if tabletType == topodatapb.TabletType_PRIMARY {
+		_, err := ts.UpdateShardFields(context.Background(), keyspace, shard, func(si *topo.ShardInfo) error {
+			si.PrimaryAlias = tablet.Alias
+			return nil
+		})
+		if err != nil {
+			panic(err)
+		}
+	}
+	return tablet
+}
+
+// mountExternalCluster mounts a new memorytopo as the external cluster with
+// the given name, and returns its topo server.
+func (env *testMaterializerEnv) mountExternalCluster(name string) *topo.Server {
+	ts, factory := memorytopo.NewServerAndFactory(env.cell)
+	externalTopoFactory.factory = factory
+	err := env.topoServ.CreateExternalVitessCluster(context.Background(), name, &topodatapb.ExternalVitessCluster{
+		TopoConfig: &topodatapb.TopoConfig{TopoType: externalTopoType},
+	})
+	if err != nil {
+		panic(err)
+	}
+	return ts
+}
+
+func (env *testMaterializerEnv) deleteTablet(tablet *topodatapb.Tablet) {
+	env.topoServ.DeleteTablet(context.Background(), tablet.Alias)
+	delete(env.tablets, int(tablet.Alias.Uid))
+}
+
+//----------------------------------------------
+// testExternalTopoFactory
+
+// externalTopoType is the topo implementation of the external clusters
+// mounted by the tests. It opens the memorytopo of the last mounted cluster.
+const externalTopoType = "memorytopo_external"
+
+var externalTopoFactory = &testExternalTopoFactory{}
+
+func init() {
+	topo.RegisterFactory(externalTopoType, externalTopoFactory)
+}
+
+type testExternalTopoFactory struct {
+	factory *memorytopo.Factory
+}
+
+func (f *testExternalTopoFactory) HasGlobalReadOnlyCell(serverAddr, root string) bool {
+	return f.factory.HasGlobalReadOnlyCell(serverAddr, root)
+}
+
+func (f *testExternalTopoFactory) Create(cell, serverAddr, root string) (topo.Conn, error) {
+	return f.factory.Create(cell, serverAddr, root)
+}
+
+//----------------------------------------------
+// testMaterializerTMClient
+
+type queryResult struct {
+	query  string
+	result *querypb.QueryResult
+}
+
+type testMaterializerTMClient struct {
+	tmclient.TabletManagerClient
+	schema map[string]*tabletmanagerdatapb.SchemaDefinition
+
+	mu        sync.Mutex
+	vrQueries map[int][]*queryResult
+}
+
+func newTestMaterializerTMClient() *testMaterializerTMClient {
+	return &testMaterializerTMClient{
+		schema:    make(map[string]*tabletmanagerdatapb.SchemaDefinition),
+		vrQueries: make(map[int][]*queryResult),
+	}
+}
+
+func (tmc *testMaterializerTMClient) GetSchema(ctx context.Context, tablet *topodatapb.Tablet, request *tabletmanagerdatapb.GetSchemaRequest) (*tabletmanagerdatapb.SchemaDefinition, error) {
+	schemaDefn := &tabletmanagerdatapb.SchemaDefinition{}
+	for _, table := range request.Tables {
+		if table == "/.*/" {
+			// Special case of all tables in keyspace.
+			for key, tableDefn := range tmc.schema {
+				if strings.HasPrefix(key, tablet.Keyspace+".") {
+					schemaDefn.TableDefinitions = append(schemaDefn.TableDefinitions, tableDefn.TableDefinitions...)
+				}
+			}
+			break
+		}
+
+		key := tablet.Keyspace + "." + table
+		tableDefn := tmc.schema[key]
+		if tableDefn == nil {
+			continue
+		}
+		schemaDefn.TableDefinitions = append(schemaDefn.TableDefinitions, tableDefn.TableDefinitions...)
+	}
+	return schemaDefn, nil
+}
+
+func (tmc *testMaterializerTMClient) expectVRQuery(tabletID int, query string, result *sqltypes.Result) {
+	tmc.mu.Lock()
+	defer tmc.mu.Unlock()
+
+	tmc.vrQueries[tabletID] = append(tmc.vrQueries[tabletID], &queryResult{
+		query:  query,
+		result: sqltypes.ResultToProto3(result),
+	})
+}
+
+func (tmc *testMaterializerTMClient) VReplicationExec(ctx context.Context, tablet *topodatapb.Tablet, query string) (*querypb.QueryResult, error) {
+	tmc.mu.Lock()
+	defer tmc.mu.Unlock()
+
+	qrs := tmc.vrQueries[int(tablet.Alias.Uid)]
+	if len(qrs) == 0 {
+		return nil, fmt.Errorf("tablet %v does not expect any more queries: %s", tablet, query)
+	}
+	matched := false
+	if qrs[0].query[0] == '/' {
+		matched = regexp.MustCompile(qrs[0].query[1:]).MatchString(query)
+	} else {
+		matched = query == qrs[0].query
+	}
+	if !matched {
+		return nil, fmt.Errorf("tablet %v:\nunexpected query\n%s\nwant:\n%s", tablet, query, qrs[0].query)
+	}
+	tmc.vrQueries[int(tablet.Alias.Uid)] = qrs[1:]
+	return qrs[0].result, nil
+}
+
+func (tmc *testMaterializerTMClient) verifyQueries(t *testing.T) {
+	t.Helper()
+
+	tmc.mu.Lock()
+	defer tmc.mu.Unlock()
+
+	for tabletID, qrs := range tmc.vrQueries {
+		if len(qrs) != 0 {
+			var list []string
+			for _, qr := range qrs {
+				list = append(list, qr.query)
+			}
+			t.Errorf("tablet %v: found queries that were expected but never got executed by the test: %v", tabletID, list)
+		}
+	}
+}
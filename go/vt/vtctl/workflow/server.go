@@ -20,29 +20,39 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"math"
 	"sort"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/google/uuid"
+	"golang.org/x/sync/semaphore"
 	"google.golang.org/protobuf/encoding/prototext"
 
 	"vitess.io/vitess/go/sets"
 	"vitess.io/vitess/go/sqltypes"
 	"vitess.io/vitess/go/trace"
 	"vitess.io/vitess/go/vt/concurrency"
+	"vitess.io/vitess/go/vt/discovery"
 	"vitess.io/vitess/go/vt/key"
 	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/logutil"
 	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/topotools"
+	"vitess.io/vitess/go/vt/vtctl/schematools"
 	"vitess.io/vitess/go/vt/vtctl/workflow/vexec"
 	"vitess.io/vitess/go/vt/vterrors"
 	"vitess.io/vitess/go/vt/vtgate/evalengine"
+	"vitess.io/vitess/go/vt/vttablet/tabletmanager/vdiff"
 	"vitess.io/vitess/go/vt/vttablet/tmclient"
 
 	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
 	querypb "vitess.io/vitess/go/vt/proto/query"
+	tabletmanagerdatapb "vitess.io/vitess/go/vt/proto/tabletmanagerdata"
 	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
 	vtctldatapb "vitess.io/vitess/go/vt/proto/vtctldata"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
 	"vitess.io/vitess/go/vt/proto/vttime"
 )
 
@@ -64,23 +74,47 @@ var (
 // workflows (MoveTables, Reshard, etc) and schema migration workflows.
 //
 // NB: This is in alpha, and you probably don't want to depend on it (yet!).
-// Currently, it provides only a read-only API to vreplication workflows. Write
-// actions on vreplication workflows, and schema migration workflows entirely,
-// are not yet supported, but planned.
+// Currently, it provides an API to create, switch the traffic of, complete and
+// cancel MoveTables and Reshard workflows, to create, complete and cancel
+// Migrate workflows, and to create Materialize workflows. Schema migration
+// workflows are not yet supported, but planned.
 type Server struct {
-	ts  *topo.Server
-	tmc tmclient.TabletManagerClient
+	ts     *topo.Server
+	tmc    tmclient.TabletManagerClient
+	logger logutil.Logger
+	// Limit the number of concurrent background goroutines if needed.
+	sem *semaphore.Weighted
 }
 
 // NewServer returns a new server instance with the given topo.Server and
 // TabletManagerClient.
 func NewServer(ts *topo.Server, tmc tmclient.TabletManagerClient) *Server {
 	return &Server{
-		ts:  ts,
-		tmc: tmc,
+		ts:     ts,
+		tmc:    tmc,
+		logger: logutil.NewConsoleLogger(),
 	}
 }
 
+// Logger returns the logger used by the server for the workflow actions it
+// performs.
+func (s *Server) Logger() logutil.Logger {
+	return s.logger
+}
+
+// SetLogger can be used to change the logger of the server. Not synchronized,
+// no calls to this server should be in progress.
+func (s *Server) SetLogger(logger logutil.Logger) {
+	s.logger = logger
+}
+
+// SetSemaphore limits the number of concurrent background goroutines the
+// server starts, e.g. to optimize the copy_state table. A nil semaphore means
+// no limit. Not synchronized, no calls to this server should be in progress.
+func (s *Server) SetSemaphore(sem *semaphore.Weighted) {
+	s.sem = sem
+}
+
 // CheckReshardingJournalExistsOnTablet returns the journal (or an empty
 // journal) and a boolean to indicate if the resharding_journal table exists on
 // the given tablet.
@@ -798,3 +832,911 @@ func (s *Server) WorkflowUpdate(ctx context.Context, req *vtctldatapb.WorkflowUp
 	response.Details = details
 	return response, nil
 }
+
+const (
+	// defaultSwitchTrafficTimeout is the default time to wait for the
+	// vreplication of primary traffic to catch up, and the default lag that
+	// is allowed when switching traffic.
+	defaultSwitchTrafficTimeout = 30 * time.Second
+	// defaultSwitchTrafficTabletTypes are the tablet types whose traffic is
+	// switched when none are given.
+	defaultSwitchTrafficTabletTypes = "in_order:RDONLY,REPLICA,PRIMARY"
+	// defaultVDiffTabletTypes are the source tablet types of a vdiff when none
+	// are given.
+	defaultVDiffTabletTypes = "in_order:RDONLY,REPLICA,PRIMARY"
+	// defaultVDiffFilteredReplicationWaitTime is the default time a vdiff
+	// waits for filtered replication to catch up.
+	defaultVDiffFilteredReplicationWaitTime = 30 * time.Second
+	// defaultVDiffMaxExtraRowsToCompare is the default number of extra rows of
+	// a vdiff that are compared a second time.
+	defaultVDiffMaxExtraRowsToCompare = 1000
+)
+
+const (
+	errWorkflowNotFullySwitched  = "cannot complete workflow because you have not yet switched all read and write traffic"
+	errWorkflowPartiallySwitched = "cannot cancel workflow because you have already switched some or all read and write traffic"
+)
+
+const (
+	cannotSwitchError               = "workflow has errors"
+	cannotSwitchCopyIncomplete      = "copy is still in progress"
+	cannotSwitchHighLag             = "replication lag %ds is higher than allowed lag %ds"
+	cannotSwitchFailedTabletRefresh = "could not refresh all of the tablets involved in the operation:\n%s"
+	cannotSwitchFrozen              = "workflow is frozen"
+)
+
+// MoveTablesCreate is part of the vtctlservicepb.VtctldServer interface.
+// It creates a MoveTables workflow, and returns it as listed by GetWorkflows.
+func (s *Server) MoveTablesCreate(ctx context.Context, req *vtctldatapb.MoveTablesCreateRequest) (*vtctldatapb.MoveTablesCreateResponse, error) {
+	for _, keyspace := range []string{req.SourceKeyspace, req.TargetKeyspace} {
+		if err := s.checkKeyspaceExists(ctx, keyspace); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.checkWorkflowDoesNotExist(ctx, req.TargetKeyspace, req.Workflow); err != nil {
+		return nil, err
+	}
+	if err := s.MoveTables(ctx, req.Workflow, req.SourceKeyspace, req.TargetKeyspace, strings.Join(req.IncludeTables, ","),
+		strings.Join(req.Cells, ","), tabletTypesString(req.TabletTypes, req.TabletTypesInPreferenceOrder), req.AllTables,
+		strings.Join(req.ExcludeTables, ","), req.AutoStart, req.StopAfterCopy, "", /* externalCluster */
+		req.DropForeignKeys, req.DeferSecondaryKeys, req.SourceTimeZone, req.OnDdl.String(), req.SourceShards,
+		req.TenantColumn, req.TenantValue); err != nil {
+		return nil, err
+	}
+	wf, err := s.getWorkflow(ctx, req.TargetKeyspace, req.Workflow)
+	if err != nil {
+		return nil, err
+	}
+	return &vtctldatapb.MoveTablesCreateResponse{Workflow: wf}, nil
+}
+
+// MigrateCreate is part of the vtctlservicepb.VtctldServer interface. It
+// creates a Migrate workflow, which imports the tables of a keyspace of the
+// external cluster mounted as req.MountName, and returns it as listed by
+// GetWorkflows.
+func (s *Server) MigrateCreate(ctx context.Context, req *vtctldatapb.MigrateCreateRequest) (*vtctldatapb.MigrateCreateResponse, error) {
+	if err := s.checkKeyspaceExists(ctx, req.TargetKeyspace); err != nil {
+		return nil, err
+	}
+	externalTopo, err := s.ts.OpenExternalVitessClusterServer(ctx, req.MountName)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := externalTopo.GetKeyspace(ctx, req.SourceKeyspace); err != nil {
+		if topo.IsErrType(err, topo.NoNode) {
+			return nil, vterrors.Errorf(vtrpcpb.Code_NOT_FOUND, "%s keyspace does not exist in external cluster %s", req.SourceKeyspace, req.MountName)
+		}
+		return nil, err
+	}
+	if err := s.checkWorkflowDoesNotExist(ctx, req.TargetKeyspace, req.Workflow); err != nil {
+		return nil, err
+	}
+	if err := s.MoveTables(ctx, req.Workflow, req.SourceKeyspace, req.TargetKeyspace, strings.Join(req.IncludeTables, ","),
+		strings.Join(req.Cells, ","), tabletTypesString(req.TabletTypes, req.TabletTypesInPreferenceOrder), req.AllTables,
+		strings.Join(req.ExcludeTables, ","), req.AutoStart, req.StopAfterCopy, req.MountName,
+		req.DropForeignKeys, req.DeferSecondaryKeys, req.SourceTimeZone, req.OnDdl.String(), nil, /* sourceShards */
+		"", "" /* tenantColumn, tenantValue */); err != nil {
+		return nil, err
+	}
+	wf, err := s.getWorkflow(ctx, req.TargetKeyspace, req.Workflow)
+	if err != nil {
+		return nil, err
+	}
+	return &vtctldatapb.MigrateCreateResponse{Workflow: wf}, nil
+}
+
+// ReshardCreate is part of the vtctlservicepb.VtctldServer interface. It
+// creates a Reshard workflow, and returns it as listed by GetWorkflows.
+func (s *Server) ReshardCreate(ctx context.Context, req *vtctldatapb.ReshardCreateRequest) (*vtctldatapb.ReshardCreateResponse, error) {
+	if err := s.checkKeyspaceExists(ctx, req.Keyspace); err != nil {
+		return nil, err
+	}
+	if err := s.checkWorkflowDoesNotExist(ctx, req.Keyspace, req.Workflow); err != nil {
+		return nil, err
+	}
+	if err := schematools.ValidateVSchema(ctx, s.ts, s.tmc, req.Keyspace, req.SourceShards, nil, true /* includeViews */); err != nil {
+		return nil, vterrors.Wrap(err, "ReshardCreate")
+	}
+	if err := s.Reshard(ctx, req.Keyspace, req.Workflow, req.SourceShards, req.TargetShards, req.SkipSchemaCopy,
+		strings.Join(req.Cells, ","), tabletTypesString(req.TabletTypes, req.TabletTypesInPreferenceOrder),
+		req.OnDdl.String(), req.AutoStart, req.StopAfterCopy, req.DeferSecondaryKeys); err != nil {
+		return nil, err
+	}
+	wf, err := s.getWorkflow(ctx, req.Keyspace, req.Workflow)
+	if err != nil {
+		return nil, err
+	}
+	return &vtctldatapb.ReshardCreateResponse{Workflow: wf}, nil
+}
+
+// MaterializeCreate is part of the vtctlservicepb.VtctldServer interface. It
+// creates a Materialize workflow, and returns it as listed by GetWorkflows.
+func (s *Server) MaterializeCreate(ctx context.Context, req *vtctldatapb.MaterializeCreateRequest) (*vtctldatapb.MaterializeCreateResponse, error) {
+	if err := s.Materialize(ctx, req.Settings); err != nil {
+		return nil, err
+	}
+	wf, err := s.getWorkflow(ctx, req.Settings.TargetKeyspace, req.Settings.Workflow)
+	if err != nil {
+		return nil, err
+	}
+	return &vtctldatapb.MaterializeCreateResponse{Workflow: wf}, nil
+}
+
+// LookupVindexCreate is part of the vtctlservicepb.VtctldServer interface. It
+// creates a lookup vindex and the workflow that backfills it, and returns the
+// workflow as listed by GetWorkflows.
+func (s *Server) LookupVindexCreate(ctx context.Context, req *vtctldatapb.LookupVindexCreateRequest) (*vtctldatapb.LookupVindexCreateResponse, error) {
+	ms, err := s.CreateLookupVindex(ctx, req.Keyspace, req.Vindex, strings.Join(req.Cells, ","),
+		tabletTypesString(req.TabletTypes, req.TabletTypesInPreferenceOrder), req.ContinueAfterCopyWithOwner)
+	if err != nil {
+		return nil, err
+	}
+	wf, err := s.getWorkflow(ctx, ms.TargetKeyspace, ms.Workflow)
+	if err != nil {
+		return nil, err
+	}
+	return &vtctldatapb.LookupVindexCreateResponse{Workflow: wf}, nil
+}
+
+// WorkflowStatus is part of the vtctlservicepb.VtctldServer interface. It
+// returns the traffic state, the copy progress and the streams of a
+// MoveTables, Reshard or Migrate workflow.
+func (s *Server) WorkflowStatus(ctx context.Context, req *vtctldatapb.WorkflowStatusRequest) (*vtctldatapb.WorkflowStatusResponse, error) {
+	ts, state, err := s.getVReplicationWorkflowState(ctx, req.Keyspace, req.Workflow, true /* allowMigrate */)
+	if err != nil {
+		return nil, err
+	}
+	resp := &vtctldatapb.WorkflowStatusResponse{
+		TableCopyState: make(map[string]*vtctldatapb.WorkflowStatusResponse_TableCopyState),
+		ShardStreams:   make(map[string]*vtctldatapb.WorkflowStatusResponse_ShardStreams),
+		TrafficState:   workflowStateString(state),
+	}
+	copyProgress, err := s.getCopyProgress(ctx, ts)
+	if err != nil {
+		return nil, err
+	}
+	for table, progress := range copyProgress {
+		resp.TableCopyState[table] = &vtctldatapb.WorkflowStatusResponse_TableCopyState{
+			RowsCopied:      progress.targetRowCount,
+			RowsTotal:       progress.sourceRowCount,
+			RowsPercentage:  percentage(progress.targetRowCount, progress.sourceRowCount),
+			BytesCopied:     progress.targetTableSize,
+			BytesTotal:      progress.sourceTableSize,
+			BytesPercentage: percentage(progress.targetTableSize, progress.sourceTableSize),
+		}
+	}
+	wf, err := s.getWorkflow(ctx, req.Keyspace, req.Workflow)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().Unix()
+	for ksShard, shardStream := range wf.ShardStreams {
+		streams := &vtctldatapb.WorkflowStatusResponse_ShardStreams{}
+		for _, st := range shardStream.Streams {
+			stream := &vtctldatapb.WorkflowStatusResponse_ShardStreamState{
+				Id:       int32(st.Id),
+				Tablet:   st.Tablet,
+				Position: st.Position,
+				Status:   st.State,
+			}
+			if st.BinlogSource != nil {
+				stream.SourceShard = fmt.Sprintf("%s/%s", st.BinlogSource.Keyspace, st.BinlogSource.Shard)
+			}
+			txTimestamp := st.GetTransactionTimestamp().GetSeconds()
+			switch {
+			case st.State == "Error":
+				stream.Info = st.Message
+			case st.Position == "":
+				stream.Info = "VStream has not started"
+			case txTimestamp > 0: // if no events occur after copy phase, TransactionTimeStamp can be 0
+				stream.Info = fmt.Sprintf("VStream Lag: %ds; Tx time: %s", now-txTimestamp,
+					time.Unix(txTimestamp, 0).Format(time.ANSIC))
+			}
+			streams.Streams = append(streams.Streams, stream)
+		}
+		sort.Slice(streams.Streams, func(i, j int) bool {
+			return streams.Streams[i].Id < streams.Streams[j].Id
+		})
+		resp.ShardStreams[ksShard] = streams
+	}
+	return resp, nil
+}
+
+// WorkflowSwitchTraffic is part of the vtctlservicepb.VtctldServer interface.
+// It switches the traffic of a MoveTables or Reshard workflow, in either
+// direction.
+func (s *Server) WorkflowSwitchTraffic(ctx context.Context, req *vtctldatapb.WorkflowSwitchTrafficRequest) (*vtctldatapb.WorkflowSwitchTrafficResponse, error) {
+	direction := TrafficSwitchDirection(req.Direction)
+	if direction != DirectionForward && direction != DirectionBackward {
+		return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "invalid traffic switch direction: %d", req.Direction)
+	}
+	timeout, err := durationOrDefault(req.Timeout, defaultSwitchTrafficTimeout)
+	if err != nil {
+		return nil, err
+	}
+	maxReplicationLagAllowed, err := durationOrDefault(req.MaxReplicationLagAllowed, defaultSwitchTrafficTimeout)
+	if err != nil {
+		return nil, err
+	}
+	tabletTypesStr := defaultSwitchTrafficTabletTypes
+	if len(req.TabletTypes) > 0 {
+		tabletTypesStr = tabletTypesString(req.TabletTypes, false)
+	}
+	tabletTypes, _, err := discovery.ParseTabletTypesAndOrder(tabletTypesStr)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		nonPrimaryTabletTypes []topodatapb.TabletType
+		hasPrimary            bool
+	)
+	for _, tabletType := range tabletTypes {
+		switch tabletType {
+		case topodatapb.TabletType_REPLICA, topodatapb.TabletType_RDONLY:
+			nonPrimaryTabletTypes = append(nonPrimaryTabletTypes, tabletType)
+		case topodatapb.TabletType_PRIMARY:
+			hasPrimary = true
+		default:
+			return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "invalid tablet type passed %s", tabletType)
+		}
+	}
+
+	ts, startState, err := s.getVReplicationWorkflowState(ctx, req.Keyspace, req.Workflow, false /* allowMigrate */)
+	if err != nil {
+		return nil, err
+	}
+	reason, err := s.canSwitch(ctx, ts, startState, direction, int64(math.Ceil(maxReplicationLagAllowed.Seconds())))
+	if err != nil {
+		return nil, err
+	}
+	if reason != "" {
+		return nil, vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "cannot switch traffic for workflow %s at this time: %s", req.Workflow, reason)
+	}
+
+	var dryRunResults []string
+	if len(nonPrimaryTabletTypes) > 0 {
+		rdDryRunResults, err := s.SwitchReads(ctx, req.Keyspace, req.Workflow, nonPrimaryTabletTypes, req.Cells, direction, req.DryRun)
+		if err != nil {
+			return nil, err
+		}
+		if rdDryRunResults != nil {
+			dryRunResults = append(dryRunResults, *rdDryRunResults...)
+		}
+	}
+	if hasPrimary {
+		keyspace, workflowName := req.Keyspace, req.Workflow
+		if direction == DirectionBackward {
+			keyspace, workflowName = ts.SourceKeyspaceName(), ReverseWorkflowName(req.Workflow)
+		}
+		_, wrDryRunResults, err := s.SwitchWrites(ctx, keyspace, workflowName, timeout, false, /* cancel */
+			direction == DirectionBackward, req.EnableReverseReplication, req.DryRun)
+		if err != nil {
+			return nil, err
+		}
+		if wrDryRunResults != nil {
+			dryRunResults = append(dryRunResults, *wrDryRunResults...)
+		}
+	}
+
+	action := "SwitchTraffic"
+	if direction == DirectionBackward {
+		action = "ReverseTraffic"
+	}
+	resp := &vtctldatapb.WorkflowSwitchTrafficResponse{StartState: workflowStateString(startState)}
+	if req.DryRun {
+		resp.Summary = fmt.Sprintf("%s dry run results for workflow %s.%s", action, req.Keyspace, req.Workflow)
+		resp.DryRunResults = dryRunResults
+		resp.CurrentState = resp.StartState
+		return resp, nil
+	}
+	resp.Summary = fmt.Sprintf("%s was successful for workflow %s.%s", action, req.Keyspace, req.Workflow)
+	_, currentState, err := s.GetWorkflowState(ctx, req.Keyspace, req.Workflow)
+	if err != nil {
+		resp.CurrentState = err.Error()
+	} else {
+		resp.CurrentState = workflowStateString(currentState)
+	}
+	return resp, nil
+}
+
+// WorkflowComplete is part of the vtctlservicepb.VtctldServer interface. It
+// cleans up the sources of a MoveTables or Reshard workflow whose traffic has
+// been fully switched, or finalizes a Migrate workflow.
+func (s *Server) WorkflowComplete(ctx context.Context, req *vtctldatapb.WorkflowCompleteRequest) (*vtctldatapb.WorkflowCompleteResponse, error) {
+	ts, state, err := s.getVReplicationWorkflowState(ctx, req.Keyspace, req.Workflow, true /* allowMigrate */)
+	if err != nil {
+		return nil, err
+	}
+	if req.RenameTables && ts.workflowType != binlogdatapb.VReplicationWorkflowType_MoveTables {
+		return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "rename_tables is only supported for MoveTables workflows")
+	}
+	var dryRunResults *[]string
+	if ts.workflowType == binlogdatapb.VReplicationWorkflowType_Migrate {
+		// The traffic of a Migrate workflow is not switched, completing it
+		// drops its streams and adds the imported tables to the target vschema.
+		dryRunResults, err = s.FinalizeMigrateWorkflow(ctx, req.Keyspace, req.Workflow, "",
+			false /* cancel */, req.KeepData, req.KeepRoutingRules, req.DryRun)
+	} else {
+		if !state.WritesSwitched || len(state.ReplicaCellsNotSwitched) > 0 || len(state.RdonlyCellsNotSwitched) > 0 {
+			return nil, vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, errWorkflowNotFullySwitched)
+		}
+		removalType := DropTable
+		if req.RenameTables {
+			removalType = RenameTable
+		}
+		dryRunResults, err = s.DropSources(ctx, req.Keyspace, req.Workflow, removalType, req.KeepData, req.KeepRoutingRules,
+			false /* force */, req.DryRun)
+	}
+	if err != nil {
+		return nil, err
+	}
+	resp := &vtctldatapb.WorkflowCompleteResponse{}
+	if req.DryRun {
+		resp.Summary = fmt.Sprintf("Complete dry run results for workflow %s.%s", req.Keyspace, req.Workflow)
+		if dryRunResults != nil {
+			resp.DryRunResults = *dryRunResults
+		}
+		return resp, nil
+	}
+	resp.Summary = fmt.Sprintf("Complete was successful for workflow %s.%s", req.Keyspace, req.Workflow)
+	return resp, nil
+}
+
+// WorkflowCancel is part of the vtctlservicepb.VtctldServer interface. It
+// cleans up the targets of a MoveTables or Reshard workflow whose traffic has
+// not been switched, or of a Migrate workflow.
+func (s *Server) WorkflowCancel(ctx context.Context, req *vtctldatapb.WorkflowCancelRequest) (*vtctldatapb.WorkflowCancelResponse, error) {
+	ts, state, err := s.getVReplicationWorkflowState(ctx, req.Keyspace, req.Workflow, true /* allowMigrate */)
+	if err != nil {
+		return nil, err
+	}
+	if ts.workflowType == binlogdatapb.VReplicationWorkflowType_Migrate {
+		if _, err := s.FinalizeMigrateWorkflow(ctx, req.Keyspace, req.Workflow, "",
+			true /* cancel */, req.KeepData, req.KeepRoutingRules, false /* dryRun */); err != nil {
+			return nil, err
+		}
+	} else {
+		if state.WritesSwitched || len(state.ReplicaCellsSwitched) > 0 || len(state.RdonlyCellsSwitched) > 0 {
+			return nil, vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, errWorkflowPartiallySwitched)
+		}
+		if _, err := s.DropTargets(ctx, req.Keyspace, req.Workflow, req.KeepData, req.KeepRoutingRules, false /* dryRun */); err != nil {
+			return nil, err
+		}
+	}
+	return &vtctldatapb.WorkflowCancelResponse{
+		Summary: fmt.Sprintf("Cancel was successful for workflow %s.%s", req.Keyspace, req.Workflow),
+	}, nil
+}
+
+// VDiffCreate is part of the vtctlservicepb.VtctldServer interface. It
+// creates a vdiff of a workflow on the primaries of its target shards.
+func (s *Server) VDiffCreate(ctx context.Context, req *vtctldatapb.VDiffCreateRequest) (*vtctldatapb.VDiffCreateResponse, error) {
+	var (
+		vdiffUUID uuid.UUID
+		err       error
+	)
+	if req.Uuid != "" {
+		vdiffUUID, err = uuid.Parse(req.Uuid)
+	} else {
+		vdiffUUID, err = uuid.NewUUID()
+	}
+	if err != nil {
+		return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "invalid vdiff uuid %q: %v", req.Uuid, err)
+	}
+	timeout, err := durationOrDefault(req.FilteredReplicationWaitTime, defaultVDiffFilteredReplicationWaitTime)
+	if err != nil {
+		return nil, err
+	}
+	maxRows := req.Limit
+	if maxRows <= 0 {
+		maxRows = math.MaxInt64
+	}
+	maxExtraRowsToCompare := req.MaxExtraRowsToCompare
+	if maxExtraRowsToCompare <= 0 {
+		maxExtraRowsToCompare = defaultVDiffMaxExtraRowsToCompare
+	}
+	tabletTypes := defaultVDiffTabletTypes
+	if len(req.TabletTypes) > 0 {
+		tabletTypes = tabletTypesString(req.TabletTypes, req.TabletTypesInPreferenceOrder)
+	}
+	options := &tabletmanagerdatapb.VDiffOptions{
+		PickerOptions: &tabletmanagerdatapb.VDiffPickerOptions{
+			TabletTypes: tabletTypes,
+			SourceCell:  strings.Join(req.SourceCells, ","),
+			TargetCell:  strings.Join(req.TargetCells, ","),
+		},
+		CoreOptions: &tabletmanagerdatapb.VDiffCoreOptions{
+			Tables:                strings.Join(req.Tables, ","),
+			AutoRetry:             req.AutoRetry,
+			MaxRows:               maxRows,
+			Checksum:              req.Checksum,
+			SamplePct:             100,
+			TimeoutSeconds:        int64(timeout.Seconds()),
+			MaxExtraRowsToCompare: maxExtraRowsToCompare,
+			UpdateTableStats:      req.UpdateTableStats,
+		},
+		ReportOptions: &tabletmanagerdatapb.VDiffReportOptions{
+			OnlyPks:    req.OnlyPKs,
+			DebugQuery: req.DebugQuery,
+			Format:     "json",
+		},
+	}
+	if _, err := s.vdiff(ctx, req.TargetKeyspace, req.Workflow, vdiff.CreateAction, vdiffUUID.String(), vdiffUUID.String(), options); err != nil {
+		return nil, err
+	}
+	return &vtctldatapb.VDiffCreateResponse{Uuid: vdiffUUID.String()}, nil
+}
+
+// VDiffShow is part of the vtctlservicepb.VtctldServer interface. It returns
+// the reports of one or all of the vdiffs of a workflow.
+func (s *Server) VDiffShow(ctx context.Context, req *vtctldatapb.VDiffShowRequest) (*vtctldatapb.VDiffShowResponse, error) {
+	arg := strings.ToLower(req.Arg)
+	var vdiffUUID uuid.UUID
+	switch arg {
+	case vdiff.AllActionArg, vdiff.LastActionArg:
+	default:
+		var err error
+		if vdiffUUID, err = parseVDiffUUID(arg); err != nil {
+			return nil, err
+		}
+	}
+	responses, err := s.vdiff(ctx, req.TargetKeyspace, req.Workflow, vdiff.ShowAction, arg, vdiffUUID.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	return &vtctldatapb.VDiffShowResponse{TabletResponses: responses}, nil
+}
+
+// VDiffStop is part of the vtctlservicepb.VtctldServer interface. It stops a
+// running vdiff of a workflow.
+func (s *Server) VDiffStop(ctx context.Context, req *vtctldatapb.VDiffStopRequest) (*vtctldatapb.VDiffStopResponse, error) {
+	vdiffUUID, err := parseVDiffUUID(req.Uuid)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.vdiff(ctx, req.TargetKeyspace, req.Workflow, vdiff.StopAction, vdiffUUID.String(), vdiffUUID.String(), nil); err != nil {
+		return nil, err
+	}
+	return &vtctldatapb.VDiffStopResponse{}, nil
+}
+
+// VDiffResume is part of the vtctlservicepb.VtctldServer interface. It
+// resumes a stopped vdiff of a workflow.
+func (s *Server) VDiffResume(ctx context.Context, req *vtctldatapb.VDiffResumeRequest) (*vtctldatapb.VDiffResumeResponse, error) {
+	vdiffUUID, err := parseVDiffUUID(req.Uuid)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.vdiff(ctx, req.TargetKeyspace, req.Workflow, vdiff.ResumeAction, vdiffUUID.String(), vdiffUUID.String(), nil); err != nil {
+		return nil, err
+	}
+	return &vtctldatapb.VDiffResumeResponse{}, nil
+}
+
+// VDiffDelete is part of the vtctlservicepb.VtctldServer interface. It
+// deletes one or all of the vdiffs of a workflow.
+func (s *Server) VDiffDelete(ctx context.Context, req *vtctldatapb.VDiffDeleteRequest) (*vtctldatapb.VDiffDeleteResponse, error) {
+	arg := strings.ToLower(req.Arg)
+	var vdiffUUID uuid.UUID
+	if arg != vdiff.AllActionArg {
+		var err error
+		if vdiffUUID, err = parseVDiffUUID(arg); err != nil {
+			return nil, err
+		}
+	}
+	if _, err := s.vdiff(ctx, req.TargetKeyspace, req.Workflow, vdiff.DeleteAction, arg, vdiffUUID.String(), nil); err != nil {
+		return nil, err
+	}
+	return &vtctldatapb.VDiffDeleteResponse{}, nil
+}
+
+// vdiff performs the vdiff action on the primaries of the target shards of
+// the workflow, and returns their responses by shard.
+func (s *Server) vdiff(ctx context.Context, keyspace, workflowName string, action vdiff.VDiffAction, actionArg, vdiffUUID string,
+	options *tabletmanagerdatapb.VDiffOptions) (map[string]*tabletmanagerdatapb.VDiffResponse, error) {
+	req := &tabletmanagerdatapb.VDiffRequest{
+		Keyspace:  keyspace,
+		Workflow:  workflowName,
+		Action:    string(action),
+		ActionArg: actionArg,
+		Options:   options,
+		VdiffUuid: vdiffUUID,
+	}
+	ts, err := s.BuildTrafficSwitcher(ctx, keyspace, workflowName)
+	if err != nil {
+		return nil, err
+	}
+	if action == vdiff.CreateAction && ts.frozen {
+		return nil, vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "invalid VDiff run: writes have been already been switched for workflow %s.%s",
+			keyspace, workflowName)
+	}
+	var mu sync.Mutex
+	responses := make(map[string]*tabletmanagerdatapb.VDiffResponse, len(ts.targets))
+	err = ts.ForAllTargets(func(target *MigrationTarget) error {
+		resp, err := s.tmc.VDiff(ctx, target.GetPrimary().Tablet, req)
+		mu.Lock()
+		defer mu.Unlock()
+		responses[target.GetShard().ShardName()] = resp
+		return err
+	})
+	if err != nil {
+		log.Errorf("Error executing vdiff action %s: %v", action, err)
+		return nil, err
+	}
+	return responses, nil
+}
+
+// getWorkflow returns the workflow as listed by GetWorkflows.
+func (s *Server) getWorkflow(ctx context.Context, keyspace, workflowName string) (*vtctldatapb.Workflow, error) {
+	resp, err := s.GetWorkflows(ctx, &vtctldatapb.GetWorkflowsRequest{Keyspace: keyspace})
+	if err != nil {
+		return nil, err
+	}
+	for _, wf := range resp.Workflows {
+		if wf.Name == workflowName {
+			return wf, nil
+		}
+	}
+	return nil, vterrors.Errorf(vtrpcpb.Code_NOT_FOUND, "workflow %s.%s does not exist", keyspace, workflowName)
+}
+
+// checkKeyspaceExists returns a NOT_FOUND error if the keyspace does not
+// exist.
+func (s *Server) checkKeyspaceExists(ctx context.Context, keyspace string) error {
+	if _, err := s.ts.GetKeyspace(ctx, keyspace); err != nil {
+		if topo.IsErrType(err, topo.NoNode) {
+			return vterrors.Errorf(vtrpcpb.Code_NOT_FOUND, "%s keyspace does not exist", keyspace)
+		}
+		return err
+	}
+	return nil
+}
+
+// checkWorkflowDoesNotExist returns an error if the workflow already exists.
+func (s *Server) checkWorkflowDoesNotExist(ctx context.Context, keyspace, workflowName string) error {
+	ts, _, err := s.GetWorkflowState(ctx, keyspace, workflowName)
+	if err != nil {
+		return err
+	}
+	if ts != nil {
+		return vterrors.Errorf(vtrpcpb.Code_ALREADY_EXISTS, "workflow %s.%s already exists", keyspace, workflowName)
+	}
+	return nil
+}
+
+// getVReplicationWorkflowState returns the traffic switcher and the state of
+// an existing MoveTables or Reshard workflow, or of a Migrate workflow if
+// allowMigrate is set.
+func (s *Server) getVReplicationWorkflowState(ctx context.Context, keyspace, workflowName string, allowMigrate bool) (*TrafficSwitcher, *State, error) {
+	ts, state, err := s.GetWorkflowState(ctx, keyspace, workflowName)
+	if err != nil {
+		return nil, nil, err
+	}
+	if ts == nil {
+		return nil, nil, vterrors.Errorf(vtrpcpb.Code_NOT_FOUND, "workflow %s.%s does not exist", keyspace, workflowName)
+	}
+	switch ts.workflowType {
+	case binlogdatapb.VReplicationWorkflowType_MoveTables, binlogdatapb.VReplicationWorkflowType_Reshard:
+	case binlogdatapb.VReplicationWorkflowType_Migrate:
+		if !allowMigrate {
+			return nil, nil, vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "workflow %s.%s is a Migrate workflow, whose traffic cannot be switched",
+				keyspace, workflowName)
+		}
+	default:
+		return nil, nil, vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "workflow %s.%s is a %s workflow, not a MoveTables, Reshard or Migrate workflow",
+			keyspace, workflowName, ts.workflowType)
+	}
+	return ts, state, nil
+}
+
+// workflowStateString returns a human readable description of the traffic
+// state of a workflow.
+func workflowStateString(state *State) string {
+	if state == nil {
+		return "Not Created"
+	}
+	var stateInfo []string
+	if state.IsTenantMigration {
+		// The reads and writes of the tenant are switched together by the
+		// tenant routing rule.
+		if state.WritesSwitched {
+			stateInfo = append(stateInfo, "All Reads Switched")
+		} else {
+			stateInfo = append(stateInfo, "Reads Not Switched")
+		}
+	} else if !state.IsPartialMigration { // shard level traffic switching is all or nothing
+		s := ""
+		if len(state.RdonlyCellsNotSwitched) == 0 && len(state.ReplicaCellsNotSwitched) == 0 && len(state.ReplicaCellsSwitched) > 0 {
+			s = "All Reads Switched"
+		} else if len(state.RdonlyCellsSwitched) == 0 && len(state.ReplicaCellsSwitched) == 0 {
+			s = "Reads Not Switched"
+		} else {
+			stateInfo = append(stateInfo, "Reads partially switched")
+			if len(state.ReplicaCellsNotSwitched) == 0 {
+				s += "All Replica Reads Switched"
+			} else if len(state.ReplicaCellsSwitched) == 0 {
+				s += "Replica not switched"
+			} else {
+				s += "Replica switched in cells: " + strings.Join(state.ReplicaCellsSwitched, ",")
+			}
+			stateInfo = append(stateInfo, s)
+			s = ""
+			if len(state.RdonlyCellsNotSwitched) == 0 {
+				s += "All Rdonly Reads Switched"
+			} else if len(state.RdonlyCellsSwitched) == 0 {
+				s += "Rdonly not switched"
+			} else {
+				s += "Rdonly switched in cells: " + strings.Join(state.RdonlyCellsSwitched, ",")
+			}
+		}
+		stateInfo = append(stateInfo, s)
+	}
+	if state.WritesSwitched {
+		stateInfo = append(stateInfo, "Writes Switched")
+	} else if state.IsPartialMigration {
+		// For partial migrations, the traffic switching is all or nothing
+		// at the shard level, so reads are effectively switched on the
+		// shard when writes are switched.
+		if len(state.ShardsAlreadySwitched) > 0 && len(state.ShardsNotYetSwitched) > 0 {
+			stateInfo = append(stateInfo, fmt.Sprintf("Reads partially switched, for shards: %s", strings.Join(state.ShardsAlreadySwitched, ",")))
+			stateInfo = append(stateInfo, fmt.Sprintf("Writes partially switched, for shards: %s", strings.Join(state.ShardsAlreadySwitched, ",")))
+		} else if len(state.ShardsAlreadySwitched) == 0 {
+			stateInfo = append(stateInfo, "Reads Not Switched")
+			stateInfo = append(stateInfo, "Writes Not Switched")
+		} else {
+			stateInfo = append(stateInfo, "All Reads Switched")
+			stateInfo = append(stateInfo, "All Writes Switched")
+		}
+	} else {
+		stateInfo = append(stateInfo, "Writes Not Switched")
+	}
+	return strings.Join(stateInfo, ". ")
+}
+
+// canSwitch returns the reason why the traffic of the workflow cannot be
+// switched in the given direction at this time, or an empty string if it
+// can be.
+func (s *Server) canSwitch(ctx context.Context, ts *TrafficSwitcher, state *State, direction TrafficSwitchDirection, maxAllowedTransactionLagSeconds int64) (reason string, err error) {
+	if direction == DirectionForward && state.WritesSwitched ||
+		direction == DirectionBackward && !state.WritesSwitched {
+		log.Infof("writes already switched no need to check lag")
+		return "", nil
+	}
+
+	// The streams of the reverse workflow run on the primaries of the
+	// source shards.
+	workflowName := ts.WorkflowName()
+	var primaries []*topo.TabletInfo
+	if direction == DirectionBackward {
+		workflowName = ts.ReverseWorkflowName()
+		for _, source := range ts.Sources() {
+			primaries = append(primaries, source.GetPrimary())
+		}
+	} else {
+		for _, target := range ts.Targets() {
+			primaries = append(primaries, target.GetPrimary())
+		}
+	}
+	var (
+		maxTransactionLag int64
+		frozen            bool
+	)
+	now := time.Now().Unix()
+	for _, primary := range primaries {
+		query := fmt.Sprintf("select id, state, message, transaction_timestamp, time_heartbeat from _vt.vreplication where workflow = %s and db_name = %s",
+			encodeString(workflowName), encodeString(primary.DbName()))
+		p3qr, err := s.tmc.VReplicationExec(ctx, primary.Tablet, query)
+		if err != nil {
+			return "", err
+		}
+		qr := sqltypes.Proto3ToResult(p3qr)
+		for _, row := range qr.Named().Rows {
+			if strings.Contains(strings.ToLower(row["message"].ToString()), "error") {
+				return cannotSwitchError, nil
+			}
+			if row["message"].ToString() == Frozen {
+				frozen = true
+			}
+			id, err := row.ToInt64("id")
+			if err != nil {
+				return "", err
+			}
+			copyStates, err := s.tmc.VReplicationExec(ctx, primary.Tablet, fmt.Sprintf("select 1 from _vt.copy_state where vrepl_id = %d limit 1", id))
+			if err != nil {
+				return "", err
+			}
+			if len(copyStates.Rows) > 0 {
+				return cannotSwitchCopyIncomplete, nil
+			}
+			// The transaction lag is the time since the last event that was
+			// applied, or since the last heartbeat if it is more recent, as
+			// there are no pending events then.
+			lastTransactionTimestamp, err := row.ToInt64("transaction_timestamp")
+			if err != nil {
+				return "", err
+			}
+			lastHeartbeatTime, err := row.ToInt64("time_heartbeat")
+			if err != nil {
+				return "", err
+			}
+			if lastTransactionTimestamp == 0 || lastHeartbeatTime > lastTransactionTimestamp {
+				lastTransactionTimestamp = lastHeartbeatTime
+			}
+			if lag := now - lastTransactionTimestamp; lag > maxTransactionLag {
+				maxTransactionLag = lag
+			}
+		}
+	}
+	if frozen {
+		return cannotSwitchFrozen, nil
+	}
+	if maxTransactionLag > maxAllowedTransactionLagSeconds {
+		return fmt.Sprintf(cannotSwitchHighLag, maxTransactionLag, maxAllowedTransactionLagSeconds), nil
+	}
+
+	// Ensure that the tablets on both sides are in good shape as we make this same call in the process
+	// and an error will cause us to backout
+	refreshErrors := strings.Builder{}
+	var m sync.Mutex
+	var wg sync.WaitGroup
+	rtbsCtx, cancel := context.WithTimeout(ctx, shardTabletRefreshTimeout)
+	defer cancel()
+	refreshTablets := func(shards []*topo.ShardInfo, stype string) {
+		defer wg.Done()
+		for _, si := range shards {
+			if partial, partialDetails, err := topotools.RefreshTabletsByShard(rtbsCtx, s.ts, s.tmc, si, nil, s.Logger()); err != nil || partial {
+				m.Lock()
+				refreshErrors.WriteString(fmt.Sprintf("failed to successfully refresh all tablets in the %s/%s %s shard (%v):\n  %v\n",
+					si.Keyspace(), si.ShardName(), stype, err, partialDetails))
+				m.Unlock()
+			}
+		}
+	}
+	wg.Add(1)
+	go refreshTablets(ts.SourceShards(), "source")
+	wg.Add(1)
+	go refreshTablets(ts.TargetShards(), "target")
+	wg.Wait()
+	if refreshErrors.Len() > 0 {
+		return fmt.Sprintf(cannotSwitchFailedTabletRefresh, refreshErrors.String()), nil
+	}
+	return "", nil
+}
+
+// tableCopyProgress stores the row counts and disk sizes of the source and
+// target tables.
+type tableCopyProgress struct {
+	targetRowCount, targetTableSize int64
+	sourceRowCount, sourceTableSize int64
+}
+
+// getCopyProgress returns the progress of all tables being copied in the
+// workflow.
+func (s *Server) getCopyProgress(ctx context.Context, ts *TrafficSwitcher) (map[string]*tableCopyProgress, error) {
+	getTablesQuery := "select distinct table_name from _vt.copy_state cs, _vt.vreplication vr where vr.id = cs.vrepl_id and vr.id = %d"
+	getRowCountQuery := "select table_name, table_rows, data_length from information_schema.tables where table_schema = %s and table_name in (%s)"
+	tables := make(map[string]bool)
+	const maxRows = 1000
+	sourcePrimaries := make(map[*topodatapb.TabletAlias]bool)
+	// The source shards of a Migrate workflow are in the external cluster.
+	sourceTopo := s.ts
+	if ts.externalTopo != nil {
+		sourceTopo = ts.externalTopo
+	}
+	for _, target := range ts.targets {
+		for id, bls := range target.Sources {
+			query := fmt.Sprintf(getTablesQuery, id)
+			p3qr, err := s.tmc.ExecuteFetchAsDba(ctx, target.GetPrimary().Tablet, true, &tabletmanagerdatapb.ExecuteFetchAsDbaRequest{
+				Query:   []byte(query),
+				MaxRows: maxRows,
+			})
+			if err != nil {
+				return nil, err
+			}
+			if len(p3qr.Rows) < 1 {
+				continue
+			}
+			qr := sqltypes.Proto3ToResult(p3qr)
+			for i := 0; i < len(p3qr.Rows); i++ {
+				tables[qr.Rows[i][0].ToString()] = true
+			}
+			sourcesi, err := sourceTopo.GetShard(ctx, bls.Keyspace, bls.Shard)
+			if err != nil {
+				return nil, err
+			}
+			found := false
+			for existingSource := range sourcePrimaries {
+				if existingSource.Uid == sourcesi.PrimaryAlias.Uid {
+					found = true
+				}
+			}
+			if !found {
+				sourcePrimaries[sourcesi.PrimaryAlias] = true
+			}
+		}
+	}
+	if len(tables) == 0 {
+		return nil, nil
+	}
+	var tableList []string
+	targetRowCounts := make(map[string]int64)
+	sourceRowCounts := make(map[string]int64)
+	targetTableSizes := make(map[string]int64)
+	sourceTableSizes := make(map[string]int64)
+
+	for table := range tables {
+		tableList = append(tableList, encodeString(table))
+		targetRowCounts[table] = 0
+		sourceRowCounts[table] = 0
+		targetTableSizes[table] = 0
+		sourceTableSizes[table] = 0
+	}
+
+	getTableMetrics := func(tablet *topodatapb.Tablet, query string, rowCounts map[string]int64, tableSizes map[string]int64) error {
+		p3qr, err := s.tmc.ExecuteFetchAsDba(ctx, tablet, true, &tabletmanagerdatapb.ExecuteFetchAsDbaRequest{
+			Query:   []byte(query),
+			MaxRows: uint64(len(tables)),
+		})
+		if err != nil {
+			return err
+		}
+		qr := sqltypes.Proto3ToResult(p3qr)
+		for i := 0; i < len(qr.Rows); i++ {
+			table := qr.Rows[i][0].ToString()
+			rowCount, err := evalengine.ToInt64(qr.Rows[i][1])
+			if err != nil {
+				return err
+			}
+			tableSize, err := evalengine.ToInt64(qr.Rows[i][2])
+			if err != nil {
+				return err
+			}
+			rowCounts[table] += rowCount
+			tableSizes[table] += tableSize
+		}
+		return nil
+	}
+	sourceDbName := ""
+	for _, tsSource := range ts.sources {
+		sourceDbName = tsSource.GetPrimary().DbName()
+		break
+	}
+	targetDbName := ""
+	for _, tsTarget := range ts.targets {
+		targetDbName = tsTarget.GetPrimary().DbName()
+		break
+	}
+	if sourceDbName == "" || targetDbName == "" {
+		return nil, fmt.Errorf("workflow %s.%s is incorrectly configured", ts.TargetKeyspaceName(), ts.WorkflowName())
+	}
+	sort.Strings(tableList) // sort list for repeatability for mocking in tests
+	tablesStr := strings.Join(tableList, ",")
+	query := fmt.Sprintf(getRowCountQuery, encodeString(targetDbName), tablesStr)
+	for _, target := range ts.targets {
+		if err := getTableMetrics(target.GetPrimary().Tablet, query, targetRowCounts, targetTableSizes); err != nil {
+			return nil, err
+		}
+	}
+
+	query = fmt.Sprintf(getRowCountQuery, encodeString(sourceDbName), tablesStr)
+	for source := range sourcePrimaries {
+		ti, err := sourceTopo.GetTablet(ctx, source)
+		if err != nil {
+			return nil, err
+		}
+		if err := getTableMetrics(ti.Tablet, query, sourceRowCounts, sourceTableSizes); err != nil {
+			return nil, err
+		}
+	}
+
+	copyProgress := make(map[string]*tableCopyProgress, len(targetRowCounts))
+	for table, rowCount := range targetRowCounts {
+		copyProgress[table] = &tableCopyProgress{
+			targetRowCount:  rowCount,
+			targetTableSize: targetTableSizes[table],
+			sourceRowCount:  sourceRowCounts[table],
+			sourceTableSize: sourceTableSizes[table],
+		}
+	}
+	return copyProgress, nil
+}
@@ -44,4 +44,7 @@ type State struct {
 	IsPartialMigration    bool
 	ShardsAlreadySwitched []string
 	ShardsNotYetSwitched  []string
+
+	// Tenant MoveTables info
+	IsTenantMigration bool
 }
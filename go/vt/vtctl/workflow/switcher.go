@@ -14,22 +14,20 @@ See the License for the specific language governing permissions and
 limitations under the License.
 */
 
-package wrangler
+package workflow
 
 import (
 	"context"
 	"time"
 
-	"vitess.io/vitess/go/vt/vtctl/workflow"
-
 	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
 )
 
 var _ iswitcher = (*switcher)(nil)
 
 type switcher struct {
-	ts *trafficSwitcher
-	wr *Wrangler
+	ts *TrafficSwitcher
+	s  *Server
 }
 
 func (r *switcher) addParticipatingTablesToKeyspace(ctx context.Context, keyspace, tableSpecs string) error {
@@ -52,19 +50,23 @@ func (r *switcher) validateWorkflowHasCompleted(ctx context.Context) error {
 	return r.ts.validateWorkflowHasCompleted(ctx)
 }
 
-func (r *switcher) removeSourceTables(ctx context.Context, removalType workflow.TableRemovalType) error {
+func (r *switcher) removeSourceTables(ctx context.Context, removalType TableRemovalType) error {
 	return r.ts.removeSourceTables(ctx, removalType)
 }
 
+func (r *switcher) removeSourceTenantRows(ctx context.Context) error {
+	return r.ts.removeSourceTenantRows(ctx)
+}
+
 func (r *switcher) dropSourceShards(ctx context.Context) error {
 	return r.ts.dropSourceShards(ctx)
 }
 
-func (r *switcher) switchShardReads(ctx context.Context, cells []string, servedTypes []topodatapb.TabletType, direction workflow.TrafficSwitchDirection) error {
+func (r *switcher) switchShardReads(ctx context.Context, cells []string, servedTypes []topodatapb.TabletType, direction TrafficSwitchDirection) error {
 	return r.ts.switchShardReads(ctx, cells, servedTypes, direction)
 }
 
-func (r *switcher) switchTableReads(ctx context.Context, cells []string, servedTypes []topodatapb.TabletType, direction workflow.TrafficSwitchDirection) error {
+func (r *switcher) switchTableReads(ctx context.Context, cells []string, servedTypes []topodatapb.TabletType, direction TrafficSwitchDirection) error {
 	return r.ts.switchTableReads(ctx, cells, servedTypes, direction)
 }
 
@@ -84,15 +86,15 @@ func (r *switcher) changeRouting(ctx context.Context) error {
 	return r.ts.changeRouting(ctx)
 }
 
-func (r *switcher) streamMigraterfinalize(ctx context.Context, ts *trafficSwitcher, workflows []string) error {
-	return workflow.StreamMigratorFinalize(ctx, ts, workflows)
+func (r *switcher) streamMigraterfinalize(ctx context.Context, ts *TrafficSwitcher, workflows []string) error {
+	return StreamMigratorFinalize(ctx, ts, workflows)
 }
 
 func (r *switcher) createReverseVReplication(ctx context.Context) error {
 	return r.ts.createReverseVReplication(ctx)
 }
 
-func (r *switcher) migrateStreams(ctx context.Context, sm *workflow.StreamMigrator) error {
+func (r *switcher) migrateStreams(ctx context.Context, sm *StreamMigrator) error {
 	return sm.MigrateStreams(ctx)
 }
 
@@ -104,17 +106,17 @@ func (r *switcher) stopSourceWrites(ctx context.Context) error {
 	return r.ts.stopSourceWrites(ctx)
 }
 
-func (r *switcher) stopStreams(ctx context.Context, sm *workflow.StreamMigrator) ([]string, error) {
+func (r *switcher) stopStreams(ctx context.Context, sm *StreamMigrator) ([]string, error) {
 	return sm.StopStreams(ctx)
 }
 
-func (r *switcher) cancelMigration(ctx context.Context, sm *workflow.StreamMigrator) {
+func (r *switcher) cancelMigration(ctx context.Context, sm *StreamMigrator) {
 	r.ts.Logger().Infof("Cancel was requested.")
 	r.ts.cancelMigration(ctx, sm)
 }
 
 func (r *switcher) lockKeyspace(ctx context.Context, keyspace, action string) (context.Context, func(*error), error) {
-	return r.wr.ts.LockKeyspace(ctx, keyspace, action)
+	return r.s.ts.LockKeyspace(ctx, keyspace, action)
 }
 
 func (r *switcher) freezeTargetVReplication(ctx context.Context) error {
@@ -14,7 +14,7 @@ See the License for the specific language governing permissions and
 limitations under the License.
 */
 
-package wrangler
+package workflow
 
 import (
 	"context"
@@ -24,7 +24,6 @@ import (
 	"time"
 
 	"vitess.io/vitess/go/mysql"
-	"vitess.io/vitess/go/vt/vtctl/workflow"
 
 	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
 	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
@@ -34,7 +33,7 @@ var _ iswitcher = (*switcherDryRun)(nil)
 
 type switcherDryRun struct {
 	drLog *LogRecorder
-	ts    *trafficSwitcher
+	ts    *TrafficSwitcher
 }
 
 func (dr *switcherDryRun) addParticipatingTablesToKeyspace(ctx context.Context, keyspace, tableSpecs string) error {
@@ -54,7 +53,7 @@ func (dr *switcherDryRun) deleteShardRoutingRules(ctx context.Context) error {
 	return nil
 }
 
-func (dr *switcherDryRun) switchShardReads(ctx context.Context, cells []string, servedTypes []topodatapb.TabletType, direction workflow.TrafficSwitchDirection) error {
+func (dr *switcherDryRun) switchShardReads(ctx context.Context, cells []string, servedTypes []topodatapb.TabletType, direction TrafficSwitchDirection) error {
 	sourceShards := make([]string, 0)
 	targetShards := make([]string, 0)
 	for _, source := range dr.ts.Sources() {
@@ -65,7 +64,7 @@ func (dr *switcherDryRun) switchShardReads(ctx context.Context, cells []string,
 	}
 	sort.Strings(sourceShards)
 	sort.Strings(targetShards)
-	if direction == workflow.DirectionForward {
+	if direction == DirectionForward {
 		dr.drLog.Log(fmt.Sprintf("Switch reads from keyspace %s to keyspace %s for shards %s to shards %s",
 			dr.ts.SourceKeyspaceName(), dr.ts.TargetKeyspaceName(), strings.Join(sourceShards, ","), strings.Join(targetShards, ",")))
 	} else {
@@ -75,9 +74,9 @@ func (dr *switcherDryRun) switchShardReads(ctx context.Context, cells []string,
 	return nil
 }
 
-func (dr *switcherDryRun) switchTableReads(ctx context.Context, cells []string, servedTypes []topodatapb.TabletType, direction workflow.TrafficSwitchDirection) error {
+func (dr *switcherDryRun) switchTableReads(ctx context.Context, cells []string, servedTypes []topodatapb.TabletType, direction TrafficSwitchDirection) error {
 	ks := dr.ts.TargetKeyspaceName()
-	if direction == workflow.DirectionBackward {
+	if direction == DirectionBackward {
 		ks = dr.ts.SourceKeyspaceName()
 	}
 	var tabletTypes []string
@@ -108,6 +107,10 @@ func (dr *switcherDryRun) allowTargetWrites(ctx context.Context) error {
 func (dr *switcherDryRun) changeRouting(ctx context.Context) error {
 	dr.drLog.Log(fmt.Sprintf("Switch routing from keyspace %s to keyspace %s", dr.ts.SourceKeyspaceName(), dr.ts.TargetKeyspaceName()))
 	var deleteLogs, addLogs []string
+	if dr.ts.isTenantMigration() {
+		dr.drLog.Log(fmt.Sprintf("Tenant routing rule for %s=%s will be updated", dr.ts.tenantColumn, dr.ts.tenantValue))
+		return nil
+	}
 	if dr.ts.MigrationType() == binlogdatapb.MigrationType_TABLES {
 		tables := strings.Join(dr.ts.Tables(), ",")
 		dr.drLog.Log(fmt.Sprintf("Routing rules for tables [%s] will be updated", tables))
@@ -130,7 +133,7 @@ func (dr *switcherDryRun) changeRouting(ctx context.Context) error {
 	return nil
 }
 
-func (dr *switcherDryRun) streamMigraterfinalize(ctx context.Context, ts *trafficSwitcher, workflows []string) error {
+func (dr *switcherDryRun) streamMigraterfinalize(ctx context.Context, ts *TrafficSwitcher, workflows []string) error {
 	dr.drLog.Log("Switch writes completed, freeze and delete vreplication streams on:")
 	logs := make([]string, 0)
 	for _, t := range ts.Targets() {
@@ -155,7 +158,7 @@ func (dr *switcherDryRun) createReverseVReplication(ctx context.Context) error {
 	return nil
 }
 
-func (dr *switcherDryRun) migrateStreams(ctx context.Context, sm *workflow.StreamMigrator) error {
+func (dr *switcherDryRun) migrateStreams(ctx context.Context, sm *StreamMigrator) error {
 	templates := sm.Templates()
 
 	if len(templates) == 0 {
@@ -200,13 +203,17 @@ func (dr *switcherDryRun) stopSourceWrites(ctx context.Context) error {
 		logs = append(logs, fmt.Sprintf("\tKeyspace %s, Shard %s at Position %s", dr.ts.SourceKeyspaceName(), source.GetShard().ShardName(), position))
 	}
 	if len(logs) > 0 {
-		dr.drLog.Log(fmt.Sprintf("Stop writes on keyspace %s, tables [%s]:", dr.ts.SourceKeyspaceName(), strings.Join(dr.ts.Tables(), ",")))
+		if dr.ts.isTenantMigration() {
+			dr.drLog.Log(fmt.Sprintf("Stop writes of tenant %s=%s on keyspace %s, tables [%s]:", dr.ts.tenantColumn, dr.ts.tenantValue, dr.ts.SourceKeyspaceName(), strings.Join(dr.ts.Tables(), ",")))
+		} else {
+			dr.drLog.Log(fmt.Sprintf("Stop writes on keyspace %s, tables [%s]:", dr.ts.SourceKeyspaceName(), strings.Join(dr.ts.Tables(), ",")))
+		}
 		dr.drLog.LogSlice(logs)
 	}
 	return nil
 }
 
-func (dr *switcherDryRun) stopStreams(ctx context.Context, sm *workflow.StreamMigrator) ([]string, error) {
+func (dr *switcherDryRun) stopStreams(ctx context.Context, sm *StreamMigrator) ([]string, error) {
 	logs := make([]string, 0)
 	for _, streams := range sm.Streams() {
 		for _, stream := range streams {
@@ -221,7 +228,7 @@ func (dr *switcherDryRun) stopStreams(ctx context.Context, sm *workflow.StreamMi
 	return nil, nil
 }
 
-func (dr *switcherDryRun) cancelMigration(ctx context.Context, sm *workflow.StreamMigrator) {
+func (dr *switcherDryRun) cancelMigration(ctx context.Context, sm *StreamMigrator) {
 	dr.drLog.Log("Cancel stream migrations as requested")
 }
 
@@ -232,7 +239,7 @@ func (dr *switcherDryRun) lockKeyspace(ctx context.Context, keyspace, _ string)
 	}, nil
 }
 
-func (dr *switcherDryRun) removeSourceTables(ctx context.Context, removalType workflow.TableRemovalType) error {
+func (dr *switcherDryRun) removeSourceTables(ctx context.Context, removalType TableRemovalType) error {
 	logs := make([]string, 0)
 	for _, source := range dr.ts.Sources() {
 		for _, tableName := range dr.ts.Tables() {
@@ -241,7 +248,7 @@ func (dr *switcherDryRun) removeSourceTables(ctx context.Context, removalType wo
 		}
 	}
 	action := "Dropping"
-	if removalType == workflow.RenameTable {
+	if removalType == RenameTable {
 		action = "Renaming"
 	}
 	if len(logs) > 0 {
@@ -252,6 +259,22 @@ func (dr *switcherDryRun) removeSourceTables(ctx context.Context, removalType wo
 	return nil
 }
 
+func (dr *switcherDryRun) removeSourceTenantRows(ctx context.Context) error {
+	logs := make([]string, 0)
+	for _, source := range dr.ts.Sources() {
+		for _, tableName := range dr.ts.Tables() {
+			logs = append(logs, fmt.Sprintf("\tKeyspace %s Shard %s DbName %s Tablet %d Table %s",
+				source.GetPrimary().Keyspace, source.GetPrimary().Shard, source.GetPrimary().DbName(), source.GetPrimary().Alias.Uid, tableName))
+		}
+	}
+	if len(logs) > 0 {
+		dr.drLog.Log(fmt.Sprintf("Deleting the rows of tenant %s=%s from these tables of keyspace %s:",
+			dr.ts.tenantColumn, dr.ts.tenantValue, dr.ts.SourceKeyspaceName()))
+		dr.drLog.LogSlice(logs)
+	}
+	return nil
+}
+
 func (dr *switcherDryRun) dropSourceShards(ctx context.Context) error {
 	logs := make([]string, 0)
 	tabletsList := make(map[string][]string)
@@ -296,7 +319,7 @@ func (dr *switcherDryRun) dropSourceReverseVReplicationStreams(ctx context.Conte
 	logs := make([]string, 0)
 	for _, t := range dr.ts.Sources() {
 		logs = append(logs, fmt.Sprintf("\tKeyspace %s Shard %s Workflow %s DbName %s Tablet %d",
-			t.GetShard().Keyspace(), t.GetShard().ShardName(), workflow.ReverseWorkflowName(dr.ts.WorkflowName()), t.GetPrimary().DbName(), t.GetPrimary().Alias.Uid))
+			t.GetShard().Keyspace(), t.GetShard().ShardName(), ReverseWorkflowName(dr.ts.WorkflowName()), t.GetPrimary().DbName(), t.GetPrimary().Alias.Uid))
 	}
 	dr.drLog.LogSlice(logs)
 	return nil
@@ -0,0 +1,303 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workflow
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"vitess.io/vitess/go/mysql"
+	"vitess.io/vitess/go/protoutil"
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/concurrency"
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/topo/topoproto"
+	"vitess.io/vitess/go/vt/vtctl/schematools"
+	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vttablet/tmclient"
+
+	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	tabletmanagerdatapb "vitess.io/vitess/go/vt/proto/tabletmanagerdata"
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+	vttimepb "vitess.io/vitess/go/vt/proto/vttime"
+)
+
+// executeFetchAsDba executes a query on the tablet with the given alias using
+// the DBA pool.
+func (s *Server) executeFetchAsDba(ctx context.Context, tabletAlias *topodatapb.TabletAlias, query string, maxRows int, disableBinlogs bool, reloadSchema bool) (*querypb.QueryResult, error) {
+	ti, err := s.ts.GetTablet(ctx, tabletAlias)
+	if err != nil {
+		return nil, err
+	}
+	return s.tmc.ExecuteFetchAsDba(ctx, ti.Tablet, false, &tabletmanagerdatapb.ExecuteFetchAsDbaRequest{
+		Query:          []byte(query),
+		MaxRows:        uint64(maxRows),
+		DisableBinlogs: disableBinlogs,
+		ReloadSchema:   reloadSchema,
+	})
+}
+
+// vreplicationExec executes a query on the tablet with the given alias using
+// the DBA pool.
+func (s *Server) vreplicationExec(ctx context.Context, tabletAlias *topodatapb.TabletAlias, query string) (*querypb.QueryResult, error) {
+	ti, err := s.ts.GetTablet(ctx, tabletAlias)
+	if err != nil {
+		return nil, err
+	}
+	return s.tmc.VReplicationExec(ctx, ti.Tablet, query)
+}
+
+// validateNewWorkflow ensures that the specified workflow doesn't already exist
+// in the keyspace.
+func (s *Server) validateNewWorkflow(ctx context.Context, keyspace, workflow string) error {
+	allshards, err := s.ts.FindAllShardsInKeyspace(ctx, keyspace)
+	if err != nil {
+		return err
+	}
+	var wg sync.WaitGroup
+	allErrors := &concurrency.AllErrorRecorder{}
+	for _, si := range allshards {
+		if si.PrimaryAlias == nil {
+			allErrors.RecordError(fmt.Errorf("shard has no primary: %v", si.ShardName()))
+			continue
+		}
+		wg.Add(1)
+		go func(si *topo.ShardInfo) {
+			defer wg.Done()
+
+			primary, err := s.ts.GetTablet(ctx, si.PrimaryAlias)
+			if err != nil {
+				allErrors.RecordError(vterrors.Wrap(err, "validateWorkflowName.GetTablet"))
+				return
+			}
+			validations := []struct {
+				query string
+				msg   string
+			}{{
+				fmt.Sprintf("select 1 from _vt.vreplication where db_name=%s and workflow=%s", encodeString(primary.DbName()), encodeString(workflow)),
+				fmt.Sprintf("workflow %s already exists in keyspace %s on tablet %d", workflow, keyspace, primary.Alias.Uid),
+			}, {
+				fmt.Sprintf("select 1 from _vt.vreplication where db_name=%s and message='FROZEN' and workflow_sub_type != %d", encodeString(primary.DbName()), binlogdatapb.VReplicationWorkflowSubType_Partial),
+				fmt.Sprintf("found previous frozen workflow on tablet %d, please review and delete it first before creating a new workflow",
+					primary.Alias.Uid),
+			}}
+			for _, validation := range validations {
+				p3qr, err := s.tmc.VReplicationExec(ctx, primary.Tablet, validation.query)
+				if err != nil {
+					allErrors.RecordError(vterrors.Wrap(err, "validateWorkflowName.VReplicationExec"))
+					return
+				}
+				if p3qr != nil && len(p3qr.Rows) != 0 {
+					allErrors.RecordError(vterrors.Wrap(fmt.Errorf(validation.msg), "validateWorkflowName.VReplicationExec"))
+					return
+				}
+			}
+		}(si)
+	}
+	wg.Wait()
+	return allErrors.AggrError(vterrors.Aggregate)
+}
+
+// refreshPrimaryTablets will just RPC-ping all the primary tablets with RefreshState
+func (s *Server) refreshPrimaryTablets(ctx context.Context, shards []*topo.ShardInfo) error {
+	wg := sync.WaitGroup{}
+	rec := concurrency.AllErrorRecorder{}
+	for _, si := range shards {
+		wg.Add(1)
+		go func(si *topo.ShardInfo) {
+			defer wg.Done()
+			s.Logger().Infof("RefreshState primary %v", topoproto.TabletAliasString(si.PrimaryAlias))
+			ti, err := s.ts.GetTablet(ctx, si.PrimaryAlias)
+			if err != nil {
+				rec.RecordError(err)
+				return
+			}
+
+			if err := s.tmc.RefreshState(ctx, ti.Tablet); err != nil {
+				rec.RecordError(err)
+			} else {
+				s.Logger().Infof("%v responded", topoproto.TabletAliasString(si.PrimaryAlias))
+			}
+		}(si)
+	}
+	wg.Wait()
+	return rec.Error()
+}
+
+// DeleteWorkflowVDiffData cleans up any potential VDiff related data associated with the workflow on the given tablet
+func (s *Server) DeleteWorkflowVDiffData(ctx context.Context, tablet *topodatapb.Tablet, workflow string) {
+	sqlDeleteVDiffs := `delete from vd, vdt, vdl using _vt.vdiff as vd inner join _vt.vdiff_table as vdt on (vd.id = vdt.vdiff_id)
+						inner join _vt.vdiff_log as vdl on (vd.id = vdl.vdiff_id)
+						where vd.keyspace = %s and vd.workflow = %s`
+	query := fmt.Sprintf(sqlDeleteVDiffs, encodeString(tablet.Keyspace), encodeString(workflow))
+	rows := -1
+	if _, err := s.tmc.ExecuteFetchAsDba(ctx, tablet, false, &tabletmanagerdatapb.ExecuteFetchAsDbaRequest{
+		Query:   []byte(query),
+		MaxRows: uint64(rows),
+	}); err != nil {
+		if sqlErr, ok := err.(*mysql.SQLError); ok && sqlErr.Num != mysql.ERNoSuchTable { // the tables may not exist if no vdiffs have been run
+			s.Logger().Errorf("Error deleting vdiff data for %s.%s workflow: %v", tablet.Keyspace, workflow, err)
+		}
+	}
+}
+
+// OptimizeCopyStateTable rebuilds the copy_state table to ensure the on-disk
+// structures are minimal and optimized and resets the auto-inc value for
+// subsequent inserts.
+// This helps to ensure that the size, storage, and performance related factors
+// for the table remain optimal over time and that we don't ever exhaust the
+// available auto-inc values for the table.
+// Note: it's not critical that this executes successfully any given time, it's
+// only important that we try to do this periodically so that things stay in an
+// optimal state over long periods of time. For this reason, the work is done
+// asynchronously in the background on the given tablet and any failures are
+// logged as warnings. Because it's done in the background we use the AllPrivs
+// account to be sure that we don't execute the writes if READ_ONLY is set on
+// the MySQL instance.
+func (s *Server) OptimizeCopyStateTable(tablet *topodatapb.Tablet) {
+	if s.sem != nil {
+		if !s.sem.TryAcquire(1) {
+			log.Warningf("Deferring work to optimize the copy_state table on %q due to hitting the maximum concurrent background job limit.",
+				tablet.Alias.String())
+			return
+		}
+	}
+	go func() {
+		defer func() {
+			if s.sem != nil {
+				s.sem.Release(1)
+			}
+		}()
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Minute)
+		defer cancel()
+		sqlOptimizeTable := "optimize table _vt.copy_state"
+		if _, err := s.tmc.ExecuteFetchAsAllPrivs(ctx, tablet, &tabletmanagerdatapb.ExecuteFetchAsAllPrivsRequest{
+			Query:   []byte(sqlOptimizeTable),
+			MaxRows: uint64(100), // always produces 1+rows with notes and status
+		}); err != nil {
+			if sqlErr, ok := err.(*mysql.SQLError); ok && sqlErr.Num == mysql.ERNoSuchTable { // the table may not exist
+				return
+			}
+			log.Warningf("Failed to optimize the copy_state table on %q: %v", tablet.Alias.String(), err)
+		}
+		// This will automatically set the value to 1 or the current max value in the table, whichever is greater
+		sqlResetAutoInc := "alter table _vt.copy_state auto_increment = 1"
+		if _, err := s.tmc.ExecuteFetchAsAllPrivs(ctx, tablet, &tabletmanagerdatapb.ExecuteFetchAsAllPrivsRequest{
+			Query:   []byte(sqlResetAutoInc),
+			MaxRows: uint64(0),
+		}); err != nil {
+			log.Warningf("Failed to reset the auto_increment value for the copy_state table on %q: %v",
+				tablet.Alias.String(), err)
+		}
+	}()
+}
+
+// tabletTypesString returns the tablet types in the format of the
+// tablet_types option of a workflow.
+func tabletTypesString(tabletTypes []topodatapb.TabletType, inOrder bool) string {
+	if len(tabletTypes) == 0 {
+		return ""
+	}
+	names := make([]string, 0, len(tabletTypes))
+	for _, tabletType := range tabletTypes {
+		names = append(names, topoproto.TabletTypeLString(tabletType))
+	}
+	s := strings.Join(names, ",")
+	if inOrder {
+		s = "in_order:" + s
+	}
+	return s
+}
+
+// durationOrDefault returns the duration, or the default if it is not set.
+func durationOrDefault(d *vttimepb.Duration, defaultDuration time.Duration) (time.Duration, error) {
+	duration, ok, err := protoutil.DurationFromProto(d)
+	if err != nil {
+		return 0, vterrors.Wrapf(err, "invalid duration")
+	}
+	if !ok {
+		return defaultDuration, nil
+	}
+	return duration, nil
+}
+
+// percentage returns the percentage of total that part is.
+func percentage(part, total int64) float32 {
+	if total <= 0 {
+		return 0
+	}
+	return float32(100 * float64(part) / float64(total))
+}
+
+// parseVDiffUUID parses the uuid of a vdiff.
+func parseVDiffUUID(arg string) (uuid.UUID, error) {
+	vdiffUUID, err := uuid.Parse(arg)
+	if err != nil {
+		return vdiffUUID, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "invalid vdiff uuid %q: %v", arg, err)
+	}
+	return vdiffUUID, nil
+}
+
+// TenantValueLiterals returns, for each of the tables, the value of the tenant
+// as a SQL literal of the type of the tenant column in the source keyspace, so
+// that comparing it to the column does not rely on implicit conversions.
+func TenantValueLiterals(ctx context.Context, ts *topo.Server, tmc tmclient.TabletManagerClient, keyspace string, tables []string, column, value string) (map[string]string, error) {
+	shards, err := ts.GetServingShards(ctx, keyspace)
+	if err != nil {
+		return nil, err
+	}
+	if len(shards) == 0 {
+		return nil, fmt.Errorf("keyspace %s has no shards", keyspace)
+	}
+	primary := shards[0].PrimaryAlias
+	if primary == nil {
+		return nil, fmt.Errorf("shard does not have a primary: %v", shards[0].ShardName())
+	}
+	sd, err := schematools.GetSchema(ctx, ts, tmc, primary, &tabletmanagerdatapb.GetSchemaRequest{Tables: tables})
+	if err != nil {
+		return nil, err
+	}
+	literals := make(map[string]string, len(tables))
+	for _, td := range sd.TableDefinitions {
+		for _, field := range td.Fields {
+			if !strings.EqualFold(field.Name, column) {
+				continue
+			}
+			val, err := sqltypes.NewValue(field.Type, []byte(value))
+			if err != nil {
+				return nil, fmt.Errorf("invalid value %s for the tenant column %s of table %s: %v", value, column, td.Name, err)
+			}
+			var sb strings.Builder
+			val.EncodeSQLStringBuilder(&sb)
+			literals[td.Name] = sb.String()
+		}
+	}
+	for _, table := range tables {
+		if _, ok := literals[table]; !ok {
+			return nil, fmt.Errorf("table %s of keyspace %s does not have the tenant column %s", table, keyspace, column)
+		}
+	}
+	return literals, nil
+}
@@ -47,6 +47,7 @@ const (
 	WrongValueCountOnRow
 	WrongValue
 	WrongArguments
+	CharacterSetMismatch
 
 	// failed precondition
 	NoDB
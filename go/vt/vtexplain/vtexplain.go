@@ -21,7 +21,9 @@ package vtexplain
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
+	"os"
 	"sort"
 	"strings"
 	"time"
@@ -37,6 +39,7 @@ import (
 	"vitess.io/vitess/go/vt/log"
 	"vitess.io/vitess/go/vt/sqlparser"
 	"vitess.io/vitess/go/vt/vtgate/engine"
+	"vitess.io/vitess/go/vt/vtgate/vindexes"
 
 	querypb "vitess.io/vitess/go/vt/proto/query"
 	vtgatepb "vitess.io/vitess/go/vt/proto/vtgate"
@@ -127,6 +130,9 @@ type (
 
 		// list of queries / bind vars sent to each tablet
 		TabletActions map[string]*TabletActions
+
+		// the error of the statement, in batch mode
+		Error string `json:",omitempty"`
 	}
 
 	outputQuery struct {
@@ -146,6 +152,9 @@ type (
 		// time simulator
 		batchTime       *sync2.Batcher
 		globalTabletEnv *tabletEnv
+
+		// tablet environments of the keyspaces that have their own schema
+		keyspaceTabletEnvs map[string]*tabletEnv
 	}
 )
 
@@ -186,12 +195,12 @@ func Init(vSchemaStr, sqlSchema, ksShardMapStr string, opts *Options) (*VTExplai
 		return nil, fmt.Errorf("invalid replication mode \"%s\"", opts.ReplicationMode)
 	}
 
-	parsedDDLs, err := parseSchema(sqlSchema, opts)
+	keyspaceDDLs, err := parseKeyspaceSchemas(sqlSchema, opts)
 	if err != nil {
 		return nil, fmt.Errorf("parseSchema: %v", err)
 	}
 
-	tabletEnv, err := newTabletEnvironment(parsedDDLs, opts)
+	tabletEnv, err := newTabletEnvironment(keyspaceDDLs[""], opts)
 	if err != nil {
 		return nil, fmt.Errorf("initTabletEnvironment: %v", err)
 	}
@@ -200,6 +209,18 @@ func Init(vSchemaStr, sqlSchema, ksShardMapStr string, opts *Options) (*VTExplai
 		Autocommit:   true,
 	}}
 	vte.setGlobalTabletEnv(tabletEnv)
+	for keyspace, ddls := range keyspaceDDLs {
+		if keyspace == "" {
+			continue
+		}
+		// the tables of a keyspace are added to those of all the keyspaces
+		ddls = append(append([]sqlparser.DDLStatement{}, keyspaceDDLs[""]...), ddls...)
+		env, err := newTabletEnvironment(ddls, opts)
+		if err != nil {
+			return nil, fmt.Errorf("initTabletEnvironment for keyspace %s: %v", keyspace, err)
+		}
+		vte.setKeyspaceTabletEnv(keyspace, env)
+	}
 	err = vte.initVtgateExecutor(vSchemaStr, ksShardMapStr, opts)
 	if err != nil {
 		return nil, fmt.Errorf("initVtgateExecutor: %v", err.Error())
@@ -221,6 +242,67 @@ func (vte *VTExplain) Stop() {
 	}
 }
 
+// VSchema returns the vschema the fake execution environment routes queries with
+func (vte *VTExplain) VSchema() *vindexes.VSchema {
+	return vte.vtgateExecutor.VSchema()
+}
+
+// parseKeyspaceSchemas parses the schema of the tablets of every keyspace.
+// The schema is either a list of SQL statements, or a JSON map of keyspace
+// names to such lists. Tables that are created with a keyspace qualifier
+// only exist in that keyspace. The tables of the empty keyspace name exist
+// in all the keyspaces.
+func parseKeyspaceSchemas(sqlSchema string, opts *Options) (map[string][]sqlparser.DDLStatement, error) {
+	schemas := map[string]string{"": sqlSchema}
+	if strings.HasPrefix(strings.TrimSpace(sqlSchema), "{") {
+		schemas = nil
+		if err := json.Unmarshal([]byte(sqlSchema), &schemas); err != nil {
+			return nil, fmt.Errorf("invalid keyspace schema map: %v", err)
+		}
+	}
+
+	keyspaceDDLs := make(map[string][]sqlparser.DDLStatement)
+	for keyspace, schema := range schemas {
+		ddls, err := parseSchema(schema, opts)
+		if err != nil {
+			return nil, err
+		}
+		for _, ddl := range ddls {
+			ks := keyspace
+			if qualifier := ddl.GetTable().Qualifier; !qualifier.IsEmpty() {
+				ks = qualifier.String()
+			}
+			keyspaceDDLs[ks] = append(keyspaceDDLs[ks], ddl)
+		}
+	}
+	return keyspaceDDLs, nil
+}
+
+// GetFileParam returns flag if it is not "", or the content of the file
+// named flagFile. It is shared by the command line tools that accept
+// their inputs either inline or from a file.
+func GetFileParam(flag, flagFile, name string, required bool) (string, error) {
+	if flag != "" {
+		if flagFile != "" {
+			return "", fmt.Errorf("action requires only one of %v or %v-file", name, name)
+		}
+		return flag, nil
+	}
+
+	if flagFile == "" {
+		if required {
+			return "", fmt.Errorf("action requires one of %v or %v-file", name, name)
+		}
+
+		return "", nil
+	}
+	data, err := os.ReadFile(flagFile)
+	if err != nil {
+		return "", fmt.Errorf("cannot read file %v: %v", flagFile, err)
+	}
+	return string(data), nil
+}
+
 func parseSchema(sqlSchema string, opts *Options) ([]sqlparser.DDLStatement, error) {
 	parsedDDLs := make([]sqlparser.DDLStatement, 0, 16)
 	for {
@@ -315,6 +397,52 @@ func (vte *VTExplain) Run(sql string) ([]*Explain, error) {
 	return explains, nil
 }
 
+// RunBatch runs the explain analysis on each of the given queries. Unlike Run,
+// it doesn't stop at the first query that fails: the error is recorded in the
+// explain of the query, and the session is reset before the next query.
+func (vte *VTExplain) RunBatch(sql string) ([]*Explain, error) {
+	pieces, err := sqlparser.SplitStatementToPieces(sql)
+	if err != nil {
+		return nil, err
+	}
+
+	explains := make([]*Explain, 0, len(pieces))
+	for _, piece := range pieces {
+		for {
+			s := sqlparser.StripLeadingComments(piece)
+			if s == piece {
+				break
+			}
+			piece = s
+		}
+		if piece == "" {
+			continue
+		}
+
+		if !vte.vtgateSession.GetInTransaction() {
+			vte.batchTime = sync2.NewBatcher(batchInterval)
+		}
+		e, err := vte.explain(piece)
+		if err != nil {
+			explains = append(explains, &Explain{SQL: piece, Error: err.Error()})
+			vte.resetSession()
+			continue
+		}
+		explains = append(explains, e)
+	}
+	return explains, nil
+}
+
+// resetSession replaces the vtgate session with a new autocommit session, so
+// a failed query doesn't leave a transaction open for the next ones
+func (vte *VTExplain) resetSession() {
+	vte.vtgateSession = &vtgatepb.Session{
+		TargetString: vte.vtgateSession.TargetString,
+		Autocommit:   true,
+		Options:      vte.vtgateSession.Options,
+	}
+}
+
 func (vte *VTExplain) explain(sql string) (*Explain, error) {
 	plans, tabletActions, err := vte.vtgateExecute(sql)
 	if err != nil {
@@ -335,6 +463,10 @@ func (vte *VTExplain) ExplainsAsText(explains []*Explain) (string, error) {
 	for _, explain := range explains {
 		fmt.Fprintf(&b, "----------------------------------------------------------------------\n")
 		fmt.Fprintf(&b, "%s\n\n", explain.SQL)
+		if explain.Error != "" {
+			fmt.Fprintf(&b, "ERROR: %s\n\n", explain.Error)
+			continue
+		}
 
 		queries := make([]outputQuery, 0, 4)
 		for tablet, actions := range explain.TabletActions {
@@ -408,3 +540,81 @@ func ExplainsAsJSON(explains []*Explain) string {
 	explainJSON, _ := jsonutil.MarshalIndentNoEscape(explains, "", "    ")
 	return string(explainJSON)
 }
+
+type (
+	// ExplainReport is the machine-readable report of how a statement is
+	// executed: the queries each shard runs and the transactions they run in
+	ExplainReport struct {
+		SQL    string
+		Error  string                  `json:",omitempty"`
+		Shards map[string]*ShardReport `json:",omitempty"`
+	}
+
+	// ShardReport contains the queries a shard runs in mysql for a statement
+	ShardReport struct {
+		Queries      []*MysqlQuery
+		Transactions []*TransactionReport `json:",omitempty"`
+	}
+
+	// TransactionReport describes the boundaries of a transaction on a shard,
+	// in logical time
+	TransactionReport struct {
+		Begin int
+		End   int `json:",omitempty"`
+
+		// Outcome is commit or rollback, or open if the transaction is still
+		// open at the end of the statement
+		Outcome string
+	}
+)
+
+// ExplainsAsJSONReport returns a report of the explains that lists, for every
+// shard, the queries it runs and its transaction boundaries. A transaction
+// that spans several statements is listed in the report of each of them.
+func (vte *VTExplain) ExplainsAsJSONReport(explains []*Explain) (string, error) {
+	reports := make([]*ExplainReport, 0, len(explains))
+	openTransactions := map[string]*TransactionReport{}
+	for _, explain := range explains {
+		report := &ExplainReport{SQL: explain.SQL, Error: explain.Error}
+		for tablet, actions := range explain.TabletActions {
+			if len(actions.MysqlQueries) == 0 {
+				continue
+			}
+			shard := &ShardReport{}
+			if tx := openTransactions[tablet]; tx != nil {
+				shard.Transactions = append(shard.Transactions, tx)
+			}
+			for _, q := range actions.MysqlQueries {
+				if err := vte.specialHandlingOfSavepoints(q); err != nil {
+					return "", err
+				}
+				shard.Queries = append(shard.Queries, q)
+
+				sql := strings.ToLower(strings.TrimSpace(q.SQL))
+				switch {
+				case sql == "begin" || strings.HasPrefix(sql, "start transaction"):
+					tx := &TransactionReport{Begin: q.Time, Outcome: "open"}
+					openTransactions[tablet] = tx
+					shard.Transactions = append(shard.Transactions, tx)
+				case sql == "commit" || sql == "rollback":
+					if tx := openTransactions[tablet]; tx != nil {
+						tx.End = q.Time
+						tx.Outcome = sql
+						delete(openTransactions, tablet)
+					}
+				}
+			}
+			if report.Shards == nil {
+				report.Shards = map[string]*ShardReport{}
+			}
+			report.Shards[tablet] = shard
+		}
+		reports = append(reports, report)
+	}
+
+	reportJSON, err := jsonutil.MarshalIndentNoEscape(reports, "", "    ")
+	if err != nil {
+		return "", err
+	}
+	return string(reportJSON), nil
+}
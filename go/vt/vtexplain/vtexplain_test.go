@@ -361,3 +361,138 @@ func TestInit(t *testing.T) {
 type vtexplainTestTopoVersion struct{}
 
 func (vtexplain *vtexplainTestTopoVersion) String() string { return "vtexplain-test-topo" }
+
+func TestRoutingRules(t *testing.T) {
+	vschema := `{
+  "keyspaces": {
+    "source": {
+      "sharded": false,
+      "tables": {
+        "t1": {}
+      }
+    },
+    "target": {
+      "sharded": false,
+      "tables": {
+        "t1": {}
+      }
+    }
+  },
+  "routing_rules": {
+    "rules": [{
+      "from_table": "t1",
+      "to_tables": ["target.t1"]
+    }]
+  }
+}`
+	schema := "create table t1 (id bigint primary key, val varchar(32))"
+	vte, err := Init(vschema, schema, "", defaultTestOpts())
+	require.NoError(t, err)
+	defer vte.Stop()
+
+	explains, err := vte.Run("select val from t1 where id = 1")
+	require.NoError(t, err)
+	require.Len(t, explains, 1)
+	require.Contains(t, explains[0].TabletActions, "target/-")
+	require.NotContains(t, explains[0].TabletActions, "source/-")
+}
+
+func TestKeyspaceSchemas(t *testing.T) {
+	vschema := `{
+  "ks1": {
+    "sharded": false,
+    "tables": {
+      "t1": {},
+      "common": {}
+    }
+  },
+  "ks2": {
+    "sharded": false,
+    "tables": {
+      "t1": {},
+      "common": {},
+      "only_ks1": {}
+    }
+  }
+}`
+	schema := `{
+  "": "create table common (id bigint primary key)",
+  "ks1": "create table t1 (id bigint primary key, a varchar(32)); create table only_ks1 (id bigint primary key)",
+  "ks2": "create table t1 (id bigint primary key, b varchar(32))"
+}`
+	vte, err := Init(vschema, schema, "", defaultTestOpts())
+	require.NoError(t, err)
+	defer vte.Stop()
+
+	_, err = vte.Run("select a from ks1.t1")
+	require.NoError(t, err)
+	_, err = vte.Run("select b from ks2.t1")
+	require.NoError(t, err)
+	_, err = vte.Run("select id from ks2.common")
+	require.NoError(t, err)
+
+	_, err = vte.Run("select id from ks2.only_ks1")
+	require.ErrorContains(t, err, "unable to resolve table name only_ks1")
+}
+
+func TestRunBatch(t *testing.T) {
+	vte := initTest(ModeMulti, defaultTestOpts(), &testopts{}, t)
+
+	explains, err := vte.RunBatch("select * from table_not_in_vschema; select 1 from user where id = 1")
+	require.NoError(t, err)
+	require.Len(t, explains, 2)
+	require.Contains(t, explains[0].Error, "table table_not_in_vschema not found")
+	require.Empty(t, explains[1].Error)
+	require.Contains(t, explains[1].TabletActions, "ks_sharded/-40")
+
+	text, err := vte.ExplainsAsText(explains)
+	require.NoError(t, err)
+	require.Contains(t, text, "ERROR: vtexplain execute error in 'select * from table_not_in_vschema'")
+}
+
+func TestJSONReport(t *testing.T) {
+	vte := initTest(ModeMulti, defaultTestOpts(), &testopts{}, t)
+
+	explains, err := vte.Run("begin; update user set nickname = 'x' where id = 1; commit")
+	require.NoError(t, err)
+
+	reportJSON, err := vte.ExplainsAsJSONReport(explains)
+	require.NoError(t, err)
+
+	var reports []*ExplainReport
+	require.NoError(t, json.Unmarshal([]byte(reportJSON), &reports))
+	require.Len(t, reports, 3)
+
+	shard := reports[1].Shards["ks_sharded/-40"]
+	require.NotNil(t, shard)
+	require.Len(t, shard.Transactions, 1)
+	require.Equal(t, &TransactionReport{Begin: 1, End: 2, Outcome: "commit"}, shard.Transactions[0])
+
+	shard = reports[2].Shards["ks_sharded/-40"]
+	require.NotNil(t, shard)
+	require.Len(t, shard.Transactions, 1)
+	require.Equal(t, &TransactionReport{Begin: 1, End: 2, Outcome: "commit"}, shard.Transactions[0])
+}
+
+func TestGetFileParam(t *testing.T) {
+	file := path.Join(t.TempDir(), "schema.sql")
+	require.NoError(t, os.WriteFile(file, []byte("create table t(id int)"), 0644))
+
+	v, err := GetFileParam("create table u(id int)", "", "schema", true)
+	require.NoError(t, err)
+	require.Equal(t, "create table u(id int)", v)
+
+	v, err = GetFileParam("", file, "schema", true)
+	require.NoError(t, err)
+	require.Equal(t, "create table t(id int)", v)
+
+	v, err = GetFileParam("", "", "ks-shard-map", false)
+	require.NoError(t, err)
+	require.Empty(t, v)
+
+	_, err = GetFileParam("", "", "schema", true)
+	require.EqualError(t, err, "action requires one of schema or schema-file")
+
+	_, err = GetFileParam("create table u(id int)", file, "schema", true)
+	require.EqualError(t, err, "action requires only one of schema or schema-file")
+}
@@ -33,6 +33,10 @@ type ExplainTopo struct {
 	// Map of keyspace name to vschema
 	Keyspaces map[string]*vschemapb.Keyspace
 
+	// Table and shard routing rules of the vschema
+	RoutingRules      *vschemapb.RoutingRules
+	ShardRoutingRules *vschemapb.ShardRoutingRules
+
 	// Map of ks/shard to test tablet connection
 	TabletConns map[string]*explainTablet
 
@@ -52,7 +56,9 @@ func (et *ExplainTopo) getSrvVSchema() *vschemapb.SrvVSchema {
 	defer et.Lock.Unlock()
 
 	return &vschemapb.SrvVSchema{
-		Keyspaces: et.Keyspaces,
+		Keyspaces:         et.Keyspaces,
+		RoutingRules:      et.RoutingRules,
+		ShardRoutingRules: et.ShardRoutingRules,
 	}
 }
 
@@ -21,6 +21,7 @@ package vtexplain
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"sort"
 	"strings"
@@ -100,21 +101,24 @@ func (vte *VTExplain) buildTopology(opts *Options, vschemaStr string, ksShardMap
 	vte.explainTopo.Lock.Lock()
 	defer vte.explainTopo.Lock.Unlock()
 
-	// We have to use proto's custom json loader so it can
-	// handle string->enum conversion correctly.
-	var srvVSchema vschemapb.SrvVSchema
-	wrappedStr := fmt.Sprintf(`{"keyspaces": %s}`, vschemaStr)
-	err := json2.Unmarshal([]byte(wrappedStr), &srvVSchema)
+	srvVSchema, err := parseSrvVSchema(vschemaStr)
 	if err != nil {
 		return err
 	}
-	schema := vindexes.BuildVSchema(&srvVSchema)
+	schema := vindexes.BuildVSchema(srvVSchema)
 	for ks, ksSchema := range schema.Keyspaces {
 		if ksSchema.Error != nil {
 			return vterrors.Wrapf(ksSchema.Error, "vschema failed to load on keyspace [%s]", ks)
 		}
 	}
+	for _, rule := range schema.RoutingRules {
+		if rule.Error != nil {
+			return vterrors.Wrapf(rule.Error, "vschema failed to load routing rules")
+		}
+	}
 	vte.explainTopo.Keyspaces = srvVSchema.Keyspaces
+	vte.explainTopo.RoutingRules = srvVSchema.RoutingRules
+	vte.explainTopo.ShardRoutingRules = srvVSchema.ShardRoutingRules
 
 	ksShardMap, err := getKeyspaceShardMap(ksShardMapStr)
 	if err != nil {
@@ -154,6 +158,41 @@ func (vte *VTExplain) buildTopology(opts *Options, vschemaStr string, ksShardMap
 	return err
 }
 
+// srvVSchemaFields are the top-level fields of a SrvVSchema in JSON
+var srvVSchemaFields = map[string]bool{
+	"keyspaces":           true,
+	"routing_rules":       true,
+	"shard_routing_rules": true,
+}
+
+// parseSrvVSchema parses either a map of keyspace names to their vschema, or
+// a full SrvVSchema, with the routing rules and the shard routing rules of
+// the keyspaces
+func parseSrvVSchema(vschemaStr string) (*vschemapb.SrvVSchema, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(vschemaStr), &fields); err != nil {
+		return nil, err
+	}
+	isSrvVSchema := len(fields) > 0
+	for field := range fields {
+		isSrvVSchema = isSrvVSchema && srvVSchemaFields[field]
+	}
+	if _, ok := fields["keyspaces"]; !ok {
+		isSrvVSchema = false
+	}
+	if !isSrvVSchema {
+		vschemaStr = fmt.Sprintf(`{"keyspaces": %s}`, vschemaStr)
+	}
+
+	// We have to use proto's custom json loader so it can
+	// handle string->enum conversion correctly.
+	var srvVSchema vschemapb.SrvVSchema
+	if err := json2.Unmarshal([]byte(vschemaStr), &srvVSchema); err != nil {
+		return nil, err
+	}
+	return &srvVSchema, nil
+}
+
 func getKeyspaceShardMap(ksShardMapStr string) (map[string]map[string]*topo.ShardInfo, error) {
 	if ksShardMapStr == "" {
 		return map[string]map[string]*topo.ShardInfo{}, nil
@@ -81,6 +81,21 @@ func (vte *VTExplain) getGlobalTabletEnv() *tabletEnv {
 	return vte.globalTabletEnv
 }
 
+func (vte *VTExplain) setKeyspaceTabletEnv(keyspace string, env *tabletEnv) {
+	if vte.keyspaceTabletEnvs == nil {
+		vte.keyspaceTabletEnvs = make(map[string]*tabletEnv)
+	}
+	vte.keyspaceTabletEnvs[keyspace] = env
+}
+
+// getTabletEnv returns the tablet environment of a keyspace
+func (vte *VTExplain) getTabletEnv(keyspace string) *tabletEnv {
+	if env, ok := vte.keyspaceTabletEnvs[keyspace]; ok {
+		return env
+	}
+	return vte.globalTabletEnv
+}
+
 // explainTablet is the query service that simulates a tablet.
 //
 // To avoid needing to boilerplate implement the unneeded portions of the
@@ -98,6 +113,7 @@ type explainTablet struct {
 	mysqlQueries  []*MysqlQuery
 	currentTime   int
 	vte           *VTExplain
+	keyspace      string
 }
 
 var _ queryservice.QueryService = (*explainTablet)(nil)
@@ -119,7 +135,7 @@ func (vte *VTExplain) newTablet(opts *Options, t *topodatapb.Tablet) *explainTab
 	// XXX much of this is cloned from the tabletserver tests
 	tsv := tabletserver.NewTabletServer(topoproto.TabletAliasString(t.Alias), config, memorytopo.NewServer(""), t.Alias)
 
-	tablet := explainTablet{db: db, tsv: tsv, vte: vte}
+	tablet := explainTablet{db: db, tsv: tsv, vte: vte, keyspace: t.Keyspace}
 	db.Handler = &tablet
 
 	tablet.QueryService = queryservice.Wrap(
@@ -528,7 +544,7 @@ func (t *explainTablet) HandleQuery(c *mysql.Conn, query string, callback func(*
 	}
 
 	// return the pre-computed results for any schema introspection queries
-	tEnv := t.vte.getGlobalTabletEnv()
+	tEnv := t.vte.getTabletEnv(t.keyspace)
 	result := tEnv.getResult(query)
 
 	if result != nil {
@@ -591,7 +607,7 @@ func (t *explainTablet) handleSelect(query string) (*sqltypes.Result, error) {
 		}
 
 		tableName := sqlparser.String(sqlparser.GetTableName(table.Expr))
-		columns, exists := t.vte.getGlobalTabletEnv().tableColumns[tableName]
+		columns, exists := t.vte.getTabletEnv(t.keyspace).tableColumns[tableName]
 		if !exists && tableName != "" && tableName != "dual" {
 			return nil, fmt.Errorf("unable to resolve table name %s", tableName)
 		}
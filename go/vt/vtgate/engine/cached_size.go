@@ -338,7 +338,7 @@ func (cached *Insert) CachedSize(alloc bool) int64 {
 	}
 	size := int64(0)
 	if alloc {
-		size += int64(224)
+		size += int64(256)
 	}
 	// field Keyspace *vitess.io/vitess/go/vt/vtgate/vindexes.Keyspace
 	size += cached.Keyspace.CachedSize(true)
@@ -398,6 +398,30 @@ func (cached *Insert) CachedSize(alloc bool) int64 {
 	if cc, ok := cached.Input.(cachedObject); ok {
 		size += cc.CachedSize(true)
 	}
+	// field TenantValues []vitess.io/vitess/go/vt/vtgate/evalengine.Expr
+	{
+		size += hack.RuntimeAllocSize(int64(cap(cached.TenantValues)) * int64(16))
+		for _, elem := range cached.TenantValues {
+			if cc, ok := elem.(cachedObject); ok {
+				size += cc.CachedSize(true)
+			}
+		}
+	}
+	// field TenantRoutes map[string]*vitess.io/vitess/go/vt/vtgate/engine.TenantRoute
+	if cached.TenantRoutes != nil {
+		size += int64(48)
+		hmap := reflect.ValueOf(cached.TenantRoutes)
+		numBuckets := int(math.Pow(2, float64((*(*uint8)(unsafe.Pointer(hmap.Pointer() + uintptr(9)))))))
+		numOldBuckets := (*(*uint16)(unsafe.Pointer(hmap.Pointer() + uintptr(10))))
+		size += hack.RuntimeAllocSize(int64(numOldBuckets * 208))
+		if len(cached.TenantRoutes) > 0 || numBuckets > 1 {
+			size += hack.RuntimeAllocSize(int64(numBuckets * 208))
+		}
+		for k, v := range cached.TenantRoutes {
+			size += hack.RuntimeAllocSize(int64(len(k)))
+			size += v.CachedSize(true)
+		}
+	}
 	return size
 }
 
@@ -802,7 +826,7 @@ func (cached *RoutingParameters) CachedSize(alloc bool) int64 {
 	}
 	size := int64(0)
 	if alloc {
-		size += int64(112)
+		size += int64(136)
 	}
 	// field Keyspace *vitess.io/vitess/go/vt/vtgate/vindexes.Keyspace
 	size += cached.Keyspace.CachedSize(true)
@@ -849,6 +873,25 @@ func (cached *RoutingParameters) CachedSize(alloc bool) int64 {
 			}
 		}
 	}
+	// field TenantValue vitess.io/vitess/go/vt/vtgate/evalengine.Expr
+	if cc, ok := cached.TenantValue.(cachedObject); ok {
+		size += cc.CachedSize(true)
+	}
+	// field TenantRoutes map[string]*vitess.io/vitess/go/vt/vtgate/engine.TenantRoute
+	if cached.TenantRoutes != nil {
+		size += int64(48)
+		hmap := reflect.ValueOf(cached.TenantRoutes)
+		numBuckets := int(math.Pow(2, float64((*(*uint8)(unsafe.Pointer(hmap.Pointer() + uintptr(9)))))))
+		numOldBuckets := (*(*uint16)(unsafe.Pointer(hmap.Pointer() + uintptr(10))))
+		size += hack.RuntimeAllocSize(int64(numOldBuckets * 208))
+		if len(cached.TenantRoutes) > 0 || numBuckets > 1 {
+			size += hack.RuntimeAllocSize(int64(numBuckets * 208))
+		}
+		for k, v := range cached.TenantRoutes {
+			size += hack.RuntimeAllocSize(int64(len(k)))
+			size += v.CachedSize(true)
+		}
+	}
 	return size
 }
 func (cached *Rows) CachedSize(alloc bool) int64 {
@@ -1125,6 +1168,26 @@ func (cached *SysVarSetAware) CachedSize(alloc bool) int64 {
 }
 
 //go:nocheckptr
+func (cached *TenantRoute) CachedSize(alloc bool) int64 {
+	if cached == nil {
+		return int64(0)
+	}
+	size := int64(0)
+	if alloc {
+		size += int64(48)
+	}
+	// field Keyspace *vitess.io/vitess/go/vt/vtgate/vindexes.Keyspace
+	size += cached.Keyspace.CachedSize(true)
+	// field Vindex vitess.io/vitess/go/vt/vtgate/vindexes.SingleColumn
+	if cc, ok := cached.Vindex.(cachedObject); ok {
+		size += cc.CachedSize(true)
+	}
+	// field Value vitess.io/vitess/go/vt/vtgate/evalengine.Expr
+	if cc, ok := cached.Value.(cachedObject); ok {
+		size += cc.CachedSize(true)
+	}
+	return size
+}
 func (cached *Update) CachedSize(alloc bool) int64 {
 	if cached == nil {
 		return int64(0)
@@ -55,6 +55,15 @@ func printMismatch(leftResult, rightResult *sqltypes.Result, leftPrimitive, righ
 	log.Error("End of diff.")
 }
 
+// CompareResults returns true if the two results of the same query match.
+// The order of the rows only matters if the query has an ORDER BY clause.
+func CompareResults(leftResult, rightResult *sqltypes.Result, ordered bool) bool {
+	if ordered {
+		return sqltypes.ResultsEqual([]sqltypes.Result{*leftResult}, []sqltypes.Result{*rightResult})
+	}
+	return sqltypes.ResultsEqualUnordered([]sqltypes.Result{*leftResult}, []sqltypes.Result{*rightResult})
+}
+
 // CompareErrors compares the two errors, and if they don't match, produces an error
 func CompareErrors(leftErr, rightErr error, leftName, rightName string) error {
 	if leftErr != nil && rightErr != nil {
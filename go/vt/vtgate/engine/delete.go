@@ -163,4 +163,7 @@ func addFieldsIfNotEmpty(dml *DML, other map[string]any) {
 		}
 		other["Values"] = s
 	}
+	if dml.TenantValue != nil {
+		other["TenantValue"] = evalengine.FormatExpr(dml.TenantValue)
+	}
 }
@@ -95,6 +95,39 @@ func TestDeleteEqual(t *testing.T) {
 	require.EqualError(t, err, "query arguments missing for aa")
 }
 
+func TestDeleteTenantWritesDenied(t *testing.T) {
+	del := &Delete{
+		DML: &DML{
+			RoutingParameters: &RoutingParameters{
+				Opcode: Scatter,
+				Keyspace: &vindexes.Keyspace{
+					Name:    "ks",
+					Sharded: true,
+				},
+				TenantValue: evalengine.NewLiteralInt(1),
+				TenantRoutes: map[string]*TenantRoute{
+					"1": {WritesDenied: true},
+				},
+			},
+			Query: "dummy_delete",
+		},
+	}
+
+	vc := newDMLTestVCursor("-20", "20-")
+	_, err := del.TryExecute(context.Background(), vc, map[string]*querypb.BindVariable{}, false)
+	require.EqualError(t, err, "writes of tenant 1 are denied while its traffic is switched")
+	vc.ExpectLog(t, nil)
+
+	// The writes of the other tenants go through.
+	del.TenantValue = evalengine.NewLiteralInt(2)
+	_, err = del.TryExecute(context.Background(), vc, map[string]*querypb.BindVariable{}, false)
+	require.NoError(t, err)
+	vc.ExpectLog(t, []string{
+		`ResolveDestinations ks [] Destinations:DestinationAllShards()`,
+		`ExecuteMultiShard ks.-20: dummy_delete {} ks.20-: dummy_delete {} true false`,
+	})
+}
+
 func TestDeleteEqualMultiCol(t *testing.T) {
 	vindex, _ := vindexes.NewRegionExperimental("", map[string]string{"region_bytes": "1"})
 	del := &Delete{
@@ -752,6 +752,9 @@ func (t *noopVCursor) DisableLogging()  {}
 func (t *noopVCursor) GetVExplainLogs() []ExecuteEntry {
 	return nil
 }
+func (t *noopVCursor) GetVExplainStats() map[Primitive]PrimitiveStats {
+	return nil
+}
 func (t *noopVCursor) GetLogs() ([]ExecuteEntry, error) {
 	return nil, nil
 }
@@ -60,22 +60,10 @@ func (f *Filter) TryExecute(ctx context.Context, vcursor VCursor, bindVars map[s
 		return nil, err
 	}
 	env := evalengine.NewExpressionEnv(ctx, bindVars, vcursor)
-	var rows [][]sqltypes.Value
-	for _, row := range result.Rows {
-		env.Row = row
-		evalResult, err := env.Evaluate(f.Predicate)
-		if err != nil {
-			return nil, err
-		}
-		intEvalResult, err := evalResult.Value().ToInt64()
-		if err != nil {
-			return nil, err
-		}
-		if intEvalResult == 1 {
-			rows = append(rows, row)
-		}
+	result.Rows, err = env.FilterBatch(f.Predicate, result.Rows)
+	if err != nil {
+		return nil, err
 	}
-	result.Rows = rows
 	return result, nil
 }
 
@@ -83,22 +71,11 @@ func (f *Filter) TryExecute(ctx context.Context, vcursor VCursor, bindVars map[s
 func (f *Filter) TryStreamExecute(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable, wantfields bool, callback func(*sqltypes.Result) error) error {
 	env := evalengine.NewExpressionEnv(ctx, bindVars, vcursor)
 	filter := func(results *sqltypes.Result) error {
-		var rows [][]sqltypes.Value
-		for _, row := range results.Rows {
-			env.Row = row
-			evalResult, err := env.Evaluate(f.Predicate)
-			if err != nil {
-				return err
-			}
-			intEvalResult, err := evalResult.Value().ToInt64()
-			if err != nil {
-				return err
-			}
-			if intEvalResult == 1 {
-				rows = append(rows, row)
-			}
+		var err error
+		results.Rows, err = env.FilterBatch(f.Predicate, results.Rows)
+		if err != nil {
+			return err
 		}
-		results.Rows = rows
 		return callback(results)
 	}
 
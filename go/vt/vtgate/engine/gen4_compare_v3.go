@@ -120,13 +120,7 @@ func (gc *Gen4CompareV3) TryStreamExecute(ctx context.Context, vcursor VCursor,
 }
 
 func (gc *Gen4CompareV3) compareResults(v3Result *sqltypes.Result, gen4Result *sqltypes.Result) error {
-	var match bool
-	if gc.HasOrderBy {
-		match = sqltypes.ResultsEqual([]sqltypes.Result{*v3Result}, []sqltypes.Result{*gen4Result})
-	} else {
-		match = sqltypes.ResultsEqualUnordered([]sqltypes.Result{*v3Result}, []sqltypes.Result{*gen4Result})
-	}
-	if !match {
+	if !CompareResults(v3Result, gen4Result, gc.HasOrderBy) {
 		printMismatch(v3Result, gen4Result, gc.V3, gc.Gen4, "V3", "Gen4")
 		return vterrors.Errorf(vtrpcpb.Code_INTERNAL, "results did not match, see VTGate's logs for more information")
 	}
@@ -101,6 +101,13 @@ type (
 		// This will avoid locking by the select table.
 		ForceNonStreaming bool
 
+		// TenantValues are the tenants of the inserted rows, when tenants
+		// have been moved out of Keyspace or are being moved. The rows of the
+		// tenants in TenantRoutes are rejected: they would be written to
+		// Keyspace instead of the keyspace of their tenant.
+		TenantValues []evalengine.Expr
+		TenantRoutes map[string]*TenantRoute
+
 		// Insert needs tx handling
 		txNeeded
 	}
@@ -228,6 +235,10 @@ func (ins *Insert) TryExecute(ctx context.Context, vcursor VCursor, bindVars map
 	ctx, cancelFunc := addQueryTimeout(ctx, vcursor, ins.QueryTimeout)
 	defer cancelFunc()
 
+	if err := ins.checkTenants(ctx, vcursor, bindVars); err != nil {
+		return nil, err
+	}
+
 	switch ins.Opcode {
 	case InsertUnsharded:
 		return ins.execInsertUnsharded(ctx, vcursor, bindVars)
@@ -296,6 +307,34 @@ func (ins *Insert) TryStreamExecute(ctx context.Context, vcursor VCursor, bindVa
 	return callback(output)
 }
 
+// checkTenants returns an error if one of the rows belongs to a tenant that
+// has been moved to another keyspace, or whose writes are denied.
+func (ins *Insert) checkTenants(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable) error {
+	if len(ins.TenantValues) == 0 {
+		return nil
+	}
+	env := evalengine.NewExpressionEnv(ctx, bindVars, vcursor)
+	for _, expr := range ins.TenantValues {
+		value, err := env.Evaluate(expr)
+		if err != nil {
+			return err
+		}
+		if value.Value().IsNull() {
+			continue
+		}
+		tenant := value.Value().ToString()
+		route, ok := ins.TenantRoutes[tenant]
+		switch {
+		case !ok:
+		case route.WritesDenied:
+			return vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "writes of tenant %s are denied while its traffic is switched", tenant)
+		case route.Keyspace != nil:
+			return vterrors.VT12001(fmt.Sprintf("INSERT of a row of tenant %s, which has been moved to keyspace %s", tenant, route.Keyspace.Name))
+		}
+	}
+	return nil
+}
+
 func (ins *Insert) insertIntoShardedTable(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable, result *sqltypes.Result) (int64, *sqltypes.Result, error) {
 	insertID, err := ins.processGenerateFromRows(ctx, vcursor, result.Rows)
 	if err != nil {
@@ -994,6 +1033,13 @@ func (ins *Insert) description() PrimitiveDescription {
 		}
 		other["VindexOffsetFromSelect"] = valuesOffsets
 	}
+	if len(ins.TenantValues) > 0 {
+		var tenants []string
+		for _, expr := range ins.TenantValues {
+			tenants = append(tenants, evalengine.FormatExpr(expr))
+		}
+		other["TenantValues"] = strings.Join(tenants, ", ")
+	}
 	if len(ins.Mid) > 0 {
 		shardQuery := fmt.Sprintf("%s%s%s", ins.Prefix, strings.Join(ins.Mid, ", "), ins.Suffix)
 		if shardQuery != ins.Query {
@@ -69,6 +69,47 @@ func TestInsertUnsharded(t *testing.T) {
 	require.EqualError(t, err, `Keyspace does not have exactly one shard: []`)
 }
 
+func TestInsertTenantRows(t *testing.T) {
+	ins := NewQueryInsert(
+		InsertUnsharded,
+		&vindexes.Keyspace{
+			Name:    "ks",
+			Sharded: false,
+		},
+		"dummy_insert",
+	)
+	ins.TenantValues = []evalengine.Expr{
+		evalengine.NewLiteralInt(1),
+		evalengine.NewBindVar("tenant"),
+	}
+	ins.TenantRoutes = map[string]*TenantRoute{
+		"2": {
+			Keyspace: &vindexes.Keyspace{
+				Name: "ks2",
+			},
+		},
+		"3": {WritesDenied: true},
+	}
+
+	vc := newDMLTestVCursor("0")
+	vc.results = []*sqltypes.Result{{
+		InsertID: 4,
+	}}
+	_, err := ins.TryExecute(context.Background(), vc, map[string]*querypb.BindVariable{"tenant": sqltypes.Int64BindVariable(2)}, false)
+	require.EqualError(t, err, "VT12001: unsupported: INSERT of a row of tenant 2, which has been moved to keyspace ks2")
+	_, err = ins.TryExecute(context.Background(), vc, map[string]*querypb.BindVariable{"tenant": sqltypes.Int64BindVariable(3)}, false)
+	require.EqualError(t, err, "writes of tenant 3 are denied while its traffic is switched")
+	vc.ExpectLog(t, nil)
+
+	result, err := ins.TryExecute(context.Background(), vc, map[string]*querypb.BindVariable{"tenant": sqltypes.Int64BindVariable(4)}, false)
+	require.NoError(t, err)
+	vc.ExpectLog(t, []string{
+		`ResolveDestinations ks [] Destinations:DestinationAllShards()`,
+		`ExecuteMultiShard ks.0: dummy_insert {tenant: type:INT64 value:"4"} true true`,
+	})
+	expectResult(t, "Execute", result, &sqltypes.Result{InsertID: 4})
+}
+
 func TestInsertUnshardedGenerate(t *testing.T) {
 	ins := NewQueryInsert(
 		InsertUnsharded,
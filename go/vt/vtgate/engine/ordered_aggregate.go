@@ -188,7 +188,9 @@ func (oa *OrderedAggregate) execute(ctx context.Context, vcursor VCursor, bindVa
 	// This code is similar to the one in StreamExecute.
 	var current []sqltypes.Value
 	var curDistincts []sqltypes.Value
-	for _, row := range result.Rows {
+	// the rows of the current group that have not been merged yet
+	pending := 0
+	for n, row := range result.Rows {
 		if current == nil {
 			current, curDistincts = convertRow(row, oa.PreProcess, oa.Aggregates, oa.AggrOnEngine)
 			continue
@@ -199,15 +201,21 @@ func (oa *OrderedAggregate) execute(ctx context.Context, vcursor VCursor, bindVa
 		}
 
 		if equal {
-			current, curDistincts, err = merge(result.Fields, current, row, curDistincts, oa.Collations, oa.Aggregates)
-			if err != nil {
-				return nil, err
-			}
+			pending++
 			continue
 		}
+		current, curDistincts, err = mergeBatch(result.Fields, current, result.Rows[n-pending:n], curDistincts, oa.Collations, oa.Aggregates)
+		if err != nil {
+			return nil, err
+		}
+		pending = 0
 		out.Rows = append(out.Rows, current)
 		current, curDistincts = convertRow(row, oa.PreProcess, oa.Aggregates, oa.AggrOnEngine)
 	}
+	current, _, err = mergeBatch(result.Fields, current, result.Rows[len(result.Rows)-pending:], curDistincts, oa.Collations, oa.Aggregates)
+	if err != nil {
+		return nil, err
+	}
 
 	if current != nil {
 		final, err := convertFinal(current, oa.Aggregates)
@@ -237,7 +245,8 @@ func (oa *OrderedAggregate) TryStreamExecute(ctx context.Context, vcursor VCurso
 			}
 		}
 		// This code is similar to the one in Execute.
-		for _, row := range qr.Rows {
+		pending := 0
+		for n, row := range qr.Rows {
 			if current == nil {
 				current, curDistincts = convertRow(row, oa.PreProcess, oa.Aggregates, oa.AggrOnEngine)
 				continue
@@ -249,18 +258,22 @@ func (oa *OrderedAggregate) TryStreamExecute(ctx context.Context, vcursor VCurso
 			}
 
 			if equal {
-				current, curDistincts, err = merge(fields, current, row, curDistincts, oa.Collations, oa.Aggregates)
-				if err != nil {
-					return err
-				}
+				pending++
 				continue
 			}
+			current, curDistincts, err = mergeBatch(fields, current, qr.Rows[n-pending:n], curDistincts, oa.Collations, oa.Aggregates)
+			if err != nil {
+				return err
+			}
+			pending = 0
 			if err := cb(&sqltypes.Result{Rows: [][]sqltypes.Value{current}}); err != nil {
 				return err
 			}
 			current, curDistincts = convertRow(row, oa.PreProcess, oa.Aggregates, oa.AggrOnEngine)
 		}
-		return nil
+		var err error
+		current, curDistincts, err = mergeBatch(fields, current, qr.Rows[len(qr.Rows)-pending:], curDistincts, oa.Collations, oa.Aggregates)
+		return err
 	})
 	if err != nil {
 		return err
@@ -481,6 +494,74 @@ func merge(
 	return result, curDistincts, nil
 }
 
+// mergeBatch merges all the given rows into row1, like calling merge once per row.
+// Aggregations that don't depend on the order of the rows are computed one column
+// at a time over all the rows, instead of copying the aggregated row for each one.
+func mergeBatch(
+	fields []*querypb.Field,
+	row1 []sqltypes.Value,
+	rows [][]sqltypes.Value,
+	curDistincts []sqltypes.Value,
+	colls map[int]collations.ID,
+	aggregates []*AggregateParams,
+) ([]sqltypes.Value, []sqltypes.Value, error) {
+	if len(rows) == 0 {
+		return row1, curDistincts, nil
+	}
+	for _, aggr := range aggregates {
+		switch aggr.Opcode {
+		case AggregateCountStar, AggregateCount, AggregateSum, AggregateMin, AggregateMax, AggregateRandom:
+		default:
+			// distinct aggregations depend on the previous row
+			var err error
+			for _, row := range rows {
+				row1, curDistincts, err = merge(fields, row1, row, curDistincts, colls, aggregates)
+				if err != nil {
+					return nil, nil, err
+				}
+			}
+			return row1, curDistincts, nil
+		}
+	}
+
+	result := sqltypes.CopyRow(row1)
+	for _, aggr := range aggregates {
+		var err error
+		switch aggr.Opcode {
+		case AggregateCountStar:
+			result[aggr.Col], err = evalengine.NullSafeAdd(result[aggr.Col], sqltypes.NewInt64(int64(len(rows))), fields[aggr.Col].Type)
+		case AggregateCount:
+			var count int64
+			for _, row := range rows {
+				if !row[aggr.Col].IsNull() {
+					count++
+				}
+			}
+			result[aggr.Col], err = evalengine.NullSafeAdd(result[aggr.Col], sqltypes.NewInt64(count), fields[aggr.Col].Type)
+		case AggregateSum:
+			result[aggr.Col], err = evalengine.AggregateSum(result[aggr.Col], rows, aggr.Col, fields[aggr.Col].Type)
+		case AggregateMin:
+			for _, row := range rows {
+				if result[aggr.Col], err = evalengine.Min(result[aggr.Col], row[aggr.Col], colls[aggr.Col]); err != nil {
+					break
+				}
+			}
+		case AggregateMax:
+			for _, row := range rows {
+				if result[aggr.Col], err = evalengine.Max(result[aggr.Col], row[aggr.Col], colls[aggr.Col]); err != nil {
+					break
+				}
+			}
+		case AggregateRandom:
+			// we just grab the first value per grouping. no need to do anything more complicated here
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	return result, curDistincts, nil
+}
+
 func aggregateParamsToString(in any) string {
 	return in.(*AggregateParams).String()
 }
@@ -1124,3 +1124,45 @@ func TestOrderedAggregateCollateKS(t *testing.T) {
 	)
 	assert.Equal(wantResult, result)
 }
+
+func TestMergeBatch(t *testing.T) {
+	fields := sqltypes.MakeTestFields(
+		"count(*)|count(col)|sum(a)|sum(b)|min(c)|max(c)|random",
+		"int64|int64|decimal|float64|varchar|varchar|varchar",
+	)
+	result := sqltypes.MakeTestResult(
+		fields,
+		"1|1|1.5|0.1|b|b|x",
+		"1|0|null|0.2|a|null|y",
+		"1|1|2|null|null|c|z",
+		"1|null|null|null|null|null|null",
+		"1|1|-10.25|1e20|B|a|y",
+	)
+	colls := map[int]collations.ID{
+		4: collationEnv.LookupByName("utf8mb4_general_ci").ID(),
+		5: collationEnv.LookupByName("utf8mb4_general_ci").ID(),
+	}
+	aggregates := []*AggregateParams{
+		{Opcode: AggregateCountStar, Col: 0},
+		{Opcode: AggregateCount, Col: 1},
+		{Opcode: AggregateSum, Col: 2},
+		{Opcode: AggregateSum, Col: 3},
+		{Opcode: AggregateMin, Col: 4},
+		{Opcode: AggregateMax, Col: 5},
+		{Opcode: AggregateRandom, Col: 6},
+	}
+
+	want := result.Rows[0]
+	for _, row := range result.Rows[1:] {
+		var err error
+		want, _, err = merge(fields, want, row, nil, colls, aggregates)
+		require.NoError(t, err)
+	}
+
+	got, _, err := mergeBatch(fields, result.Rows[0], result.Rows[1:], nil, colls, aggregates)
+	require.NoError(t, err)
+	assert.Equal(t, fmt.Sprintf("%v", want), fmt.Sprintf("%v", got))
+	assert.Equal(t, `[INT64(5) INT64(4) DECIMAL(-6.75) FLOAT64(1e20) VARCHAR("a") VARCHAR("c") VARCHAR("x")]`, fmt.Sprintf("%v", got))
+	// the first row is not modified
+	assert.Equal(t, "INT64(1)", result.Rows[0][0].String())
+}
@@ -41,7 +41,33 @@ type PrimitiveDescription struct {
 	// this is only used in conjunction with TargetDestination
 	TargetTabletType topodatapb.TabletType
 	Other            map[string]any
-	Inputs           []PrimitiveDescription
+	// Stats holds the runtime statistics of the primitive, and is only set by VEXPLAIN FORMAT=JSON
+	Stats  *RuntimeStats
+	Inputs []PrimitiveDescription
+}
+
+// RuntimeStats is the serializable representation of the runtime statistics
+// that VEXPLAIN FORMAT=JSON reports for each primitive
+type RuntimeStats struct {
+	// Calls is the number of times the primitive was executed
+	Calls int
+	// Rows is the total number of rows produced by the primitive
+	Rows int
+	// WallTimeMicros is the total time spent executing the primitive and its inputs
+	WallTimeMicros int64
+	// ShardsQueried is the number of shards the primitive sent queries to
+	ShardsQueried int
+	// Shards has the statistics of the queries sent to each shard
+	Shards []ShardRuntimeStats `json:",omitempty"`
+}
+
+// ShardRuntimeStats are the statistics of the queries that a primitive sent to a shard
+type ShardRuntimeStats struct {
+	Keyspace      string
+	Shard         string
+	Queries       int
+	RowsReceived  int
+	BytesReceived int
 }
 
 // MarshalJSON serializes the PlanDescription into a JSON representation.
@@ -82,6 +108,12 @@ func (pd PrimitiveDescription) MarshalJSON() ([]byte, error) {
 		return nil, err
 	}
 
+	if pd.Stats != nil {
+		if err := marshalAdd(",", buf, "Stats", pd.Stats); err != nil {
+			return nil, err
+		}
+	}
+
 	if len(pd.Inputs) > 0 {
 		if err := marshalAdd(",", buf, "Inputs", pd.Inputs); err != nil {
 			return nil, err
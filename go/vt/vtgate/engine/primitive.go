@@ -180,12 +180,16 @@ type (
 		RemoveAdvisoryLock(name string)
 
 		// VExplainLogging enables logging of all interactions to the tablets so
-		// VEXPLAIN QUERIES/ALL can report what's being done
+		// VEXPLAIN QUERIES/ALL/ANALYZE can report what's being done
 		VExplainLogging()
 
 		// GetVExplainLogs retrieves the vttablet interaction logs
 		GetVExplainLogs() []ExecuteEntry
 
+		// GetVExplainStats retrieves the runtime statistics of the primitives
+		// executed since VExplainLogging was enabled
+		GetVExplainStats() map[Primitive]PrimitiveStats
+
 		// SetCommitOrder sets the commit order for the shard session in respect of the type of vindex lookup.
 		// This is used to select the right shard session to perform the vindex lookup query.
 		SetCommitOrder(co vtgatepb.CommitOrder)
@@ -58,18 +58,9 @@ func (p *Projection) TryExecute(ctx context.Context, vcursor VCursor, bindVars m
 	}
 
 	env := evalengine.NewExpressionEnv(ctx, bindVars, vcursor)
-	var resultRows []sqltypes.Row
-	for _, row := range result.Rows {
-		resultRow := make(sqltypes.Row, 0, len(p.Exprs))
-		env.Row = row
-		for _, exp := range p.Exprs {
-			result, err := env.Evaluate(exp)
-			if err != nil {
-				return nil, err
-			}
-			resultRow = append(resultRow, result.Value())
-		}
-		resultRows = append(resultRows, resultRow)
+	resultRows, err := p.evalRows(env, result.Rows)
+	if err != nil {
+		return nil, err
 	}
 	if wantfields {
 		result.Fields, err = p.evalFields(env, result.Fields)
@@ -104,20 +95,10 @@ func (p *Projection) TryStreamExecute(ctx context.Context, vcursor VCursor, bind
 		if err != nil {
 			return err
 		}
-		resultRows := make([]sqltypes.Row, 0, len(qr.Rows))
-		for _, r := range qr.Rows {
-			resultRow := make(sqltypes.Row, 0, len(p.Exprs))
-			env.Row = r
-			for _, exp := range p.Exprs {
-				c, err := env.Evaluate(exp)
-				if err != nil {
-					return err
-				}
-				resultRow = append(resultRow, c.Value())
-			}
-			resultRows = append(resultRows, resultRow)
+		qr.Rows, err = p.evalRows(env, qr.Rows)
+		if err != nil {
+			return err
 		}
-		qr.Rows = resultRows
 		return callback(qr)
 	})
 }
@@ -136,6 +117,33 @@ func (p *Projection) GetFields(ctx context.Context, vcursor VCursor, bindVars ma
 	return qr, nil
 }
 
+// evalRows evaluates the projected expressions for all the given rows. The
+// expressions are evaluated one at a time over the whole set of rows, so that
+// compiled expressions can be evaluated in batches.
+func (p *Projection) evalRows(env *evalengine.ExpressionEnv, rows []sqltypes.Row) ([]sqltypes.Row, error) {
+	if len(rows) == 0 {
+		return nil, nil
+	}
+	resultRows := make([]sqltypes.Row, len(rows))
+	values := make([]sqltypes.Value, len(rows)*len(p.Exprs))
+	for i := range resultRows {
+		resultRows[i] = values[i*len(p.Exprs) : (i+1)*len(p.Exprs) : (i+1)*len(p.Exprs)]
+	}
+
+	column := make([]sqltypes.Value, 0, len(rows))
+	for col, exp := range p.Exprs {
+		var err error
+		column, err = env.EvaluateBatch(exp, rows, column[:0])
+		if err != nil {
+			return nil, err
+		}
+		for i, v := range column {
+			resultRows[i][col] = v
+		}
+	}
+	return resultRows, nil
+}
+
 func (p *Projection) evalFields(env *evalengine.ExpressionEnv, infields []*querypb.Field) ([]*querypb.Field, error) {
 	var fields []*querypb.Field
 	for i, col := range p.Cols {
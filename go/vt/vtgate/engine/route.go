@@ -502,6 +502,9 @@ func (route *Route) description() PrimitiveDescription {
 	if route.QueryTimeout > 0 {
 		other["QueryTimeout"] = route.QueryTimeout
 	}
+	if route.TenantValue != nil {
+		other["TenantValue"] = evalengine.FormatExpr(route.TenantValue)
+	}
 	return PrimitiveDescription{
 		OperatorType:      "Route",
 		Variant:           route.Opcode.String(),
@@ -273,6 +273,69 @@ func TestSelectEqualUnique(t *testing.T) {
 	expectResult(t, "sel.StreamExecute", result, defaultSelectResult)
 }
 
+func TestSelectTenantRoute(t *testing.T) {
+	vindex, _ := vindexes.NewHash("", nil)
+	sel := NewRoute(
+		Scatter,
+		&vindexes.Keyspace{
+			Name:    "ks",
+			Sharded: true,
+		},
+		"dummy_select",
+		"dummy_select_field",
+	)
+	sel.TenantValue = evalengine.NewBindVar("tenant")
+	sel.TenantRoutes = map[string]*TenantRoute{
+		"1": {
+			Keyspace: &vindexes.Keyspace{
+				Name:    "ks2",
+				Sharded: true,
+			},
+			Vindex: vindex.(vindexes.SingleColumn),
+			Value:  evalengine.NewBindVar("tenant"),
+		},
+		"2": {
+			Keyspace: &vindexes.Keyspace{
+				Name: "ks3",
+			},
+		},
+	}
+	vc := &loggingVCursor{
+		shards:  []string{"-20", "20-"},
+		results: []*sqltypes.Result{defaultSelectResult},
+	}
+
+	// The moved tenant is routed through the vindex of its new keyspace.
+	bv := map[string]*querypb.BindVariable{"tenant": sqltypes.Int64BindVariable(1)}
+	result, err := sel.TryExecute(context.Background(), vc, bv, false)
+	require.NoError(t, err)
+	vc.ExpectLog(t, []string{
+		`ResolveDestinations ks2 [type:INT64 value:"1"] Destinations:DestinationKeyspaceID(166b40b44aba4bd6)`,
+		`ExecuteMultiShard ks2.-20: dummy_select {tenant: type:INT64 value:"1"} false false`,
+	})
+	expectResult(t, "sel.Execute", result, defaultSelectResult)
+
+	// Without a vindex, it is sent to all the shards of its new keyspace.
+	vc.Rewind()
+	bv = map[string]*querypb.BindVariable{"tenant": sqltypes.Int64BindVariable(2)}
+	_, err = sel.TryExecute(context.Background(), vc, bv, false)
+	require.NoError(t, err)
+	vc.ExpectLog(t, []string{
+		`ResolveDestinations ks3 [] Destinations:DestinationAllShards()`,
+		`ExecuteMultiShard ks3.-20: dummy_select {tenant: type:INT64 value:"2"} ks3.20-: dummy_select {tenant: type:INT64 value:"2"} false false`,
+	})
+
+	// The other tenants stay in the keyspace of the route.
+	vc.Rewind()
+	bv = map[string]*querypb.BindVariable{"tenant": sqltypes.Int64BindVariable(3)}
+	_, err = sel.TryExecute(context.Background(), vc, bv, false)
+	require.NoError(t, err)
+	vc.ExpectLog(t, []string{
+		`ResolveDestinations ks [] Destinations:DestinationAllShards()`,
+		`ExecuteMultiShard ks.-20: dummy_select {tenant: type:INT64 value:"3"} ks.20-: dummy_select {tenant: type:INT64 value:"3"} false false`,
+	})
+}
+
 func TestSelectNone(t *testing.T) {
 	vindex, _ := vindexes.NewHash("", nil)
 	sel := NewRoute(
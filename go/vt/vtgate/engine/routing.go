@@ -119,6 +119,30 @@ type RoutingParameters struct {
 
 	// Values specifies the vindex values to use for routing.
 	Values []evalengine.Expr
+
+	// TenantValue is the tenant the query is restricted to, when tenants have
+	// been moved out of Keyspace. TenantRoutes maps those tenants to the route
+	// of the query in their new keyspace.
+	TenantValue  evalengine.Expr
+	TenantRoutes map[string]*TenantRoute
+}
+
+// TenantRoute is the route of the queries of a tenant that has been moved to
+// another keyspace, or whose writes are denied while it is being moved.
+type TenantRoute struct {
+	// Keyspace is the keyspace the tenant has been moved to, nil if it has not
+	// been switched yet.
+	Keyspace *vindexes.Keyspace
+
+	// Vindex is the primary vindex of the table in Keyspace, and Value the
+	// value of its column in the query. The query is sent to all the shards of
+	// Keyspace when Vindex is nil.
+	Vindex vindexes.SingleColumn
+	Value  evalengine.Expr
+
+	// WritesDenied is set for the DMLs of a tenant whose traffic is being
+	// switched.
+	WritesDenied bool
 }
 
 func (code Opcode) IsSingleShard() bool {
@@ -130,6 +154,12 @@ func (code Opcode) IsSingleShard() bool {
 }
 
 func (rp *RoutingParameters) findRoute(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable) ([]*srvtopo.ResolvedShard, []map[string]*querypb.BindVariable, error) {
+	if rp.TenantValue != nil {
+		rss, multiBindVars, routed, err := rp.tenantRoute(ctx, vcursor, bindVars)
+		if err != nil || routed {
+			return rss, multiBindVars, err
+		}
+	}
 	switch rp.Opcode {
 	case None:
 		return nil, nil, nil
@@ -332,6 +362,52 @@ func (rp *RoutingParameters) byDestination(ctx context.Context, vcursor VCursor,
 	return rss, multiBindVars, err
 }
 
+// tenantRoute sends the query to the keyspace its tenant has been moved to,
+// through the vindex of the table in that keyspace. It returns false if the
+// tenant has not been moved.
+func (rp *RoutingParameters) tenantRoute(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable) ([]*srvtopo.ResolvedShard, []map[string]*querypb.BindVariable, bool, error) {
+	env := evalengine.NewExpressionEnv(ctx, bindVars, vcursor)
+	value, err := env.Evaluate(rp.TenantValue)
+	if err != nil {
+		return nil, nil, false, err
+	}
+	if value.Value().IsNull() {
+		return nil, nil, false, nil
+	}
+	tenant := value.Value().ToString()
+	route, ok := rp.TenantRoutes[tenant]
+	if !ok {
+		return nil, nil, false, nil
+	}
+	if route.WritesDenied {
+		return nil, nil, false, vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "writes of tenant %s are denied while its traffic is switched", tenant)
+	}
+	if route.Keyspace == nil {
+		return nil, nil, false, nil
+	}
+	var rss []*srvtopo.ResolvedShard
+	if route.Vindex != nil {
+		vindexValue, err := env.Evaluate(route.Value)
+		if err != nil {
+			return nil, nil, false, err
+		}
+		rss, _, err = resolveShards(ctx, vcursor, route.Vindex, route.Keyspace, []sqltypes.Value{vindexValue.Value()})
+		if err != nil {
+			return nil, nil, false, err
+		}
+	} else {
+		rss, _, err = vcursor.ResolveDestinations(ctx, route.Keyspace.Name, nil, []key.Destination{key.DestinationAllShards{}})
+		if err != nil {
+			return nil, nil, false, err
+		}
+	}
+	multiBindVars := make([]map[string]*querypb.BindVariable, len(rss))
+	for i := range multiBindVars {
+		multiBindVars[i] = bindVars
+	}
+	return rss, multiBindVars, true, nil
+}
+
 func (rp *RoutingParameters) equal(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable) ([]*srvtopo.ResolvedShard, []map[string]*querypb.BindVariable, error) {
 	env := evalengine.NewExpressionEnv(ctx, bindVars, vcursor)
 	value, err := env.Evaluate(rp.Values[0])
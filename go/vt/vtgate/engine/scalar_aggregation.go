@@ -97,15 +97,14 @@ func (sa *ScalarAggregate) TryExecute(ctx context.Context, vcursor VCursor, bind
 
 	var resultRow []sqltypes.Value
 	var curDistincts []sqltypes.Value
-	for _, row := range result.Rows {
-		if resultRow == nil {
-			resultRow, curDistincts = convertRow(row, sa.PreProcess, sa.Aggregates, sa.AggrOnEngine)
-			continue
-		}
-		resultRow, curDistincts, err = merge(result.Fields, resultRow, row, curDistincts, sa.Collations, sa.Aggregates)
-		if err != nil {
-			return nil, err
-		}
+	rows := result.Rows
+	if len(rows) > 0 {
+		resultRow, curDistincts = convertRow(rows[0], sa.PreProcess, sa.Aggregates, sa.AggrOnEngine)
+		rows = rows[1:]
+	}
+	resultRow, _, err = mergeBatch(result.Fields, resultRow, rows, curDistincts, sa.Collations, sa.Aggregates)
+	if err != nil {
+		return nil, err
 	}
 
 	if resultRow == nil {
@@ -152,18 +151,14 @@ func (sa *ScalarAggregate) TryStreamExecute(ctx context.Context, vcursor VCursor
 		}
 
 		// this code is very similar to the TryExecute method
-		for _, row := range result.Rows {
-			if current == nil {
-				current, curDistincts = convertRow(row, sa.PreProcess, sa.Aggregates, sa.AggrOnEngine)
-				continue
-			}
-			var err error
-			current, curDistincts, err = merge(fields, current, row, curDistincts, sa.Collations, sa.Aggregates)
-			if err != nil {
-				return err
-			}
+		rows := result.Rows
+		if current == nil && len(rows) > 0 {
+			current, curDistincts = convertRow(rows[0], sa.PreProcess, sa.Aggregates, sa.AggrOnEngine)
+			rows = rows[1:]
 		}
-		return nil
+		var err error
+		current, curDistincts, err = mergeBatch(fields, current, rows, curDistincts, sa.Collations, sa.Aggregates)
+		return err
 	})
 	if err != nil {
 		return err
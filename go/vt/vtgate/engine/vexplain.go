@@ -20,6 +20,8 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"sort"
+	"time"
 
 	"vitess.io/vitess/go/sqltypes"
 	querypb "vitess.io/vitess/go/vt/proto/query"
@@ -36,6 +38,23 @@ type (
 		Gateway   srvtopo.Gateway
 		Query     string
 		FiredFrom Primitive
+
+		// RowsReceived and BytesReceived are the size of the result
+		// returned by the tablet for this query
+		RowsReceived  int
+		BytesReceived int
+	}
+
+	// PrimitiveStats are the runtime statistics of a primitive collected
+	// while running VEXPLAIN FORMAT=JSON
+	PrimitiveStats struct {
+		// Calls is the number of times the primitive was executed
+		Calls int
+		// Rows is the total number of rows produced by the primitive
+		Rows int
+		// Time is the total wall time spent executing the primitive,
+		// including the time spent executing its inputs
+		Time time.Duration
 	}
 
 	VExplain struct {
@@ -104,6 +123,8 @@ func (v *VExplain) convertToResult(ctx context.Context, vcursor VCursor) (*sqlty
 		return result, nil
 	case sqlparser.AllVExplainType:
 		return v.convertToVExplainAllResult(ctx, vcursor)
+	case sqlparser.JSONVExplainType:
+		return v.convertToVExplainJSONResult(vcursor)
 	default:
 		return nil, vterrors.Errorf(vtrpcpb.Code_INTERNAL, "Unknown type of VExplain plan")
 	}
@@ -138,7 +159,82 @@ func (v *VExplain) convertToVExplainAllResult(ctx context.Context, vcursor VCurs
 	}
 
 	planDescription := primitiveToPlanDescriptionWithSQLResults(v.Input, explainResults)
-	resultBytes, err := json.MarshalIndent(planDescription, "", "\t")
+	return vexplainJSONResult(planDescription)
+}
+
+func (v *VExplain) convertToVExplainJSONResult(vcursor VCursor) (*sqltypes.Result, error) {
+	stats := make(map[Primitive]*RuntimeStats)
+	for primitive, ps := range vcursor.Session().GetVExplainStats() {
+		stats[primitive] = &RuntimeStats{
+			Calls:          ps.Calls,
+			Rows:           ps.Rows,
+			WallTimeMicros: ps.Time.Microseconds(),
+		}
+	}
+
+	shards := make(map[Primitive]map[*querypb.Target]*ShardRuntimeStats)
+	for _, entry := range vcursor.Session().GetVExplainLogs() {
+		if entry.Target == nil || entry.FiredFrom == nil || entry.Query == "begin" {
+			continue
+		}
+		byTarget := shards[entry.FiredFrom]
+		if byTarget == nil {
+			byTarget = make(map[*querypb.Target]*ShardRuntimeStats)
+			shards[entry.FiredFrom] = byTarget
+		}
+		ss := byTarget[entry.Target]
+		if ss == nil {
+			ss = &ShardRuntimeStats{Keyspace: entry.Target.Keyspace, Shard: entry.Target.Shard}
+			byTarget[entry.Target] = ss
+		}
+		ss.Queries++
+		ss.RowsReceived += entry.RowsReceived
+		ss.BytesReceived += entry.BytesReceived
+	}
+
+	for primitive, byTarget := range shards {
+		rs := stats[primitive]
+		if rs == nil {
+			rs = &RuntimeStats{}
+			stats[primitive] = rs
+		}
+		rs.Shards = mergeShardRuntimeStats(byTarget)
+		rs.ShardsQueried = len(rs.Shards)
+	}
+
+	return vexplainJSONResult(primitiveToPlanDescriptionWithStats(v.Input, stats))
+}
+
+// mergeShardRuntimeStats merges the statistics of the queries sent to the same
+// shard through different targets and sorts them so the output is stable
+func mergeShardRuntimeStats(byTarget map[*querypb.Target]*ShardRuntimeStats) []ShardRuntimeStats {
+	merged := make(map[string]*ShardRuntimeStats, len(byTarget))
+	for _, ss := range byTarget {
+		key := ss.Keyspace + "/" + ss.Shard
+		if m, ok := merged[key]; ok {
+			m.Queries += ss.Queries
+			m.RowsReceived += ss.RowsReceived
+			m.BytesReceived += ss.BytesReceived
+			continue
+		}
+		merged[key] = ss
+	}
+
+	result := make([]ShardRuntimeStats, 0, len(merged))
+	for _, ss := range merged {
+		result = append(result, *ss)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Keyspace != result[j].Keyspace {
+			return result[i].Keyspace < result[j].Keyspace
+		}
+		return result[i].Shard < result[j].Shard
+	})
+	return result
+}
+
+func vexplainJSONResult(description PrimitiveDescription) (*sqltypes.Result, error) {
+	resultBytes, err := json.MarshalIndent(description, "", "\t")
 	if err != nil {
 		return nil, err
 	}
@@ -161,6 +257,28 @@ func (v *VExplain) convertToVExplainAllResult(ctx context.Context, vcursor VCurs
 	return qr, nil
 }
 
+// primitiveToPlanDescriptionWithStats transforms a primitive tree into a corresponding PlanDescription tree
+// and adds the runtime statistics collected for each primitive. Primitives that were never executed
+// get empty statistics, so all the nodes in the output have the same shape.
+func primitiveToPlanDescriptionWithStats(in Primitive, stats map[Primitive]*RuntimeStats) PrimitiveDescription {
+	this := in.description()
+
+	this.Stats = stats[in]
+	if this.Stats == nil {
+		this.Stats = &RuntimeStats{}
+	}
+
+	for _, input := range in.Inputs() {
+		this.Inputs = append(this.Inputs, primitiveToPlanDescriptionWithStats(input, stats))
+	}
+
+	if len(in.Inputs()) == 0 {
+		this.Inputs = []PrimitiveDescription{}
+	}
+
+	return this
+}
+
 // primitiveToPlanDescriptionWithSQLResults transforms a primitive tree into a corresponding PlanDescription tree
 // and adds the given res ...
 func primitiveToPlanDescriptionWithSQLResults(in Primitive, res map[Primitive]string) PrimitiveDescription {
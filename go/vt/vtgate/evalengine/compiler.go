@@ -54,7 +54,8 @@ func (ct ctype) nullable() bool {
 }
 
 func (ct ctype) isTextual() bool {
-	return sqltypes.IsText(ct.Type) || sqltypes.IsBinary(ct.Type)
+	// spatial values are represented as binary strings in MySQL's internal format
+	return sqltypes.IsText(ct.Type) || sqltypes.IsBinary(ct.Type) || ct.Type == sqltypes.Geometry
 }
 
 func (ct ctype) isHexOrBitLiteral() bool {
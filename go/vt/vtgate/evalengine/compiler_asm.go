@@ -2135,6 +2135,49 @@ func (asm *assembler) Fn_JSON_ARRAY(args int) {
 	}, "FN JSON_ARRAY (SP-%d)...(SP-1)", args)
 }
 
+func (asm *assembler) Fn_JSON_MODIFY(call *builtinJSONModify, paths []*json.Path, values int) {
+	asm.adjustStack(-values)
+	asm.emit(func(env *ExpressionEnv) int {
+		doc := env.vm.stack[env.vm.sp-values-1]
+		vals := env.vm.stack[env.vm.sp-values : env.vm.sp]
+		env.vm.stack[env.vm.sp-values-1], env.vm.err = call.modify(doc, paths, vals)
+		env.vm.sp -= values
+		return 1
+	}, "FN %s (SP-%d)...(SP-1), [static]", call.Method, values+1)
+}
+
+func (asm *assembler) Fn_JSON_MERGE(call *builtinJSONMerge, args int) {
+	asm.adjustStack(-(args - 1))
+	asm.emit(func(env *ExpressionEnv) int {
+		docs := env.vm.stack[env.vm.sp-args : env.vm.sp]
+		env.vm.stack[env.vm.sp-args], env.vm.err = call.merge(docs)
+		env.vm.sp -= args - 1
+		return 1
+	}, "FN %s (SP-%d)...(SP-1)", call.Method, args)
+}
+
+func (asm *assembler) Fn_JSON_CONTAINS(jp *json.Path) {
+	asm.adjustStack(-1)
+	asm.emit(func(env *ExpressionEnv) int {
+		target := env.vm.stack[env.vm.sp-2]
+		candidate := env.vm.stack[env.vm.sp-1]
+		env.vm.stack[env.vm.sp-2], env.vm.err = builtin_JSON_CONTAINS(target, candidate, jp)
+		env.vm.sp--
+		return 1
+	}, "FN JSON_CONTAINS (SP-2), (SP-1), [static]")
+}
+
+func (asm *assembler) Fn_JSON_SEARCH(call *builtinJSONSearch, match jsonMatch, escape rune, paths []*json.Path) {
+	asm.adjustStack(-1)
+	asm.emit(func(env *ExpressionEnv) int {
+		doc := env.vm.stack[env.vm.sp-2]
+		search := env.vm.stack[env.vm.sp-1]
+		env.vm.stack[env.vm.sp-2], env.vm.err = call.search(doc, search, match, escape, paths)
+		env.vm.sp--
+		return 1
+	}, "FN JSON_SEARCH (SP-2), '%s', (SP-1), [static]", match)
+}
+
 func (asm *assembler) Fn_JSON_CONTAINS_PATH(match jsonMatch, paths []*json.Path) {
 	switch match {
 	case jsonMatchOne:
@@ -2684,7 +2727,7 @@ func (asm *assembler) Fn_LTRIM2(col collations.TypedCollation) {
 		str := env.vm.stack[env.vm.sp-2].(*evalBytes)
 		pat := env.vm.stack[env.vm.sp-1].(*evalBytes)
 		str.tt = int16(sqltypes.VarChar)
-		str.bytes = bytes.TrimPrefix(str.bytes, pat.bytes)
+		str.bytes = trimPrefixAll(str.bytes, pat.bytes)
 		str.col = col
 		env.vm.sp--
 		return 1
@@ -2697,7 +2740,7 @@ func (asm *assembler) Fn_RTRIM2(col collations.TypedCollation) {
 		str := env.vm.stack[env.vm.sp-2].(*evalBytes)
 		pat := env.vm.stack[env.vm.sp-1].(*evalBytes)
 		str.tt = int16(sqltypes.VarChar)
-		str.bytes = bytes.TrimSuffix(str.bytes, pat.bytes)
+		str.bytes = trimSuffixAll(str.bytes, pat.bytes)
 		str.col = col
 		env.vm.sp--
 		return 1
@@ -2710,13 +2753,186 @@ func (asm *assembler) Fn_TRIM2(col collations.TypedCollation) {
 		str := env.vm.stack[env.vm.sp-2].(*evalBytes)
 		pat := env.vm.stack[env.vm.sp-1].(*evalBytes)
 		str.tt = int16(sqltypes.VarChar)
-		str.bytes = bytes.TrimPrefix(bytes.TrimSuffix(str.bytes, pat.bytes), pat.bytes)
+		str.bytes = trimPrefixAll(trimSuffixAll(str.bytes, pat.bytes), pat.bytes)
 		str.col = col
 		env.vm.sp--
 		return 1
 	}, "FN TRIM VARCHAR(SP-2) VARCHAR(SP-1)")
 }
 
+func (asm *assembler) Fn_SUBSTRING(col collations.TypedCollation, args int) {
+	asm.adjustStack(-(args - 1))
+	asm.emit(func(env *ExpressionEnv) int {
+		str := env.vm.stack[env.vm.sp-args].(*evalBytes)
+		pos := env.vm.stack[env.vm.sp-args+1].(*evalInt64)
+		length := int64(math.MaxInt64)
+		if args > 2 {
+			length = env.vm.stack[env.vm.sp-1].(*evalInt64).i
+		}
+
+		cs := col.Collation.Get().Charset()
+		env.vm.stack[env.vm.sp-args] = env.vm.arena.newEvalText(substring(cs, str.bytes, pos.i, length), col)
+		env.vm.sp -= args - 1
+		return 1
+	}, "FN SUBSTRING VARCHAR(SP-%d) INT64(SP-%d)...", args, args-1)
+}
+
+func (asm *assembler) Fn_SUBSTRING_INDEX(col collations.TypedCollation) {
+	asm.adjustStack(-2)
+	asm.emit(func(env *ExpressionEnv) int {
+		str := env.vm.stack[env.vm.sp-3].(*evalBytes)
+		delim := env.vm.stack[env.vm.sp-2].(*evalBytes)
+		count := env.vm.stack[env.vm.sp-1].(*evalInt64)
+
+		cs := col.Collation.Get().Charset()
+		env.vm.stack[env.vm.sp-3] = env.vm.arena.newEvalText(substringIndex(cs, str.bytes, delim.bytes, count.i), col)
+		env.vm.sp -= 2
+		return 1
+	}, "FN SUBSTRING_INDEX VARCHAR(SP-3) VARCHAR(SP-2) INT64(SP-1)")
+}
+
+func (asm *assembler) Fn_LOCATE(col collations.Collation, args int) {
+	asm.adjustStack(-(args - 1))
+	asm.emit(func(env *ExpressionEnv) int {
+		sub := env.vm.stack[env.vm.sp-args].(*evalBytes)
+		str := env.vm.stack[env.vm.sp-args+1].(*evalBytes)
+		var pos int64
+		if args > 2 {
+			pos = env.vm.stack[env.vm.sp-1].(*evalInt64).i
+		}
+
+		env.vm.stack[env.vm.sp-args] = env.vm.arena.newEvalInt64(locate(col, sub.bytes, str.bytes, pos, args > 2))
+		env.vm.sp -= args - 1
+		return 1
+	}, "FN LOCATE VARCHAR(SP-%d) VARCHAR(SP-%d)...", args, args-1)
+}
+
+func (asm *assembler) Fn_REPLACE(col collations.TypedCollation) {
+	asm.adjustStack(-2)
+	asm.emit(func(env *ExpressionEnv) int {
+		str := env.vm.stack[env.vm.sp-3].(*evalBytes)
+		from := env.vm.stack[env.vm.sp-2].(*evalBytes)
+		to := env.vm.stack[env.vm.sp-1].(*evalBytes)
+
+		cs := col.Collation.Get().Charset()
+		env.vm.stack[env.vm.sp-3] = env.vm.arena.newEvalText(replace(cs, str.bytes, from.bytes, to.bytes), col)
+		env.vm.sp -= 2
+		return 1
+	}, "FN REPLACE VARCHAR(SP-3) VARCHAR(SP-2) VARCHAR(SP-1)")
+}
+
+func (asm *assembler) Fn_REVERSE(col collations.TypedCollation) {
+	asm.emit(func(env *ExpressionEnv) int {
+		str := env.vm.stack[env.vm.sp-1].(*evalBytes)
+
+		cs := col.Collation.Get().Charset()
+		env.vm.stack[env.vm.sp-1] = env.vm.arena.newEvalText(reverse(cs, str.bytes), col)
+		return 1
+	}, "FN REVERSE VARCHAR(SP-1)")
+}
+
+func (asm *assembler) Fn_INSERT(col collations.TypedCollation) {
+	asm.adjustStack(-3)
+	asm.emit(func(env *ExpressionEnv) int {
+		str := env.vm.stack[env.vm.sp-4].(*evalBytes)
+		pos := env.vm.stack[env.vm.sp-3].(*evalInt64)
+		length := env.vm.stack[env.vm.sp-2].(*evalInt64)
+		newstr := env.vm.stack[env.vm.sp-1].(*evalBytes)
+
+		cs := col.Collation.Get().Charset()
+		env.vm.stack[env.vm.sp-4] = env.vm.arena.newEvalText(insert(cs, str.bytes, pos.i, length.i, newstr.bytes), col)
+		env.vm.sp -= 3
+		return 1
+	}, "FN INSERT VARCHAR(SP-4) INT64(SP-3) INT64(SP-2) VARCHAR(SP-1)")
+}
+
+func (asm *assembler) Fn_FIELD(args int, collate collations.ID) {
+	asm.adjustStack(-(args - 1))
+	asm.emit(func(env *ExpressionEnv) int {
+		idx, err := fieldIndex(env.vm.stack[env.vm.sp-args:env.vm.sp], collate)
+		if err != nil {
+			env.vm.err = err
+			return 0
+		}
+
+		env.vm.stack[env.vm.sp-args] = env.vm.arena.newEvalInt64(idx)
+		env.vm.sp -= args - 1
+		return 1
+	}, "FN FIELD (SP-%d)...(SP-1)", args)
+}
+
+func (asm *assembler) Fn_ELT(args int, col collations.TypedCollation) {
+	asm.adjustStack(-(args - 1))
+	asm.emit(func(env *ExpressionEnv) int {
+		res, err := elt(env.vm.stack[env.vm.sp-args], env.vm.stack[env.vm.sp-args+1:env.vm.sp], col)
+		if err != nil {
+			env.vm.err = err
+			return 0
+		}
+
+		env.vm.stack[env.vm.sp-args] = res
+		env.vm.sp -= args - 1
+		return 1
+	}, "FN ELT INT64(SP-%d) (SP-%d)...(SP-1)", args, args-1)
+}
+
+func (asm *assembler) Fn_FIND_IN_SET(col collations.Collation) {
+	asm.adjustStack(-1)
+	asm.emit(func(env *ExpressionEnv) int {
+		str := env.vm.stack[env.vm.sp-2].(*evalBytes)
+		strlist := env.vm.stack[env.vm.sp-1].(*evalBytes)
+
+		env.vm.stack[env.vm.sp-2] = env.vm.arena.newEvalInt64(findInSet(col, str.bytes, strlist.bytes))
+		env.vm.sp--
+		return 1
+	}, "FN FIND_IN_SET VARCHAR(SP-2) VARCHAR(SP-1)")
+}
+
+func (asm *assembler) Fn_SPACE(col collations.TypedCollation) {
+	asm.emit(func(env *ExpressionEnv) int {
+		n := env.vm.stack[env.vm.sp-1].(*evalInt64)
+
+		res, ok := space(col.Collation.Get(), n.i)
+		if !ok {
+			env.vm.stack[env.vm.sp-1] = nil
+			return 1
+		}
+		env.vm.stack[env.vm.sp-1] = env.vm.arena.newEvalText(res, col)
+		return 1
+	}, "FN SPACE INT64(SP-1)")
+}
+
+func (asm *assembler) Fn_SOUNDEX(col collations.TypedCollation) {
+	asm.emit(func(env *ExpressionEnv) int {
+		str := env.vm.stack[env.vm.sp-1].(*evalBytes)
+
+		cs := col.Collation.Get().Charset()
+		env.vm.stack[env.vm.sp-1] = env.vm.arena.newEvalText(soundex(cs, str.bytes), col)
+		return 1
+	}, "FN SOUNDEX VARCHAR(SP-1)")
+}
+
+func (asm *assembler) Fn_FORMAT(col collations.TypedCollation) {
+	asm.adjustStack(-1)
+	asm.emit(func(env *ExpressionEnv) int {
+		num := env.vm.stack[env.vm.sp-2]
+		dec := env.vm.stack[env.vm.sp-1].(*evalInt64)
+
+		env.vm.stack[env.vm.sp-2] = env.vm.arena.newEvalText(formatNumber(num, dec.i), col)
+		env.vm.sp--
+		return 1
+	}, "FN FORMAT (SP-2) INT64(SP-1)")
+}
+
+func (asm *assembler) Fn_CHAR(call *builtinChar, args int) {
+	asm.adjustStack(-(args - 1))
+	asm.emit(func(env *ExpressionEnv) int {
+		env.vm.stack[env.vm.sp-args] = call.charString(env.vm.stack[env.vm.sp-args : env.vm.sp])
+		env.vm.sp -= args - 1
+		return 1
+	}, "FN CHAR (SP-%d)...(SP-1)", args)
+}
+
 func (asm *assembler) Fn_TO_BASE64(t sqltypes.Type, col collations.TypedCollation) {
 	asm.emit(func(env *ExpressionEnv) int {
 		str := env.vm.stack[env.vm.sp-1].(*evalBytes)
@@ -3942,6 +4158,74 @@ func (asm *assembler) Fn_YEARWEEK() {
 	}, "FN YEARWEEK DATE(SP-1)")
 }
 
+func (asm *assembler) Fn_DATE_MATH(unit datetime.IntervalType, sub bool, col collations.TypedCollation) {
+	asm.adjustStack(-1)
+	asm.emit(func(env *ExpressionEnv) int {
+		date := env.vm.stack[env.vm.sp-2]
+		interval := env.vm.stack[env.vm.sp-1]
+		env.vm.stack[env.vm.sp-2] = dateMath(date, interval, unit, sub, col)
+		env.vm.sp--
+		return 1
+	}, "FN DATE_MATH (SP-2), (SP-1)")
+}
+
+func (asm *assembler) Fn_DATEDIFF() {
+	asm.adjustStack(-1)
+	asm.emit(func(env *ExpressionEnv) int {
+		env.vm.stack[env.vm.sp-2] = dateDiff(env.vm.stack[env.vm.sp-2], env.vm.stack[env.vm.sp-1])
+		env.vm.sp--
+		return 1
+	}, "FN DATEDIFF (SP-2), (SP-1)")
+}
+
+func (asm *assembler) Fn_TIMESTAMPDIFF(unit datetime.IntervalType) {
+	asm.adjustStack(-1)
+	asm.emit(func(env *ExpressionEnv) int {
+		env.vm.stack[env.vm.sp-2] = timestampDiff(unit, env.vm.stack[env.vm.sp-2], env.vm.stack[env.vm.sp-1])
+		env.vm.sp--
+		return 1
+	}, "FN TIMESTAMPDIFF (SP-2), (SP-1)")
+}
+
+func (asm *assembler) Fn_STR_TO_DATE(tt sqltypes.Type, prec int) {
+	asm.adjustStack(-1)
+	asm.emit(func(env *ExpressionEnv) int {
+		env.vm.stack[env.vm.sp-2] = strToDate(env.vm.stack[env.vm.sp-2], env.vm.stack[env.vm.sp-1], tt, prec)
+		env.vm.sp--
+		return 1
+	}, "FN STR_TO_DATE VARCHAR(SP-2), VARCHAR(SP-1)")
+}
+
+func (asm *assembler) Fn_LAST_DAY() {
+	asm.emit(func(env *ExpressionEnv) int {
+		env.vm.stack[env.vm.sp-1] = lastDay(env.vm.stack[env.vm.sp-1])
+		return 1
+	}, "FN LAST_DAY (SP-1)")
+}
+
+func (asm *assembler) Fn_ADDTIME(sub bool, col collations.TypedCollation) {
+	asm.adjustStack(-1)
+	asm.emit(func(env *ExpressionEnv) int {
+		env.vm.stack[env.vm.sp-2] = addTime(env.vm.stack[env.vm.sp-2], env.vm.stack[env.vm.sp-1], sub, col)
+		env.vm.sp--
+		return 1
+	}, "FN ADDTIME (SP-2), (SP-1)")
+}
+
+func (asm *assembler) Fn_TIME_TO_SEC() {
+	asm.emit(func(env *ExpressionEnv) int {
+		env.vm.stack[env.vm.sp-1] = timeToSec(env.vm.stack[env.vm.sp-1])
+		return 1
+	}, "FN TIME_TO_SEC (SP-1)")
+}
+
+func (asm *assembler) Fn_SEC_TO_TIME() {
+	asm.emit(func(env *ExpressionEnv) int {
+		env.vm.stack[env.vm.sp-1] = secToTime(env.vm.stack[env.vm.sp-1])
+		return 1
+	}, "FN SEC_TO_TIME (SP-1)")
+}
+
 func intervalStackOffset(l, i int) int {
 	return l - i + 1
 }
@@ -4240,3 +4524,170 @@ func (asm *assembler) Fn_UUID_TO_BIN1() {
 		return 1
 	}, "FN UUID_TO_BIN VARBINARY(SP-2) INT64(SP-1)")
 }
+
+func (asm *assembler) Fn_REGEXP_LIKE(r *builtinRegexpLike, args int) {
+	asm.adjustStack(-args + 1)
+	asm.emit(func(env *ExpressionEnv) int {
+		subject := env.vm.stack[env.vm.sp-args].(*evalBytes)
+		pattern := env.vm.stack[env.vm.sp-args+1].(*evalBytes)
+		var matchType *evalBytes
+		if args > 2 {
+			matchType = env.vm.stack[env.vm.sp-args+2].(*evalBytes)
+		}
+		var match bool
+		match, env.vm.err = regexpLike(&r.cache, subject, pattern, matchType)
+		env.vm.stack[env.vm.sp-args] = env.vm.arena.newEvalBool(match == !r.Negate)
+		env.vm.sp -= args - 1
+		return 1
+	}, "FN REGEXP_LIKE VARCHAR(SP-%d)...VARCHAR(SP-1)", args)
+}
+
+func (asm *assembler) Fn_REGEXP_INSTR(r *builtinRegexpInstr, args int) {
+	asm.adjustStack(-args + 1)
+	asm.emit(func(env *ExpressionEnv) int {
+		subject := env.vm.stack[env.vm.sp-args].(*evalBytes)
+		pattern := env.vm.stack[env.vm.sp-args+1].(*evalBytes)
+		opts := [3]int64{1, 1, 0}
+		for i := 2; i < args && i < 5; i++ {
+			opts[i-2] = env.vm.stack[env.vm.sp-args+i].(*evalInt64).i
+		}
+		var matchType *evalBytes
+		if args > 5 {
+			matchType = env.vm.stack[env.vm.sp-args+5].(*evalBytes)
+		}
+		var pos int64
+		pos, env.vm.err = regexpInstr(&r.cache, subject, pattern, opts[0], opts[1], opts[2], matchType)
+		env.vm.stack[env.vm.sp-args] = env.vm.arena.newEvalInt64(pos)
+		env.vm.sp -= args - 1
+		return 1
+	}, "FN REGEXP_INSTR VARCHAR(SP-%d)...(SP-1)", args)
+}
+
+func (asm *assembler) Fn_REGEXP_SUBSTR(r *builtinRegexpSubstr, args int) {
+	asm.adjustStack(-args + 1)
+	asm.emit(func(env *ExpressionEnv) int {
+		subject := env.vm.stack[env.vm.sp-args].(*evalBytes)
+		pattern := env.vm.stack[env.vm.sp-args+1].(*evalBytes)
+		opts := [2]int64{1, 1}
+		for i := 2; i < args && i < 4; i++ {
+			opts[i-2] = env.vm.stack[env.vm.sp-args+i].(*evalInt64).i
+		}
+		var matchType *evalBytes
+		if args > 4 {
+			matchType = env.vm.stack[env.vm.sp-args+4].(*evalBytes)
+		}
+		res, err := regexpSubstr(&r.cache, subject, pattern, opts[0], opts[1], matchType)
+		if res == nil || err != nil {
+			env.vm.stack[env.vm.sp-args] = nil
+		} else {
+			env.vm.stack[env.vm.sp-args] = res
+		}
+		env.vm.err = err
+		env.vm.sp -= args - 1
+		return 1
+	}, "FN REGEXP_SUBSTR VARCHAR(SP-%d)...(SP-1)", args)
+}
+
+func (asm *assembler) Fn_REGEXP_REPLACE(r *builtinRegexpReplace, args int) {
+	asm.adjustStack(-args + 1)
+	asm.emit(func(env *ExpressionEnv) int {
+		subject := env.vm.stack[env.vm.sp-args].(*evalBytes)
+		pattern := env.vm.stack[env.vm.sp-args+1].(*evalBytes)
+		repl := env.vm.stack[env.vm.sp-args+2].(*evalBytes)
+		opts := [2]int64{1, 0}
+		for i := 3; i < args && i < 5; i++ {
+			opts[i-3] = env.vm.stack[env.vm.sp-args+i].(*evalInt64).i
+		}
+		var matchType *evalBytes
+		if args > 5 {
+			matchType = env.vm.stack[env.vm.sp-args+5].(*evalBytes)
+		}
+		res, err := regexpReplace(&r.cache, subject, pattern, repl, opts[0], opts[1], matchType)
+		if err != nil {
+			env.vm.stack[env.vm.sp-args] = nil
+		} else {
+			env.vm.stack[env.vm.sp-args] = res
+		}
+		env.vm.err = err
+		env.vm.sp -= args - 1
+		return 1
+	}, "FN REGEXP_REPLACE VARCHAR(SP-%d)...(SP-1)", args)
+}
+
+func (asm *assembler) Fn_ST_GEOMFROM(call *builtinGeomFrom, srid bool) {
+	if srid {
+		asm.adjustStack(-1)
+		asm.emit(func(env *ExpressionEnv) int {
+			data := env.vm.stack[env.vm.sp-2]
+			s := env.vm.stack[env.vm.sp-1].(*evalInt64)
+			env.vm.stack[env.vm.sp-2], env.vm.err = call.geometry(data.ToRawBytes(), s.i)
+			env.vm.sp--
+			return 1
+		}, "FN %s (SP-2) INT64(SP-1)", call.Method)
+	} else {
+		asm.emit(func(env *ExpressionEnv) int {
+			data := env.vm.stack[env.vm.sp-1]
+			env.vm.stack[env.vm.sp-1], env.vm.err = call.geometry(data.ToRawBytes(), 0)
+			return 1
+		}, "FN %s (SP-1)", call.Method)
+	}
+}
+
+func (asm *assembler) Fn_ST_AS(call *builtinGeomFormat) {
+	asm.emit(func(env *ExpressionEnv) int {
+		env.vm.stack[env.vm.sp-1], env.vm.err = call.encode(env.vm.stack[env.vm.sp-1])
+		return 1
+	}, "FN %s GEOMETRY(SP-1)", call.Method)
+}
+
+func (asm *assembler) Fn_POINT() {
+	asm.adjustStack(-1)
+	asm.emit(func(env *ExpressionEnv) int {
+		x := env.vm.stack[env.vm.sp-2].(*evalFloat)
+		y := env.vm.stack[env.vm.sp-1].(*evalFloat)
+		env.vm.stack[env.vm.sp-2] = newEvalPoint(x.f, y.f)
+		env.vm.sp--
+		return 1
+	}, "FN POINT FLOAT64(SP-2) FLOAT64(SP-1)")
+}
+
+func (asm *assembler) Fn_ST_XY(call *builtinPointCoord) {
+	asm.emit(func(env *ExpressionEnv) int {
+		env.vm.stack[env.vm.sp-1], env.vm.err = call.coord(env.vm.stack[env.vm.sp-1])
+		return 1
+	}, "FN %s GEOMETRY(SP-1)", call.Method)
+}
+
+func (asm *assembler) Fn_ST_DISTANCE_SPHERE(call *builtinDistanceSphere, radius bool) {
+	if radius {
+		asm.adjustStack(-2)
+		asm.emit(func(env *ExpressionEnv) int {
+			g1 := env.vm.stack[env.vm.sp-3]
+			g2 := env.vm.stack[env.vm.sp-2]
+			r := env.vm.stack[env.vm.sp-1].(*evalFloat)
+			env.vm.stack[env.vm.sp-3], env.vm.err = call.distance(g1, g2, r.f)
+			env.vm.sp -= 2
+			return 1
+		}, "FN ST_DISTANCE_SPHERE GEOMETRY(SP-3) GEOMETRY(SP-2) FLOAT64(SP-1)")
+	} else {
+		asm.adjustStack(-1)
+		asm.emit(func(env *ExpressionEnv) int {
+			g1 := env.vm.stack[env.vm.sp-2]
+			g2 := env.vm.stack[env.vm.sp-1]
+			env.vm.stack[env.vm.sp-2], env.vm.err = call.distance(g1, g2, earthRadius)
+			env.vm.sp--
+			return 1
+		}, "FN ST_DISTANCE_SPHERE GEOMETRY(SP-2) GEOMETRY(SP-1)")
+	}
+}
+
+func (asm *assembler) Fn_ST_CONTAINS(call *builtinGeomContains) {
+	asm.adjustStack(-1)
+	asm.emit(func(env *ExpressionEnv) int {
+		g1 := env.vm.stack[env.vm.sp-2]
+		g2 := env.vm.stack[env.vm.sp-1]
+		env.vm.stack[env.vm.sp-2], env.vm.err = call.contains(g1, g2)
+		env.vm.sp--
+		return 1
+	}, "FN %s GEOMETRY(SP-2) GEOMETRY(SP-1)", call.Method)
+}
@@ -22,6 +22,7 @@ import (
 	"vitess.io/vitess/go/mysql/decimal"
 	"vitess.io/vitess/go/mysql/fastparse"
 	"vitess.io/vitess/go/mysql/json"
+	"vitess.io/vitess/go/sqltypes"
 	querypb "vitess.io/vitess/go/vt/proto/query"
 	"vitess.io/vitess/go/vt/proto/vtrpc"
 	"vitess.io/vitess/go/vt/vterrors"
@@ -141,6 +142,33 @@ func (asm *assembler) PushBVar_f(key string) {
 	}, "PUSH FLOAT64(:%q)", key)
 }
 
+func push_geometry(env *ExpressionEnv, raw []byte) int {
+	env.vm.stack[env.vm.sp] = newEvalRaw(sqltypes.Geometry, raw, collationBinary)
+	env.vm.sp++
+	return 1
+}
+
+func (asm *assembler) PushColumn_geometry(offset int) {
+	asm.adjustStack(1)
+
+	asm.emit(func(env *ExpressionEnv) int {
+		return push_geometry(env, env.Row[offset].Raw())
+	}, "PUSH GEOMETRY(:%d)", offset)
+}
+
+func (asm *assembler) PushBVar_geometry(key string) {
+	asm.adjustStack(1)
+
+	asm.emit(func(env *ExpressionEnv) int {
+		var bvar *querypb.BindVariable
+		bvar, env.vm.err = env.lookupBindVar(key)
+		if env.vm.err != nil {
+			return 0
+		}
+		return push_geometry(env, bvar.Value)
+	}, "PUSH GEOMETRY(:%q)", key)
+}
+
 func push_hexnum(env *ExpressionEnv, raw []byte) int {
 	raw, env.vm.err = parseHexNumber(raw)
 	env.vm.stack[env.vm.sp] = newEvalBytesHex(raw)
@@ -444,6 +444,186 @@ func TestCompilerSingle(t *testing.T) {
 			expression: `INTERVAL(0, 0, 0, -1, NULL, NULL, 1)`,
 			result:     `INT64(5)`,
 		},
+		{
+			expression: `REGEXP_LIKE('CamelCase', 'CAMELCASE')`,
+			result:     `INT64(1)`,
+		},
+		{
+			expression: `REGEXP_LIKE('CamelCase', 'CAMELCASE', 'c')`,
+			result:     `INT64(0)`,
+		},
+		{
+			expression: `'abc' NOT REGEXP '^b'`,
+			result:     `INT64(1)`,
+		},
+		{
+			expression: `REGEXP_INSTR('dog cat dog', 'dog', 2)`,
+			result:     `INT64(9)`,
+		},
+		{
+			expression: `REGEXP_INSTR('aa aaa aaaa', 'a{3}', 1, 1, 1)`,
+			result:     `INT64(7)`,
+		},
+		{
+			expression: `REGEXP_INSTR('àbç dëf', 'd')`,
+			result:     `INT64(5)`,
+		},
+		{
+			expression: `REGEXP_SUBSTR('abc def ghi', '[a-z]+', 1, 3)`,
+			result:     `VARCHAR("ghi")`,
+		},
+		{
+			expression: `REGEXP_REPLACE('abc def ghi', '[a-z]+', 'X', 1, 3)`,
+			result:     `VARCHAR("abc def X")`,
+		},
+		{
+			expression: `REGEXP_REPLACE('2023-04-01', '([0-9]+)-([0-9]+)', '$2/$1')`,
+			result:     `VARCHAR("04/2023-01")`,
+		},
+		{
+			expression: `REGEXP_LIKE('١٢٣', '^\\d+$')`,
+			result:     `INT64(1)`,
+		},
+		{
+			expression: `REGEXP_SUBSTR('straße über', '\\w+', 1, 2)`,
+			result:     `VARCHAR("über")`,
+		},
+		{
+			expression: `REGEXP_LIKE('ÀÉ', '^[[:upper:]]+$', 'c')`,
+			result:     `INT64(1)`,
+		},
+		{
+			expression: `REGEXP_REPLACE(_utf8mb4 0x61C2A062, '\\s', '-')`,
+			result:     `VARCHAR("a-b")`,
+		},
+		{
+			expression: `REGEXP_INSTR(_binary 'àbc', _binary 'b')`,
+			result:     `INT64(3)`,
+		},
+		{
+			expression: `DATE_ADD(timestamp '2023-01-31 10:00:00', INTERVAL 1 MONTH)`,
+			result:     `DATETIME("2023-02-28 10:00:00")`,
+		},
+		{
+			expression: `DATE_ADD(date '2023-01-31', INTERVAL 1 DAY)`,
+			result:     `DATE("2023-02-01")`,
+		},
+		{
+			expression: `DATE_ADD(date '2023-01-31', INTERVAL 1 HOUR)`,
+			result:     `DATETIME("2023-01-31 01:00:00")`,
+		},
+		{
+			expression: `DATE_SUB('2023-03-01', INTERVAL 1 DAY)`,
+			result:     `VARCHAR("2023-02-28")`,
+		},
+		{
+			expression: `'2023-03-01 10:00:00' + INTERVAL '1:30' HOUR_MINUTE`,
+			result:     `VARCHAR("2023-03-01 11:30:00")`,
+		},
+		{
+			expression: `DATE_ADD(time '10:00:00', INTERVAL 1.5 SECOND)`,
+			result:     `TIME("10:00:01.5")`,
+		},
+		{
+			expression: `DATE_ADD(timestamp '2023-01-01 00:00:00', INTERVAL '1 2' YEAR_MONTH)`,
+			result:     `DATETIME("2024-03-01 00:00:00")`,
+		},
+		{
+			expression: `ADDDATE(date '2023-01-01', 31)`,
+			result:     `DATE("2023-02-01")`,
+		},
+		{
+			expression: `TIMESTAMPADD(WEEK, 1, '2023-01-01')`,
+			result:     `VARCHAR("2023-01-08")`,
+		},
+		{
+			expression: `DATEDIFF('2023-03-01 23:59:59', '2023-02-28')`,
+			result:     `INT64(1)`,
+		},
+		{
+			expression: `TIMESTAMPDIFF(MONTH, '2023-01-31', '2023-02-28')`,
+			result:     `INT64(0)`,
+		},
+		{
+			expression: `TIMESTAMPDIFF(SECOND, timestamp '2023-01-01 00:00:01', timestamp '2023-01-01 00:00:00')`,
+			result:     `INT64(-1)`,
+		},
+		{
+			expression: `STR_TO_DATE('May 3rd, 2013', '%M %D, %Y')`,
+			result:     `DATE("2013-05-03")`,
+		},
+		{
+			expression: `STR_TO_DATE('10:11:12.5', '%H:%i:%s.%f')`,
+			result:     `TIME("10:11:12.500000")`,
+		},
+		{
+			expression: `STR_TO_DATE('2023-02-30', '%Y-%m-%d')`,
+			result:     `NULL`,
+		},
+		{
+			expression: `LAST_DAY('2024-02-10')`,
+			result:     `DATE("2024-02-29")`,
+		},
+		{
+			expression: `ADDTIME('2023-01-01 23:59:59', '1.000001')`,
+			result:     `VARCHAR("2023-01-02 00:00:00.000001")`,
+		},
+		{
+			expression: `SUBTIME(time '10:00:00', '11:00:00')`,
+			result:     `TIME("-01:00:00")`,
+		},
+		{
+			expression: `TIME_TO_SEC('-01:00:01')`,
+			result:     `INT64(-3601)`,
+		},
+		{
+			expression: `SEC_TO_TIME(3601.5)`,
+			result:     `TIME("01:00:01.5")`,
+		},
+		{
+			expression: `SEC_TO_TIME(4000000)`,
+			result:     `TIME("838:59:59")`,
+		},
+		{
+			expression: `JSON_SET('{"a": 1, "b": [2, 3]}', '$.a', 10, '$.c', '[true, false]')`,
+			result:     `JSON("{\"a\": 10, \"b\": [2, 3], \"c\": \"[true, false]\"}")`,
+		},
+		{
+			expression: `JSON_INSERT('{"a": 1, "b": [2, 3]}', '$.a', 10, '$.c', '[true, false]')`,
+			result:     `JSON("{\"a\": 1, \"b\": [2, 3], \"c\": \"[true, false]\"}")`,
+		},
+		{
+			expression: `JSON_REPLACE('{"a": 1, "b": [2, 3]}', '$.a', 10, '$.c', '[true, false]')`,
+			result:     `JSON("{\"a\": 10, \"b\": [2, 3]}")`,
+		},
+		{
+			expression: `JSON_REMOVE('["a", ["b", "c"], "d"]', '$[1]')`,
+			result:     `JSON("[\"a\", \"d\"]")`,
+		},
+		{
+			expression: `JSON_ARRAY_APPEND('["a", ["b", "c"], "d"]', '$[1]', 1, '$[0]', 2)`,
+			result:     `JSON("[[\"a\", 2], [\"b\", \"c\", 1], \"d\"]")`,
+		},
+		{
+			expression: `JSON_ARRAY_INSERT('["a", {"b": [1, 2]}, [3, 4]]', '$[1]', 'x', '$[100]', 'y')`,
+			result:     `JSON("[\"a\", \"x\", {\"b\": [1, 2]}, [3, 4], \"y\"]")`,
+		},
+		{
+			expression: `JSON_MERGE_PATCH('{"a": 1, "b": 2}', '{"a": 3, "c": 4}', '{"a": 5, "d": 6}')`,
+			result:     `JSON("{\"a\": 5, \"b\": 2, \"c\": 4, \"d\": 6}")`,
+		},
+		{
+			expression: `JSON_MERGE_PRESERVE('{"a": 1, "b": 2}', '{"a": 3, "c": 4}')`,
+			result:     `JSON("{\"a\": [1, 3], \"b\": 2, \"c\": 4}")`,
+		},
+		{
+			expression: `JSON_CONTAINS('{"a": 1, "b": 2, "c": {"d": 4}}', '{"d": 4}', '$.c')`,
+			result:     `INT64(1)`,
+		},
+		{
+			expression: `JSON_SEARCH('["abc", [{"k": "10"}, "def"], {"x": "abc"}, {"y": "bcd"}]', 'all', 'abc')`,
+			result:     `JSON("[\"$[0]\", \"$[2].x\"]")`,
+		},
 	}
 
 	for _, tc := range testCases {
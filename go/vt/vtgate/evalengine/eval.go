@@ -358,6 +358,8 @@ func valueToEval(value sqltypes.Value, collation collations.TypedCollation) (eva
 		var p json.Parser
 		j, err := p.ParseBytes(value.Raw())
 		return j, wrap(err)
+	case tt == sqltypes.Geometry:
+		return newEvalRaw(sqltypes.Geometry, value.Raw(), collationBinary), nil
 	default:
 		return nil, vterrors.Errorf(vtrpcpb.Code_INTERNAL, "Type is not supported: %q %s", value, value.Type())
 	}
@@ -92,6 +92,8 @@ func (column *Column) compile(c *compiler) (ctype, error) {
 		c.asm.PushNull()
 	case tt == sqltypes.TypeJSON:
 		c.asm.PushColumn_json(column.Offset)
+	case tt == sqltypes.Geometry:
+		c.asm.PushColumn_geometry(column.Offset)
 	default:
 		return ctype{}, vterrors.Errorf(vtrpc.Code_UNIMPLEMENTED, "Type is not supported: %s", tt)
 	}
@@ -0,0 +1,671 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package evalengine
+
+import (
+	"math"
+	"strings"
+
+	"vitess.io/vitess/go/hack"
+	"vitess.io/vitess/go/mysql/collations"
+	"vitess.io/vitess/go/sqltypes"
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+	"vitess.io/vitess/go/vt/vterrors"
+)
+
+// The spatial functions only support the two spatial reference systems whose
+// properties we know without access to MySQL's data dictionary: the Cartesian
+// plane (SRID 0) and WGS 84 (SRID 4326), whose coordinates are given in
+// latitude-longitude order but are stored by MySQL as longitude-latitude.
+// Geometries in any other reference system fail to evaluate.
+const sridWGS84 = 4326
+
+// earthRadius is the default sphere radius used by ST_Distance_Sphere, in meters.
+const earthRadius = 6370986.0
+
+type (
+	// builtinGeomFrom implements the ST_*FromText and ST_*FromWKB functions.
+	builtinGeomFrom struct {
+		CallExpr
+		geom   sqltypes.GeomType
+		binary bool
+	}
+
+	// builtinGeomFormat implements ST_AsText and ST_AsBinary.
+	builtinGeomFormat struct {
+		CallExpr
+		binary  bool
+		collate collations.ID
+	}
+
+	builtinPoint struct {
+		CallExpr
+	}
+
+	// builtinPointCoord implements ST_X and ST_Y.
+	builtinPointCoord struct {
+		CallExpr
+		y bool
+	}
+
+	builtinDistanceSphere struct {
+		CallExpr
+	}
+
+	// builtinGeomContains implements ST_Contains and ST_Within.
+	builtinGeomContains struct {
+		CallExpr
+		within bool
+	}
+)
+
+var _ Expr = (*builtinGeomFrom)(nil)
+var _ Expr = (*builtinGeomFormat)(nil)
+var _ Expr = (*builtinPoint)(nil)
+var _ Expr = (*builtinPointCoord)(nil)
+var _ Expr = (*builtinDistanceSphere)(nil)
+var _ Expr = (*builtinGeomContains)(nil)
+
+func errGeomInvalid(fname string) error {
+	return vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "Invalid GIS data provided to function %s.", fname)
+}
+
+func errGeomDifferentSRIDs(fname string, srid1, srid2 uint32) error {
+	return vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "Binary geometry function %s given two geometries of different srids: %d and %d, which should have been identical.", fname, srid1, srid2)
+}
+
+func errGeomUnsupportedArguments(fname string) error {
+	return vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "Calling geometry function %s with unsupported types of arguments.", fname)
+}
+
+func checkSRID(srid uint32, fname string) error {
+	if srid != 0 && srid != sridWGS84 {
+		return vterrors.Errorf(vtrpcpb.Code_UNIMPLEMENTED, "spatial reference system %d is not supported in function %s", srid, fname)
+	}
+	return nil
+}
+
+func checkLongLat(c sqltypes.Coord, fname string) error {
+	if c.Y < -90 || c.Y > 90 {
+		return vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "Latitude %f is out of range in function %s. It must be within [-90.000000, 90.000000].", c.Y, fname)
+	}
+	if c.X <= -180 || c.X > 180 {
+		return vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "Longitude %f is out of range in function %s. It must be within (-180.000000, 180.000000].", c.X, fname)
+	}
+	return nil
+}
+
+// evalToGeom decodes a spatial value in MySQL's internal storage format.
+func evalToGeom(e eval, fname string) (*sqltypes.Geom, error) {
+	b, ok := e.(*evalBytes)
+	if !ok {
+		return nil, errGeomInvalid(fname)
+	}
+	g, err := sqltypes.ParseGeom(b.bytes)
+	if err != nil {
+		return nil, errGeomInvalid(fname)
+	}
+	if err := checkSRID(g.SRID, fname); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+func newEvalGeometry(g *sqltypes.Geom) *evalBytes {
+	return newEvalRaw(sqltypes.Geometry, g.Bytes(), collationBinary)
+}
+
+func (call *builtinGeomFrom) geometry(data []byte, srid int64) (eval, error) {
+	fname := strings.ToLower(call.Method)
+	if srid < 0 || srid > math.MaxUint32 {
+		return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "SRID value is out of range in '%s'", fname)
+	}
+	if err := checkSRID(uint32(srid), fname); err != nil {
+		return nil, err
+	}
+
+	var g *sqltypes.Geom
+	var err error
+	if call.binary {
+		g, err = sqltypes.ParseWKB(data, uint32(srid), call.geom)
+	} else {
+		g, err = sqltypes.ParseWKT(hack.String(data), uint32(srid), call.geom)
+	}
+	if err != nil {
+		return nil, errGeomInvalid(fname)
+	}
+
+	if srid == sridWGS84 {
+		g.SwapXY()
+		g.Walk(func(c *sqltypes.Coord) {
+			if err == nil {
+				err = checkLongLat(*c, fname)
+			}
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return newEvalGeometry(g), nil
+}
+
+func (call *builtinGeomFrom) eval(env *ExpressionEnv) (eval, error) {
+	data, err := call.Arguments[0].eval(env)
+	if err != nil {
+		return nil, err
+	}
+	var srid eval = newEvalInt64(0)
+	if len(call.Arguments) > 1 {
+		srid, err = call.Arguments[1].eval(env)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if data == nil || srid == nil {
+		return nil, nil
+	}
+	return call.geometry(data.ToRawBytes(), evalToInt64(srid).i)
+}
+
+func (call *builtinGeomFrom) typeof(env *ExpressionEnv, fields []*querypb.Field) (sqltypes.Type, typeFlag) {
+	var f typeFlag
+	for _, arg := range call.Arguments {
+		_, af := arg.typeof(env, fields)
+		f |= af
+	}
+	return sqltypes.Geometry, f
+}
+
+func (call *builtinGeomFrom) compile(c *compiler) (ctype, error) {
+	data, err := call.Arguments[0].compile(c)
+	if err != nil {
+		return ctype{}, err
+	}
+
+	var skip *jump
+	flag := data.Flag
+	if len(call.Arguments) > 1 {
+		srid, err := call.Arguments[1].compile(c)
+		if err != nil {
+			return ctype{}, err
+		}
+		skip = c.compileNullCheck2(data, srid)
+		_ = c.compileToInt64(srid, 1)
+		flag |= srid.Flag
+	} else {
+		skip = c.compileNullCheck1(data)
+	}
+
+	c.asm.Fn_ST_GEOMFROM(call, len(call.Arguments) > 1)
+	c.asm.jumpDestination(skip)
+	return ctype{Type: sqltypes.Geometry, Col: collationBinary, Flag: flag}, nil
+}
+
+func (call *builtinGeomFormat) encode(e eval) (eval, error) {
+	g, err := evalToGeom(e, strings.ToLower(call.Method))
+	if err != nil {
+		return nil, err
+	}
+	if g.SRID == sridWGS84 {
+		g.SwapXY()
+	}
+	if call.binary {
+		return newEvalRaw(sqltypes.Blob, g.AppendWKB(nil), collationBinary), nil
+	}
+	col := collations.TypedCollation{
+		Collation:    call.collate,
+		Coercibility: collations.CoerceCoercible,
+		Repertoire:   collations.RepertoireASCII,
+	}
+	return newEvalRaw(sqltypes.Text, g.AppendWKT(nil), col), nil
+}
+
+func (call *builtinGeomFormat) eval(env *ExpressionEnv) (eval, error) {
+	arg, err := call.arg1(env)
+	if err != nil {
+		return nil, err
+	}
+	if arg == nil {
+		return nil, nil
+	}
+	return call.encode(arg)
+}
+
+func (call *builtinGeomFormat) typeof(env *ExpressionEnv, fields []*querypb.Field) (sqltypes.Type, typeFlag) {
+	_, f := call.Arguments[0].typeof(env, fields)
+	if call.binary {
+		return sqltypes.Blob, f
+	}
+	return sqltypes.Text, f
+}
+
+func (call *builtinGeomFormat) compile(c *compiler) (ctype, error) {
+	arg, err := call.Arguments[0].compile(c)
+	if err != nil {
+		return ctype{}, err
+	}
+
+	skip := c.compileNullCheck1(arg)
+	c.asm.Fn_ST_AS(call)
+	c.asm.jumpDestination(skip)
+
+	if call.binary {
+		return ctype{Type: sqltypes.Blob, Col: collationBinary, Flag: arg.Flag}, nil
+	}
+	col := collations.TypedCollation{
+		Collation:    call.collate,
+		Coercibility: collations.CoerceCoercible,
+		Repertoire:   collations.RepertoireASCII,
+	}
+	return ctype{Type: sqltypes.Text, Col: col, Flag: arg.Flag}, nil
+}
+
+func newEvalPoint(x, y float64) *evalBytes {
+	return newEvalGeometry(&sqltypes.Geom{
+		Type:   sqltypes.GeomPoint,
+		Coords: []sqltypes.Coord{{X: x, Y: y}},
+	})
+}
+
+func (call *builtinPoint) eval(env *ExpressionEnv) (eval, error) {
+	x, y, err := call.arg2(env)
+	if err != nil {
+		return nil, err
+	}
+	if x == nil || y == nil {
+		return nil, nil
+	}
+	fx, _ := evalToFloat(x)
+	fy, _ := evalToFloat(y)
+	return newEvalPoint(fx.f, fy.f), nil
+}
+
+func (call *builtinPoint) typeof(env *ExpressionEnv, fields []*querypb.Field) (sqltypes.Type, typeFlag) {
+	_, f1 := call.Arguments[0].typeof(env, fields)
+	_, f2 := call.Arguments[1].typeof(env, fields)
+	return sqltypes.Geometry, f1 | f2
+}
+
+func (call *builtinPoint) compile(c *compiler) (ctype, error) {
+	x, err := call.Arguments[0].compile(c)
+	if err != nil {
+		return ctype{}, err
+	}
+	y, err := call.Arguments[1].compile(c)
+	if err != nil {
+		return ctype{}, err
+	}
+
+	skip := c.compileNullCheck2(x, y)
+	_ = c.compileToFloat(x, 2)
+	_ = c.compileToFloat(y, 1)
+	c.asm.Fn_POINT()
+	c.asm.jumpDestination(skip)
+	return ctype{Type: sqltypes.Geometry, Col: collationBinary, Flag: x.Flag | y.Flag}, nil
+}
+
+func (call *builtinPointCoord) coord(e eval) (eval, error) {
+	fname := strings.ToLower(call.Method)
+	g, err := evalToGeom(e, fname)
+	if err != nil {
+		return nil, err
+	}
+	if g.Type != sqltypes.GeomPoint {
+		return nil, errGeomInvalid(fname)
+	}
+	// the coordinates are stored in longitude-latitude order, but the X
+	// coordinate of a WGS 84 point is its first axis, the latitude
+	c := g.Coords[0]
+	if call.y != (g.SRID == sridWGS84) {
+		return newEvalFloat(c.Y), nil
+	}
+	return newEvalFloat(c.X), nil
+}
+
+func (call *builtinPointCoord) eval(env *ExpressionEnv) (eval, error) {
+	arg, err := call.arg1(env)
+	if err != nil {
+		return nil, err
+	}
+	if arg == nil {
+		return nil, nil
+	}
+	return call.coord(arg)
+}
+
+func (call *builtinPointCoord) typeof(env *ExpressionEnv, fields []*querypb.Field) (sqltypes.Type, typeFlag) {
+	_, f := call.Arguments[0].typeof(env, fields)
+	return sqltypes.Float64, f
+}
+
+func (call *builtinPointCoord) compile(c *compiler) (ctype, error) {
+	arg, err := call.Arguments[0].compile(c)
+	if err != nil {
+		return ctype{}, err
+	}
+
+	skip := c.compileNullCheck1(arg)
+	c.asm.Fn_ST_XY(call)
+	c.asm.jumpDestination(skip)
+	return ctype{Type: sqltypes.Float64, Col: collationNumeric, Flag: arg.Flag}, nil
+}
+
+// geomPoints returns the positions of a POINT or MULTIPOINT geometry.
+func geomPoints(g *sqltypes.Geom) ([]sqltypes.Coord, bool) {
+	switch g.Type {
+	case sqltypes.GeomPoint:
+		return g.Coords, true
+	case sqltypes.GeomMultiPoint:
+		points := make([]sqltypes.Coord, 0, len(g.Members))
+		for _, m := range g.Members {
+			points = append(points, m.Coords[0])
+		}
+		return points, true
+	}
+	return nil, false
+}
+
+// haversine returns the great-circle distance between two positions given
+// as longitude-latitude degrees, using the same formula as MySQL.
+func haversine(p1, p2 sqltypes.Coord, radius float64) float64 {
+	const d2r = math.Pi / 180
+	hav := func(theta float64) float64 {
+		s := math.Sin(theta / 2)
+		return s * s
+	}
+	lon1, lat1 := p1.X*d2r, p1.Y*d2r
+	lon2, lat2 := p2.X*d2r, p2.Y*d2r
+	a := hav(lat2-lat1) + math.Cos(lat1)*math.Cos(lat2)*hav(lon2-lon1)
+	if a > 1 {
+		a = 1
+	}
+	return radius * 2 * math.Asin(math.Sqrt(a))
+}
+
+func (call *builtinDistanceSphere) distance(e1, e2 eval, radius float64) (eval, error) {
+	const fname = "st_distance_sphere"
+	g1, err := evalToGeom(e1, fname)
+	if err != nil {
+		return nil, err
+	}
+	g2, err := evalToGeom(e2, fname)
+	if err != nil {
+		return nil, err
+	}
+	if g1.SRID != g2.SRID {
+		return nil, errGeomDifferentSRIDs(fname, g1.SRID, g2.SRID)
+	}
+
+	points1, ok1 := geomPoints(g1)
+	points2, ok2 := geomPoints(g2)
+	if !ok1 || !ok2 {
+		return nil, errGeomUnsupportedArguments(fname)
+	}
+	if radius <= 0 {
+		return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "Invalid radius provided to function %s: Radius must be greater than zero.", fname)
+	}
+	for _, points := range [][]sqltypes.Coord{points1, points2} {
+		for _, p := range points {
+			if err := checkLongLat(p, fname); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	dist := math.Inf(1)
+	for _, p1 := range points1 {
+		for _, p2 := range points2 {
+			if d := haversine(p1, p2, radius); d < dist {
+				dist = d
+			}
+		}
+	}
+	return newEvalFloat(dist), nil
+}
+
+func (call *builtinDistanceSphere) eval(env *ExpressionEnv) (eval, error) {
+	g1, g2, err := call.arg2(env)
+	if err != nil {
+		return nil, err
+	}
+	var radius eval = newEvalFloat(earthRadius)
+	if len(call.Arguments) > 2 {
+		radius, err = call.Arguments[2].eval(env)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if g1 == nil || g2 == nil || radius == nil {
+		return nil, nil
+	}
+	r, _ := evalToFloat(radius)
+	return call.distance(g1, g2, r.f)
+}
+
+func (call *builtinDistanceSphere) typeof(env *ExpressionEnv, fields []*querypb.Field) (sqltypes.Type, typeFlag) {
+	var f typeFlag
+	for _, arg := range call.Arguments {
+		_, af := arg.typeof(env, fields)
+		f |= af
+	}
+	return sqltypes.Float64, f
+}
+
+func (call *builtinDistanceSphere) compile(c *compiler) (ctype, error) {
+	g1, err := call.Arguments[0].compile(c)
+	if err != nil {
+		return ctype{}, err
+	}
+	g2, err := call.Arguments[1].compile(c)
+	if err != nil {
+		return ctype{}, err
+	}
+
+	var skip *jump
+	flag := g1.Flag | g2.Flag
+	if len(call.Arguments) > 2 {
+		radius, err := call.Arguments[2].compile(c)
+		if err != nil {
+			return ctype{}, err
+		}
+		skip = c.compileNullCheck3(g1, g2, radius)
+		_ = c.compileToFloat(radius, 1)
+		flag |= radius.Flag
+	} else {
+		skip = c.compileNullCheck2(g1, g2)
+	}
+
+	c.asm.Fn_ST_DISTANCE_SPHERE(call, len(call.Arguments) > 2)
+	c.asm.jumpDestination(skip)
+	return ctype{Type: sqltypes.Float64, Col: collationNumeric, Flag: flag}, nil
+}
+
+type geomLocation int8
+
+const (
+	geomExterior geomLocation = iota
+	geomBoundary
+	geomInterior
+)
+
+func onSegment(p, a, b sqltypes.Coord) bool {
+	cross := (b.X-a.X)*(p.Y-a.Y) - (b.Y-a.Y)*(p.X-a.X)
+	return cross == 0 &&
+		p.X >= math.Min(a.X, b.X) && p.X <= math.Max(a.X, b.X) &&
+		p.Y >= math.Min(a.Y, b.Y) && p.Y <= math.Max(a.Y, b.Y)
+}
+
+func locateInRing(p sqltypes.Coord, ring []sqltypes.Coord) geomLocation {
+	inside := false
+	for i := 1; i < len(ring); i++ {
+		a, b := ring[i-1], ring[i]
+		if onSegment(p, a, b) {
+			return geomBoundary
+		}
+		if (a.Y > p.Y) != (b.Y > p.Y) && p.X < (b.X-a.X)*(p.Y-a.Y)/(b.Y-a.Y)+a.X {
+			inside = !inside
+		}
+	}
+	if inside {
+		return geomInterior
+	}
+	return geomExterior
+}
+
+func locateInPolygon(p sqltypes.Coord, rings [][]sqltypes.Coord) geomLocation {
+	if loc := locateInRing(p, rings[0]); loc != geomInterior {
+		return loc
+	}
+	for _, hole := range rings[1:] {
+		switch locateInRing(p, hole) {
+		case geomBoundary:
+			return geomBoundary
+		case geomInterior:
+			return geomExterior
+		}
+	}
+	return geomInterior
+}
+
+func locateInLineString(p sqltypes.Coord, line []sqltypes.Coord) geomLocation {
+	for i := 1; i < len(line); i++ {
+		if onSegment(p, line[i-1], line[i]) {
+			first, last := line[0], line[len(line)-1]
+			if first != last && (p == first || p == last) {
+				return geomBoundary
+			}
+			return geomInterior
+		}
+	}
+	return geomExterior
+}
+
+// locatePoint returns where a position lies relative to the given geometry.
+// It returns false if the geometry's type is not supported.
+func locatePoint(p sqltypes.Coord, g *sqltypes.Geom) (geomLocation, bool) {
+	switch g.Type {
+	case sqltypes.GeomPoint, sqltypes.GeomMultiPoint:
+		points, _ := geomPoints(g)
+		for _, q := range points {
+			if p == q {
+				return geomInterior, true
+			}
+		}
+		return geomExterior, true
+	case sqltypes.GeomLineString:
+		return locateInLineString(p, g.Coords), true
+	case sqltypes.GeomPolygon:
+		return locateInPolygon(p, g.Rings), true
+	case sqltypes.GeomMultiPolygon:
+		loc := geomExterior
+		for _, m := range g.Members {
+			if l := locateInPolygon(p, m.Rings); l > loc {
+				loc = l
+			}
+		}
+		return loc, true
+	}
+	return geomExterior, false
+}
+
+// geomContains returns whether g1 contains g2: no point of g2 lies in the
+// exterior of g1, and at least one point of g2 lies in the interior of g1.
+// Only point-like geometries are supported for g2; it returns false as its
+// second value for any combination of types that is not supported.
+func geomContains(g1, g2 *sqltypes.Geom) (bool, bool) {
+	points, ok := geomPoints(g2)
+	if !ok {
+		return false, false
+	}
+	interior := false
+	for _, p := range points {
+		loc, ok := locatePoint(p, g1)
+		if !ok {
+			return false, false
+		}
+		switch loc {
+		case geomExterior:
+			return false, true
+		case geomInterior:
+			interior = true
+		}
+	}
+	return interior, true
+}
+
+func (call *builtinGeomContains) contains(e1, e2 eval) (eval, error) {
+	fname := strings.ToLower(call.Method)
+	g1, err := evalToGeom(e1, fname)
+	if err != nil {
+		return nil, err
+	}
+	g2, err := evalToGeom(e2, fname)
+	if err != nil {
+		return nil, err
+	}
+	if g1.SRID != g2.SRID {
+		return nil, errGeomDifferentSRIDs(fname, g1.SRID, g2.SRID)
+	}
+	if g1.SRID != 0 {
+		return nil, vterrors.Errorf(vtrpcpb.Code_UNIMPLEMENTED, "function %s is not supported for geographic spatial reference systems", fname)
+	}
+
+	var res, ok bool
+	if call.within {
+		res, ok = geomContains(g2, g1)
+	} else {
+		res, ok = geomContains(g1, g2)
+	}
+	if !ok {
+		return nil, vterrors.Errorf(vtrpcpb.Code_UNIMPLEMENTED, "function %s is not supported for %s and %s arguments", fname, g1.Type, g2.Type)
+	}
+	return newEvalBool(res), nil
+}
+
+func (call *builtinGeomContains) eval(env *ExpressionEnv) (eval, error) {
+	g1, g2, err := call.arg2(env)
+	if err != nil {
+		return nil, err
+	}
+	if g1 == nil || g2 == nil {
+		return nil, nil
+	}
+	return call.contains(g1, g2)
+}
+
+func (call *builtinGeomContains) typeof(env *ExpressionEnv, fields []*querypb.Field) (sqltypes.Type, typeFlag) {
+	_, f1 := call.Arguments[0].typeof(env, fields)
+	_, f2 := call.Arguments[1].typeof(env, fields)
+	return sqltypes.Int64, f1 | f2 | flagIsBoolean
+}
+
+func (call *builtinGeomContains) compile(c *compiler) (ctype, error) {
+	g1, err := call.Arguments[0].compile(c)
+	if err != nil {
+		return ctype{}, err
+	}
+	g2, err := call.Arguments[1].compile(c)
+	if err != nil {
+		return ctype{}, err
+	}
+
+	skip := c.compileNullCheck2(g1, g2)
+	c.asm.Fn_ST_CONTAINS(call)
+	c.asm.jumpDestination(skip)
+	return ctype{Type: sqltypes.Int64, Col: collationNumeric, Flag: g1.Flag | g2.Flag | flagIsBoolean}, nil
+}
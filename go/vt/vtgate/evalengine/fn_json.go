@@ -58,6 +58,25 @@ type (
 	builtinJSONKeys struct {
 		CallExpr
 	}
+
+	builtinJSONModify struct {
+		CallExpr
+		mode json.Transformation
+	}
+
+	builtinJSONMerge struct {
+		CallExpr
+		patch bool
+	}
+
+	builtinJSONContains struct {
+		CallExpr
+	}
+
+	builtinJSONSearch struct {
+		CallExpr
+		collate collations.ID
+	}
 )
 
 var _ Expr = (*builtinJSONExtract)(nil)
@@ -68,6 +87,10 @@ var _ Expr = (*builtinJSONDepth)(nil)
 var _ Expr = (*builtinJSONLength)(nil)
 var _ Expr = (*builtinJSONContainsPath)(nil)
 var _ Expr = (*builtinJSONKeys)(nil)
+var _ Expr = (*builtinJSONModify)(nil)
+var _ Expr = (*builtinJSONMerge)(nil)
+var _ Expr = (*builtinJSONContains)(nil)
+var _ Expr = (*builtinJSONSearch)(nil)
 
 var errInvalidPathForTransform = vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "In this situation, path expressions may not contain the * and ** tokens or an array range.")
 
@@ -550,3 +573,464 @@ func (call *builtinJSONKeys) compile(c *compiler) (ctype, error) {
 	c.asm.Fn_JSON_KEYS(jp)
 	return ctype{Type: sqltypes.TypeJSON, Flag: flagNullable, Col: collationJSON}, nil
 }
+
+// intoJSONTransformPath parses a path argument for one of the JSON modification
+// functions, which do not allow wildcards in their paths.
+func intoJSONTransformPath(e eval) (*json.Path, error) {
+	jp, err := intoJSONPath(e)
+	if err != nil {
+		return nil, err
+	}
+	if jp.ContainsWildcards() {
+		return nil, errInvalidPathForTransform
+	}
+	return jp, nil
+}
+
+// jsonConstantPaths returns the parsed paths for the given arguments if all
+// of them are non-NULL constants. Otherwise, it returns false and the calling
+// function cannot be compiled with static paths.
+func (c *compiler) jsonConstantPaths(args []Expr, transform bool) ([]*json.Path, bool, error) {
+	paths := make([]*json.Path, 0, len(args))
+	for _, arg := range args {
+		if lit, ok := arg.(*Literal); !ok || lit.inner == nil {
+			return nil, false, nil
+		}
+		jp, err := c.jsonExtractPath(arg)
+		if err != nil {
+			return nil, false, err
+		}
+		if transform && jp.ContainsWildcards() {
+			return nil, false, errInvalidPathForTransform
+		}
+		paths = append(paths, jp)
+	}
+	return paths, true, nil
+}
+
+// pathArgs returns the path arguments of the modification function; all of
+// them take a document followed by either paths, or pairs of paths and values.
+func (call *builtinJSONModify) pathArgs() []Expr {
+	if call.mode == json.Remove {
+		return call.Arguments[1:]
+	}
+	var paths []Expr
+	for i := 1; i < len(call.Arguments); i += 2 {
+		paths = append(paths, call.Arguments[i])
+	}
+	return paths
+}
+
+func (call *builtinJSONModify) modify(doc eval, paths []*json.Path, values []eval) (eval, error) {
+	if doc == nil {
+		return nil, nil
+	}
+	j, err := intoJSON(call.Method, doc)
+	if err != nil {
+		return nil, err
+	}
+	for i, jp := range paths {
+		var value *json.Value
+		if call.mode != json.Remove {
+			value, err = argToJSON(values[i])
+			if err != nil {
+				return nil, err
+			}
+		}
+		j, err = json.Transform(call.mode, j, jp, value)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return j, nil
+}
+
+func (call *builtinJSONModify) eval(env *ExpressionEnv) (eval, error) {
+	args, err := call.args(env)
+	if err != nil {
+		return nil, err
+	}
+	if args[0] == nil {
+		return nil, nil
+	}
+
+	var paths []*json.Path
+	var values []eval
+	step := 2
+	if call.mode == json.Remove {
+		step = 1
+	}
+	for i := 1; i < len(args); i += step {
+		if args[i] == nil {
+			return nil, nil
+		}
+		jp, err := intoJSONTransformPath(args[i])
+		if err != nil {
+			return nil, err
+		}
+		paths = append(paths, jp)
+		if step == 2 {
+			values = append(values, args[i+1])
+		}
+	}
+	return call.modify(args[0], paths, values)
+}
+
+func (call *builtinJSONModify) typeof(env *ExpressionEnv, fields []*querypb.Field) (sqltypes.Type, typeFlag) {
+	_, f := call.Arguments[0].typeof(env, fields)
+	return sqltypes.TypeJSON, f | flagNullable
+}
+
+func (call *builtinJSONModify) compile(c *compiler) (ctype, error) {
+	paths, ok, err := c.jsonConstantPaths(call.pathArgs(), true)
+	if err != nil {
+		return ctype{}, err
+	}
+	if !ok {
+		return ctype{}, c.unsupported(call)
+	}
+
+	doc, err := call.Arguments[0].compile(c)
+	if err != nil {
+		return ctype{}, err
+	}
+	var values int
+	if call.mode != json.Remove {
+		for i := 2; i < len(call.Arguments); i += 2 {
+			tt, err := call.Arguments[i].compile(c)
+			if err != nil {
+				return ctype{}, err
+			}
+			if _, err := c.compileArgToJSON(tt, 1); err != nil {
+				return ctype{}, err
+			}
+			values++
+		}
+	}
+
+	c.asm.Fn_JSON_MODIFY(call, paths, values)
+	return ctype{Type: sqltypes.TypeJSON, Flag: doc.Flag | flagNullable, Col: collationJSON}, nil
+}
+
+func (call *builtinJSONMerge) merge(docs []eval) (eval, error) {
+	var merged *json.Value
+	for _, doc := range docs {
+		if doc == nil {
+			return nil, nil
+		}
+		j, err := intoJSON(call.Method, doc)
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case merged == nil:
+			merged = j
+		case call.patch:
+			merged = json.MergePatch(merged, j)
+		default:
+			merged = json.MergePreserve(merged, j)
+		}
+	}
+	return merged, nil
+}
+
+func (call *builtinJSONMerge) eval(env *ExpressionEnv) (eval, error) {
+	args, err := call.args(env)
+	if err != nil {
+		return nil, err
+	}
+	return call.merge(args)
+}
+
+func (call *builtinJSONMerge) typeof(env *ExpressionEnv, fields []*querypb.Field) (sqltypes.Type, typeFlag) {
+	var f typeFlag
+	for _, arg := range call.Arguments {
+		_, f1 := arg.typeof(env, fields)
+		f |= f1
+	}
+	return sqltypes.TypeJSON, f
+}
+
+func (call *builtinJSONMerge) compile(c *compiler) (ctype, error) {
+	var f typeFlag
+	for _, arg := range call.Arguments {
+		tt, err := arg.compile(c)
+		if err != nil {
+			return ctype{}, err
+		}
+		f |= tt.Flag
+	}
+	c.asm.Fn_JSON_MERGE(call, len(call.Arguments))
+	return ctype{Type: sqltypes.TypeJSON, Flag: f, Col: collationJSON}, nil
+}
+
+// jsonContains returns whether the candidate is contained in the target
+// document, following the rules of MySQL's JSON_CONTAINS: a scalar is
+// contained in an equal scalar, or in an array that contains it; an array
+// is contained in an array if all its elements are contained in it; and an
+// object is contained in an object if all its members are contained in the
+// members of the target with the same keys.
+func jsonContains(target, candidate *json.Value) (bool, error) {
+	switch target.Type() {
+	case json.TypeArray:
+		elems, _ := target.Array()
+		if cs, ok := candidate.Array(); ok {
+			for _, c := range cs {
+				found, err := jsonContainedInAny(elems, c)
+				if err != nil || !found {
+					return false, err
+				}
+			}
+			return true, nil
+		}
+		return jsonContainedInAny(elems, candidate)
+	case json.TypeObject:
+		obj, _ := target.Object()
+		cobj, ok := candidate.Object()
+		if !ok {
+			return false, nil
+		}
+		for _, key := range cobj.Keys() {
+			member := obj.Get(key)
+			if member == nil {
+				return false, nil
+			}
+			found, err := jsonContains(member, cobj.Get(key))
+			if err != nil || !found {
+				return false, err
+			}
+		}
+		return true, nil
+	default:
+		switch candidate.Type() {
+		case json.TypeArray, json.TypeObject:
+			return false, nil
+		}
+		cmp, err := compareJSONValue(target, candidate)
+		return cmp == 0, err
+	}
+}
+
+func jsonContainedInAny(targets []*json.Value, candidate *json.Value) (bool, error) {
+	for _, t := range targets {
+		found, err := jsonContains(t, candidate)
+		if err != nil || found {
+			return found, err
+		}
+	}
+	return false, nil
+}
+
+func builtin_JSON_CONTAINS(target, candidate eval, jp *json.Path) (eval, error) {
+	tj, err := intoJSON("JSON_CONTAINS", target)
+	if err != nil {
+		return nil, err
+	}
+	cj, err := intoJSON("JSON_CONTAINS", candidate)
+	if err != nil {
+		return nil, err
+	}
+	if jp != nil {
+		var match *json.Value
+		jp.Match(tj, true, func(v *json.Value) { match = v })
+		if match == nil {
+			return nil, nil
+		}
+		tj = match
+	}
+	found, err := jsonContains(tj, cj)
+	if err != nil {
+		return nil, err
+	}
+	return newEvalBool(found), nil
+}
+
+func (call *builtinJSONContains) eval(env *ExpressionEnv) (eval, error) {
+	args, err := call.args(env)
+	if err != nil {
+		return nil, err
+	}
+	for _, arg := range args {
+		if arg == nil {
+			return nil, nil
+		}
+	}
+
+	var jp *json.Path
+	if len(args) > 2 {
+		jp, err = intoJSONTransformPath(args[2])
+		if err != nil {
+			return nil, err
+		}
+	}
+	return builtin_JSON_CONTAINS(args[0], args[1], jp)
+}
+
+func (call *builtinJSONContains) typeof(env *ExpressionEnv, fields []*querypb.Field) (sqltypes.Type, typeFlag) {
+	_, f1 := call.Arguments[0].typeof(env, fields)
+	_, f2 := call.Arguments[1].typeof(env, fields)
+	return sqltypes.Int64, f1 | f2 | flagIsBoolean | flagNullable
+}
+
+func (call *builtinJSONContains) compile(c *compiler) (ctype, error) {
+	paths, ok, err := c.jsonConstantPaths(call.Arguments[2:], true)
+	if err != nil {
+		return ctype{}, err
+	}
+	if !ok {
+		return ctype{}, c.unsupported(call)
+	}
+	var jp *json.Path
+	if len(paths) > 0 {
+		jp = paths[0]
+	}
+
+	target, err := call.Arguments[0].compile(c)
+	if err != nil {
+		return ctype{}, err
+	}
+	candidate, err := call.Arguments[1].compile(c)
+	if err != nil {
+		return ctype{}, err
+	}
+
+	skip := c.compileNullCheck2(target, candidate)
+	c.asm.Fn_JSON_CONTAINS(jp)
+	c.asm.jumpDestination(skip)
+	return ctype{Type: sqltypes.Int64, Col: collationNumeric, Flag: flagIsBoolean | flagNullable}, nil
+}
+
+var errJSONSearchEscape = vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "Incorrect arguments to ESCAPE")
+
+// jsonSearchEscape returns the escape character for JSON_SEARCH. Like in
+// MySQL, a missing, NULL or empty escape argument defaults to a backslash.
+func jsonSearchEscape(e eval) (rune, error) {
+	if e == nil {
+		return '\\', nil
+	}
+	esc := []rune(evalToBinary(e).string())
+	switch len(esc) {
+	case 0:
+		return '\\', nil
+	case 1:
+		return esc[0], nil
+	default:
+		return 0, errJSONSearchEscape
+	}
+}
+
+func (call *builtinJSONSearch) search(doc, search eval, match jsonMatch, escape rune, paths []*json.Path) (eval, error) {
+	j, err := intoJSON("JSON_SEARCH", doc)
+	if err != nil {
+		return nil, err
+	}
+
+	col := call.collate
+	if b, ok := search.(*evalBytes); ok && b.isVarChar() && isEncodingJSONSafe(b.col.Collation) {
+		col = b.col.Collation
+	}
+	pattern, err := evalToVarchar(search, col, true)
+	if err != nil {
+		return nil, err
+	}
+
+	wc := col.Get().Wildcard(pattern.bytes, 0, 0, escape)
+	found := json.Search(j, paths, match == jsonMatchOne, wc.Match)
+
+	switch len(found) {
+	case 0:
+		return nil, nil
+	case 1:
+		return json.NewString(found[0]), nil
+	default:
+		ary := make([]*json.Value, 0, len(found))
+		for _, p := range found {
+			ary = append(ary, json.NewString(p))
+		}
+		return json.NewArray(ary), nil
+	}
+}
+
+func (call *builtinJSONSearch) eval(env *ExpressionEnv) (eval, error) {
+	args, err := call.args(env)
+	if err != nil {
+		return nil, err
+	}
+	if args[0] == nil || args[1] == nil || args[2] == nil {
+		return nil, nil
+	}
+
+	match, err := intoOneOrAll("JSON_SEARCH", evalToBinary(args[1]).string())
+	if err != nil {
+		return nil, err
+	}
+
+	var escape = '\\'
+	var paths []*json.Path
+	if len(args) > 3 {
+		escape, err = jsonSearchEscape(args[3])
+		if err != nil {
+			return nil, err
+		}
+		for _, arg := range args[4:] {
+			if arg == nil {
+				return nil, nil
+			}
+			jp, err := intoJSONPath(arg)
+			if err != nil {
+				return nil, err
+			}
+			paths = append(paths, jp)
+		}
+	}
+	return call.search(args[0], args[2], match, escape, paths)
+}
+
+func (call *builtinJSONSearch) typeof(env *ExpressionEnv, fields []*querypb.Field) (sqltypes.Type, typeFlag) {
+	_, f := call.Arguments[0].typeof(env, fields)
+	return sqltypes.TypeJSON, f | flagNullable
+}
+
+func (call *builtinJSONSearch) compile(c *compiler) (ctype, error) {
+	if lit, ok := call.Arguments[1].(*Literal); !ok || lit.inner == nil {
+		return ctype{}, c.unsupported(call)
+	}
+	match, err := c.jsonExtractOneOrAll("JSON_SEARCH", call.Arguments[1])
+	if err != nil {
+		return ctype{}, err
+	}
+
+	var escape = '\\'
+	var paths []*json.Path
+	if len(call.Arguments) > 3 {
+		lit, ok := call.Arguments[3].(*Literal)
+		if !ok {
+			return ctype{}, c.unsupported(call)
+		}
+		escape, err = jsonSearchEscape(lit.inner)
+		if err != nil {
+			return ctype{}, err
+		}
+		paths, ok, err = c.jsonConstantPaths(call.Arguments[4:], false)
+		if err != nil {
+			return ctype{}, err
+		}
+		if !ok {
+			return ctype{}, c.unsupported(call)
+		}
+	}
+
+	doc, err := call.Arguments[0].compile(c)
+	if err != nil {
+		return ctype{}, err
+	}
+	search, err := call.Arguments[2].compile(c)
+	if err != nil {
+		return ctype{}, err
+	}
+
+	skip := c.compileNullCheck2(doc, search)
+	c.asm.Fn_JSON_SEARCH(call, match, escape, paths)
+	c.asm.jumpDestination(skip)
+	return ctype{Type: sqltypes.TypeJSON, Flag: flagNullable, Col: collationJSON}, nil
+}
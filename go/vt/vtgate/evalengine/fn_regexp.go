@@ -0,0 +1,934 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package evalengine
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"regexp/syntax"
+	"strings"
+	"sync/atomic"
+	"unicode"
+	"unicode/utf8"
+
+	"vitess.io/vitess/go/mysql/collations"
+	"vitess.io/vitess/go/mysql/collations/charset"
+	"vitess.io/vitess/go/sqltypes"
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+	"vitess.io/vitess/go/vt/vterrors"
+)
+
+// The regular expression functions in MySQL are implemented on top of ICU.
+// We use Go's regexp package instead, which shares ICU's syntax for all the
+// common constructs. The character classes that Go only matches in ASCII
+// (\d, \w, \s and the POSIX classes) are rewritten into their Unicode
+// definitions before compiling a pattern. Patterns using ICU features that
+// Go does not support (backreferences, lookaround, possessive quantifiers,
+// word boundaries) are rejected during translation when the pattern is
+// constant; when the pattern is only known at runtime, evaluating them
+// fails with an unsupported error instead.
+
+type (
+	builtinRegexpLike struct {
+		CallExpr
+		Negate  bool
+		collate collations.ID
+		cache   regexpCache
+	}
+
+	builtinRegexpInstr struct {
+		CallExpr
+		collate collations.ID
+		cache   regexpCache
+	}
+
+	builtinRegexpSubstr struct {
+		CallExpr
+		collate collations.ID
+		cache   regexpCache
+	}
+
+	builtinRegexpReplace struct {
+		CallExpr
+		collate collations.ID
+		cache   regexpCache
+	}
+)
+
+var _ Expr = (*builtinRegexpLike)(nil)
+var _ Expr = (*builtinRegexpInstr)(nil)
+var _ Expr = (*builtinRegexpSubstr)(nil)
+var _ Expr = (*builtinRegexpReplace)(nil)
+
+var (
+	errRegexpIndexOutOfBounds     = vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "Index out of bounds in regular expression search.")
+	errRegexpInvalidCaptureGroup  = vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "A capture group has an invalid name.")
+	errRegexpMismatchedParen      = vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "Mismatched parenthesis in regular expression.")
+	errRegexpMissingCloseBracket  = vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "The regular expression contains an unclosed bracket expression.")
+	errRegexpBadEscapeSequence    = vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "Unrecognized escape sequence in regular expression.")
+	errRegexpBadInterval          = vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "Incorrect description of a {min,max} interval.")
+	errRegexpIllegalArgument      = vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "Illegal argument to a regular expression.")
+	errRegexpMissingRepeatOperand = vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "Syntax error in regular expression.")
+	errRegexpUnsupported          = vterrors.Errorf(vtrpcpb.Code_UNIMPLEMENTED, "unsupported: regular expression construct that cannot be evaluated in vtgate")
+)
+
+func errRegexpCharacterSetMismatch(subject, pattern collations.ID, fname string) error {
+	return vterrors.NewErrorf(vtrpcpb.Code_INVALID_ARGUMENT, vterrors.CharacterSetMismatch, "Character set '%s' cannot be used in conjunction with '%s' in call to %s.", subject.Get().Name(), pattern.Get().Name(), fname)
+}
+
+func errRegexpWrongArguments(fname string) error {
+	return vterrors.NewErrorf(vtrpcpb.Code_INVALID_ARGUMENT, vterrors.WrongArguments, "Incorrect arguments to %s.", fname)
+}
+
+// regexpError translates an error from Go's regexp parser into
+// the error that MySQL returns for the same pattern.
+func regexpError(err error) error {
+	var serr *syntax.Error
+	if !errors.As(err, &serr) {
+		return errRegexpIllegalArgument
+	}
+	switch serr.Code {
+	case syntax.ErrMissingParen, syntax.ErrUnexpectedParen:
+		return errRegexpMismatchedParen
+	case syntax.ErrMissingBracket:
+		return errRegexpMissingCloseBracket
+	case syntax.ErrInvalidEscape, syntax.ErrInvalidCharRange, syntax.ErrInvalidCharClass:
+		return errRegexpBadEscapeSequence
+	case syntax.ErrInvalidRepeatSize:
+		return errRegexpBadInterval
+	case syntax.ErrMissingRepeatArgument:
+		return errRegexpMissingRepeatOperand
+	default:
+		return errRegexpIllegalArgument
+	}
+}
+
+// regexpUnsupported returns true if the given constant pattern cannot be
+// evaluated with Go's regexp package because it uses ICU syntax that Go does
+// not support, as opposed to being an invalid pattern in MySQL too.
+func regexpUnsupported(pattern Expr) bool {
+	lit, ok := pattern.(*Literal)
+	if !ok {
+		return false
+	}
+	b, ok := lit.inner.(*evalBytes)
+	if !ok {
+		return false
+	}
+	translated, err := regexpTranslate(string(b.bytes))
+	if err != nil {
+		return true
+	}
+	_, err = syntax.Parse(translated, syntax.Perl)
+	return regexpSyntaxUnsupported(err)
+}
+
+// regexpSyntaxUnsupported returns true if the given error from Go's regexp
+// parser is caused by ICU syntax that Go does not support.
+func regexpSyntaxUnsupported(err error) bool {
+	var serr *syntax.Error
+	if !errors.As(err, &serr) {
+		return false
+	}
+	switch serr.Code {
+	case syntax.ErrInvalidPerlOp, syntax.ErrInvalidRepeatOp, syntax.ErrInvalidNamedCapture:
+		return true
+	case syntax.ErrInvalidEscape:
+		// Backreferences such as \1 or \k<name>
+		return strings.HasPrefix(serr.Expr, `\k`) || (len(serr.Expr) == 2 && serr.Expr[1] >= '1' && serr.Expr[1] <= '9')
+	default:
+		return false
+	}
+}
+
+// The Unicode definitions of the character classes of ICU, which Go's regexp
+// package only matches in ASCII. They are the contents of a bracket expression,
+// without the brackets.
+var (
+	regexpAlphabetic = `\p{L}\p{Nl}` + regexpRanges(unicode.Other_Alphabetic)
+	regexpWord       = regexpAlphabetic + `\p{M}\p{Nd}\p{Pc}\x{200C}\x{200D}`
+	regexpSpace      = regexpRanges(unicode.White_Space)
+
+	// regexpPosixClasses are the POSIX classes that can be used inside
+	// a bracket expression, such as [[:alpha:]]. [:graph:] and [:print:]
+	// are defined by ICU in terms of set differences, so they are not
+	// translated.
+	regexpPosixClasses = map[string]string{
+		"alpha":  regexpAlphabetic,
+		"alnum":  regexpAlphabetic + `\p{Nd}`,
+		"blank":  `\p{Zs}\t`,
+		"cntrl":  `\p{Cc}`,
+		"digit":  `\p{Nd}`,
+		"lower":  `\p{Ll}` + regexpRanges(unicode.Other_Lowercase),
+		"punct":  `\p{P}`,
+		"space":  regexpSpace,
+		"upper":  `\p{Lu}` + regexpRanges(unicode.Other_Uppercase),
+		"xdigit": `\p{Nd}` + regexpRanges(unicode.Hex_Digit),
+	}
+)
+
+// regexpRanges renders a Unicode range table as the contents of a bracket expression.
+func regexpRanges(table *unicode.RangeTable) string {
+	var b strings.Builder
+	write := func(lo, hi, stride uint32) {
+		if stride == 1 {
+			fmt.Fprintf(&b, `\x{%X}-\x{%X}`, lo, hi)
+			return
+		}
+		for c := lo; c <= hi; c += stride {
+			fmt.Fprintf(&b, `\x{%X}`, c)
+		}
+	}
+	for _, r := range table.R16 {
+		write(uint32(r.Lo), uint32(r.Hi), uint32(r.Stride))
+	}
+	for _, r := range table.R32 {
+		write(r.Lo, r.Hi, r.Stride)
+	}
+	return b.String()
+}
+
+// regexpTranslate rewrites the character classes of an ICU pattern that Go's
+// regexp package matches in ASCII only into their Unicode definitions. It returns
+// errRegexpUnsupported for the ICU constructs that cannot be expressed in Go:
+// word boundaries, nested sets and the classes that would need a negation
+// inside a bracket expression.
+func regexpTranslate(pattern string) (string, error) {
+	var out strings.Builder
+	var inClass bool
+
+	for i := 0; i < len(pattern); i++ {
+		c := pattern[i]
+		switch {
+		case c == '\\' && i+1 < len(pattern):
+			i++
+			switch e := pattern[i]; e {
+			case 'Q':
+				// Everything up to \E is a literal
+				end := strings.Index(pattern[i+1:], `\E`)
+				if end < 0 {
+					out.WriteString(pattern[i-1:])
+					return out.String(), nil
+				}
+				out.WriteString(pattern[i-1 : i+end+3])
+				i += end + 2
+			case 'd':
+				out.WriteString(`\p{Nd}`)
+			case 'D':
+				out.WriteString(`\P{Nd}`)
+			case 'w', 's', 'W', 'S':
+				class := regexpWord
+				if e == 's' || e == 'S' {
+					class = regexpSpace
+				}
+				negate := e == 'W' || e == 'S'
+				switch {
+				case inClass && negate:
+					return "", errRegexpUnsupported
+				case inClass:
+					out.WriteString(class)
+				case negate:
+					out.WriteString("[^" + class + "]")
+				default:
+					out.WriteString("[" + class + "]")
+				}
+			case 'b', 'B':
+				return "", errRegexpUnsupported
+			default:
+				out.WriteByte(c)
+				out.WriteByte(e)
+			}
+		case c == '[' && !inClass:
+			inClass = true
+			out.WriteByte(c)
+			if i+1 < len(pattern) && pattern[i+1] == '^' {
+				out.WriteByte('^')
+				i++
+			}
+			// A closing bracket right at the start is a literal
+			if i+1 < len(pattern) && pattern[i+1] == ']' {
+				out.WriteByte(']')
+				i++
+			}
+		case c == '[':
+			// Inside a bracket expression, ICU only allows POSIX classes and nested sets
+			if !strings.HasPrefix(pattern[i:], "[:") {
+				return "", errRegexpUnsupported
+			}
+			end := strings.Index(pattern[i+2:], ":]")
+			if end < 0 {
+				return "", errRegexpUnsupported
+			}
+			class, ok := regexpPosixClasses[pattern[i+2:i+2+end]]
+			if !ok {
+				return "", errRegexpUnsupported
+			}
+			out.WriteString(class)
+			i += end + 3
+		case c == ']' && inClass:
+			inClass = false
+			out.WriteByte(c)
+		default:
+			out.WriteByte(c)
+		}
+	}
+	return out.String(), nil
+}
+
+// regexpCache keeps the last regular expression compiled for an expression,
+// so that patterns which are the same for every row are only compiled once.
+// It is safe for concurrent use.
+type regexpCache struct {
+	last atomic.Pointer[compiledRegexp]
+}
+
+type compiledRegexp struct {
+	pattern string
+	re      *regexp.Regexp
+}
+
+func (cache *regexpCache) compile(pattern string) (*regexp.Regexp, error) {
+	if last := cache.last.Load(); last != nil && last.pattern == pattern {
+		return last.re, nil
+	}
+	translated, err := regexpTranslate(pattern)
+	if err != nil {
+		return nil, err
+	}
+	re, err := regexp.Compile(translated)
+	if regexpSyntaxUnsupported(err) {
+		return nil, errRegexpUnsupported
+	}
+	if err != nil {
+		return nil, regexpError(err)
+	}
+	cache.last.Store(&compiledRegexp{pattern: pattern, re: re})
+	return re, nil
+}
+
+// regexpCaseInsensitive returns whether a regular expression matching
+// strings in the given collation is case-insensitive by default.
+func regexpCaseInsensitive(col collations.ID) bool {
+	return strings.HasSuffix(col.Get().Name(), "_ci")
+}
+
+// regexpFlags parses the match_type argument of the regular expression
+// functions into the equivalent flags for Go's regexp package.
+func regexpFlags(fname string, matchType *evalBytes, caseInsensitive bool) (string, error) {
+	var multiline, dotall bool
+	if matchType != nil {
+		for _, c := range matchType.bytes {
+			switch c {
+			case 'c':
+				caseInsensitive = false
+			case 'i':
+				caseInsensitive = true
+			case 'm':
+				multiline = true
+			case 'n':
+				dotall = true
+			case 'u':
+				// Unix-only line endings; Go only ever treats \n as a line ending
+			default:
+				return "", errRegexpWrongArguments(fname)
+			}
+		}
+	}
+
+	if !caseInsensitive && !multiline && !dotall {
+		return "", nil
+	}
+	var flags strings.Builder
+	flags.WriteString("(?")
+	if caseInsensitive {
+		flags.WriteByte('i')
+	}
+	if multiline {
+		flags.WriteByte('m')
+	}
+	if dotall {
+		flags.WriteByte('s')
+	}
+	flags.WriteByte(')')
+	return flags.String(), nil
+}
+
+// regexpInput is the subject of a regular expression function, prepared for
+// matching with Go's regexp package.
+type regexpInput struct {
+	re   *regexp.Regexp
+	text []byte
+	// binary is set when the subject is a binary string; in this case
+	// positions are counted in bytes instead of characters.
+	binary bool
+	col    collations.TypedCollation
+	tt     sqltypes.Type
+}
+
+func (cache *regexpCache) prepare(fname string, subject, pattern, matchType *evalBytes) (*regexpInput, error) {
+	// Binary strings can only be matched against binary patterns, and the other way around
+	if (subject.col.Collation == collations.CollationBinaryID) != (pattern.col.Collation == collations.CollationBinaryID) {
+		return nil, errRegexpCharacterSetMismatch(subject.col.Collation, pattern.col.Collation, fname)
+	}
+
+	s, p, col, err := mergeAndCoerceCollations(subject, pattern)
+	if err != nil {
+		return nil, err
+	}
+	subject = s.(*evalBytes)
+	pattern = p.(*evalBytes)
+
+	flags, err := regexpFlags(fname, matchType, regexpCaseInsensitive(col))
+	if err != nil {
+		return nil, err
+	}
+
+	text, binary, err := regexpToUTF8(subject)
+	if err != nil {
+		return nil, err
+	}
+	pat, _, err := regexpToUTF8(pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	re, err := cache.compile(flags + string(pat))
+	if err != nil {
+		return nil, err
+	}
+	return &regexpInput{
+		re:     re,
+		text:   text,
+		binary: binary,
+		col:    subject.col,
+		tt:     subject.SQLType(),
+	}, nil
+}
+
+func regexpToUTF8(b *evalBytes) ([]byte, bool, error) {
+	switch cs := b.col.Collation.Get().Charset().(type) {
+	case charset.Charset_binary:
+		return b.bytes, true, nil
+	case charset.Charset_utf8mb4, charset.Charset_utf8mb3:
+		return b.bytes, false, nil
+	default:
+		out, err := charset.Convert(nil, charset.Charset_utf8mb4{}, b.bytes, cs)
+		return out, false, err
+	}
+}
+
+// result converts a string produced from the input back into the
+// charset of the input.
+func (in *regexpInput) result(out []byte) (*evalBytes, error) {
+	if !in.binary {
+		cs := in.col.Collation.Get().Charset()
+		switch cs.(type) {
+		case charset.Charset_utf8mb4, charset.Charset_utf8mb3:
+		default:
+			var err error
+			out, err = charset.ConvertFromUTF8(nil, cs, out)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	tt := sqltypes.VarChar
+	if sqltypes.IsBinary(in.tt) {
+		tt = sqltypes.VarBinary
+	}
+	return newEvalRaw(tt, out, in.col), nil
+}
+
+// offset returns the byte offset of the 1-based character position pos.
+// A position right after the last character is valid.
+func (in *regexpInput) offset(pos int64) (int, error) {
+	if pos < 1 {
+		return 0, errRegexpIndexOutOfBounds
+	}
+	if in.binary {
+		if pos > int64(len(in.text))+1 {
+			return 0, errRegexpIndexOutOfBounds
+		}
+		return int(pos - 1), nil
+	}
+	offset := 0
+	for n := int64(1); n < pos; n++ {
+		if offset >= len(in.text) {
+			return 0, errRegexpIndexOutOfBounds
+		}
+		_, size := utf8.DecodeRune(in.text[offset:])
+		offset += size
+	}
+	return offset, nil
+}
+
+// position returns the 1-based character position of the given byte offset.
+func (in *regexpInput) position(offset int) int64 {
+	if in.binary {
+		return int64(offset) + 1
+	}
+	return int64(utf8.RuneCount(in.text[:offset])) + 1
+}
+
+// find returns the byte offsets of the given occurrence of the pattern,
+// searching from the byte offset start.
+func (in *regexpInput) find(start int, occurrence int64) []int {
+	if occurrence < 1 {
+		occurrence = 1
+	}
+	matches := in.re.FindAllIndex(in.text[start:], int(occurrence))
+	if int64(len(matches)) < occurrence {
+		return nil
+	}
+	m := matches[occurrence-1]
+	return []int{m[0] + start, m[1] + start}
+}
+
+func regexpLike(cache *regexpCache, subject, pattern, matchType *evalBytes) (bool, error) {
+	in, err := cache.prepare("regexp_like", subject, pattern, matchType)
+	if err != nil {
+		return false, err
+	}
+	return in.re.Match(in.text), nil
+}
+
+func regexpInstr(cache *regexpCache, subject, pattern *evalBytes, pos, occurrence, returnOption int64, matchType *evalBytes) (int64, error) {
+	in, err := cache.prepare("regexp_instr", subject, pattern, matchType)
+	if err != nil {
+		return 0, err
+	}
+	if returnOption != 0 && returnOption != 1 {
+		return 0, vterrors.NewErrorf(vtrpcpb.Code_INVALID_ARGUMENT, vterrors.WrongArguments, "Incorrect arguments to regexp_instr: return_option must be 1 or 0.")
+	}
+	start, err := in.offset(pos)
+	if err != nil {
+		return 0, err
+	}
+	m := in.find(start, occurrence)
+	if m == nil {
+		return 0, nil
+	}
+	return in.position(m[returnOption]), nil
+}
+
+func regexpSubstr(cache *regexpCache, subject, pattern *evalBytes, pos, occurrence int64, matchType *evalBytes) (*evalBytes, error) {
+	in, err := cache.prepare("regexp_substr", subject, pattern, matchType)
+	if err != nil {
+		return nil, err
+	}
+	start, err := in.offset(pos)
+	if err != nil {
+		return nil, err
+	}
+	m := in.find(start, occurrence)
+	if m == nil {
+		return nil, nil
+	}
+	return in.result(in.text[m[0]:m[1]])
+}
+
+func regexpReplace(cache *regexpCache, subject, pattern, repl *evalBytes, pos, occurrence int64, matchType *evalBytes) (*evalBytes, error) {
+	in, err := cache.prepare("regexp_replace", subject, pattern, matchType)
+	if err != nil {
+		return nil, err
+	}
+	start, err := in.offset(pos)
+	if err != nil {
+		return nil, err
+	}
+	r, err := evalToVarchar(repl, in.col.Collation, true)
+	if err != nil {
+		return nil, err
+	}
+	replacement, _, err := regexpToUTF8(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// An occurrence of 0 (the default) replaces all matches
+	n := -1
+	if occurrence > 0 {
+		n = int(occurrence)
+	}
+	matches := in.re.FindAllSubmatchIndex(in.text[start:], n)
+	if occurrence > 0 {
+		if int64(len(matches)) < occurrence {
+			matches = nil
+		} else {
+			matches = matches[occurrence-1:]
+		}
+	}
+
+	out := append([]byte(nil), in.text[:start]...)
+	last := start
+	for _, m := range matches {
+		for i := range m {
+			if m[i] >= 0 {
+				m[i] += start
+			}
+		}
+		out = append(out, in.text[last:m[0]]...)
+		out, err = regexpExpand(out, replacement, in.text, m, in.re)
+		if err != nil {
+			return nil, err
+		}
+		last = m[1]
+	}
+	out = append(out, in.text[last:]...)
+	return in.result(out)
+}
+
+// regexpExpand appends the replacement string to dst, substituting capture
+// groups using ICU's syntax: $n refers to the n-th group, ${name} to a named
+// group, and a backslash escapes the following character.
+func regexpExpand(dst, repl, src []byte, match []int, re *regexp.Regexp) ([]byte, error) {
+	group := func(n int) []byte {
+		if match[2*n] < 0 {
+			return nil
+		}
+		return src[match[2*n]:match[2*n+1]]
+	}
+
+	for i := 0; i < len(repl); i++ {
+		switch c := repl[i]; c {
+		case '\\':
+			i++
+			if i < len(repl) {
+				dst = append(dst, repl[i])
+			}
+		case '$':
+			i++
+			if i >= len(repl) {
+				return nil, errRegexpInvalidCaptureGroup
+			}
+			if repl[i] == '{' {
+				end := i + 1
+				for end < len(repl) && repl[end] != '}' {
+					end++
+				}
+				if end >= len(repl) {
+					return nil, errRegexpInvalidCaptureGroup
+				}
+				n := re.SubexpIndex(string(repl[i+1 : end]))
+				if n < 0 {
+					return nil, errRegexpInvalidCaptureGroup
+				}
+				dst = append(dst, group(n)...)
+				i = end
+				continue
+			}
+			if repl[i] < '0' || repl[i] > '9' {
+				return nil, errRegexpInvalidCaptureGroup
+			}
+			n := int(repl[i] - '0')
+			if n > re.NumSubexp() {
+				return nil, errRegexpIndexOutOfBounds
+			}
+			// Consume as many digits as still form a valid group number
+			for i+1 < len(repl) && repl[i+1] >= '0' && repl[i+1] <= '9' {
+				next := n*10 + int(repl[i+1]-'0')
+				if next > re.NumSubexp() {
+					break
+				}
+				n = next
+				i++
+			}
+			dst = append(dst, group(n)...)
+		default:
+			dst = append(dst, c)
+		}
+	}
+	return dst, nil
+}
+
+// regexpText returns the given argument as a string for use in
+// a regular expression function.
+func regexpText(e eval, col collations.ID) (*evalBytes, error) {
+	if b, ok := e.(*evalBytes); ok {
+		return b, nil
+	}
+	return evalToVarchar(e, col, true)
+}
+
+// regexpArgs evaluates all the arguments of a regular expression function.
+// It returns false if any of them is NULL.
+func regexpArgs(call *CallExpr, env *ExpressionEnv) ([]eval, bool, error) {
+	args, err := call.args(env)
+	if err != nil {
+		return nil, false, err
+	}
+	for _, arg := range args {
+		if arg == nil {
+			return nil, false, nil
+		}
+	}
+	return args, true, nil
+}
+
+// regexpOptionalArgs returns the optional integer arguments of a regular
+// expression function at the given indexes, or their default values when
+// they are not present.
+func regexpOptionalArgs(args []eval, defaults ...int64) []int64 {
+	res := make([]int64, 0, len(defaults))
+	for i, def := range defaults {
+		if i+2 < len(args) {
+			res = append(res, evalToInt64(args[i+2]).i)
+		} else {
+			res = append(res, def)
+		}
+	}
+	return res
+}
+
+func (r *builtinRegexpLike) eval(env *ExpressionEnv) (eval, error) {
+	args, ok, err := regexpArgs(&r.CallExpr, env)
+	if !ok || err != nil {
+		return nil, err
+	}
+	subject, err := regexpText(args[0], r.collate)
+	if err != nil {
+		return nil, err
+	}
+	pattern, err := regexpText(args[1], r.collate)
+	if err != nil {
+		return nil, err
+	}
+	var matchType *evalBytes
+	if len(args) > 2 {
+		matchType, err = regexpText(args[2], r.collate)
+		if err != nil {
+			return nil, err
+		}
+	}
+	match, err := regexpLike(&r.cache, subject, pattern, matchType)
+	if err != nil {
+		return nil, err
+	}
+	return newEvalBool(match == !r.Negate), nil
+}
+
+func (r *builtinRegexpLike) typeof(env *ExpressionEnv, fields []*querypb.Field) (sqltypes.Type, typeFlag) {
+	var f typeFlag
+	for _, arg := range r.Arguments {
+		_, af := arg.typeof(env, fields)
+		f |= af
+	}
+	return sqltypes.Int64, f | flagIsBoolean
+}
+
+func (r *builtinRegexpLike) compile(c *compiler) (ctype, error) {
+	_, skips, f, err := c.compileRegexpArgs(r.Arguments, 2, 2, r.collate)
+	if err != nil {
+		return ctype{}, err
+	}
+	c.asm.Fn_REGEXP_LIKE(r, len(r.Arguments))
+	c.asm.jumpDestination(skips...)
+	return ctype{Type: sqltypes.Int64, Col: collationNumeric, Flag: f | flagIsBoolean}, nil
+}
+
+func (r *builtinRegexpInstr) eval(env *ExpressionEnv) (eval, error) {
+	args, ok, err := regexpArgs(&r.CallExpr, env)
+	if !ok || err != nil {
+		return nil, err
+	}
+	subject, err := regexpText(args[0], r.collate)
+	if err != nil {
+		return nil, err
+	}
+	pattern, err := regexpText(args[1], r.collate)
+	if err != nil {
+		return nil, err
+	}
+	opts := regexpOptionalArgs(args, 1, 1, 0)
+	var matchType *evalBytes
+	if len(args) > 5 {
+		matchType, err = regexpText(args[5], r.collate)
+		if err != nil {
+			return nil, err
+		}
+	}
+	pos, err := regexpInstr(&r.cache, subject, pattern, opts[0], opts[1], opts[2], matchType)
+	if err != nil {
+		return nil, err
+	}
+	return newEvalInt64(pos), nil
+}
+
+func (r *builtinRegexpInstr) typeof(env *ExpressionEnv, fields []*querypb.Field) (sqltypes.Type, typeFlag) {
+	var f typeFlag
+	for _, arg := range r.Arguments {
+		_, af := arg.typeof(env, fields)
+		f |= af
+	}
+	return sqltypes.Int64, f
+}
+
+func (r *builtinRegexpInstr) compile(c *compiler) (ctype, error) {
+	_, skips, f, err := c.compileRegexpArgs(r.Arguments, 2, 5, r.collate)
+	if err != nil {
+		return ctype{}, err
+	}
+	c.asm.Fn_REGEXP_INSTR(r, len(r.Arguments))
+	c.asm.jumpDestination(skips...)
+	return ctype{Type: sqltypes.Int64, Col: collationNumeric, Flag: f}, nil
+}
+
+func (r *builtinRegexpSubstr) eval(env *ExpressionEnv) (eval, error) {
+	args, ok, err := regexpArgs(&r.CallExpr, env)
+	if !ok || err != nil {
+		return nil, err
+	}
+	subject, err := regexpText(args[0], r.collate)
+	if err != nil {
+		return nil, err
+	}
+	pattern, err := regexpText(args[1], r.collate)
+	if err != nil {
+		return nil, err
+	}
+	opts := regexpOptionalArgs(args, 1, 1)
+	var matchType *evalBytes
+	if len(args) > 4 {
+		matchType, err = regexpText(args[4], r.collate)
+		if err != nil {
+			return nil, err
+		}
+	}
+	res, err := regexpSubstr(&r.cache, subject, pattern, opts[0], opts[1], matchType)
+	if res == nil || err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (r *builtinRegexpSubstr) typeof(env *ExpressionEnv, fields []*querypb.Field) (sqltypes.Type, typeFlag) {
+	tt, _ := r.Arguments[0].typeof(env, fields)
+	for _, arg := range r.Arguments[1:] {
+		arg.typeof(env, fields)
+	}
+	if sqltypes.IsBinary(tt) {
+		return sqltypes.VarBinary, flagNullable
+	}
+	return sqltypes.VarChar, flagNullable
+}
+
+func (r *builtinRegexpSubstr) compile(c *compiler) (ctype, error) {
+	subject, skips, _, err := c.compileRegexpArgs(r.Arguments, 2, 4, r.collate)
+	if err != nil {
+		return ctype{}, err
+	}
+	c.asm.Fn_REGEXP_SUBSTR(r, len(r.Arguments))
+	c.asm.jumpDestination(skips...)
+	return regexpResultType(subject, c.cfg.Collation), nil
+}
+
+func (r *builtinRegexpReplace) eval(env *ExpressionEnv) (eval, error) {
+	args, ok, err := regexpArgs(&r.CallExpr, env)
+	if !ok || err != nil {
+		return nil, err
+	}
+	subject, err := regexpText(args[0], r.collate)
+	if err != nil {
+		return nil, err
+	}
+	pattern, err := regexpText(args[1], r.collate)
+	if err != nil {
+		return nil, err
+	}
+	repl, err := regexpText(args[2], r.collate)
+	if err != nil {
+		return nil, err
+	}
+	opts := regexpOptionalArgs(args[1:], 1, 0)
+	var matchType *evalBytes
+	if len(args) > 5 {
+		matchType, err = regexpText(args[5], r.collate)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return regexpReplace(&r.cache, subject, pattern, repl, opts[0], opts[1], matchType)
+}
+
+func (r *builtinRegexpReplace) typeof(env *ExpressionEnv, fields []*querypb.Field) (sqltypes.Type, typeFlag) {
+	tt, f := r.Arguments[0].typeof(env, fields)
+	for _, arg := range r.Arguments[1:] {
+		_, af := arg.typeof(env, fields)
+		f |= af
+	}
+	if sqltypes.IsBinary(tt) {
+		return sqltypes.VarBinary, f
+	}
+	return sqltypes.VarChar, f
+}
+
+func (r *builtinRegexpReplace) compile(c *compiler) (ctype, error) {
+	subject, skips, f, err := c.compileRegexpArgs(r.Arguments, 3, 5, r.collate)
+	if err != nil {
+		return ctype{}, err
+	}
+	c.asm.Fn_REGEXP_REPLACE(r, len(r.Arguments))
+	c.asm.jumpDestination(skips...)
+	ct := regexpResultType(subject, c.cfg.Collation)
+	ct.Flag = f
+	return ct, nil
+}
+
+func regexpResultType(subject ctype, collate collations.ID) ctype {
+	switch {
+	case sqltypes.IsBinary(subject.Type):
+		return ctype{Type: sqltypes.VarBinary, Col: collationBinary, Flag: flagNullable}
+	case subject.isTextual():
+		return ctype{Type: sqltypes.VarChar, Col: subject.Col, Flag: flagNullable}
+	default:
+		return ctype{Type: sqltypes.VarChar, Col: defaultCoercionCollation(collate), Flag: flagNullable}
+	}
+}
+
+// compileRegexpArgs compiles all the arguments of a regular expression function
+// and returns the type of its subject. The first textArgs arguments and the
+// match type argument at matchTypeIdx are converted to text; all the other
+// arguments are converted to INT64.
+func (c *compiler) compileRegexpArgs(args []Expr, textArgs, matchTypeIdx int, collate collations.ID) (ctype, []*jump, typeFlag, error) {
+	var skips []*jump
+	var f typeFlag
+	cts := make([]ctype, 0, len(args))
+
+	for i, arg := range args {
+		ct, err := arg.compile(c)
+		if err != nil {
+			return ctype{}, nil, 0, err
+		}
+		f |= ct.Flag
+		cts = append(cts, ct)
+		skips = append(skips, c.compileNullCheckArg(ct, i))
+	}
+
+	for i, ct := range cts {
+		offset := len(cts) - i
+		if i < textArgs || i == matchTypeIdx {
+			if !ct.isTextual() {
+				c.asm.Convert_xc(offset, sqltypes.VarChar, collate, 0, false)
+			}
+		} else {
+			_ = c.compileToInt64(ct, offset)
+		}
+	}
+	return cts[0], skips, f, nil
+}
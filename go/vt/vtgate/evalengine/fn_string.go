@@ -18,6 +18,10 @@ package evalengine
 
 import (
 	"bytes"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
 
 	"vitess.io/vitess/go/mysql/collations"
 	"vitess.io/vitess/go/mysql/collations/charset"
@@ -868,14 +872,37 @@ func (call builtinTrim) eval(env *ExpressionEnv) (eval, error) {
 
 	switch call.trim {
 	case sqlparser.LeadingTrimType:
-		return newEvalText(bytes.TrimPrefix(text.bytes, pat.bytes), text.col), nil
+		return newEvalText(trimPrefixAll(text.bytes, pat.bytes), text.col), nil
 	case sqlparser.TrailingTrimType:
-		return newEvalText(bytes.TrimSuffix(text.bytes, pat.bytes), text.col), nil
+		return newEvalText(trimSuffixAll(text.bytes, pat.bytes), text.col), nil
 	default:
-		return newEvalText(bytes.TrimPrefix(bytes.TrimSuffix(text.bytes, pat.bytes), pat.bytes), text.col), nil
+		return newEvalText(trimPrefixAll(trimSuffixAll(text.bytes, pat.bytes), pat.bytes), text.col), nil
 	}
 }
 
+// trimPrefixAll removes all the leading repetitions of pat from str, which
+// is how MySQL trims a string with an explicit pattern.
+func trimPrefixAll(str, pat []byte) []byte {
+	if len(pat) == 0 {
+		return str
+	}
+	for bytes.HasPrefix(str, pat) {
+		str = str[len(pat):]
+	}
+	return str
+}
+
+// trimSuffixAll removes all the trailing repetitions of pat from str.
+func trimSuffixAll(str, pat []byte) []byte {
+	if len(pat) == 0 {
+		return str
+	}
+	for bytes.HasSuffix(str, pat) {
+		str = str[:len(str)-len(pat)]
+	}
+	return str
+}
+
 func (call builtinTrim) typeof(env *ExpressionEnv, fields []*querypb.Field) (sqltypes.Type, typeFlag) {
 	_, f1 := call.Arguments[0].typeof(env, fields)
 	return sqltypes.VarChar, f1
@@ -1230,3 +1257,1298 @@ func (call *builtinConcatWs) compile(c *compiler) (ctype, error) {
 
 	return ctype{Type: tt, Flag: args[0].Flag, Col: tc}, nil
 }
+
+type (
+	builtinSubstring struct {
+		CallExpr
+		collate collations.ID
+	}
+
+	builtinSubstringIndex struct {
+		CallExpr
+		collate collations.ID
+	}
+
+	builtinLocate struct {
+		CallExpr
+		collate collations.ID
+	}
+
+	builtinReplace struct {
+		CallExpr
+		collate collations.ID
+	}
+
+	builtinReverse struct {
+		CallExpr
+		collate collations.ID
+	}
+
+	builtinInsert struct {
+		CallExpr
+		collate collations.ID
+	}
+
+	builtinField struct {
+		CallExpr
+		collate collations.ID
+	}
+
+	builtinElt struct {
+		CallExpr
+		collate collations.ID
+	}
+
+	builtinFindInSet struct {
+		CallExpr
+		collate collations.ID
+	}
+
+	builtinSpace struct {
+		CallExpr
+		collate collations.ID
+	}
+
+	builtinSoundex struct {
+		CallExpr
+		collate collations.ID
+	}
+
+	builtinFormat struct {
+		CallExpr
+		collate collations.ID
+	}
+
+	builtinChar struct {
+		CallExpr
+		collate collations.ID
+	}
+)
+
+var _ Expr = (*builtinSubstring)(nil)
+var _ Expr = (*builtinSubstringIndex)(nil)
+var _ Expr = (*builtinLocate)(nil)
+var _ Expr = (*builtinReplace)(nil)
+var _ Expr = (*builtinReverse)(nil)
+var _ Expr = (*builtinInsert)(nil)
+var _ Expr = (*builtinField)(nil)
+var _ Expr = (*builtinElt)(nil)
+var _ Expr = (*builtinFindInSet)(nil)
+var _ Expr = (*builtinSpace)(nil)
+var _ Expr = (*builtinSoundex)(nil)
+var _ Expr = (*builtinFormat)(nil)
+var _ Expr = (*builtinChar)(nil)
+
+// aggregateStringCollation returns the collation in which a string function
+// operates on the given arguments. If all the arguments are numeric, the
+// default collation for the connection is used instead.
+func aggregateStringCollation(collate collations.ID, cols ...collations.TypedCollation) (collations.TypedCollation, error) {
+	local := collations.Local()
+	var ca collationAggregation
+	for _, col := range cols {
+		if err := ca.add(local, col); err != nil {
+			return collations.TypedCollation{}, err
+		}
+	}
+	tc := ca.result()
+	if tc.Coercibility == collations.CoerceNumeric {
+		tc = defaultCoercionCollation(collate)
+	}
+	return tc, nil
+}
+
+// compileToCollation converts the string argument at the given stack offset
+// into the given collation, if it's not already textual in that collation.
+func (c *compiler) compileToCollation(ct ctype, offset int, tc collations.TypedCollation) {
+	if !ct.isTextual() || ct.Col.Collation != tc.Collation {
+		c.asm.Convert_xce(offset, sqltypes.VarChar, tc.Collation)
+	}
+}
+
+// charsetIndex returns the byte offset of the first occurrence of sub in str
+// that starts at a character boundary in the given charset, or -1 if there is
+// no such occurrence.
+func charsetIndex(cs charset.Charset, str, sub []byte) int {
+	if cs.MaxWidth() == 1 {
+		return bytes.Index(str, sub)
+	}
+	for pos := 0; pos+len(sub) <= len(str); {
+		if bytes.HasPrefix(str[pos:], sub) {
+			return pos
+		}
+		_, size := cs.DecodeRune(str[pos:])
+		if size < 1 {
+			size = 1
+		}
+		pos += size
+	}
+	return -1
+}
+
+func substring(cs charset.Charset, str []byte, pos, length int64) []byte {
+	if length <= 0 {
+		return nil
+	}
+	n := int64(charset.Length(cs, str))
+	if pos < 0 {
+		pos += n
+	} else {
+		pos--
+	}
+	if pos < 0 || pos >= n {
+		return nil
+	}
+	if length > n-pos {
+		length = n - pos
+	}
+	return charset.Slice(cs, str, int(pos), int(pos+length))
+}
+
+func (call *builtinSubstring) eval(env *ExpressionEnv) (eval, error) {
+	args, err := call.args(env)
+	if err != nil {
+		return nil, err
+	}
+	for _, arg := range args {
+		if arg == nil {
+			return nil, nil
+		}
+	}
+
+	text, ok := args[0].(*evalBytes)
+	if !ok {
+		text, err = evalToVarchar(args[0], call.collate, true)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	pos := evalToInt64(args[1]).i
+	length := int64(math.MaxInt64)
+	if len(args) > 2 {
+		length = evalToInt64(args[2]).i
+	}
+
+	cs := text.col.Collation.Get().Charset()
+	return newEvalText(substring(cs, text.bytes, pos, length), text.col), nil
+}
+
+func (call *builtinSubstring) typeof(env *ExpressionEnv, fields []*querypb.Field) (sqltypes.Type, typeFlag) {
+	var f typeFlag
+	for _, arg := range call.Arguments {
+		_, af := arg.typeof(env, fields)
+		f |= af
+	}
+	return sqltypes.VarChar, f
+}
+
+func (call *builtinSubstring) compile(c *compiler) (ctype, error) {
+	str, err := call.Arguments[0].compile(c)
+	if err != nil {
+		return ctype{}, err
+	}
+
+	pos, err := call.Arguments[1].compile(c)
+	if err != nil {
+		return ctype{}, err
+	}
+
+	args := len(call.Arguments)
+	var skip *jump
+	var l ctype
+	if args > 2 {
+		l, err = call.Arguments[2].compile(c)
+		if err != nil {
+			return ctype{}, err
+		}
+		skip = c.compileNullCheck3(str, pos, l)
+	} else {
+		skip = c.compileNullCheck2(str, pos)
+	}
+
+	col := defaultCoercionCollation(c.cfg.Collation)
+	switch {
+	case str.isTextual():
+		col = str.Col
+	default:
+		c.asm.Convert_xc(args, sqltypes.VarChar, col.Collation, 0, false)
+	}
+	_ = c.compileToInt64(pos, args-1)
+	if args > 2 {
+		_ = c.compileToInt64(l, 1)
+	}
+
+	c.asm.Fn_SUBSTRING(col, args)
+	c.asm.jumpDestination(skip)
+	return ctype{Type: sqltypes.VarChar, Col: col, Flag: flagNullable}, nil
+}
+
+// substringIndex implements SUBSTRING_INDEX: it returns the part of str
+// before the count-th occurrence of delim, or after the count-th occurrence
+// from the right if count is negative. Like in MySQL, delimiters are matched
+// byte by byte and never overlap.
+func substringIndex(cs charset.Charset, str, delim []byte, count int64) []byte {
+	if len(str) == 0 || len(delim) == 0 || count == 0 {
+		return nil
+	}
+
+	var found []int
+	for offset := 0; ; {
+		idx := charsetIndex(cs, str[offset:], delim)
+		if idx < 0 {
+			break
+		}
+		found = append(found, offset+idx)
+		if count > 0 && int64(len(found)) == count {
+			return str[:offset+idx]
+		}
+		offset += idx + len(delim)
+	}
+
+	if count > 0 {
+		return str
+	}
+	n := int64(len(found)) + count
+	if n < 0 {
+		return str
+	}
+	return str[found[n]+len(delim):]
+}
+
+func (call *builtinSubstringIndex) eval(env *ExpressionEnv) (eval, error) {
+	str, delim, count, err := call.arg3(env)
+	if err != nil {
+		return nil, err
+	}
+	if str == nil || delim == nil || count == nil {
+		return nil, nil
+	}
+
+	tc, err := aggregateStringCollation(call.collate, evalCollation(str), evalCollation(delim))
+	if err != nil {
+		return nil, err
+	}
+	text, err := evalToVarchar(str, tc.Collation, true)
+	if err != nil {
+		return nil, err
+	}
+	d, err := evalToVarchar(delim, tc.Collation, true)
+	if err != nil {
+		return nil, err
+	}
+
+	cs := tc.Collation.Get().Charset()
+	return newEvalText(substringIndex(cs, text.bytes, d.bytes, evalToInt64(count).i), tc), nil
+}
+
+func (call *builtinSubstringIndex) typeof(env *ExpressionEnv, fields []*querypb.Field) (sqltypes.Type, typeFlag) {
+	_, f1 := call.Arguments[0].typeof(env, fields)
+	_, f2 := call.Arguments[1].typeof(env, fields)
+	_, f3 := call.Arguments[2].typeof(env, fields)
+	return sqltypes.VarChar, f1 | f2 | f3
+}
+
+func (call *builtinSubstringIndex) compile(c *compiler) (ctype, error) {
+	str, err := call.Arguments[0].compile(c)
+	if err != nil {
+		return ctype{}, err
+	}
+
+	delim, err := call.Arguments[1].compile(c)
+	if err != nil {
+		return ctype{}, err
+	}
+
+	count, err := call.Arguments[2].compile(c)
+	if err != nil {
+		return ctype{}, err
+	}
+
+	skip := c.compileNullCheck3(str, delim, count)
+
+	tc, err := aggregateStringCollation(c.cfg.Collation, str.Col, delim.Col)
+	if err != nil {
+		return ctype{}, err
+	}
+	c.compileToCollation(str, 3, tc)
+	c.compileToCollation(delim, 2, tc)
+	_ = c.compileToInt64(count, 1)
+
+	c.asm.Fn_SUBSTRING_INDEX(tc)
+	c.asm.jumpDestination(skip)
+	return ctype{Type: sqltypes.VarChar, Col: tc, Flag: flagNullable}, nil
+}
+
+// locate implements LOCATE: it returns the 1-based position of the first
+// occurrence of sub in str, starting the search at the given character
+// position, or 0 if sub cannot be found. Like in MySQL, every window of str
+// that starts at a character boundary and has the byte length of sub is
+// compared to sub using the collation.
+func locate(col collations.Collation, sub, str []byte, pos int64, hasPos bool) int64 {
+	cs := col.Charset()
+
+	var start int
+	var chars int64
+	if hasPos {
+		if pos <= 0 || pos > int64(len(str)) {
+			return 0
+		}
+		chars = pos - 1
+		start = len(charset.Slice(cs, str, 0, int(chars)))
+		if start+len(sub) > len(str) {
+			return 0
+		}
+	}
+	if len(sub) == 0 {
+		return int64(start) + 1
+	}
+
+	for start+len(sub) <= len(str) {
+		if col.Collate(str[start:start+len(sub)], sub, false) == 0 {
+			return chars + 1
+		}
+		_, size := cs.DecodeRune(str[start:])
+		if size < 1 {
+			size = 1
+		}
+		start += size
+		chars++
+	}
+	return 0
+}
+
+func (call *builtinLocate) eval(env *ExpressionEnv) (eval, error) {
+	args, err := call.args(env)
+	if err != nil {
+		return nil, err
+	}
+	for _, arg := range args {
+		if arg == nil {
+			return nil, nil
+		}
+	}
+
+	tc, err := aggregateStringCollation(call.collate, evalCollation(args[0]), evalCollation(args[1]))
+	if err != nil {
+		return nil, err
+	}
+	sub, err := evalToVarchar(args[0], tc.Collation, true)
+	if err != nil {
+		return nil, err
+	}
+	str, err := evalToVarchar(args[1], tc.Collation, true)
+	if err != nil {
+		return nil, err
+	}
+
+	var pos int64
+	if len(args) > 2 {
+		pos = evalToInt64(args[2]).i
+	}
+	return newEvalInt64(locate(tc.Collation.Get(), sub.bytes, str.bytes, pos, len(args) > 2)), nil
+}
+
+func (call *builtinLocate) typeof(env *ExpressionEnv, fields []*querypb.Field) (sqltypes.Type, typeFlag) {
+	var f typeFlag
+	for _, arg := range call.Arguments {
+		_, af := arg.typeof(env, fields)
+		f |= af
+	}
+	return sqltypes.Int64, f
+}
+
+func (call *builtinLocate) compile(c *compiler) (ctype, error) {
+	sub, err := call.Arguments[0].compile(c)
+	if err != nil {
+		return ctype{}, err
+	}
+
+	str, err := call.Arguments[1].compile(c)
+	if err != nil {
+		return ctype{}, err
+	}
+
+	args := len(call.Arguments)
+	var skip *jump
+	var pos ctype
+	if args > 2 {
+		pos, err = call.Arguments[2].compile(c)
+		if err != nil {
+			return ctype{}, err
+		}
+		skip = c.compileNullCheck3(sub, str, pos)
+	} else {
+		skip = c.compileNullCheck2(sub, str)
+	}
+
+	tc, err := aggregateStringCollation(c.cfg.Collation, sub.Col, str.Col)
+	if err != nil {
+		return ctype{}, err
+	}
+	c.compileToCollation(sub, args, tc)
+	c.compileToCollation(str, args-1, tc)
+	if args > 2 {
+		_ = c.compileToInt64(pos, 1)
+	}
+
+	c.asm.Fn_LOCATE(tc.Collation.Get(), args)
+	c.asm.jumpDestination(skip)
+	return ctype{Type: sqltypes.Int64, Col: collationNumeric, Flag: flagNullable}, nil
+}
+
+// replace implements REPLACE; like in MySQL, the matching is performed
+// byte by byte, so it's always case-sensitive.
+func replace(cs charset.Charset, str, from, to []byte) []byte {
+	if len(from) == 0 {
+		return str
+	}
+	var res []byte
+	for {
+		idx := charsetIndex(cs, str, from)
+		if idx < 0 {
+			break
+		}
+		res = append(res, str[:idx]...)
+		res = append(res, to...)
+		str = str[idx+len(from):]
+	}
+	if res == nil {
+		return str
+	}
+	return append(res, str...)
+}
+
+func (call *builtinReplace) eval(env *ExpressionEnv) (eval, error) {
+	str, from, to, err := call.arg3(env)
+	if err != nil {
+		return nil, err
+	}
+	if str == nil || from == nil || to == nil {
+		return nil, nil
+	}
+
+	tc, err := aggregateStringCollation(call.collate, evalCollation(str), evalCollation(from), evalCollation(to))
+	if err != nil {
+		return nil, err
+	}
+
+	var text [3]*evalBytes
+	for i, arg := range []eval{str, from, to} {
+		text[i], err = evalToVarchar(arg, tc.Collation, true)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	cs := tc.Collation.Get().Charset()
+	return newEvalText(replace(cs, text[0].bytes, text[1].bytes, text[2].bytes), tc), nil
+}
+
+func (call *builtinReplace) typeof(env *ExpressionEnv, fields []*querypb.Field) (sqltypes.Type, typeFlag) {
+	_, f1 := call.Arguments[0].typeof(env, fields)
+	_, f2 := call.Arguments[1].typeof(env, fields)
+	_, f3 := call.Arguments[2].typeof(env, fields)
+	return sqltypes.VarChar, f1 | f2 | f3
+}
+
+func (call *builtinReplace) compile(c *compiler) (ctype, error) {
+	str, err := call.Arguments[0].compile(c)
+	if err != nil {
+		return ctype{}, err
+	}
+
+	from, err := call.Arguments[1].compile(c)
+	if err != nil {
+		return ctype{}, err
+	}
+
+	to, err := call.Arguments[2].compile(c)
+	if err != nil {
+		return ctype{}, err
+	}
+
+	skip := c.compileNullCheck3(str, from, to)
+
+	tc, err := aggregateStringCollation(c.cfg.Collation, str.Col, from.Col, to.Col)
+	if err != nil {
+		return ctype{}, err
+	}
+	c.compileToCollation(str, 3, tc)
+	c.compileToCollation(from, 2, tc)
+	c.compileToCollation(to, 1, tc)
+
+	c.asm.Fn_REPLACE(tc)
+	c.asm.jumpDestination(skip)
+	return ctype{Type: sqltypes.VarChar, Col: tc, Flag: flagNullable}, nil
+}
+
+func reverse(cs charset.Charset, str []byte) []byte {
+	res := make([]byte, len(str))
+	end := len(res)
+	for len(str) > 0 {
+		_, size := cs.DecodeRune(str)
+		if size < 1 {
+			size = 1
+		}
+		end -= size
+		copy(res[end:], str[:size])
+		str = str[size:]
+	}
+	return res
+}
+
+func (call *builtinReverse) eval(env *ExpressionEnv) (eval, error) {
+	arg, err := call.arg1(env)
+	if err != nil {
+		return nil, err
+	}
+	if arg == nil {
+		return nil, nil
+	}
+
+	text, ok := arg.(*evalBytes)
+	if !ok {
+		text, err = evalToVarchar(arg, call.collate, true)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	cs := text.col.Collation.Get().Charset()
+	return newEvalText(reverse(cs, text.bytes), text.col), nil
+}
+
+func (call *builtinReverse) typeof(env *ExpressionEnv, fields []*querypb.Field) (sqltypes.Type, typeFlag) {
+	_, f := call.Arguments[0].typeof(env, fields)
+	return sqltypes.VarChar, f
+}
+
+func (call *builtinReverse) compile(c *compiler) (ctype, error) {
+	str, err := call.Arguments[0].compile(c)
+	if err != nil {
+		return ctype{}, err
+	}
+
+	skip := c.compileNullCheck1(str)
+
+	col := defaultCoercionCollation(c.cfg.Collation)
+	switch {
+	case str.isTextual():
+		col = str.Col
+	default:
+		c.asm.Convert_xc(1, sqltypes.VarChar, col.Collation, 0, false)
+	}
+
+	c.asm.Fn_REVERSE(col)
+	c.asm.jumpDestination(skip)
+	return ctype{Type: sqltypes.VarChar, Col: col, Flag: str.Flag}, nil
+}
+
+// insert implements INSERT: it replaces the length characters of str that
+// start at the 1-based position pos with newstr. If pos is out of range,
+// str is returned unchanged.
+func insert(cs charset.Charset, str []byte, pos, length int64, newstr []byte) []byte {
+	n := int64(charset.Length(cs, str))
+	if pos < 1 || pos > n {
+		return str
+	}
+	pos--
+	if length < 0 || length > n-pos {
+		length = n - pos
+	}
+
+	res := make([]byte, 0, len(str)+len(newstr))
+	res = append(res, charset.Slice(cs, str, 0, int(pos))...)
+	res = append(res, newstr...)
+	res = append(res, charset.Slice(cs, str, int(pos+length), int(n))...)
+	return res
+}
+
+func (call *builtinInsert) eval(env *ExpressionEnv) (eval, error) {
+	args, err := call.args(env)
+	if err != nil {
+		return nil, err
+	}
+	for _, arg := range args {
+		if arg == nil {
+			return nil, nil
+		}
+	}
+
+	tc, err := aggregateStringCollation(call.collate, evalCollation(args[0]), evalCollation(args[3]))
+	if err != nil {
+		return nil, err
+	}
+	str, err := evalToVarchar(args[0], tc.Collation, true)
+	if err != nil {
+		return nil, err
+	}
+	newstr, err := evalToVarchar(args[3], tc.Collation, true)
+	if err != nil {
+		return nil, err
+	}
+
+	cs := tc.Collation.Get().Charset()
+	pos := evalToInt64(args[1]).i
+	length := evalToInt64(args[2]).i
+	return newEvalText(insert(cs, str.bytes, pos, length, newstr.bytes), tc), nil
+}
+
+func (call *builtinInsert) typeof(env *ExpressionEnv, fields []*querypb.Field) (sqltypes.Type, typeFlag) {
+	var f typeFlag
+	for _, arg := range call.Arguments {
+		_, af := arg.typeof(env, fields)
+		f |= af
+	}
+	return sqltypes.VarChar, f
+}
+
+func (call *builtinInsert) compile(c *compiler) (ctype, error) {
+	args := make([]ctype, 0, len(call.Arguments))
+	skips := make([]*jump, 0, len(call.Arguments))
+	for i, arg := range call.Arguments {
+		a, err := arg.compile(c)
+		if err != nil {
+			return ctype{}, err
+		}
+		skips = append(skips, c.compileNullCheckArg(a, i))
+		args = append(args, a)
+	}
+
+	tc, err := aggregateStringCollation(c.cfg.Collation, args[0].Col, args[3].Col)
+	if err != nil {
+		return ctype{}, err
+	}
+	c.compileToCollation(args[0], 4, tc)
+	_ = c.compileToInt64(args[1], 3)
+	_ = c.compileToInt64(args[2], 2)
+	c.compileToCollation(args[3], 1, tc)
+
+	c.asm.Fn_INSERT(tc)
+	c.asm.jumpDestination(skips...)
+	return ctype{Type: sqltypes.VarChar, Col: tc, Flag: flagNullable}, nil
+}
+
+// fieldIndex implements FIELD: it returns the 1-based index of the first
+// argument in the rest of the arguments, or 0 if it cannot be found. Like
+// in MySQL, the arguments are compared as strings if all of them are strings,
+// as integers or decimals if all of them are integers or decimals, and as
+// floating point numbers otherwise.
+func fieldIndex(args []eval, collate collations.ID) (int64, error) {
+	if args[0] == nil {
+		return 0, nil
+	}
+
+	var (
+		textual = true
+		exact   = true
+		cols    = make([]collations.TypedCollation, 0, len(args))
+	)
+	for _, arg := range args {
+		switch arg.(type) {
+		case nil:
+			continue
+		case *evalInt64, *evalUint64, *evalDecimal:
+			textual = false
+		case *evalFloat:
+			textual = false
+			exact = false
+		default:
+			exact = false
+		}
+		cols = append(cols, evalCollation(arg))
+	}
+
+	if textual {
+		tc, err := aggregateStringCollation(collate, cols...)
+		if err != nil {
+			return 0, err
+		}
+		col := tc.Collation.Get()
+		str, err := evalToVarchar(args[0], tc.Collation, true)
+		if err != nil {
+			return 0, err
+		}
+		for i, arg := range args[1:] {
+			if arg == nil {
+				continue
+			}
+			other, err := evalToVarchar(arg, tc.Collation, true)
+			if err != nil {
+				return 0, err
+			}
+			if col.Collate(str.bytes, other.bytes, false) == 0 {
+				return int64(i + 1), nil
+			}
+		}
+		return 0, nil
+	}
+
+	toNumeric := func(e eval) eval {
+		if exact {
+			return e
+		}
+		if _, ok := e.(evalNumeric); ok {
+			return e
+		}
+		f, _ := evalToFloat(e)
+		return f
+	}
+
+	num := toNumeric(args[0])
+	for i, arg := range args[1:] {
+		if arg == nil {
+			continue
+		}
+		cmp, err := compareNumeric(num, toNumeric(arg))
+		if err != nil {
+			return 0, err
+		}
+		if cmp == 0 {
+			return int64(i + 1), nil
+		}
+	}
+	return 0, nil
+}
+
+func (call *builtinField) eval(env *ExpressionEnv) (eval, error) {
+	args, err := call.args(env)
+	if err != nil {
+		return nil, err
+	}
+	idx, err := fieldIndex(args, call.collate)
+	if err != nil {
+		return nil, err
+	}
+	return newEvalInt64(idx), nil
+}
+
+func (call *builtinField) typeof(env *ExpressionEnv, fields []*querypb.Field) (sqltypes.Type, typeFlag) {
+	for _, arg := range call.Arguments {
+		arg.typeof(env, fields)
+	}
+	return sqltypes.Int64, 0
+}
+
+func (call *builtinField) compile(c *compiler) (ctype, error) {
+	for _, arg := range call.Arguments {
+		if _, err := arg.compile(c); err != nil {
+			return ctype{}, err
+		}
+	}
+
+	c.asm.Fn_FIELD(len(call.Arguments), call.collate)
+	return ctype{Type: sqltypes.Int64, Col: collationNumeric}, nil
+}
+
+// elt implements ELT: it returns the n-th string in args, converted into
+// the given collation, or NULL if n is out of range.
+func elt(n eval, args []eval, tc collations.TypedCollation) (eval, error) {
+	if n == nil {
+		return nil, nil
+	}
+	idx := evalToInt64(n).i
+	if idx < 1 || idx > int64(len(args)) {
+		return nil, nil
+	}
+	arg := args[idx-1]
+	if arg == nil {
+		return nil, nil
+	}
+	text, err := evalToVarchar(arg, tc.Collation, true)
+	if err != nil {
+		return nil, err
+	}
+	return text, nil
+}
+
+func (call *builtinElt) eval(env *ExpressionEnv) (eval, error) {
+	args, err := call.args(env)
+	if err != nil {
+		return nil, err
+	}
+
+	cols := make([]collations.TypedCollation, 0, len(args)-1)
+	for _, arg := range args[1:] {
+		cols = append(cols, evalCollation(arg))
+	}
+	tc, err := aggregateStringCollation(call.collate, cols...)
+	if err != nil {
+		return nil, err
+	}
+	return elt(args[0], args[1:], tc)
+}
+
+func (call *builtinElt) typeof(env *ExpressionEnv, fields []*querypb.Field) (sqltypes.Type, typeFlag) {
+	for _, arg := range call.Arguments {
+		arg.typeof(env, fields)
+	}
+	return sqltypes.VarChar, flagNullable
+}
+
+func (call *builtinElt) compile(c *compiler) (ctype, error) {
+	cols := make([]collations.TypedCollation, 0, len(call.Arguments)-1)
+	for i, arg := range call.Arguments {
+		a, err := arg.compile(c)
+		if err != nil {
+			return ctype{}, err
+		}
+		if i > 0 && a.Type != sqltypes.Null {
+			cols = append(cols, a.Col)
+		}
+	}
+
+	tc, err := aggregateStringCollation(c.cfg.Collation, cols...)
+	if err != nil {
+		return ctype{}, err
+	}
+
+	c.asm.Fn_ELT(len(call.Arguments), tc)
+	return ctype{Type: sqltypes.VarChar, Col: tc, Flag: flagNullable}, nil
+}
+
+// findInSet implements FIND_IN_SET: it returns the 1-based index of str in
+// the comma-separated list strlist, using the given collation to compare the
+// elements, or 0 if str is not in the list. This is a port of MySQL's
+// implementation, including its handling of empty elements.
+func findInSet(col collations.Collation, str, strlist []byte) int64 {
+	if len(strlist) < len(str) {
+		return 0
+	}
+
+	cs := col.Charset()
+	var position int64
+	var last rune
+	begin, end := 0, 0
+	for {
+		r, size := cs.DecodeRune(strlist[end:])
+		if size > 0 && (r != charset.RuneError || size > 1) {
+			last = r
+			next := end + size
+			last := next == len(strlist)
+			separator := r == ','
+			if separator || last {
+				position++
+				if last && !separator {
+					end = next
+				}
+				if col.Collate(strlist[begin:end], str, false) == 0 {
+					return position
+				}
+				begin = next
+			}
+			end = next
+			continue
+		}
+		if end == begin && len(str) == 0 && last == ',' {
+			return position + 1
+		}
+		return 0
+	}
+}
+
+func (call *builtinFindInSet) eval(env *ExpressionEnv) (eval, error) {
+	str, strlist, err := call.arg2(env)
+	if err != nil {
+		return nil, err
+	}
+	if str == nil || strlist == nil {
+		return nil, nil
+	}
+
+	tc, err := aggregateStringCollation(call.collate, evalCollation(str), evalCollation(strlist))
+	if err != nil {
+		return nil, err
+	}
+	s, err := evalToVarchar(str, tc.Collation, true)
+	if err != nil {
+		return nil, err
+	}
+	list, err := evalToVarchar(strlist, tc.Collation, true)
+	if err != nil {
+		return nil, err
+	}
+	return newEvalInt64(findInSet(tc.Collation.Get(), s.bytes, list.bytes)), nil
+}
+
+func (call *builtinFindInSet) typeof(env *ExpressionEnv, fields []*querypb.Field) (sqltypes.Type, typeFlag) {
+	_, f1 := call.Arguments[0].typeof(env, fields)
+	_, f2 := call.Arguments[1].typeof(env, fields)
+	return sqltypes.Int64, f1 | f2
+}
+
+func (call *builtinFindInSet) compile(c *compiler) (ctype, error) {
+	str, err := call.Arguments[0].compile(c)
+	if err != nil {
+		return ctype{}, err
+	}
+
+	strlist, err := call.Arguments[1].compile(c)
+	if err != nil {
+		return ctype{}, err
+	}
+
+	skip := c.compileNullCheck2(str, strlist)
+
+	tc, err := aggregateStringCollation(c.cfg.Collation, str.Col, strlist.Col)
+	if err != nil {
+		return ctype{}, err
+	}
+	c.compileToCollation(str, 2, tc)
+	c.compileToCollation(strlist, 1, tc)
+
+	c.asm.Fn_FIND_IN_SET(tc.Collation.Get())
+	c.asm.jumpDestination(skip)
+	return ctype{Type: sqltypes.Int64, Col: collationNumeric, Flag: str.Flag | strlist.Flag}, nil
+}
+
+// space returns a string with n spaces in the given collation, or false
+// if the result would be larger than the maximum allowed length.
+func space(col collations.Collation, n int64) ([]byte, bool) {
+	if n <= 0 {
+		return nil, true
+	}
+	var sp [4]byte
+	size := col.Charset().EncodeRune(sp[:], ' ')
+	if !validMaxLength(int64(size), n) {
+		return nil, false
+	}
+	return bytes.Repeat(sp[:size], int(n)), true
+}
+
+func (call *builtinSpace) eval(env *ExpressionEnv) (eval, error) {
+	arg, err := call.arg1(env)
+	if err != nil {
+		return nil, err
+	}
+	if arg == nil {
+		return nil, nil
+	}
+
+	res, ok := space(call.collate.Get(), evalToInt64(arg).i)
+	if !ok {
+		return nil, nil
+	}
+	return newEvalText(res, defaultCoercionCollation(call.collate)), nil
+}
+
+func (call *builtinSpace) typeof(env *ExpressionEnv, fields []*querypb.Field) (sqltypes.Type, typeFlag) {
+	_, f := call.Arguments[0].typeof(env, fields)
+	return sqltypes.VarChar, f | flagNullable
+}
+
+func (call *builtinSpace) compile(c *compiler) (ctype, error) {
+	n, err := call.Arguments[0].compile(c)
+	if err != nil {
+		return ctype{}, err
+	}
+
+	skip := c.compileNullCheck1(n)
+	_ = c.compileToInt64(n, 1)
+
+	col := defaultCoercionCollation(c.cfg.Collation)
+	c.asm.Fn_SPACE(col)
+	c.asm.jumpDestination(skip)
+	return ctype{Type: sqltypes.VarChar, Col: col, Flag: flagNullable}, nil
+}
+
+const soundexMap = "01230120022455012623010202"
+
+// soundexCode returns the SOUNDEX digit for the given character. Like in
+// MySQL, only the low byte of the character is taken into account, and all
+// characters outside of the A-Z range are treated as vowels.
+func soundexCode(r rune) byte {
+	ch := byte(r)
+	if ch >= 'a' && ch <= 'z' {
+		ch -= 'a' - 'A'
+	}
+	if ch < 'A' || ch > 'Z' {
+		return '0'
+	}
+	return soundexMap[ch-'A']
+}
+
+func soundexIsAlpha(r rune, size int) bool {
+	switch {
+	case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z':
+		return true
+	case r < 0x80:
+		return false
+	case size == 1:
+		return unicode.IsLetter(r)
+	default:
+		return r >= 0xC0
+	}
+}
+
+// soundex implements MySQL's variant of the SOUNDEX algorithm.
+func soundex(cs charset.Charset, str []byte) []byte {
+	var res []byte
+	var last byte
+	var chars int
+	for len(str) > 0 {
+		r, size := cs.DecodeRune(str)
+		if r == charset.RuneError && size < 2 {
+			break
+		}
+		str = str[size:]
+		if !soundexIsAlpha(r, size) {
+			continue
+		}
+
+		code := soundexCode(r)
+		if chars == 0 {
+			if r >= 'a' && r <= 'z' {
+				r -= 'a' - 'A'
+			}
+			res = appendRune(res, cs, r)
+		} else {
+			if code == '0' || code == last {
+				continue
+			}
+			res = appendRune(res, cs, rune(code))
+		}
+		last = code
+		chars++
+	}
+
+	if chars == 0 {
+		return nil
+	}
+	for ; chars < 4; chars++ {
+		res = appendRune(res, cs, '0')
+	}
+	return res
+}
+
+func appendRune(dst []byte, cs charset.Charset, r rune) []byte {
+	var buf [4]byte
+	n := cs.EncodeRune(buf[:], r)
+	if n < 0 {
+		return dst
+	}
+	return append(dst, buf[:n]...)
+}
+
+func (call *builtinSoundex) eval(env *ExpressionEnv) (eval, error) {
+	arg, err := call.arg1(env)
+	if err != nil {
+		return nil, err
+	}
+	if arg == nil {
+		return nil, nil
+	}
+
+	text, ok := arg.(*evalBytes)
+	if !ok {
+		text, err = evalToVarchar(arg, call.collate, true)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	cs := text.col.Collation.Get().Charset()
+	return newEvalText(soundex(cs, text.bytes), text.col), nil
+}
+
+func (call *builtinSoundex) typeof(env *ExpressionEnv, fields []*querypb.Field) (sqltypes.Type, typeFlag) {
+	_, f := call.Arguments[0].typeof(env, fields)
+	return sqltypes.VarChar, f
+}
+
+func (call *builtinSoundex) compile(c *compiler) (ctype, error) {
+	str, err := call.Arguments[0].compile(c)
+	if err != nil {
+		return ctype{}, err
+	}
+
+	skip := c.compileNullCheck1(str)
+
+	col := defaultCoercionCollation(c.cfg.Collation)
+	switch {
+	case str.isTextual():
+		col = str.Col
+	default:
+		c.asm.Convert_xc(1, sqltypes.VarChar, col.Collation, 0, false)
+	}
+
+	c.asm.Fn_SOUNDEX(col)
+	c.asm.jumpDestination(skip)
+	return ctype{Type: sqltypes.VarChar, Col: col, Flag: str.Flag}, nil
+}
+
+// formatMaxDecimals is the maximum number of decimals that FORMAT will
+// output, like in MySQL.
+const formatMaxDecimals = 30
+
+// formatNumber implements FORMAT for the en_US locale: it rounds the number
+// to the given amount of decimals and groups the digits of its integral part
+// in thousands.
+func formatNumber(num eval, dec int64) []byte {
+	if dec < 0 {
+		dec = 0
+	} else if dec > formatMaxDecimals {
+		dec = formatMaxDecimals
+	}
+
+	var str string
+	switch num := num.(type) {
+	case *evalInt64, *evalUint64, *evalDecimal:
+		d := evalToDecimal(num, 0, 0)
+		str = d.dec.StringFixed(int32(dec))
+	default:
+		f, _ := evalToFloat(num)
+		p := math.Pow(10, float64(dec))
+		v := f.f
+		if r := math.RoundToEven(v*p) / p; !math.IsInf(v*p, 0) && !math.IsNaN(r) {
+			v = r
+		}
+		str = strconv.FormatFloat(v, 'f', int(dec), 64)
+	}
+
+	var sign string
+	if str[0] == '-' {
+		sign, str = "-", str[1:]
+	}
+	integral, fractional := str, ""
+	if i := strings.IndexByte(str, '.'); i >= 0 {
+		integral, fractional = str[:i], str[i:]
+	}
+
+	res := make([]byte, 0, len(sign)+len(integral)+len(integral)/3+len(fractional))
+	res = append(res, sign...)
+	for i := range integral {
+		if i > 0 && (len(integral)-i)%3 == 0 {
+			res = append(res, ',')
+		}
+		res = append(res, integral[i])
+	}
+	return append(res, fractional...)
+}
+
+func (call *builtinFormat) eval(env *ExpressionEnv) (eval, error) {
+	num, dec, err := call.arg2(env)
+	if err != nil {
+		return nil, err
+	}
+	if num == nil || dec == nil {
+		return nil, nil
+	}
+
+	col := collations.TypedCollation{
+		Collation:    call.collate,
+		Coercibility: collations.CoerceCoercible,
+		Repertoire:   collations.RepertoireASCII,
+	}
+	return newEvalText(formatNumber(num, evalToInt64(dec).i), col), nil
+}
+
+func (call *builtinFormat) typeof(env *ExpressionEnv, fields []*querypb.Field) (sqltypes.Type, typeFlag) {
+	_, f1 := call.Arguments[0].typeof(env, fields)
+	_, f2 := call.Arguments[1].typeof(env, fields)
+	return sqltypes.VarChar, f1 | f2
+}
+
+func (call *builtinFormat) compile(c *compiler) (ctype, error) {
+	num, err := call.Arguments[0].compile(c)
+	if err != nil {
+		return ctype{}, err
+	}
+
+	dec, err := call.Arguments[1].compile(c)
+	if err != nil {
+		return ctype{}, err
+	}
+
+	skip := c.compileNullCheck2(num, dec)
+	_ = c.compileToInt64(dec, 1)
+
+	col := collations.TypedCollation{
+		Collation:    c.cfg.Collation,
+		Coercibility: collations.CoerceCoercible,
+		Repertoire:   collations.RepertoireASCII,
+	}
+	c.asm.Fn_FORMAT(col)
+	c.asm.jumpDestination(skip)
+	return ctype{Type: sqltypes.VarChar, Col: col, Flag: num.Flag | dec.Flag}, nil
+}
+
+// appendChar appends the bytes for the given CHAR() argument, which is
+// truncated to 32 bits and stored in big-endian order without leading
+// zero bytes.
+func appendChar(buf []byte, num int64) []byte {
+	n := uint32(num)
+	switch {
+	case n&0xFF000000 != 0:
+		return append(buf, byte(n>>24), byte(n>>16), byte(n>>8), byte(n))
+	case n&0xFF0000 != 0:
+		return append(buf, byte(n>>16), byte(n>>8), byte(n))
+	case n&0xFF00 != 0:
+		return append(buf, byte(n>>8), byte(n))
+	default:
+		return append(buf, byte(n))
+	}
+}
+
+// charString implements CHAR(): NULL arguments are skipped, and if the
+// result is not valid in the target collation, NULL is returned like
+// MySQL does in strict mode.
+func (call *builtinChar) charString(args []eval) eval {
+	var buf []byte
+	for _, arg := range args {
+		if arg == nil {
+			continue
+		}
+		buf = appendChar(buf, evalToInt64(arg).i)
+	}
+
+	if call.collate == collations.CollationBinaryID {
+		return newEvalBinary(buf)
+	}
+	cs := call.collate.Get().Charset()
+	if !charset.Validate(cs, buf) {
+		return nil
+	}
+	return newEvalText(buf, collations.TypedCollation{
+		Collation:    call.collate,
+		Coercibility: collations.CoerceCoercible,
+		Repertoire:   collations.RepertoireUnicode,
+	})
+}
+
+func (call *builtinChar) eval(env *ExpressionEnv) (eval, error) {
+	args, err := call.args(env)
+	if err != nil {
+		return nil, err
+	}
+	return call.charString(args), nil
+}
+
+func (call *builtinChar) typeof(env *ExpressionEnv, fields []*querypb.Field) (sqltypes.Type, typeFlag) {
+	for _, arg := range call.Arguments {
+		arg.typeof(env, fields)
+	}
+	if call.collate == collations.CollationBinaryID {
+		return sqltypes.VarBinary, 0
+	}
+	return sqltypes.VarChar, flagNullable
+}
+
+func (call *builtinChar) compile(c *compiler) (ctype, error) {
+	for _, arg := range call.Arguments {
+		if _, err := arg.compile(c); err != nil {
+			return ctype{}, err
+		}
+	}
+
+	c.asm.Fn_CHAR(call, len(call.Arguments))
+	if call.collate == collations.CollationBinaryID {
+		return ctype{Type: sqltypes.VarBinary, Col: collationBinary}, nil
+	}
+	col := collations.TypedCollation{
+		Collation:    call.collate,
+		Coercibility: collations.CoerceCoercible,
+		Repertoire:   collations.RepertoireUnicode,
+	}
+	return ctype{Type: sqltypes.VarChar, Col: col, Flag: flagNullable}, nil
+}
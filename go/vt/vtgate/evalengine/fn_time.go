@@ -141,6 +141,44 @@ type (
 	builtinYearWeek struct {
 		CallExpr
 	}
+
+	builtinDateMath struct {
+		CallExpr
+		sub     bool
+		unit    datetime.IntervalType
+		collate collations.ID
+	}
+
+	builtinDateDiff struct {
+		CallExpr
+	}
+
+	builtinTimestampDiff struct {
+		CallExpr
+		unit datetime.IntervalType
+	}
+
+	builtinStrToDate struct {
+		CallExpr
+	}
+
+	builtinLastDay struct {
+		CallExpr
+	}
+
+	builtinAddTime struct {
+		CallExpr
+		sub     bool
+		collate collations.ID
+	}
+
+	builtinTimeToSec struct {
+		CallExpr
+	}
+
+	builtinSecToTime struct {
+		CallExpr
+	}
 )
 
 var _ Expr = (*builtinNow)(nil)
@@ -169,6 +207,14 @@ var _ Expr = (*builtinWeekDay)(nil)
 var _ Expr = (*builtinWeekOfYear)(nil)
 var _ Expr = (*builtinYear)(nil)
 var _ Expr = (*builtinYearWeek)(nil)
+var _ Expr = (*builtinDateMath)(nil)
+var _ Expr = (*builtinDateDiff)(nil)
+var _ Expr = (*builtinTimestampDiff)(nil)
+var _ Expr = (*builtinStrToDate)(nil)
+var _ Expr = (*builtinLastDay)(nil)
+var _ Expr = (*builtinAddTime)(nil)
+var _ Expr = (*builtinTimeToSec)(nil)
+var _ Expr = (*builtinSecToTime)(nil)
 
 func (call *builtinNow) eval(env *ExpressionEnv) (eval, error) {
 	now := env.time(call.utc)
@@ -1688,3 +1734,579 @@ func (call *builtinYearWeek) compile(c *compiler) (ctype, error) {
 	c.asm.jumpDestination(skip1, skip2)
 	return ctype{Type: sqltypes.Int64, Col: collationNumeric, Flag: arg.Flag | flagNullable}, nil
 }
+
+// evalToInterval converts the value of an INTERVAL expression into an interval
+// with the given unit. Like in MySQL, compound units are always parsed from the
+// string representation of the value, fractional values for SECOND keep their
+// microseconds and any other unit uses the integer value of the expression.
+func evalToInterval(itv eval, unit datetime.IntervalType, negate bool) *datetime.Interval {
+	switch {
+	case unit.IsCompound():
+		return datetime.ParseInterval(evalToBinary(itv).string(), unit, negate)
+	case unit == datetime.IntervalSecond:
+		switch e := itv.(type) {
+		case *evalBytes, *evalFloat:
+			return datetime.ParseIntervalDecimal(evalToDecimal(e, 0, 0).dec, unit, negate)
+		case *evalDecimal:
+			if e.length > 0 {
+				return datetime.ParseIntervalDecimal(e.dec, unit, negate)
+			}
+		}
+	}
+	return datetime.ParseIntervalInt64(evalToInt64(itv).i, unit, negate)
+}
+
+// intervalPrecision returns the number of fractional digits that an
+// interval adds to the result of a date arithmetic operation.
+func intervalPrecision(itv eval, unit datetime.IntervalType) int {
+	if unit.NeedsPrecision() {
+		return datetime.DefaultPrecision
+	}
+	if unit == datetime.IntervalSecond {
+		switch e := itv.(type) {
+		case *evalDecimal:
+			if e.length < datetime.DefaultPrecision {
+				return int(e.length)
+			}
+			return datetime.DefaultPrecision
+		case *evalBytes, *evalFloat:
+			return datetime.DefaultPrecision
+		}
+	}
+	return 0
+}
+
+func maxPrecision(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// evalToDateMath converts a non-temporal value into a DATETIME for the
+// purposes of date arithmetic. It also returns whether the value was a
+// plain date, because in that case the result is also a date as long
+// as the interval doesn't have time parts.
+func evalToDateMath(e eval) (datetime.DateTime, bool, bool) {
+	switch e := e.(type) {
+	case *evalBytes:
+		if d, ok := datetime.ParseDate(e.string()); ok {
+			return datetime.DateTime{Date: d}, true, !d.IsZero()
+		}
+	case evalNumeric:
+		i := e.toInt64().i
+		if _, ok := datetime.ParseDateTimeInt64(i); !ok {
+			if d, ok := datetime.ParseDateInt64(i); ok {
+				return datetime.DateTime{Date: d}, true, !d.IsZero()
+			}
+		}
+	}
+	t := evalToDateTime(e, -1)
+	if t == nil || t.dt.Date.IsZero() {
+		return datetime.DateTime{}, false, false
+	}
+	return t.dt, false, true
+}
+
+func dateMathType(tt sqltypes.Type, unit datetime.IntervalType) sqltypes.Type {
+	switch tt {
+	case sqltypes.Date:
+		if !unit.HasTimeParts() {
+			return sqltypes.Date
+		}
+		return sqltypes.Datetime
+	case sqltypes.Time:
+		if unit.IsTimeOnly() {
+			return sqltypes.Time
+		}
+		return sqltypes.Datetime
+	case sqltypes.Datetime, sqltypes.Timestamp:
+		return sqltypes.Datetime
+	default:
+		return sqltypes.VarChar
+	}
+}
+
+func dateMath(date, interval eval, unit datetime.IntervalType, sub bool, col collations.TypedCollation) eval {
+	itv := evalToInterval(interval, unit, sub)
+	if itv == nil {
+		return nil
+	}
+	prec := intervalPrecision(interval, unit)
+
+	if tmp, ok := date.(*evalTemporal); ok {
+		switch tmp.SQLType() {
+		case sqltypes.Time:
+			if unit.IsTimeOnly() {
+				t, ok := tmp.dt.Time.AddInterval(itv)
+				if !ok {
+					return nil
+				}
+				return newEvalTime(t, maxPrecision(int(tmp.prec), prec))
+			}
+			tmp = tmp.toDateTime(int(tmp.prec))
+		case sqltypes.Date:
+			if tmp.dt.Date.IsZero() {
+				return nil
+			}
+			dt, ok := tmp.dt.AddInterval(itv)
+			if !ok {
+				return nil
+			}
+			if !unit.HasTimeParts() {
+				return newEvalDate(dt.Date)
+			}
+			return newEvalDateTime(dt, prec)
+		}
+
+		if tmp.dt.Date.IsZero() {
+			return nil
+		}
+		dt, ok := tmp.dt.AddInterval(itv)
+		if !ok {
+			return nil
+		}
+		return newEvalDateTime(dt, maxPrecision(int(tmp.prec), prec))
+	}
+
+	dt, isDate, ok := evalToDateMath(date)
+	if !ok {
+		return nil
+	}
+	dt, ok = dt.AddInterval(itv)
+	if !ok {
+		return nil
+	}
+
+	var out []byte
+	switch {
+	case isDate && !unit.HasTimeParts():
+		out = dt.Date.Format()
+	case dt.Time.Nanosecond() != 0:
+		out = dt.Format(datetime.DefaultPrecision)
+	default:
+		out = dt.Format(0)
+	}
+	return newEvalText(out, col)
+}
+
+func (call *builtinDateMath) eval(env *ExpressionEnv) (eval, error) {
+	date, interval, err := call.arg2(env)
+	if err != nil {
+		return nil, err
+	}
+	if date == nil || interval == nil {
+		return nil, nil
+	}
+	return dateMath(date, interval, call.unit, call.sub, defaultCoercionCollation(call.collate)), nil
+}
+
+func (call *builtinDateMath) typeof(env *ExpressionEnv, fields []*querypb.Field) (sqltypes.Type, typeFlag) {
+	tt, f := call.Arguments[0].typeof(env, fields)
+	return dateMathType(tt, call.unit), f | flagNullable
+}
+
+func (call *builtinDateMath) compile(c *compiler) (ctype, error) {
+	date, err := call.Arguments[0].compile(c)
+	if err != nil {
+		return ctype{}, err
+	}
+	interval, err := call.Arguments[1].compile(c)
+	if err != nil {
+		return ctype{}, err
+	}
+
+	skip := c.compileNullCheck2(date, interval)
+
+	col := defaultCoercionCollation(call.collate)
+	c.asm.Fn_DATE_MATH(call.unit, call.sub, col)
+	c.asm.jumpDestination(skip)
+
+	tt := dateMathType(date.Type, call.unit)
+	if tt != sqltypes.VarChar {
+		col = collationBinary
+	}
+	return ctype{Type: tt, Col: col, Flag: date.Flag | flagNullable}, nil
+}
+
+func dateDiff(left, right eval) eval {
+	d1 := evalToDate(left)
+	if d1 == nil || d1.isZero() {
+		return nil
+	}
+	d2 := evalToDate(right)
+	if d2 == nil || d2.isZero() {
+		return nil
+	}
+	return newEvalInt64(int64(d1.dt.Date.DayNumber() - d2.dt.Date.DayNumber()))
+}
+
+func (call *builtinDateDiff) eval(env *ExpressionEnv) (eval, error) {
+	left, right, err := call.arg2(env)
+	if err != nil {
+		return nil, err
+	}
+	if left == nil || right == nil {
+		return nil, nil
+	}
+	return dateDiff(left, right), nil
+}
+
+func (call *builtinDateDiff) typeof(env *ExpressionEnv, fields []*querypb.Field) (sqltypes.Type, typeFlag) {
+	return sqltypes.Int64, flagNullable
+}
+
+func (call *builtinDateDiff) compile(c *compiler) (ctype, error) {
+	left, err := call.Arguments[0].compile(c)
+	if err != nil {
+		return ctype{}, err
+	}
+	right, err := call.Arguments[1].compile(c)
+	if err != nil {
+		return ctype{}, err
+	}
+
+	skip := c.compileNullCheck2(left, right)
+	c.asm.Fn_DATEDIFF()
+	c.asm.jumpDestination(skip)
+	return ctype{Type: sqltypes.Int64, Col: collationNumeric, Flag: flagNullable}, nil
+}
+
+func timestampDiff(unit datetime.IntervalType, left, right eval) eval {
+	t1 := evalToDateTime(left, -1)
+	if t1 == nil || t1.dt.Date.IsZero() {
+		return nil
+	}
+	t2 := evalToDateTime(right, -1)
+	if t2 == nil || t2.dt.Date.IsZero() {
+		return nil
+	}
+	return newEvalInt64(datetime.TimestampDiff(unit, t1.dt, t2.dt))
+}
+
+func (call *builtinTimestampDiff) eval(env *ExpressionEnv) (eval, error) {
+	left, right, err := call.arg2(env)
+	if err != nil {
+		return nil, err
+	}
+	if left == nil || right == nil {
+		return nil, nil
+	}
+	return timestampDiff(call.unit, left, right), nil
+}
+
+func (call *builtinTimestampDiff) typeof(env *ExpressionEnv, fields []*querypb.Field) (sqltypes.Type, typeFlag) {
+	return sqltypes.Int64, flagNullable
+}
+
+func (call *builtinTimestampDiff) compile(c *compiler) (ctype, error) {
+	left, err := call.Arguments[0].compile(c)
+	if err != nil {
+		return ctype{}, err
+	}
+	right, err := call.Arguments[1].compile(c)
+	if err != nil {
+		return ctype{}, err
+	}
+
+	skip := c.compileNullCheck2(left, right)
+	c.asm.Fn_TIMESTAMPDIFF(call.unit)
+	c.asm.jumpDestination(skip)
+	return ctype{Type: sqltypes.Int64, Col: collationNumeric, Flag: flagNullable}, nil
+}
+
+// resultType returns the type and precision of the values returned by
+// STR_TO_DATE. If the format is a constant, the type depends on which
+// date and time parts the format contains; otherwise, the result is
+// always a DATETIME(6).
+func (call *builtinStrToDate) resultType() (sqltypes.Type, int) {
+	lit, ok := call.Arguments[1].(*Literal)
+	if !ok || lit.inner == nil {
+		return sqltypes.Datetime, datetime.DefaultPrecision
+	}
+
+	var prec int
+	hasDate, hasTime, hasFrac := datetime.StrToDateType(evalToBinary(lit.inner).string())
+	if hasFrac {
+		prec = datetime.DefaultPrecision
+	}
+	switch {
+	case hasDate && hasTime:
+		return sqltypes.Datetime, prec
+	case hasTime:
+		return sqltypes.Time, prec
+	default:
+		return sqltypes.Date, 0
+	}
+}
+
+func strToDate(value, format eval, tt sqltypes.Type, prec int) eval {
+	v := evalToBinary(value).string()
+	f := evalToBinary(format).string()
+
+	if tt == sqltypes.Time {
+		t, ok := datetime.StrToTime(v, f)
+		if !ok {
+			return nil
+		}
+		return newEvalTime(t, prec)
+	}
+
+	dt, ok := datetime.StrToDate(v, f)
+	if !ok {
+		return nil
+	}
+	if tt == sqltypes.Date {
+		return newEvalDate(dt.Date)
+	}
+	return newEvalDateTime(dt, prec)
+}
+
+func (call *builtinStrToDate) eval(env *ExpressionEnv) (eval, error) {
+	value, format, err := call.arg2(env)
+	if err != nil {
+		return nil, err
+	}
+	if value == nil || format == nil {
+		return nil, nil
+	}
+	tt, prec := call.resultType()
+	return strToDate(value, format, tt, prec), nil
+}
+
+func (call *builtinStrToDate) typeof(env *ExpressionEnv, fields []*querypb.Field) (sqltypes.Type, typeFlag) {
+	tt, _ := call.resultType()
+	return tt, flagNullable
+}
+
+func (call *builtinStrToDate) compile(c *compiler) (ctype, error) {
+	value, err := call.Arguments[0].compile(c)
+	if err != nil {
+		return ctype{}, err
+	}
+	format, err := call.Arguments[1].compile(c)
+	if err != nil {
+		return ctype{}, err
+	}
+
+	skip := c.compileNullCheck2(value, format)
+
+	tt, prec := call.resultType()
+	c.asm.Fn_STR_TO_DATE(tt, prec)
+	c.asm.jumpDestination(skip)
+	return ctype{Type: tt, Col: collationBinary, Flag: flagNullable}, nil
+}
+
+func lastDay(date eval) eval {
+	d := evalToDate(date)
+	if d == nil {
+		return nil
+	}
+	last, ok := d.dt.Date.LastDay()
+	if !ok {
+		return nil
+	}
+	return newEvalDate(last)
+}
+
+func (call *builtinLastDay) eval(env *ExpressionEnv) (eval, error) {
+	date, err := call.arg1(env)
+	if err != nil {
+		return nil, err
+	}
+	if date == nil {
+		return nil, nil
+	}
+	return lastDay(date), nil
+}
+
+func (call *builtinLastDay) typeof(env *ExpressionEnv, fields []*querypb.Field) (sqltypes.Type, typeFlag) {
+	return sqltypes.Date, flagNullable
+}
+
+func (call *builtinLastDay) compile(c *compiler) (ctype, error) {
+	arg, err := call.Arguments[0].compile(c)
+	if err != nil {
+		return ctype{}, err
+	}
+
+	skip := c.compileNullCheck1(arg)
+	c.asm.Fn_LAST_DAY()
+	c.asm.jumpDestination(skip)
+	return ctype{Type: sqltypes.Date, Col: collationBinary, Flag: arg.Flag | flagNullable}, nil
+}
+
+// evalToTimeOrDateTime converts a value into a TIME, unless the value
+// has a date part, in which case it's converted into a DATETIME. This
+// is how MySQL parses the arguments for ADDTIME and SUBTIME.
+func evalToTimeOrDateTime(e eval) *evalTemporal {
+	switch e := e.(type) {
+	case *evalTemporal:
+		return e
+	case *evalBytes:
+		if dt, l, ok := datetime.ParseDateTime(e.string(), -1); ok && !dt.Date.IsZero() {
+			return newEvalDateTime(dt, l)
+		}
+	case evalNumeric:
+		if dt, ok := datetime.ParseDateTimeInt64(e.toInt64().i); ok && !dt.Date.IsZero() {
+			return evalToDateTime(e, -1)
+		}
+	}
+	return evalToTime(e, -1)
+}
+
+func addTimeType(tt sqltypes.Type) sqltypes.Type {
+	switch tt {
+	case sqltypes.Time:
+		return sqltypes.Time
+	case sqltypes.Datetime, sqltypes.Timestamp, sqltypes.Date:
+		return sqltypes.Datetime
+	default:
+		return sqltypes.VarChar
+	}
+}
+
+func addTime(left, right eval, sub bool, col collations.TypedCollation) eval {
+	t2 := evalToTimeOrDateTime(right)
+	if t2 == nil || t2.SQLType() != sqltypes.Time {
+		return nil
+	}
+	t1 := evalToTimeOrDateTime(left)
+	if t1 == nil {
+		return nil
+	}
+
+	var res *evalTemporal
+	prec := maxPrecision(int(t1.prec), int(t2.prec))
+	if t1.SQLType() == sqltypes.Time {
+		res = newEvalTime(t1.dt.Time.AddTime(t2.dt.Time, sub), prec)
+	} else {
+		dt, ok := t1.dt.AddTime(t2.dt.Time, sub)
+		if !ok {
+			return nil
+		}
+		res = newEvalDateTime(dt, prec)
+	}
+
+	if _, ok := left.(*evalTemporal); ok {
+		return res
+	}
+	return newEvalText(res.ToRawBytes(), col)
+}
+
+func (call *builtinAddTime) eval(env *ExpressionEnv) (eval, error) {
+	left, right, err := call.arg2(env)
+	if err != nil {
+		return nil, err
+	}
+	if left == nil || right == nil {
+		return nil, nil
+	}
+	return addTime(left, right, call.sub, defaultCoercionCollation(call.collate)), nil
+}
+
+func (call *builtinAddTime) typeof(env *ExpressionEnv, fields []*querypb.Field) (sqltypes.Type, typeFlag) {
+	tt, f := call.Arguments[0].typeof(env, fields)
+	return addTimeType(tt), f | flagNullable
+}
+
+func (call *builtinAddTime) compile(c *compiler) (ctype, error) {
+	left, err := call.Arguments[0].compile(c)
+	if err != nil {
+		return ctype{}, err
+	}
+	right, err := call.Arguments[1].compile(c)
+	if err != nil {
+		return ctype{}, err
+	}
+
+	skip := c.compileNullCheck2(left, right)
+
+	col := defaultCoercionCollation(call.collate)
+	c.asm.Fn_ADDTIME(call.sub, col)
+	c.asm.jumpDestination(skip)
+
+	tt := addTimeType(left.Type)
+	if tt != sqltypes.VarChar {
+		col = collationBinary
+	}
+	return ctype{Type: tt, Col: col, Flag: left.Flag | flagNullable}, nil
+}
+
+func timeToSec(e eval) eval {
+	t := evalToTime(e, -1)
+	if t == nil {
+		return nil
+	}
+	return newEvalInt64(t.dt.Time.Seconds())
+}
+
+func (call *builtinTimeToSec) eval(env *ExpressionEnv) (eval, error) {
+	arg, err := call.arg1(env)
+	if err != nil {
+		return nil, err
+	}
+	if arg == nil {
+		return nil, nil
+	}
+	return timeToSec(arg), nil
+}
+
+func (call *builtinTimeToSec) typeof(env *ExpressionEnv, fields []*querypb.Field) (sqltypes.Type, typeFlag) {
+	return sqltypes.Int64, flagNullable
+}
+
+func (call *builtinTimeToSec) compile(c *compiler) (ctype, error) {
+	arg, err := call.Arguments[0].compile(c)
+	if err != nil {
+		return ctype{}, err
+	}
+
+	skip := c.compileNullCheck1(arg)
+	c.asm.Fn_TIME_TO_SEC()
+	c.asm.jumpDestination(skip)
+	return ctype{Type: sqltypes.Int64, Col: collationNumeric, Flag: arg.Flag | flagNullable}, nil
+}
+
+func secToTime(e eval) eval {
+	prec := datetime.DefaultPrecision
+	switch e := e.(type) {
+	case *evalInt64, *evalUint64:
+		prec = 0
+	case *evalDecimal:
+		if e.length < datetime.DefaultPrecision {
+			prec = int(e.length)
+		}
+	case *evalTemporal:
+		prec = int(e.prec)
+	}
+	return newEvalTime(datetime.NewTimeFromSeconds(evalToDecimal(e, 0, 0).dec), prec)
+}
+
+func (call *builtinSecToTime) eval(env *ExpressionEnv) (eval, error) {
+	arg, err := call.arg1(env)
+	if err != nil {
+		return nil, err
+	}
+	if arg == nil {
+		return nil, nil
+	}
+	return secToTime(arg), nil
+}
+
+func (call *builtinSecToTime) typeof(env *ExpressionEnv, fields []*querypb.Field) (sqltypes.Type, typeFlag) {
+	_, f := call.Arguments[0].typeof(env, fields)
+	return sqltypes.Time, f
+}
+
+func (call *builtinSecToTime) compile(c *compiler) (ctype, error) {
+	arg, err := call.Arguments[0].compile(c)
+	if err != nil {
+		return ctype{}, err
+	}
+
+	skip := c.compileNullCheck1(arg)
+	c.asm.Fn_SEC_TO_TIME()
+	c.asm.jumpDestination(skip)
+	return ctype{Type: sqltypes.Time, Col: collationBinary, Flag: arg.Flag}, nil
+}
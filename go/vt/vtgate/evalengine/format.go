@@ -171,6 +171,49 @@ func (c *builtinWeightString) format(w *formatter, depth int) {
 	w.WriteByte(')')
 }
 
+func (r *builtinRegexpLike) format(w *formatter, depth int) {
+	if r.Negate {
+		w.WriteString("NOT ")
+	}
+	r.CallExpr.format(w, depth)
+}
+
+func (b *builtinDateMath) format(w *formatter, depth int) {
+	w.WriteString(strings.ToUpper(b.Method))
+	w.WriteByte('(')
+	b.Arguments[0].format(w, depth+1)
+	w.WriteString(", INTERVAL ")
+	b.Arguments[1].format(w, depth+1)
+	w.WriteByte(' ')
+	w.WriteString(b.unit.String())
+	w.WriteByte(')')
+}
+
+func (b *builtinTimestampDiff) format(w *formatter, depth int) {
+	w.WriteString("TIMESTAMPDIFF(")
+	w.WriteString(b.unit.String())
+	for _, expr := range b.Arguments {
+		w.WriteString(", ")
+		expr.format(w, depth+1)
+	}
+	w.WriteByte(')')
+}
+
+func (b *builtinChar) format(w *formatter, depth int) {
+	w.WriteString("CHAR(")
+	for i, expr := range b.Arguments {
+		if i > 0 {
+			w.WriteString(", ")
+		}
+		expr.format(w, depth+1)
+	}
+	if b.collate != collations.CollationBinaryID {
+		w.WriteString(" USING ")
+		w.WriteString(b.collate.Get().Charset().Name())
+	}
+	w.WriteByte(')')
+}
+
 func (n *NegateExpr) format(w *formatter, depth int) {
 	w.WriteByte('-')
 	n.Inner.format(w, depth)
@@ -68,3 +68,72 @@ func BenchmarkCompilerExpressions(b *testing.B) {
 		})
 	}
 }
+
+func BenchmarkBatchExpressions(b *testing.B) {
+	var testCases = []struct {
+		name       string
+		expression string
+		values     []sqltypes.Value
+	}{
+		{"comparison_i64", "column0 = 12", []sqltypes.Value{sqltypes.NewInt64(666)}},
+		{"comparison_f", "column0 > 12.5e0", []sqltypes.Value{sqltypes.NewFloat64(420.0)}},
+		{"arith_i64", "column0 * 2 + column1", []sqltypes.Value{sqltypes.NewInt64(666), sqltypes.NewInt64(420)}},
+		{"logical", "column0 > 12 and column1 < 100", []sqltypes.Value{sqltypes.NewInt64(666), sqltypes.NewInt64(42)}},
+	}
+
+	const batchSize = 1024
+
+	for _, tc := range testCases {
+		expr, err := sqlparser.ParseExpr(tc.expression)
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		fields := evalengine.FieldResolver(makeFields(tc.values))
+		cfg := &evalengine.Config{
+			ResolveColumn: fields.Column,
+			ResolveType:   fields.Type,
+			Collation:     collations.CollationUtf8mb4ID,
+			Optimization:  evalengine.OptimizationLevelCompile,
+		}
+
+		translated, err := evalengine.Translate(expr, cfg)
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		rows := make([]sqltypes.Row, batchSize)
+		for i := range rows {
+			rows[i] = tc.values
+		}
+
+		b.Run(tc.name+"/eval=vm", func(b *testing.B) {
+			compiled := translated.(*evalengine.CompiledExpr)
+
+			b.ResetTimer()
+			b.ReportAllocs()
+
+			var env evalengine.ExpressionEnv
+			out := make([]sqltypes.Value, 0, batchSize)
+			for n := 0; n < b.N; n++ {
+				out = out[:0]
+				for _, row := range rows {
+					env.Row = row
+					res, _ := env.EvaluateVM(compiled)
+					out = append(out, res.Value())
+				}
+			}
+		})
+
+		b.Run(tc.name+"/eval=batch", func(b *testing.B) {
+			b.ResetTimer()
+			b.ReportAllocs()
+
+			var env evalengine.ExpressionEnv
+			out := make([]sqltypes.Value, 0, batchSize)
+			for n := 0; n < b.N; n++ {
+				out, _ = env.EvaluateBatch(translated, rows, out[:0])
+			}
+		})
+	}
+}
@@ -33,6 +33,10 @@ var Cases = []TestCase{
 	{Run: JSONPathOperations},
 	{Run: JSONArray},
 	{Run: JSONObject},
+	{Run: JSONModify},
+	{Run: JSONMerge},
+	{Run: JSONContains},
+	{Run: JSONSearch},
 	{Run: CharsetConversionOperators},
 	{Run: CaseExprWithPredicate},
 	{Run: CaseExprWithValue},
@@ -80,8 +84,22 @@ var Cases = []TestCase{
 	{Run: FnTrim},
 	{Run: FnConcat},
 	{Run: FnConcatWs},
+	{Run: FnSubstring},
+	{Run: FnSubstringIndex},
+	{Run: FnLocate},
+	{Run: FnReplace},
+	{Run: FnReverse},
+	{Run: FnInsert},
+	{Run: FnField},
+	{Run: FnElt},
+	{Run: FnFindInSet},
+	{Run: FnSpace},
+	{Run: FnSoundex},
+	{Run: FnFormat},
+	{Run: FnChar},
 	{Run: FnHex},
 	{Run: FnUnhex},
+	{Run: FnStringExamples},
 	{Run: FnCeil},
 	{Run: FnFloor},
 	{Run: FnAbs},
@@ -138,6 +156,18 @@ var Cases = []TestCase{
 	{Run: FnWeekOfYear},
 	{Run: FnYear},
 	{Run: FnYearWeek},
+	{Run: FnDateAdd},
+	{Run: FnDateSub},
+	{Run: FnTimestampAdd},
+	{Run: FnDateDiff},
+	{Run: FnTimestampDiff},
+	{Run: FnStrToDate},
+	{Run: FnLastDay},
+	{Run: FnAddTime},
+	{Run: FnSubTime},
+	{Run: FnTimeToSec},
+	{Run: FnSecToTime},
+	{Run: FnDateArithmeticExamples},
 	{Run: FnInetAton},
 	{Run: FnInetNtoa},
 	{Run: FnInet6Aton},
@@ -150,6 +180,17 @@ var Cases = []TestCase{
 	{Run: FnIsUUID},
 	{Run: FnUUID},
 	{Run: FnUUIDToBin},
+	{Run: FnRegexpLike},
+	{Run: FnRegexpInstr},
+	{Run: FnRegexpSubstr},
+	{Run: FnRegexpReplace},
+	{Run: FnGeomFromText},
+	{Run: FnGeomFromWKB},
+	{Run: FnPoint},
+	{Run: FnPointCoord},
+	{Run: FnDistanceSphere},
+	{Run: FnGeomContains},
+	{Run: FnGeomExamples},
 }
 
 func JSONPathOperations(yield Query) {
@@ -171,6 +212,71 @@ func JSONPathOperations(yield Query) {
 	}
 }
 
+func JSONModify(yield Query) {
+	for _, obj := range inputJSONObjects {
+		for _, path := range inputJSONPaths {
+			yield(fmt.Sprintf("JSON_REMOVE('%s', '%s')", obj, path), nil)
+			for _, fn := range []string{"JSON_SET", "JSON_INSERT", "JSON_REPLACE", "JSON_ARRAY_APPEND", "JSON_ARRAY_INSERT"} {
+				for _, value := range []string{`1`, `'foo'`, `NULL`, `JSON_ARRAY(1, 2)`} {
+					yield(fmt.Sprintf("%s('%s', '%s', %s)", fn, obj, path, value), nil)
+				}
+				yield(fmt.Sprintf("%s('%s', '%s', 1, '$.z', 2)", fn, obj, path), nil)
+			}
+		}
+		yield(fmt.Sprintf("JSON_SET('%s', NULL, 1)", obj), nil)
+		yield(fmt.Sprintf("JSON_REMOVE('%s', '$[0]', '$[0]')", obj), nil)
+	}
+	for _, value := range inputJSONPrimitives {
+		yield(fmt.Sprintf("JSON_SET('{\"a\": 1}', '$.b', %s)", value), nil)
+		yield(fmt.Sprintf("JSON_SET(%s, '$[1]', 1)", value), nil)
+	}
+}
+
+func JSONMerge(yield Query) {
+	for _, a := range inputJSONObjects {
+		for _, b := range inputJSONObjects {
+			yield(fmt.Sprintf("JSON_MERGE_PRESERVE('%s', '%s')", a, b), nil)
+			yield(fmt.Sprintf("JSON_MERGE_PATCH('%s', '%s')", a, b), nil)
+		}
+	}
+	for _, a := range inputJSONPrimitives {
+		yield(fmt.Sprintf("JSON_MERGE_PRESERVE(JSON_ARRAY(%s), '{\"a\": null}', '[1]')", a), nil)
+		yield(fmt.Sprintf("JSON_MERGE_PATCH('{\"a\": 1, \"b\": 2}', JSON_OBJECT('a', %s))", a), nil)
+	}
+	yield("JSON_MERGE('[1]', '[2]')", nil)
+	yield("JSON_MERGE_PATCH('[1]', NULL)", nil)
+}
+
+func JSONContains(yield Query) {
+	candidates := []string{`1`, `"foo"`, `[10]`, `[10, 40]`, `{"a": 1}`, `{"c": 123}`, `[true]`, `{"d": 4}`, `"a"`}
+	for _, obj := range inputJSONObjects {
+		for _, c := range candidates {
+			yield(fmt.Sprintf("JSON_CONTAINS('%s', '%s')", obj, c), nil)
+			for _, path := range inputJSONPaths {
+				yield(fmt.Sprintf("JSON_CONTAINS('%s', '%s', '%s')", obj, c, path), nil)
+			}
+		}
+	}
+	yield("JSON_CONTAINS('[1]', NULL)", nil)
+	yield("JSON_CONTAINS('[1]', '1', NULL)", nil)
+}
+
+func JSONSearch(yield Query) {
+	searches := []string{`'foo'`, `'FOO'`, `'f%'`, `'%'`, `'1_3'`, `'123'`, `'a'`, `'x'`, `NULL`}
+	for _, obj := range inputJSONObjects {
+		for _, search := range searches {
+			yield(fmt.Sprintf("JSON_SEARCH('%s', 'one', %s)", obj, search), nil)
+			yield(fmt.Sprintf("JSON_SEARCH('%s', 'all', %s)", obj, search), nil)
+			for _, path := range inputJSONPaths {
+				yield(fmt.Sprintf("JSON_SEARCH('%s', 'all', %s, NULL, '%s')", obj, search, path), nil)
+			}
+		}
+	}
+	yield(`JSON_SEARCH('["a%b", "axb"]', 'all', 'a|%b', '|')`, nil)
+	yield(`JSON_SEARCH('["a%b", "axb"]', 'all', 'a\\%b')`, nil)
+	yield(`JSON_SEARCH('["a"]', 'any', 'a')`, nil)
+}
+
 func JSONArray(yield Query) {
 	for _, a := range inputJSONPrimitives {
 		yield(fmt.Sprintf("JSON_ARRAY(%s)", a), nil)
@@ -1392,6 +1498,156 @@ func FnTrim(yield Query) {
 			}
 		}
 	}
+
+	for _, mode := range modes {
+		yield(fmt.Sprintf("TRIM(%s 'xy' FROM 'xyxyxbarxyxy')", mode), nil)
+		yield(fmt.Sprintf("TRIM(%s 'x' FROM 'xxx')", mode), nil)
+	}
+}
+
+func FnSubstring(yield Query) {
+	positions := []string{"-10", "-3", "-1", "0", "1", "2", "3", "10", "1.5", "'2'", "NULL"}
+	for _, str := range inputStrings {
+		for _, pos := range positions {
+			yield(fmt.Sprintf("SUBSTRING(%s, %s)", str, pos), nil)
+			yield(fmt.Sprintf("SUBSTR(%s FROM %s FOR 2)", str, pos), nil)
+			for _, l := range []string{"-1", "0", "1", "3", "100", "NULL"} {
+				yield(fmt.Sprintf("MID(%s, %s, %s)", str, pos, l), nil)
+			}
+		}
+	}
+}
+
+func FnSubstringIndex(yield Query) {
+	strs := []string{"'www.mysql.com'", "'a,,b,c'", "_utf8mb4 'Å,å,Å'", "_latin1 'a.b.c'", "_binary 'a.b.c'", "'aaa'", "''", "1.2345", "NULL"}
+	delims := []string{"'.'", "','", "'Å'", "'aa'", "''", "2", "NULL"}
+	counts := []string{"-4", "-2", "-1", "0", "1", "2", "4", "NULL"}
+	for _, str := range strs {
+		for _, delim := range delims {
+			for _, count := range counts {
+				yield(fmt.Sprintf("SUBSTRING_INDEX(%s, %s, %s)", str, delim, count), nil)
+			}
+		}
+	}
+}
+
+func FnLocate(yield Query) {
+	subs := []string{"'bar'", "'BAR'", "''", "'a'", "'Å'", "_binary 'a'", "'5'", "1", "NULL"}
+	strs := []string{"'foobarbar'", "'aAÅå'", "_latin1 'aAbB'", "_binary 'aAbB'", "''", "12345", "NULL"}
+	for _, sub := range subs {
+		for _, str := range strs {
+			yield(fmt.Sprintf("LOCATE(%s, %s)", sub, str), nil)
+			yield(fmt.Sprintf("INSTR(%s, %s)", str, sub), nil)
+			yield(fmt.Sprintf("POSITION(%s IN %s)", sub, str), nil)
+			for _, pos := range []string{"-1", "0", "1", "3", "5", "9", "10", "NULL"} {
+				yield(fmt.Sprintf("LOCATE(%s, %s, %s)", sub, str, pos), nil)
+			}
+		}
+	}
+}
+
+func FnReplace(yield Query) {
+	strs := []string{"'www.mysql.com'", "'aaaa'", "'aAÅå'", "_latin1 'aAbB'", "_binary 'aAbB'", "''", "1234", "NULL"}
+	froms := []string{"'w'", "'W'", "'aa'", "'Å'", "''", "2", "NULL"}
+	tos := []string{"'Ww'", "''", "'中'", "3", "NULL"}
+	for _, str := range strs {
+		for _, from := range froms {
+			for _, to := range tos {
+				yield(fmt.Sprintf("REPLACE(%s, %s, %s)", str, from, to), nil)
+			}
+		}
+	}
+}
+
+func FnReverse(yield Query) {
+	for _, str := range inputStrings {
+		yield(fmt.Sprintf("REVERSE(%s)", str), nil)
+	}
+}
+
+func FnInsert(yield Query) {
+	strs := []string{"'Quadratic'", "'aÅå中'", "_latin1 'aAbB'", "_binary 'aAbB'", "''", "1234", "NULL"}
+	for _, str := range strs {
+		for _, pos := range []string{"-1", "0", "1", "3", "4", "5", "100", "NULL"} {
+			for _, l := range []string{"-1", "0", "1", "4", "100", "NULL"} {
+				for _, newstr := range []string{"'What'", "''", "'Å'", "1", "NULL"} {
+					yield(fmt.Sprintf("INSERT(%s, %s, %s, %s)", str, pos, l, newstr), nil)
+				}
+			}
+		}
+	}
+}
+
+func FnField(yield Query) {
+	args := []string{"'Bb'", "'bb'", "'BB' COLLATE utf8mb4_0900_as_cs", "_binary 'bb'", "1", "1.0", "1e0", "'1'", "2", "18446744073709551615", "NULL", "date '2000-01-01'", "'2000-01-01'"}
+	for _, a := range args {
+		for _, b := range args {
+			yield(fmt.Sprintf("FIELD(%s, %s)", a, b), nil)
+			for _, c := range args {
+				yield(fmt.Sprintf("FIELD(%s, %s, %s)", a, b, c), nil)
+			}
+		}
+	}
+}
+
+func FnElt(yield Query) {
+	for _, n := range []string{"-1", "0", "1", "2", "3", "4", "1.5", "'2'", "NULL"} {
+		for _, a := range inputStrings {
+			yield(fmt.Sprintf("ELT(%s, %s, 'foo', NULL)", n, a), nil)
+			yield(fmt.Sprintf("ELT(%s, 1, %s, 2.5)", n, a), nil)
+		}
+	}
+}
+
+func FnFindInSet(yield Query) {
+	strs := []string{"'b'", "'B'", "''", "'a,b'", "'Å'", "_binary 'b'", "1", "NULL"}
+	lists := []string{"'a,b,c,d'", "'A,B'", "'a,,b'", "'a,'", "','", "''", "'å,Å'", "_latin1 'a,b'", "_binary 'a,b'", "'2,1'", "NULL"}
+	for _, str := range strs {
+		for _, list := range lists {
+			yield(fmt.Sprintf("FIND_IN_SET(%s, %s)", str, list), nil)
+		}
+	}
+}
+
+func FnSpace(yield Query) {
+	for _, n := range []string{"-1", "0", "1", "6", "1.5", "'3'", "1073741825", "NULL"} {
+		yield(fmt.Sprintf("SPACE(%s)", n), nil)
+		yield(fmt.Sprintf("CONCAT('a', SPACE(%s), 'b')", n), nil)
+	}
+}
+
+func FnSoundex(yield Query) {
+	strs := []string{"'Hello'", "'Quadratically'", "'Robert'", "'Rupert'", "'Tymczak'", "'Pfister'", "'  abc'", "'Ab'", "'é'", "'Ëlisa'", "_latin1 'Ëlisa'", "'123'", "''", "'中文'", "_binary 'Hello'", "NULL"}
+	for _, str := range strs {
+		yield(fmt.Sprintf("SOUNDEX(%s)", str), nil)
+	}
+	for _, str := range inputStrings {
+		yield(fmt.Sprintf("SOUNDEX(%s)", str), nil)
+	}
+}
+
+func FnFormat(yield Query) {
+	nums := []string{"0", "1", "-1", "12332.123456", "-12332.123456", "12332.1", "12332.2", "1234567.891", "-999.995", "0.5", "1.5e0", "2.5e0", "-1234567.891e0", "123456789012345678901234567890.123", "18446744073709551615", "-9223372036854775808", "'12345.678'", "'abc'", "NULL"}
+	decs := []string{"-1", "0", "1", "2", "4", "31", "'2'", "NULL"}
+	for _, num := range nums {
+		for _, dec := range decs {
+			yield(fmt.Sprintf("FORMAT(%s, %s)", num, dec), nil)
+		}
+	}
+}
+
+func FnChar(yield Query) {
+	args := []string{"77", "121", "83", "81", "'76'", "77.3", "256", "65536", "16777216", "-1", "0", "NULL"}
+	for _, a := range args {
+		yield(fmt.Sprintf("CHAR(%s)", a), nil)
+		for _, b := range args {
+			yield(fmt.Sprintf("CHAR(%s, %s)", a, b), nil)
+			yield(fmt.Sprintf("CHAR(%s, %s USING utf8mb4)", a, b), nil)
+			yield(fmt.Sprintf("CHAR(%s, %s USING latin1)", a, b), nil)
+		}
+	}
+	yield("CHAR(0xc3a5 USING utf8mb4)", nil)
+	yield("CHAR(0xe4b8ad USING utf8mb4)", nil)
 }
 
 func FnConcat(yield Query) {
@@ -1478,6 +1734,50 @@ func FnUnhex(yield Query) {
 	}
 }
 
+// FnStringExamples are examples of the string functions from the MySQL documentation,
+// such as negative positions and out of range lengths.
+func FnStringExamples(yield Query) {
+	queries := []string{
+		`SUBSTRING('Quadratically', 5)`,
+		`SUBSTRING('foobarbar' FROM 4)`,
+		`SUBSTRING('Quadratically', 5, 6)`,
+		`SUBSTRING('Sakila', -3)`,
+		`SUBSTRING('Sakila', -5, 3)`,
+		`SUBSTRING('Sakila' FROM -4 FOR 2)`,
+		`SUBSTRING_INDEX('www.mysql.com', '.', 2)`,
+		`SUBSTRING_INDEX('www.mysql.com', '.', -2)`,
+		`LOCATE('bar', 'foobarbar')`,
+		`LOCATE('xbar', 'foobar')`,
+		`LOCATE('bar', 'foobarbar', 5)`,
+		`INSTR('foobarbar', 'bar')`,
+		`INSTR('xbar', 'foobar')`,
+		`POSITION('bar' IN 'foobarbar')`,
+		`REPLACE('www.mysql.com', 'w', 'Ww')`,
+		`REVERSE('abc')`,
+		`INSERT('Quadratic', 3, 4, 'What')`,
+		`INSERT('Quadratic', -1, 4, 'What')`,
+		`INSERT('Quadratic', 3, 100, 'What')`,
+		`FIELD('Bb', 'Aa', 'Bb', 'Cc', 'Dd', 'Ff')`,
+		`FIELD('Gg', 'Aa', 'Bb', 'Cc', 'Dd', 'Ff')`,
+		`ELT(1, 'Aa', 'Bb', 'Cc', 'Dd')`,
+		`ELT(4, 'Aa', 'Bb', 'Cc', 'Dd')`,
+		`FIND_IN_SET('b', 'a,b,c,d')`,
+		`SOUNDEX('Hello')`,
+		`SOUNDEX('Quadratically')`,
+		`FORMAT(12332.123456, 4)`,
+		`FORMAT(12332.1, 4)`,
+		`FORMAT(12332.2, 0)`,
+		`CHAR(77, 121, 83, 81, '76')`,
+		`CHAR(77, 77.3, '77.3')`,
+		`TRIM(LEADING 'x' FROM 'xxxbarxxx')`,
+		`TRIM(BOTH 'x' FROM 'xxxbarxxx')`,
+		`TRIM(TRAILING 'xyz' FROM 'barxxyz')`,
+	}
+	for _, q := range queries {
+		yield(q, nil)
+	}
+}
+
 func InStatement(yield Query) {
 	roots := append([]string(nil), inputBitwise...)
 	roots = append(roots, inputComparisonElement...)
@@ -1735,6 +2035,193 @@ func FnYearWeek(yield Query) {
 	}
 }
 
+func FnDateAdd(yield Query) {
+	for _, d := range inputConversions {
+		for _, unit := range inputIntervalUnits {
+			yield(fmt.Sprintf("DATE_ADD(%s, INTERVAL 1 %s)", d, unit), nil)
+		}
+	}
+	for _, i := range inputIntervals {
+		for _, unit := range inputIntervalUnits {
+			yield(fmt.Sprintf("DATE_ADD(timestamp '2000-01-31 10:34:58', INTERVAL %s %s)", i, unit), nil)
+			yield(fmt.Sprintf("DATE_ADD(date '2000-01-31', INTERVAL %s %s)", i, unit), nil)
+			yield(fmt.Sprintf("DATE_ADD(time '10:04:58', INTERVAL %s %s)", i, unit), nil)
+			yield(fmt.Sprintf("DATE_ADD('2000-01-31', INTERVAL %s %s)", i, unit), nil)
+		}
+	}
+	for _, d := range inputConversions {
+		yield(fmt.Sprintf("ADDDATE(%s, 31)", d), nil)
+		yield(fmt.Sprintf("%s + INTERVAL 1 DAY", d), nil)
+	}
+}
+
+func FnDateSub(yield Query) {
+	for _, d := range inputConversions {
+		for _, unit := range inputIntervalUnits {
+			yield(fmt.Sprintf("DATE_SUB(%s, INTERVAL 1 %s)", d, unit), nil)
+		}
+	}
+	for _, i := range inputIntervals {
+		for _, unit := range inputIntervalUnits {
+			yield(fmt.Sprintf("DATE_SUB(timestamp '2000-03-31 10:34:58', INTERVAL %s %s)", i, unit), nil)
+			yield(fmt.Sprintf("DATE_SUB(date '2000-03-31', INTERVAL %s %s)", i, unit), nil)
+		}
+	}
+	for _, d := range inputConversions {
+		yield(fmt.Sprintf("SUBDATE(%s, 31)", d), nil)
+		yield(fmt.Sprintf("%s - INTERVAL 1 DAY", d), nil)
+	}
+}
+
+func FnTimestampAdd(yield Query) {
+	units := []string{"YEAR", "QUARTER", "MONTH", "WEEK", "DAY", "HOUR", "MINUTE", "SECOND", "MICROSECOND"}
+	for _, d := range inputConversions {
+		for _, unit := range units {
+			yield(fmt.Sprintf("TIMESTAMPADD(%s, 1, %s)", unit, d), nil)
+		}
+	}
+	for _, i := range inputIntervals {
+		for _, unit := range units {
+			yield(fmt.Sprintf("TIMESTAMPADD(%s, %s, timestamp '2000-01-31 10:34:58')", unit, i), nil)
+		}
+	}
+}
+
+func FnDateDiff(yield Query) {
+	for _, d1 := range inputConversions {
+		for _, d2 := range []string{"date '2000-01-01'", "timestamp '1999-12-31 23:59:59'", "'2023-03-01'", "NULL"} {
+			yield(fmt.Sprintf("DATEDIFF(%s, %s)", d1, d2), nil)
+			yield(fmt.Sprintf("DATEDIFF(%s, %s)", d2, d1), nil)
+		}
+	}
+}
+
+func FnTimestampDiff(yield Query) {
+	units := []string{"YEAR", "QUARTER", "MONTH", "WEEK", "DAY", "HOUR", "MINUTE", "SECOND", "MICROSECOND"}
+	dates := []string{
+		"timestamp '2000-01-31 10:34:58'", "timestamp '2000-02-29 10:34:57.123456'",
+		"date '1999-12-31'", "'2023-03-01 00:00:00'", "20230301",
+	}
+	for _, d1 := range inputConversions {
+		for _, d2 := range dates {
+			for _, unit := range units {
+				yield(fmt.Sprintf("TIMESTAMPDIFF(%s, %s, %s)", unit, d1, d2), nil)
+			}
+		}
+	}
+}
+
+func FnStrToDate(yield Query) {
+	for _, d := range inputStrToDate {
+		yield(fmt.Sprintf("STR_TO_DATE(%s, %s)", d.value, d.format), nil)
+	}
+}
+
+func FnLastDay(yield Query) {
+	for _, d := range inputConversions {
+		yield(fmt.Sprintf("LAST_DAY(%s)", d), nil)
+	}
+}
+
+func FnAddTime(yield Query) {
+	times := []string{
+		"time '10:04:58'", "time '-10:04:58.5'", "time '838:59:59'", "'1 10:04:58'", "'10:04:58.123'",
+		"10458", "1.5", "'2000-01-01 00:00:00'", "date '2000-01-01'", "NULL",
+	}
+	for _, d := range inputConversions {
+		for _, t := range times {
+			yield(fmt.Sprintf("ADDTIME(%s, %s)", d, t), nil)
+		}
+	}
+}
+
+func FnSubTime(yield Query) {
+	times := []string{
+		"time '10:04:58'", "time '-10:04:58.5'", "time '838:59:59'", "'1 10:04:58'", "'10:04:58.123'",
+		"10458", "1.5", "'2000-01-01 00:00:00'", "date '2000-01-01'", "NULL",
+	}
+	for _, d := range inputConversions {
+		for _, t := range times {
+			yield(fmt.Sprintf("SUBTIME(%s, %s)", d, t), nil)
+		}
+	}
+}
+
+func FnTimeToSec(yield Query) {
+	for _, d := range inputConversions {
+		yield(fmt.Sprintf("TIME_TO_SEC(%s)", d), nil)
+	}
+}
+
+func FnSecToTime(yield Query) {
+	for _, d := range inputConversions {
+		yield(fmt.Sprintf("SEC_TO_TIME(%s)", d), nil)
+	}
+	for _, s := range []string{"3020399", "3020400", "-3020400", "3020399.999999", "-1.5", "86400.123"} {
+		yield(fmt.Sprintf("SEC_TO_TIME(%s)", s), nil)
+	}
+}
+
+// FnDateArithmeticExamples are edge cases of the date arithmetic functions, such as
+// month overflows, negative intervals and the limits of the supported range.
+func FnDateArithmeticExamples(yield Query) {
+	queries := []string{
+		`DATE_ADD('2018-05-01', INTERVAL 1 DAY)`,
+		`DATE_SUB('2018-05-01', INTERVAL 1 YEAR)`,
+		`DATE_ADD('2020-12-31 23:59:59', INTERVAL 1 SECOND)`,
+		`DATE_ADD('2018-12-31 23:59:59', INTERVAL 1 DAY)`,
+		`DATE_ADD('2100-12-31 23:59:59', INTERVAL '1:1' MINUTE_SECOND)`,
+		`DATE_SUB('2025-01-01 00:00:00', INTERVAL '1 1:1:1' DAY_SECOND)`,
+		`DATE_ADD('1900-01-01 00:00:00', INTERVAL '-1 10' DAY_HOUR)`,
+		`DATE_SUB('1998-01-02', INTERVAL 31 DAY)`,
+		`DATE_ADD('1992-12-31 23:59:59.000002', INTERVAL '1.999999' SECOND_MICROSECOND)`,
+		`DATE_ADD('2024-03-30', INTERVAL 1 MONTH)`,
+		`DATE_ADD('2024-03-31', INTERVAL 1 MONTH)`,
+		`DATE_ADD(DATE '2018-05-01', INTERVAL 1 DAY)`,
+		`DATE_ADD(TIMESTAMP '2018-05-01 10:00:00', INTERVAL 90 MINUTE)`,
+		`DATE_ADD(DATE '9999-12-31', INTERVAL 1 DAY)`,
+		`ADDDATE('2008-01-02', 31)`,
+		`SUBDATE('2008-01-02 12:00:00', 31)`,
+		`'2008-12-31 23:59:59' + INTERVAL 1 SECOND`,
+		`INTERVAL 1 DAY + '2008-12-31'`,
+		`'2005-01-01' - INTERVAL 1 SECOND`,
+		`TIMESTAMPADD(MINUTE, 1, '2003-01-02')`,
+		`TIMESTAMPADD(WEEK, 1, '2003-01-02')`,
+		`DATEDIFF('2007-12-31 23:59:59', '2007-12-30')`,
+		`DATEDIFF('2010-11-30 23:59:59', '2010-12-31')`,
+		`DATEDIFF('2010-11-30', NULL)`,
+		`TIMESTAMPDIFF(MONTH, '2003-02-01', '2003-05-01')`,
+		`TIMESTAMPDIFF(YEAR, '2002-05-01', '2001-01-01')`,
+		`TIMESTAMPDIFF(MINUTE, '2003-02-01', '2003-05-01 12:05:55')`,
+		`TIMESTAMPDIFF(DAY, '2023-01-01 12:00:00', '2023-01-02 11:59:59')`,
+		`STR_TO_DATE('01,5,2013', '%d,%m,%Y')`,
+		`STR_TO_DATE('May 1, 2013', '%M %d,%Y')`,
+		`STR_TO_DATE('a09:30:17', 'a%h:%i:%s')`,
+		`STR_TO_DATE('a09:30:17', '%h:%i:%s')`,
+		`STR_TO_DATE('09:30:17a', '%h:%i:%s')`,
+		`STR_TO_DATE('2013-05-01 10:11:12.5', '%Y-%m-%d %H:%i:%s.%f')`,
+		`LAST_DAY('2003-02-05')`,
+		`LAST_DAY('2004-02-05')`,
+		`LAST_DAY('2004-01-01 01:01:01')`,
+		`LAST_DAY('2003-03-32')`,
+		`ADDTIME('2007-12-31 23:59:59.999999', '1 1:1:1.000002')`,
+		`ADDTIME('01:00:00.999999', '02:00:00.999998')`,
+		`ADDTIME(TIME '10:00:00', '01:00:00')`,
+		`SUBTIME('2007-12-31 23:59:59.999999', '1 1:1:1.000002')`,
+		`SUBTIME('01:00:00.999999', '02:00:00.999998')`,
+		`TIME_TO_SEC('22:23:00')`,
+		`TIME_TO_SEC('00:39:38')`,
+		`TIME_TO_SEC(TIME '-01:00:00')`,
+		`SEC_TO_TIME(2378)`,
+		`SEC_TO_TIME(-2378)`,
+		`SEC_TO_TIME(3020400)`,
+		`SEC_TO_TIME(1.5)`,
+	}
+	for _, q := range queries {
+		yield(q, nil)
+	}
+}
+
 func FnInetAton(yield Query) {
 	for _, d := range ipInputs {
 		yield(fmt.Sprintf("INET_ATON(%s)", d), nil)
@@ -1847,3 +2334,239 @@ func FnUUIDToBin(yield Query) {
 		}
 	}
 }
+
+func FnRegexpLike(yield Query) {
+	for _, str := range regexInputs {
+		for _, pat := range regexPatterns {
+			yield(fmt.Sprintf("%s REGEXP %s", str, pat), nil)
+			yield(fmt.Sprintf("%s NOT REGEXP %s", str, pat), nil)
+			yield(fmt.Sprintf("REGEXP_LIKE(%s, %s)", str, pat), nil)
+			for _, mode := range regexMatchTypes {
+				yield(fmt.Sprintf("REGEXP_LIKE(%s, %s, %s)", str, pat, mode), nil)
+			}
+		}
+	}
+
+	for _, str := range []string{"'abc'", "_binary 'abc'", "0x616263", "_latin1 'abc'"} {
+		for _, pat := range []string{"'b'", "_binary 'b'", "0x62", "_latin1 'b'"} {
+			yield(fmt.Sprintf("REGEXP_LIKE(%s, %s)", str, pat), nil)
+		}
+	}
+}
+
+func FnRegexpInstr(yield Query) {
+	for _, str := range regexInputs {
+		for _, pat := range regexPatterns {
+			yield(fmt.Sprintf("REGEXP_INSTR(%s, %s)", str, pat), nil)
+		}
+	}
+
+	positions := []string{"NULL", "0", "1", "2", "4", "100", "'2'"}
+	for _, pos := range positions {
+		for _, occ := range []string{"NULL", "-1", "0", "1", "2", "3"} {
+			for _, opt := range []string{"NULL", "0", "1", "2"} {
+				yield(fmt.Sprintf("REGEXP_INSTR('abc def ghi', '[a-z]+', %s, %s, %s)", pos, occ, opt), nil)
+				yield(fmt.Sprintf("REGEXP_INSTR('àbç dëf ghï', '[a-zà-ÿ]+', %s, %s, %s, 'i')", pos, occ, opt), nil)
+			}
+		}
+	}
+}
+
+func FnRegexpSubstr(yield Query) {
+	for _, str := range regexInputs {
+		for _, pat := range regexPatterns {
+			yield(fmt.Sprintf("REGEXP_SUBSTR(%s, %s)", str, pat), nil)
+		}
+	}
+
+	positions := []string{"NULL", "0", "1", "2", "4", "100"}
+	for _, pos := range positions {
+		for _, occ := range []string{"NULL", "-1", "0", "1", "2", "3"} {
+			yield(fmt.Sprintf("REGEXP_SUBSTR('abc def ghi', '[a-z]+', %s, %s)", pos, occ), nil)
+			yield(fmt.Sprintf("REGEXP_SUBSTR('ABC def GHI', '[a-z]+', %s, %s, 'c')", pos, occ), nil)
+			yield(fmt.Sprintf("REGEXP_SUBSTR(_binary 'abc def ghi', _binary '[a-z]+', %s, %s)", pos, occ), nil)
+			yield(fmt.Sprintf("REGEXP_SUBSTR('١٢ ٣٤ ٥٦', '\\d+', %s, %s)", pos, occ), nil)
+		}
+	}
+}
+
+func FnRegexpReplace(yield Query) {
+	for _, str := range regexInputs {
+		for _, pat := range regexPatterns {
+			yield(fmt.Sprintf("REGEXP_REPLACE(%s, %s, 'X')", str, pat), nil)
+		}
+	}
+
+	replacements := []string{"NULL", "''", "'X'", "'[$0]'", "'$1-$2'", "'\\\\$1'", "'$3'", "'$a'", "'${year}'"}
+	for _, repl := range replacements {
+		yield(fmt.Sprintf("REGEXP_REPLACE('2023-04-01', '([0-9]+)-([0-9]+)', %s)", repl), nil)
+		yield(fmt.Sprintf("REGEXP_REPLACE('2023-04-01', '(?<year>[0-9]+)-', %s)", repl), nil)
+	}
+
+	positions := []string{"NULL", "0", "1", "2", "5", "100"}
+	for _, pos := range positions {
+		for _, occ := range []string{"NULL", "-1", "0", "1", "2", "3"} {
+			yield(fmt.Sprintf("REGEXP_REPLACE('abc def ghi', '[a-z]+', 'X', %s, %s)", pos, occ), nil)
+			yield(fmt.Sprintf("REGEXP_REPLACE('abc DEF ghi', '[a-z]+', 'X', %s, %s, 'c')", pos, occ), nil)
+		}
+	}
+}
+
+var inputGeometryWKT = []string{
+	"'POINT(1 2)'",
+	"'POINT(-1.5 2e3)'",
+	"'point ( 10   20 )'",
+	"'LINESTRING(0 0,1 1,2 2)'",
+	"'POLYGON((0 0,10 0,10 10,0 10,0 0),(5 5,7 5,7 7,5 7,5 5))'",
+	"'MULTIPOINT(1 1,2 2)'",
+	"'MULTIPOINT((1 1),(2 2))'",
+	"'MULTILINESTRING((0 0,1 1),(2 2,3 3))'",
+	"'MULTIPOLYGON(((0 0,1 0,1 1,0 0)),((2 2,3 2,3 3,2 2)))'",
+	"'GEOMETRYCOLLECTION(POINT(1 1),LINESTRING(0 0,1 1))'",
+	"'GEOMETRYCOLLECTION EMPTY'",
+	"'POINT(100 200)'",
+	"'POINT(1)'",
+	"'POLYGON((0 0,1 0,1 1))'",
+	"'CIRCLE(1 1)'",
+	"''",
+	"NULL",
+}
+
+func FnGeomFromText(yield Query) {
+	for _, wkt := range inputGeometryWKT {
+		yield(fmt.Sprintf("ST_AsText(ST_GeomFromText(%s))", wkt), nil)
+		yield(fmt.Sprintf("ST_AsText(ST_GeometryFromText(%s, 0))", wkt), nil)
+		yield(fmt.Sprintf("ST_AsText(ST_GeomFromText(%s, 4326))", wkt), nil)
+		yield(fmt.Sprintf("ST_AsText(ST_PointFromText(%s))", wkt), nil)
+		yield(fmt.Sprintf("ST_AsText(ST_PolygonFromText(%s))", wkt), nil)
+		yield(fmt.Sprintf("HEX(ST_GeomFromText(%s))", wkt), nil)
+		yield(fmt.Sprintf("HEX(ST_AsBinary(ST_GeomFromText(%s, 4326)))", wkt), nil)
+	}
+	for _, srid := range []string{"NULL", "-1", "3857", "4294967296", "'4326'"} {
+		yield(fmt.Sprintf("ST_AsText(ST_GeomFromText('POINT(1 2)', %s))", srid), nil)
+	}
+}
+
+func FnGeomFromWKB(yield Query) {
+	for _, wkt := range inputGeometryWKT {
+		yield(fmt.Sprintf("ST_AsText(ST_GeomFromWKB(ST_AsBinary(ST_GeomFromText(%s))))", wkt), nil)
+		yield(fmt.Sprintf("ST_AsText(ST_GeomFromWKB(ST_AsBinary(ST_GeomFromText(%s)), 4326))", wkt), nil)
+		yield(fmt.Sprintf("ST_AsText(ST_LineStringFromWKB(ST_AsBinary(ST_GeomFromText(%s))))", wkt), nil)
+	}
+	wkbs := []string{
+		"0x00000000013ff0000000000000bff0000000000000",
+		"0x0101000000000000000000f03f000000000000f0bf",
+		"0x0101000000000000000000f87f0000000000000000",
+		"0x010400000000000000",
+		"0x0108000000",
+		"'abc'",
+		"NULL",
+	}
+	for _, wkb := range wkbs {
+		yield(fmt.Sprintf("ST_AsText(ST_GeomFromWKB(%s))", wkb), nil)
+	}
+	yield("ST_AsText(0x000000000101000000000000000000F03F000000000000F0BF)", nil)
+	yield("ST_AsText(0xE6100000010100000000000000000000F03F000000000000F0BF)", nil)
+	yield("ST_AsText(0x00000000)", nil)
+	yield("ST_AsText(1)", nil)
+}
+
+func FnPoint(yield Query) {
+	coords := []string{"0", "1", "-1.5", "1e100", "'2.5'", "'abc'", "NULL"}
+	for _, x := range coords {
+		for _, y := range coords {
+			yield(fmt.Sprintf("ST_AsText(POINT(%s, %s))", x, y), nil)
+		}
+	}
+}
+
+func FnPointCoord(yield Query) {
+	for _, wkt := range inputGeometryWKT {
+		yield(fmt.Sprintf("ST_X(ST_GeomFromText(%s))", wkt), nil)
+		yield(fmt.Sprintf("ST_Y(ST_GeomFromText(%s))", wkt), nil)
+		yield(fmt.Sprintf("ST_X(ST_GeomFromText(%s, 4326))", wkt), nil)
+		yield(fmt.Sprintf("ST_Y(ST_GeomFromText(%s, 4326))", wkt), nil)
+	}
+	yield("ST_X(POINT(1, 2))", nil)
+	yield("ST_Y(POINT(1, 2))", nil)
+	yield("ST_X(NULL)", nil)
+}
+
+func FnDistanceSphere(yield Query) {
+	points := []string{
+		"POINT(0, 0)",
+		"POINT(180, 0)",
+		"POINT(-73.9949, 40.7501)",
+		"POINT(-73.9961, 40.7308)",
+		"POINT(190, 0)",
+		"POINT(0, 91)",
+		"ST_GeomFromText('POINT(40.7501 -73.9949)', 4326)",
+		"ST_GeomFromText('POINT(40.7308 -73.9961)', 4326)",
+		"ST_GeomFromText('MULTIPOINT(1 1,10 10,-20 5)')",
+		"ST_GeomFromText('LINESTRING(0 0,1 1)')",
+		"NULL",
+	}
+	for _, p1 := range points {
+		for _, p2 := range points {
+			yield(fmt.Sprintf("ST_Distance_Sphere(%s, %s)", p1, p2), nil)
+		}
+	}
+	for _, radius := range []string{"1", "6378137", "0", "-1", "'1000'", "NULL"} {
+		yield(fmt.Sprintf("ST_Distance_Sphere(POINT(0, 0), POINT(1, 1), %s)", radius), nil)
+	}
+}
+
+func FnGeomContains(yield Query) {
+	containers := []string{
+		"'POLYGON((0 0,10 0,10 10,0 10,0 0),(5 5,7 5,7 7,5 7,5 5))'",
+		"'MULTIPOLYGON(((0 0,1 0,1 1,0 0)),((2 2,3 2,3 3,2 2)))'",
+		"'LINESTRING(0 0,5 5,10 0)'",
+		"'LINESTRING(0 0,10 0,10 10,0 0)'",
+		"'POINT(2 2)'",
+		"'MULTIPOINT(1 1,2 2)'",
+		"'GEOMETRYCOLLECTION(POINT(1 1))'",
+	}
+	contained := []string{
+		"'POINT(1 1)'",
+		"'POINT(2 2)'",
+		"'POINT(0 0)'",
+		"'POINT(10 5)'",
+		"'POINT(6 6)'",
+		"'POINT(5 7)'",
+		"'POINT(2.5 2.2)'",
+		"'POINT(11 11)'",
+		"'POINT(5 0)'",
+		"'MULTIPOINT(0 0,1 1)'",
+		"'MULTIPOINT(0 0,10 0)'",
+		"'LINESTRING(1 1,2 2)'",
+	}
+	for _, a := range containers {
+		for _, b := range contained {
+			yield(fmt.Sprintf("ST_Contains(ST_GeomFromText(%s), ST_GeomFromText(%s))", a, b), nil)
+			yield(fmt.Sprintf("ST_Within(ST_GeomFromText(%s), ST_GeomFromText(%s))", b, a), nil)
+		}
+	}
+	yield("ST_Contains(ST_GeomFromText('POINT(1 1)', 4326), ST_GeomFromText('POINT(1 1)'))", nil)
+	yield("ST_Contains(ST_GeomFromText('POINT(1 1)', 4326), ST_GeomFromText('POINT(1 1)', 4326))", nil)
+	yield("ST_Contains(NULL, POINT(1, 1))", nil)
+	yield("ST_Within(POINT(1, 1), NULL)", nil)
+}
+
+// FnGeomExamples are examples of the spatial functions from the MySQL documentation,
+// such as points on the boundary of a polygon.
+func FnGeomExamples(yield Query) {
+	queries := []string{
+		`ST_Distance_Sphere(ST_GeomFromText('POINT(0 0)'), ST_GeomFromText('POINT(180 0)'))`,
+		`ST_X(Point(56.7, 53.34))`,
+		`ST_Y(Point(56.7, 53.34))`,
+		`ST_AsText(ST_GeomFromText('LineString(1 1,2 2,3 3)'))`,
+		`ST_AsText(ST_GeomFromText('MULTIPOINT(1 1, 2 2, 3 3)'))`,
+		`ST_Contains(ST_GeomFromText('Polygon((0 0,0 3,3 3,3 0,0 0))'), ST_GeomFromText('Point(1 1)'))`,
+		`ST_Within(ST_GeomFromText('Point(1 1)'), ST_GeomFromText('Polygon((0 0,0 3,3 3,3 0,0 0))'))`,
+		`ST_Contains(ST_GeomFromText('Polygon((0 0,0 3,3 3,3 0,0 0))'), ST_GeomFromText('Point(0 0)'))`,
+		`ST_Within(ST_GeomFromText('Point(5 5)'), ST_GeomFromText('Polygon((0 0,0 3,3 3,3 0,0 0))'))`,
+	}
+	for _, q := range queries {
+		yield(q, nil)
+	}
+}
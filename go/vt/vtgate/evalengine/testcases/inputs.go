@@ -285,3 +285,56 @@ var uuidInputs = []string{
 	"0x09DB81F6F26611EDA6F920FC8FD6830E",
 	"0x11EDF26609DB81F6A6F920FC8FD6830E",
 }
+
+var regexInputs = []string{
+	"NULL", "''", "'abc'", "'ABC'", "'a.b.c'", "'abc\\ndef'", "'foo bar baz'", "'ñandú'",
+	"_binary 'abc'", "_latin1 0xe9e8e0", "123", "1.5", "0x616263", "'١٢٣'", "'straße Über'",
+	"_utf8mb4 0x61C2A062",
+}
+
+var regexPatterns = []string{
+	"NULL", "''", "'a'", "'^a'", "'c$'", "'^abc$'", "'A'", "'.'", "'a.b'", "'a\\.b'", "'[a-c]+'",
+	"'[[:alpha:]]+'", "'b{2,}'", "'(a|b)c'", "'^def'", "'ñ'", "'\\d+'", "'é'", "'1'",
+	"'('", "'[a'", "'a{2,1}'", "'*'", "'\\z'", "'\\w+'", "'\\W'", "'\\s'", "'[\\w-]+'",
+	"'[[:upper:]]'", "'[[:space:]]'", "'[[:digit:]]+'", "'\\bfoo'", "_binary 'b'",
+}
+
+var regexMatchTypes = []string{
+	"NULL", "''", "'c'", "'i'", "'ci'", "'ic'", "'m'", "'n'", "'u'", "'x'",
+}
+
+var inputIntervals = []string{
+	"1", "-1", "0", "100", "1.5", "-1.5", "1.000005", "'1'", "'-1'", "'1 2'", "'1:2'",
+	"'1-2'", "'1 2:3:4'", "'1:2:3.5'", "'-1 2:3:4.000005'", "'1 2 3'", "'foo'", "NULL",
+	"time '10:04:58'", "date '2000-01-01'", "timestamp '2000-01-01 10:34:58.123456'",
+}
+
+var inputIntervalUnits = []string{
+	"YEAR", "QUARTER", "MONTH", "WEEK", "DAY", "HOUR", "MINUTE", "SECOND", "MICROSECOND",
+	"YEAR_MONTH", "DAY_HOUR", "DAY_MINUTE", "DAY_SECOND", "HOUR_MINUTE", "HOUR_SECOND",
+	"MINUTE_SECOND", "DAY_MICROSECOND", "HOUR_MICROSECOND", "MINUTE_MICROSECOND", "SECOND_MICROSECOND",
+}
+
+var inputStrToDate = []struct {
+	value, format string
+}{
+	{"'2023-01-02'", "'%Y-%m-%d'"},
+	{"'23-1-2'", "'%y-%c-%e'"},
+	{"'01/02/99 10:11:12'", "'%m/%d/%y %H:%i:%s'"},
+	{"'May 3rd, 2013'", "'%M %D, %Y'"},
+	{"'sep 3 2013'", "'%b %e %Y'"},
+	{"'2023-01-02 10:11:12 PM'", "'%Y-%m-%d %r'"},
+	{"'2023-01-02 10:11:12.5'", "'%Y-%m-%d %T.%f'"},
+	{"'10:11:12'", "'%H:%i:%s'"},
+	{"'10:11:12.123'", "'%H:%i:%s.%f'"},
+	{"'2023 032'", "'%Y %j'"},
+	{"'2023 1 Monday'", "'%Y %u %W'"},
+	{"'2023-01-02 trailing'", "'%Y-%m-%d'"},
+	{"'2023-01'", "'%Y-%m-%d'"},
+	{"'2023-02-30'", "'%Y-%m-%d'"},
+	{"'2023/01/02'", "'%Y-%m-%d'"},
+	{"'foo'", "'%Y'"},
+	{"20230102", "'%Y%m%d'"},
+	{"NULL", "'%Y-%m-%d'"},
+	{"'2023-01-02'", "NULL"},
+}
@@ -75,6 +75,15 @@ func (ast *astCompiler) translateComparisonExpr2(op sqlparser.ComparisonExprOper
 		return &LikeExpr{BinaryExpr: binaryExpr}, nil
 	case sqlparser.NotLikeOp:
 		return &LikeExpr{BinaryExpr: binaryExpr, Negate: true}, nil
+	case sqlparser.RegexpOp, sqlparser.NotRegexpOp:
+		if regexpUnsupported(right) {
+			return nil, vterrors.Errorf(vtrpcpb.Code_UNIMPLEMENTED, "%s: %s", ErrTranslateExprNotSupported, op.ToString())
+		}
+		return &builtinRegexpLike{
+			CallExpr: CallExpr{Arguments: []Expr{left, right}, Method: "REGEXP_LIKE"},
+			Negate:   op == sqlparser.NotRegexpOp,
+			collate:  ast.cfg.Collation,
+		}, nil
 	default:
 		return nil, vterrors.Errorf(vtrpcpb.Code_UNIMPLEMENTED, op.ToString())
 	}
@@ -602,7 +611,7 @@ func Translate(e sqlparser.Expr, cfg *Config) (Expr, error) {
 		comp := compiler{cfg: cfg}
 		var ct ctype
 		if ct, cfg.CompilerErr = comp.compile(expr); cfg.CompilerErr == nil {
-			expr = &CompiledExpr{code: comp.asm.ins, original: expr, stack: comp.asm.stack.max, typed: ct.Type}
+			expr = &CompiledExpr{code: comp.asm.ins, original: expr, stack: comp.asm.stack.max, typed: ct.Type, batch: compileBatch(expr)}
 		}
 	}
 
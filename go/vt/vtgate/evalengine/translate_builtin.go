@@ -20,6 +20,10 @@ import (
 	"fmt"
 	"strings"
 
+	"vitess.io/vitess/go/mysql/collations"
+	"vitess.io/vitess/go/mysql/datetime"
+	"vitess.io/vitess/go/mysql/json"
+	"vitess.io/vitess/go/sqltypes"
 	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
 	"vitess.io/vitess/go/vt/sqlparser"
 	"vitess.io/vitess/go/vt/vterrors"
@@ -465,6 +469,36 @@ func (ast *astCompiler) translateFuncExpr(fn *sqlparser.FuncExpr) (Expr, error)
 		default:
 			return nil, argError(method)
 		}
+	case "datediff":
+		if len(args) != 2 {
+			return nil, argError(method)
+		}
+		return &builtinDateDiff{CallExpr: call}, nil
+	case "str_to_date":
+		if len(args) != 2 {
+			return nil, argError(method)
+		}
+		return &builtinStrToDate{CallExpr: call}, nil
+	case "last_day":
+		if len(args) != 1 {
+			return nil, argError(method)
+		}
+		return &builtinLastDay{CallExpr: call}, nil
+	case "addtime", "subtime":
+		if len(args) != 2 {
+			return nil, argError(method)
+		}
+		return &builtinAddTime{CallExpr: call, sub: method == "subtime", collate: ast.cfg.Collation}, nil
+	case "time_to_sec":
+		if len(args) != 1 {
+			return nil, argError(method)
+		}
+		return &builtinTimeToSec{CallExpr: call}, nil
+	case "sec_to_time":
+		if len(args) != 1 {
+			return nil, argError(method)
+		}
+		return &builtinSecToTime{CallExpr: call}, nil
 	case "inet_aton":
 		if len(args) != 1 {
 			return nil, argError(method)
@@ -574,6 +608,78 @@ func (ast *astCompiler) translateFuncExpr(fn *sqlparser.FuncExpr) (Expr, error)
 			return nil, argError(method)
 		}
 		return &builtinStrcmp{CallExpr: call, collate: ast.cfg.Collation}, nil
+	case "substr", "substring", "mid":
+		if len(args) != 2 && len(args) != 3 {
+			return nil, argError(method)
+		}
+		return &builtinSubstring{CallExpr: call, collate: ast.cfg.Collation}, nil
+	case "substring_index":
+		if len(args) != 3 {
+			return nil, argError(method)
+		}
+		return &builtinSubstringIndex{CallExpr: call, collate: ast.cfg.Collation}, nil
+	case "instr":
+		if len(args) != 2 {
+			return nil, argError(method)
+		}
+		// INSTR(str, substr) is LOCATE(substr, str)
+		call = CallExpr{Arguments: []Expr{args[1], args[0]}, Method: "LOCATE"}
+		return &builtinLocate{CallExpr: call, collate: ast.cfg.Collation}, nil
+	case "replace":
+		if len(args) != 3 {
+			return nil, argError(method)
+		}
+		return &builtinReplace{CallExpr: call, collate: ast.cfg.Collation}, nil
+	case "reverse":
+		if len(args) != 1 {
+			return nil, argError(method)
+		}
+		return &builtinReverse{CallExpr: call, collate: ast.cfg.Collation}, nil
+	case "field":
+		if len(args) < 2 {
+			return nil, argError(method)
+		}
+		return &builtinField{CallExpr: call, collate: ast.cfg.Collation}, nil
+	case "elt":
+		if len(args) < 2 {
+			return nil, argError(method)
+		}
+		return &builtinElt{CallExpr: call, collate: ast.cfg.Collation}, nil
+	case "find_in_set":
+		if len(args) != 2 {
+			return nil, argError(method)
+		}
+		return &builtinFindInSet{CallExpr: call, collate: ast.cfg.Collation}, nil
+	case "space":
+		if len(args) != 1 {
+			return nil, argError(method)
+		}
+		return &builtinSpace{CallExpr: call, collate: ast.cfg.Collation}, nil
+	case "soundex":
+		if len(args) != 1 {
+			return nil, argError(method)
+		}
+		return &builtinSoundex{CallExpr: call, collate: ast.cfg.Collation}, nil
+	case "format":
+		switch len(args) {
+		case 2:
+			return &builtinFormat{CallExpr: call, collate: ast.cfg.Collation}, nil
+		case 3:
+			// we only know how to format numbers for the default locale
+			return nil, translateExprNotSupported(fn)
+		default:
+			return nil, argError(method)
+		}
+	case "st_contains", "st_within":
+		if len(args) != 2 {
+			return nil, argError(method)
+		}
+		return &builtinGeomContains{CallExpr: call, within: method == "st_within"}, nil
+	case "st_distance_sphere":
+		if len(args) != 2 && len(args) != 3 {
+			return nil, argError(method)
+		}
+		return &builtinDistanceSphere{CallExpr: call}, nil
 	default:
 		return nil, translateExprNotSupported(fn)
 	}
@@ -694,6 +800,97 @@ func (ast *astCompiler) translateCallable(call sqlparser.Callable) (Expr, error)
 			Method:    "JSON_KEYS",
 		}}, nil
 
+	case *sqlparser.JSONValueModifierExpr:
+		exprs := []sqlparser.Expr{call.JSONDoc}
+		for _, param := range call.Params {
+			exprs = append(exprs, param.Key, param.Value)
+		}
+		args, err := ast.translateFuncArgs(exprs)
+		if err != nil {
+			return nil, err
+		}
+
+		var mode json.Transformation
+		var method string
+		switch call.Type {
+		case sqlparser.JSONArrayAppendType:
+			mode, method = json.ArrayAppend, "JSON_ARRAY_APPEND"
+		case sqlparser.JSONArrayInsertType:
+			mode, method = json.ArrayInsert, "JSON_ARRAY_INSERT"
+		case sqlparser.JSONInsertType:
+			mode, method = json.Insert, "JSON_INSERT"
+		case sqlparser.JSONReplaceType:
+			mode, method = json.Replace, "JSON_REPLACE"
+		case sqlparser.JSONSetType:
+			mode, method = json.Set, "JSON_SET"
+		default:
+			return nil, translateExprNotSupported(call)
+		}
+		return &builtinJSONModify{
+			CallExpr: CallExpr{Arguments: args, Method: method},
+			mode:     mode,
+		}, nil
+
+	case *sqlparser.JSONRemoveExpr:
+		args, err := ast.translateFuncArgs(append([]sqlparser.Expr{call.JSONDoc}, call.PathList...))
+		if err != nil {
+			return nil, err
+		}
+		return &builtinJSONModify{
+			CallExpr: CallExpr{Arguments: args, Method: "JSON_REMOVE"},
+			mode:     json.Remove,
+		}, nil
+
+	case *sqlparser.JSONValueMergeExpr:
+		args, err := ast.translateFuncArgs(append([]sqlparser.Expr{call.JSONDoc}, call.JSONDocList...))
+		if err != nil {
+			return nil, err
+		}
+
+		var method string
+		switch call.Type {
+		case sqlparser.JSONMergeType:
+			method = "JSON_MERGE"
+		case sqlparser.JSONMergePatchType:
+			method = "JSON_MERGE_PATCH"
+		case sqlparser.JSONMergePreserveType:
+			method = "JSON_MERGE_PRESERVE"
+		default:
+			return nil, translateExprNotSupported(call)
+		}
+		return &builtinJSONMerge{
+			CallExpr: CallExpr{Arguments: args, Method: method},
+			patch:    call.Type == sqlparser.JSONMergePatchType,
+		}, nil
+
+	case *sqlparser.JSONContainsExpr:
+		if len(call.PathList) > 1 {
+			return nil, argError("json_contains")
+		}
+		args, err := ast.translateFuncArgs(append([]sqlparser.Expr{call.Target, call.Candidate}, call.PathList...))
+		if err != nil {
+			return nil, err
+		}
+		return &builtinJSONContains{CallExpr: CallExpr{
+			Arguments: args,
+			Method:    "JSON_CONTAINS",
+		}}, nil
+
+	case *sqlparser.JSONSearchExpr:
+		exprs := []sqlparser.Expr{call.JSONDoc, call.OneOrAll, call.SearchStr}
+		if call.EscapeChar != nil {
+			exprs = append(exprs, call.EscapeChar)
+			exprs = append(exprs, call.PathList...)
+		}
+		args, err := ast.translateFuncArgs(exprs)
+		if err != nil {
+			return nil, err
+		}
+		return &builtinJSONSearch{
+			CallExpr: CallExpr{Arguments: args, Method: "JSON_SEARCH"},
+			collate:  ast.cfg.Collation,
+		}, nil
+
 	case *sqlparser.CurTimeFuncExpr:
 		if call.Fsp > 6 {
 			return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "Too-big precision 12 specified for '%s'. Maximum is 6.", call.Name.String())
@@ -722,6 +919,67 @@ func (ast *astCompiler) translateCallable(call sqlparser.Callable) (Expr, error)
 			prec:     uint8(call.Fsp),
 		}, nil
 
+	case *sqlparser.SubstrExpr:
+		args, err := ast.translateFuncArgs([]sqlparser.Expr{call.Name, call.From})
+		if err != nil {
+			return nil, err
+		}
+		if call.To != nil {
+			to, err := ast.translateExpr(call.To)
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, to)
+		}
+		return &builtinSubstring{
+			CallExpr: CallExpr{Arguments: args, Method: "SUBSTRING"},
+			collate:  ast.cfg.Collation,
+		}, nil
+
+	case *sqlparser.LocateExpr:
+		args, err := ast.translateFuncArgs([]sqlparser.Expr{call.SubStr, call.Str})
+		if err != nil {
+			return nil, err
+		}
+		if call.Pos != nil {
+			pos, err := ast.translateExpr(call.Pos)
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, pos)
+		}
+		return &builtinLocate{
+			CallExpr: CallExpr{Arguments: args, Method: "LOCATE"},
+			collate:  ast.cfg.Collation,
+		}, nil
+
+	case *sqlparser.InsertExpr:
+		args, err := ast.translateFuncArgs([]sqlparser.Expr{call.Str, call.Pos, call.Len, call.NewStr})
+		if err != nil {
+			return nil, err
+		}
+		return &builtinInsert{
+			CallExpr: CallExpr{Arguments: args, Method: "INSERT"},
+			collate:  ast.cfg.Collation,
+		}, nil
+
+	case *sqlparser.CharExpr:
+		args, err := ast.translateFuncArgs(call.Exprs)
+		if err != nil {
+			return nil, err
+		}
+		var collate collations.ID = collations.CollationBinaryID
+		if call.Charset != "" {
+			collate, err = ast.translateConvertCharset(call.Charset, false)
+			if err != nil {
+				return nil, err
+			}
+		}
+		return &builtinChar{
+			CallExpr: CallExpr{Arguments: args, Method: "CHAR"},
+			collate:  collate,
+		}, nil
+
 	case *sqlparser.TrimFuncExpr:
 		var args []Expr
 		str, err := ast.translateExpr(call.StringArg)
@@ -744,11 +1002,257 @@ func (ast *astCompiler) translateCallable(call sqlparser.Callable) (Expr, error)
 			trim:     call.Type,
 		}, nil
 
+	case *sqlparser.RegexpLikeExpr:
+		args, err := ast.translateRegexpArgs(call, call.Expr, call.Pattern, call.MatchType)
+		if err != nil {
+			return nil, err
+		}
+		return &builtinRegexpLike{
+			CallExpr: CallExpr{Arguments: args, Method: "REGEXP_LIKE"},
+			collate:  ast.cfg.Collation,
+		}, nil
+
+	case *sqlparser.RegexpInstrExpr:
+		args, err := ast.translateRegexpArgs(call, call.Expr, call.Pattern, call.Position, call.Occurrence, call.ReturnOption, call.MatchType)
+		if err != nil {
+			return nil, err
+		}
+		return &builtinRegexpInstr{
+			CallExpr: CallExpr{Arguments: args, Method: "REGEXP_INSTR"},
+			collate:  ast.cfg.Collation,
+		}, nil
+
+	case *sqlparser.RegexpSubstrExpr:
+		args, err := ast.translateRegexpArgs(call, call.Expr, call.Pattern, call.Position, call.Occurrence, call.MatchType)
+		if err != nil {
+			return nil, err
+		}
+		return &builtinRegexpSubstr{
+			CallExpr: CallExpr{Arguments: args, Method: "REGEXP_SUBSTR"},
+			collate:  ast.cfg.Collation,
+		}, nil
+
+	case *sqlparser.RegexpReplaceExpr:
+		args, err := ast.translateRegexpArgs(call, call.Expr, call.Pattern, call.Repl, call.Position, call.Occurrence, call.MatchType)
+		if err != nil {
+			return nil, err
+		}
+		return &builtinRegexpReplace{
+			CallExpr: CallExpr{Arguments: args, Method: "REGEXP_REPLACE"},
+			collate:  ast.cfg.Collation,
+		}, nil
+
+	case *sqlparser.GeomFromTextExpr:
+		if call.AxisOrderOpt != nil {
+			return nil, translateExprNotSupported(call)
+		}
+		args, err := ast.translateFuncArgs([]sqlparser.Expr{call.WktText})
+		if err != nil {
+			return nil, err
+		}
+		if call.Srid != nil {
+			srid, err := ast.translateExpr(call.Srid)
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, srid)
+		}
+		return &builtinGeomFrom{
+			CallExpr: CallExpr{Arguments: args, Method: strings.ToUpper(call.Type.ToString())},
+			geom:     geomTypeFromWkt[call.Type],
+		}, nil
+
+	case *sqlparser.GeomFromWKBExpr:
+		if call.AxisOrderOpt != nil {
+			return nil, translateExprNotSupported(call)
+		}
+		args, err := ast.translateFuncArgs([]sqlparser.Expr{call.WkbBlob})
+		if err != nil {
+			return nil, err
+		}
+		if call.Srid != nil {
+			srid, err := ast.translateExpr(call.Srid)
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, srid)
+		}
+		return &builtinGeomFrom{
+			CallExpr: CallExpr{Arguments: args, Method: strings.ToUpper(call.Type.ToString())},
+			geom:     geomTypeFromWkb[call.Type],
+			binary:   true,
+		}, nil
+
+	case *sqlparser.GeomFormatExpr:
+		if call.AxisOrderOpt != nil {
+			return nil, translateExprNotSupported(call)
+		}
+		args, err := ast.translateFuncArgs([]sqlparser.Expr{call.Geom})
+		if err != nil {
+			return nil, err
+		}
+		return &builtinGeomFormat{
+			CallExpr: CallExpr{Arguments: args, Method: strings.ToUpper(call.FormatType.ToString())},
+			binary:   call.FormatType == sqlparser.BinaryFormat,
+			collate:  ast.cfg.Collation,
+		}, nil
+
+	case *sqlparser.PointExpr:
+		args, err := ast.translateFuncArgs([]sqlparser.Expr{call.XCordinate, call.YCordinate})
+		if err != nil {
+			return nil, err
+		}
+		return &builtinPoint{CallExpr: CallExpr{Arguments: args, Method: "POINT"}}, nil
+
+	case *sqlparser.PointPropertyFuncExpr:
+		// the two-argument forms that set a coordinate are not supported
+		if call.ValueToSet != nil {
+			return nil, translateExprNotSupported(call)
+		}
+		switch call.Property {
+		case sqlparser.XCordinate, sqlparser.YCordinate:
+		default:
+			return nil, translateExprNotSupported(call)
+		}
+		args, err := ast.translateFuncArgs([]sqlparser.Expr{call.Point})
+		if err != nil {
+			return nil, err
+		}
+		return &builtinPointCoord{
+			CallExpr: CallExpr{Arguments: args, Method: strings.ToUpper(call.Property.ToString())},
+			y:        call.Property == sqlparser.YCordinate,
+		}, nil
+
+	case *sqlparser.DateAddExpr:
+		return ast.translateDateMath(call.Date, call.Expr, call.Unit, call.Type == sqlparser.AdddateType, "DATE_ADD", false)
+
+	case *sqlparser.DateSubExpr:
+		return ast.translateDateMath(call.Date, call.Expr, call.Unit, call.Type == sqlparser.SubdateType, "DATE_SUB", true)
+
+	case *sqlparser.TimestampFuncExpr:
+		unit, ok := datetime.ParseIntervalType(call.Unit)
+		if !ok {
+			return nil, translateExprNotSupported(call)
+		}
+		switch strings.ToLower(call.Name) {
+		case "timestampadd":
+			args, err := ast.translateFuncArgs([]sqlparser.Expr{call.Expr2, call.Expr1})
+			if err != nil {
+				return nil, err
+			}
+			return &builtinDateMath{
+				CallExpr: CallExpr{Arguments: args, Method: "DATE_ADD"},
+				unit:     unit,
+				collate:  ast.cfg.Collation,
+			}, nil
+		case "timestampdiff":
+			args, err := ast.translateFuncArgs([]sqlparser.Expr{call.Expr1, call.Expr2})
+			if err != nil {
+				return nil, err
+			}
+			return &builtinTimestampDiff{
+				CallExpr: CallExpr{Arguments: args, Method: "TIMESTAMPDIFF"},
+				unit:     unit,
+			}, nil
+		default:
+			return nil, translateExprNotSupported(call)
+		}
+
 	default:
 		return nil, translateExprNotSupported(call)
 	}
 }
 
+var geomTypeFromWkt = [...]sqltypes.GeomType{
+	sqlparser.GeometryFromText:           0,
+	sqlparser.GeometryCollectionFromText: sqltypes.GeomCollection,
+	sqlparser.PointFromText:              sqltypes.GeomPoint,
+	sqlparser.LineStringFromText:         sqltypes.GeomLineString,
+	sqlparser.PolygonFromText:            sqltypes.GeomPolygon,
+	sqlparser.MultiPointFromText:         sqltypes.GeomMultiPoint,
+	sqlparser.MultiPolygonFromText:       sqltypes.GeomMultiPolygon,
+	sqlparser.MultiLinestringFromText:    sqltypes.GeomMultiLineString,
+}
+
+var geomTypeFromWkb = [...]sqltypes.GeomType{
+	sqlparser.GeometryFromWKB:           0,
+	sqlparser.GeometryCollectionFromWKB: sqltypes.GeomCollection,
+	sqlparser.PointFromWKB:              sqltypes.GeomPoint,
+	sqlparser.LineStringFromWKB:         sqltypes.GeomLineString,
+	sqlparser.PolygonFromWKB:            sqltypes.GeomPolygon,
+	sqlparser.MultiPointFromWKB:         sqltypes.GeomMultiPoint,
+	sqlparser.MultiPolygonFromWKB:       sqltypes.GeomMultiPolygon,
+	sqlparser.MultiLinestringFromWKB:    sqltypes.GeomMultiLineString,
+}
+
+// translateRegexpArgs translates the arguments of a regular expression function,
+// skipping the trailing optional arguments that were not given. Patterns that
+// cannot be evaluated by Go's regexp package are not supported.
+func (ast *astCompiler) translateRegexpArgs(call sqlparser.Expr, exprs ...sqlparser.Expr) ([]Expr, error) {
+	for len(exprs) > 0 && exprs[len(exprs)-1] == nil {
+		exprs = exprs[:len(exprs)-1]
+	}
+	args, err := ast.translateFuncArgs(exprs)
+	if err != nil {
+		return nil, err
+	}
+	if regexpUnsupported(args[1]) {
+		return nil, translateExprNotSupported(call)
+	}
+	return args, nil
+}
+
+var intervalTypes = [...]datetime.IntervalType{
+	sqlparser.IntervalUnknown:           datetime.IntervalNone,
+	sqlparser.IntervalYear:              datetime.IntervalYear,
+	sqlparser.IntervalQuarter:           datetime.IntervalQuarter,
+	sqlparser.IntervalMonth:             datetime.IntervalMonth,
+	sqlparser.IntervalWeek:              datetime.IntervalWeek,
+	sqlparser.IntervalDay:               datetime.IntervalDay,
+	sqlparser.IntervalHour:              datetime.IntervalHour,
+	sqlparser.IntervalMinute:            datetime.IntervalMinute,
+	sqlparser.IntervalSecond:            datetime.IntervalSecond,
+	sqlparser.IntervalMicrosecond:       datetime.IntervalMicrosecond,
+	sqlparser.IntervalYearMonth:         datetime.IntervalYearMonth,
+	sqlparser.IntervalDayHour:           datetime.IntervalDayHour,
+	sqlparser.IntervalDayMinute:         datetime.IntervalDayMinute,
+	sqlparser.IntervalDaySecond:         datetime.IntervalDaySecond,
+	sqlparser.IntervalHourMinute:        datetime.IntervalHourMinute,
+	sqlparser.IntervalHourSecond:        datetime.IntervalHourSecond,
+	sqlparser.IntervalMinuteSecond:      datetime.IntervalMinuteSecond,
+	sqlparser.IntervalDayMicrosecond:    datetime.IntervalDayMicrosecond,
+	sqlparser.IntervalHourMicrosecond:   datetime.IntervalHourMicrosecond,
+	sqlparser.IntervalMinuteMicrosecond: datetime.IntervalMinuteMicrosecond,
+	sqlparser.IntervalSecondMicrosecond: datetime.IntervalSecondMicrosecond,
+}
+
+// translateDateMath translates DATE_ADD, DATE_SUB and their aliases. The short
+// forms ADDDATE(date, n) and SUBDATE(date, n) have no unit and use days.
+func (ast *astCompiler) translateDateMath(date, interval sqlparser.Expr, unit sqlparser.IntervalTypes, short bool, method string, sub bool) (Expr, error) {
+	args, err := ast.translateFuncArgs([]sqlparser.Expr{date, interval})
+	if err != nil {
+		return nil, err
+	}
+
+	var u datetime.IntervalType
+	if int(unit) < len(intervalTypes) {
+		u = intervalTypes[unit]
+	}
+	if u == datetime.IntervalNone {
+		if !short || unit != sqlparser.IntervalUnknown {
+			return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "invalid interval unit for %s", method)
+		}
+		u = datetime.IntervalDay
+	}
+
+	return &builtinDateMath{
+		CallExpr: CallExpr{Arguments: args, Method: method},
+		sub:      sub,
+		unit:     u,
+		collate:  ast.cfg.Collation,
+	}, nil
+}
+
 func builtinJSONExtractUnquoteRewrite(left Expr, right Expr) (Expr, error) {
 	extract, err := builtinJSONExtractRewrite(left, right)
 	if err != nil {
@@ -54,6 +54,7 @@ type CompiledExpr struct {
 	typed    sqltypes.Type
 	stack    int
 	original Expr
+	batch    batchExpr
 }
 
 func (p *CompiledExpr) eval(env *ExpressionEnv) (eval, error) {
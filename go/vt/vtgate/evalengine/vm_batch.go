@@ -0,0 +1,698 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package evalengine
+
+import (
+	"bytes"
+	"math"
+	"strconv"
+
+	"vitess.io/vitess/go/hack"
+	"vitess.io/vitess/go/mysql/collations"
+	"vitess.io/vitess/go/mysql/fastparse"
+	"vitess.io/vitess/go/mysql/format"
+	"vitess.io/vitess/go/sqltypes"
+)
+
+// The batch compiler evaluates an expression over a whole chunk of rows at once.
+// Instead of running the VM's instructions once per row, a batch program loads
+// each of the columns it references into a typed vector and then runs every
+// operation as a tight loop over the vectors, without going through the
+// generic eval types.
+//
+// Only a subset of expressions can be evaluated this way: comparisons,
+// arithmetic and logical operations on BIGINT, DOUBLE and binary string
+// columns and literals. Any row that a batch program cannot evaluate
+// with the exact same semantics as the AST interpreter (e.g. because its values
+// have a different type than the one the expression was compiled for, or
+// because the operation would overflow) is flagged as slow, and gets
+// evaluated again by the AST interpreter on its own, like the VM does when
+// it de-optimizes.
+
+type vectorType int8
+
+const (
+	vectorInt64 vectorType = iota
+	vectorFloat64
+	vectorBytes
+)
+
+// vector is a column of typed values, one for each row in a batch
+type vector struct {
+	typ  vectorType
+	i    []int64
+	f    []float64
+	b    [][]byte
+	null []bool
+}
+
+func newVector(typ vectorType, size int) *vector {
+	vec := &vector{typ: typ, null: make([]bool, size)}
+	switch typ {
+	case vectorInt64:
+		vec.i = make([]int64, size)
+	case vectorFloat64:
+		vec.f = make([]float64, size)
+	case vectorBytes:
+		vec.b = make([][]byte, size)
+	}
+	return vec
+}
+
+// appendValues appends the values in the vector to out. The textual
+// representation of all the values is stored in a single buffer.
+func (vec *vector) appendValues(out []sqltypes.Value) []sqltypes.Value {
+	var buf []byte
+	var offsets []int
+	switch vec.typ {
+	case vectorInt64:
+		buf = make([]byte, 0, len(vec.i)*4)
+		offsets = make([]int, 0, len(vec.i)+1)
+		for _, i := range vec.i {
+			offsets = append(offsets, len(buf))
+			buf = strconv.AppendInt(buf, i, 10)
+		}
+	case vectorFloat64:
+		buf = make([]byte, 0, len(vec.f)*8)
+		offsets = make([]int, 0, len(vec.f)+1)
+		for _, f := range vec.f {
+			offsets = append(offsets, len(buf))
+			buf = format.AppendFloat(buf, f)
+		}
+	}
+	offsets = append(offsets, len(buf))
+
+	typ := sqltypes.Int64
+	if vec.typ == vectorFloat64 {
+		typ = sqltypes.Float64
+	}
+	for n, null := range vec.null {
+		switch {
+		case null:
+			out = append(out, sqltypes.NULL)
+		case vec.typ == vectorBytes:
+			out = append(out, sqltypes.MakeTrusted(sqltypes.VarBinary, vec.b[n]))
+		default:
+			out = append(out, sqltypes.MakeTrusted(typ, buf[offsets[n]:offsets[n+1]:offsets[n+1]]))
+		}
+	}
+	return out
+}
+
+func (vec *vector) truthy(n int) bool {
+	if vec.null[n] {
+		return false
+	}
+	switch vec.typ {
+	case vectorInt64:
+		return vec.i[n] != 0
+	case vectorFloat64:
+		return vec.f[n] != 0.0
+	default:
+		panic("truthy() on a bytes vector")
+	}
+}
+
+func (vec *vector) toFloat() *vector {
+	if vec.typ == vectorFloat64 {
+		return vec
+	}
+	f := make([]float64, len(vec.i))
+	for n, i := range vec.i {
+		f[n] = float64(i)
+	}
+	return &vector{typ: vectorFloat64, f: f, null: vec.null}
+}
+
+// batchExpr is a node in a batch program
+type batchExpr interface {
+	typeof() vectorType
+	run(rows []sqltypes.Row, slow []bool) *vector
+}
+
+type (
+	batchColumn struct {
+		offset int
+		typ    vectorType
+	}
+
+	batchLiteral struct {
+		literal eval
+		typ     vectorType
+	}
+
+	batchCompare struct {
+		left, right batchExpr
+		op          ComparisonOp
+	}
+
+	batchArith struct {
+		left, right batchExpr
+		op          opArith
+		typ         vectorType
+	}
+
+	batchLogical struct {
+		left, right batchExpr
+		opname      string
+	}
+
+	batchNot struct {
+		inner batchExpr
+	}
+)
+
+// compileBatch returns the batch program for the given expression, or nil if the
+// expression cannot be evaluated in batches. Plain columns and literals are not
+// worth evaluating in batches on their own, so they're only supported as the
+// arguments of another operation.
+func compileBatch(expr Expr) batchExpr {
+	switch expr.(type) {
+	case *ComparisonExpr, *ArithmeticExpr, *LogicalExpr, *NotExpr:
+		return compileBatchExpr(expr)
+	default:
+		return nil
+	}
+}
+
+func compileBatchExpr(expr Expr) batchExpr {
+	switch expr := expr.(type) {
+	case *Column:
+		if !expr.typed {
+			return nil
+		}
+		switch tt := expr.Type; {
+		case sqltypes.IsSigned(tt):
+			return &batchColumn{offset: expr.Offset, typ: vectorInt64}
+		case sqltypes.IsFloat(tt):
+			return &batchColumn{offset: expr.Offset, typ: vectorFloat64}
+		case sqltypes.IsBinary(tt) && expr.Collation.Collation == collations.CollationBinaryID:
+			return &batchColumn{offset: expr.Offset, typ: vectorBytes}
+		}
+
+	case *Literal:
+		switch lit := expr.inner.(type) {
+		case *evalInt64:
+			return &batchLiteral{literal: lit, typ: vectorInt64}
+		case *evalFloat:
+			return &batchLiteral{literal: lit, typ: vectorFloat64}
+		case *evalBytes:
+			if lit.col.Collation == collations.CollationBinaryID && !lit.isHexLiteral && !lit.isBitLiteral {
+				return &batchLiteral{literal: lit, typ: vectorBytes}
+			}
+		}
+
+	case *ComparisonExpr:
+		switch expr.Op.(type) {
+		case compareEQ, compareNE, compareLT, compareLE, compareGT, compareGE:
+		default:
+			return nil
+		}
+		left, right := compileBatchExpr(expr.Left), compileBatchExpr(expr.Right)
+		if left == nil || right == nil || left.typeof() != right.typeof() {
+			return nil
+		}
+		return &batchCompare{left: left, right: right, op: expr.Op}
+
+	case *ArithmeticExpr:
+		switch expr.Op.(type) {
+		case *opArithAdd, *opArithSub, *opArithMul:
+		default:
+			return nil
+		}
+		left, right := compileBatchExpr(expr.Left), compileBatchExpr(expr.Right)
+		if left == nil || right == nil || left.typeof() == vectorBytes || right.typeof() == vectorBytes {
+			return nil
+		}
+		typ := vectorInt64
+		if left.typeof() == vectorFloat64 || right.typeof() == vectorFloat64 {
+			typ = vectorFloat64
+		}
+		return &batchArith{left: left, right: right, op: expr.Op, typ: typ}
+
+	case *LogicalExpr:
+		left, right := compileBatchExpr(expr.Left), compileBatchExpr(expr.Right)
+		if left == nil || right == nil || left.typeof() == vectorBytes || right.typeof() == vectorBytes {
+			return nil
+		}
+		return &batchLogical{left: left, right: right, opname: expr.opname}
+
+	case *NotExpr:
+		inner := compileBatchExpr(expr.Inner)
+		if inner == nil || inner.typeof() == vectorBytes {
+			return nil
+		}
+		return &batchNot{inner: inner}
+	}
+	return nil
+}
+
+func (expr *batchColumn) typeof() vectorType {
+	return expr.typ
+}
+
+func (expr *batchColumn) run(rows []sqltypes.Row, slow []bool) *vector {
+	vec := newVector(expr.typ, len(rows))
+	for n, row := range rows {
+		if expr.offset >= len(row) {
+			slow[n] = true
+			continue
+		}
+		v := row[expr.offset]
+		if v.IsNull() {
+			vec.null[n] = true
+			continue
+		}
+
+		var err error
+		switch expr.typ {
+		case vectorInt64:
+			if !v.IsSigned() {
+				slow[n] = true
+				continue
+			}
+			vec.i[n], err = fastparse.ParseInt64(hack.String(v.Raw()), 10)
+		case vectorFloat64:
+			if !v.IsFloat() {
+				slow[n] = true
+				continue
+			}
+			vec.f[n], err = fastparse.ParseFloat64(hack.String(v.Raw()))
+		case vectorBytes:
+			if !v.IsBinary() {
+				slow[n] = true
+				continue
+			}
+			vec.b[n] = v.Raw()
+		}
+		if err != nil {
+			slow[n] = true
+		}
+	}
+	return vec
+}
+
+func (expr *batchLiteral) typeof() vectorType {
+	return expr.typ
+}
+
+func (expr *batchLiteral) run(rows []sqltypes.Row, _ []bool) *vector {
+	vec := newVector(expr.typ, len(rows))
+	switch lit := expr.literal.(type) {
+	case *evalInt64:
+		for n := range vec.i {
+			vec.i[n] = lit.i
+		}
+	case *evalFloat:
+		for n := range vec.f {
+			vec.f[n] = lit.f
+		}
+	case *evalBytes:
+		for n := range vec.b {
+			vec.b[n] = lit.bytes
+		}
+	}
+	return vec
+}
+
+func (expr *batchCompare) typeof() vectorType {
+	return vectorInt64
+}
+
+func (expr *batchCompare) run(rows []sqltypes.Row, slow []bool) *vector {
+	left := expr.left.run(rows, slow)
+	right := expr.right.run(rows, slow)
+	vec := newVector(vectorInt64, len(rows))
+
+	// first store the result of comparing each pair of values in the output
+	// vector, and then map that result into a boolean for the operator
+	cmp := vec.i
+	switch left.typ {
+	case vectorInt64:
+		for n, l := range left.i {
+			switch r := right.i[n]; {
+			case l < r:
+				cmp[n] = -1
+			case l > r:
+				cmp[n] = 1
+			}
+		}
+	case vectorFloat64:
+		for n, l := range left.f {
+			switch r := right.f[n]; {
+			case l < r:
+				cmp[n] = -1
+			case l > r:
+				cmp[n] = 1
+			}
+		}
+	case vectorBytes:
+		for n, l := range left.b {
+			cmp[n] = int64(bytes.Compare(l, right.b[n]))
+		}
+	}
+
+	var match func(c int64) bool
+	switch expr.op.(type) {
+	case compareEQ:
+		match = func(c int64) bool { return c == 0 }
+	case compareNE:
+		match = func(c int64) bool { return c != 0 }
+	case compareLT:
+		match = func(c int64) bool { return c < 0 }
+	case compareLE:
+		match = func(c int64) bool { return c <= 0 }
+	case compareGT:
+		match = func(c int64) bool { return c > 0 }
+	case compareGE:
+		match = func(c int64) bool { return c >= 0 }
+	}
+
+	for n, c := range cmp {
+		cmp[n] = 0
+		switch {
+		case slow[n]:
+		case left.null[n] || right.null[n]:
+			vec.null[n] = true
+		case match(c):
+			cmp[n] = 1
+		}
+	}
+	return vec
+}
+
+func (expr *batchArith) typeof() vectorType {
+	return expr.typ
+}
+
+func (expr *batchArith) run(rows []sqltypes.Row, slow []bool) *vector {
+	left := expr.left.run(rows, slow)
+	right := expr.right.run(rows, slow)
+	vec := newVector(expr.typ, len(rows))
+
+	for n := range vec.null {
+		vec.null[n] = left.null[n] || right.null[n]
+	}
+
+	if expr.typ == vectorFloat64 {
+		left, right = left.toFloat(), right.toFloat()
+		switch expr.op.(type) {
+		case *opArithAdd:
+			for n, l := range left.f {
+				vec.f[n] = l + right.f[n]
+			}
+		case *opArithSub:
+			for n, l := range left.f {
+				vec.f[n] = l - right.f[n]
+			}
+		case *opArithMul:
+			for n, l := range left.f {
+				vec.f[n] = l * right.f[n]
+			}
+		}
+		for n, f := range vec.f {
+			if !vec.null[n] && (math.IsInf(f, 0) || math.IsNaN(f)) {
+				// let the AST interpreter handle the values that are out of range
+				slow[n] = true
+			}
+		}
+		return vec
+	}
+
+	var arith func(l, r int64) (int64, error)
+	switch expr.op.(type) {
+	case *opArithAdd:
+		arith = mathAdd_ii0
+	case *opArithSub:
+		arith = mathSub_ii0
+	case *opArithMul:
+		arith = mathMul_ii0
+	}
+
+	for n, l := range left.i {
+		if vec.null[n] {
+			continue
+		}
+		var err error
+		if vec.i[n], err = arith(l, right.i[n]); err != nil {
+			// let the AST interpreter return the out of range error for this row
+			slow[n] = true
+		}
+	}
+	return vec
+}
+
+func (expr *batchLogical) typeof() vectorType {
+	return vectorInt64
+}
+
+func (expr *batchLogical) run(rows []sqltypes.Row, slow []bool) *vector {
+	left := expr.left.run(rows, slow)
+	right := expr.right.run(rows, slow)
+	vec := newVector(vectorInt64, len(rows))
+
+	for n := range rows {
+		if slow[n] {
+			continue
+		}
+		l, r := left.truthy(n), right.truthy(n)
+		lnull, rnull := left.null[n], right.null[n]
+
+		var result boolean
+		switch expr.opname {
+		case "AND":
+			switch {
+			case !lnull && !l, !rnull && !r:
+				result = boolFalse
+			case lnull || rnull:
+				result = boolNULL
+			default:
+				result = boolTrue
+			}
+		case "OR":
+			switch {
+			case l || r:
+				result = boolTrue
+			case lnull || rnull:
+				result = boolNULL
+			default:
+				result = boolFalse
+			}
+		case "XOR":
+			result = makeboolean2(l != r, lnull || rnull)
+		}
+
+		switch result {
+		case boolNULL:
+			vec.null[n] = true
+		case boolTrue:
+			vec.i[n] = 1
+		}
+	}
+	return vec
+}
+
+func (expr *batchNot) typeof() vectorType {
+	return vectorInt64
+}
+
+func (expr *batchNot) run(rows []sqltypes.Row, slow []bool) *vector {
+	inner := expr.inner.run(rows, slow)
+	vec := newVector(vectorInt64, len(rows))
+	for n := range rows {
+		switch {
+		case slow[n]:
+		case inner.null[n]:
+			vec.null[n] = true
+		case !inner.truthy(n):
+			vec.i[n] = 1
+		}
+	}
+	return vec
+}
+
+// EvaluateBatch evaluates expr for each one of the given rows and appends the
+// results to out. When evaluating a compiled expression, EvaluateBatch will use
+// the batch compiler to process all the rows at once if the expression supports it.
+// The current row of the ExpressionEnv is overwritten during the evaluation.
+func (env *ExpressionEnv) EvaluateBatch(expr Expr, rows []sqltypes.Row, out []sqltypes.Value) ([]sqltypes.Value, error) {
+	if p, ok := expr.(*CompiledExpr); ok && p.batch != nil && len(rows) > 0 {
+		slow := make([]bool, len(rows))
+		vec := p.batch.run(rows, slow)
+
+		start := len(out)
+		out = vec.appendValues(out)
+		for n, row := range rows {
+			if !slow[n] {
+				continue
+			}
+			env.Row = row
+			e, err := p.original.eval(env)
+			if err != nil {
+				return out[:start+n], err
+			}
+			out[start+n] = evalToSQLValue(e)
+		}
+		return out, nil
+	}
+
+	for _, row := range rows {
+		env.Row = row
+		res, err := env.Evaluate(expr)
+		if err != nil {
+			return out, err
+		}
+		out = append(out, res.Value())
+	}
+	return out, nil
+}
+
+// FilterBatch returns the rows for which the given predicate evaluates to 1.
+// The input rows are not modified. Like with EvaluateBatch, compiled predicates
+// are evaluated with the batch compiler whenever possible.
+func (env *ExpressionEnv) FilterBatch(predicate Expr, rows []sqltypes.Row) ([]sqltypes.Row, error) {
+	var selected []sqltypes.Row
+
+	if p, ok := predicate.(*CompiledExpr); ok && p.batch != nil && p.batch.typeof() == vectorInt64 && len(rows) > 0 {
+		slow := make([]bool, len(rows))
+		vec := p.batch.run(rows, slow)
+		for n, row := range rows {
+			if !slow[n] {
+				if vec.null[n] {
+					// like when evaluating row by row, NULL is not a valid result
+					return nil, sqltypes.ErrIncompatibleTypeCast
+				}
+				if vec.i[n] == 1 {
+					selected = append(selected, row)
+				}
+				continue
+			}
+			env.Row = row
+			e, err := p.original.eval(env)
+			if err != nil {
+				return nil, err
+			}
+			match, err := isFilterMatch(evalToSQLValue(e))
+			if err != nil {
+				return nil, err
+			}
+			if match {
+				selected = append(selected, row)
+			}
+		}
+		return selected, nil
+	}
+
+	for _, row := range rows {
+		env.Row = row
+		res, err := env.Evaluate(predicate)
+		if err != nil {
+			return nil, err
+		}
+		match, err := isFilterMatch(res.Value())
+		if err != nil {
+			return nil, err
+		}
+		if match {
+			selected = append(selected, row)
+		}
+	}
+	return selected, nil
+}
+
+// isFilterMatch returns true if the result of a predicate selects its row:
+// only predicates that evaluate to exactly 1 do. Results that are not integers,
+// including NULL, are an error.
+func isFilterMatch(v sqltypes.Value) (bool, error) {
+	i, err := v.ToInt64()
+	if err != nil {
+		return false, err
+	}
+	return i == 1, nil
+}
+
+// AggregateSum adds the values in the given column of all the rows to sum, and
+// returns the result as a value of resultType. NULL values are skipped, and the
+// result is NULL only if sum and all the values are NULL. The result is the same
+// as adding each value in turn with NullSafeAdd, but the partial sums are not
+// converted back into a sqltypes.Value for every row unless their type differs
+// from resultType.
+func AggregateSum(sum sqltypes.Value, rows []sqltypes.Row, col int, resultType sqltypes.Type) (sqltypes.Value, error) {
+	var acc eval
+	var err error
+	if !sum.IsNull() {
+		if acc, err = valueToEval(sum, collationNumeric); err != nil {
+			return sqltypes.NULL, err
+		}
+	}
+	for _, row := range rows {
+		v := row[col]
+		if v.IsNull() {
+			if acc != nil {
+				// adding a NULL value still casts the sum to the result type
+				if acc, err = castSum(acc, resultType); err != nil {
+					return sqltypes.NULL, err
+				}
+			}
+			continue
+		}
+		if acc == nil {
+			if acc, err = valueToEval(sqltypes.MakeTrusted(resultType, zeroBytes), collationNumeric); err != nil {
+				return sqltypes.NULL, err
+			}
+		}
+		e, err := valueToEval(v, collationNumeric)
+		if err != nil {
+			return sqltypes.NULL, err
+		}
+		r, err := addNumericWithError(acc, e)
+		if err != nil {
+			return sqltypes.NULL, err
+		}
+		if acc, err = castSum(r, resultType); err != nil {
+			return sqltypes.NULL, err
+		}
+	}
+	if acc == nil {
+		return sqltypes.NULL, nil
+	}
+	return evalToSQLValueWithType(acc, resultType), nil
+}
+
+// castSum returns the partial sum e as if it had been converted into a value
+// of resultType and back, like NullSafeAdd does after every addition.
+func castSum(e eval, resultType sqltypes.Type) (eval, error) {
+	switch e.(type) {
+	case *evalInt64:
+		if sqltypes.IsSigned(resultType) {
+			return e, nil
+		}
+	case *evalUint64:
+		if sqltypes.IsUnsigned(resultType) {
+			return e, nil
+		}
+	case *evalFloat:
+		if sqltypes.IsFloat(resultType) {
+			return e, nil
+		}
+	case *evalDecimal:
+		if sqltypes.IsDecimal(resultType) {
+			return e, nil
+		}
+	}
+	return valueToEval(evalToSQLValueWithType(e, resultType), collationNumeric)
+}
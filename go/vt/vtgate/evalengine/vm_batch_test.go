@@ -0,0 +1,281 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package evalengine
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/mysql/collations"
+	"vitess.io/vitess/go/sqltypes"
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	"vitess.io/vitess/go/vt/sqlparser"
+)
+
+var batchFields = []*querypb.Field{
+	{Name: "i1", Type: sqltypes.Int64, Charset: collations.CollationBinaryID},
+	{Name: "i2", Type: sqltypes.Int64, Charset: collations.CollationBinaryID},
+	{Name: "f1", Type: sqltypes.Float64, Charset: collations.CollationBinaryID},
+	{Name: "b1", Type: sqltypes.VarBinary, Charset: collations.CollationBinaryID},
+	{Name: "u1", Type: sqltypes.Uint64, Charset: collations.CollationBinaryID},
+}
+
+// makeBatchRows returns rows with random values for batchFields, including NULLs,
+// values that overflow when used in arithmetic, and values that don't match the
+// type of their field so they must be evaluated by the VM.
+func makeBatchRows(r *rand.Rand, count int) []sqltypes.Row {
+	ints := []int64{0, 1, -1, 2, 42, -42, math.MaxInt64, math.MinInt64}
+	floats := []float64{0, 1, -1, 0.5, 1e20, -2.5}
+	binaries := []string{"", "a", "ab", "b", "\xff"}
+
+	rows := make([]sqltypes.Row, 0, count)
+	for n := 0; n < count; n++ {
+		row := sqltypes.Row{
+			sqltypes.NewInt64(ints[r.Intn(len(ints))]),
+			sqltypes.NewInt64(int64(r.Intn(100) - 50)),
+			sqltypes.NewFloat64(floats[r.Intn(len(floats))]),
+			sqltypes.NewVarBinary(binaries[r.Intn(len(binaries))]),
+			sqltypes.NewUint64(uint64(r.Intn(10))),
+		}
+		switch r.Intn(10) {
+		case 0:
+			row[r.Intn(len(row))] = sqltypes.NULL
+		case 1:
+			row[0] = sqltypes.NewUint64(math.MaxUint64)
+		case 2:
+			row[2] = sqltypes.NewDecimal("1.5")
+		case 3:
+			row[3] = sqltypes.NewVarChar("a")
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+func TestEvaluateBatch(t *testing.T) {
+	var testCases = []struct {
+		expression string
+		batched    bool
+	}{
+		{"i1 = 42", true},
+		{"i1 != i2", true},
+		{"i2 < 10 and f1 >= 0.5e0", true},
+		{"i1 > 0 or i2 <= 0", true},
+		{"i1 > 0 xor f1 > 0e0", true},
+		{"not i2 > 0", true},
+		{"not i1", true},
+		{"i1 + i2", true},
+		{"i1 - i2 * 2", true},
+		{"i1 * i1", true},
+		{"f1 * 2", true},
+		{"i2 + f1 - 1.5e0", true},
+		{"f1 * 1e300", true},
+		{"(i1 + 1) * f1 > 10e0", true},
+		{"b1 = _binary'a'", true},
+		{"b1 < _binary'b'", true},
+		{"b1 = 'a'", false},
+		{"i1 / 2", false},
+		{"u1 = 1", false},
+		{"i1 <=> 1", false},
+		{"i1 = 1.5", false},
+		{"i1", false},
+		{"concat(b1, b1)", false},
+	}
+
+	r := rand.New(rand.NewSource(1234))
+	rows := makeBatchRows(r, 1000)
+
+	for _, tc := range testCases {
+		t.Run(tc.expression, func(t *testing.T) {
+			ast, err := sqlparser.ParseExpr(tc.expression)
+			require.NoError(t, err)
+
+			fields := FieldResolver(batchFields)
+			cfg := &Config{
+				ResolveColumn: fields.Column,
+				ResolveType:   fields.Type,
+				Collation:     collations.CollationUtf8mb4ID,
+				Optimization:  OptimizationLevelCompile,
+			}
+			expr, err := Translate(ast, cfg)
+			require.NoError(t, err)
+			require.NoError(t, cfg.CompilerErr)
+
+			compiled := expr.(*CompiledExpr)
+			assert.Equal(t, tc.batched, compiled.batch != nil)
+
+			// batched expressions must return the same results as the AST
+			// interpreter; the others are evaluated by the VM one row at a time
+			env := EmptyExpressionEnv()
+			reference := func(row sqltypes.Row) (EvalResult, error) {
+				env.Row = row
+				if tc.batched {
+					return env.Evaluate(Deoptimize(expr))
+				}
+				return env.Evaluate(expr)
+			}
+
+			// results from the VM are only valid until the next evaluation, so
+			// they're converted to values right away
+			var valid, filtered []sqltypes.Row
+			var want []sqltypes.Value
+			var filterErr error
+			for _, row := range rows {
+				res, wantErr := reference(row)
+
+				got, err := env.EvaluateBatch(expr, []sqltypes.Row{row}, nil)
+				if wantErr != nil {
+					assert.EqualError(t, err, wantErr.Error())
+					continue
+				}
+				require.NoError(t, err)
+				assert.Equal(t, res.Value().String(), got[0].String(), "row %v", row)
+
+				valid = append(valid, row)
+				want = append(want, res.Value())
+				match, err := isFilterMatch(res.Value())
+				if err != nil && filterErr == nil {
+					filterErr = err
+				}
+				if match {
+					filtered = append(filtered, row)
+				}
+			}
+
+			// evaluating all the rows that don't fail in a single batch must
+			// return the same results
+			out, err := env.EvaluateBatch(expr, valid, nil)
+			require.NoError(t, err)
+			require.Len(t, out, len(valid))
+
+			for n, row := range valid {
+				assert.Equal(t, want[n].String(), out[n].String(), "row %v", row)
+			}
+
+			// filtering must not modify the input rows
+			input := append([]sqltypes.Row(nil), valid...)
+			selected, err := env.FilterBatch(expr, input)
+			assert.Equal(t, valid, input)
+			if filterErr != nil {
+				require.EqualError(t, err, filterErr.Error())
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, filtered, selected)
+		})
+	}
+}
+
+func TestEvaluateBatchErrors(t *testing.T) {
+	ast, err := sqlparser.ParseExpr("i1 + 1")
+	require.NoError(t, err)
+
+	fields := FieldResolver(batchFields)
+	expr, err := Translate(ast, &Config{
+		ResolveColumn: fields.Column,
+		ResolveType:   fields.Type,
+		Optimization:  OptimizationLevelCompile,
+	})
+	require.NoError(t, err)
+
+	rows := []sqltypes.Row{
+		{sqltypes.NewInt64(1)},
+		{sqltypes.NewInt64(math.MaxInt64)},
+	}
+	env := EmptyExpressionEnv()
+	_, err = env.EvaluateBatch(expr, rows, nil)
+	require.EqualError(t, err, "BIGINT value is out of range in '(9223372036854775807 + 1)'")
+
+	_, err = env.FilterBatch(expr, rows)
+	require.EqualError(t, err, "BIGINT value is out of range in '(9223372036854775807 + 1)'")
+}
+
+func TestFilterBatchMatchesOne(t *testing.T) {
+	ast, err := sqlparser.ParseExpr("i1 + i2")
+	require.NoError(t, err)
+
+	fields := FieldResolver(batchFields)
+	expr, err := Translate(ast, &Config{
+		ResolveColumn: fields.Column,
+		ResolveType:   fields.Type,
+		Optimization:  OptimizationLevelCompile,
+	})
+	require.NoError(t, err)
+
+	// like the row by row evaluation of a Filter, only the rows for which
+	// the predicate is exactly 1 are selected
+	rows := []sqltypes.Row{
+		{sqltypes.NewInt64(1), sqltypes.NewInt64(0)},
+		{sqltypes.NewInt64(1), sqltypes.NewInt64(1)},
+		{sqltypes.NewInt64(-1), sqltypes.NewInt64(2)},
+	}
+	env := EmptyExpressionEnv()
+	for _, e := range []Expr{expr, Deoptimize(expr)} {
+		selected, err := env.FilterBatch(e, rows)
+		require.NoError(t, err)
+		assert.Equal(t, []sqltypes.Row{rows[0], rows[2]}, selected)
+
+		// a NULL result is an error
+		_, err = env.FilterBatch(e, append(rows, sqltypes.Row{sqltypes.NULL, sqltypes.NewInt64(1)}))
+		assert.ErrorIs(t, err, sqltypes.ErrIncompatibleTypeCast)
+	}
+}
+
+func TestAggregateSum(t *testing.T) {
+	var testCases = []struct {
+		sum        sqltypes.Value
+		values     []sqltypes.Value
+		resultType sqltypes.Type
+	}{
+		{sqltypes.NULL, nil, sqltypes.Int64},
+		{sqltypes.NULL, []sqltypes.Value{sqltypes.NULL, sqltypes.NULL}, sqltypes.Int64},
+		{sqltypes.NULL, []sqltypes.Value{sqltypes.NewInt64(1), sqltypes.NULL, sqltypes.NewInt64(-3)}, sqltypes.Int64},
+		{sqltypes.NewInt64(10), []sqltypes.Value{sqltypes.NULL}, sqltypes.Decimal},
+		{sqltypes.NewDecimal("1.25"), []sqltypes.Value{sqltypes.NewDecimal("2.5"), sqltypes.NewInt64(3)}, sqltypes.Decimal},
+		{sqltypes.NewInt64(1), []sqltypes.Value{sqltypes.NewFloat64(0.1), sqltypes.NewFloat64(0.2)}, sqltypes.Float64},
+		{sqltypes.NewFloat64(0.5), []sqltypes.Value{sqltypes.NewInt64(1), sqltypes.NewFloat64(0.7)}, sqltypes.Int64},
+		{sqltypes.NewUint64(1), []sqltypes.Value{sqltypes.NewUint64(math.MaxUint64 - 1), sqltypes.NewInt64(-2)}, sqltypes.Uint64},
+		{sqltypes.NewVarChar("1.5"), []sqltypes.Value{sqltypes.NewVarChar("2")}, sqltypes.Decimal},
+	}
+
+	for _, tc := range testCases {
+		rows := make([]sqltypes.Row, 0, len(tc.values))
+		want := tc.sum
+		var wantErr error
+		for _, v := range tc.values {
+			rows = append(rows, sqltypes.Row{sqltypes.NULL, v})
+			if want.IsNull() && v.IsNull() {
+				continue
+			}
+			if wantErr == nil {
+				want, wantErr = NullSafeAdd(want, v, tc.resultType)
+			}
+		}
+
+		got, err := AggregateSum(tc.sum, rows, 1, tc.resultType)
+		if wantErr != nil {
+			assert.EqualError(t, err, wantErr.Error(), "%v + %v", tc.sum, tc.values)
+			continue
+		}
+		require.NoError(t, err, "%v + %v", tc.sum, tc.values)
+		assert.Equal(t, want.String(), got.String(), "%v + %v", tc.sum, tc.values)
+	}
+}
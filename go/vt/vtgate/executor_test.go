@@ -2557,6 +2557,35 @@ func TestExecutorVExplainQueries(t *testing.T) {
 	require.Contains(t, txt, lookupQuery)
 }
 
+func TestExecutorVExplainJSON(t *testing.T) {
+	executor, _, _, sbclookup := createExecutorEnv()
+	session := NewAutocommitSession(&vtgatepb.Session{})
+
+	sbclookup.SetResults([]*sqltypes.Result{
+		sqltypes.MakeTestResult(sqltypes.MakeTestFields("name|user_id", "varchar|int64"), "apa|1", "apa|2"),
+	})
+	qr, err := executor.Execute(ctx, "TestExecutorVExplainJSON", session, "vexplain format=json select * from user where name = 'apa'", nil)
+	require.NoError(t, err)
+	require.Len(t, qr.Rows, 1)
+
+	var plan map[string]any
+	require.NoError(t, json.Unmarshal([]byte(qr.Rows[0][0].ToString()), &plan))
+	assert.Equal(t, "Route", plan["OperatorType"])
+
+	stats := plan["Stats"].(map[string]any)
+	assert.EqualValues(t, 1, stats["Calls"])
+	assert.EqualValues(t, 1, stats["Rows"])
+	assert.Contains(t, stats, "WallTimeMicros")
+	assert.EqualValues(t, 1, stats["ShardsQueried"])
+
+	shards := stats["Shards"].([]any)
+	require.Len(t, shards, 1)
+	shard := shards[0].(map[string]any)
+	assert.Equal(t, "TestExecutor", shard["Keyspace"])
+	assert.EqualValues(t, 1, shard["Queries"])
+	assert.EqualValues(t, 1, shard["RowsReceived"])
+}
+
 func TestExecutorStartTxnStmt(t *testing.T) {
 	executor, _, _, _ := createExecutorEnv()
 	session := NewAutocommitSession(&vtgatepb.Session{})
@@ -345,6 +345,10 @@ func routeToEngineRoute(ctx *plancontext.PlanningContext, op *operators.Route) (
 	if err != nil {
 		return nil, err
 	}
+	err = operators.UpdateTenantRoutingParams(ctx, op, rp)
+	if err != nil {
+		return nil, err
+	}
 
 	return &engine.Route{
 		TableName:           strings.Join(tableNames, ", "),
@@ -394,6 +398,7 @@ func transformRoutePlan(ctx *plancontext.PlanningContext, op *operators.Route) (
 	if err != nil {
 		return nil, err
 	}
+	operators.FilterMovedTenants(ctx, eroute.RoutingParameters, sel)
 	return &routeGen4{
 		eroute:    eroute,
 		Select:    sel,
@@ -414,6 +419,8 @@ func transformInsertPlan(ctx *plancontext.PlanningContext, op *operators.Route,
 		ColVindexes:       ins.ColVindexes,
 		VindexValues:      ins.VindexValues,
 		VindexValueOffset: ins.VindexValueOffset,
+		TenantValues:      ins.TenantValues,
+		TenantRoutes:      ins.TenantRoutes,
 	}
 	i = &insert{eInsert: eins}
 
@@ -512,6 +519,10 @@ func transformUpdatePlan(ctx *plancontext.PlanningContext, op *operators.Route,
 	if err != nil {
 		return nil, err
 	}
+	err = operators.UpdateTenantRoutingParams(ctx, op, rp)
+	if err != nil {
+		return nil, err
+	}
 	edml := &engine.DML{
 		Query: generateQuery(ast),
 		Table: []*vindexes.Table{
@@ -539,6 +550,10 @@ func transformDeletePlan(ctx *plancontext.PlanningContext, op *operators.Route,
 	if err != nil {
 		return nil, err
 	}
+	err = operators.UpdateTenantRoutingParams(ctx, op, rp)
+	if err != nil {
+		return nil, err
+	}
 	edml := &engine.DML{
 		Query: generateQuery(ast),
 		Table: []*vindexes.Table{
@@ -18,6 +18,7 @@ package operators
 
 import (
 	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/vtgate/engine"
 	"vitess.io/vitess/go/vt/vtgate/evalengine"
 	"vitess.io/vitess/go/vt/vtgate/planbuilder/operators/ops"
 	"vitess.io/vitess/go/vt/vtgate/vindexes"
@@ -50,6 +51,12 @@ type Insert struct {
 	// Insert using select query will have select plan as input operator for the insert operation.
 	Input ops.Operator
 
+	// TenantValues are the tenants of the inserted rows when tenants have been
+	// moved out of the keyspace, or are being moved. TenantRoutes are the
+	// routes of those tenants, whose rows are rejected.
+	TenantValues []evalengine.Expr
+	TenantRoutes map[string]*engine.TenantRoute
+
 	noColumns
 	noPredicates
 }
@@ -117,6 +124,8 @@ func (i *Insert) Clone(inputs []ops.Operator) ops.Operator {
 		ColVindexes:       i.ColVindexes,
 		VindexValues:      i.VindexValues,
 		VindexValueOffset: i.VindexValueOffset,
+		TenantValues:      i.TenantValues,
+		TenantRoutes:      i.TenantRoutes,
 	}
 }
 
@@ -284,6 +284,11 @@ func createOperatorFromInsert(ctx *plancontext.PlanningContext, ins *sqlparser.I
 	insOp.Ignore = bool(ins.Ignore) || ins.OnDup != nil
 
 	insOp.ColVindexes = getColVindexes(insOp)
+	// The tenants are read before the vindex values of the rows are replaced
+	// by arguments.
+	if err := setTenantInsertValues(ctx, insOp, ins); err != nil {
+		return nil, err
+	}
 	switch rows := ins.Rows.(type) {
 	case sqlparser.Values:
 		route.Source, err = insertRowsPlan(insOp, ins, rows)
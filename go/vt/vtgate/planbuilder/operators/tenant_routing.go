@@ -0,0 +1,298 @@
+/*
+Copyright 2021 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package operators
+
+import (
+	"fmt"
+
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vtgate/engine"
+	"vitess.io/vitess/go/vt/vtgate/evalengine"
+	"vitess.io/vitess/go/vt/vtgate/planbuilder/operators/ops"
+	"vitess.io/vitess/go/vt/vtgate/planbuilder/operators/rewrite"
+	"vitess.io/vitess/go/vt/vtgate/planbuilder/plancontext"
+	"vitess.io/vitess/go/vt/vtgate/vindexes"
+)
+
+// UpdateTenantRoutingParams sets the tenant of the query on the routing
+// parameters when tenants have been moved out of the keyspace of the route,
+// or are being moved, so that the queries of those tenants are sent to their
+// new keyspace. The tenant of a query is given by a <tenant column> = <value>
+// predicate on one of its tables. UPDATEs and DELETEs without such a predicate
+// are rejected: they would miss the rows of the moved tenants, and bypass the
+// fence on the writes of the tenants being switched.
+func UpdateTenantRoutingParams(ctx *plancontext.PlanningContext, op *Route, rp *engine.RoutingParameters) error {
+	if rp.Keyspace == nil {
+		return nil
+	}
+	tr := ctx.VSchema.GetVSchema().FindTenantRouting(rp.Keyspace.Name)
+	if tr == nil {
+		return nil
+	}
+
+	var (
+		tenant     evalengine.Expr
+		table      *vindexes.Table
+		predicates []sqlparser.Expr
+		dml        string
+	)
+	err := rewrite.Visit(op, func(op ops.Operator) error {
+		if tenant != nil {
+			return nil
+		}
+		var (
+			qt   *QueryTable
+			vt   *vindexes.Table
+			stmt string
+		)
+		switch op := op.(type) {
+		case *Table:
+			qt, vt = op.QTable, op.VTable
+		case *Update:
+			qt, vt, stmt = op.QTable, op.VTable, "UPDATE"
+		case *Delete:
+			qt, vt, stmt = op.QTable, op.VTable, "DELETE"
+		default:
+			return nil
+		}
+		if qt == nil || vt == nil || vt.Keyspace == nil || vt.Keyspace.Name != rp.Keyspace.Name {
+			return nil
+		}
+		tenant = findColumnValue(ctx, qt.Predicates, tr.Column)
+		if stmt != "" {
+			dml = stmt
+		}
+		table, predicates = vt, qt.Predicates
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if tenant == nil && dml != "" && tr.AppliesTo(table) {
+		return vterrors.VT12001(fmt.Sprintf("%s without a %s = <value> predicate on table %s while tenants of keyspace %s are moved", dml, tr.Column, table.Name.String(), rp.Keyspace.Name))
+	}
+	if tenant != nil {
+		rp.TenantValue = tenant
+		rp.TenantRoutes = tenantRoutes(ctx, tr, table, tenant, predicates, dml != "")
+	}
+	return nil
+}
+
+// setTenantInsertValues sets the tenants of the inserted rows on the insert
+// when tenants have been moved out of its keyspace, or are being moved, so
+// that the rows of those tenants are rejected. Inserts that do not give the
+// tenant column are rejected, as their tenant is unknown.
+func setTenantInsertValues(ctx *plancontext.PlanningContext, insOp *Insert, ins *sqlparser.Insert) error {
+	if insOp.VTable.Keyspace == nil {
+		return nil
+	}
+	tr := ctx.VSchema.GetVSchema().FindTenantRouting(insOp.VTable.Keyspace.Name)
+	if tr == nil {
+		return nil
+	}
+	colNum := findColumn(ins, sqlparser.NewIdentifierCI(tr.Column))
+	if colNum == -1 {
+		if !tr.AppliesTo(insOp.VTable) {
+			return nil
+		}
+		return vterrors.VT12001(fmt.Sprintf("INSERT without the %s column on table %s while tenants of keyspace %s are moved", tr.Column, insOp.VTable.Name.String(), insOp.VTable.Keyspace.Name))
+	}
+	rows, ok := ins.Rows.(sqlparser.Values)
+	if !ok {
+		return vterrors.VT12001(fmt.Sprintf("INSERT ... SELECT of the tenant column while tenants of keyspace %s are moved", insOp.VTable.Keyspace.Name))
+	}
+	for _, row := range rows {
+		if colNum >= len(row) {
+			return vterrors.VT03006()
+		}
+		if _, ok := row[colNum].(*sqlparser.Default); ok {
+			continue
+		}
+		tenant, err := evalengine.Translate(row[colNum], nil)
+		if err != nil {
+			return err
+		}
+		insOp.TenantValues = append(insOp.TenantValues, tenant)
+	}
+	insOp.TenantRoutes = tenantRoutes(ctx, tr, insOp.VTable, nil, nil, true)
+	return nil
+}
+
+// FilterMovedTenants filters the rows of the tenants that have been switched
+// out of the keyspace of the route out of the SELECT, when it is not
+// restricted to a tenant: those rows are stale copies until the workflow of
+// the tenant is completed, and the SELECT does not read the new keyspace of
+// the tenant. The tables on the inner side of outer joins are not filtered, as
+// the filter would turn the joins into inner joins.
+func FilterMovedTenants(ctx *plancontext.PlanningContext, rp *engine.RoutingParameters, sel sqlparser.SelectStatement) {
+	if rp.Keyspace == nil || rp.TenantValue != nil {
+		return
+	}
+	tr := ctx.VSchema.GetVSchema().FindTenantRouting(rp.Keyspace.Name)
+	if tr == nil {
+		return
+	}
+	tenants := tr.MovedTenants(rp.Keyspace.Name)
+	if len(tenants) == 0 {
+		return
+	}
+	values := make(sqlparser.ValTuple, 0, len(tenants))
+	for _, tenant := range tenants {
+		values = append(values, sqlparser.NewStrLiteral(tenant))
+	}
+	filterMovedTenants(ctx, tr, rp.Keyspace.Name, sel, values)
+}
+
+func filterMovedTenants(ctx *plancontext.PlanningContext, tr *vindexes.TenantRouting, keyspace string, sel sqlparser.SelectStatement, tenants sqlparser.ValTuple) {
+	switch sel := sel.(type) {
+	case *sqlparser.Union:
+		filterMovedTenants(ctx, tr, keyspace, sel.Left, tenants)
+		filterMovedTenants(ctx, tr, keyspace, sel.Right, tenants)
+	case *sqlparser.Select:
+		var tables []*sqlparser.AliasedTableExpr
+		for _, expr := range sel.From {
+			tables = append(tables, filteredTables(expr)...)
+		}
+		for _, table := range tables {
+			if derived, ok := table.Expr.(*sqlparser.DerivedTable); ok {
+				filterMovedTenants(ctx, tr, keyspace, derived.Select, tenants)
+				continue
+			}
+			tableName, ok := table.Expr.(sqlparser.TableName)
+			if !ok {
+				continue
+			}
+			ti, err := ctx.SemTable.TableInfoFor(ctx.SemTable.TableSetFor(table))
+			if err != nil {
+				continue
+			}
+			vt := ti.GetVindexTable()
+			if vt == nil || vt.Keyspace == nil || vt.Keyspace.Name != keyspace || !tr.AppliesTo(vt) {
+				continue
+			}
+			qualifier := sqlparser.TableName{Name: tableName.Name}
+			if !table.As.IsEmpty() {
+				qualifier = sqlparser.TableName{Name: table.As}
+			}
+			col := sqlparser.NewColNameWithQualifier(tr.Column, qualifier)
+			sel.AddWhere(&sqlparser.OrExpr{
+				Left:  &sqlparser.IsExpr{Left: col, Right: sqlparser.IsNullOp},
+				Right: &sqlparser.ComparisonExpr{Operator: sqlparser.NotInOp, Left: col, Right: tenants},
+			})
+		}
+	}
+}
+
+// filteredTables returns the tables of the table expression that are not on
+// the inner side of an outer join.
+func filteredTables(expr sqlparser.TableExpr) []*sqlparser.AliasedTableExpr {
+	switch expr := expr.(type) {
+	case *sqlparser.AliasedTableExpr:
+		return []*sqlparser.AliasedTableExpr{expr}
+	case *sqlparser.ParenTableExpr:
+		var tables []*sqlparser.AliasedTableExpr
+		for _, expr := range expr.Exprs {
+			tables = append(tables, filteredTables(expr)...)
+		}
+		return tables
+	case *sqlparser.JoinTableExpr:
+		switch expr.Join {
+		case sqlparser.LeftJoinType, sqlparser.NaturalLeftJoinType:
+			return filteredTables(expr.LeftExpr)
+		case sqlparser.RightJoinType, sqlparser.NaturalRightJoinType:
+			return filteredTables(expr.RightExpr)
+		}
+		return append(filteredTables(expr.LeftExpr), filteredTables(expr.RightExpr)...)
+	}
+	return nil
+}
+
+// tenantRoutes returns the routes of the queries of the moved tenants on the
+// table. A tenant is routed through the primary vindex of the table in its new
+// keyspace when the query gives the value of its column: either the tenant
+// itself, or a predicate. The writes of the tenants being switched are denied
+// for DMLs.
+func tenantRoutes(ctx *plancontext.PlanningContext, tr *vindexes.TenantRouting, table *vindexes.Table, tenant evalengine.Expr, predicates []sqlparser.Expr, dml bool) map[string]*engine.TenantRoute {
+	vschema := ctx.VSchema.GetVSchema()
+	routes := make(map[string]*engine.TenantRoute, len(tr.Keyspaces))
+	for value, keyspace := range tr.Keyspaces {
+		route := &engine.TenantRoute{
+			WritesDenied: dml && tr.WritesDenied[value],
+		}
+		if keyspace != table.Keyspace.Name {
+			route.Keyspace = &vindexes.Keyspace{Name: keyspace}
+			if ks := vschema.Keyspaces[keyspace]; ks != nil {
+				route.Keyspace = ks.Keyspace
+				route.Vindex, route.Value = tenantVindex(ctx, ks.Tables[table.Name.String()], tr.Column, tenant, predicates)
+			}
+		} else if !route.WritesDenied {
+			continue
+		}
+		routes[value] = route
+	}
+	return routes
+}
+
+// tenantVindex returns the primary vindex of the table and the value of its
+// column in the query, or nil if the table has no single column vindex or the
+// query does not give the value of its column.
+func tenantVindex(ctx *plancontext.PlanningContext, table *vindexes.Table, column string, tenant evalengine.Expr, predicates []sqlparser.Expr) (vindexes.SingleColumn, evalengine.Expr) {
+	if table == nil || table.Keyspace == nil || !table.Keyspace.Sharded || len(table.ColumnVindexes) == 0 {
+		return nil, nil
+	}
+	cv := table.ColumnVindexes[0]
+	vindex, ok := cv.Vindex.(vindexes.SingleColumn)
+	if !ok || len(cv.Columns) != 1 {
+		return nil, nil
+	}
+	if cv.Columns[0].EqualString(column) {
+		if tenant == nil {
+			return nil, nil
+		}
+		return vindex, tenant
+	}
+	value := findColumnValue(ctx, predicates, cv.Columns[0].String())
+	if value == nil {
+		return nil, nil
+	}
+	return vindex, value
+}
+
+// findColumnValue returns the value the column is compared to in the
+// predicates, or nil if there is no such predicate.
+func findColumnValue(ctx *plancontext.PlanningContext, predicates []sqlparser.Expr, column string) evalengine.Expr {
+	for _, pred := range predicates {
+		cmp, ok := pred.(*sqlparser.ComparisonExpr)
+		if !ok || cmp.Operator != sqlparser.EqualOp {
+			continue
+		}
+		col, value := cmp.Left, cmp.Right
+		if _, ok := col.(*sqlparser.ColName); !ok {
+			col, value = value, col
+		}
+		colName, ok := col.(*sqlparser.ColName)
+		if !ok || !colName.Name.EqualString(column) || !sqlparser.IsValue(value) {
+			continue
+		}
+		if expr := makeEvalEngineExpr(ctx, value); expr != nil {
+			return expr
+		}
+	}
+	return nil
+}
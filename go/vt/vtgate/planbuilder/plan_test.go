@@ -320,6 +320,74 @@ func TestViews(t *testing.T) {
 	testFile(t, "view_cases.json", makeTestOutput(t), vschemaWrapper, false)
 }
 
+func TestTenantRouting(t *testing.T) {
+	vschema := loadSchema(t, "vschemas/schema.json", true)
+	// Tenant 1 has been moved to second_user, and the writes of tenant 2 are
+	// denied while its traffic is switched.
+	vschema.TenantRoutingRules = map[string]*vindexes.TenantRouting{
+		"user": {
+			Column:       "col",
+			Keyspaces:    map[string]string{"1": "second_user", "2": "user"},
+			WritesDenied: map[string]bool{"2": true},
+		},
+	}
+	vw := &vschemaWrapper{
+		v:          vschema,
+		tabletType: topodatapb.TabletType_PRIMARY,
+	}
+
+	tcases := []struct {
+		query string
+		err   string
+		route string
+	}{{
+		query: "select id from user where col = 1",
+		route: "select id from `user` where col = 1",
+	}, {
+		query: "select id from user",
+		route: "select id from `user` where `user`.col is null or `user`.col not in ('1')",
+	}, {
+		query: "select u.id, m.id from user as u join music as m on u.id = m.user_id where u.name = 'a'",
+		route: "select u.id, m.id from `user` as u, music as m where u.`name` = 'a' and u.id = m.user_id and (u.col is null or u.col not in ('1')) and (m.col is null or m.col not in ('1'))",
+	}, {
+		query: "select u.id from user as u left join user_extra as ue on u.id = ue.user_id",
+		route: "select u.id from `user` as u left join user_extra as ue on u.id = ue.user_id where u.col is null or u.col not in ('1')",
+	}, {
+		query: "select user_id from authoritative",
+		route: "select user_id from authoritative",
+	}, {
+		query: "update user set val = 1 where col = 1",
+	}, {
+		query: "delete from user where col = 2",
+	}, {
+		query: "update user set val = 1 where id = 1",
+		err:   "VT12001: unsupported: UPDATE without a col = <value> predicate on table user while tenants of keyspace user are moved",
+	}, {
+		query: "delete from user_extra where col in (1, 2)",
+		err:   "VT12001: unsupported: DELETE without a col = <value> predicate on table user_extra while tenants of keyspace user are moved",
+	}, {
+		query: "insert into user_extra(user_id) values (1)",
+		err:   "VT12001: unsupported: INSERT without the col column on table user_extra while tenants of keyspace user are moved",
+	}, {
+		query: "delete from authoritative where user_id = 1",
+	}}
+	for _, tcase := range tcases {
+		t.Run(tcase.query, func(t *testing.T) {
+			plan, err := TestBuilder(tcase.query, vw, vw.currentDb())
+			if tcase.err != "" {
+				require.EqualError(t, err, tcase.err)
+				return
+			}
+			require.NoError(t, err)
+			if tcase.route != "" {
+				route, ok := plan.Instructions.(*engine.Route)
+				require.True(t, ok, "unexpected plan: %T", plan.Instructions)
+				require.Equal(t, tcase.route, route.Query)
+			}
+		})
+	}
+}
+
 func TestOne(t *testing.T) {
 	oprewriters.DebugOperatorTree = true
 	vschema := &vschemaWrapper{
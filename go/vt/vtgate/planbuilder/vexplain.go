@@ -54,7 +54,7 @@ func buildExplainPlan(ctx context.Context, stmt sqlparser.Explain, reservedVars
 
 func buildVExplainPlan(ctx context.Context, vexplainStmt *sqlparser.VExplainStmt, reservedVars *sqlparser.ReservedVars, vschema plancontext.VSchema, enableOnlineDDL, enableDirectDDL bool) (*planResult, error) {
 	switch vexplainStmt.Type {
-	case sqlparser.QueriesVExplainType, sqlparser.AllVExplainType:
+	case sqlparser.QueriesVExplainType, sqlparser.AllVExplainType, sqlparser.JSONVExplainType:
 		return buildVExplainLoggingPlan(ctx, vexplainStmt, reservedVars, vschema, enableOnlineDDL, enableDirectDDL)
 	case sqlparser.PlanVExplainType:
 		return buildVExplainVtgatePlan(ctx, vexplainStmt.Statement, reservedVars, vschema, enableOnlineDDL, enableDirectDDL)
@@ -26,6 +26,7 @@ import (
 	"google.golang.org/protobuf/proto"
 
 	"vitess.io/vitess/go/mysql/datetime"
+	"vitess.io/vitess/go/sqltypes"
 
 	"vitess.io/vitess/go/vt/sqlparser"
 	"vitess.io/vitess/go/vt/srvtopo"
@@ -73,6 +74,12 @@ type (
 		mu      sync.Mutex
 		entries []engine.ExecuteEntry
 		lastID  int
+		stats   map[engine.Primitive]*engine.PrimitiveStats
+	}
+
+	// resultSize is the size of the results received from a tablet
+	resultSize struct {
+		rows, bytes int
 	}
 
 	// autocommitState keeps track of whether a single round-trip
@@ -947,7 +954,28 @@ func (session *SafeSession) GetPrepareData(name string) *vtgatepb.PrepareData {
 	return session.PrepareStatement[name]
 }
 
-func (l *executeLogger) log(primitive engine.Primitive, target *querypb.Target, gateway srvtopo.Gateway, query string, begin bool, bv map[string]*querypb.BindVariable) {
+func (rs *resultSize) add(qr *sqltypes.Result) {
+	if qr == nil {
+		return
+	}
+	rs.rows += len(qr.Rows)
+	for _, row := range qr.Rows {
+		for _, v := range row {
+			rs.bytes += v.Len()
+		}
+	}
+}
+
+// wrap returns a streaming callback that adds the size of every result to rs
+// before passing it along to callback
+func (rs *resultSize) wrap(callback func(*sqltypes.Result) error) func(*sqltypes.Result) error {
+	return func(qr *sqltypes.Result) error {
+		rs.add(qr)
+		return callback(qr)
+	}
+}
+
+func (l *executeLogger) log(primitive engine.Primitive, target *querypb.Target, gateway srvtopo.Gateway, query string, begin bool, bv map[string]*querypb.BindVariable, received resultSize) {
 	if l == nil {
 		return
 	}
@@ -978,14 +1006,37 @@ func (l *executeLogger) log(primitive engine.Primitive, target *querypb.Target,
 	}
 
 	l.entries = append(l.entries, engine.ExecuteEntry{
-		ID:        id,
-		Target:    target,
-		Gateway:   gateway,
-		Query:     q,
-		FiredFrom: primitive,
+		ID:            id,
+		Target:        target,
+		Gateway:       gateway,
+		Query:         q,
+		FiredFrom:     primitive,
+		RowsReceived:  received.rows,
+		BytesReceived: received.bytes,
 	})
 }
 
+// logPrimitive records the execution of a primitive that produced the given
+// number of rows in the given time
+func (l *executeLogger) logPrimitive(primitive engine.Primitive, rows int, elapsed time.Duration) {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.stats == nil {
+		l.stats = make(map[engine.Primitive]*engine.PrimitiveStats)
+	}
+	stats := l.stats[primitive]
+	if stats == nil {
+		stats = &engine.PrimitiveStats{}
+		l.stats[primitive] = stats
+	}
+	stats.Calls++
+	stats.Rows += rows
+	stats.Time += elapsed
+}
+
 func (l *executeLogger) GetLogs() []engine.ExecuteEntry {
 	l.mu.Lock()
 	defer l.mu.Unlock()
@@ -993,3 +1044,13 @@ func (l *executeLogger) GetLogs() []engine.ExecuteEntry {
 	copy(result, l.entries)
 	return result
 }
+
+func (l *executeLogger) GetStats() map[engine.Primitive]engine.PrimitiveStats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	result := make(map[engine.Primitive]engine.PrimitiveStats, len(l.stats))
+	for primitive, stats := range l.stats {
+		result[primitive] = *stats
+	}
+	return result
+}
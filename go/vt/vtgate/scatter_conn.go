@@ -248,7 +248,9 @@ func (stc *ScatterConn) ExecuteMultiShard(
 			default:
 				return nil, vterrors.Errorf(vtrpcpb.Code_INTERNAL, "[BUG] unexpected actionNeeded on query execution: %v", info.actionNeeded)
 			}
-			session.logging.log(primitive, rs.Target, rs.Gateway, queries[i].Sql, info.actionNeeded == begin || info.actionNeeded == reserveBegin, queries[i].BindVariables)
+			var received resultSize
+			received.add(innerqr)
+			session.logging.log(primitive, rs.Target, rs.Gateway, queries[i].Sql, info.actionNeeded == begin || info.actionNeeded == reserveBegin, queries[i].BindVariables, received)
 
 			// We need to new shard info irrespective of the error.
 			newInfo := info.updateTransactionAndReservedID(transactionID, reservedID, alias)
@@ -365,14 +367,20 @@ func (stc *ScatterConn) StreamExecuteMulti(
 		autocommit,
 		func(rs *srvtopo.ResolvedShard, i int, info *shardActionInfo) (*shardActionInfo, error) {
 			var (
-				err   error
-				opts  *querypb.ExecuteOptions
-				alias *topodatapb.TabletAlias
-				qs    queryservice.QueryService
+				err      error
+				opts     *querypb.ExecuteOptions
+				alias    *topodatapb.TabletAlias
+				qs       queryservice.QueryService
+				received resultSize
 			)
 			transactionID := info.transactionID
 			reservedID := info.reservedID
 
+			callback := callback
+			if session.logging != nil {
+				callback = received.wrap(callback)
+			}
+
 			if session != nil && session.Session != nil {
 				opts = session.Session.Options
 			}
@@ -446,7 +454,7 @@ func (stc *ScatterConn) StreamExecuteMulti(
 			default:
 				return nil, vterrors.Errorf(vtrpcpb.Code_INTERNAL, "[BUG] unexpected actionNeeded on query execution: %v", info.actionNeeded)
 			}
-			session.logging.log(primitive, rs.Target, rs.Gateway, query, info.actionNeeded == begin || info.actionNeeded == reserveBegin, bindVars[i])
+			session.logging.log(primitive, rs.Target, rs.Gateway, query, info.actionNeeded == begin || info.actionNeeded == reserveBegin, bindVars[i], received)
 
 			// We need to new shard info irrespective of the error.
 			newInfo := info.updateTransactionAndReservedID(transactionID, reservedID, alias)
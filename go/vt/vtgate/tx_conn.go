@@ -121,7 +121,7 @@ func (txc *TxConn) commitShard(ctx context.Context, s *vtgatepb.Session_ShardSes
 	}
 	s.TransactionId = 0
 	s.ReservedId = reservedID
-	logging.log(nil, s.Target, nil, "commit", false, nil)
+	logging.log(nil, s.Target, nil, "commit", false, nil, resultSize{})
 	return nil
 }
 
@@ -227,7 +227,7 @@ func (txc *TxConn) Rollback(ctx context.Context, session *SafeSession) error {
 		}
 		s.TransactionId = 0
 		s.ReservedId = reservedID
-		logging.log(nil, s.Target, nil, "rollback", false, nil)
+		logging.log(nil, s.Target, nil, "rollback", false, nil, resultSize{})
 		return nil
 	})
 	if err != nil {
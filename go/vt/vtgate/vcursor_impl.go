@@ -434,10 +434,12 @@ const MaxBufferingRetries = 3
 
 func (vc *vcursorImpl) ExecutePrimitive(ctx context.Context, primitive engine.Primitive, bindVars map[string]*querypb.BindVariable, wantfields bool) (*sqltypes.Result, error) {
 	for try := 0; try < MaxBufferingRetries; try++ {
+		start := vc.startTimer()
 		res, err := primitive.TryExecute(ctx, vc, bindVars, wantfields)
 		if err != nil && vterrors.RootCause(err) == buffer.ShardMissingError {
 			continue
 		}
+		vc.logExecutedPrimitive(primitive, res, start)
 		return res, err
 	}
 	return nil, vterrors.New(vtrpcpb.Code_UNAVAILABLE, "upstream shards are not available")
@@ -447,10 +449,12 @@ func (vc *vcursorImpl) ExecutePrimitiveStandalone(ctx context.Context, primitive
 	// clone the vcursorImpl with a new session.
 	newVC := vc.cloneWithAutocommitSession()
 	for try := 0; try < MaxBufferingRetries; try++ {
+		start := vc.startTimer()
 		res, err := primitive.TryExecute(ctx, newVC, bindVars, wantfields)
 		if err != nil && vterrors.RootCause(err) == buffer.ShardMissingError {
 			continue
 		}
+		vc.logExecutedPrimitive(primitive, res, start)
 		return res, err
 	}
 	return nil, vterrors.New(vtrpcpb.Code_UNAVAILABLE, "upstream shards are not available")
@@ -458,23 +462,67 @@ func (vc *vcursorImpl) ExecutePrimitiveStandalone(ctx context.Context, primitive
 
 func (vc *vcursorImpl) StreamExecutePrimitive(ctx context.Context, primitive engine.Primitive, bindVars map[string]*querypb.BindVariable, wantfields bool, callback func(*sqltypes.Result) error) error {
 	for try := 0; try < MaxBufferingRetries; try++ {
-		err := primitive.TryStreamExecute(ctx, vc, bindVars, wantfields, callback)
+		var produced resultSize
+		start := vc.startTimer()
+		err := primitive.TryStreamExecute(ctx, vc, bindVars, wantfields, vc.countRows(&produced, callback))
 		if err != nil && vterrors.RootCause(err) == buffer.ShardMissingError {
 			continue
 		}
+		vc.logStreamedPrimitive(primitive, produced, start)
 		return err
 	}
 	return vterrors.New(vtrpcpb.Code_UNAVAILABLE, "upstream shards are not available")
 }
 
+// startTimer returns the time at which a primitive starts executing, or the
+// zero time if the session is not logging for VEXPLAIN FORMAT=JSON.
+func (vc *vcursorImpl) startTimer() time.Time {
+	if vc.safeSession.logging == nil {
+		return time.Time{}
+	}
+	return time.Now()
+}
+
+// logExecutedPrimitive records the runtime statistics of a primitive for VEXPLAIN FORMAT=JSON.
+func (vc *vcursorImpl) logExecutedPrimitive(primitive engine.Primitive, res *sqltypes.Result, start time.Time) {
+	if vc.safeSession.logging == nil {
+		return
+	}
+	var rows int
+	if res != nil {
+		rows = len(res.Rows)
+	}
+	vc.safeSession.logging.logPrimitive(primitive, rows, time.Since(start))
+}
+
+// logStreamedPrimitive records the runtime statistics of a streaming primitive for VEXPLAIN FORMAT=JSON.
+func (vc *vcursorImpl) logStreamedPrimitive(primitive engine.Primitive, produced resultSize, start time.Time) {
+	if vc.safeSession.logging == nil {
+		return
+	}
+	vc.safeSession.logging.logPrimitive(primitive, produced.rows, time.Since(start))
+}
+
+// countRows wraps the callback of a streaming primitive to count the rows it
+// produces, when the session is logging for VEXPLAIN.
+func (vc *vcursorImpl) countRows(produced *resultSize, callback func(*sqltypes.Result) error) func(*sqltypes.Result) error {
+	if vc.safeSession.logging == nil {
+		return callback
+	}
+	return produced.wrap(callback)
+}
+
 func (vc *vcursorImpl) StreamExecutePrimitiveStandalone(ctx context.Context, primitive engine.Primitive, bindVars map[string]*querypb.BindVariable, wantfields bool, callback func(result *sqltypes.Result) error) error {
 	// clone the vcursorImpl with a new session.
 	newVC := vc.cloneWithAutocommitSession()
 	for try := 0; try < MaxBufferingRetries; try++ {
-		err := primitive.TryStreamExecute(ctx, newVC, bindVars, wantfields, callback)
+		var produced resultSize
+		start := vc.startTimer()
+		err := primitive.TryStreamExecute(ctx, newVC, bindVars, wantfields, vc.countRows(&produced, callback))
 		if err != nil && vterrors.RootCause(err) == buffer.ShardMissingError {
 			continue
 		}
+		vc.logStreamedPrimitive(primitive, produced, start)
 		return err
 	}
 	return vterrors.New(vtrpcpb.Code_UNAVAILABLE, "upstream shards are not available")
@@ -1160,6 +1208,11 @@ func (vc *vcursorImpl) VExplainLogging() {
 func (vc *vcursorImpl) GetVExplainLogs() []engine.ExecuteEntry {
 	return vc.safeSession.logging.GetLogs()
 }
+
+func (vc *vcursorImpl) GetVExplainStats() map[engine.Primitive]engine.PrimitiveStats {
+	return vc.safeSession.logging.GetStats()
+}
+
 func (vc *vcursorImpl) FindRoutedShard(keyspace, shard string) (keyspaceName string, err error) {
 	return vc.vschema.FindRoutedShard(keyspace, shard)
 }
@@ -8,6 +8,7 @@ import (
 	"strings"
 	"testing"
 
+	"vitess.io/vitess/go/sqltypes"
 	querypb "vitess.io/vitess/go/vt/proto/query"
 
 	"vitess.io/vitess/go/vt/proto/vschema"
@@ -16,6 +17,7 @@ import (
 	"vitess.io/vitess/go/vt/topo"
 
 	"vitess.io/vitess/go/vt/key"
+	"vitess.io/vitess/go/vt/vtgate/engine"
 	"vitess.io/vitess/go/vt/vtgate/vindexes"
 
 	"github.com/stretchr/testify/require"
@@ -317,3 +319,34 @@ func TestFirstSortedKeyspace(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, ks3Schema.Keyspace, ks)
 }
+
+func TestPrimitiveStatsOnlyWhenLogging(t *testing.T) {
+	vschema := &vindexes.VSchema{}
+	primitive := &engine.SingleRow{}
+	for _, logging := range []bool{false, true} {
+		ss := NewSafeSession(&vtgatepb.Session{})
+		if logging {
+			ss.EnableLogging()
+		}
+		vc, err := newVCursorImpl(ss, sqlparser.MarginComments{}, nil, nil, &fakeVSchemaOperator{vschema: vschema}, vschema, srvtopo.NewResolver(&fakeTopoServer{}, nil, ""), nil, false, querypb.ExecuteOptions_Gen4)
+		require.NoError(t, err)
+
+		// standalone streaming primitives run on a cloned session, and must
+		// still be accounted for in the stats of the original one
+		_, err = vc.ExecutePrimitive(context.Background(), primitive, nil, false)
+		require.NoError(t, err)
+		err = vc.StreamExecutePrimitive(context.Background(), primitive, nil, false, func(*sqltypes.Result) error { return nil })
+		require.NoError(t, err)
+		err = vc.StreamExecutePrimitiveStandalone(context.Background(), primitive, nil, false, func(*sqltypes.Result) error { return nil })
+		require.NoError(t, err)
+
+		if !logging {
+			require.Nil(t, ss.logging)
+			continue
+		}
+		stats := ss.logging.stats[primitive]
+		require.NotNil(t, stats)
+		require.Equal(t, 3, stats.Calls)
+		require.Equal(t, 3, stats.Rows)
+	}
+}
@@ -144,6 +144,18 @@ func (cached *ConsistentLookupUnique) CachedSize(alloc bool) int64 {
 	size += cached.clCommon.CachedSize(true)
 	return size
 }
+func (cached *Geohash) CachedSize(alloc bool) int64 {
+	if cached == nil {
+		return int64(0)
+	}
+	size := int64(0)
+	if alloc {
+		size += int64(16)
+	}
+	// field name string
+	size += hack.RuntimeAllocSize(int64(len(cached.name)))
+	return size
+}
 func (cached *Hash) CachedSize(alloc bool) int64 {
 	if cached == nil {
 		return int64(0)
@@ -89,6 +89,7 @@ var availableVindexes = []string{"binary",
 	"xxhash",
 	"unicode_loose_xxhash",
 	"reverse_bits",
+	"geohash",
 	"region_json",
 	"null"}
 
@@ -0,0 +1,149 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vindexes
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/key"
+)
+
+var (
+	_ SingleColumn = (*Geohash)(nil)
+	_ Hashing      = (*Geohash)(nil)
+)
+
+// Geohash defines a vindex for POINT columns that maps each point to the
+// 64-bit geohash of its location. A geohash interleaves the bits of the
+// longitude and the latitude, so points that are close to each other share
+// a keyspace id prefix, and every key range of a keyspace sharded with this
+// vindex covers a contiguous geographic area.
+// Points are read in MySQL's internal storage format, where the X coordinate
+// is the longitude and the Y coordinate is the latitude. Only points in the
+// Cartesian plane (SRID 0) or in WGS 84 (SRID 4326) are supported.
+// It's Unique and Functional, but not Reversible because the geohash
+// only identifies the area a point belongs to.
+type Geohash struct {
+	name string
+}
+
+// NewGeohash creates a new Geohash.
+func NewGeohash(name string, _ map[string]string) (Vindex, error) {
+	return &Geohash{name: name}, nil
+}
+
+// String returns the name of the vindex.
+func (vind *Geohash) String() string {
+	return vind.name
+}
+
+// Cost returns the cost of this index as 1.
+func (vind *Geohash) Cost() int {
+	return 1
+}
+
+// IsUnique returns true since the Vindex is unique.
+func (vind *Geohash) IsUnique() bool {
+	return true
+}
+
+// NeedsVCursor satisfies the Vindex interface.
+func (vind *Geohash) NeedsVCursor() bool {
+	return false
+}
+
+// Map can map ids to key.Destination objects.
+func (vind *Geohash) Map(ctx context.Context, vcursor VCursor, ids []sqltypes.Value) ([]key.Destination, error) {
+	out := make([]key.Destination, 0, len(ids))
+	for _, id := range ids {
+		ksid, err := vind.Hash(id)
+		if err != nil {
+			out = append(out, key.DestinationNone{})
+			continue
+		}
+		out = append(out, key.DestinationKeyspaceID(ksid))
+	}
+	return out, nil
+}
+
+// Verify returns true if ids maps to ksids.
+func (vind *Geohash) Verify(ctx context.Context, vcursor VCursor, ids []sqltypes.Value, ksids [][]byte) ([]bool, error) {
+	out := make([]bool, 0, len(ids))
+	for i, id := range ids {
+		ksid, err := vind.Hash(id)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, bytes.Equal(ksid, ksids[i]))
+	}
+	return out, nil
+}
+
+// Hash returns the geohash of the given POINT value as a keyspace id.
+func (vind *Geohash) Hash(id sqltypes.Value) ([]byte, error) {
+	g, err := id.ToGeom()
+	if err != nil {
+		return nil, fmt.Errorf("Geohash.Hash: %v", err)
+	}
+	if g.Type != sqltypes.GeomPoint {
+		return nil, fmt.Errorf("Geohash.Hash: unsupported geometry type: %s", g.Type)
+	}
+	if g.SRID != 0 && g.SRID != 4326 {
+		return nil, fmt.Errorf("Geohash.Hash: unsupported SRID: %d", g.SRID)
+	}
+	lon, lat := g.Coords[0].X, g.Coords[0].Y
+	if lon < -180 || lon > 180 || lat < -90 || lat > 90 {
+		return nil, fmt.Errorf("Geohash.Hash: point out of range: (%v, %v)", lon, lat)
+	}
+	var keybytes [8]byte
+	binary.BigEndian.PutUint64(keybytes[:], geohash(lon, lat))
+	return keybytes[:], nil
+}
+
+// geohash returns the 64-bit geohash of a location, starting with a
+// longitude bit and alternating between longitude and latitude.
+func geohash(lon, lat float64) uint64 {
+	var hash uint64
+	lonMin, lonMax := -180.0, 180.0
+	latMin, latMax := -90.0, 90.0
+	for bit := 63; bit >= 0; bit-- {
+		if bit%2 == 1 {
+			if mid := (lonMin + lonMax) / 2; lon >= mid {
+				hash |= 1 << bit
+				lonMin = mid
+			} else {
+				lonMax = mid
+			}
+		} else {
+			if mid := (latMin + latMax) / 2; lat >= mid {
+				hash |= 1 << bit
+				latMin = mid
+			} else {
+				latMax = mid
+			}
+		}
+	}
+	return hash
+}
+
+func init() {
+	Register("geohash", NewGeohash)
+}
@@ -0,0 +1,126 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vindexes
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/key"
+)
+
+var geohashVindex SingleColumn
+
+func init() {
+	hv, err := CreateVindex("geohash", "gh", map[string]string{})
+	if err != nil {
+		panic(err)
+	}
+	geohashVindex = hv.(SingleColumn)
+}
+
+func geohashPoint(t *testing.T, wkt string, srid uint32) sqltypes.Value {
+	g, err := sqltypes.ParseWKT(wkt, srid, 0)
+	require.NoError(t, err)
+	return sqltypes.NewGeometry(g)
+}
+
+func TestGeohashInfo(t *testing.T) {
+	assert.Equal(t, 1, geohashVindex.Cost())
+	assert.Equal(t, "gh", geohashVindex.String())
+	assert.True(t, geohashVindex.IsUnique())
+	assert.False(t, geohashVindex.NeedsVCursor())
+}
+
+func TestGeohash(t *testing.T) {
+	// decodes a base32 geohash into its leading bits
+	decode := func(s string) uint64 {
+		const alphabet = "0123456789bcdefghjkmnpqrstuvwxyz"
+		var h uint64
+		for _, c := range s {
+			h = h<<5 | uint64(strings.IndexRune(alphabet, c))
+		}
+		return h
+	}
+
+	tcases := []struct {
+		lon, lat float64
+		hash     string
+	}{
+		{lon: -5.6, lat: 42.6, hash: "ezs42"},
+		{lon: 10.40744, lat: 57.64911, hash: "u4pruydqqvj"},
+		{lon: -73.9857, lat: 40.7484, hash: "dr5ru6j2"},
+		{lon: 0, lat: 0, hash: "s0000"},
+	}
+	for _, tc := range tcases {
+		bits := 5 * len(tc.hash)
+		assert.Equal(t, decode(tc.hash), geohash(tc.lon, tc.lat)>>(64-bits), tc.hash)
+	}
+}
+
+func TestGeohashMap(t *testing.T) {
+	got, err := geohashVindex.Map(context.Background(), nil, []sqltypes.Value{
+		geohashPoint(t, "POINT(0 0)", 0),
+		geohashPoint(t, "POINT(-180 -90)", 0),
+		geohashPoint(t, "POINT(-5.6 42.6)", 4326),
+		geohashPoint(t, "POINT(200 0)", 0),
+		geohashPoint(t, "POINT(1 1)", 3857),
+		geohashPoint(t, "LINESTRING(0 0,1 1)", 0),
+		sqltypes.NewInt64(1),
+		sqltypes.NULL,
+	})
+	require.NoError(t, err)
+	want := []key.Destination{
+		key.DestinationKeyspaceID([]byte("\xc0\x00\x00\x00\x00\x00\x00\x00")),
+		key.DestinationKeyspaceID([]byte("\x00\x00\x00\x00\x00\x00\x00\x00")),
+		key.DestinationKeyspaceID([]byte("\x6f\xf0\x41\x00\x00\x00\x00\x00")),
+		key.DestinationNone{},
+		key.DestinationNone{},
+		key.DestinationNone{},
+		key.DestinationNone{},
+		key.DestinationNone{},
+	}
+	for i := range want {
+		if ksid, ok := want[i].(key.DestinationKeyspaceID); ok {
+			// only the leading bits of the geohash are checked
+			gotKsid, ok := got[i].(key.DestinationKeyspaceID)
+			require.True(t, ok, "Map()[%d] = %v", i, got[i])
+			assert.Equal(t, ksid[:3], gotKsid[:3], "Map()[%d]", i)
+			continue
+		}
+		assert.Equal(t, want[i], got[i], "Map()[%d]", i)
+	}
+}
+
+func TestGeohashVerify(t *testing.T) {
+	p1 := geohashPoint(t, "POINT(10 20)", 0)
+	p2 := geohashPoint(t, "POINT(-10 -20)", 0)
+	ksid, err := geohashVindex.(Hashing).Hash(p1)
+	require.NoError(t, err)
+
+	got, err := geohashVindex.Verify(context.Background(), nil, []sqltypes.Value{p1, p2}, [][]byte{ksid, ksid})
+	require.NoError(t, err)
+	assert.Equal(t, []bool{true, false}, got)
+
+	_, err = geohashVindex.Verify(context.Background(), nil, []sqltypes.Value{sqltypes.NewVarBinary("aa")}, [][]byte{nil})
+	require.EqualError(t, err, "Geohash.Hash: invalid GIS data")
+}
@@ -69,6 +69,21 @@ type VSchema struct {
 	uniqueVindexes    map[string]Vindex
 	Keyspaces         map[string]*KeyspaceSchema `json:"keyspaces"`
 	ShardRoutingRules map[string]string          `json:"shard_routing_rules"`
+
+	// TenantRoutingRules maps a keyspace to the tenants that have been moved
+	// out of it.
+	TenantRoutingRules map[string]*TenantRouting `json:"tenant_routing_rules,omitempty"`
+}
+
+// TenantRouting represents the tenant routing rules of one keyspace. The
+// queries that filter on Column = <tenant> are routed to the keyspace of the
+// tenant, which is the keyspace itself for a tenant that is being moved but
+// has not been switched yet. The writes of the tenants in WritesDenied are
+// rejected while their traffic is switched.
+type TenantRouting struct {
+	Column       string            `json:"column"`
+	Keyspaces    map[string]string `json:"keyspaces"`
+	WritesDenied map[string]bool   `json:"writes_denied,omitempty"`
 }
 
 // RoutingRule represents one routing rule.
@@ -259,6 +274,7 @@ func BuildVSchema(source *vschemapb.SrvVSchema) (vschema *VSchema) {
 	resolveAutoIncrement(source, vschema)
 	buildRoutingRule(source, vschema)
 	buildShardRoutingRule(source, vschema)
+	buildTenantRoutingRule(source, vschema)
 	return vschema
 }
 
@@ -869,6 +885,35 @@ func buildShardRoutingRule(source *vschemapb.SrvVSchema, vschema *VSchema) {
 	}
 }
 
+func buildTenantRoutingRule(source *vschemapb.SrvVSchema, vschema *VSchema) {
+	if source.TenantRoutingRules == nil || len(source.TenantRoutingRules.Rules) == 0 {
+		return
+	}
+	vschema.TenantRoutingRules = make(map[string]*TenantRouting)
+	for _, rule := range source.TenantRoutingRules.Rules {
+		tr, ok := vschema.TenantRoutingRules[rule.FromKeyspace]
+		if !ok {
+			tr = &TenantRouting{
+				Column:    rule.TenantColumn,
+				Keyspaces: make(map[string]string),
+			}
+			vschema.TenantRoutingRules[rule.FromKeyspace] = tr
+		}
+		if !strings.EqualFold(tr.Column, rule.TenantColumn) {
+			// The tenants of a keyspace are all identified by the same column,
+			// which is enforced when the rules are created.
+			continue
+		}
+		tr.Keyspaces[rule.TenantValue] = rule.ToKeyspace
+		if rule.WritesDenied {
+			if tr.WritesDenied == nil {
+				tr.WritesDenied = make(map[string]bool)
+			}
+			tr.WritesDenied[rule.TenantValue] = true
+		}
+	}
+}
+
 // FindTable returns a pointer to the Table. If a keyspace is specified, only tables
 // from that keyspace are searched. If the specified keyspace is unsharded
 // and no tables matched, it's considered valid: FindTable will construct a table
@@ -1119,6 +1164,47 @@ func (vschema *VSchema) FindRoutedShard(keyspace, shard string) (string, error)
 	return keyspace, nil
 }
 
+// FindTenantRouting returns the tenant routing rules of the keyspace, or nil
+// if none of its tenants have been moved to another keyspace.
+func (vschema *VSchema) FindTenantRouting(keyspace string) *TenantRouting {
+	if vschema == nil {
+		return nil
+	}
+	return vschema.TenantRoutingRules[keyspace]
+}
+
+// AppliesTo returns true if the rows of the table belong to tenants. Reference
+// tables, and tables whose authoritative column list does not have the tenant
+// column, are shared by all the tenants of the keyspace.
+func (tr *TenantRouting) AppliesTo(table *Table) bool {
+	if table.Type == TypeReference {
+		return false
+	}
+	if !table.ColumnListAuthoritative {
+		return true
+	}
+	for _, col := range table.Columns {
+		if col.Name.EqualString(tr.Column) {
+			return true
+		}
+	}
+	return false
+}
+
+// MovedTenants returns the sorted tenants that have been switched out of the
+// keyspace. Their rows are left in the keyspace until their workflow is
+// completed.
+func (tr *TenantRouting) MovedTenants(keyspace string) []string {
+	var tenants []string
+	for tenant, ks := range tr.Keyspaces {
+		if ks != keyspace {
+			tenants = append(tenants, tenant)
+		}
+	}
+	sort.Strings(tenants)
+	return tenants
+}
+
 // ByCost provides the interface needed for ColumnVindexes to
 // be sorted by cost order.
 type ByCost []*ColumnVindex
@@ -683,6 +683,61 @@ func TestVSchemaRoutingRules(t *testing.T) {
 	assert.Equal(t, string(wantb), string(gotb), string(gotb))
 }
 
+func TestVSchemaTenantRoutingRules(t *testing.T) {
+	input := vschemapb.SrvVSchema{
+		TenantRoutingRules: &vschemapb.TenantRoutingRules{
+			Rules: []*vschemapb.TenantRoutingRule{{
+				FromKeyspace: "ks1",
+				ToKeyspace:   "ks2",
+				TenantColumn: "tenant_id",
+				TenantValue:  "1",
+			}, {
+				FromKeyspace: "ks1",
+				ToKeyspace:   "ks3",
+				TenantColumn: "tenant_id",
+				TenantValue:  "2",
+			}, {
+				FromKeyspace: "ks1",
+				ToKeyspace:   "ks1",
+				TenantColumn: "tenant_id",
+				TenantValue:  "4",
+				WritesDenied: true,
+			}, {
+				FromKeyspace: "ks1",
+				ToKeyspace:   "ks3",
+				TenantColumn: "customer_id",
+				TenantValue:  "3",
+			}},
+		},
+	}
+	vschema := BuildVSchema(&input)
+	assert.Equal(t, &TenantRouting{
+		Column: "tenant_id",
+		Keyspaces: map[string]string{
+			"1": "ks2",
+			"2": "ks3",
+			"4": "ks1",
+		},
+		WritesDenied: map[string]bool{
+			"4": true,
+		},
+	}, vschema.FindTenantRouting("ks1"))
+	assert.Nil(t, vschema.FindTenantRouting("ks2"))
+
+	tr := vschema.FindTenantRouting("ks1")
+	assert.Equal(t, []string{"1", "2"}, tr.MovedTenants("ks1"))
+	assert.True(t, tr.AppliesTo(&Table{}))
+	assert.False(t, tr.AppliesTo(&Table{Type: TypeReference}))
+	assert.True(t, tr.AppliesTo(&Table{
+		Columns:                 []Column{{Name: sqlparser.NewIdentifierCI("Tenant_ID")}},
+		ColumnListAuthoritative: true,
+	}))
+	assert.False(t, tr.AppliesTo(&Table{
+		Columns:                 []Column{{Name: sqlparser.NewIdentifierCI("id")}},
+		ColumnListAuthoritative: true,
+	}))
+}
+
 func TestChooseVindexForType(t *testing.T) {
 	testcases := []struct {
 		in  querypb.Type
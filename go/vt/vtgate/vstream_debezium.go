@@ -0,0 +1,335 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtgate
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"vitess.io/vitess/go/acl"
+	"vitess.io/vitess/go/json2"
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/servenv"
+	"vitess.io/vitess/go/vt/topo/topoproto"
+
+	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vtgatepb "vitess.io/vitess/go/vt/proto/vtgate"
+)
+
+// This file implements a change data capture endpoint that streams the row
+// changes of a VStream as self-describing events in the Debezium JSON
+// envelope, one event per line.
+
+const (
+	pathDebeziumVStream = "/debezium/vstream"
+
+	debeziumConnector = "vitess"
+
+	debeziumOpCreate = "c"
+	debeziumOpUpdate = "u"
+	debeziumOpDelete = "d"
+	debeziumOpRead   = "r"
+)
+
+type (
+	// DebeziumEvent is a row change in the Debezium envelope
+	DebeziumEvent struct {
+		Before map[string]any  `json:"before"`
+		After  map[string]any  `json:"after"`
+		Source *DebeziumSource `json:"source"`
+		Op     string          `json:"op"`
+		TsMs   int64           `json:"ts_ms"`
+	}
+
+	// DebeziumSource describes where a change event comes from. Vgtid is the
+	// position of the transaction of the event: a stream started from it
+	// resumes after that transaction.
+	DebeziumSource struct {
+		Version   string `json:"version"`
+		Connector string `json:"connector"`
+		Name      string `json:"name"`
+		TsMs      int64  `json:"ts_ms"`
+		Snapshot  string `json:"snapshot"`
+		Db        string `json:"db"`
+		Keyspace  string `json:"keyspace"`
+		Table     string `json:"table"`
+		Shard     string `json:"shard"`
+		Vgtid     string `json:"vgtid"`
+	}
+
+	// debeziumConverter converts the events of a VStream into Debezium
+	// events. It keeps the fields of the tables to interpret the rows, and
+	// holds the rows back until the VGTID of their transaction is known.
+	debeziumConverter struct {
+		name    string
+		version string
+		fields  map[string][]*querypb.Field
+		pending []*DebeziumEvent
+	}
+)
+
+func newDebeziumConverter(name string) *debeziumConverter {
+	return &debeziumConverter{
+		name:    name,
+		version: servenv.AppVersion.ToStringMap()["version"],
+		fields:  map[string][]*querypb.Field{},
+	}
+}
+
+// convert returns the change events of the rows whose transaction position
+// is known once the given events are processed
+func (dc *debeziumConverter) convert(events []*binlogdatapb.VEvent) ([]*DebeziumEvent, error) {
+	var ready []*DebeziumEvent
+	for _, event := range events {
+		switch event.Type {
+		case binlogdatapb.VEventType_FIELD:
+			dc.fields[event.FieldEvent.TableName] = event.FieldEvent.Fields
+		case binlogdatapb.VEventType_ROW:
+			changes, err := dc.convertRows(event)
+			if err != nil {
+				return nil, err
+			}
+			dc.pending = append(dc.pending, changes...)
+		case binlogdatapb.VEventType_VGTID:
+			vgtid, err := json2.MarshalPB(event.Vgtid)
+			if err != nil {
+				return nil, err
+			}
+			for _, change := range dc.pending {
+				change.Source.Vgtid = string(vgtid)
+				if isCopying(event.Vgtid, change.Source.Keyspace, change.Source.Shard) {
+					change.Source.Snapshot = "true"
+					change.Op = debeziumOpRead
+				}
+			}
+			ready = append(ready, dc.pending...)
+			dc.pending = nil
+		}
+	}
+	return ready, nil
+}
+
+func (dc *debeziumConverter) convertRows(event *binlogdatapb.VEvent) ([]*DebeziumEvent, error) {
+	fields, ok := dc.fields[event.RowEvent.TableName]
+	if !ok {
+		return nil, fmt.Errorf("no fields for table %s", event.RowEvent.TableName)
+	}
+
+	keyspace, table := event.Keyspace, event.RowEvent.TableName
+	if idx := strings.IndexByte(table, '.'); idx >= 0 {
+		keyspace, table = table[:idx], table[idx+1:]
+	}
+	shard := event.Shard
+	if shard == "" {
+		shard = event.RowEvent.Shard
+	}
+
+	tsMs := event.CurrentTime / int64(time.Millisecond)
+	if tsMs == 0 {
+		tsMs = time.Now().UnixMilli()
+	}
+
+	changes := make([]*DebeziumEvent, 0, len(event.RowEvent.RowChanges))
+	for _, rc := range event.RowEvent.RowChanges {
+		change := &DebeziumEvent{
+			Before: debeziumRow(fields, rc.Before),
+			After:  debeziumRow(fields, rc.After),
+			Source: &DebeziumSource{
+				Version:   dc.version,
+				Connector: debeziumConnector,
+				Name:      dc.name,
+				TsMs:      event.Timestamp * 1000,
+				Snapshot:  "false",
+				Db:        keyspace,
+				Keyspace:  keyspace,
+				Table:     table,
+				Shard:     shard,
+			},
+			TsMs: tsMs,
+		}
+		switch {
+		case rc.Before == nil:
+			change.Op = debeziumOpCreate
+		case rc.After == nil:
+			change.Op = debeziumOpDelete
+		default:
+			change.Op = debeziumOpUpdate
+		}
+		changes = append(changes, change)
+	}
+	return changes, nil
+}
+
+// isCopying returns true if the shard is still copying tables at the given
+// position, which means its rows are part of the initial snapshot
+func isCopying(vgtid *binlogdatapb.VGtid, keyspace, shard string) bool {
+	for _, sgtid := range vgtid.ShardGtids {
+		if sgtid.Keyspace == keyspace && sgtid.Shard == shard {
+			return len(sgtid.TablePKs) > 0
+		}
+	}
+	return false
+}
+
+func debeziumRow(fields []*querypb.Field, row *querypb.Row) map[string]any {
+	if row == nil {
+		return nil
+	}
+	values := sqltypes.MakeRowTrusted(fields, row)
+	out := make(map[string]any, len(values))
+	for i, field := range fields {
+		if i >= len(values) {
+			break
+		}
+		out[field.Name] = debeziumValue(values[i])
+	}
+	return out
+}
+
+// debeziumValue returns the JSON representation of a value: numbers for the
+// numeric types, base64 strings for the binary types, and strings otherwise
+func debeziumValue(v sqltypes.Value) any {
+	switch {
+	case v.IsNull():
+		return nil
+	case v.IsSigned():
+		if i, err := v.ToInt64(); err == nil {
+			return i
+		}
+	case v.IsUnsigned():
+		if u, err := v.ToUint64(); err == nil {
+			return u
+		}
+	case v.IsFloat():
+		if f, err := v.ToFloat64(); err == nil {
+			return f
+		}
+	case v.Type() == sqltypes.TypeJSON:
+		if json.Valid(v.Raw()) {
+			return json.RawMessage(v.Raw())
+		}
+	case v.IsBinary() || v.Type() == sqltypes.Bit:
+		return v.Raw()
+	}
+	return v.ToString()
+}
+
+// debeziumVStreamRequest parses the parameters of a Debezium stream request.
+// The stream starts from the vgtid parameter, usually the vgtid of the last
+// event a client received, or else from the current position of the shards
+// of the keyspace, or from a snapshot of their tables with snapshot=true.
+func debeziumVStreamRequest(r *http.Request) (topodatapb.TabletType, *binlogdatapb.VGtid, *binlogdatapb.Filter, error) {
+	if err := r.ParseForm(); err != nil {
+		return 0, nil, nil, err
+	}
+
+	tabletType := topodatapb.TabletType_PRIMARY
+	if tt := r.FormValue("tablet_type"); tt != "" {
+		var err error
+		if tabletType, err = topoproto.ParseTabletType(tt); err != nil {
+			return 0, nil, nil, err
+		}
+	}
+
+	vgtid := &binlogdatapb.VGtid{}
+	if pos := r.FormValue("vgtid"); pos != "" {
+		if err := json2.Unmarshal([]byte(pos), vgtid); err != nil {
+			return 0, nil, nil, fmt.Errorf("invalid vgtid: %v", err)
+		}
+	} else {
+		keyspace := r.FormValue("keyspace")
+		if keyspace == "" {
+			return 0, nil, nil, fmt.Errorf("one of keyspace or vgtid is required")
+		}
+		gtid := "current"
+		if r.FormValue("snapshot") == "true" {
+			gtid = ""
+		}
+		vgtid.ShardGtids = []*binlogdatapb.ShardGtid{{
+			Keyspace: keyspace,
+			Shard:    r.FormValue("shard"),
+			Gtid:     gtid,
+		}}
+	}
+
+	filter := &binlogdatapb.Filter{}
+	tables := r.FormValue("tables")
+	if tables == "" {
+		filter.Rules = append(filter.Rules, &binlogdatapb.Rule{Match: "/.*"})
+	} else {
+		for _, table := range strings.Split(tables, ",") {
+			filter.Rules = append(filter.Rules, &binlogdatapb.Rule{Match: strings.TrimSpace(table)})
+		}
+	}
+	return tabletType, vgtid, filter, nil
+}
+
+func (vtg *VTGate) registerDebeziumVStreamHandler() {
+	servenv.HTTPHandleFunc(pathDebeziumVStream, func(w http.ResponseWriter, r *http.Request) {
+		if err := acl.CheckAccessHTTP(r, acl.ADMIN); err != nil {
+			acl.SendError(w, err)
+			return
+		}
+		tabletType, vgtid, filter, err := debeziumVStreamRequest(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		name := r.FormValue("name")
+		if name == "" {
+			name = debeziumConnector
+		}
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		w.WriteHeader(http.StatusOK)
+		err = vtg.streamDebezium(r.Context(), w, name, tabletType, vgtid, filter)
+		if err != nil && r.Context().Err() == nil {
+			log.Warningf("debezium vstream ended: %v", err)
+		}
+	})
+}
+
+// streamDebezium runs a VStream and writes its row changes as Debezium events,
+// one per line, until the stream fails or the client goes away
+func (vtg *VTGate) streamDebezium(ctx context.Context, w http.ResponseWriter, name string, tabletType topodatapb.TabletType, vgtid *binlogdatapb.VGtid, filter *binlogdatapb.Filter) error {
+	converter := newDebeziumConverter(name)
+	encoder := json.NewEncoder(w)
+	flusher, _ := w.(http.Flusher)
+
+	return vtg.VStream(ctx, tabletType, vgtid, filter, &vtgatepb.VStreamFlags{}, func(events []*binlogdatapb.VEvent) error {
+		changes, err := converter.convert(events)
+		if err != nil {
+			return err
+		}
+		for _, change := range changes {
+			if err := encoder.Encode(change); err != nil {
+				return err
+			}
+		}
+		if len(changes) > 0 && flusher != nil {
+			flusher.Flush()
+		}
+		return nil
+	})
+}
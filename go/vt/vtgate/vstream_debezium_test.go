@@ -0,0 +1,191 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtgate
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/json2"
+	"vitess.io/vitess/go/sqltypes"
+
+	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+)
+
+func TestDebeziumConverter(t *testing.T) {
+	fields := sqltypes.MakeTestFields("id|name|price|data", "int64|varchar|float64|varbinary")
+	insert := sqltypes.RowToProto3([]sqltypes.Value{
+		sqltypes.NewInt64(1), sqltypes.NewVarChar("a"), sqltypes.NewFloat64(1.5), sqltypes.NewVarBinary("\x00\x01"),
+	})
+	update := sqltypes.RowToProto3([]sqltypes.Value{
+		sqltypes.NewInt64(1), sqltypes.NewVarChar("b"), sqltypes.NULL, sqltypes.NewVarBinary(""),
+	})
+
+	vgtid := &binlogdatapb.VGtid{ShardGtids: []*binlogdatapb.ShardGtid{{Keyspace: "ks", Shard: "-80", Gtid: "pos"}}}
+	events := []*binlogdatapb.VEvent{
+		{Type: binlogdatapb.VEventType_BEGIN},
+		{Type: binlogdatapb.VEventType_FIELD, FieldEvent: &binlogdatapb.FieldEvent{TableName: "ks.t1", Fields: fields}},
+		{
+			Type:        binlogdatapb.VEventType_ROW,
+			Keyspace:    "ks",
+			Shard:       "-80",
+			Timestamp:   10,
+			CurrentTime: 11_000_000_000,
+			RowEvent: &binlogdatapb.RowEvent{TableName: "ks.t1", RowChanges: []*binlogdatapb.RowChange{
+				{After: insert},
+				{Before: insert, After: update},
+				{Before: update},
+			}},
+		},
+	}
+
+	dc := newDebeziumConverter("test")
+	changes, err := dc.convert(events)
+	require.NoError(t, err)
+	require.Empty(t, changes, "rows are held back until their vgtid is known")
+
+	changes, err = dc.convert([]*binlogdatapb.VEvent{
+		{Type: binlogdatapb.VEventType_VGTID, Vgtid: vgtid},
+		{Type: binlogdatapb.VEventType_COMMIT},
+	})
+	require.NoError(t, err)
+	require.Len(t, changes, 3)
+
+	wantVgtid, err := json2.MarshalPB(vgtid)
+	require.NoError(t, err)
+
+	assert.Equal(t, "c", changes[0].Op)
+	assert.Equal(t, "u", changes[1].Op)
+	assert.Equal(t, "d", changes[2].Op)
+	assert.Nil(t, changes[0].Before)
+	assert.Nil(t, changes[2].After)
+	assert.Equal(t, map[string]any{"id": int64(1), "name": "a", "price": 1.5, "data": []byte("\x00\x01")}, changes[0].After)
+	assert.Equal(t, map[string]any{"id": int64(1), "name": "b", "price": nil, "data": []byte{}}, changes[1].After)
+	assert.Equal(t, &DebeziumSource{
+		Version:   dc.version,
+		Connector: "vitess",
+		Name:      "test",
+		TsMs:      10_000,
+		Snapshot:  "false",
+		Db:        "ks",
+		Keyspace:  "ks",
+		Table:     "t1",
+		Shard:     "-80",
+		Vgtid:     string(wantVgtid),
+	}, changes[0].Source)
+	assert.EqualValues(t, 11_000, changes[0].TsMs)
+
+	out, err := json.Marshal(changes[2])
+	require.NoError(t, err)
+	var envelope map[string]any
+	require.NoError(t, json.Unmarshal(out, &envelope))
+	assert.ElementsMatch(t, []string{"before", "after", "source", "op", "ts_ms"}, envelopeKeys(envelope))
+}
+
+func TestDebeziumConverterSnapshot(t *testing.T) {
+	fields := sqltypes.MakeTestFields("id", "int64")
+	dc := newDebeziumConverter("test")
+	changes, err := dc.convert([]*binlogdatapb.VEvent{
+		{Type: binlogdatapb.VEventType_FIELD, FieldEvent: &binlogdatapb.FieldEvent{TableName: "ks.t1", Fields: fields}},
+		{Type: binlogdatapb.VEventType_ROW, Keyspace: "ks", Shard: "0", RowEvent: &binlogdatapb.RowEvent{
+			TableName:  "ks.t1",
+			RowChanges: []*binlogdatapb.RowChange{{After: sqltypes.RowToProto3([]sqltypes.Value{sqltypes.NewInt64(1)})}},
+		}},
+		{Type: binlogdatapb.VEventType_VGTID, Vgtid: &binlogdatapb.VGtid{ShardGtids: []*binlogdatapb.ShardGtid{{
+			Keyspace: "ks",
+			Shard:    "0",
+			TablePKs: []*binlogdatapb.TableLastPK{{TableName: "t1"}},
+		}}}},
+	})
+	require.NoError(t, err)
+	require.Len(t, changes, 1)
+	assert.Equal(t, "r", changes[0].Op)
+	assert.Equal(t, "true", changes[0].Source.Snapshot)
+}
+
+func TestDebeziumConverterMissingFields(t *testing.T) {
+	dc := newDebeziumConverter("test")
+	_, err := dc.convert([]*binlogdatapb.VEvent{
+		{Type: binlogdatapb.VEventType_ROW, RowEvent: &binlogdatapb.RowEvent{TableName: "ks.t1"}},
+	})
+	require.EqualError(t, err, "no fields for table ks.t1")
+}
+
+func TestDebeziumVStreamRequest(t *testing.T) {
+	vgtid := &binlogdatapb.VGtid{ShardGtids: []*binlogdatapb.ShardGtid{{Keyspace: "ks", Shard: "-80", Gtid: "pos"}}}
+	vgtidJSON, err := json2.MarshalPB(vgtid)
+	require.NoError(t, err)
+
+	testcases := []struct {
+		name       string
+		params     url.Values
+		tabletType topodatapb.TabletType
+		vgtid      *binlogdatapb.VGtid
+		filter     *binlogdatapb.Filter
+		err        string
+	}{{
+		name:       "keyspace",
+		params:     url.Values{"keyspace": {"ks"}},
+		tabletType: topodatapb.TabletType_PRIMARY,
+		vgtid:      &binlogdatapb.VGtid{ShardGtids: []*binlogdatapb.ShardGtid{{Keyspace: "ks", Gtid: "current"}}},
+		filter:     &binlogdatapb.Filter{Rules: []*binlogdatapb.Rule{{Match: "/.*"}}},
+	}, {
+		name:       "snapshot of tables",
+		params:     url.Values{"keyspace": {"ks"}, "shard": {"-80"}, "snapshot": {"true"}, "tables": {"t1, t2"}, "tablet_type": {"replica"}},
+		tabletType: topodatapb.TabletType_REPLICA,
+		vgtid:      &binlogdatapb.VGtid{ShardGtids: []*binlogdatapb.ShardGtid{{Keyspace: "ks", Shard: "-80"}}},
+		filter:     &binlogdatapb.Filter{Rules: []*binlogdatapb.Rule{{Match: "t1"}, {Match: "t2"}}},
+	}, {
+		name:       "resume",
+		params:     url.Values{"vgtid": {string(vgtidJSON)}},
+		tabletType: topodatapb.TabletType_PRIMARY,
+		vgtid:      vgtid,
+		filter:     &binlogdatapb.Filter{Rules: []*binlogdatapb.Rule{{Match: "/.*"}}},
+	}, {
+		name:   "no position",
+		params: url.Values{},
+		err:    "one of keyspace or vgtid is required",
+	}}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", pathDebeziumVStream+"?"+tc.params.Encode(), nil)
+			tabletType, vgtid, filter, err := debeziumVStreamRequest(r)
+			if tc.err != "" {
+				require.EqualError(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.tabletType, tabletType)
+			assert.Equal(t, tc.vgtid.String(), vgtid.String())
+			assert.Equal(t, tc.filter.String(), filter.String())
+		})
+	}
+}
+
+func envelopeKeys(m map[string]any) []string {
+	out := make([]string, 0, len(m))
+	for k := range m {
+		out = append(out, k)
+	}
+	return out
+}
@@ -26,6 +26,7 @@ import (
 	"time"
 
 	"vitess.io/vitess/go/stats"
+	"vitess.io/vitess/go/vt/binlog"
 	"vitess.io/vitess/go/vt/discovery"
 	querypb "vitess.io/vitess/go/vt/proto/query"
 	"vitess.io/vitess/go/vt/servenv"
@@ -199,8 +200,13 @@ func (vsm *vstreamManager) resolveParams(ctx context.Context, tabletType topodat
 	if vgtid == nil || len(vgtid.ShardGtids) == 0 {
 		return nil, nil, nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "vgtid must have at least one value with a starting position")
 	}
+	for _, sgtid := range vgtid.ShardGtids {
+		if _, _, err := binlog.ParseTimestampPosition(sgtid.Gtid); err != nil {
+			return nil, nil, nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "%v", err)
+		}
+	}
 	// To fetch from all keyspaces, the input must contain a single ShardGtid
-	// that has an empty keyspace, and the Gtid must be "current".
+	// that has an empty keyspace, and the Gtid must be "current" or a timestamp.
 	// Or the input must contain a single ShardGtid that has keyspace wildcards.
 	if len(vgtid.ShardGtids) == 1 {
 		inputKeyspace := vgtid.ShardGtids[0].Keyspace
@@ -214,13 +220,14 @@ func (vsm *vstreamManager) resolveParams(ctx context.Context, tabletType topodat
 			}
 
 			if isEmpty {
-				if vgtid.ShardGtids[0].Gtid != "current" {
-					return nil, nil, nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "for an empty keyspace, the Gtid value must be 'current': %v", vgtid)
+				gtid := vgtid.ShardGtids[0].Gtid
+				if gtid != "current" && !isTimestampPosition(gtid) {
+					return nil, nil, nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "for an empty keyspace, the Gtid value must be 'current' or a timestamp: %v", vgtid)
 				}
 				for _, keyspace := range keyspaces {
 					newvgtid.ShardGtids = append(newvgtid.ShardGtids, &binlogdatapb.ShardGtid{
 						Keyspace: keyspace,
-						Gtid:     "current",
+						Gtid:     gtid,
 					})
 				}
 			} else {
@@ -243,8 +250,8 @@ func (vsm *vstreamManager) resolveParams(ctx context.Context, tabletType topodat
 	newvgtid := &binlogdatapb.VGtid{}
 	for _, sgtid := range vgtid.ShardGtids {
 		if sgtid.Shard == "" {
-			if sgtid.Gtid != "current" && sgtid.Gtid != "" {
-				return nil, nil, nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "if shards are unspecified, the Gtid value must be 'current' or empty; got: %v", vgtid)
+			if sgtid.Gtid != "current" && sgtid.Gtid != "" && !isTimestampPosition(sgtid.Gtid) {
+				return nil, nil, nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "if shards are unspecified, the Gtid value must be 'current', a timestamp or empty; got: %v", vgtid)
 			}
 			// TODO(sougou): this should work with the new Migrate workflow
 			_, _, allShards, err := vsm.resolver.GetKeyspaceShards(ctx, sgtid.Keyspace, tabletType)
@@ -268,6 +275,14 @@ func (vsm *vstreamManager) resolveParams(ctx context.Context, tabletType topodat
 	return newvgtid, filter, flags, nil
 }
 
+// isTimestampPosition returns true if the position starts the stream of each
+// shard at the first transaction committed at or after a point in time, like
+// "timestamp:2023-06-01T02:00:00Z"
+func isTimestampPosition(gtid string) bool {
+	_, ok, _ := binlog.ParseTimestampPosition(gtid)
+	return ok
+}
+
 func (vsm *vstreamManager) RecordStreamDelay() {
 	vstreamSkewDelayCount.Add(1)
 }
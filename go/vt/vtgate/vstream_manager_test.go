@@ -945,7 +945,15 @@ func TestResolveVStreamParams(t *testing.T) {
 				Gtid:     "other",
 			}},
 		},
-		err: "if shards are unspecified, the Gtid value must be 'current' or empty",
+		err: "if shards are unspecified, the Gtid value must be 'current', a timestamp or empty",
+	}, {
+		input: &binlogdatapb.VGtid{
+			ShardGtids: []*binlogdatapb.ShardGtid{{
+				Keyspace: "TestVStream",
+				Gtid:     "timestamp:yesterday",
+			}},
+		},
+		err: `invalid timestamp position "timestamp:yesterday"`,
 	}, {
 		// Verify that the function maps the input missing the shard to a list of all shards in the topology.
 		input: &binlogdatapb.VGtid{
@@ -1022,6 +1030,48 @@ func TestResolveVStreamParams(t *testing.T) {
 				Gtid:     "current",
 			}},
 		},
+	}, {
+		input: &binlogdatapb.VGtid{
+			ShardGtids: []*binlogdatapb.ShardGtid{{
+				Keyspace: "TestVStream",
+				Gtid:     "timestamp:2023-06-01T02:00:00Z",
+			}},
+		},
+		output: &binlogdatapb.VGtid{
+			ShardGtids: []*binlogdatapb.ShardGtid{{
+				Keyspace: "TestVStream",
+				Shard:    "-20",
+				Gtid:     "timestamp:2023-06-01T02:00:00Z",
+			}, {
+				Keyspace: "TestVStream",
+				Shard:    "20-40",
+				Gtid:     "timestamp:2023-06-01T02:00:00Z",
+			}, {
+				Keyspace: "TestVStream",
+				Shard:    "40-60",
+				Gtid:     "timestamp:2023-06-01T02:00:00Z",
+			}, {
+				Keyspace: "TestVStream",
+				Shard:    "60-80",
+				Gtid:     "timestamp:2023-06-01T02:00:00Z",
+			}, {
+				Keyspace: "TestVStream",
+				Shard:    "80-a0",
+				Gtid:     "timestamp:2023-06-01T02:00:00Z",
+			}, {
+				Keyspace: "TestVStream",
+				Shard:    "a0-c0",
+				Gtid:     "timestamp:2023-06-01T02:00:00Z",
+			}, {
+				Keyspace: "TestVStream",
+				Shard:    "c0-e0",
+				Gtid:     "timestamp:2023-06-01T02:00:00Z",
+			}, {
+				Keyspace: "TestVStream",
+				Shard:    "e0-",
+				Gtid:     "timestamp:2023-06-01T02:00:00Z",
+			}},
+		},
 	}, {
 		input: &binlogdatapb.VGtid{
 			ShardGtids: []*binlogdatapb.ShardGtid{{
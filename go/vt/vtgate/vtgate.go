@@ -106,6 +106,9 @@ var (
 	// vtgate views flags
 	enableViews bool
 
+	// enableDebeziumVStream serves VStreams as Debezium change events over HTTP
+	enableDebeziumVStream bool
+
 	// queryLogToFile controls whether query logs are sent to a file
 	queryLogToFile string
 	// queryLogBufferSize controls how many query logs will be buffered before dropping them if logging is not fast enough
@@ -147,6 +150,7 @@ func registerFlags(fs *pflag.FlagSet) {
 	fs.IntVar(&queryLogBufferSize, "querylog-buffer-size", queryLogBufferSize, "Maximum number of buffered query logs before throttling log output")
 	fs.DurationVar(&messageStreamGracePeriod, "message_stream_grace_period", messageStreamGracePeriod, "the amount of time to give for a vttablet to resume if it ends a message stream, usually because of a reparent.")
 	fs.BoolVar(&enableViews, "enable-views", enableViews, "Enable views support in vtgate.")
+	fs.BoolVar(&enableDebeziumVStream, "enable-debezium-vstream", enableDebeziumVStream, "Serve the row changes of VStreams as Debezium change events over HTTP, at /debezium/vstream.")
 }
 func init() {
 	servenv.OnParseFor("vtgate", registerFlags)
@@ -363,6 +367,9 @@ func Init(
 	})
 	rpcVTGate.registerDebugHealthHandler()
 	rpcVTGate.registerDebugEnvHandler()
+	if enableDebeziumVStream {
+		rpcVTGate.registerDebeziumVStreamHandler()
+	}
 	err = initQueryLogger(rpcVTGate)
 	if err != nil {
 		log.Fatalf("error initializing query logger: %v", err)
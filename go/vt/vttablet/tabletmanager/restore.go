@@ -25,7 +25,9 @@ import (
 	"github.com/spf13/pflag"
 
 	"vitess.io/vitess/go/mysql"
+	"vitess.io/vitess/go/sqltypes"
 	"vitess.io/vitess/go/vt/dbconfigs"
+	"vitess.io/vitess/go/vt/discovery"
 	"vitess.io/vitess/go/vt/hook"
 	"vitess.io/vitess/go/vt/log"
 	"vitess.io/vitess/go/vt/logutil"
@@ -46,11 +48,12 @@ import (
 )
 
 // This file handles the initial backup restore upon startup.
-// It is only enabled if restore_from_backup is set.
+// It is only enabled if restore_from_backup or restore_from_clone is set.
 
 var (
 	restoreFromBackup      bool
 	restoreFromBackupTsStr string
+	restoreFromClone       bool
 	restoreConcurrency     = 4
 	waitForBackupInterval  time.Duration
 )
@@ -58,6 +61,7 @@ var (
 func registerRestoreFlags(fs *pflag.FlagSet) {
 	fs.BoolVar(&restoreFromBackup, "restore_from_backup", restoreFromBackup, "(init restore parameter) will check BackupStorage for a recent backup at startup and start there")
 	fs.StringVar(&restoreFromBackupTsStr, "restore_from_backup_ts", restoreFromBackupTsStr, "(init restore parameter) if set, restore the latest backup taken at or before this timestamp. Example: '2021-04-29.133050'")
+	fs.BoolVar(&restoreFromClone, "restore_from_clone", restoreFromClone, "(init restore parameter) will provision the tablet at startup with a physical copy of a tablet of its shard, preferring a replica, using the MySQL CLONE plugin")
 	fs.IntVar(&restoreConcurrency, "restore_concurrency", restoreConcurrency, "(init restore parameter) how many concurrent files to restore at once")
 	fs.DurationVar(&waitForBackupInterval, "wait_for_backup_interval", waitForBackupInterval, "(init restore parameter) if this is greater than 0, instead of starting up empty when no backups are found, keep checking at this interval for a backup to appear")
 }
@@ -143,6 +147,10 @@ func (tm *TabletManager) RestoreData(ctx context.Context, logger logutil.Logger,
 
 	startTime = time.Now()
 
+	if restoreFromClone {
+		err = tm.cloneDataLocked(ctx, logger)
+		return err
+	}
 	req := &tabletmanagerdatapb.RestoreFromBackupRequest{
 		BackupTime: logutil.TimeToProto(backupTime),
 	}
@@ -198,6 +206,12 @@ func (tm *TabletManager) restoreDataLocked(ctx context.Context, logger logutil.L
 		}
 		params.RestoreToPos = pos
 	}
+	if request.RestoreToTimestamp != nil {
+		if request.RestoreToPos != "" {
+			return vterrors.New(vtrpcpb.Code_INVALID_ARGUMENT, "restore failed: --restore_to_pos and --restore_to_timestamp are mutually exclusive")
+		}
+		params.RestoreToTimestamp = logutil.ProtoToTime(request.RestoreToTimestamp)
+	}
 	params.Logger.Infof("Restore: original tablet type=%v", originalType)
 
 	// Check whether we're going to restore before changing to RESTORE type,
@@ -310,6 +324,102 @@ func (tm *TabletManager) restoreDataLocked(ctx context.Context, logger logutil.L
 	return tm.tmState.ChangeTabletType(bgCtx, originalType, DBActionNone)
 }
 
+// cloneDataLocked provisions the tablet with a physical copy of the data of
+// another tablet of its shard, using the MySQL CLONE plugin.
+func (tm *TabletManager) cloneDataLocked(ctx context.Context, logger logutil.Logger) error {
+	tablet := tm.Tablet()
+	originalType := tablet.Type
+	params := mysqlctl.RestoreParams{
+		Cnf:          tm.Cnf,
+		Mysqld:       tm.MysqlDaemon,
+		Logger:       logger,
+		HookExtraEnv: tm.hookExtraEnv(),
+		DbName:       topoproto.TabletDbName(tablet),
+		Keyspace:     tablet.Keyspace,
+		Shard:        tablet.Shard,
+	}
+	params.Logger.Infof("Clone: original tablet type=%v", originalType)
+
+	ok, err := mysqlctl.ShouldRestore(ctx, params)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		params.Logger.Infof("Attempting to clone, but mysqld already contains data. Assuming vttablet was just restarted.")
+		return nil
+	}
+	// We should not become primary after the clone, because that would
+	// incorrectly start a new primary term.
+	if originalType == topodatapb.TabletType_PRIMARY {
+		originalType = tm.baseTabletType
+	}
+	if err := tm.tmState.ChangeTabletType(ctx, topodatapb.TabletType_RESTORE, DBActionNone); err != nil {
+		return err
+	}
+
+	pos, err := tm.cloneFromDonor(ctx, params)
+	if err != nil {
+		// If anything failed, we should reset the original tablet type
+		if err := tm.tmState.ChangeTabletType(context.Background(), originalType, DBActionNone); err != nil {
+			log.Errorf("Could not change back to original tablet type %v: %v", originalType, err)
+		}
+		return vterrors.Wrap(err, "Can't clone data")
+	}
+
+	// Starting from here we won't be able to recover if we get stopped by a cancelled
+	// context. Thus we use the background context to get through to the finish.
+	params.Logger.Infof("Clone: starting replication at position %v", pos)
+	if err := tm.startReplication(context.Background(), pos, originalType); err != nil {
+		return err
+	}
+
+	// If we had type BACKUP or RESTORE it's better to set our type to the init_tablet_type to make result of the clone
+	// similar to completely clean start from scratch.
+	if (originalType == topodatapb.TabletType_BACKUP || originalType == topodatapb.TabletType_RESTORE) && initTabletType != "" {
+		initType, err := topoproto.ParseTabletType(initTabletType)
+		if err == nil {
+			originalType = initType
+		}
+	}
+	params.Logger.Infof("Clone: changing tablet type to %v for %s", originalType, tm.tabletAlias.String())
+	return tm.tmState.ChangeTabletType(context.Background(), originalType, DBActionNone)
+}
+
+// cloneFromDonor picks a tablet of the shard, preferring a replica, and
+// clones its data. It returns the position of the copy.
+func (tm *TabletManager) cloneFromDonor(ctx context.Context, params mysqlctl.RestoreParams) (mysql.Position, error) {
+	pickCtx, pickCancel := context.WithTimeout(ctx, topo.RemoteOperationTimeout)
+	defer pickCancel()
+	tp, err := discovery.NewTabletPicker(pickCtx, tm.TopoServer, []string{tm.tabletAlias.Cell}, tm.tabletAlias.Cell, params.Keyspace, params.Shard,
+		"in_order:REPLICA,RDONLY,PRIMARY", discovery.TabletPickerOptions{})
+	if err != nil {
+		return mysql.Position{}, err
+	}
+	donor, err := tp.PickForStreaming(pickCtx)
+	if err != nil {
+		return mysql.Position{}, vterrors.Wrap(err, "failed to find a tablet to clone")
+	}
+	params.Logger.Infof("Clone: using tablet %v as the donor", topoproto.TabletAliasString(donor.Alias))
+
+	tmc := tmclient.NewTabletManagerClient()
+	defer tmc.Close()
+	executeOnDonor := func(ctx context.Context, query string) (*sqltypes.Result, error) {
+		qr, err := tmc.ExecuteFetchAsDba(ctx, donor, false, &tabletmanagerdatapb.ExecuteFetchAsDbaRequest{
+			Query:   []byte(query),
+			MaxRows: 1,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return sqltypes.Proto3ToResult(qr), nil
+	}
+	// The plugin cannot be installed on a donor that is super_read_only.
+	if err := mysqlctl.CheckClonePlugin(ctx, executeOnDonor); err != nil {
+		return mysql.Position{}, vterrors.Wrapf(err, "cannot clone %v", topoproto.TabletAliasString(donor.Alias))
+	}
+	return mysqlctl.CloneFromDonor(ctx, params, donor.MysqlHostname, donor.MysqlPort)
+}
+
 // restoreToTimeFromBinlog restores to the snapshot time of the keyspace
 // currently this works with mysql based database only (as it uses mysql specific queries for restoring)
 func (tm *TabletManager) restoreToTimeFromBinlog(ctx context.Context, pos mysql.Position, restoreTime *vttime.Time) error {
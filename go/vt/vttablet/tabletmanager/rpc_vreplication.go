@@ -21,6 +21,7 @@ import (
 	"strings"
 
 	"google.golang.org/protobuf/encoding/prototext"
+	"google.golang.org/protobuf/proto"
 
 	"vitess.io/vitess/go/sqltypes"
 	"vitess.io/vitess/go/textutil"
@@ -30,6 +31,7 @@ import (
 	tabletmanagerdatapb "vitess.io/vitess/go/vt/proto/tabletmanagerdata"
 	"vitess.io/vitess/go/vt/sidecardb"
 	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/vttablet/tabletmanager/vreplication"
 )
 
 const (
@@ -111,6 +113,17 @@ func (tm *TabletManager) UpdateVRWorkflow(ctx context.Context, req *tabletmanage
 	if !textutil.ValueIsSimulatedNull(req.OnDdl) {
 		bls.OnDdl = req.OnDdl
 	}
+	// A nil schedule keeps the existing one, and an empty schedule
+	// removes it.
+	if req.Schedule != nil {
+		if err := vreplication.ValidateSchedule(req.Schedule); err != nil {
+			return nil, err
+		}
+		bls.Schedule = req.Schedule
+		if proto.Equal(req.Schedule, &binlogdatapb.VReplicationSchedule{}) {
+			bls.Schedule = nil
+		}
+	}
 	source, err = prototext.Marshal(bls)
 	if err != nil {
 		return nil, err
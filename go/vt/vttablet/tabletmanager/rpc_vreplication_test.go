@@ -163,6 +163,19 @@ func TestUpdateVRWorkflow(t *testing.T) {
 			query: fmt.Sprintf(`update _vt.vreplication set source = 'keyspace:\"%s\" shard:\"%s\" filter:{rules:{match:\"customer\" filter:\"select * from customer\"} rules:{match:\"corder\" filter:\"select * from corder\"}} on_ddl:%s', cell = '%s', tablet_types = '%s' where id in (%d)`,
 				keyspace, shard, binlogdatapb.OnDDLAction_name[int32(binlogdatapb.OnDDLAction_EXEC_IGNORE)], "zone1,zone2,zone3", "rdonly,replica,primary", vreplID),
 		},
+		{
+			name: "update schedule",
+			request: &tabletmanagerdatapb.UpdateVRWorkflowRequest{
+				Workflow: workflow,
+				Schedule: &binlogdatapb.VReplicationSchedule{
+					WindowStart:      "01:00",
+					WindowEnd:        "06:00",
+					MaxRowsPerSecond: 1000,
+				},
+			},
+			query: fmt.Sprintf(`update _vt.vreplication set source = 'keyspace:\"%s\" shard:\"%s\" filter:{rules:{match:\"customer\" filter:\"select * from customer\"} rules:{match:\"corder\" filter:\"select * from corder\"}} schedule:{window_start:\"01:00\" window_end:\"06:00\" max_rows_per_second:1000}', cell = '', tablet_types = '' where id in (%d)`,
+				keyspace, shard, vreplID),
+		},
 	}
 
 	for _, tt := range tests {
@@ -762,9 +762,15 @@ func (tm *TabletManager) handleRestore(ctx context.Context) (bool, error) {
 	if tm.Cnf == nil && restoreFromBackup {
 		return false, fmt.Errorf("you cannot enable --restore_from_backup without a my.cnf file")
 	}
+	if tm.Cnf == nil && restoreFromClone {
+		return false, fmt.Errorf("you cannot enable --restore_from_clone without a my.cnf file")
+	}
+	if restoreFromBackup && restoreFromClone {
+		return false, fmt.Errorf("you cannot enable both --restore_from_backup and --restore_from_clone")
+	}
 
 	// Restore in the background
-	if restoreFromBackup {
+	if restoreFromBackup || restoreFromClone {
 		go func() {
 			// Open the state manager after restore is done.
 			defer tm.tmState.Open()
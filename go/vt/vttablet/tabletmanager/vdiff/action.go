@@ -22,12 +22,14 @@ import (
 	"fmt"
 
 	"github.com/google/uuid"
+	"google.golang.org/protobuf/encoding/prototext"
 
 	"vitess.io/vitess/go/vt/topo/topoproto"
 	"vitess.io/vitess/go/vt/vterrors"
 
 	"vitess.io/vitess/go/sqltypes"
 	"vitess.io/vitess/go/vt/binlog/binlogplayer"
+	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
 	"vitess.io/vitess/go/vt/proto/query"
 	tabletmanagerdatapb "vitess.io/vitess/go/vt/proto/tabletmanagerdata"
 	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
@@ -43,10 +45,15 @@ const (
 	DeleteAction  VDiffAction = "delete"
 	AllActionArg              = "all"
 	LastActionArg             = "last"
+
+	// RepairPlanAction shows the statements that bring the target in line
+	// with the source, and RepairAction applies them.
+	RepairPlanAction VDiffAction = "repair-plan"
+	RepairAction     VDiffAction = "repair"
 )
 
 var (
-	Actions    = []VDiffAction{CreateAction, ShowAction, StopAction, ResumeAction, DeleteAction}
+	Actions    = []VDiffAction{CreateAction, ShowAction, StopAction, ResumeAction, DeleteAction, RepairPlanAction, RepairAction}
 	ActionArgs = []string{AllActionArg, LastActionArg}
 )
 
@@ -87,6 +94,10 @@ func (vde *Engine) PerformVDiffAction(ctx context.Context, req *tabletmanagerdat
 		if err := vde.handleDeleteAction(ctx, dbClient, action, req, resp); err != nil {
 			return nil, err
 		}
+	case RepairPlanAction, RepairAction:
+		if err := vde.handleRepairAction(ctx, dbClient, action, req, resp); err != nil {
+			return nil, err
+		}
 	default:
 		return nil, fmt.Errorf("action %s not supported", action)
 	}
@@ -168,6 +179,11 @@ func (vde *Engine) handleCreateResumeAction(ctx context.Context, dbClient binlog
 	if options, err = vde.fixupOptions(options); err != nil {
 		return err
 	}
+	if options.GetCoreOptions().GetChecksum() {
+		if err := vde.checkChecksumSupported(dbClient, req.Workflow); err != nil {
+			return err
+		}
+	}
 	optionsJSON, err := json.Marshal(options)
 	if err != nil {
 		return err
@@ -213,6 +229,27 @@ func (vde *Engine) handleCreateResumeAction(ctx context.Context, dbClient binlog
 	return nil
 }
 
+// checkChecksumSupported returns an error if the rows of the workflow can't
+// be compared by checksum, so that such a vdiff is rejected when it's created
+// or resumed rather than falling back to comparing all the rows.
+func (vde *Engine) checkChecksumSupported(dbClient binlogplayer.DBClient, workflow string) error {
+	query := fmt.Sprintf(sqlGetVReplicationEntry, fmt.Sprintf("where workflow = %s and db_name = %s", encodeString(workflow), encodeString(vde.dbName)))
+	qr, err := dbClient.ExecuteFetch(query, -1)
+	if err != nil {
+		return err
+	}
+	for _, row := range qr.Named().Rows {
+		var bls binlogdatapb.BinlogSource
+		if err := prototext.Unmarshal(row.AsBytes("source", nil), &bls); err != nil {
+			return err
+		}
+		if err := checkChecksumFilter(bls.Filter); err != nil {
+			return vterrors.Wrapf(err, "cannot use --checksum for workflow %s on tablet %s", workflow, topoproto.TabletAliasString(vde.thisTablet.Alias))
+		}
+	}
+	return nil
+}
+
 func (vde *Engine) handleShowAction(ctx context.Context, dbClient binlogplayer.DBClient, action VDiffAction, req *tabletmanagerdatapb.VDiffRequest, resp *tabletmanagerdatapb.VDiffResponse) error {
 	var qr *sqltypes.Result
 	var err error
@@ -306,3 +343,49 @@ func (vde *Engine) handleDeleteAction(ctx context.Context, dbClient binlogplayer
 
 	return nil
 }
+
+func (vde *Engine) handleRepairAction(ctx context.Context, dbClient binlogplayer.DBClient, action VDiffAction, req *tabletmanagerdatapb.VDiffRequest, resp *tabletmanagerdatapb.VDiffResponse) error {
+	vdiffUUID, err := uuid.Parse(req.ActionArg)
+	if err != nil {
+		return fmt.Errorf("action argument %s not supported", req.ActionArg)
+	}
+	resp.VdiffUuid = vdiffUUID.String()
+	qr, err := dbClient.ExecuteFetch(fmt.Sprintf(sqlGetVDiffByKeyspaceWorkflowUUID, encodeString(req.Keyspace), encodeString(req.Workflow), encodeString(resp.VdiffUuid)), 1)
+	if err != nil {
+		return err
+	}
+	if len(qr.Rows) != 1 {
+		return fmt.Errorf("no vdiff found for UUID %s keyspace %s and workflow %s on tablet %v",
+			resp.VdiffUuid, req.Keyspace, req.Workflow, vde.thisTablet.Alias)
+	}
+	row := qr.Named().Row()
+	vdiffID, err := row.ToInt64("id")
+	if err != nil {
+		return err
+	}
+	resp.Id = vdiffID
+
+	if action == RepairPlanAction {
+		if qr, err = dbClient.ExecuteFetch(fmt.Sprintf(sqlGetVDiffRepairs, vdiffID), -1); err != nil {
+			return err
+		}
+		resp.Output = sqltypes.ResultToProto3(qr)
+		return nil
+	}
+	// A running vdiff is still adding to the plan.
+	if state := VDiffState(row.AsString("state", "")); state != CompletedState {
+		return fmt.Errorf("vdiff with UUID %s is %s on tablet %v, only a completed vdiff can be repaired",
+			resp.VdiffUuid, state, vde.thisTablet.Alias)
+	}
+	applied, skipped, err := vde.repairWorkflow(ctx, dbClient, req.Workflow, vdiffID)
+	insertVDiffLog(ctx, dbClient, vdiffID, fmt.Sprintf("Applied %d repair statements, skipped %d whose rows changed since the diff", applied, skipped))
+	if err != nil {
+		return err
+	}
+	resp.Output = sqltypes.ResultToProto3(&sqltypes.Result{
+		Fields:       []*query.Field{{Name: "skipped", Type: query.Type_INT64}},
+		Rows:         [][]sqltypes.Value{{sqltypes.NewInt64(skipped)}},
+		RowsAffected: uint64(applied),
+	})
+	return nil
+}
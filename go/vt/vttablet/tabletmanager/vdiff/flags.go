@@ -0,0 +1,38 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vdiff
+
+import (
+	"github.com/spf13/pflag"
+
+	"vitess.io/vitess/go/vt/servenv"
+)
+
+var (
+	checksumBucketRows      int64 = 10000
+	repairPlanMaxStatements int64 = 10000
+)
+
+func registerVDiffFlags(fs *pflag.FlagSet) {
+	fs.Int64Var(&checksumBucketRows, "vdiff-checksum-bucket-rows", checksumBucketRows, "Number of rows in each of the primary key ranges that a VDiff run with the checksum option compares by checksum.")
+	fs.Int64Var(&repairPlanMaxStatements, "vdiff-repair-plan-max-statements", repairPlanMaxStatements, "Maximum number of statements of the repair plan that a VDiff saves for each table, to bring the rows of the target in line with the source. 0 disables the repair plan.")
+}
+
+func init() {
+	servenv.OnParseFor("vtcombo", registerVDiffFlags)
+	servenv.OnParseFor("vttablet", registerVDiffFlags)
+}
@@ -91,6 +91,12 @@ func (pe *primitiveExecutor) next() ([]sqltypes.Value, error) {
 	return row, nil
 }
 
+// unread puts back a row returned by next, so that it is returned again by the
+// next call to next.
+func (pe *primitiveExecutor) unread(row []sqltypes.Value) {
+	pe.rows = append([][]sqltypes.Value{row}, pe.rows...)
+}
+
 // drain fastforward's a shard to process (and ignore) everything from its results stream and return a count of the
 // discarded rows.
 func (pe *primitiveExecutor) drain(ctx context.Context) (int64, error) {
@@ -0,0 +1,595 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vdiff
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"google.golang.org/protobuf/encoding/prototext"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/binlog/binlogplayer"
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/topo/topoproto"
+	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/throttle/throttlerapp"
+	"vitess.io/vitess/go/vt/vttablet/tmclient"
+
+	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
+	tabletmanagerdatapb "vitess.io/vitess/go/vt/proto/tabletmanagerdata"
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+/*
+	When the rows of a table differ, VDiff saves a repair plan in _vt.vdiff_repair: the
+	statements, keyed by primary key, that bring the rows of the target in line with the
+	source.
+		* A row that is missing on the target is inserted.
+		* A row whose values differ on the target is replaced by the row of the source.
+		* A row that is only on the target is deleted.
+	Rows that are reported as extra on both sides, because MySQL returned the rows of each
+	side in a different order, are dropped from the plan if they are identical.
+
+	The plan reflects the rows at the time of the diff, while the workflow goes on replicating
+	to the target. Each statement is saved with the queries that check, on the source and on
+	the target, that the rows it was generated from are unchanged: the row of the source that
+	is inserted or replaced, or the absence of the key of a deleted row on the source, and the
+	row of the target that is replaced or deleted, or the absence of the key of an inserted row
+	on the target.
+
+	The repair action stops the running streams of the workflow on the target primary, so that
+	they don't write to the rows being repaired, and restarts them once it is done. It runs
+	the checks of each statement on the source primaries and, in the transaction that applies
+	the statement, on the target. A statement whose rows changed since the diff is skipped, as
+	the workflow replicates the change itself. Each statement waits on the tablet throttler.
+*/
+
+// repairBatchSize is the number of repair statements that are saved or read
+// at a time.
+const repairBatchSize = 100
+
+type repairKind int
+
+// The statements of a repair plan are applied in this order, so that a row
+// with a different primary key collation on each side is deleted before
+// being inserted again.
+const (
+	repairDelete repairKind = iota
+	repairReplace
+	repairInsert
+)
+
+func (kind repairKind) String() string {
+	switch kind {
+	case repairDelete:
+		return "delete"
+	case repairReplace:
+		return "replace"
+	default:
+		return "insert"
+	}
+}
+
+func parseRepairKind(s string) (repairKind, error) {
+	for _, kind := range []repairKind{repairDelete, repairReplace, repairInsert} {
+		if s == kind.String() {
+			return kind, nil
+		}
+	}
+	return 0, vterrors.Errorf(vtrpcpb.Code_INTERNAL, "unknown repair kind %q", s)
+}
+
+// repairRow is the repair of a row: the row of the source to insert, the row
+// of the target to delete, or both for a row of the target that is replaced
+// by the row of the source.
+type repairRow struct {
+	kind   repairKind
+	source []sqltypes.Value
+	target []sqltypes.Value
+}
+
+// repairUnsupportedReason returns why no repair plan can be generated for
+// the table, or an empty string if one can.
+func (td *tableDiffer) repairUnsupportedReason() string {
+	switch {
+	case repairPlanMaxStatements <= 0:
+		return "the repair plan is disabled"
+	case len(td.tablePlan.aggregates) != 0:
+		return "the filter of the table has aggregates"
+	case td.wd.ct.sourceTimeZone != "":
+		return "the workflow converts time zones"
+	}
+	return ""
+}
+
+// addRepair adds the repair of a row to the repair plan of the table.
+func (td *tableDiffer) addRepair(kind repairKind, source, target []sqltypes.Value) {
+	if td.repairUnsupportedReason() != "" {
+		return
+	}
+	if int64(len(td.repairs)) >= repairPlanMaxStatements {
+		td.repairsTruncated = true
+		return
+	}
+	td.repairs = append(td.repairs, repairRow{kind: kind, source: source, target: target})
+}
+
+// drainRepairs is the version of primitiveExecutor.drain that adds the
+// repair of each of the drained rows to the repair plan of the table: the
+// drained rows are inserted if they are rows of the source, and deleted if
+// they are rows of the target.
+func (td *tableDiffer) drainRepairs(ctx context.Context, pe *primitiveExecutor, kind repairKind) (int64, error) {
+	if td.repairUnsupportedReason() != "" {
+		return pe.drain(ctx)
+	}
+	var count int64
+	for {
+		row, err := pe.next()
+		if err != nil {
+			return 0, err
+		}
+		if row == nil {
+			return count, nil
+		}
+		if kind == repairDelete {
+			td.addRepair(kind, nil, row)
+		} else {
+			td.addRepair(kind, row, nil)
+		}
+		count++
+	}
+}
+
+// reconcileRepairs drops the inserts and deletes of rows that are identical
+// on the source and the target.
+func (td *tableDiffer) reconcileRepairs() error {
+	var deletes []int
+	for i, repair := range td.repairs {
+		if repair.kind == repairDelete {
+			deletes = append(deletes, i)
+		}
+	}
+	if len(deletes) == 0 {
+		return nil
+	}
+	dropped := make(map[int]bool)
+	for i, repair := range td.repairs {
+		if repair.kind != repairInsert {
+			continue
+		}
+		for j, deleteIndex := range deletes {
+			c, err := td.compare(repair.source, td.repairs[deleteIndex].target, td.tablePlan.compareCols, false)
+			if err != nil {
+				return err
+			}
+			if c == 0 {
+				dropped[i] = true
+				dropped[deleteIndex] = true
+				deletes = append(deletes[:j], deletes[j+1:]...)
+				break
+			}
+		}
+	}
+	if len(dropped) == 0 {
+		return nil
+	}
+	repairs := make([]repairRow, 0, len(td.repairs)-len(dropped))
+	for i, repair := range td.repairs {
+		if !dropped[i] {
+			repairs = append(repairs, repair)
+		}
+	}
+	td.repairs = repairs
+	return nil
+}
+
+// saveRepairs saves the repair plan of the table, and clears it.
+func (td *tableDiffer) saveRepairs(ctx context.Context, dbClient binlogplayer.DBClient) error {
+	defer func() {
+		td.repairs = nil
+		td.repairsTruncated = false
+	}()
+	if len(td.repairs) == 0 {
+		return nil
+	}
+	if err := td.reconcileRepairs(); err != nil {
+		return err
+	}
+	sort.SliceStable(td.repairs, func(i, j int) bool {
+		return td.repairs[i].kind < td.repairs[j].kind
+	})
+	sourceSelect, err := td.tablePlan.repairSourceSelect()
+	if err != nil {
+		return err
+	}
+	for start := 0; start < len(td.repairs); start += repairBatchSize {
+		end := start + repairBatchSize
+		if end > len(td.repairs) {
+			end = len(td.repairs)
+		}
+		values := make([]string, 0, end-start)
+		for _, repair := range td.repairs[start:end] {
+			sourceCheck, targetCheck := td.tablePlan.repairChecks(sourceSelect, repair)
+			values = append(values, fmt.Sprintf("(%d, %s, %s, %s, %s, %s)", td.wd.ct.id, encodeString(td.table.Name),
+				encodeString(repair.kind.String()), encodeString(td.tablePlan.repairStatement(repair)),
+				encodeString(sourceCheck), encodeString(targetCheck)))
+		}
+		if _, err := dbClient.ExecuteFetch(fmt.Sprintf(sqlNewVDiffRepair, strings.Join(values, ", ")), 1); err != nil {
+			return err
+		}
+	}
+	if td.repairsTruncated {
+		insertVDiffLog(ctx, dbClient, td.wd.ct.id, fmt.Sprintf("The repair plan of table %s was truncated to %d statements",
+			td.table.Name, repairPlanMaxStatements))
+	}
+	return nil
+}
+
+// repairTable returns the qualified name of the target table.
+func (tp *tablePlan) repairTable() string {
+	return sqlparser.String(sqlparser.TableName{
+		Name:      sqlparser.NewIdentifierCS(tp.table.Name),
+		Qualifier: sqlparser.NewIdentifierCS(tp.dbName),
+	})
+}
+
+// repairKeyOp returns the operator that compares the key columns. Without a
+// primary key, all the columns are compared, and they can be null.
+func (tp *tablePlan) repairKeyOp() string {
+	if tp.fullRowKey {
+		return "<=>"
+	}
+	return "="
+}
+
+// repairStatement returns the statement that applies the repair to the
+// target table.
+func (tp *tablePlan) repairStatement(repair repairRow) string {
+	buf := &strings.Builder{}
+	switch repair.kind {
+	case repairDelete:
+		// Without a primary key, a single one of the identical rows is
+		// deleted.
+		fmt.Fprintf(buf, "delete from %s where %s", tp.repairTable(),
+			repairCondition(tp.repairColumns(tp.comparePKs), tp.repairKeyOp(), tp.comparePKs, repair.target))
+		if tp.fullRowKey {
+			buf.WriteString(" limit 1")
+		}
+	default:
+		verb := "insert"
+		if repair.kind == repairReplace {
+			verb = "replace"
+		}
+		fmt.Fprintf(buf, "%s into %s(", verb, tp.repairTable())
+		for i, col := range tp.compareCols {
+			if i > 0 {
+				buf.WriteString(", ")
+			}
+			buf.WriteString(sqlparser.String(sqlparser.NewIdentifierCI(col.colName)))
+		}
+		buf.WriteString(") values (")
+		for i, col := range tp.compareCols {
+			if i > 0 {
+				buf.WriteString(", ")
+			}
+			repair.source[col.colIndex].EncodeSQLStringBuilder(buf)
+		}
+		buf.WriteByte(')')
+	}
+	return buf.String()
+}
+
+// repairSourceSelect returns the source query of the table without the
+// in_keyrange filters, which MySQL doesn't understand. The diff already found
+// the rows of the plan in the key range of the target.
+func (tp *tablePlan) repairSourceSelect() (*sqlparser.Select, error) {
+	statement, err := sqlparser.Parse(tp.sourceQuery)
+	if err != nil {
+		return nil, err
+	}
+	sel, ok := statement.(*sqlparser.Select)
+	if !ok {
+		return nil, fmt.Errorf("unexpected: %v", sqlparser.String(statement))
+	}
+	if sel.Where == nil {
+		return sel, nil
+	}
+	var filters []sqlparser.Expr
+	for _, expr := range sqlparser.SplitAndExpression(nil, sel.Where.Expr) {
+		if fn, ok := expr.(*sqlparser.FuncExpr); ok && fn.Name.EqualString("in_keyrange") {
+			continue
+		}
+		filters = append(filters, expr)
+	}
+	sel.Where = nil
+	if len(filters) != 0 {
+		sel.Where = sqlparser.NewWhere(sqlparser.WhereClause, sqlparser.AndExpressions(filters...))
+	}
+	return sel, nil
+}
+
+// repairColumns returns the target columns of the table.
+func (tp *tablePlan) repairColumns(cols []compareColInfo) []string {
+	names := make([]string, 0, len(cols))
+	for _, col := range cols {
+		names = append(names, sqlparser.String(sqlparser.NewIdentifierCI(col.colName)))
+	}
+	return names
+}
+
+// repairChecks returns the queries that return a row if the source and the
+// target still have the rows the repair was generated from. The source check
+// is run on the source primaries, and the target check in the transaction
+// that applies the repair.
+func (tp *tablePlan) repairChecks(sourceSelect *sqlparser.Select, repair repairRow) (sourceCheck, targetCheck string) {
+	sourceExprs := make([]string, 0, len(tp.compareCols))
+	for _, col := range tp.compareCols {
+		expr := sourceSelect.SelectExprs[col.colIndex].(*sqlparser.AliasedExpr).Expr
+		sourceExprs = append(sourceExprs, sqlparser.String(expr))
+	}
+	sourceKeyExprs := make([]string, 0, len(tp.comparePKs))
+	for _, pk := range tp.comparePKs {
+		sourceKeyExprs = append(sourceKeyExprs, sourceExprs[pk.colIndex])
+	}
+	tableExprs := make([]string, 0, len(sourceSelect.From))
+	for _, tableExpr := range sourceSelect.From {
+		tableExprs = append(tableExprs, sqlparser.String(tableExpr))
+	}
+	sourceFrom := strings.Join(tableExprs, ", ")
+	if sourceSelect.Where != nil {
+		sourceFrom = fmt.Sprintf("%s where (%s) and", sourceFrom, sqlparser.String(sourceSelect.Where.Expr))
+	} else {
+		sourceFrom += " where"
+	}
+	targetFrom := tp.repairTable() + " where"
+
+	switch repair.kind {
+	case repairDelete:
+		sourceCheck = repairCheck(sourceFrom, repairCondition(sourceKeyExprs, tp.repairKeyOp(), tp.comparePKs, repair.target), "")
+	default:
+		sourceCheck = repairCheck(sourceFrom, repairCondition(sourceExprs, "<=>", tp.compareCols, repair.source), "")
+	}
+	switch repair.kind {
+	case repairInsert:
+		targetCheck = repairCheck(targetFrom, repairCondition(tp.repairColumns(tp.comparePKs), tp.repairKeyOp(), tp.comparePKs, repair.source), " for update")
+	default:
+		targetCheck = repairCheck(targetFrom, repairCondition(tp.repairColumns(tp.compareCols), "<=>", tp.compareCols, repair.target), " for update")
+	}
+	return sourceCheck, targetCheck
+}
+
+func repairCheck(from, condition, lock string) string {
+	return fmt.Sprintf("select 1 from %s %s limit 1%s", from, condition, lock)
+}
+
+// repairCondition returns the condition that compares each of the expressions
+// with the value of the corresponding column of the row.
+func repairCondition(exprs []string, op string, cols []compareColInfo, row []sqltypes.Value) string {
+	buf := &strings.Builder{}
+	for i, col := range cols {
+		if i > 0 {
+			buf.WriteString(" and ")
+		}
+		fmt.Fprintf(buf, "%s %s ", exprs[i], op)
+		row[col.colIndex].EncodeSQLStringBuilder(buf)
+	}
+	return buf.String()
+}
+
+// repairer applies the repair plan of a vdiff, while the streams of the
+// workflow are stopped.
+type repairer struct {
+	dbClient binlogplayer.DBClient
+	tmc      tmclient.TabletManagerClient
+	// sources are the primaries of the source shards of the workflow.
+	sources []*topodatapb.Tablet
+	// throttle checks the tablet throttler. If the throttler is not satisfied,
+	// it briefly sleeps and returns false.
+	throttle func(ctx context.Context) bool
+
+	applied, skipped int64
+}
+
+// repairWorkflow stops the running streams of the workflow, applies the
+// repair plan of the vdiff, and restarts the streams it stopped.
+func (vde *Engine) repairWorkflow(ctx context.Context, dbClient binlogplayer.DBClient, workflow string, vdiffID int64) (applied, skipped int64, err error) {
+	// The streams of the workflow must not be restarted by a vdiff while the
+	// plan is applied.
+	vde.snapshotMu.Lock()
+	defer vde.snapshotMu.Unlock()
+
+	r := &repairer{
+		dbClient: dbClient,
+		tmc:      vde.tmClientFactory(),
+		throttle: func(ctx context.Context) bool { return true },
+	}
+	if vde.vre != nil {
+		r.throttle = func(ctx context.Context) bool {
+			return vde.vre.ThrottleCheckOKOrWait(ctx, throttlerapp.VDiffName)
+		}
+	}
+	qr, err := dbClient.ExecuteFetch(fmt.Sprintf(sqlGetVReplicationEntry, fmt.Sprintf("where workflow = %s and db_name = %s",
+		encodeString(workflow), encodeString(vde.dbName))), -1)
+	if err != nil {
+		return 0, 0, err
+	}
+	var running []string
+	for _, row := range qr.Named().Rows {
+		sourceBytes, err := row["source"].ToBytes()
+		if err != nil {
+			return 0, 0, err
+		}
+		var bls binlogdatapb.BinlogSource
+		if err := prototext.Unmarshal(sourceBytes, &bls); err != nil {
+			return 0, 0, err
+		}
+		tablet, err := vde.sourcePrimary(ctx, &bls)
+		if err != nil {
+			return 0, 0, err
+		}
+		r.sources = append(r.sources, tablet)
+		if row.AsString("state", "") == binlogplayer.BlpRunning {
+			running = append(running, row.AsString("id", ""))
+		}
+	}
+	if len(r.sources) == 0 {
+		return 0, 0, fmt.Errorf("no streams found for workflow %s on tablet %v", workflow, topoproto.TabletAliasString(vde.thisTablet.Alias))
+	}
+
+	if len(running) != 0 {
+		ids := strings.Join(running, ", ")
+		if _, err := r.tmc.VReplicationExec(ctx, vde.thisTablet, fmt.Sprintf(sqlStopVReplicationForRepair, ids)); err != nil {
+			return 0, 0, err
+		}
+		defer func() {
+			if _, rerr := r.tmc.VReplicationExec(ctx, vde.thisTablet, fmt.Sprintf(sqlRestartVReplicationAfterRepair, ids)); rerr != nil {
+				log.Errorf("Failed to restart the streams of workflow %s after repairing vdiff %d: %v", workflow, vdiffID, rerr)
+				if err == nil {
+					err = rerr
+				}
+			}
+		}()
+	}
+	err = r.applyRepairs(ctx, vdiffID)
+	return r.applied, r.skipped, err
+}
+
+// sourcePrimary returns the primary of the source shard of a stream.
+func (vde *Engine) sourcePrimary(ctx context.Context, bls *binlogdatapb.BinlogSource) (*topodatapb.Tablet, error) {
+	// For Mount+Migrate, the source tablets are in a different Vitess
+	// cluster with its own TopoServer.
+	ts := vde.ts
+	if bls.ExternalCluster != "" {
+		var err error
+		if ts, err = vde.ts.OpenExternalVitessClusterServer(ctx, bls.ExternalCluster); err != nil {
+			return nil, err
+		}
+	}
+	si, err := ts.GetShard(ctx, bls.Keyspace, bls.Shard)
+	if err != nil {
+		return nil, err
+	}
+	if !si.HasPrimary() {
+		return nil, fmt.Errorf("shard %s/%s has no primary", bls.Keyspace, bls.Shard)
+	}
+	ti, err := ts.GetTablet(ctx, si.PrimaryAlias)
+	if err != nil {
+		return nil, err
+	}
+	return ti.Tablet, nil
+}
+
+// applyRepairs applies the pending statements of the repair plan of the
+// vdiff, in order.
+func (r *repairer) applyRepairs(ctx context.Context, vdiffID int64) error {
+	var lastID int64
+	for {
+		qr, err := r.dbClient.ExecuteFetch(fmt.Sprintf(sqlGetPendingVDiffRepairs, vdiffID, lastID, repairBatchSize), -1)
+		if err != nil {
+			return err
+		}
+		if len(qr.Rows) == 0 {
+			return nil
+		}
+		for _, row := range qr.Named().Rows {
+			id, err := row.ToInt64("id")
+			if err != nil {
+				return err
+			}
+			kind, err := parseRepairKind(row.AsString("kind", ""))
+			if err != nil {
+				return err
+			}
+			for !r.throttle(ctx) {
+				select {
+				case <-ctx.Done():
+					return vterrors.Errorf(vtrpcpb.Code_CANCELED, "context has expired")
+				default:
+				}
+			}
+			if err := r.applyRepair(ctx, id, kind, row.AsString("statement", ""),
+				row.AsString("source_check", ""), row.AsString("target_check", "")); err != nil {
+				return vterrors.Wrapf(err, "failed to apply repair statement %d", id)
+			}
+			lastID = id
+		}
+	}
+}
+
+// applyRepair applies a repair statement and marks it as applied in the same
+// transaction, or marks it as skipped if its rows changed since the diff.
+func (r *repairer) applyRepair(ctx context.Context, id int64, kind repairKind, statement, sourceCheck, targetCheck string) (err error) {
+	var sourceFound bool
+	for _, tablet := range r.sources {
+		qr, err := r.tmc.ExecuteFetchAsDba(ctx, tablet, false, &tabletmanagerdatapb.ExecuteFetchAsDbaRequest{
+			Query:   []byte(sourceCheck),
+			DbName:  topoproto.TabletDbName(tablet),
+			MaxRows: 1,
+		})
+		if err != nil {
+			return vterrors.Wrapf(err, "repair check on tablet %v", topoproto.TabletAliasString(tablet.Alias))
+		}
+		if len(qr.Rows) != 0 {
+			sourceFound = true
+			break
+		}
+	}
+
+	if _, err := r.dbClient.ExecuteFetch("begin", 1); err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			if _, rerr := r.dbClient.ExecuteFetch("rollback", 1); rerr != nil {
+				log.Errorf("Failed to roll back repair statement %d: %v", id, rerr)
+			}
+		}
+	}()
+	qr, err := r.dbClient.ExecuteFetch(targetCheck, 1)
+	if err != nil {
+		return err
+	}
+	targetFound := len(qr.Rows) != 0
+	// Only deleted rows are gone from the source, and only inserted rows
+	// are missing on the target.
+	if sourceFound != (kind != repairDelete) || targetFound != (kind != repairInsert) {
+		if _, err = r.dbClient.ExecuteFetch(fmt.Sprintf(sqlUpdateVDiffRepairSkipped, id), 1); err != nil {
+			return err
+		}
+		if _, err = r.dbClient.ExecuteFetch("commit", 1); err != nil {
+			return err
+		}
+		r.skipped++
+		return nil
+	}
+	if _, err = r.dbClient.ExecuteFetch(statement, 1); err != nil {
+		return err
+	}
+	if _, err = r.dbClient.ExecuteFetch(fmt.Sprintf(sqlUpdateVDiffRepairApplied, id), 1); err != nil {
+		return err
+	}
+	if _, err = r.dbClient.ExecuteFetch("commit", 1); err != nil {
+		return err
+	}
+	r.applied++
+	return nil
+}
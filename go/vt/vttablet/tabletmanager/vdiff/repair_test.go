@@ -0,0 +1,287 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vdiff
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/binlog/binlogplayer"
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/topo/memorytopo"
+	"vitess.io/vitess/go/vt/vttablet/tmclient"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	tabletmanagerdatapb "vitess.io/vitess/go/vt/proto/tabletmanagerdata"
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+)
+
+// repairTMClient returns the results of the repair checks on the source
+// tablets, and records the VReplicationExec queries.
+type repairTMClient struct {
+	tmclient.TabletManagerClient
+	sourceRows map[string]int
+	vrQueries  []string
+}
+
+func (tmc *repairTMClient) ExecuteFetchAsDba(ctx context.Context, tablet *topodatapb.Tablet, usePool bool, req *tabletmanagerdatapb.ExecuteFetchAsDbaRequest) (*querypb.QueryResult, error) {
+	qr := &querypb.QueryResult{}
+	for i := 0; i < tmc.sourceRows[string(req.Query)]; i++ {
+		qr.Rows = append(qr.Rows, sqltypes.RowToProto3([]sqltypes.Value{sqltypes.NewInt64(1)}))
+	}
+	return qr, nil
+}
+
+func (tmc *repairTMClient) VReplicationExec(ctx context.Context, tablet *topodatapb.Tablet, query string) (*querypb.QueryResult, error) {
+	tmc.vrQueries = append(tmc.vrQueries, query)
+	return &querypb.QueryResult{}, nil
+}
+
+func testRepairRow(id int64, val string) []sqltypes.Value {
+	return []sqltypes.Value{sqltypes.NewInt64(id), sqltypes.NewVarChar(val)}
+}
+
+func TestRepairStatement(t *testing.T) {
+	tp := testChecksumTablePlan()
+	row := testRepairRow(1, "it's")
+
+	assert.Equal(t, "insert into vt_customer.t1(c1, c3) values (1, 'it\\'s')", tp.repairStatement(repairRow{kind: repairInsert, source: row}))
+	assert.Equal(t, "replace into vt_customer.t1(c1, c3) values (1, 'it\\'s')", tp.repairStatement(repairRow{kind: repairReplace, source: row, target: testRepairRow(1, "b")}))
+	assert.Equal(t, "delete from vt_customer.t1 where c1 = 1", tp.repairStatement(repairRow{kind: repairDelete, target: row}))
+
+	tp.fullRowKey = true
+	tp.comparePKs = tp.compareCols
+	row[1] = sqltypes.NULL
+	assert.Equal(t, "delete from vt_customer.t1 where c1 <=> 1 and c3 <=> null limit 1", tp.repairStatement(repairRow{kind: repairDelete, target: row}))
+}
+
+func TestRepairChecks(t *testing.T) {
+	tp := testChecksumTablePlan()
+	tp.sourceQuery = "select c1, c2 as c3 from t1 where in_keyrange(c1, 'hash', '-80') and c2 != 'x' order by c1 asc"
+	sel, err := tp.repairSourceSelect()
+	require.NoError(t, err)
+
+	tcases := []struct {
+		repair                   repairRow
+		sourceCheck, targetCheck string
+	}{{
+		repair:      repairRow{kind: repairInsert, source: testRepairRow(1, "a")},
+		sourceCheck: "select 1 from t1 where (c2 != 'x') and c1 <=> 1 and c2 <=> 'a' limit 1",
+		targetCheck: "select 1 from vt_customer.t1 where c1 = 1 limit 1 for update",
+	}, {
+		repair:      repairRow{kind: repairReplace, source: testRepairRow(1, "a"), target: testRepairRow(1, "b")},
+		sourceCheck: "select 1 from t1 where (c2 != 'x') and c1 <=> 1 and c2 <=> 'a' limit 1",
+		targetCheck: "select 1 from vt_customer.t1 where c1 <=> 1 and c3 <=> 'b' limit 1 for update",
+	}, {
+		repair:      repairRow{kind: repairDelete, target: testRepairRow(1, "b")},
+		sourceCheck: "select 1 from t1 where (c2 != 'x') and c1 = 1 limit 1",
+		targetCheck: "select 1 from vt_customer.t1 where c1 <=> 1 and c3 <=> 'b' limit 1 for update",
+	}}
+	for _, tcase := range tcases {
+		t.Run(tcase.repair.kind.String(), func(t *testing.T) {
+			sourceCheck, targetCheck := tp.repairChecks(sel, tcase.repair)
+			assert.Equal(t, tcase.sourceCheck, sourceCheck)
+			assert.Equal(t, tcase.targetCheck, targetCheck)
+		})
+	}
+
+	tp.sourceQuery = "select c1, c2 as c3 from t1 where in_keyrange('-80') order by c1 asc"
+	sel, err = tp.repairSourceSelect()
+	require.NoError(t, err)
+	sourceCheck, _ := tp.repairChecks(sel, repairRow{kind: repairDelete, target: testRepairRow(1, "b")})
+	assert.Equal(t, "select 1 from t1 where c1 = 1 limit 1", sourceCheck)
+}
+
+func TestReconcileRepairs(t *testing.T) {
+	td := &tableDiffer{tablePlan: testChecksumTablePlan()}
+	td.repairs = []repairRow{
+		{kind: repairInsert, source: testRepairRow(1, "a")},
+		{kind: repairReplace, source: testRepairRow(2, "b"), target: testRepairRow(2, "x")},
+		{kind: repairDelete, target: testRepairRow(1, "a")},
+		{kind: repairInsert, source: testRepairRow(3, "c")},
+		{kind: repairDelete, target: testRepairRow(3, "d")},
+	}
+	require.NoError(t, td.reconcileRepairs())
+	assert.Equal(t, []repairRow{
+		{kind: repairReplace, source: testRepairRow(2, "b"), target: testRepairRow(2, "x")},
+		{kind: repairInsert, source: testRepairRow(3, "c")},
+		{kind: repairDelete, target: testRepairRow(3, "d")},
+	}, td.repairs)
+}
+
+var repairFields = sqltypes.MakeTestFields("id|kind|statement|source_check|target_check", "int64|varbinary|varbinary|varbinary|varbinary")
+
+func TestApplyRepairs(t *testing.T) {
+	ctx := context.Background()
+	dbClient := binlogplayer.NewMockDBClient(t)
+	tmc := &repairTMClient{sourceRows: map[string]int{"source 1": 1, "source 3": 1}}
+	var throttled int
+	r := &repairer{
+		dbClient: dbClient,
+		tmc:      tmc,
+		sources:  []*topodatapb.Tablet{{Alias: &topodatapb.TabletAlias{Cell: "zone1", Uid: 200}}},
+		throttle: func(ctx context.Context) bool {
+			throttled++
+			return throttled%2 == 0
+		},
+	}
+
+	dbClient.ExpectRequest(fmt.Sprintf(sqlGetPendingVDiffRepairs, 1, 0, repairBatchSize), sqltypes.MakeTestResult(repairFields,
+		"1|insert|stmt 1|source 1|target 1",
+		"2|replace|stmt 2|source 2|target 2",
+		"3|delete|stmt 3|source 3|target 3",
+	), nil)
+	// The source and the target are as diffed.
+	dbClient.ExpectRequest("begin", nil, nil)
+	dbClient.ExpectRequest("target 1", &sqltypes.Result{}, nil)
+	dbClient.ExpectRequest("stmt 1", &sqltypes.Result{}, nil)
+	dbClient.ExpectRequest(fmt.Sprintf(sqlUpdateVDiffRepairApplied, 1), &sqltypes.Result{}, nil)
+	dbClient.ExpectRequest("commit", nil, nil)
+	// The replaced row changed on the source.
+	dbClient.ExpectRequest("begin", nil, nil)
+	dbClient.ExpectRequest("target 2", sqltypes.MakeTestResult(sqltypes.MakeTestFields("1", "int64"), "1"), nil)
+	dbClient.ExpectRequest(fmt.Sprintf(sqlUpdateVDiffRepairSkipped, 2), &sqltypes.Result{}, nil)
+	dbClient.ExpectRequest("commit", nil, nil)
+	// The deleted row was inserted again on the source.
+	dbClient.ExpectRequest("begin", nil, nil)
+	dbClient.ExpectRequest("target 3", sqltypes.MakeTestResult(sqltypes.MakeTestFields("1", "int64"), "1"), nil)
+	dbClient.ExpectRequest(fmt.Sprintf(sqlUpdateVDiffRepairSkipped, 3), &sqltypes.Result{}, nil)
+	dbClient.ExpectRequest("commit", nil, nil)
+	dbClient.ExpectRequest(fmt.Sprintf(sqlGetPendingVDiffRepairs, 1, 3, repairBatchSize), &sqltypes.Result{}, nil)
+
+	require.NoError(t, r.applyRepairs(ctx, 1))
+	dbClient.Wait()
+	assert.EqualValues(t, 1, r.applied)
+	assert.EqualValues(t, 2, r.skipped)
+	// Each statement waited once on the throttler.
+	assert.Equal(t, 6, throttled)
+
+	// A statement that fails is rolled back, and the rest of the plan is left
+	// pending.
+	r.applied, r.skipped = 0, 0
+	dbClient.ExpectRequest(fmt.Sprintf(sqlGetPendingVDiffRepairs, 1, 0, repairBatchSize), sqltypes.MakeTestResult(repairFields,
+		"1|insert|stmt 1|source 1|target 1",
+		"4|insert|stmt 4|source 4|target 4",
+	), nil)
+	dbClient.ExpectRequest("begin", nil, nil)
+	dbClient.ExpectRequest("target 1", &sqltypes.Result{}, nil)
+	dbClient.ExpectRequest("stmt 1", nil, errors.New("duplicate key"))
+	dbClient.ExpectRequest("rollback", nil, nil)
+	require.EqualError(t, r.applyRepairs(ctx, 1), "failed to apply repair statement 1: duplicate key")
+	dbClient.Wait()
+	assert.EqualValues(t, 0, r.applied)
+
+	// The throttler is waited on until the context expires.
+	r.throttle = func(ctx context.Context) bool { return false }
+	cctx, cancel := context.WithCancel(ctx)
+	cancel()
+	dbClient.ExpectRequest(fmt.Sprintf(sqlGetPendingVDiffRepairs, 1, 0, repairBatchSize), sqltypes.MakeTestResult(repairFields,
+		"1|insert|stmt 1|source 1|target 1",
+	), nil)
+	require.EqualError(t, r.applyRepairs(cctx, 1), "context has expired")
+	dbClient.Wait()
+}
+
+func TestHandleRepairAction(t *testing.T) {
+	ctx := context.Background()
+	ts := memorytopo.NewServer("zone1")
+	source := &topodatapb.Tablet{
+		Alias:    &topodatapb.TabletAlias{Cell: "zone1", Uid: 200},
+		Keyspace: "source",
+		Shard:    "0",
+		Type:     topodatapb.TabletType_PRIMARY,
+	}
+	require.NoError(t, ts.CreateKeyspace(ctx, "source", &topodatapb.Keyspace{}))
+	require.NoError(t, ts.CreateShard(ctx, "source", "0"))
+	require.NoError(t, ts.CreateTablet(ctx, source))
+	_, err := ts.UpdateShardFields(ctx, "source", "0", func(si *topo.ShardInfo) error {
+		si.PrimaryAlias = source.Alias
+		return nil
+	})
+	require.NoError(t, err)
+
+	dbClient := binlogplayer.NewMockDBClient(t)
+	tmc := &repairTMClient{sourceRows: map[string]int{"source 1": 1}}
+	vde := NewTestEngine(ts, &topodatapb.Tablet{Alias: &topodatapb.TabletAlias{Cell: "zone1", Uid: 100}}, "vt_target",
+		func() binlogplayer.DBClient { return dbClient }, func() tmclient.TabletManagerClient { return tmc })
+
+	const uuid = "a5f2e82c-4d4d-11ee-be56-0242ac120002"
+	req := &tabletmanagerdatapb.VDiffRequest{Keyspace: "target", Workflow: "wf", ActionArg: uuid}
+	vdiffQuery := fmt.Sprintf(sqlGetVDiffByKeyspaceWorkflowUUID, encodeString("target"), encodeString("wf"), encodeString(uuid))
+	vdiffResult := func(state VDiffState) *sqltypes.Result {
+		return sqltypes.MakeTestResult(sqltypes.MakeTestFields("id|state", "int64|varbinary"), fmt.Sprintf("1|%s", state))
+	}
+
+	t.Run("repair-plan", func(t *testing.T) {
+		plan := sqltypes.MakeTestResult(sqltypes.MakeTestFields("table_name|statement|state", "varbinary|varbinary|varbinary"),
+			"t1|insert into vt_target.t1(c1) values (1)|pending")
+		dbClient.ExpectRequest(vdiffQuery, vdiffResult(StartedState), nil)
+		dbClient.ExpectRequest(fmt.Sprintf(sqlGetVDiffRepairs, 1), plan, nil)
+		resp := &tabletmanagerdatapb.VDiffResponse{}
+		require.NoError(t, vde.handleRepairAction(ctx, dbClient, RepairPlanAction, req, resp))
+		dbClient.Wait()
+		assert.EqualValues(t, 1, resp.Id)
+		assert.Equal(t, sqltypes.ResultToProto3(plan), resp.Output)
+	})
+
+	t.Run("repair of a running vdiff", func(t *testing.T) {
+		dbClient.ExpectRequest(vdiffQuery, vdiffResult(StartedState), nil)
+		err := vde.handleRepairAction(ctx, dbClient, RepairAction, req, &tabletmanagerdatapb.VDiffResponse{})
+		require.ErrorContains(t, err, "only a completed vdiff can be repaired")
+		dbClient.Wait()
+		assert.Empty(t, tmc.vrQueries)
+	})
+
+	t.Run("repair", func(t *testing.T) {
+		dbClient.ExpectRequest(vdiffQuery, vdiffResult(CompletedState), nil)
+		dbClient.ExpectRequest("select * from _vt.vreplication where workflow = 'wf' and db_name = 'vt_target'",
+			sqltypes.MakeTestResult(sqltypes.MakeTestFields("id|state|source", "int64|varbinary|varbinary"),
+				`1|Running|keyspace:"source" shard:"0" filter:{rules:{match:"t1"}}`), nil)
+		dbClient.ExpectRequest(fmt.Sprintf(sqlGetPendingVDiffRepairs, 1, 0, repairBatchSize), sqltypes.MakeTestResult(repairFields,
+			"1|insert|stmt 1|source 1|target 1",
+			"2|insert|stmt 2|source 2|target 2",
+		), nil)
+		dbClient.ExpectRequest("begin", nil, nil)
+		dbClient.ExpectRequest("target 1", &sqltypes.Result{}, nil)
+		dbClient.ExpectRequest("stmt 1", &sqltypes.Result{}, nil)
+		dbClient.ExpectRequest(fmt.Sprintf(sqlUpdateVDiffRepairApplied, 1), &sqltypes.Result{}, nil)
+		dbClient.ExpectRequest("commit", nil, nil)
+		dbClient.ExpectRequest("begin", nil, nil)
+		dbClient.ExpectRequest("target 2", &sqltypes.Result{}, nil)
+		dbClient.ExpectRequest(fmt.Sprintf(sqlUpdateVDiffRepairSkipped, 2), &sqltypes.Result{}, nil)
+		dbClient.ExpectRequest("commit", nil, nil)
+		dbClient.ExpectRequest(fmt.Sprintf(sqlGetPendingVDiffRepairs, 1, 2, repairBatchSize), &sqltypes.Result{}, nil)
+		dbClient.ExpectRequest("insert into _vt.vdiff_log(vdiff_id, message) values (1, 'Applied 1 repair statements, skipped 1 whose rows changed since the diff')", &sqltypes.Result{}, nil)
+
+		resp := &tabletmanagerdatapb.VDiffResponse{}
+		require.NoError(t, vde.handleRepairAction(ctx, dbClient, RepairAction, req, resp))
+		dbClient.Wait()
+		qr := sqltypes.Proto3ToResult(resp.Output)
+		assert.EqualValues(t, 1, qr.RowsAffected)
+		skipped, err := qr.Named().Row().ToInt64("skipped")
+		require.NoError(t, err)
+		assert.EqualValues(t, 1, skipped)
+		// The workflow is stopped while the plan is applied.
+		assert.Equal(t, []string{fmt.Sprintf(sqlStopVReplicationForRepair, "1"), fmt.Sprintf(sqlRestartVReplicationAfterRepair, "1")}, tmc.vrQueries)
+	})
+}
@@ -41,6 +41,15 @@ type DiffReport struct {
 	MismatchedRows  int64
 	ExtraRowsSource int64
 	ExtraRowsTarget int64
+	// DuplicateRowsSource and DuplicateRowsTarget count the rows of a table
+	// without a primary key that are identical to the previous row.
+	DuplicateRowsSource int64 `json:",omitempty"`
+	DuplicateRowsTarget int64 `json:",omitempty"`
+	// ChecksumBuckets counts the primary key ranges that were compared by
+	// checksum, and MismatchedBuckets the ones whose rows were compared
+	// because their checksums differed.
+	ChecksumBuckets   int64 `json:",omitempty"`
+	MismatchedBuckets int64 `json:",omitempty"`
 
 	// actual data for a few sample rows
 	ExtraRowsSourceDiffs []*RowDiff      `json:"ExtraRowsSourceSample,omitempty"`
@@ -27,11 +27,13 @@ const (
 	sqlGetVDiffByKeyspaceWorkflowUUID = "select * from _vt.vdiff where keyspace = %s and workflow = %s and vdiff_uuid = %s"
 	sqlGetMostRecentVDiff             = "select * from _vt.vdiff where keyspace = %s and workflow = %s order by id desc limit 1"
 	sqlGetVDiffByID                   = "select * from _vt.vdiff where id = %d"
-	sqlDeleteVDiffs                   = `delete from vd, vdt, vdl using _vt.vdiff as vd left join _vt.vdiff_table as vdt on (vd.id = vdt.vdiff_id)
+	sqlDeleteVDiffs                   = `delete from vd, vdt, vdl, vdr using _vt.vdiff as vd left join _vt.vdiff_table as vdt on (vd.id = vdt.vdiff_id)
 										left join _vt.vdiff_log as vdl on (vd.id = vdl.vdiff_id)
+										left join _vt.vdiff_repair as vdr on (vd.id = vdr.vdiff_id)
 										where vd.keyspace = %s and vd.workflow = %s`
-	sqlDeleteVDiffByUUID = `delete from vd, vdt using _vt.vdiff as vd left join _vt.vdiff_table as vdt on (vd.id = vdt.vdiff_id)
-							and vd.vdiff_uuid = %s`
+	sqlDeleteVDiffByUUID = `delete from vd, vdt, vdr using _vt.vdiff as vd left join _vt.vdiff_table as vdt on (vd.id = vdt.vdiff_id)
+							left join _vt.vdiff_repair as vdr on (vd.id = vdr.vdiff_id)
+							where vd.vdiff_uuid = %s`
 	sqlVDiffSummary = `select vd.state as vdiff_state, vd.last_error as last_error, vdt.table_name as table_name,
 						vd.vdiff_uuid as 'uuid', vdt.state as table_state, vdt.table_rows as table_rows,
 						vd.started_at as started_at, vdt.rows_compared as rows_compared, vd.completed_at as completed_at,
@@ -62,4 +64,13 @@ const (
 	sqlUpdateTableMismatch       = "update _vt.vdiff_table set mismatch = true where vdiff_id = %d and table_name = %s"
 
 	sqlGetIncompleteTables = "select table_name as table_name from _vt.vdiff_table where vdiff_id = %d and state != 'completed'"
+
+	sqlNewVDiffRepair           = "insert into _vt.vdiff_repair(vdiff_id, table_name, kind, statement, source_check, target_check) values %s"
+	sqlGetVDiffRepairs          = "select table_name as table_name, statement as statement, state as state from _vt.vdiff_repair where vdiff_id = %d order by id"
+	sqlGetPendingVDiffRepairs   = "select id as id, kind as kind, statement as statement, source_check as source_check, target_check as target_check from _vt.vdiff_repair where vdiff_id = %d and state = 'pending' and id > %d order by id limit %d"
+	sqlUpdateVDiffRepairApplied = "update _vt.vdiff_repair set state = 'applied', applied_at = utc_timestamp() where id = %d"
+	sqlUpdateVDiffRepairSkipped = "update _vt.vdiff_repair set state = 'skipped' where id = %d"
+
+	sqlStopVReplicationForRepair      = "update _vt.vreplication set state = 'Stopped', message = 'for vdiff repair' where id in (%s)"
+	sqlRestartVReplicationAfterRepair = "update _vt.vreplication set state = 'Running', message = '' where id in (%s)"
 )
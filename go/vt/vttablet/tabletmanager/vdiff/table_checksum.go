@@ -0,0 +1,441 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vdiff
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/binlog/binlogplayer"
+	"vitess.io/vitess/go/vt/key"
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/topo/topoproto"
+	"vitess.io/vitess/go/vt/vterrors"
+
+	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	tabletmanagerdatapb "vitess.io/vitess/go/vt/proto/tabletmanagerdata"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+/*
+	When a VDiff is run with the checksum option, the rows of a table are compared by
+	primary key ranges, called buckets, instead of one by one:
+		* The end of each bucket is the last of the next checksumBucketRows primary keys
+			after the end of the previous one on the target, which are read from the primary
+			key index.
+		* Each side computes the number of rows of the bucket, and the BIT_XOR of a hash of
+			each row. Each column is prefixed with its length before the row is hashed, so
+			that values can't run into each other. The checksums of the sources are XORed
+			together.
+		* If the checksums match, the rows of the bucket are counted as matching. Otherwise,
+			the rows of the bucket are compared one by one, like for a regular VDiff.
+		* The streams of the rows that are compared one by one are started once, at the first
+			bucket whose checksums differ, and go on to the end of the table. The rows of the
+			later buckets whose checksums match are skipped.
+
+	The checksums are computed while the workflow is running, so a bucket whose rows are
+	changing can mismatch even though its rows will end up matching. The comparison of the
+	rows of such a bucket uses consistent snapshots, and sorts that out.
+
+	The source computes the checksums with a query, so the rows of workflows that filter them
+	by keyrange, which only the vstreamer can do, can't be compared by checksum. A VDiff with
+	the checksum option is rejected for these workflows when it's created.
+
+	The end of the last bucket compared is saved as the lastpk of the table, so a VDiff that
+	is resumed or retried carries on from there.
+*/
+
+// checksumBucket is the number of rows and the checksum of a range of a
+// table on one side.
+type checksumBucket struct {
+	rows     int64
+	checksum uint64
+}
+
+// checksumUnsupportedReason returns why the rows of the table can't be
+// compared by checksum, or an empty string if they can.
+func (tp *tablePlan) checksumUnsupportedReason() string {
+	switch {
+	case tp.fullRowKey:
+		return "the table has no primary key"
+	case len(tp.aggregates) != 0:
+		return "the filter of the table has aggregates"
+	}
+	return ""
+}
+
+// checkChecksumFilter returns an error if the rows of a workflow with the
+// given filter can't be compared by checksum. The rules that filter rows by
+// keyrange, as Reshard and MoveTables into a sharded keyspace do, rely on
+// in_keyrange, which only the vstreamer understands, so the checksums of
+// their rows can't be computed with a query on the source.
+func checkChecksumFilter(filter *binlogdatapb.Filter) error {
+	for _, rule := range filter.GetRules() {
+		if key.IsValidKeyRange(rule.Filter) {
+			return fmt.Errorf("checksums are not supported for table %s, as its rows are filtered by keyrange %s", rule.Match, rule.Filter)
+		}
+		if rule.Filter == "" || rule.Filter == "exclude" {
+			continue
+		}
+		stmt, err := sqlparser.Parse(rule.Filter)
+		if err != nil {
+			return err
+		}
+		inKeyrange := false
+		_ = sqlparser.Walk(func(node sqlparser.SQLNode) (bool, error) {
+			if fn, ok := node.(*sqlparser.FuncExpr); ok && fn.Name.EqualString("in_keyrange") {
+				inKeyrange = true
+				return false, nil
+			}
+			return true, nil
+		}, stmt)
+		if inKeyrange {
+			return fmt.Errorf("checksums are not supported for table %s, as its rows are filtered with in_keyrange", rule.Match)
+		}
+	}
+	return nil
+}
+
+// checksumSelect returns the select of the plan without its order by. If
+// dbName is set, the table is qualified with it.
+func (tp *tablePlan) checksumSelect(query, dbName string) (*sqlparser.Select, error) {
+	stmt, err := sqlparser.Parse(query)
+	if err != nil {
+		return nil, err
+	}
+	sel, ok := stmt.(*sqlparser.Select)
+	if !ok {
+		return nil, fmt.Errorf("unexpected: %v", sqlparser.String(stmt))
+	}
+	sel.OrderBy = nil
+	if dbName != "" {
+		for _, expr := range sel.From {
+			if aliased, ok := expr.(*sqlparser.AliasedTableExpr); ok {
+				if tableName, ok := aliased.Expr.(sqlparser.TableName); ok {
+					tableName.Qualifier = sqlparser.NewIdentifierCS(dbName)
+					aliased.Expr = tableName
+				}
+			}
+		}
+	}
+	return sel, nil
+}
+
+// bucketEndQuery returns the query that reads the pks of the bucket that
+// starts after start on the target. The last of them is the end of the
+// bucket.
+func (tp *tablePlan) bucketEndQuery(start []sqltypes.Value, bucketRows int64) string {
+	buf := &strings.Builder{}
+	buf.WriteString("select ")
+	tp.writePKColumns(buf)
+	fmt.Fprintf(buf, " from %s", sqlparser.String(sqlparser.TableName{
+		Name:      sqlparser.NewIdentifierCS(tp.table.Name),
+		Qualifier: sqlparser.NewIdentifierCS(tp.dbName),
+	}))
+	if start != nil {
+		buf.WriteString(" where ")
+		tp.writePKComparison(buf, ">", start)
+	}
+	buf.WriteString(" order by ")
+	tp.writePKColumns(buf)
+	fmt.Fprintf(buf, " limit %d", bucketRows)
+	return buf.String()
+}
+
+// checksumQuery returns the query that computes the number of rows and the
+// checksum of the rows of query after start and up to end. A nil start or
+// end leaves the range open on that side.
+func (tp *tablePlan) checksumQuery(query, dbName string, start, end []sqltypes.Value) (string, error) {
+	sel, err := tp.checksumSelect(query, dbName)
+	if err != nil {
+		return "", err
+	}
+	// The rows are hashed on the column names of the target, which the
+	// select of the source uses as aliases. Each value is prefixed with its
+	// length, and NULL is written as a value that has no length, so that no
+	// two rows are hashed from the same string.
+	cols := make([]string, 0, len(tp.compareCols))
+	for _, col := range tp.compareCols {
+		name := sqlparser.String(sqlparser.NewIdentifierCI(col.colName))
+		cols = append(cols, fmt.Sprintf("ifnull(concat(length(%s), ':', %s), '-')", name, name))
+	}
+	buf := &strings.Builder{}
+	fmt.Fprintf(buf, "select count(*) as cnt, bit_xor(cast(conv(substring(md5(concat(%s)), 1, 16), 16, 10) as unsigned)) as checksum from (%s) as vdt",
+		strings.Join(cols, ", "), sqlparser.String(sel))
+	sep := " where "
+	if start != nil {
+		buf.WriteString(sep)
+		tp.writePKComparison(buf, ">", start)
+		sep = " and "
+	}
+	if end != nil {
+		buf.WriteString(sep)
+		tp.writePKComparison(buf, "<=", end)
+	}
+	return buf.String(), nil
+}
+
+func (tp *tablePlan) writePKColumns(buf *strings.Builder) {
+	for i, pk := range tp.comparePKs {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		buf.WriteString(sqlparser.String(sqlparser.NewIdentifierCI(pk.colName)))
+	}
+}
+
+// writePKComparison writes the comparison of the pk columns with the pk
+// values of row.
+func (tp *tablePlan) writePKComparison(buf *strings.Builder, op string, row []sqltypes.Value) {
+	buf.WriteByte('(')
+	tp.writePKColumns(buf)
+	fmt.Fprintf(buf, ") %s (", op)
+	for i, pk := range tp.comparePKs {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		row[pk.colIndex].EncodeSQLStringBuilder(buf)
+	}
+	buf.WriteByte(')')
+}
+
+// diffChecksums is the version of diff that compares the rows of the table
+// by checksum, and only compares the rows of the buckets whose checksums
+// differ.
+func (td *tableDiffer) diffChecksums(ctx context.Context, dbClient binlogplayer.DBClient, rowsToCompare int64, debug, onlyPks bool, maxExtraRowsToCompare int64) (*DiffReport, error) {
+	dr, _, err := td.getReport(dbClient)
+	if err != nil {
+		return nil, err
+	}
+	if err := td.selectTablets(ctx, td.wd.opts.PickerOptions.SourceCell, td.wd.opts.PickerOptions.TabletTypes); err != nil {
+		return nil, err
+	}
+	// The streams of the rows of the buckets are abandoned once the
+	// table is compared, so they are canceled when we're done.
+	ctx, cancel := context.WithCancel(ctx)
+	defer func() {
+		cancel()
+		td.sourceExecutor, td.targetExecutor = nil, nil
+	}()
+
+	start := td.rowFromLastPK(td.lastPK)
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, vterrors.Errorf(vtrpcpb.Code_CANCELED, "context has expired")
+		default:
+		}
+		if dr.ProcessedRows >= rowsToCompare {
+			log.Infof("Stopping vdiff, specified limit reached")
+			return dr, nil
+		}
+
+		end, err := td.bucketEnd(dbClient, start)
+		if err != nil {
+			return nil, err
+		}
+		source, target, err := td.bucketChecksums(ctx, dbClient, start, end)
+		if err != nil {
+			return nil, err
+		}
+		dr.ChecksumBuckets++
+		if source == target {
+			if err := td.skipBucket(end); err != nil {
+				return nil, err
+			}
+			dr.ProcessedRows += target.rows
+			dr.MatchingRows += target.rows
+		} else {
+			log.Infof("Checksums of table %s differ after %v up to %v: source %+v, target %+v, comparing the rows",
+				td.table.Name, start, end, source, target)
+			dr.MismatchedBuckets++
+			if err := td.updateTableProgress(dbClient, dr, start); err != nil {
+				return nil, err
+			}
+			if dr, err = td.diffBucket(ctx, start, end, rowsToCompare-dr.ProcessedRows, debug, onlyPks, maxExtraRowsToCompare); err != nil {
+				return nil, err
+			}
+		}
+		if end == nil {
+			return dr, nil
+		}
+		if err := td.updateTableProgress(dbClient, dr, end); err != nil {
+			return nil, err
+		}
+		start = end
+	}
+}
+
+// bucketEnd returns the end of the bucket that starts after start, or nil
+// if the rest of the table fits in the bucket.
+func (td *tableDiffer) bucketEnd(dbClient binlogplayer.DBClient, start []sqltypes.Value) ([]sqltypes.Value, error) {
+	bucketRows := checksumBucketRows
+	if bucketRows < 1 {
+		bucketRows = 1
+	}
+	qr, err := dbClient.ExecuteFetch(td.tablePlan.bucketEndQuery(start, bucketRows), int(bucketRows))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(qr.Rows)) < bucketRows {
+		return nil, nil
+	}
+	last := qr.Rows[len(qr.Rows)-1]
+	end := make([]sqltypes.Value, len(td.tablePlan.compareCols))
+	for i, pk := range td.tablePlan.comparePKs {
+		end[pk.colIndex] = last[i]
+	}
+	return end, nil
+}
+
+// bucketChecksums computes the checksums of the bucket on the sources and on
+// the target.
+func (td *tableDiffer) bucketChecksums(ctx context.Context, dbClient binlogplayer.DBClient, start, end []sqltypes.Value) (source, target checksumBucket, err error) {
+	ct := td.wd.ct
+	sourceQuery, err := td.tablePlan.checksumQuery(td.tablePlan.sourceQuery, "", start, end)
+	if err != nil {
+		return source, target, err
+	}
+	targetQuery, err := td.tablePlan.checksumQuery(td.tablePlan.targetQuery, td.tablePlan.dbName, start, end)
+	if err != nil {
+		return source, target, err
+	}
+
+	var mu sync.Mutex
+	if err := td.forEachSource(func(ms *migrationSource) error {
+		qr, err := ct.tmc.ExecuteFetchAsDba(ctx, ms.tablet, false, &tabletmanagerdatapb.ExecuteFetchAsDbaRequest{
+			Query:   []byte(sourceQuery),
+			DbName:  topoproto.TabletDbName(ms.tablet),
+			MaxRows: 1,
+		})
+		if err != nil {
+			return vterrors.Wrapf(err, "checksum on tablet %v", topoproto.TabletAliasString(ms.tablet.Alias))
+		}
+		bucket, err := parseChecksumBucket(sqltypes.Proto3ToResult(qr))
+		if err != nil {
+			return err
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		source.rows += bucket.rows
+		source.checksum ^= bucket.checksum
+		return nil
+	}); err != nil {
+		return source, target, err
+	}
+
+	qr, err := dbClient.ExecuteFetch(targetQuery, 1)
+	if err != nil {
+		return source, target, err
+	}
+	target, err = parseChecksumBucket(qr)
+	return source, target, err
+}
+
+func parseChecksumBucket(qr *sqltypes.Result) (checksumBucket, error) {
+	var bucket checksumBucket
+	if len(qr.Rows) != 1 || len(qr.Rows[0]) != 2 {
+		return bucket, vterrors.Errorf(vtrpcpb.Code_INTERNAL, "unexpected result for checksum: %+v", qr.Rows)
+	}
+	var err error
+	if bucket.rows, err = qr.Rows[0][0].ToInt64(); err != nil {
+		return bucket, err
+	}
+	// The checksum of an empty bucket is 0.
+	if !qr.Rows[0][1].IsNull() {
+		if bucket.checksum, err = qr.Rows[0][1].ToUint64(); err != nil {
+			return bucket, err
+		}
+	}
+	return bucket, nil
+}
+
+// diffBucket compares the rows of a bucket one by one. It returns the report
+// of the table, updated with the results for the bucket.
+func (td *tableDiffer) diffBucket(ctx context.Context, start, end []sqltypes.Value, rowsToCompare int64, debug, onlyPks bool, maxExtraRowsToCompare int64) (*DiffReport, error) {
+	// The streams are only started for the first bucket whose checksums
+	// differ, as starting them means stopping the workflow to take consistent
+	// snapshots. They go on to the end of the table, and the rows of the
+	// buckets whose checksums match are skipped.
+	if td.sourceExecutor == nil {
+		for _, source := range td.wd.ct.sources {
+			source.shardStreamer = &shardStreamer{tablet: source.tablet, shard: source.shard}
+		}
+		td.lastPK = nil
+		if start != nil {
+			td.lastPK = td.lastPKResultFromRow(start)
+		}
+		if err := td.initialize(ctx); err != nil {
+			return nil, err
+		}
+		td.sourceExecutor = newPrimitiveExecutor(ctx, td.sourcePrimitive, "source")
+		td.targetExecutor = newPrimitiveExecutor(ctx, td.targetPrimitive, "target")
+	}
+
+	td.endRow = end
+	defer func() {
+		td.endRow = nil
+	}()
+	return td.diff(ctx, rowsToCompare, debug, onlyPks, maxExtraRowsToCompare)
+}
+
+// skipBucket discards the rows of the streams up to end, for a bucket whose
+// checksums match after the streams were started.
+func (td *tableDiffer) skipBucket(end []sqltypes.Value) error {
+	if td.sourceExecutor == nil || end == nil {
+		return nil
+	}
+	td.endRow = end
+	defer func() {
+		td.endRow = nil
+	}()
+	for _, pe := range []*primitiveExecutor{td.sourceExecutor, td.targetExecutor} {
+		for {
+			row, err := pe.next()
+			if err != nil {
+				return err
+			}
+			if row == nil {
+				break
+			}
+			if td.isPastEndRow(row) {
+				pe.unread(row)
+				break
+			}
+		}
+	}
+	return nil
+}
+
+// rowFromLastPK returns a row with the pk values of lastPK at the indexes
+// of the pk columns in the select list.
+func (td *tableDiffer) rowFromLastPK(lastPK *querypb.QueryResult) []sqltypes.Value {
+	if lastPK == nil || len(lastPK.Rows) == 0 {
+		return nil
+	}
+	pkVals := sqltypes.Proto3ToResult(lastPK).Rows[0]
+	row := make([]sqltypes.Value, len(td.tablePlan.compareCols))
+	for i, colIndex := range td.tablePlan.pkCols {
+		row[colIndex] = pkVals[i]
+	}
+	return row
+}
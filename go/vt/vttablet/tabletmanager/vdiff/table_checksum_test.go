@@ -0,0 +1,171 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vdiff
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/binlog/binlogplayer"
+
+	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
+	tabletmanagerdatapb "vitess.io/vitess/go/vt/proto/tabletmanagerdata"
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+)
+
+func testChecksumTablePlan() *tablePlan {
+	return &tablePlan{
+		sourceQuery: "select c1, c2 as c3 from t1 order by c1 asc",
+		targetQuery: "select c1, c3 from t1 order by c1 asc",
+		compareCols: []compareColInfo{{colIndex: 0, isPK: true, colName: "c1"}, {colIndex: 1, colName: "c3"}},
+		comparePKs:  []compareColInfo{{colIndex: 0, isPK: true, colName: "c1"}},
+		pkCols:      []int{0},
+		dbName:      "vt_customer",
+		table:       &tabletmanagerdatapb.TableDefinition{Name: "t1"},
+	}
+}
+
+func TestChecksumQueries(t *testing.T) {
+	tp := testChecksumTablePlan()
+	start := []sqltypes.Value{sqltypes.NewInt64(10), sqltypes.NULL}
+	end := []sqltypes.Value{sqltypes.NewInt64(20), sqltypes.NULL}
+
+	assert.Equal(t, "select c1 from vt_customer.t1 order by c1 limit 100", tp.bucketEndQuery(nil, 100))
+	assert.Equal(t, "select c1 from vt_customer.t1 where (c1) > (10) order by c1 limit 100", tp.bucketEndQuery(start, 100))
+
+	query, err := tp.checksumQuery(tp.sourceQuery, "", start, end)
+	require.NoError(t, err)
+	assert.Equal(t, "select count(*) as cnt, bit_xor(cast(conv(substring(md5(concat(ifnull(concat(length(c1), ':', c1), '-'), ifnull(concat(length(c3), ':', c3), '-'))), 1, 16), 16, 10) as unsigned)) as checksum "+
+		"from (select c1, c2 as c3 from t1) as vdt where (c1) > (10) and (c1) <= (20)", query)
+
+	query, err = tp.checksumQuery(tp.targetQuery, tp.dbName, nil, end)
+	require.NoError(t, err)
+	assert.Equal(t, "select count(*) as cnt, bit_xor(cast(conv(substring(md5(concat(ifnull(concat(length(c1), ':', c1), '-'), ifnull(concat(length(c3), ':', c3), '-'))), 1, 16), 16, 10) as unsigned)) as checksum "+
+		"from (select c1, c3 from vt_customer.t1) as vdt where (c1) <= (20)", query)
+}
+
+func TestBucketEnd(t *testing.T) {
+	savedBucketRows := checksumBucketRows
+	checksumBucketRows = 3
+	defer func() { checksumBucketRows = savedBucketRows }()
+
+	td := &tableDiffer{tablePlan: testChecksumTablePlan()}
+	dbClient := binlogplayer.NewMockDBClient(t)
+	fields := sqltypes.MakeTestFields("c1", "int64")
+
+	// The end of a full bucket is its last pk.
+	dbClient.ExpectRequest("select c1 from vt_customer.t1 order by c1 limit 3", sqltypes.MakeTestResult(fields, "1", "2", "5"), nil)
+	end, err := td.bucketEnd(dbClient, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []sqltypes.Value{sqltypes.NewInt64(5), {}}, end)
+
+	// The next bucket is read from the end of the previous one, and the rest
+	// of the table fits in it.
+	dbClient.ExpectRequest("select c1 from vt_customer.t1 where (c1) > (5) order by c1 limit 3", sqltypes.MakeTestResult(fields, "7", "8"), nil)
+	end, err = td.bucketEnd(dbClient, end)
+	require.NoError(t, err)
+	assert.Nil(t, end)
+	dbClient.Wait()
+}
+
+func TestChecksumUnsupportedReason(t *testing.T) {
+	tp := testChecksumTablePlan()
+	assert.Empty(t, tp.checksumUnsupportedReason())
+
+	tp.fullRowKey = true
+	assert.Equal(t, "the table has no primary key", tp.checksumUnsupportedReason())
+}
+
+func TestCheckChecksumSupported(t *testing.T) {
+	vde := &Engine{dbName: "vt_customer", thisTablet: &topodatapb.Tablet{Alias: &topodatapb.TabletAlias{Cell: "zone1", Uid: 100}}}
+	dbClient := binlogplayer.NewMockDBClient(t)
+	query := "select * from _vt.vreplication where workflow = 'wf1' and db_name = 'vt_customer'"
+	result := func(filters ...string) *sqltypes.Result {
+		var rows []string
+		for i, filter := range filters {
+			bls := &binlogdatapb.BinlogSource{
+				Keyspace: "commerce",
+				Shard:    "0",
+				Filter:   &binlogdatapb.Filter{Rules: []*binlogdatapb.Rule{{Match: "t1", Filter: filter}}},
+			}
+			rows = append(rows, fmt.Sprintf("%d|%s", i+1, bls))
+		}
+		return sqltypes.MakeTestResult(sqltypes.MakeTestFields("id|source", "int64|varbinary"), rows...)
+	}
+
+	dbClient.ExpectRequest(query, result("select * from t1", ""), nil)
+	require.NoError(t, vde.checkChecksumSupported(dbClient, "wf1"))
+
+	// Reshard
+	dbClient.ExpectRequest(query, result("-80"), nil)
+	require.EqualError(t, vde.checkChecksumSupported(dbClient, "wf1"),
+		"cannot use --checksum for workflow wf1 on tablet zone1-0000000100: checksums are not supported for table t1, as its rows are filtered by keyrange -80")
+
+	// MoveTables into a sharded keyspace
+	dbClient.ExpectRequest(query, result("select * from t1", "select * from t1 where in_keyrange(id, 'hash', '80-')"), nil)
+	require.EqualError(t, vde.checkChecksumSupported(dbClient, "wf1"),
+		"cannot use --checksum for workflow wf1 on tablet zone1-0000000100: checksums are not supported for table t1, as its rows are filtered with in_keyrange")
+	dbClient.Wait()
+}
+
+func TestSkipBucket(t *testing.T) {
+	td := &tableDiffer{tablePlan: testChecksumTablePlan()}
+	fields := sqltypes.MakeTestFields("c1|c3", "int64|varchar")
+	executor := func(rows ...string) *primitiveExecutor {
+		pe := &primitiveExecutor{resultch: make(chan *sqltypes.Result, 1)}
+		pe.resultch <- sqltypes.MakeTestResult(fields, rows...)
+		close(pe.resultch)
+		return pe
+	}
+	td.sourceExecutor = executor("1|a", "2|b", "5|c", "7|d")
+	td.targetExecutor = executor("1|a", "3|b", "7|d")
+
+	// The rows up to the end of the bucket are skipped, and the first row
+	// after it is left for the next bucket.
+	require.NoError(t, td.skipBucket([]sqltypes.Value{sqltypes.NewInt64(3), {}}))
+	row, err := td.sourceExecutor.next()
+	require.NoError(t, err)
+	assert.Equal(t, "5", row[0].ToString())
+	row, err = td.targetExecutor.next()
+	require.NoError(t, err)
+	assert.Equal(t, "7", row[0].ToString())
+	assert.Nil(t, td.endRow)
+
+	// Skipping past the end of the streams stops there.
+	require.NoError(t, td.skipBucket([]sqltypes.Value{sqltypes.NewInt64(10), {}}))
+	row, err = td.sourceExecutor.next()
+	require.NoError(t, err)
+	assert.Nil(t, row)
+}
+
+func TestParseChecksumBucket(t *testing.T) {
+	fields := sqltypes.MakeTestFields("cnt|checksum", "int64|uint64")
+	bucket, err := parseChecksumBucket(sqltypes.MakeTestResult(fields, "3|12345"))
+	require.NoError(t, err)
+	assert.Equal(t, checksumBucket{rows: 3, checksum: 12345}, bucket)
+
+	bucket, err = parseChecksumBucket(sqltypes.MakeTestResult(fields, "0|null"))
+	require.NoError(t, err)
+	assert.Equal(t, checksumBucket{}, bucket)
+
+	_, err = parseChecksumBucket(sqltypes.MakeTestResult(fields))
+	require.Error(t, err)
+}
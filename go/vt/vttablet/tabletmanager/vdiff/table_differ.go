@@ -72,6 +72,19 @@ type tableDiffer struct {
 	sourceQuery string
 	table       *tabletmanagerdatapb.TableDefinition
 	lastPK      *querypb.QueryResult
+	// endRow limits the diff to the rows up to its primary key, when only a
+	// range of the table is compared. Its pk values are at the indexes of
+	// the pk columns in the select list.
+	endRow []sqltypes.Value
+	// sourceExecutor and targetExecutor are the streams of the rows, when
+	// they are shared by the diffs of several ranges of the table.
+	sourceExecutor *primitiveExecutor
+	targetExecutor *primitiveExecutor
+
+	// repairs is the repair plan of the rows compared so far, which is saved
+	// once the table is compared.
+	repairs          []repairRow
+	repairsTruncated bool
 }
 
 func newTableDiffer(wd *workflowDiffer, table *tabletmanagerdatapb.TableDefinition, sourceQuery string) *tableDiffer {
@@ -439,34 +452,17 @@ func (td *tableDiffer) diff(ctx context.Context, rowsToCompare int64, debug, onl
 	}
 	defer dbClient.Close()
 
-	// We need to continue were we left off when appropriate. This can be an
-	// auto-retry on error, or a manual retry via the resume command.
-	// Otherwise the existing state will be empty and we start from scratch.
-	query := fmt.Sprintf(sqlGetVDiffTable, td.wd.ct.id, encodeString(td.table.Name))
-	cs, err := dbClient.ExecuteFetch(query, -1)
+	dr, mismatch, err := td.getReport(dbClient)
 	if err != nil {
 		return nil, err
 	}
-	if len(cs.Rows) == 0 {
-		return nil, fmt.Errorf("no state found for vdiff table %s for vdiff_id %d on tablet %v",
-			td.table.Name, td.wd.ct.id, td.wd.ct.vde.thisTablet.Alias)
-	} else if len(cs.Rows) > 1 {
-		return nil, fmt.Errorf("invalid state found for vdiff table %s (multiple records) for vdiff_id %d on tablet %v",
-			td.table.Name, td.wd.ct.id, td.wd.ct.vde.thisTablet.Alias)
-	}
-	curState := cs.Named().Row()
-	mismatch := curState.AsBool("mismatch", false)
-	dr := &DiffReport{}
-	if rpt := curState.AsBytes("report", []byte("{}")); json.Valid(rpt) {
-		if err = json.Unmarshal(rpt, dr); err != nil {
-			return nil, err
-		}
-	}
-	dr.TableName = td.table.Name
 
-	sourceExecutor := newPrimitiveExecutor(ctx, td.sourcePrimitive, "source")
-	targetExecutor := newPrimitiveExecutor(ctx, td.targetPrimitive, "target")
-	var sourceRow, lastProcessedRow, targetRow []sqltypes.Value
+	sourceExecutor, targetExecutor := td.sourceExecutor, td.targetExecutor
+	if sourceExecutor == nil {
+		sourceExecutor = newPrimitiveExecutor(ctx, td.sourcePrimitive, "source")
+		targetExecutor = newPrimitiveExecutor(ctx, td.targetPrimitive, "target")
+	}
+	var sourceRow, lastProcessedRow, targetRow, lastTargetRow []sqltypes.Value
 	advanceSource := true
 	advanceTarget := true
 
@@ -504,13 +500,29 @@ func (td *tableDiffer) diff(ctx context.Context, rowsToCompare int64, debug, onl
 				log.Error(err)
 				return nil, err
 			}
+			// The first row of the next range is left for its diff
+			if td.isPastEndRow(sourceRow) {
+				sourceExecutor.unread(sourceRow)
+				sourceRow = nil
+			}
+			if td.isDuplicateRow(lastProcessedRow, sourceRow) {
+				dr.DuplicateRowsSource++
+			}
 		}
 		if advanceTarget {
+			lastTargetRow = targetRow
 			targetRow, err = targetExecutor.next()
 			if err != nil {
 				log.Error(err)
 				return nil, err
 			}
+			if td.isPastEndRow(targetRow) {
+				targetExecutor.unread(targetRow)
+				targetRow = nil
+			}
+			if td.isDuplicateRow(lastTargetRow, targetRow) {
+				dr.DuplicateRowsTarget++
+			}
 		}
 
 		if sourceRow == nil && targetRow == nil {
@@ -519,15 +531,46 @@ func (td *tableDiffer) diff(ctx context.Context, rowsToCompare int64, debug, onl
 
 		advanceSource = true
 		advanceTarget = true
+		// The streams go on past the end of a range, so the rows left on
+		// one side are counted one by one instead of being drained.
+		if sourceRow == nil && td.endRow != nil {
+			if dr.ExtraRowsTarget < maxExtraRowsToCompare {
+				diffRow, err := td.genRowDiff(td.tablePlan.targetQuery, targetRow, debug, onlyPks)
+				if err != nil {
+					return nil, vterrors.Wrap(err, "unexpected error generating diff")
+				}
+				dr.ExtraRowsTargetDiffs = append(dr.ExtraRowsTargetDiffs, diffRow)
+			}
+			td.addRepair(repairDelete, nil, targetRow)
+			dr.ExtraRowsTarget++
+			dr.ProcessedRows++
+			advanceSource = false
+			continue
+		}
+		if targetRow == nil && td.endRow != nil {
+			if dr.ExtraRowsSource < maxExtraRowsToCompare {
+				diffRow, err := td.genRowDiff(td.tablePlan.sourceQuery, sourceRow, debug, onlyPks)
+				if err != nil {
+					return nil, vterrors.Wrap(err, "unexpected error generating diff")
+				}
+				dr.ExtraRowsSourceDiffs = append(dr.ExtraRowsSourceDiffs, diffRow)
+			}
+			td.addRepair(repairInsert, sourceRow, nil)
+			dr.ExtraRowsSource++
+			dr.ProcessedRows++
+			advanceTarget = false
+			continue
+		}
 		if sourceRow == nil {
 			diffRow, err := td.genRowDiff(td.tablePlan.sourceQuery, targetRow, debug, onlyPks)
 			if err != nil {
 				return nil, vterrors.Wrap(err, "unexpected error generating diff")
 			}
 			dr.ExtraRowsTargetDiffs = append(dr.ExtraRowsTargetDiffs, diffRow)
+			td.addRepair(repairDelete, nil, targetRow)
 
 			// drain target, update count
-			count, err := targetExecutor.drain(ctx)
+			count, err := td.drainRepairs(ctx, targetExecutor, repairDelete)
 			if err != nil {
 				return nil, err
 			}
@@ -543,7 +586,8 @@ func (td *tableDiffer) diff(ctx context.Context, rowsToCompare int64, debug, onl
 				return nil, vterrors.Wrap(err, "unexpected error generating diff")
 			}
 			dr.ExtraRowsSourceDiffs = append(dr.ExtraRowsSourceDiffs, diffRow)
-			count, err := sourceExecutor.drain(ctx)
+			td.addRepair(repairInsert, sourceRow, nil)
+			count, err := td.drainRepairs(ctx, sourceExecutor, repairInsert)
 			if err != nil {
 				return nil, err
 			}
@@ -567,6 +611,7 @@ func (td *tableDiffer) diff(ctx context.Context, rowsToCompare int64, debug, onl
 				}
 				dr.ExtraRowsSourceDiffs = append(dr.ExtraRowsSourceDiffs, diffRow)
 			}
+			td.addRepair(repairInsert, sourceRow, nil)
 			dr.ExtraRowsSource++
 			advanceTarget = false
 			continue
@@ -578,6 +623,7 @@ func (td *tableDiffer) diff(ctx context.Context, rowsToCompare int64, debug, onl
 				}
 				dr.ExtraRowsTargetDiffs = append(dr.ExtraRowsTargetDiffs, diffRow)
 			}
+			td.addRepair(repairDelete, nil, targetRow)
 			dr.ExtraRowsTarget++
 			advanceSource = false
 			continue
@@ -602,6 +648,7 @@ func (td *tableDiffer) diff(ctx context.Context, rowsToCompare int64, debug, onl
 				}
 				dr.MismatchedRowsDiffs = append(dr.MismatchedRowsDiffs, &DiffMismatch{Source: sourceDiffRow, Target: targetDiffRow})
 			}
+			td.addRepair(repairReplace, sourceRow, targetRow)
 			dr.MismatchedRows++
 		default:
 			dr.MatchingRows++
@@ -618,6 +665,57 @@ func (td *tableDiffer) diff(ctx context.Context, rowsToCompare int64, debug, onl
 	}
 }
 
+// getReport returns the report saved for the table, and whether a mismatch
+// was already flagged.
+func (td *tableDiffer) getReport(dbClient binlogplayer.DBClient) (*DiffReport, bool, error) {
+	// We need to continue were we left off when appropriate. This can be an
+	// auto-retry on error, or a manual retry via the resume command.
+	// Otherwise the existing state will be empty and we start from scratch.
+	query := fmt.Sprintf(sqlGetVDiffTable, td.wd.ct.id, encodeString(td.table.Name))
+	cs, err := dbClient.ExecuteFetch(query, -1)
+	if err != nil {
+		return nil, false, err
+	}
+	if len(cs.Rows) == 0 {
+		return nil, false, fmt.Errorf("no state found for vdiff table %s for vdiff_id %d on tablet %v",
+			td.table.Name, td.wd.ct.id, td.wd.ct.vde.thisTablet.Alias)
+	} else if len(cs.Rows) > 1 {
+		return nil, false, fmt.Errorf("invalid state found for vdiff table %s (multiple records) for vdiff_id %d on tablet %v",
+			td.table.Name, td.wd.ct.id, td.wd.ct.vde.thisTablet.Alias)
+	}
+	curState := cs.Named().Row()
+	mismatch := curState.AsBool("mismatch", false)
+	dr := &DiffReport{}
+	if rpt := curState.AsBytes("report", []byte("{}")); json.Valid(rpt) {
+		if err = json.Unmarshal(rpt, dr); err != nil {
+			return nil, false, err
+		}
+	}
+	dr.TableName = td.table.Name
+	return dr, mismatch, nil
+}
+
+// isPastEndRow returns true if the diff is limited to a range of the table,
+// and the row comes after it.
+func (td *tableDiffer) isPastEndRow(row []sqltypes.Value) bool {
+	if td.endRow == nil || row == nil {
+		return false
+	}
+	c, err := td.compare(row, td.endRow, td.tablePlan.comparePKs, false)
+	return err == nil && c > 0
+}
+
+// isDuplicateRow returns true if the table has no primary key and the row is
+// identical to the previous one. Such rows can't be told apart, so they are
+// matched one to one in order.
+func (td *tableDiffer) isDuplicateRow(prevRow, row []sqltypes.Value) bool {
+	if !td.tablePlan.fullRowKey || prevRow == nil || row == nil {
+		return false
+	}
+	c, err := td.compare(prevRow, row, td.tablePlan.comparePKs, false)
+	return err == nil && c == 0
+}
+
 func (td *tableDiffer) compare(sourceRow, targetRow []sqltypes.Value, cols []compareColInfo, compareOnlyNonPKs bool) (int, error) {
 	for _, col := range cols {
 		if col.isPK && compareOnlyNonPKs {
@@ -712,6 +810,12 @@ func updateTableMismatch(dbClient binlogplayer.DBClient, vdiffID int64, table st
 }
 
 func (td *tableDiffer) lastPKFromRow(row []sqltypes.Value) ([]byte, error) {
+	return prototext.Marshal(td.lastPKResultFromRow(row))
+}
+
+// lastPKResultFromRow returns the pk values of the row, in the form that
+// VStreamRows expects for its lastpk.
+func (td *tableDiffer) lastPKResultFromRow(row []sqltypes.Value) *querypb.QueryResult {
 	pkColCnt := len(td.tablePlan.pkCols)
 	pkFields := make([]*querypb.Field, pkColCnt)
 	pkVals := make([]sqltypes.Value, pkColCnt)
@@ -719,11 +823,10 @@ func (td *tableDiffer) lastPKFromRow(row []sqltypes.Value) ([]byte, error) {
 		pkFields[i] = td.tablePlan.table.Fields[colIndex]
 		pkVals[i] = row[colIndex]
 	}
-	buf, err := prototext.Marshal(&querypb.QueryResult{
+	return &querypb.QueryResult{
 		Fields: pkFields,
 		Rows:   []*querypb.Row{sqltypes.RowToProto3(pkVals)},
-	})
-	return buf, err
+	}
 }
 
 // If SourceTimeZone is defined in the BinlogSource (_vt.vreplication.source), the
@@ -50,6 +50,9 @@ type tablePlan struct {
 	comparePKs []compareColInfo
 	// pkCols has the indices of PK cols in the select list
 	pkCols []int
+	// fullRowKey is set if the table has no primary key. Its rows are
+	// ordered and matched on all of the selected columns.
+	fullRowKey bool
 
 	// selectPks is the list of pk columns as they appear in the select clause for the diff.
 	selectPks  []int
@@ -179,7 +182,14 @@ func (td *tableDiffer) buildTablePlan(dbClient binlogplayer.DBClient, dbName str
 // findPKs identifies PKs and removes them from the columns to do data comparison.
 func (tp *tablePlan) findPKs(dbClient binlogplayer.DBClient, targetSelect *sqlparser.Select) error {
 	var orderby sqlparser.OrderBy
-	for _, pk := range tp.table.PrimaryKeyColumns {
+	pkColumns := tp.table.PrimaryKeyColumns
+	if len(pkColumns) == 0 {
+		tp.fullRowKey = true
+		for _, col := range tp.compareCols {
+			pkColumns = append(pkColumns, col.colName)
+		}
+	}
+	for _, pk := range pkColumns {
 		found := false
 		for i, selExpr := range targetSelect.SelectExprs {
 			expr := selExpr.(*sqlparser.AliasedExpr).Expr
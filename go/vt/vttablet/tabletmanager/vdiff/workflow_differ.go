@@ -144,11 +144,32 @@ func (wd *workflowDiffer) diffTable(ctx context.Context, dbClient binlogplayer.D
 	if err := td.updateTableState(ctx, dbClient, StartedState); err != nil {
 		return err
 	}
-	if err := td.initialize(ctx); err != nil {
-		return err
+	var dr *DiffReport
+	var err error
+	checksum := wd.opts.CoreOptions.Checksum
+	if checksum {
+		if reason := td.tablePlan.checksumUnsupportedReason(); reason != "" {
+			insertVDiffLog(ctx, dbClient, wd.ct.id, fmt.Sprintf("Comparing all the rows of table %s instead of checksums: %s", td.table.Name, reason))
+			checksum = false
+		}
+	}
+	if checksum {
+		dr, err = td.diffChecksums(ctx, dbClient, wd.opts.CoreOptions.MaxRows, wd.opts.ReportOptions.DebugQuery, wd.opts.ReportOptions.OnlyPks, wd.opts.CoreOptions.MaxExtraRowsToCompare)
+	} else {
+		if err := td.initialize(ctx); err != nil {
+			return err
+		}
+		log.Infof("Table initialization done on table %s for vdiff %s", td.table.Name, wd.ct.uuid)
+		dr, err = td.diff(ctx, wd.opts.CoreOptions.MaxRows, wd.opts.ReportOptions.DebugQuery, wd.opts.ReportOptions.OnlyPks, wd.opts.CoreOptions.MaxExtraRowsToCompare)
+	}
+	// The repair plan of the rows compared so far is saved even on error, as
+	// a retry carries on from the last row compared.
+	if repairErr := td.saveRepairs(ctx, dbClient); repairErr != nil {
+		log.Errorf("Encountered an error saving the repair plan of table %s for vdiff %s: %v", td.table.Name, wd.ct.uuid, repairErr)
+		if err == nil {
+			return repairErr
+		}
 	}
-	log.Infof("Table initialization done on table %s for vdiff %s", td.table.Name, wd.ct.uuid)
-	dr, err := td.diff(ctx, wd.opts.CoreOptions.MaxRows, wd.opts.ReportOptions.DebugQuery, wd.opts.ReportOptions.OnlyPks, wd.opts.CoreOptions.MaxExtraRowsToCompare)
 	if err != nil {
 		log.Errorf("Encountered an error diffing table %s for vdiff %s: %v", td.table.Name, wd.ct.uuid, err)
 		return err
@@ -165,6 +186,9 @@ func (wd *workflowDiffer) diffTable(ctx context.Context, dbClient binlogplayer.D
 		if err := updateTableMismatch(dbClient, wd.ct.id, td.table.Name); err != nil {
 			return err
 		}
+		if reason := td.repairUnsupportedReason(); reason != "" {
+			insertVDiffLog(ctx, dbClient, wd.ct.id, fmt.Sprintf("No repair plan for table %s: %s", td.table.Name, reason))
+		}
 	}
 
 	log.Infof("Completed reconciliation on table %s for vdiff %s with updated report: %+v", td.table.Name, wd.ct.uuid, dr)
@@ -68,6 +68,9 @@ type controller struct {
 	sourceTablet atomic.Value
 
 	lastWorkflowError *vterrors.LastError
+
+	// schedule is the maintenance window and the rate limits of the stream.
+	schedule *streamSchedule
 }
 
 // newController creates a new controller. Unless a stream is explicitly 'Stopped',
@@ -111,6 +114,9 @@ func newController(ctx context.Context, params map[string]string, dbClientFactor
 		return nil, err
 	}
 	ct.stopPos = params["stop_pos"]
+	if ct.schedule, err = newStreamSchedule(ct.source.Schedule); err != nil {
+		return nil, err
+	}
 
 	if ct.source.GetExternalMysql() == "" {
 		// tabletPicker
@@ -152,7 +158,16 @@ func (ct *controller) run(ctx context.Context) {
 	}()
 
 	for {
-		err := ct.runBlp(ctx)
+		windowCtx, cancel, err := ct.schedule.waitForWindow(ctx, false, ct.setPauseMessage)
+		if err != nil {
+			return
+		}
+		err = ct.runBlp(windowCtx)
+		cancel()
+		if windowClosed(ctx, windowCtx) {
+			log.Infof("stream %v: maintenance window %s closed, pausing", ct.id, ct.schedule)
+			continue
+		}
 		if err == nil {
 			return
 		}
@@ -264,6 +279,7 @@ func (ct *controller) runBlp(ctx context.Context) (err error) {
 		defer vsClient.Close(ctx)
 
 		vr := newVReplicator(ct.id, ct.source, vsClient, ct.blpStats, dbClient, ct.mysqld, ct.vre)
+		vr.schedule = ct.schedule
 		err = vr.Replicate(ctx)
 		ct.lastWorkflowError.Record(err)
 		// If this is a mysql error that we know needs manual intervention OR
@@ -292,6 +308,18 @@ func (ct *controller) setMessage(dbClient binlogplayer.DBClient, message string)
 	}
 	return nil
 }
+
+// setPauseMessage saves the reason the stream is paused, over a connection
+// of its own since the stream holds none while it's paused.
+func (ct *controller) setPauseMessage(message string) error {
+	dbClient := ct.dbClientFactory()
+	if err := dbClient.Connect(); err != nil {
+		return vterrors.Wrap(err, "can't connect to database")
+	}
+	defer dbClient.Close()
+	return ct.setMessage(dbClient, message)
+}
+
 func (ct *controller) Stop() {
 	ct.cancel()
 	<-ct.done
@@ -290,6 +290,13 @@ func (vre *Engine) IsOpen() bool {
 	return vre.isOpen
 }
 
+// ThrottleCheckOKOrWait checks the tablet throttler on behalf of appName, for
+// other tablet components that write to the primary. If the throttler is not
+// satisfied, it briefly sleeps and returns false.
+func (vre *Engine) ThrottleCheckOKOrWait(ctx context.Context, appName throttlerapp.Name) bool {
+	return vre.throttlerClient.ThrottleCheckOKOrWaitAppName(ctx, appName)
+}
+
 // Close closes the Engine service.
 func (vre *Engine) Close() {
 	vre.mu.Lock()
@@ -487,6 +494,7 @@ func (vre *Engine) exec(query string, runAsAdmin bool) (*sqltypes.Result, error)
 				ct.Stop()
 				delete(vre.controllers, id)
 			}
+			removeDiskRelayLog(vre.dbName, id)
 			if err := insertLogWithParams(vdbc, LogStreamDelete, id, nil); err != nil {
 				return nil, err
 			}
@@ -718,6 +726,7 @@ func (vre *Engine) transitionJournal(je *journalEvent) {
 		ks := participants[id]
 		id := je.participants[ks]
 		delete(vre.controllers, id)
+		removeDiskRelayLog(vre.dbName, id)
 	}
 
 	for _, id := range newids {
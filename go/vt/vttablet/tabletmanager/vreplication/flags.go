@@ -40,6 +40,11 @@ var (
 
 	vreplicationStoreCompressedGTID   = false
 	vreplicationParallelInsertWorkers = 1
+	vreplicationParallelApplyWorkers  = 1
+	vreplicationParallelTableCopies   = 1
+
+	relayLogDir         string
+	relayLogMaxDiskSize int64 = 1 << 30
 )
 
 func registerVReplicationFlags(fs *pflag.FlagSet) {
@@ -70,6 +75,10 @@ func registerVReplicationFlags(fs *pflag.FlagSet) {
 	fs.Duration("vreplication_healthcheck_timeout", 1*time.Minute, "healthcheck retry delay")
 
 	fs.IntVar(&vreplicationParallelInsertWorkers, "vreplication-parallel-insert-workers", vreplicationParallelInsertWorkers, "Number of parallel insertion workers to use during copy phase. Set <= 1 to disable parallelism, or > 1 to enable concurrent insertion during copy phase.")
+	fs.IntVar(&vreplicationParallelApplyWorkers, "vreplication-parallel-apply-workers", vreplicationParallelApplyWorkers, "Number of parallel workers to apply transactions with during the replication phase. Transactions that change different rows are applied concurrently, and committed in the source order. Set <= 1 to disable parallelism.")
+	fs.IntVar(&vreplicationParallelTableCopies, "vreplication-parallel-table-copies", vreplicationParallelTableCopies, "Number of tables to copy concurrently during the copy phase, each from its own snapshot of the source. Set <= 1 to copy one table at a time.")
+	fs.StringVar(&relayLogDir, "vreplication-relay-log-dir", relayLogDir, "Directory to spill the events received by VReplication streams to, so that the source streams aren't held back while the target applies them. The events are kept across restarts of the streams. Empty disables the disk relay log.")
+	fs.Int64Var(&relayLogMaxDiskSize, "vreplication-relay-log-max-disk-size", relayLogMaxDiskSize, "Maximum size (in bytes) of the disk relay log of each VReplication stream.")
 }
 
 func init() {
@@ -317,11 +317,19 @@ var vstreamRowsHook func(ctx context.Context)
 // vstreamRowsSendHook allows you to do work just before VStreamRows calls send.
 var vstreamRowsSendHook func(ctx context.Context)
 
+// vstreamRowsQueryHook allows you to do work just before calling VStreamRows
+// for a query, and vstreamRowsQuerySendHook just before it calls send. They
+// are used when several tables are copied concurrently.
+var vstreamRowsQueryHook, vstreamRowsQuerySendHook func(ctx context.Context, query string)
+
 // VStreamRows directly calls into the pre-initialized engine.
 func (ftc *fakeTabletConn) VStreamRows(ctx context.Context, request *binlogdatapb.VStreamRowsRequest, send func(*binlogdatapb.VStreamRowsResponse) error) error {
 	if vstreamRowsHook != nil {
 		vstreamRowsHook(ctx)
 	}
+	if vstreamRowsQueryHook != nil {
+		vstreamRowsQueryHook(ctx, request.Query)
+	}
 	var row []sqltypes.Value
 	if request.Lastpk != nil {
 		r := sqltypes.Proto3ToResult(request.Lastpk)
@@ -334,6 +342,9 @@ func (ftc *fakeTabletConn) VStreamRows(ctx context.Context, request *binlogdatap
 		if vstreamRowsSendHook != nil {
 			vstreamRowsSendHook(ctx)
 		}
+		if vstreamRowsQuerySendHook != nil {
+			vstreamRowsQuerySendHook(ctx, request.Query)
+		}
 		return send(rows)
 	})
 }
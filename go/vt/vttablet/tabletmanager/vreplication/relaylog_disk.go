@@ -0,0 +1,712 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vreplication
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+
+	"vitess.io/vitess/go/mysql"
+	"vitess.io/vitess/go/vt/binlog/binlogplayer"
+	"vitess.io/vitess/go/vt/log"
+
+	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
+)
+
+const (
+	relayLogFilterFile    = "filter"
+	relayLogSegmentSuffix = ".relay"
+	// relayLogRecordHeaderSize is the size of the length and the checksum
+	// that precede every record.
+	relayLogRecordHeaderSize = 8
+)
+
+var relayLogCRCTable = crc32.MakeTable(crc32.Castagnoli)
+
+// eventRelay buffers the events received from the source until the
+// vplayer applies them.
+type eventRelay interface {
+	// Send adds events received from the source.
+	Send(events []*binlogdatapb.VEvent) error
+	// Fetch returns the buffered events. It returns no events if none
+	// arrived for idleTimeout.
+	Fetch() ([][]*binlogdatapb.VEvent, error)
+}
+
+// diskRelayLog is a relay log that persists the events received from the
+// source in local files, so that the source stream isn't held back by a
+// slow target as long as the files stay under maxDiskSize.
+//
+// The events are written to segment files. Each segment starts with a
+// header record that holds the position the segment starts at, and the
+// last field events of the tables streamed so far. The rest of the records
+// are the batches of events, as they were received. A new segment is only
+// started between transactions, and a segment is deleted once all of its
+// events were fetched and the next fetch shows that they were applied.
+//
+// The relay log outlives the vplayer that writes it. When a vplayer opens
+// the relay log of its stream again, the events that follow its position
+// are replayed from the files, and the source stream resumes after the
+// last transaction in the files.
+type diskRelayLog struct {
+	ctx         context.Context
+	dir         string
+	maxItems    int
+	maxSize     int
+	maxDiskSize int64
+	segmentSize int64
+
+	// mu protects the variables below. canAccept and hasItems follow the
+	// same rules as in relayLog.
+	mu        sync.Mutex
+	canAccept sync.Cond
+	hasItems  sync.Cond
+	timedout  bool
+	closed    bool
+	// segments are the sequence numbers of the segment files, oldest first.
+	segments []int
+	diskSize int64
+	// unread is the number of records that were written and not read yet.
+	unread int
+
+	// The following are only used by Send.
+	writer     *os.File
+	writerSize int64
+	inTxn      bool
+	lastPos    string
+	fields     map[string]*binlogdatapb.VEvent
+
+	// The following are only used by Fetch.
+	reader        *bufio.Reader
+	readerFile    *os.File
+	readerSegment int
+	// pending are the events to return before the ones read from the
+	// files, when the relay log is replayed.
+	pending [][]*binlogdatapb.VEvent
+}
+
+// openDiskRelayLog opens the relay log in dir for a vplayer that streams
+// with filter from pos. It returns the position the source stream must
+// start at, which is after the events already in the relay log.
+func openDiskRelayLog(ctx context.Context, dir string, filter *binlogdatapb.Filter, pos mysql.Position, maxItems, maxSize int, maxDiskSize int64) (*diskRelayLog, mysql.Position, error) {
+	rl := &diskRelayLog{
+		ctx:         ctx,
+		dir:         dir,
+		maxItems:    maxItems,
+		maxSize:     maxSize,
+		maxDiskSize: maxDiskSize,
+		segmentSize: maxDiskSize / 4,
+		fields:      make(map[string]*binlogdatapb.VEvent),
+	}
+	rl.canAccept.L = &rl.mu
+	rl.hasItems.L = &rl.mu
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, mysql.Position{}, err
+	}
+
+	startPos, err := rl.recover(filter, pos)
+	if err != nil {
+		log.Warningf("Discarding the relay log in %s: %v", dir, err)
+		if err := rl.reset(filter, pos); err != nil {
+			rl.Close()
+			return nil, mysql.Position{}, err
+		}
+		startPos = pos
+	}
+
+	go func() {
+		<-ctx.Done()
+		rl.mu.Lock()
+		defer rl.mu.Unlock()
+		rl.canAccept.Broadcast()
+		rl.hasItems.Broadcast()
+	}()
+	return rl, startPos, nil
+}
+
+// removeDiskRelayLog removes the relay log of a stream that was deleted.
+func removeDiskRelayLog(dbName string, id int32) {
+	if relayLogDir == "" {
+		return
+	}
+	if err := os.RemoveAll(diskRelayLogDir(dbName, id)); err != nil {
+		log.Warningf("Failed to remove the relay log of stream %d: %v", id, err)
+	}
+}
+
+func diskRelayLogDir(dbName string, id int32) string {
+	return filepath.Join(relayLogDir, fmt.Sprintf("%s-%d", dbName, id))
+}
+
+// Send writes events to the relay log.
+func (rl *diskRelayLog) Send(events []*binlogdatapb.VEvent) error {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	if err := rl.checkDone(); err != nil {
+		return err
+	}
+	// The limit is only enforced while there are events to apply, because
+	// the files can't shrink until the vplayer fetches them.
+	for rl.diskSize >= rl.maxDiskSize && rl.unread > 0 {
+		rl.canAccept.Wait()
+		if err := rl.checkDone(); err != nil {
+			return err
+		}
+	}
+	n, err := writeRelayLogRecord(rl.writer, events)
+	if err != nil {
+		return err
+	}
+	rl.writerSize += n
+	rl.diskSize += n
+	rl.unread++
+	rl.track(events)
+	rl.hasItems.Broadcast()
+
+	if !rl.inTxn && rl.writerSize >= rl.segmentSize {
+		return rl.newSegment()
+	}
+	return nil
+}
+
+// Fetch returns the events in the relay log, up to maxItems batches or
+// maxSize bytes of rows.
+func (rl *diskRelayLog) Fetch() ([][]*binlogdatapb.VEvent, error) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	if err := rl.checkDone(); err != nil {
+		return nil, err
+	}
+	// The events fetched last time were applied, so the segments that were
+	// read completely can go.
+	if err := rl.purge(); err != nil {
+		return nil, err
+	}
+	if len(rl.pending) > 0 {
+		items := rl.pending
+		rl.pending = nil
+		return items, nil
+	}
+	cancelTimer := rl.startTimer()
+	defer cancelTimer()
+	for rl.unread == 0 && !rl.timedout {
+		rl.hasItems.Wait()
+		if err := rl.checkDone(); err != nil {
+			return nil, err
+		}
+	}
+	rl.timedout = false
+
+	var items [][]*binlogdatapb.VEvent
+	size := 0
+	for rl.unread > 0 && len(items) < rl.maxItems && size <= rl.maxSize {
+		events, err := rl.read()
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, events)
+		size += eventsSize(events)
+		rl.unread--
+	}
+	rl.canAccept.Broadcast()
+	return items, nil
+}
+
+// Close closes the files of the relay log. The files are kept, so that the
+// next vplayer of the stream can use them.
+func (rl *diskRelayLog) Close() {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	rl.closed = true
+	if rl.writer != nil {
+		rl.writer.Close()
+	}
+	if rl.readerFile != nil {
+		rl.readerFile.Close()
+	}
+	rl.canAccept.Broadcast()
+	rl.hasItems.Broadcast()
+}
+
+func (rl *diskRelayLog) checkDone() error {
+	if rl.closed {
+		return io.EOF
+	}
+	select {
+	case <-rl.ctx.Done():
+		return io.EOF
+	default:
+	}
+	return nil
+}
+
+func (rl *diskRelayLog) startTimer() (cancel func()) {
+	timer := time.NewTimer(idleTimeout)
+	timerDone := make(chan struct{})
+	go func() {
+		select {
+		case <-timer.C:
+			rl.mu.Lock()
+			defer rl.mu.Unlock()
+			rl.timedout = true
+			rl.hasItems.Broadcast()
+		case <-timerDone:
+		}
+	}()
+	return func() {
+		timer.Stop()
+		close(timerDone)
+	}
+}
+
+// track keeps the state that the header of the next segment needs: whether
+// a transaction is in progress, the position after the last transaction,
+// and the last field event of every table.
+func (rl *diskRelayLog) track(events []*binlogdatapb.VEvent) {
+	for _, event := range events {
+		switch event.Type {
+		case binlogdatapb.VEventType_BEGIN:
+			rl.inTxn = true
+		case binlogdatapb.VEventType_COMMIT:
+			rl.inTxn = false
+		case binlogdatapb.VEventType_GTID:
+			rl.lastPos = event.Gtid
+		case binlogdatapb.VEventType_FIELD:
+			rl.fields[event.FieldEvent.TableName] = event
+		}
+	}
+}
+
+// newSegment starts a new segment file for Send.
+func (rl *diskRelayLog) newSegment() error {
+	seq := 1
+	if len(rl.segments) > 0 {
+		seq = rl.segments[len(rl.segments)-1] + 1
+	}
+	f, err := os.OpenFile(rl.segmentPath(seq), os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	header := []*binlogdatapb.VEvent{{Type: binlogdatapb.VEventType_GTID, Gtid: rl.lastPos}}
+	header = append(header, sortedFieldEvents(rl.fields)...)
+	n, err := writeRelayLogRecord(f, header)
+	if err != nil {
+		f.Close()
+		return err
+	}
+	if rl.writer != nil {
+		rl.writer.Close()
+	}
+	rl.writer = f
+	rl.writerSize = n
+	rl.diskSize += n
+	rl.segments = append(rl.segments, seq)
+	return nil
+}
+
+// read reads the next record for Fetch, moving on to the next segment at
+// the end of the current one.
+func (rl *diskRelayLog) read() ([]*binlogdatapb.VEvent, error) {
+	for {
+		if rl.reader != nil {
+			events, _, err := readRelayLogRecord(rl.reader)
+			if err != io.EOF {
+				return events, err
+			}
+		}
+		next := -1
+		for _, seq := range rl.segments {
+			if seq > rl.readerSegment {
+				next = seq
+				break
+			}
+		}
+		if next < 0 {
+			return nil, fmt.Errorf("relay log in %s is missing records", rl.dir)
+		}
+		if err := rl.openReader(next, 0); err != nil {
+			return nil, err
+		}
+		// Skip the header.
+		if _, _, err := readRelayLogRecord(rl.reader); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (rl *diskRelayLog) openReader(seq int, offset int64) error {
+	f, err := os.Open(rl.segmentPath(seq))
+	if err != nil {
+		return err
+	}
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		f.Close()
+		return err
+	}
+	if rl.readerFile != nil {
+		rl.readerFile.Close()
+	}
+	rl.readerFile = f
+	rl.reader = bufio.NewReader(f)
+	rl.readerSegment = seq
+	return nil
+}
+
+// purge deletes the segments before the one that is being read.
+func (rl *diskRelayLog) purge() error {
+	for len(rl.segments) > 0 && rl.segments[0] < rl.readerSegment {
+		path := rl.segmentPath(rl.segments[0])
+		fi, err := os.Stat(path)
+		if err != nil {
+			return err
+		}
+		if err := os.Remove(path); err != nil {
+			return err
+		}
+		rl.diskSize -= fi.Size()
+		rl.segments = rl.segments[1:]
+		rl.canAccept.Broadcast()
+	}
+	return nil
+}
+
+// relayLogMark is a place in the relay log files.
+type relayLogMark struct {
+	segment int
+	offset  int64
+	// unread is the number of records between the place the vplayer
+	// resumes at and the mark.
+	unread int
+	pos    string
+	fields map[string]*binlogdatapb.VEvent
+}
+
+func (m *relayLogMark) after(other *relayLogMark) bool {
+	return m.segment > other.segment || (m.segment == other.segment && m.offset > other.offset)
+}
+
+// recover prepares the existing relay log for a vplayer at pos. It fails if
+// the relay log doesn't continue from pos.
+func (rl *diskRelayLog) recover(filter *binlogdatapb.Filter, pos mysql.Position) (mysql.Position, error) {
+	data, err := os.ReadFile(filepath.Join(rl.dir, relayLogFilterFile))
+	if err != nil {
+		return mysql.Position{}, err
+	}
+	savedFilter := &binlogdatapb.Filter{}
+	if err := savedFilter.UnmarshalVT(data); err != nil {
+		return mysql.Position{}, err
+	}
+	if !proto.Equal(savedFilter, filter) {
+		return mysql.Position{}, errors.New("the filter of the stream changed")
+	}
+	segments, err := rl.listSegments()
+	if err != nil {
+		return mysql.Position{}, err
+	}
+
+	var (
+		// resume is where the vplayer resumes, and end is the end of the
+		// last complete transaction.
+		resume, end *relayLogMark
+		// pending are the events to replay before the records that follow
+		// resume.
+		pending [][]*binlogdatapb.VEvent
+		unread  int
+		lastPos string
+		inTxn   bool
+		fields  map[string]*binlogdatapb.VEvent
+		// skipping is set when the next event ends the transaction at pos,
+		// which was already applied.
+		skipping bool
+	)
+	mark := func(segment int, offset int64) *relayLogMark {
+		m := &relayLogMark{segment: segment, offset: offset, unread: unread, pos: lastPos, fields: make(map[string]*binlogdatapb.VEvent, len(fields))}
+		for table, event := range fields {
+			m.fields[table] = event
+		}
+		return m
+	}
+scan:
+	for _, seq := range segments {
+		f, err := os.Open(rl.segmentPath(seq))
+		if err != nil {
+			return mysql.Position{}, err
+		}
+		r := bufio.NewReader(f)
+		header, offset, err := readRelayLogRecord(r)
+		switch {
+		case err != nil || len(header) == 0 || header[0].Type != binlogdatapb.VEventType_GTID:
+			err = fmt.Errorf("invalid header in segment %d", seq)
+		case end != nil && (end.segment != seq-1 || header[0].Gtid != end.pos):
+			err = fmt.Errorf("segment %d doesn't continue the previous one", seq)
+		}
+		if err != nil {
+			f.Close()
+			if end == nil {
+				return mysql.Position{}, err
+			}
+			log.Warningf("Truncating the relay log in %s: %v", rl.dir, err)
+			break
+		}
+		lastPos = header[0].Gtid
+		inTxn = false
+		fields = make(map[string]*binlogdatapb.VEvent)
+		for _, event := range header[1:] {
+			fields[event.FieldEvent.TableName] = event
+		}
+		if resume == nil && !skipping && positionEquals(lastPos, pos) {
+			resume = mark(seq, offset)
+			if len(fields) > 0 {
+				pending = append(pending, sortedFieldEvents(fields))
+			}
+		}
+		end = mark(seq, offset)
+
+		for {
+			events, n, err := readRelayLogRecord(r)
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				// A torn record at the end of the files is expected after
+				// a crash.
+				log.Warningf("Truncating the relay log in %s at segment %d: %v", rl.dir, seq, err)
+				f.Close()
+				break scan
+			}
+			offset += n
+			if resume != nil {
+				unread++
+			}
+			for i, event := range events {
+				switch event.Type {
+				case binlogdatapb.VEventType_BEGIN:
+					inTxn = true
+				case binlogdatapb.VEventType_COMMIT:
+					inTxn = false
+				case binlogdatapb.VEventType_GTID:
+					lastPos = event.Gtid
+				case binlogdatapb.VEventType_FIELD:
+					fields[event.FieldEvent.TableName] = event
+				}
+				switch {
+				case resume != nil:
+				case skipping:
+					skipping = false
+					resume = mark(seq, offset)
+					replay := append(sortedFieldEvents(fields), events[i+1:]...)
+					if len(replay) > 0 {
+						pending = append(pending, replay)
+					}
+				case event.Type == binlogdatapb.VEventType_GTID && positionEquals(event.Gtid, pos):
+					skipping = true
+				}
+			}
+			if !inTxn {
+				end = mark(seq, offset)
+			}
+		}
+		f.Close()
+	}
+	if resume == nil || resume.after(end) {
+		return mysql.Position{}, fmt.Errorf("position %v is not in the relay log", pos)
+	}
+	startPos, err := binlogplayer.DecodePosition(end.pos)
+	if err != nil {
+		return mysql.Position{}, err
+	}
+
+	// Drop what follows the last complete transaction, because the source
+	// stream sends it again, and what precedes the place the vplayer
+	// resumes at, because it was applied.
+	for _, seq := range segments {
+		path := rl.segmentPath(seq)
+		if seq < resume.segment || seq > end.segment {
+			if err := os.Remove(path); err != nil {
+				return mysql.Position{}, err
+			}
+			continue
+		}
+		if seq == end.segment {
+			if err := os.Truncate(path, end.offset); err != nil {
+				return mysql.Position{}, err
+			}
+		}
+		fi, err := os.Stat(path)
+		if err != nil {
+			return mysql.Position{}, err
+		}
+		rl.segments = append(rl.segments, seq)
+		rl.diskSize += fi.Size()
+	}
+	if rl.writer, err = os.OpenFile(rl.segmentPath(end.segment), os.O_APPEND|os.O_WRONLY, 0o644); err != nil {
+		return mysql.Position{}, err
+	}
+	rl.writerSize = end.offset
+	rl.lastPos = end.pos
+	rl.fields = end.fields
+	if err := rl.openReader(resume.segment, resume.offset); err != nil {
+		return mysql.Position{}, err
+	}
+	rl.unread = end.unread
+	rl.pending = pending
+	log.Infof("Replaying %d batches of events from the relay log in %s, the source stream resumes at %v", rl.unread+len(rl.pending), rl.dir, startPos)
+	return startPos, nil
+}
+
+// reset discards the files of the relay log, and starts a new one at pos.
+func (rl *diskRelayLog) reset(filter *binlogdatapb.Filter, pos mysql.Position) error {
+	if rl.writer != nil {
+		rl.writer.Close()
+		rl.writer = nil
+	}
+	if rl.readerFile != nil {
+		rl.readerFile.Close()
+		rl.readerFile = nil
+		rl.reader = nil
+	}
+	segments, err := rl.listSegments()
+	if err != nil {
+		return err
+	}
+	for _, seq := range segments {
+		if err := os.Remove(rl.segmentPath(seq)); err != nil {
+			return err
+		}
+	}
+	data, err := filter.MarshalVT()
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(rl.dir, relayLogFilterFile), data, 0o644); err != nil {
+		return err
+	}
+	rl.segments = nil
+	rl.diskSize = 0
+	rl.unread = 0
+	rl.pending = nil
+	rl.inTxn = false
+	rl.lastPos = mysql.EncodePosition(pos)
+	rl.fields = make(map[string]*binlogdatapb.VEvent)
+	if err := rl.newSegment(); err != nil {
+		return err
+	}
+	return rl.openReader(rl.segments[0], rl.writerSize)
+}
+
+func (rl *diskRelayLog) listSegments() ([]int, error) {
+	entries, err := os.ReadDir(rl.dir)
+	if err != nil {
+		return nil, err
+	}
+	var segments []int
+	for _, entry := range entries {
+		name, ok := strings.CutSuffix(entry.Name(), relayLogSegmentSuffix)
+		if !ok {
+			continue
+		}
+		seq, err := strconv.Atoi(name)
+		if err != nil {
+			continue
+		}
+		segments = append(segments, seq)
+	}
+	sort.Ints(segments)
+	return segments, nil
+}
+
+func (rl *diskRelayLog) segmentPath(seq int) string {
+	return filepath.Join(rl.dir, fmt.Sprintf("%08d%s", seq, relayLogSegmentSuffix))
+}
+
+// sortedFieldEvents returns the field events of the tables, in the order
+// of the table names.
+func sortedFieldEvents(fields map[string]*binlogdatapb.VEvent) []*binlogdatapb.VEvent {
+	tables := make([]string, 0, len(fields))
+	for table := range fields {
+		tables = append(tables, table)
+	}
+	sort.Strings(tables)
+	events := make([]*binlogdatapb.VEvent, 0, len(tables))
+	for _, table := range tables {
+		events = append(events, fields[table])
+	}
+	return events
+}
+
+// positionEquals returns true if the encoded position is pos.
+func positionEquals(encoded string, pos mysql.Position) bool {
+	p, err := binlogplayer.DecodePosition(encoded)
+	return err == nil && p.Equal(pos)
+}
+
+// writeRelayLogRecord writes events as one record, and returns its size.
+func writeRelayLogRecord(w io.Writer, events []*binlogdatapb.VEvent) (int64, error) {
+	data, err := (&binlogdatapb.VStreamResponse{Events: events}).MarshalVT()
+	if err != nil {
+		return 0, err
+	}
+	buf := make([]byte, relayLogRecordHeaderSize+len(data))
+	binary.LittleEndian.PutUint32(buf[0:4], uint32(len(data)))
+	binary.LittleEndian.PutUint32(buf[4:8], crc32.Checksum(data, relayLogCRCTable))
+	copy(buf[relayLogRecordHeaderSize:], data)
+	if _, err := w.Write(buf); err != nil {
+		return 0, err
+	}
+	return int64(len(buf)), nil
+}
+
+// readRelayLogRecord reads the next record, and returns its events and its
+// size. It returns io.EOF if there are no more records.
+func readRelayLogRecord(r io.Reader) ([]*binlogdatapb.VEvent, int64, error) {
+	var header [relayLogRecordHeaderSize]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		if err == io.ErrUnexpectedEOF {
+			return nil, 0, errors.New("truncated record header")
+		}
+		return nil, 0, err
+	}
+	data := make([]byte, binary.LittleEndian.Uint32(header[0:4]))
+	if _, err := io.ReadFull(r, data); err != nil {
+		return nil, 0, fmt.Errorf("truncated record: %v", err)
+	}
+	if crc32.Checksum(data, relayLogCRCTable) != binary.LittleEndian.Uint32(header[4:8]) {
+		return nil, 0, errors.New("record checksum mismatch")
+	}
+	resp := &binlogdatapb.VStreamResponse{}
+	if err := resp.UnmarshalVT(data); err != nil {
+		return nil, 0, err
+	}
+	return resp.Events, int64(relayLogRecordHeaderSize + len(data)), nil
+}
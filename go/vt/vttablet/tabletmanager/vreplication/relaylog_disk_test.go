@@ -0,0 +1,185 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vreplication
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/mysql"
+
+	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
+	querypb "vitess.io/vitess/go/vt/proto/query"
+)
+
+func relayLogTestPos(t *testing.T, n int) mysql.Position {
+	pos, err := mysql.DecodePosition(relayLogTestGTID(n))
+	require.NoError(t, err)
+	return pos
+}
+
+func relayLogTestGTID(n int) string {
+	return fmt.Sprintf("MySQL56/00000000-0000-0000-0000-000000000001:1-%d", n)
+}
+
+func relayLogTestTxn(n int, withFields bool) []*binlogdatapb.VEvent {
+	events := []*binlogdatapb.VEvent{{Type: binlogdatapb.VEventType_BEGIN}}
+	if withFields {
+		events = append(events, &binlogdatapb.VEvent{
+			Type: binlogdatapb.VEventType_FIELD,
+			FieldEvent: &binlogdatapb.FieldEvent{
+				TableName: "t1",
+				Fields:    []*querypb.Field{{Name: "id", Type: querypb.Type_INT64}},
+			},
+		})
+	}
+	return append(events,
+		&binlogdatapb.VEvent{
+			Type: binlogdatapb.VEventType_ROW,
+			RowEvent: &binlogdatapb.RowEvent{
+				TableName:  "t1",
+				RowChanges: []*binlogdatapb.RowChange{{After: &querypb.Row{Lengths: []int64{1}, Values: []byte(fmt.Sprint(n % 10))}}},
+			},
+		},
+		&binlogdatapb.VEvent{Type: binlogdatapb.VEventType_GTID, Gtid: relayLogTestGTID(n)},
+		&binlogdatapb.VEvent{Type: binlogdatapb.VEventType_COMMIT},
+	)
+}
+
+func TestDiskRelayLogResume(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	dir := t.TempDir()
+	filter := &binlogdatapb.Filter{Rules: []*binlogdatapb.Rule{{Match: "t1"}}}
+
+	rl, startPos, err := openDiskRelayLog(ctx, dir, filter, relayLogTestPos(t, 1), 100, 10000, 1<<20)
+	require.NoError(t, err)
+	assert.True(t, startPos.Equal(relayLogTestPos(t, 1)))
+	for n := 2; n <= 4; n++ {
+		require.NoError(t, rl.Send(relayLogTestTxn(n, n == 2)))
+	}
+	items, err := rl.Fetch()
+	require.NoError(t, err)
+	require.Len(t, items, 3)
+	assert.Equal(t, relayLogTestGTID(4), items[2][2].Gtid)
+	rl.Close()
+
+	// Only the first transaction was applied: the others are replayed from
+	// the relay log, after the fields they need.
+	rl, startPos, err = openDiskRelayLog(ctx, dir, filter, relayLogTestPos(t, 2), 100, 10000, 1<<20)
+	require.NoError(t, err)
+	assert.True(t, startPos.Equal(relayLogTestPos(t, 4)), startPos.String())
+	items, err = rl.Fetch()
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	require.Len(t, items[0], 1)
+	assert.Equal(t, binlogdatapb.VEventType_FIELD, items[0][0].Type)
+	items, err = rl.Fetch()
+	require.NoError(t, err)
+	require.Len(t, items, 2)
+	assert.Equal(t, relayLogTestGTID(3), items[0][2].Gtid)
+	assert.Equal(t, relayLogTestGTID(4), items[1][2].Gtid)
+
+	// The stream continues after the replayed transactions.
+	require.NoError(t, rl.Send(relayLogTestTxn(5, false)))
+	items, err = rl.Fetch()
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	assert.Equal(t, relayLogTestGTID(5), items[0][2].Gtid)
+	rl.Close()
+
+	// A position that isn't in the relay log discards it.
+	rl, startPos, err = openDiskRelayLog(ctx, dir, filter, relayLogTestPos(t, 10), 100, 10000, 1<<20)
+	require.NoError(t, err)
+	assert.True(t, startPos.Equal(relayLogTestPos(t, 10)))
+	assert.Zero(t, rl.unread)
+	assert.Empty(t, rl.pending)
+	rl.Close()
+}
+
+func TestDiskRelayLogTruncate(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	dir := t.TempDir()
+	filter := &binlogdatapb.Filter{Rules: []*binlogdatapb.Rule{{Match: "t1"}}}
+
+	rl, _, err := openDiskRelayLog(ctx, dir, filter, relayLogTestPos(t, 1), 100, 10000, 1<<20)
+	require.NoError(t, err)
+	require.NoError(t, rl.Send(relayLogTestTxn(2, true)))
+	// A transaction that was cut short, followed by a torn record.
+	require.NoError(t, rl.Send(relayLogTestTxn(3, false)[:2]))
+	path := rl.segmentPath(rl.segments[len(rl.segments)-1])
+	rl.Close()
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o644)
+	require.NoError(t, err)
+	_, err = f.Write([]byte{1, 2, 3})
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	rl, startPos, err := openDiskRelayLog(ctx, dir, filter, relayLogTestPos(t, 1), 100, 10000, 1<<20)
+	require.NoError(t, err)
+	assert.True(t, startPos.Equal(relayLogTestPos(t, 2)), startPos.String())
+	items, err := rl.Fetch()
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	assert.Equal(t, relayLogTestGTID(2), items[0][3].Gtid)
+	rl.Close()
+
+	// The relay log of a different filter is discarded.
+	otherFilter := &binlogdatapb.Filter{Rules: []*binlogdatapb.Rule{{Match: "t2"}}}
+	rl, startPos, err = openDiskRelayLog(ctx, dir, otherFilter, relayLogTestPos(t, 1), 100, 10000, 1<<20)
+	require.NoError(t, err)
+	assert.True(t, startPos.Equal(relayLogTestPos(t, 1)))
+	assert.Zero(t, rl.unread)
+	rl.Close()
+}
+
+func TestDiskRelayLogSegments(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	dir := t.TempDir()
+	filter := &binlogdatapb.Filter{Rules: []*binlogdatapb.Rule{{Match: "t1"}}}
+
+	// Every transaction gets its own segment.
+	rl, _, err := openDiskRelayLog(ctx, dir, filter, relayLogTestPos(t, 1), 100, 10000, 4)
+	require.NoError(t, err)
+	for n := 2; n <= 10; n++ {
+		require.NoError(t, rl.Send(relayLogTestTxn(n, n == 2)))
+		items, err := rl.Fetch()
+		require.NoError(t, err)
+		require.Len(t, items, 1)
+		assert.Equal(t, relayLogTestGTID(n), items[0][len(items[0])-2].Gtid)
+	}
+	segments, err := filepath.Glob(filepath.Join(dir, "*"+relayLogSegmentSuffix))
+	require.NoError(t, err)
+	assert.LessOrEqual(t, len(segments), 3)
+	rl.Close()
+
+	// The fields are carried over to the segment the relay log resumes at.
+	rl, startPos, err := openDiskRelayLog(ctx, dir, filter, relayLogTestPos(t, 10), 100, 10000, 4)
+	require.NoError(t, err)
+	assert.True(t, startPos.Equal(relayLogTestPos(t, 10)))
+	require.Len(t, rl.pending, 1)
+	assert.Equal(t, "t1", rl.pending[0][0].FieldEvent.TableName)
+	rl.Close()
+}
@@ -133,6 +133,7 @@ func (rp *ReplicatorPlan) buildFromFields(tableName string, lastpk *sqltypes.Res
 		tpb.colExprs = append(tpb.colExprs, cexpr)
 	}
 	// The following actions are a subset of buildTablePlan.
+	tpb.fullRowKey = isFullRowKey(tpb.colInfos)
 	if err := tpb.analyzePK(rp.ColInfoMap[tableName]); err != nil {
 		return nil, err
 	}
@@ -209,6 +210,9 @@ type TablePlan struct {
 	FieldsToSkip            map[string]bool
 	ConvertCharset          map[string](*binlogdatapb.CharsetConversion)
 	HasExtraSourcePkColumns bool
+	// FullRowKey is set if the table has no primary or unique key. Its rows
+	// are matched on all of their columns, and updated in place.
+	FullRowKey bool
 
 	TablePlanBuilder *tablePlanBuilder
 	// PartialInserts is a dynamically generated cache of insert ParsedQueries, which update only some columns.
@@ -422,7 +426,7 @@ func (tp *TablePlan) applyChange(rowChange *binlogdatapb.RowChange, executor fun
 		}
 		return execParsedQuery(tp.Delete, bindvars, executor)
 	case before && after:
-		if !tp.pkChanged(bindvars) && !tp.HasExtraSourcePkColumns {
+		if (tp.FullRowKey || !tp.pkChanged(bindvars)) && !tp.HasExtraSourcePkColumns {
 			if tp.isPartial(rowChange) {
 				upd, err := tp.getPartialUpdateQuery(rowChange.DataColumns)
 				if err != nil {
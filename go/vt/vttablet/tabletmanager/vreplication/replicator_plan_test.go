@@ -24,6 +24,7 @@ import (
 	"vitess.io/vitess/go/vt/binlog/binlogplayer"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"vitess.io/vitess/go/sqltypes"
 	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
@@ -772,3 +773,38 @@ func TestBuildPlayerPlanExclude(t *testing.T) {
 	wantPlan, _ := json.Marshal(want)
 	assert.Equal(t, string(gotPlan), string(wantPlan))
 }
+
+func TestBuildPlayerPlanFullRowKey(t *testing.T) {
+	colInfos := map[string][]*ColumnInfo{
+		"t1": {
+			&ColumnInfo{Name: "c1", IsPK: true, IsFullRowKey: true},
+			&ColumnInfo{Name: "c2", IsPK: true, IsFullRowKey: true},
+		},
+	}
+	input := &binlogdatapb.Filter{
+		Rules: []*binlogdatapb.Rule{{
+			Match:  "t1",
+			Filter: "select c1, c2 from t1",
+		}},
+	}
+	plan, err := buildReplicatorPlan(getSource(input), colInfos, nil, binlogplayer.NewStats())
+	require.NoError(t, err)
+	tplan := plan.TablePlans["t1"]
+	require.NotNil(t, tplan)
+	assert.True(t, tplan.FullRowKey)
+	assert.Equal(t, "update t1 set c1=:a_c1, c2=:a_c2 where c1<=>:b_c1 and c2<=>:b_c2 limit 1", tplan.Update.Query)
+	assert.Equal(t, "delete from t1 where c1<=>:b_c1 and c2<=>:b_c2 limit 1", tplan.Delete.Query)
+
+	// Updates change the row in place instead of moving it.
+	tplan.Fields = sqltypes.MakeTestFields("c1|c2", "int64|varchar")
+	var queries []string
+	_, err = tplan.applyChange(&binlogdatapb.RowChange{
+		Before: sqltypes.RowToProto3([]sqltypes.Value{sqltypes.NewInt64(1), sqltypes.NULL}),
+		After:  sqltypes.RowToProto3([]sqltypes.Value{sqltypes.NewInt64(1), sqltypes.NewVarChar("a")}),
+	}, func(sql string) (*sqltypes.Result, error) {
+		queries = append(queries, sql)
+		return &sqltypes.Result{}, nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"update t1 set c1=1, c2='a' where c1<=>1 and c2<=>null limit 1"}, queries)
+}
@@ -0,0 +1,233 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vreplication
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	"vitess.io/vitess/go/vt/log"
+
+	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
+	querypb "vitess.io/vitess/go/vt/proto/query"
+)
+
+const day = 24 * time.Hour
+
+// streamSchedule enforces the maintenance window and the rate limits of a
+// stream. A nil streamSchedule places no restriction on the stream.
+type streamSchedule struct {
+	// windowStart and windowEnd are the bounds of the window, as offsets
+	// from midnight UTC. They are equal if the stream has no window.
+	windowStart, windowEnd time.Duration
+	copyOnly               bool
+
+	rows, bytes *rate.Limiter
+}
+
+// ValidateSchedule returns an error if the schedule can't be enforced.
+func ValidateSchedule(schedule *binlogdatapb.VReplicationSchedule) error {
+	_, err := newStreamSchedule(schedule)
+	return err
+}
+
+// newStreamSchedule returns the streamSchedule of a binlog source. It returns
+// nil if the schedule has no window and no limit.
+func newStreamSchedule(schedule *binlogdatapb.VReplicationSchedule) (*streamSchedule, error) {
+	if schedule == nil {
+		return nil, nil
+	}
+	if (schedule.WindowStart == "") != (schedule.WindowEnd == "") {
+		return nil, fmt.Errorf("both the start and the end of the maintenance window must be specified")
+	}
+	if schedule.MaxRowsPerSecond < 0 || schedule.MaxBytesPerSecond < 0 {
+		return nil, fmt.Errorf("invalid rate limits: %d rows/s, %d bytes/s", schedule.MaxRowsPerSecond, schedule.MaxBytesPerSecond)
+	}
+	ss := &streamSchedule{copyOnly: schedule.CopyOnly}
+	if schedule.WindowStart != "" {
+		var err error
+		if ss.windowStart, err = parseTimeOfDay(schedule.WindowStart); err != nil {
+			return nil, err
+		}
+		if ss.windowEnd, err = parseTimeOfDay(schedule.WindowEnd); err != nil {
+			return nil, err
+		}
+		if ss.windowStart == ss.windowEnd {
+			return nil, fmt.Errorf("the maintenance window %s-%s is empty", schedule.WindowStart, schedule.WindowEnd)
+		}
+	}
+	if schedule.MaxRowsPerSecond > 0 {
+		ss.rows = rate.NewLimiter(rate.Limit(schedule.MaxRowsPerSecond), int(schedule.MaxRowsPerSecond))
+	}
+	if schedule.MaxBytesPerSecond > 0 {
+		ss.bytes = rate.NewLimiter(rate.Limit(schedule.MaxBytesPerSecond), int(schedule.MaxBytesPerSecond))
+	}
+	if !ss.hasWindow() && ss.rows == nil && ss.bytes == nil {
+		return nil, nil
+	}
+	return ss, nil
+}
+
+// parseTimeOfDay parses a HH:MM time of day into its offset from midnight.
+func parseTimeOfDay(s string) (time.Duration, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time of day %q, expected HH:MM", s)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+func (ss *streamSchedule) hasWindow() bool {
+	return ss != nil && ss.windowStart != ss.windowEnd
+}
+
+func (ss *streamSchedule) String() string {
+	return fmt.Sprintf("%02d:%02d-%02d:%02d UTC",
+		int(ss.windowStart.Hours()), int(ss.windowStart.Minutes())%60, int(ss.windowEnd.Hours()), int(ss.windowEnd.Minutes())%60)
+}
+
+// untilOpen returns how long it takes for the window to open, or 0 if it's
+// open.
+func (ss *streamSchedule) untilOpen(now time.Time) time.Duration {
+	offset := sinceMidnight(now)
+	if ss.windowStart < ss.windowEnd {
+		if offset >= ss.windowStart && offset < ss.windowEnd {
+			return 0
+		}
+	} else if offset >= ss.windowStart || offset < ss.windowEnd {
+		// The window wraps around midnight.
+		return 0
+	}
+	return untilOffset(offset, ss.windowStart)
+}
+
+// untilClose returns how long it takes for the open window to close.
+func (ss *streamSchedule) untilClose(now time.Time) time.Duration {
+	return untilOffset(sinceMidnight(now), ss.windowEnd)
+}
+
+func sinceMidnight(t time.Time) time.Duration {
+	t = t.UTC()
+	return t.Sub(time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC))
+}
+
+func untilOffset(from, to time.Duration) time.Duration {
+	d := to - from
+	if d <= 0 {
+		d += day
+	}
+	return d
+}
+
+// waitForWindow waits until the window of the stream is open, and returns
+// a context that expires when it closes. While it waits, the reason of the
+// pause is saved with setMessage. The window only applies to the copy phase
+// if the schedule is copy only: copying tells whether the stream is copying.
+func (ss *streamSchedule) waitForWindow(ctx context.Context, copying bool, setMessage func(string) error) (context.Context, context.CancelFunc, error) {
+	if !ss.hasWindow() || (ss.copyOnly && !copying) {
+		return ctx, func() {}, nil
+	}
+	now := time.Now()
+	if wait := ss.untilOpen(now); wait > 0 {
+		message := fmt.Sprintf("Paused: outside of the maintenance window %s", ss)
+		log.Infof("%s, resuming in %v", message, wait)
+		if err := setMessage(message); err != nil {
+			log.Warningf("Unable to save the pause of the stream: %v", err)
+		}
+		timer := time.NewTimer(wait)
+		defer timer.Stop()
+		select {
+		case <-ctx.Done():
+			return nil, nil, ctx.Err()
+		case <-timer.C:
+		}
+		if err := setMessage(fmt.Sprintf("Resumed: inside of the maintenance window %s", ss)); err != nil {
+			log.Warningf("Unable to save the resumption of the stream: %v", err)
+		}
+		now = time.Now()
+	}
+	windowCtx, cancel := context.WithDeadline(ctx, now.Add(ss.untilClose(now)))
+	return windowCtx, cancel, nil
+}
+
+// windowClosed returns true if the stream stopped because its window closed,
+// as opposed to being stopped by its parent context.
+func windowClosed(ctx, windowCtx context.Context) bool {
+	return ctx.Err() == nil && windowCtx.Err() != nil
+}
+
+// waitForBudget waits until the given rows can be copied or applied within
+// the rate limits of the stream.
+func (ss *streamSchedule) waitForBudget(ctx context.Context, rows []*querypb.Row) error {
+	if ss == nil || (ss.rows == nil && ss.bytes == nil) {
+		return nil
+	}
+	var size int
+	for _, row := range rows {
+		size += len(row.Values)
+	}
+	if err := waitN(ctx, ss.rows, len(rows)); err != nil {
+		return err
+	}
+	return waitN(ctx, ss.bytes, size)
+}
+
+// waitForRowChanges waits until the row changes of the event can be applied
+// within the rate limits of the stream.
+func (ss *streamSchedule) waitForRowChanges(ctx context.Context, rowEvent *binlogdatapb.RowEvent) error {
+	if ss == nil || (ss.rows == nil && ss.bytes == nil) {
+		return nil
+	}
+	rows := make([]*querypb.Row, 0, len(rowEvent.RowChanges))
+	for _, change := range rowEvent.RowChanges {
+		if change.After != nil {
+			rows = append(rows, change.After)
+		} else if change.Before != nil {
+			rows = append(rows, change.Before)
+		}
+	}
+	return ss.waitForBudget(ctx, rows)
+}
+
+// waitN waits for n tokens of the limiter, in bursts that it can grant.
+// Unlike rate.Limiter.WaitN, it only fails once the context is done, and
+// not as soon as the wait is known to exceed its deadline.
+func waitN(ctx context.Context, limiter *rate.Limiter, n int) error {
+	if limiter == nil {
+		return nil
+	}
+	for n > 0 {
+		burst := n
+		if burst > limiter.Burst() {
+			burst = limiter.Burst()
+		}
+		reservation := limiter.ReserveN(time.Now(), burst)
+		timer := time.NewTimer(reservation.Delay())
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			reservation.Cancel()
+			return ctx.Err()
+		case <-timer.C:
+		}
+		n -= burst
+	}
+	return nil
+}
@@ -0,0 +1,167 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vreplication
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
+	querypb "vitess.io/vitess/go/vt/proto/query"
+)
+
+func TestNewStreamSchedule(t *testing.T) {
+	testcases := []struct {
+		schedule *binlogdatapb.VReplicationSchedule
+		isNil    bool
+		err      string
+	}{{
+		schedule: nil,
+		isNil:    true,
+	}, {
+		schedule: &binlogdatapb.VReplicationSchedule{},
+		isNil:    true,
+	}, {
+		schedule: &binlogdatapb.VReplicationSchedule{WindowStart: "01:00", WindowEnd: "06:00"},
+	}, {
+		schedule: &binlogdatapb.VReplicationSchedule{MaxBytesPerSecond: 1 << 20},
+	}, {
+		schedule: &binlogdatapb.VReplicationSchedule{WindowStart: "01:00"},
+		err:      "both the start and the end of the maintenance window must be specified",
+	}, {
+		schedule: &binlogdatapb.VReplicationSchedule{WindowStart: "1am", WindowEnd: "06:00"},
+		err:      `invalid time of day "1am", expected HH:MM`,
+	}, {
+		schedule: &binlogdatapb.VReplicationSchedule{WindowStart: "06:00", WindowEnd: "06:00"},
+		err:      "the maintenance window 06:00-06:00 is empty",
+	}, {
+		schedule: &binlogdatapb.VReplicationSchedule{MaxRowsPerSecond: -1},
+		err:      "invalid rate limits: -1 rows/s, 0 bytes/s",
+	}}
+	for _, tcase := range testcases {
+		ss, err := newStreamSchedule(tcase.schedule)
+		if tcase.err != "" {
+			assert.EqualError(t, err, tcase.err, "%v", tcase.schedule)
+			continue
+		}
+		require.NoError(t, err, "%v", tcase.schedule)
+		assert.Equal(t, tcase.isNil, ss == nil, "%v", tcase.schedule)
+	}
+}
+
+func TestStreamScheduleWindow(t *testing.T) {
+	at := func(hour, min int) time.Time {
+		return time.Date(2023, 5, 1, hour, min, 0, 0, time.UTC)
+	}
+	testcases := []struct {
+		start, end            string
+		now                   time.Time
+		untilOpen, untilClose time.Duration
+	}{{
+		start:      "01:00",
+		end:        "06:00",
+		now:        at(0, 30),
+		untilOpen:  30 * time.Minute,
+		untilClose: 5*time.Hour + 30*time.Minute,
+	}, {
+		start:      "01:00",
+		end:        "06:00",
+		now:        at(1, 0),
+		untilClose: 5 * time.Hour,
+	}, {
+		start:     "01:00",
+		end:       "06:00",
+		now:       at(6, 0),
+		untilOpen: 19 * time.Hour,
+	}, {
+		// The window wraps around midnight.
+		start:      "22:00",
+		end:        "02:30",
+		now:        at(23, 0),
+		untilClose: 3*time.Hour + 30*time.Minute,
+	}, {
+		start:      "22:00",
+		end:        "02:30",
+		now:        at(1, 0),
+		untilClose: 90 * time.Minute,
+	}, {
+		start:     "22:00",
+		end:       "02:30",
+		now:       at(12, 0),
+		untilOpen: 10 * time.Hour,
+	}}
+	for _, tcase := range testcases {
+		ss, err := newStreamSchedule(&binlogdatapb.VReplicationSchedule{WindowStart: tcase.start, WindowEnd: tcase.end})
+		require.NoError(t, err)
+		assert.Equal(t, tcase.untilOpen, ss.untilOpen(tcase.now), "%s-%s at %v", tcase.start, tcase.end, tcase.now)
+		if tcase.untilOpen == 0 {
+			assert.Equal(t, tcase.untilClose, ss.untilClose(tcase.now), "%s-%s at %v", tcase.start, tcase.end, tcase.now)
+		}
+	}
+}
+
+func TestStreamScheduleCopyOnly(t *testing.T) {
+	// The window is never open: it opens one hour from now.
+	now := time.Now().UTC()
+	start := now.Add(time.Hour)
+	ss, err := newStreamSchedule(&binlogdatapb.VReplicationSchedule{
+		WindowStart: start.Format("15:04"),
+		WindowEnd:   start.Add(time.Hour).Format("15:04"),
+		CopyOnly:    true,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "15:04-16:04 UTC", (&streamSchedule{windowStart: 15*time.Hour + 4*time.Minute, windowEnd: 16*time.Hour + 4*time.Minute}).String())
+
+	var messages []string
+	setMessage := func(message string) error {
+		messages = append(messages, message)
+		return nil
+	}
+	// The window doesn't apply to the replication of the stream.
+	ctx, cancel, err := ss.waitForWindow(context.Background(), false, setMessage)
+	require.NoError(t, err)
+	cancel()
+	assert.NoError(t, ctx.Err())
+	assert.Empty(t, messages)
+
+	// The copy waits for the window to open.
+	parent, cancelParent := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancelParent()
+	_, _, err = ss.waitForWindow(parent, true, setMessage)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, []string{"Paused: outside of the maintenance window " + ss.String()}, messages)
+}
+
+func TestStreamScheduleBudget(t *testing.T) {
+	ss, err := newStreamSchedule(&binlogdatapb.VReplicationSchedule{MaxRowsPerSecond: 2})
+	require.NoError(t, err)
+	rows := []*querypb.Row{{Values: []byte("a")}, {Values: []byte("b")}}
+	// The first rows are within the burst.
+	require.NoError(t, ss.waitForBudget(context.Background(), rows))
+
+	// The next rows exceed the budget of the second.
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, ss.waitForBudget(ctx, rows), context.DeadlineExceeded)
+
+	var nilSchedule *streamSchedule
+	assert.NoError(t, nilSchedule.waitForBudget(ctx, rows))
+}
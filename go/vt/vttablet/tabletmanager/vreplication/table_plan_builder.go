@@ -56,6 +56,9 @@ type tablePlanBuilder struct {
 	stats             *binlogplayer.Stats
 	source            *binlogdatapb.BinlogSource
 	pkIndices         []bool
+	// fullRowKey is set if the table has no primary or unique key, and its
+	// rows are matched on all of their columns.
+	fullRowKey bool
 }
 
 // colExpr describes the processing to be performed to
@@ -281,6 +284,7 @@ func buildTablePlan(tableName string, rule *binlogdatapb.Rule, colInfos []*Colum
 		return nil, err
 	}
 	pkColsInfo := tpb.getPKColsInfo(targetKeyColumnNames, colInfos)
+	tpb.fullRowKey = len(targetKeyColumnNames) == 0 && isFullRowKey(colInfos)
 	if err := tpb.analyzePK(pkColsInfo); err != nil {
 		return nil, err
 	}
@@ -363,6 +367,7 @@ func (tpb *tablePlanBuilder) generate() *TablePlan {
 		Stats:                   tpb.stats,
 		FieldsToSkip:            fieldsToSkip,
 		HasExtraSourcePkColumns: len(tpb.extraSourcePkCols) > 0,
+		FullRowKey:              tpb.fullRowKey,
 		TablePlanBuilder:        tpb,
 		PartialInserts:          make(map[string]*sqlparser.ParsedQuery, 0),
 		PartialUpdates:          make(map[string]*sqlparser.ParsedQuery, 0),
@@ -617,6 +622,17 @@ func (tpb *tablePlanBuilder) analyzeExtraSourcePkCols(colInfos []*ColumnInfo, so
 	return nil
 }
 
+// isFullRowKey returns true if the columns belong to a table without a
+// primary or unique key.
+func isFullRowKey(colInfos []*ColumnInfo) bool {
+	for _, colInfo := range colInfos {
+		if colInfo.IsFullRowKey {
+			return true
+		}
+	}
+	return false
+}
+
 // findCol finds a column in a list of expressions
 func findCol(name sqlparser.IdentifierCI, exprs []*colExpr) *colExpr {
 	for _, cexpr := range exprs {
@@ -776,7 +792,9 @@ func (tpb *tablePlanBuilder) generateUpdateStatement() *sqlparser.ParsedQuery {
 		if cexpr.isPK {
 			tpb.pkIndices[i] = true
 		}
-		if cexpr.isGrouped || cexpr.isPK {
+		// Rows of tables without a key are updated in place, so all of
+		// their columns are set.
+		if cexpr.isGrouped || (cexpr.isPK && !tpb.fullRowKey) {
 			continue
 		}
 		if tpb.isColumnGenerated(cexpr.colName) {
@@ -848,19 +866,26 @@ func (tpb *tablePlanBuilder) generateDeleteStatement() *sqlparser.ParsedQuery {
 	return buf.ParsedQuery()
 }
 
+// generateWhere generates the where clause that matches the row before the
+// change. The rows of tables without a key are matched on all of their
+// columns, which may be null, and only one of any duplicate rows is changed.
 func (tpb *tablePlanBuilder) generateWhere(buf *sqlparser.TrackedBuffer, bvf *bindvarFormatter) {
 	buf.WriteString(" where ")
 	bvf.mode = bvBefore
 	separator := ""
+	op := "="
+	if tpb.fullRowKey {
+		op = "<=>"
+	}
 
 	addWhereColumns := func(colExprs []*colExpr) {
 		for _, cexpr := range colExprs {
 			if _, ok := cexpr.expr.(*sqlparser.ColName); ok {
-				buf.Myprintf("%s%v=", separator, cexpr.colName)
+				buf.Myprintf("%s%v%s", separator, cexpr.colName, op)
 				buf.Myprintf("%v", cexpr.expr)
 			} else {
 				// Parenthesize non-trivial expressions.
-				buf.Myprintf("%s%v=(", separator, cexpr.colName)
+				buf.Myprintf("%s%v%s(", separator, cexpr.colName, op)
 				buf.Myprintf("%v", cexpr.expr)
 				buf.Myprintf(")")
 			}
@@ -873,6 +898,9 @@ func (tpb *tablePlanBuilder) generateWhere(buf *sqlparser.TrackedBuffer, bvf *bi
 		buf.WriteString(" and ")
 		tpb.generatePKConstraint(buf, bvf)
 	}
+	if tpb.fullRowKey {
+		buf.WriteString(" limit 1")
+	}
 }
 
 func (tpb *tablePlanBuilder) getCharsetAndCollation(pkname string) (charSet string, collation string) {
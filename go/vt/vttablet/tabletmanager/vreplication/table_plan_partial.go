@@ -141,7 +141,7 @@ func (tpb *tablePlanBuilder) createPartialUpdateQuery(dataColumns *binlogdatapb.
 	buf.Myprintf("update %v set ", tpb.name)
 	separator := ""
 	for i, cexpr := range tpb.colExprs {
-		if cexpr.isPK {
+		if cexpr.isPK && !tpb.fullRowKey {
 			continue
 		}
 		if tpb.isColumnGenerated(cexpr.colName) {
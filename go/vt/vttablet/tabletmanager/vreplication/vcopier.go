@@ -23,6 +23,7 @@ import (
 	"math"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"google.golang.org/protobuf/encoding/prototext"
@@ -33,6 +34,7 @@ import (
 	"vitess.io/vitess/go/pools"
 	"vitess.io/vitess/go/sqltypes"
 	"vitess.io/vitess/go/vt/binlog/binlogplayer"
+	"vitess.io/vitess/go/vt/key"
 	"vitess.io/vitess/go/vt/log"
 	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
 	querypb "vitess.io/vitess/go/vt/proto/query"
@@ -45,6 +47,13 @@ import (
 type vcopier struct {
 	vr               *vreplicator
 	throttlerAppName string
+	// snapshots are the snapshots of the tables that are copied
+	// concurrently, which the vplayers of the copy phase have to take into
+	// account. See copyNextConcurrently.
+	snapshots map[string]*copySnapshot
+	// replicatorClientMu serializes the use of the connection of the
+	// replicator by tables that are copied concurrently.
+	replicatorClientMu sync.Mutex
 }
 
 // vcopierCopyTask stores the args and lifecycle hooks of a copy task.
@@ -293,6 +302,9 @@ func (vc *vcopier) initTablesForCopy(ctx context.Context) error {
 // primary key that was copied. A nil Result means that nothing has been copied.
 // A table that was fully copied is removed from copyState.
 func (vc *vcopier) copyNext(ctx context.Context, settings binlogplayer.VRSettings) error {
+	if vreplicationParallelTableCopies > 1 {
+		return vc.copyNextConcurrently(ctx, settings)
+	}
 	qr, err := vc.vr.dbClient.Execute(fmt.Sprintf("select table_name, lastpk from _vt.copy_state where vrepl_id = %d and id in (select max(id) from _vt.copy_state group by vrepl_id, table_name)", vc.vr.id))
 	if err != nil {
 		return err
@@ -301,28 +313,135 @@ func (vc *vcopier) copyNext(ctx context.Context, settings binlogplayer.VRSetting
 	copyState := make(map[string]*sqltypes.Result)
 	for _, row := range qr.Rows {
 		tableName := row[0].ToString()
-		lastpk := row[1].ToString()
 		if tableToCopy == "" {
 			tableToCopy = tableName
 		}
-		copyState[tableName] = nil
-		if lastpk != "" {
-			var r querypb.QueryResult
-			if err := prototext.Unmarshal([]byte(lastpk), &r); err != nil {
-				return err
-			}
-			copyState[tableName] = sqltypes.Proto3ToResult(&r)
+		if copyState[tableName], err = decodeLastpk(row[1].ToString()); err != nil {
+			return err
 		}
 	}
 	if len(copyState) == 0 {
 		return fmt.Errorf("unexpected: there are no tables to copy")
 	}
+	if err := vc.restartFullRowKeyCopies(copyState, nil); err != nil {
+		return err
+	}
 	if err := vc.catchup(ctx, copyState); err != nil {
 		return err
 	}
 	return vc.copyTable(ctx, tableToCopy, copyState)
 }
 
+// restartFullRowKeyCopies discards the rows of the tables without a primary
+// or unique key that were only partially copied. The lastpk of such a table
+// doesn't identify a row, so its copy can't be resumed and starts over.
+// Tables that have a snapshot were copied concurrently with other tables and
+// are left alone.
+func (vc *vcopier) restartFullRowKeyCopies(copyState map[string]*sqltypes.Result, snapshots map[string]*copySnapshot) error {
+	defer vc.vr.dbClient.Rollback()
+
+	for tableName, lastpk := range copyState {
+		if lastpk == nil || snapshots[tableName] != nil || !isFullRowKey(vc.vr.colInfoMap[tableName]) {
+			continue
+		}
+		if err := vc.checkRestartableCopy(tableName); err != nil {
+			return vterrors.Wrapf(err, "cannot restart the copy of table %s, which has no primary or unique key", tableName)
+		}
+		log.Infof("Restarting the copy of %s, which has no primary or unique key", tableName)
+		if err := vc.vr.dbClient.Begin(); err != nil {
+			return err
+		}
+		buf := sqlparser.NewTrackedBuffer(nil)
+		buf.Myprintf("delete from %v", sqlparser.NewIdentifierCS(tableName))
+		if _, err := vc.vr.dbClient.Execute(buf.String()); err != nil {
+			return err
+		}
+		buf = sqlparser.NewTrackedBuffer(nil)
+		buf.Myprintf("delete from _vt.%s where vrepl_id=%d and table_name=%s", copyStateTableName, vc.vr.id, encodeString(tableName))
+		if _, err := vc.vr.dbClient.Execute(buf.String()); err != nil {
+			return err
+		}
+		buf = sqlparser.NewTrackedBuffer(nil)
+		buf.Myprintf("insert into _vt.%s (vrepl_id, table_name) values (%d, %s)", copyStateTableName, vc.vr.id, encodeString(tableName))
+		if _, err := vc.vr.dbClient.Execute(buf.String()); err != nil {
+			return err
+		}
+		if err := vc.vr.dbClient.Commit(); err != nil {
+			return err
+		}
+		copyState[tableName] = nil
+	}
+	return nil
+}
+
+// checkRestartableCopy returns an error if the rows copied to the table
+// can't be told apart from the other rows of the table, in which case they
+// can't be discarded to restart its copy. The target keeps no trace of the
+// stream a row was copied by, so this stream must be the only one that
+// writes to the table, and its filter must copy all the rows of the source
+// table that are in its keyrange.
+func (vc *vcopier) checkRestartableCopy(tableName string) error {
+	rule, err := MatchTable(tableName, vc.vr.source.Filter)
+	if err != nil {
+		return err
+	}
+	if rule == nil {
+		return fmt.Errorf("no filter rule matches the table")
+	}
+	if !isKeyRangeFilter(rule.Filter) {
+		return fmt.Errorf("the rows copied by the filter %q cannot be told apart from the other rows of the table", rule.Filter)
+	}
+
+	query := fmt.Sprintf("select id, source from _vt.vreplication where db_name=%s and id!=%d", encodeString(vc.vr.dbClient.DBName()), vc.vr.id)
+	qr, err := vc.vr.dbClient.Execute(query)
+	if err != nil {
+		return err
+	}
+	for _, row := range qr.Rows {
+		var bls binlogdatapb.BinlogSource
+		if err := prototext.Unmarshal([]byte(row[1].ToString()), &bls); err != nil {
+			return err
+		}
+		if bls.Filter == nil {
+			continue
+		}
+		other, err := MatchTable(tableName, bls.Filter)
+		if err != nil {
+			return err
+		}
+		if other != nil && other.Filter != ExcludeStr {
+			return fmt.Errorf("the table is also written to by stream %s", row[0].ToString())
+		}
+	}
+	return nil
+}
+
+// isKeyRangeFilter returns true if the filter of a rule selects all the
+// rows of its table that are in a keyrange, if any.
+func isKeyRangeFilter(filter string) bool {
+	if filter == "" || key.IsValidKeyRange(filter) {
+		return true
+	}
+	stmt, err := sqlparser.Parse(filter)
+	if err != nil {
+		return false
+	}
+	sel, ok := stmt.(*sqlparser.Select)
+	if !ok {
+		return false
+	}
+	if sel.Where == nil {
+		return true
+	}
+	for _, expr := range sqlparser.SplitAndExpression(nil, sel.Where.Expr) {
+		funcExpr, ok := expr.(*sqlparser.FuncExpr)
+		if !ok || !funcExpr.Name.EqualString("in_keyrange") {
+			return false
+		}
+	}
+	return true
+}
+
 // catchup replays events to the subset of the tables that have been copied
 // until replication is caught up. In order to stop, the seconds behind primary has
 // to fall below replicationLagTolerance.
@@ -342,9 +461,11 @@ func (vc *vcopier) catchup(ctx context.Context, copyState map[string]*sqltypes.R
 	}
 
 	// Start vreplication.
+	vp := newVPlayer(vc.vr, settings, copyState, mysql.Position{}, "catchup")
+	vp.copySnapshots = vc.snapshots
 	errch := make(chan error, 1)
 	go func() {
-		errch <- newVPlayer(vc.vr, settings, copyState, mysql.Position{}, "catchup").play(ctx)
+		errch <- vp.play(ctx)
 	}()
 
 	// Wait for catchup.
@@ -378,7 +499,20 @@ func (vc *vcopier) catchup(ctx context.Context, copyState map[string]*sqltypes.R
 // the current table being copied. Each packet received is transactionally
 // committed with the lastpk. This allows for consistent resumability.
 func (vc *vcopier) copyTable(ctx context.Context, tableName string, copyState map[string]*sqltypes.Result) error {
-	defer vc.vr.dbClient.Rollback()
+	return vc.copyTableRows(ctx, tableName, copyState, nil)
+}
+
+// copyTableRows copies the next set of rows of the table. If tc is set, the
+// table is copied concurrently with other tables: the copy uses the
+// connection of tc, the target is not fast forwarded to the snapshot of the
+// rows, and the copy state of the table is kept even if all of its rows are
+// copied. See copyNextConcurrently.
+func (vc *vcopier) copyTableRows(ctx context.Context, tableName string, copyState map[string]*sqltypes.Result, tc *tableCopy) error {
+	dbClient := vc.vr.dbClient
+	if tc != nil {
+		dbClient = tc.dbClient
+	}
+	defer dbClient.Rollback()
 	defer vc.vr.stats.PhaseTimings.Record("copy", time.Now())
 	defer vc.vr.stats.CopyLoopCount.Add(1)
 
@@ -394,7 +528,14 @@ func (vc *vcopier) copyTable(ctx context.Context, tableName string, copyState ma
 		return fmt.Errorf("plan not found for table: %s, current plans are: %#v", tableName, plan.TargetTables)
 	}
 
-	ctx, cancel := context.WithTimeout(ctx, copyPhaseDuration)
+	// Tables without a primary or unique key can't be resumed from their
+	// lastpk, so they are copied in a single pass.
+	var cancel context.CancelFunc
+	if isFullRowKey(vc.vr.colInfoMap[tableName]) {
+		ctx, cancel = context.WithCancel(ctx)
+	} else {
+		ctx, cancel = context.WithTimeout(ctx, copyPhaseDuration)
+	}
 	defer cancel()
 
 	var lastpkpb *querypb.QueryResult
@@ -408,7 +549,7 @@ func (vc *vcopier) copyTable(ctx context.Context, tableName string, copyState ma
 	defer copyStateGCTicker.Stop()
 
 	parallelism := int(math.Max(1, float64(vreplicationParallelInsertWorkers)))
-	copyWorkerFactory := vc.newCopyWorkerFactory(parallelism)
+	copyWorkerFactory := vc.newCopyWorkerFactory(parallelism, dbClient)
 	copyWorkQueue := vc.newCopyWorkQueue(parallelism, copyWorkerFactory)
 	defer copyWorkQueue.close()
 
@@ -427,7 +568,7 @@ func (vc *vcopier) copyTable(ctx context.Context, tableName string, copyState ma
 			select {
 			case <-rowsCopiedTicker.C:
 				update := binlogplayer.GenerateUpdateRowsCopied(vc.vr.id, vc.vr.stats.CopyRowCount.Get())
-				_, _ = vc.vr.dbClient.Execute(update)
+				_, _ = dbClient.Execute(update)
 			case <-ctx.Done():
 				return io.EOF
 			default:
@@ -458,26 +599,36 @@ func (vc *vcopier) copyTable(ctx context.Context, tableName string, copyState ma
 			default:
 			}
 			if rows.Throttled {
-				_ = vc.vr.updateTimeThrottled(throttlerapp.RowStreamerName)
+				_ = vc.withReplicatorClient(tc, func() error {
+					return vc.vr.updateTimeThrottled(throttlerapp.RowStreamerName)
+				})
 				return nil
 			}
 			if rows.Heartbeat {
-				_ = vc.vr.updateHeartbeatTime(time.Now().Unix())
+				_ = vc.withReplicatorClient(tc, func() error {
+					return vc.vr.updateHeartbeatTime(time.Now().Unix())
+				})
 				return nil
 			}
 			// verify throttler is happy, otherwise keep looping
 			if vc.vr.vre.throttlerClient.ThrottleCheckOKOrWaitAppName(ctx, throttlerapp.Name(vc.throttlerAppName)) {
 				break // out of 'for' loop
 			} else { // we're throttled
-				_ = vc.vr.updateTimeThrottled(throttlerapp.VCopierName)
+				_ = vc.withReplicatorClient(tc, func() error {
+					return vc.vr.updateTimeThrottled(throttlerapp.VCopierName)
+				})
 			}
 		}
 		if !copyWorkQueue.isOpen {
 			if len(rows.Fields) == 0 {
 				return fmt.Errorf("expecting field event first, got: %v", rows)
 			}
-			if err := vc.fastForward(ctx, copyState, rows.Gtid); err != nil {
-				return err
+			// Tables copied concurrently keep the snapshot position along
+			// with their copy state instead.
+			if tc == nil {
+				if err := vc.fastForward(ctx, copyState, rows.Gtid); err != nil {
+					return err
+				}
 			}
 			fieldEvent := &binlogdatapb.FieldEvent{
 				TableName: initialPlan.SendRule.Match,
@@ -489,16 +640,28 @@ func (vc *vcopier) copyTable(ctx context.Context, tableName string, copyState ma
 			}
 			pkfields = append(pkfields, rows.Pkfields...)
 			buf := sqlparser.NewTrackedBuffer(nil)
-			buf.Myprintf(
-				"insert into _vt.copy_state (lastpk, vrepl_id, table_name) values (%a, %s, %s)", ":lastpk",
-				strconv.Itoa(int(vc.vr.id)),
-				encodeString(tableName))
+			if tc == nil {
+				buf.Myprintf(
+					"insert into _vt.copy_state (lastpk, vrepl_id, table_name) values (%a, %s, %s)", ":lastpk",
+					strconv.Itoa(int(vc.vr.id)),
+					encodeString(tableName))
+			} else {
+				buf.Myprintf(
+					"insert into _vt.copy_state (lastpk, vrepl_id, table_name, snapshot_pos, snapshot_lastpk) values (%a, %s, %s, %s, %s)", ":lastpk",
+					strconv.Itoa(int(vc.vr.id)),
+					encodeString(tableName),
+					encodeString(rows.Gtid),
+					tc.encodedLastpk())
+			}
 			addLatestCopyState := buf.ParsedQuery()
 			copyWorkQueue.open(addLatestCopyState, pkfields, tablePlan)
 		}
 		if len(rows.Rows) == 0 {
 			return nil
 		}
+		if err := vc.vr.schedule.waitForBudget(ctx, rows.Rows); err != nil {
+			return err
+		}
 
 		// Clone rows, since pointer values will change while async work is
 		// happening. Can skip this when there's no parallelism.
@@ -506,6 +669,10 @@ func (vc *vcopier) copyTable(ctx context.Context, tableName string, copyState ma
 			rows = proto.Clone(rows).(*binlogdatapb.VStreamRowsResponse)
 		}
 
+		if tc != nil {
+			tc.copiedRows = true
+		}
+
 		// Prepare a vcopierCopyTask for the current batch of work.
 		// TODO(maxeng) see if using a pre-allocated pool will speed things up.
 		currCh := make(chan *vcopierCopyTaskResult, 1)
@@ -649,19 +816,29 @@ func (vc *vcopier) copyTable(ctx context.Context, tableName string, copyState ma
 		return serr
 	}
 
+	log.Infof("Copy of %v finished at lastpk: %v", tableName, lastpkbv)
+	if tc != nil {
+		tc.completed = true
+		return nil
+	}
+	return vc.completeTable(ctx, dbClient, tableName)
+}
+
+// completeTable performs the post copy actions of a table that was fully
+// copied, and deletes its copy state.
+func (vc *vcopier) completeTable(ctx context.Context, dbClient *vdbClient, tableName string) error {
 	// Perform any post copy actions
 	if err := vc.vr.execPostCopyActions(ctx, tableName); err != nil {
 		return vterrors.Wrapf(err, "failed to execute post copy actions for table %q", tableName)
 	}
 
-	log.Infof("Copy of %v finished at lastpk: %v", tableName, lastpkbv)
 	buf := sqlparser.NewTrackedBuffer(nil)
 	buf.Myprintf(
 		"delete cs, pca from _vt.%s as cs left join _vt.%s as pca on cs.vrepl_id=pca.vrepl_id and cs.table_name=pca.table_name where cs.vrepl_id=%d and cs.table_name=%s",
 		copyStateTableName, postCopyActionTableName,
 		vc.vr.id, encodeString(tableName),
 	)
-	if _, err := vc.vr.dbClient.Execute(buf.String()); err != nil {
+	if _, err := dbClient.Execute(buf.String()); err != nil {
 		return err
 	}
 
@@ -683,7 +860,9 @@ func (vc *vcopier) fastForward(ctx context.Context, copyState map[string]*sqltyp
 		_, err := vc.vr.dbClient.Execute(update)
 		return err
 	}
-	return newVPlayer(vc.vr, settings, copyState, pos, "fastforward").play(ctx)
+	vp := newVPlayer(vc.vr, settings, copyState, pos, "fastforward")
+	vp.copySnapshots = vc.snapshots
+	return vp.play(ctx)
 }
 
 func (vc *vcopier) newCopyWorkQueue(
@@ -694,7 +873,7 @@ func (vc *vcopier) newCopyWorkQueue(
 	return newVCopierCopyWorkQueue(concurrent, parallelism, workerFactory)
 }
 
-func (vc *vcopier) newCopyWorkerFactory(parallelism int) func(context.Context) (*vcopierCopyWorker, error) {
+func (vc *vcopier) newCopyWorkerFactory(parallelism int, dbClient *vdbClient) func(context.Context) (*vcopierCopyWorker, error) {
 	if parallelism > 1 {
 		return func(ctx context.Context) (*vcopierCopyWorker, error) {
 			dbClient, err := vc.vr.newClientConnection(ctx)
@@ -710,7 +889,7 @@ func (vc *vcopier) newCopyWorkerFactory(parallelism int) func(context.Context) (
 	return func(_ context.Context) (*vcopierCopyWorker, error) {
 		return newVCopierCopyWorker(
 			false, /* close db client */
-			vc.vr.dbClient,
+			dbClient,
 		), nil
 	}
 }
@@ -0,0 +1,255 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vreplication
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"google.golang.org/protobuf/encoding/prototext"
+
+	"vitess.io/vitess/go/mysql"
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/binlog/binlogplayer"
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/vterrors"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+)
+
+// copySnapshot is the snapshot of the source that the rows of a table copied
+// after lastpk come from, when the table was copied concurrently with other
+// tables. The rows up to lastpk are consistent with the position of the
+// stream instead, so the events that are already part of the snapshot must
+// only be applied to them.
+type copySnapshot struct {
+	pos    mysql.Position
+	lastpk *sqltypes.Result
+}
+
+// snapshotTablePlan is the plan that a vplayer uses for the events of a
+// table that are already part of its copy snapshot. A nil plan means that
+// no rows were copied before the snapshot, so such events are skipped.
+type snapshotTablePlan struct {
+	pos  mysql.Position
+	plan *TablePlan
+}
+
+// tableCopy is the copy of a table that runs concurrently with the copies of
+// other tables.
+type tableCopy struct {
+	dbClient *vdbClient
+	// lastpk is the copy state of the table when the copy started, as it is
+	// stored in _vt.copy_state.
+	lastpk string
+	// copiedRows is set if any rows were copied.
+	copiedRows bool
+	// completed is set if all the rows of the table were copied.
+	completed bool
+}
+
+// copyNextConcurrently is the version of copyNext that copies several tables
+// at the same time, each from its own snapshot of the source.
+//
+// Since the position of the stream can only match one snapshot, the target
+// isn't fast forwarded to the snapshots. The position of each snapshot is
+// saved in _vt.copy_state along with the lastpk the table had when the copy
+// started, and the vplayers of the copy phase only apply the events that
+// are part of a snapshot to the rows copied before it. Before the next
+// tables are copied, the target is fast forwarded past all the snapshots,
+// so that all the copied rows are consistent with the position of the
+// stream again.
+//
+// This also means that a table whose rows were all copied is not consistent
+// with the position until the target passes its snapshot. Its copy state is
+// kept until then, and it is completed by its next copy, which finds no rows
+// left to copy.
+func (vc *vcopier) copyNextConcurrently(ctx context.Context, settings binlogplayer.VRSettings) error {
+	qr, err := vc.vr.dbClient.Execute(fmt.Sprintf("select table_name, lastpk, snapshot_pos, snapshot_lastpk from _vt.copy_state where vrepl_id = %d and id in (select max(id) from _vt.copy_state group by vrepl_id, table_name)", vc.vr.id))
+	if err != nil {
+		return err
+	}
+	var tables []string
+	lastpks := make(map[string]string)
+	copyState := make(map[string]*sqltypes.Result)
+	snapshots := make(map[string]*copySnapshot)
+	for _, row := range qr.Rows {
+		tableName := row[0].ToString()
+		tables = append(tables, tableName)
+		lastpks[tableName] = row[1].ToString()
+		if copyState[tableName], err = decodeLastpk(row[1].ToString()); err != nil {
+			return err
+		}
+		if row[2].IsNull() {
+			continue
+		}
+		snapshot := &copySnapshot{}
+		if snapshot.pos, err = mysql.DecodePosition(row[2].ToString()); err != nil {
+			return err
+		}
+		if snapshot.lastpk, err = decodeLastpk(row[3].ToString()); err != nil {
+			return err
+		}
+		snapshots[tableName] = snapshot
+	}
+	if len(copyState) == 0 {
+		return fmt.Errorf("unexpected: there are no tables to copy")
+	}
+	if err := vc.restartFullRowKeyCopies(copyState, snapshots); err != nil {
+		return err
+	}
+
+	if len(snapshots) > 0 {
+		earliest, latest, err := snapshotBounds(snapshots)
+		if err != nil {
+			return err
+		}
+		// The first tables were copied before the stream had a position.
+		// The earliest snapshot is a position that all the copied rows can
+		// be caught up from.
+		if settings.StartPos.IsZero() {
+			update := binlogplayer.GenerateUpdatePos(vc.vr.id, earliest, time.Now().Unix(), 0, vc.vr.stats.CopyRowCount.Get(), vreplicationStoreCompressedGTID)
+			if _, err := vc.vr.dbClient.Execute(update); err != nil {
+				return err
+			}
+		}
+		vc.snapshots = snapshots
+		if err := vc.catchup(ctx, copyState); err != nil {
+			return err
+		}
+		if err := vc.fastForward(ctx, copyState, mysql.EncodePosition(latest)); err != nil {
+			return err
+		}
+		vc.snapshots = nil
+	} else if err := vc.catchup(ctx, copyState); err != nil {
+		return err
+	}
+
+	// Tables without a primary or unique key must be copied in a single
+	// pass, so they are copied one at a time with copyTable.
+	if isFullRowKey(vc.vr.colInfoMap[tables[0]]) {
+		return vc.copyTable(ctx, tables[0], copyState)
+	}
+	batch := make([]string, 0, vreplicationParallelTableCopies)
+	for _, tableName := range tables {
+		if len(batch) == vreplicationParallelTableCopies {
+			break
+		}
+		if !isFullRowKey(vc.vr.colInfoMap[tableName]) {
+			batch = append(batch, tableName)
+		}
+	}
+	tables = batch
+	copies := make([]*tableCopy, 0, len(tables))
+	defer func() {
+		for _, tc := range copies {
+			tc.dbClient.Close()
+		}
+	}()
+	for _, tableName := range tables {
+		dbClient, err := vc.vr.newClientConnection(ctx)
+		if err != nil {
+			return err
+		}
+		copies = append(copies, &tableCopy{dbClient: dbClient, lastpk: lastpks[tableName]})
+	}
+
+	errs := make([]error, len(tables))
+	var wg sync.WaitGroup
+	for i, tableName := range tables {
+		wg.Add(1)
+		go func(i int, tableName string) {
+			defer wg.Done()
+			if err := vc.copyTableRows(ctx, tableName, copyState, copies[i]); err != nil {
+				errs[i] = vterrors.Wrapf(err, "failed to copy table %q", tableName)
+			}
+		}(i, tableName)
+	}
+	wg.Wait()
+	if err := vterrors.Aggregate(errs); err != nil {
+		return err
+	}
+
+	for i, tc := range copies {
+		if tc.completed && !tc.copiedRows {
+			log.Infof("Completing the copy of %v", tables[i])
+			if err := vc.completeTable(ctx, vc.vr.dbClient, tables[i]); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// withReplicatorClient runs a function that uses the connection of the
+// replicator. Tables that are copied concurrently take turns to use it.
+func (vc *vcopier) withReplicatorClient(tc *tableCopy, fn func() error) error {
+	if tc != nil {
+		vc.replicatorClientMu.Lock()
+		defer vc.replicatorClientMu.Unlock()
+	}
+	return fn()
+}
+
+// encodedLastpk returns the lastpk of the table when the copy started, as a
+// SQL value.
+func (tc *tableCopy) encodedLastpk() string {
+	if tc.lastpk == "" {
+		return "null"
+	}
+	return encodeString(tc.lastpk)
+}
+
+// decodeLastpk decodes a lastpk stored in _vt.copy_state. It returns nil if
+// no rows were copied.
+func decodeLastpk(lastpk string) (*sqltypes.Result, error) {
+	if lastpk == "" {
+		return nil, nil
+	}
+	var r querypb.QueryResult
+	if err := prototext.Unmarshal([]byte(lastpk), &r); err != nil {
+		return nil, err
+	}
+	return sqltypes.Proto3ToResult(&r), nil
+}
+
+// snapshotBounds returns the earliest and the latest positions of the
+// snapshots.
+func snapshotBounds(snapshots map[string]*copySnapshot) (earliest, latest mysql.Position, err error) {
+	first := true
+	for tableName, snapshot := range snapshots {
+		if first {
+			earliest, latest, first = snapshot.pos, snapshot.pos, false
+			continue
+		}
+		switch {
+		case earliest.AtLeast(snapshot.pos):
+			earliest = snapshot.pos
+		case !snapshot.pos.AtLeast(earliest):
+			return earliest, latest, fmt.Errorf("the snapshot position of table %s is not comparable with the other snapshots: %v", tableName, snapshot.pos)
+		}
+		switch {
+		case snapshot.pos.AtLeast(latest):
+			latest = snapshot.pos
+		case !latest.AtLeast(snapshot.pos):
+			return earliest, latest, fmt.Errorf("the snapshot position of table %s is not comparable with the other snapshots: %v", tableName, snapshot.pos)
+		}
+	}
+	return earliest, latest, nil
+}
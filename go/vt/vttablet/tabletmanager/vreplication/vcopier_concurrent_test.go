@@ -0,0 +1,258 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vreplication
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/prototext"
+
+	"vitess.io/vitess/go/mysql"
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/binlog/binlogplayer"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/vstreamer"
+
+	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
+)
+
+var snapshotPosRe = regexp.MustCompile(`^insert into _vt\.copy_state \(lastpk, vrepl_id, table_name, snapshot_pos, snapshot_lastpk\) values \(.*, \d+, '(dst\d)', '([^']*)', `)
+
+// TestPlayerCopyTablesConcurrently copies two tables concurrently from
+// snapshots at different positions, and checks that the catchup starts
+// from the earliest snapshot, that it skips the events that are already
+// part of the snapshot of a table, and that the target is fast forwarded
+// past the latest snapshot.
+func TestPlayerCopyTablesConcurrently(t *testing.T) {
+	defer deleteTablet(addTablet(100))
+
+	reset := vstreamer.AdjustPacketSize(1)
+	defer reset()
+
+	savedParallelTableCopies := vreplicationParallelTableCopies
+	vreplicationParallelTableCopies = 2
+	defer func() { vreplicationParallelTableCopies = savedParallelTableCopies }()
+
+	savedCopyPhaseDuration := copyPhaseDuration
+	// copyPhaseDuration should be low enough to have time to send one row.
+	copyPhaseDuration = 500 * time.Millisecond
+	defer func() { copyPhaseDuration = savedCopyPhaseDuration }()
+
+	execStatements(t, []string{
+		"create table src1(id int, val varbinary(128), primary key(id))",
+		"insert into src1 values(1, 'a'), (2, 'b')",
+		fmt.Sprintf("create table %s.dst1(id int, val varbinary(128), primary key(id))", vrepldb),
+		"create table src2(id int, val varbinary(128), primary key(id))",
+		"insert into src2 values(1, 'a')",
+		fmt.Sprintf("create table %s.dst2(id int, val varbinary(128), primary key(id))", vrepldb),
+	})
+	defer execStatements(t, []string{
+		"drop table src1",
+		fmt.Sprintf("drop table %s.dst1", vrepldb),
+		"drop table src2",
+		fmt.Sprintf("drop table %s.dst2", vrepldb),
+	})
+	env.SchemaEngine.Reload(context.Background())
+
+	// The snapshot of src1 is taken after the one of src2, once src2 has
+	// sent its fields. The changes made in between are part of the snapshot
+	// of src1 but not of the one of src2. The first copy of src1 then stops
+	// after its first row, so that both tables have rows that were copied
+	// before their snapshot when the copy phase catches up.
+	src2Snapshot := make(chan struct{})
+	var src1Calls, src1Sends, src2Sends int
+	vstreamRowsQueryHook = func(ctx context.Context, query string) {
+		if !strings.Contains(query, "src1") {
+			return
+		}
+		defer func() { src1Calls++ }()
+		if src1Calls > 0 {
+			return
+		}
+		<-src2Snapshot
+		execStatements(t, []string{
+			"insert into src2 values(2, 'b')",
+			"update src2 set val='c' where id=1",
+			"update src1 set val='aa' where id=1",
+			"insert into src1 values(3, 'c')",
+		})
+	}
+	vstreamRowsQuerySendHook = func(ctx context.Context, query string) {
+		switch {
+		case strings.Contains(query, "src2"):
+			if src2Sends == 0 {
+				close(src2Snapshot)
+			}
+			src2Sends++
+		case strings.Contains(query, "src1"):
+			defer func() { src1Sends++ }()
+			// Allow the first two calls to go through: field info and one row.
+			if src1Sends != 2 {
+				return
+			}
+			execStatements(t, []string{
+				"update src1 set val='x' where id=1",
+				"update src2 set val='d' where id=1",
+			})
+			// Wait for context to expire and then send the row.
+			// This will cause the copier to abort and go back to catchup mode.
+			<-ctx.Done()
+		}
+	}
+	defer func() {
+		vstreamRowsQueryHook = nil
+		vstreamRowsQuerySendHook = nil
+	}()
+
+	filter := &binlogdatapb.Filter{
+		Rules: []*binlogdatapb.Rule{{
+			Match:  "dst1",
+			Filter: "select * from src1",
+		}, {
+			Match:  "dst2",
+			Filter: "select * from src2",
+		}},
+	}
+	bls := &binlogdatapb.BinlogSource{
+		Keyspace: env.KeyspaceName,
+		Shard:    env.ShardName,
+		Filter:   filter,
+		OnDdl:    binlogdatapb.OnDDLAction_IGNORE,
+	}
+	query := binlogplayer.CreateVReplicationState("test", bls, "", binlogplayer.VReplicationInit, playerEngine.dbName, 0, 0)
+	qr, err := playerEngine.Exec(query)
+	require.NoError(t, err)
+	defer func() {
+		query := fmt.Sprintf("delete from _vt.vreplication where id = %d", qr.InsertID)
+		_, err := playerEngine.Exec(query)
+		require.NoError(t, err)
+		expectDeleteQueries(t)
+	}()
+
+	// Collect the statements executed until the copy phase is done.
+	snapshots := make(map[string]mysql.Position)
+	var positions []mysql.Position
+	var queries []string
+	for done := false; !done; {
+		select {
+		case got := <-globalDBQueries:
+			if m := snapshotPosRe.FindStringSubmatch(got); m != nil {
+				// Only the first snapshot of each table is of interest.
+				if _, ok := snapshots[m[1]]; !ok {
+					pos, err := mysql.DecodePosition(m[2])
+					require.NoError(t, err)
+					snapshots[m[1]] = pos
+				}
+			}
+			if m := updatePosRe.FindStringSubmatch(got); m != nil {
+				pos, err := mysql.DecodePosition(m[1])
+				require.NoError(t, err)
+				positions = append(positions, pos)
+			}
+			queries = append(queries, got)
+			done = strings.HasPrefix(got, "update _vt.vreplication set state='Running'")
+		case <-time.After(5 * time.Second):
+			require.FailNow(t, "the copy phase did not complete", "queries: %v", queries)
+		}
+	}
+
+	// The tables were copied from snapshots at different positions.
+	require.Contains(t, snapshots, "dst1")
+	require.Contains(t, snapshots, "dst2")
+	require.True(t, snapshots["dst1"].AtLeast(snapshots["dst2"]))
+	require.False(t, snapshots["dst1"].Equal(snapshots["dst2"]))
+
+	// The catchup starts from the earliest snapshot, and the target is fast
+	// forwarded past the latest one.
+	require.NotEmpty(t, positions)
+	assert.True(t, positions[0].Equal(snapshots["dst2"]), "first position: %v, want %v", positions[0], snapshots["dst2"])
+	assert.True(t, positions[len(positions)-1].AtLeast(snapshots["dst1"]), "last position: %v, want at least %v", positions[len(positions)-1], snapshots["dst1"])
+
+	// The events between the two snapshots are applied to the row copied
+	// from the snapshot of src2, but not to the one copied from the
+	// snapshot of src1, which already contains them. The events after both
+	// snapshots are applied to both rows.
+	hasQuery := func(prefix string) bool {
+		for _, q := range queries {
+			if strings.HasPrefix(q, prefix) {
+				return true
+			}
+		}
+		return false
+	}
+	assert.True(t, hasQuery("update dst2 set val='c'"), "queries: %v", queries)
+	assert.False(t, hasQuery("update dst1 set val='aa'"), "queries: %v", queries)
+	assert.True(t, hasQuery("update dst1 set val='x'"), "queries: %v", queries)
+	assert.True(t, hasQuery("update dst2 set val='d'"), "queries: %v", queries)
+
+	expectData(t, "dst1", [][]string{
+		{"1", "x"},
+		{"2", "b"},
+		{"3", "c"},
+	})
+	expectData(t, "dst2", [][]string{
+		{"1", "d"},
+		{"2", "b"},
+	})
+}
+
+func TestSnapshotBounds(t *testing.T) {
+	snapshot := func(gtid string) *copySnapshot {
+		pos, err := mysql.DecodePosition(gtid)
+		require.NoError(t, err)
+		return &copySnapshot{pos: pos}
+	}
+
+	earliest, latest, err := snapshotBounds(map[string]*copySnapshot{
+		"t1": snapshot("MySQL56/00000000-0000-0000-0000-000000000001:1-10"),
+		"t2": snapshot("MySQL56/00000000-0000-0000-0000-000000000001:1-30"),
+		"t3": snapshot("MySQL56/00000000-0000-0000-0000-000000000001:1-20"),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "MySQL56/00000000-0000-0000-0000-000000000001:1-10", mysql.EncodePosition(earliest))
+	assert.Equal(t, "MySQL56/00000000-0000-0000-0000-000000000001:1-30", mysql.EncodePosition(latest))
+
+	_, _, err = snapshotBounds(map[string]*copySnapshot{
+		"t1": snapshot("MySQL56/00000000-0000-0000-0000-000000000001:1-10"),
+		"t2": snapshot("MySQL56/00000000-0000-0000-0000-000000000002:1-10"),
+	})
+	assert.ErrorContains(t, err, "not comparable")
+}
+
+func TestDecodeLastpk(t *testing.T) {
+	lastpk, err := decodeLastpk("")
+	require.NoError(t, err)
+	assert.Nil(t, lastpk)
+
+	want := sqltypes.MakeTestResult(sqltypes.MakeTestFields("id", "int64"), "10")
+	encoded, err := prototext.Marshal(sqltypes.ResultToProto3(want))
+	require.NoError(t, err)
+	lastpk, err = decodeLastpk(string(encoded))
+	require.NoError(t, err)
+	assert.Equal(t, want.Rows, lastpk.Rows)
+
+	tc := &tableCopy{}
+	assert.Equal(t, "null", tc.encodedLastpk())
+	tc.lastpk = string(encoded)
+	assert.Equal(t, encodeString(string(encoded)), tc.encodedLastpk())
+}
@@ -1830,3 +1830,23 @@ func testCopyInvisibleColumns(t *testing.T) {
 		{"2", "20", "200", "2000"},
 	})
 }
+
+func TestIsKeyRangeFilter(t *testing.T) {
+	testCases := []struct {
+		filter string
+		want   bool
+	}{
+		{"", true},
+		{"-80", true},
+		{"select * from t1", true},
+		{"select id, val from t1", true},
+		{"select * from t1 where in_keyrange('-80')", true},
+		{"select * from t1 where in_keyrange(id, 'hash', '-80') and in_keyrange(c1, 'hash', '40-')", true},
+		{"select * from t1 where id > 10", false},
+		{"select * from t1 where in_keyrange('-80') and id > 10", false},
+		{"not a query", false},
+	}
+	for _, tc := range testCases {
+		require.Equal(t, tc.want, isKeyRangeFilter(tc.filter), tc.filter)
+	}
+}
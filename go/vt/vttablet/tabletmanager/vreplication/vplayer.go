@@ -68,6 +68,17 @@ type vplayer struct {
 	phase string
 
 	throttlerAppName string
+
+	// parallel is set if the transactions are applied concurrently.
+	parallel *parallelApplier
+
+	// copySnapshots are the snapshots of the tables that were copied
+	// concurrently, by target table. Events that are already part of a
+	// snapshot are only applied to the rows copied before it, using the
+	// plans of snapshotPlan.
+	copySnapshots      map[string]*copySnapshot
+	snapshotPlan       *ReplicatorPlan
+	snapshotTablePlans map[string]*snapshotTablePlan
 }
 
 // newVPlayer creates a new vplayer. Parameters:
@@ -120,6 +131,20 @@ func (vp *vplayer) play(ctx context.Context) error {
 		return err
 	}
 	vp.replicatorPlan = plan
+	if len(vp.copySnapshots) > 0 {
+		snapshotState := make(map[string]*sqltypes.Result, len(vp.copyState))
+		for tableName, lastpk := range vp.copyState {
+			snapshotState[tableName] = lastpk
+		}
+		for tableName, snapshot := range vp.copySnapshots {
+			snapshotState[tableName] = snapshot.lastpk
+		}
+		if vp.snapshotPlan, err = buildReplicatorPlan(vp.vr.source, vp.vr.colInfoMap, snapshotState, vp.vr.stats); err != nil {
+			vp.vr.stats.ErrorCounts.Add([]string{"Plan"}, 1)
+			return err
+		}
+		vp.snapshotTablePlans = make(map[string]*snapshotTablePlan)
+	}
 
 	// We can't run in statement mode if there are filters defined.
 	vp.canAcceptStmtEvents = true
@@ -149,11 +174,30 @@ func (vp *vplayer) fetchAndApply(ctx context.Context) (err error) {
 	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
 
-	relay := newRelayLog(ctx, relayLogMaxItems, relayLogMaxSize)
+	// The disk relay log is only used in the replication phase, because the
+	// other phases stream up to a position that changes between runs.
+	var relay eventRelay = newRelayLog(ctx, relayLogMaxItems, relayLogMaxSize)
+	streamPos := vp.startPos
+	if relayLogDir != "" && len(vp.copyState) == 0 && vp.stopPos.IsZero() {
+		diskRelay, pos, err := openDiskRelayLog(ctx, diskRelayLogDir(vp.vr.dbClient.DBName(), vp.vr.id), vp.replicatorPlan.VStreamFilter, vp.startPos, relayLogMaxItems, relayLogMaxSize, relayLogMaxDiskSize)
+		if err != nil {
+			return fmt.Errorf("error %v opening the relay log", err)
+		}
+		defer diskRelay.Close()
+		relay, streamPos = diskRelay, pos
+	}
+
+	// Parallel apply is only used in the replication phase: the catchup and
+	// fast forward phases, and streams that stop at a position, apply their
+	// transactions serially.
+	if vreplicationParallelApplyWorkers > 1 && len(vp.copyState) == 0 && vp.stopPos.IsZero() {
+		vp.parallel = newParallelApplier(vp, vreplicationParallelApplyWorkers)
+		defer vp.parallel.close()
+	}
 
 	streamErr := make(chan error, 1)
 	go func() {
-		streamErr <- vp.vr.sourceVStreamer.VStream(ctx, mysql.EncodePosition(vp.startPos), nil, vp.replicatorPlan.VStreamFilter, func(events []*binlogdatapb.VEvent) error {
+		streamErr <- vp.vr.sourceVStreamer.VStream(ctx, mysql.EncodePosition(streamPos), nil, vp.replicatorPlan.VStreamFilter, func(events []*binlogdatapb.VEvent) error {
 			return relay.Send(events)
 		})
 	}()
@@ -217,15 +261,31 @@ func (vp *vplayer) applyStmtEvent(ctx context.Context, event *binlogdatapb.VEven
 }
 
 func (vp *vplayer) applyRowEvent(ctx context.Context, rowEvent *binlogdatapb.RowEvent) error {
+	if err := vp.vr.schedule.waitForRowChanges(ctx, rowEvent); err != nil {
+		return err
+	}
 	tplan := vp.tablePlans[rowEvent.TableName]
 	if tplan == nil {
 		return fmt.Errorf("unexpected event on table %s", rowEvent.TableName)
 	}
+	if splan := vp.snapshotTablePlans[rowEvent.TableName]; splan != nil && splan.pos.AtLeast(vp.pos) {
+		if splan.plan == nil {
+			return nil
+		}
+		tplan = splan.plan
+	}
+	return vp.applyRowChanges(tplan, rowEvent, func(sql string) (*sqltypes.Result, error) {
+		return vp.vr.dbClient.ExecuteWithRetry(ctx, sql)
+	})
+}
+
+// applyRowChanges applies the row changes of the event with the given executor.
+func (vp *vplayer) applyRowChanges(tplan *TablePlan, rowEvent *binlogdatapb.RowEvent, execute func(string) (*sqltypes.Result, error)) error {
 	for _, change := range rowEvent.RowChanges {
 		_, err := tplan.applyChange(change, func(sql string) (*sqltypes.Result, error) {
 			stats := NewVrLogStats("ROWCHANGE")
 			start := time.Now()
-			qr, err := vp.vr.dbClient.ExecuteWithRetry(ctx, sql)
+			qr, err := execute(sql)
 			vp.vr.stats.QueryCount.Add(vp.phase, 1)
 			vp.vr.stats.QueryTimings.Record(vp.phase, start)
 			stats.Send(sql)
@@ -322,7 +382,7 @@ func (vp *vplayer) recordHeartbeat() error {
 // this from becoming a tight loop.
 // TODO(sougou): we can look at recognizing self-generated events and find a better
 // way to handle them.
-func (vp *vplayer) applyEvents(ctx context.Context, relay *relayLog) error {
+func (vp *vplayer) applyEvents(ctx context.Context, relay eventRelay) error {
 	defer vp.vr.dbClient.Rollback()
 
 	// If we're not running, set ReplicationLagSeconds to be very high.
@@ -375,6 +435,16 @@ func (vp *vplayer) applyEvents(ctx context.Context, relay *relayLog) error {
 					vp.timeOffsetNs = time.Now().UnixNano() - event.CurrentTime
 					sbm = event.CurrentTime/1e9 - event.Timestamp
 				}
+				if vp.parallel != nil {
+					if err := vp.parallel.applyEvent(ctx, event); err != nil {
+						if err != io.EOF {
+							vp.vr.stats.ErrorCounts.Add([]string{"Apply"}, 1)
+							log.Errorf("Error applying event: %s", err.Error())
+						}
+						return err
+					}
+					continue
+				}
 				mustSave := false
 				switch event.Type {
 				case binlogdatapb.VEventType_COMMIT:
@@ -477,6 +547,15 @@ func (vp *vplayer) applyEvent(ctx context.Context, event *binlogdatapb.VEvent, m
 			return err
 		}
 		vp.tablePlans[event.FieldEvent.TableName] = tplan
+		if snapshot := vp.copySnapshots[tplan.TargetName]; snapshot != nil {
+			splan := &snapshotTablePlan{pos: snapshot.pos}
+			if vp.snapshotPlan.TablePlans[event.FieldEvent.TableName] != nil {
+				if splan.plan, err = vp.snapshotPlan.buildExecutionPlan(event.FieldEvent); err != nil {
+					return err
+				}
+			}
+			vp.snapshotTablePlans[event.FieldEvent.TableName] = splan
+		}
 		stats.Send(fmt.Sprintf("%v", event.FieldEvent))
 
 	case binlogdatapb.VEventType_INSERT, binlogdatapb.VEventType_DELETE, binlogdatapb.VEventType_UPDATE,
@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"math"
 	"os"
+	"regexp"
 	"strconv"
 	"strings"
 	"sync"
@@ -881,8 +882,7 @@ func TestPlayerFilters(t *testing.T) {
 		input: "update nopk set val='bbb' where id=1",
 		output: qh.Expect(
 			"begin",
-			"delete from nopk where id=1 and val='aaa'",
-			"insert into nopk(id,val) values (1,'bbb')",
+			"update nopk set id=1, val='bbb' where id<=>1 and val<=>'aaa' limit 1",
 			"/update _vt.vreplication set pos=",
 			"commit",
 		),
@@ -895,7 +895,7 @@ func TestPlayerFilters(t *testing.T) {
 		input: "delete from nopk where id=1",
 		output: qh.Expect(
 			"begin",
-			"delete from nopk where id=1 and val='bbb'",
+			"delete from nopk where id<=>1 and val<=>'bbb' limit 1",
 			"/update _vt.vreplication set pos=",
 			"commit",
 		),
@@ -3040,6 +3040,158 @@ func TestPlayerNoBlob(t *testing.T) {
 	require.Equal(t, int64(4), stats.PartialQueryCount.Counts()["update"])
 }
 
+// TestPlayerParallelApply confirms that transactions applied in parallel end
+// up with the same data as the source, including transactions that depend on
+// each other and ones that have to be applied serially, and that the position
+// of the last transaction is saved.
+func TestPlayerParallelApply(t *testing.T) {
+	oldParallelApplyWorkers := vreplicationParallelApplyWorkers
+	vreplicationParallelApplyWorkers = 4
+	defer func() {
+		vreplicationParallelApplyWorkers = oldParallelApplyWorkers
+	}()
+
+	defer deleteTablet(addTablet(100))
+	execStatements(t, []string{
+		"create table t1(id int, val varchar(20), primary key(id))",
+		fmt.Sprintf("create table %s.t1(id int, val varchar(20), primary key(id))", vrepldb),
+		"create table nopk(id int, val varchar(20))",
+		fmt.Sprintf("create table %s.nopk(id int, val varchar(20))", vrepldb),
+		"create table uk(id int, code varchar(20), primary key(id), unique key(code))",
+		fmt.Sprintf("create table %s.uk(id int, code varchar(20), primary key(id), unique key(code))", vrepldb),
+	})
+	defer execStatements(t, []string{
+		"drop table t1",
+		fmt.Sprintf("drop table %s.t1", vrepldb),
+		"drop table nopk",
+		fmt.Sprintf("drop table %s.nopk", vrepldb),
+		"drop table uk",
+		fmt.Sprintf("drop table %s.uk", vrepldb),
+	})
+	env.SchemaEngine.Reload(context.Background())
+
+	filter := &binlogdatapb.Filter{
+		Rules: []*binlogdatapb.Rule{{
+			Match: "/.*",
+		}},
+	}
+	bls := &binlogdatapb.BinlogSource{
+		Keyspace: env.KeyspaceName,
+		Shard:    env.ShardName,
+		Filter:   filter,
+		OnDdl:    binlogdatapb.OnDDLAction_IGNORE,
+	}
+	cancel, vrID := startVReplication(t, bls, "")
+	defer cancel()
+
+	execStatements(t, []string{
+		"insert into t1 values(1, 'a')",
+		"insert into t1 values(2, 'b')",
+		"update t1 set val='aa' where id=1",
+		"insert into nopk values(1, 'a')",
+		"delete from t1 where id=2",
+		"insert into t1 values(3, 'c')",
+		"update t1 set id=4 where id=3",
+		"update t1 set val='aaa' where id=1",
+		"insert into uk values(1, 'x')",
+		"update uk set code='y' where id=1",
+		"insert into uk values(2, 'x')",
+		"insert into nopk values(2, 'b')",
+	})
+	expectData(t, "t1", [][]string{
+		{"1", "aaa"},
+		{"4", "c"},
+	})
+	expectData(t, "nopk", [][]string{
+		{"1", "a"},
+		{"2", "b"},
+	})
+	expectData(t, "uk", [][]string{
+		{"1", "y"},
+		{"2", "x"},
+	})
+
+	ctx, cancelWait := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancelWait()
+	require.NoError(t, playerEngine.WaitForPos(ctx, int32(vrID), primaryPosition(t)))
+
+	// The statements of independent transactions are applied in any order,
+	// so they're collected before they're checked.
+	var applied []string
+	var positions []mysql.Position
+	for len(globalDBQueries) > 0 {
+		query := <-globalDBQueries
+		switch {
+		case shouldIgnoreQuery(query), query == "begin", query == "commit":
+		case strings.HasPrefix(query, "update _vt.vreplication set pos="):
+			match := updatePosRe.FindStringSubmatch(query)
+			require.NotNil(t, match, query)
+			pos, err := mysql.DecodePosition(match[1])
+			require.NoError(t, err)
+			positions = append(positions, pos)
+		default:
+			applied = append(applied, query)
+		}
+	}
+	require.ElementsMatch(t, []string{
+		"insert into t1(id,val) values (1,'a')",
+		"insert into t1(id,val) values (2,'b')",
+		"update t1 set val='aa' where id=1",
+		"insert into nopk(id,val) values (1,'a')",
+		"delete from t1 where id=2",
+		"insert into t1(id,val) values (3,'c')",
+		"delete from t1 where id=3",
+		"insert into t1(id,val) values (4,'c')",
+		"update t1 set val='aaa' where id=1",
+		"insert into uk(id,code) values (1,'x')",
+		"update uk set code='y' where id=1",
+		"insert into uk(id,code) values (2,'x')",
+		"insert into nopk(id,val) values (2,'b')",
+	}, applied)
+
+	// Transactions that change the same rows, or the same unique key values,
+	// or tables without a primary key, are applied in the order of the source.
+	index := func(query string) int {
+		for i, q := range applied {
+			if q == query {
+				return i
+			}
+		}
+		return -1
+	}
+	for _, ordered := range [][]string{{
+		"insert into t1(id,val) values (1,'a')",
+		"update t1 set val='aa' where id=1",
+		"update t1 set val='aaa' where id=1",
+	}, {
+		"insert into t1(id,val) values (2,'b')",
+		"delete from t1 where id=2",
+	}, {
+		"insert into t1(id,val) values (3,'c')",
+		"delete from t1 where id=3",
+		"insert into t1(id,val) values (4,'c')",
+	}, {
+		"insert into uk(id,code) values (1,'x')",
+		"update uk set code='y' where id=1",
+		"insert into uk(id,code) values (2,'x')",
+	}, {
+		"insert into nopk(id,val) values (1,'a')",
+		"insert into nopk(id,val) values (2,'b')",
+	}} {
+		for i := 1; i < len(ordered); i++ {
+			require.Less(t, index(ordered[i-1]), index(ordered[i]), "%s must be applied before %s", ordered[i-1], ordered[i])
+		}
+	}
+
+	// Every transaction commits its position, in the order of the source.
+	require.Len(t, positions, 13)
+	for i := 1; i < len(positions); i++ {
+		require.True(t, positions[i].AtLeast(positions[i-1]) && !positions[i].Equal(positions[i-1]), "position %v is committed after %v", positions[i], positions[i-1])
+	}
+}
+
+var updatePosRe = regexp.MustCompile(`^update _vt.vreplication set pos='([^']*)'`)
+
 func expectJSON(t *testing.T, table string, values [][]string, id int, exec func(ctx context.Context, query string) (*sqltypes.Result, error)) {
 	t.Helper()
 
@@ -0,0 +1,637 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vreplication
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"vitess.io/vitess/go/mysql"
+	"vitess.io/vitess/go/mysql/collations"
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/binlog/binlogplayer"
+
+	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
+	querypb "vitess.io/vitess/go/vt/proto/query"
+)
+
+const (
+	// maxWritesetHistory is the number of row keys the parallel applier
+	// remembers before it forgets the ones of the transactions that are
+	// already committed.
+	maxWritesetHistory = 100000
+
+	sqlSelectUniqueKeys = `select table_name, index_name, column_name from information_schema.statistics
+	where table_schema=%s and non_unique=0 and index_name!='PRIMARY' order by table_name, index_name, seq_in_index`
+	sqlSelectForeignKeyTables = `select table_name from information_schema.key_column_usage
+	where table_schema=%s and referenced_table_name is not null
+	union select referenced_table_name from information_schema.key_column_usage
+	where referenced_table_schema=%s and referenced_table_name is not null`
+)
+
+// parallelApplier applies the transactions of a vplayer concurrently on
+// several connections.
+//
+// A transaction only waits for the earlier transactions it depends on before
+// it applies its rows, so independent transactions are applied at the same
+// time. Dependencies come from two sources:
+//   - The writeset of a transaction is the set of keys of the rows it
+//     changes: their primary key and the values of their unique keys in the
+//     target table. Transactions whose writesets intersect are dependent.
+//   - The logical clock of the source, when it has one (MySQL 5.7 and later):
+//     a transaction depends on the earlier transactions whose sequence number
+//     is not greater than its last committed. The rows of a transaction whose
+//     writeset can't be computed, because their table has no primary key or
+//     their row images are partial, are keyed by their table instead, so the
+//     transaction is applied in parallel with the transactions on the other
+//     tables that the clock says are independent.
+//
+// Commits still happen in the order of the source: each transaction waits
+// for the previous one to commit, and then saves its own position and commit
+// timestamp in _vt.vreplication as part of its commit. So the saved position
+// is always consistent with the data, as with serial apply.
+//
+// Transactions on tables that have foreign keys or are referenced by one,
+// transactions whose writeset can't be computed if the source has no logical
+// clock, statements, empty transactions, DDLs, journals and other events are
+// applied serially by the vplayer after all the running transactions are
+// committed.
+type parallelApplier struct {
+	vp          *vplayer
+	parallelism int
+
+	// pending holds the events of the current transaction until its commit.
+	pending []*binlogdatapb.VEvent
+	// serial is set while the rest of the current transaction is applied
+	// serially by the vplayer.
+	serial bool
+	// pos is the position of the last GTID event seen.
+	pos mysql.Position
+	// lastCommitted and sequenceNumber are the logical clock of the last
+	// GTID event seen. clockReset is set if its sequence number doesn't
+	// follow the previous one, because the source has no logical clock or
+	// rotated its binary log.
+	lastCommitted  int64
+	sequenceNumber int64
+	clockReset     bool
+
+	// keys holds the unique keys of the target tables, and whether they
+	// are related by foreign keys. It's loaded when the first transaction
+	// is applied, and again after every DDL.
+	keys map[string]*targetKeys
+
+	// lastWriters maps the rows changed by the running transactions to the
+	// last transaction that changed them.
+	lastWriters map[string]*parallelTxn
+	// clocked are the running transactions that have a logical clock, in
+	// the order they were started.
+	clocked []*parallelTxn
+	// unkeyed is the last running transaction whose writeset is incomplete.
+	unkeyed *parallelTxn
+	// last is the last transaction that was started.
+	last *parallelTxn
+
+	workers chan *vdbClient
+	created int
+	running sync.WaitGroup
+	errMu   sync.Mutex
+	err     error
+	conns   []*vdbClient
+}
+
+// parallelTxn is a transaction applied by the parallelApplier.
+type parallelTxn struct {
+	pos       mysql.Position
+	timestamp int64
+	rows      []*parallelRows
+	writeset  []string
+	// unkeyed is set if the writeset doesn't contain all the rows changed
+	// by the transaction, which can then only be applied in parallel using
+	// its logical clock.
+	unkeyed bool
+	// lastCommitted and sequenceNumber are the logical clock of the
+	// transaction, if the source has one.
+	lastCommitted  int64
+	sequenceNumber int64
+	clockReset     bool
+	// deps are the earlier transactions this one depends on.
+	deps []*parallelTxn
+	// prev is the transaction that has to commit before this one.
+	prev *parallelTxn
+	// done is closed once the transaction is committed or failed.
+	done chan struct{}
+	err  error
+}
+
+// targetKeys are the keys of a target table that are used to compute the
+// writesets of its row changes, besides its primary key.
+type targetKeys struct {
+	// uniqueKeys are the columns of the unique keys of the table, by name.
+	uniqueKeys map[string][]string
+	// serial is set if the table has foreign keys or is referenced by one,
+	// or if one of its unique keys is on an expression rather than columns.
+	serial bool
+}
+
+// parallelRows are the row changes of a transaction on a table, along with
+// the plan of the table when the changes were received.
+type parallelRows struct {
+	plan     *TablePlan
+	rowEvent *binlogdatapb.RowEvent
+}
+
+func newParallelApplier(vp *vplayer, parallelism int) *parallelApplier {
+	return &parallelApplier{
+		vp:          vp,
+		parallelism: parallelism,
+		pos:         vp.pos,
+		lastWriters: make(map[string]*parallelTxn),
+		workers:     make(chan *vdbClient, parallelism),
+	}
+}
+
+// applyEvent buffers the events of a transaction until its commit, and
+// starts applying it then. Events that can't be applied in parallel are
+// applied serially once the running transactions are committed.
+func (pa *parallelApplier) applyEvent(ctx context.Context, event *binlogdatapb.VEvent) error {
+	if err := pa.failure(); err != nil {
+		return err
+	}
+	switch event.Type {
+	case binlogdatapb.VEventType_GTID:
+		pos, err := binlogplayer.DecodePosition(event.Gtid)
+		if err != nil {
+			return err
+		}
+		pa.pos = pos
+		pa.clockReset = event.SequenceNumber == 0 || event.SequenceNumber <= pa.sequenceNumber
+		pa.lastCommitted = event.LastCommitted
+		pa.sequenceNumber = event.SequenceNumber
+	case binlogdatapb.VEventType_DDL:
+		pa.keys = nil
+	}
+	if pa.serial {
+		return pa.applySerially(ctx, event)
+	}
+
+	switch event.Type {
+	case binlogdatapb.VEventType_HEARTBEAT:
+		return pa.vp.applyEvent(ctx, event, false)
+	case binlogdatapb.VEventType_GTID, binlogdatapb.VEventType_BEGIN, binlogdatapb.VEventType_ROW:
+		pa.pending = append(pa.pending, event)
+		return nil
+	case binlogdatapb.VEventType_FIELD:
+		// The plan is built right away so that the transaction knows the
+		// plans of its tables, but the event is kept in case the transaction
+		// has to be applied serially.
+		tplan, err := pa.vp.replicatorPlan.buildExecutionPlan(event.FieldEvent)
+		if err != nil {
+			return err
+		}
+		pa.vp.tablePlans[event.FieldEvent.TableName] = tplan
+		pa.pending = append(pa.pending, event)
+		return nil
+	case binlogdatapb.VEventType_COMMIT:
+		txn, err := pa.newTxn(ctx, event)
+		if err != nil {
+			return err
+		}
+		if txn == nil {
+			return pa.applySerially(ctx, event)
+		}
+		pa.pending = nil
+		return pa.start(ctx, txn)
+	}
+	return pa.applySerially(ctx, event)
+}
+
+// applySerially waits for the running transactions to commit, and applies
+// the pending events and the given event with the vplayer. The rest of the
+// transaction is then applied serially too.
+func (pa *parallelApplier) applySerially(ctx context.Context, event *binlogdatapb.VEvent) error {
+	if err := pa.drain(ctx); err != nil {
+		return err
+	}
+	pending := pa.pending
+	pa.pending = nil
+	for _, ev := range append(pending, event) {
+		if err := pa.vp.applyEvent(ctx, ev, false); err != nil {
+			return err
+		}
+	}
+	pa.serial = pa.vp.vr.dbClient.InTransaction
+	return nil
+}
+
+// newTxn returns the transaction of the pending events, or nil if they have
+// to be applied serially.
+func (pa *parallelApplier) newTxn(ctx context.Context, commit *binlogdatapb.VEvent) (*parallelTxn, error) {
+	if pa.keys == nil {
+		keys, err := pa.loadTargetKeys(ctx)
+		if err != nil {
+			return nil, err
+		}
+		pa.keys = keys
+	}
+
+	txn := &parallelTxn{
+		pos:            pa.pos,
+		timestamp:      commit.Timestamp,
+		lastCommitted:  pa.lastCommitted,
+		sequenceNumber: pa.sequenceNumber,
+		clockReset:     pa.clockReset,
+		done:           make(chan struct{}),
+	}
+	for _, event := range pa.pending {
+		if event.Type != binlogdatapb.VEventType_ROW {
+			continue
+		}
+		tplan := pa.vp.tablePlans[event.RowEvent.TableName]
+		if tplan == nil {
+			return nil, nil
+		}
+		tkeys := pa.keys[tplan.TargetName]
+		if tkeys != nil && tkeys.serial {
+			return nil, nil
+		}
+		keys, ok := rowEventWriteset(tplan, tkeys, event.RowEvent)
+		if !ok {
+			if txn.sequenceNumber == 0 {
+				return nil, nil
+			}
+			// The rows are keyed by their table instead: changes to
+			// tables without a key lock all the rows they scan, so
+			// they're not applied concurrently with each other.
+			txn.unkeyed = true
+			keys = []string{tableKey(tplan)}
+		}
+		txn.rows = append(txn.rows, &parallelRows{plan: tplan, rowEvent: event.RowEvent})
+		txn.writeset = append(txn.writeset, keys...)
+	}
+	if len(txn.rows) == 0 {
+		return nil, nil
+	}
+	return txn, nil
+}
+
+// loadTargetKeys returns the unique keys of the target tables, and the
+// tables that have to be applied serially because of their foreign keys.
+func (pa *parallelApplier) loadTargetKeys(ctx context.Context) (map[string]*targetKeys, error) {
+	vr := pa.vp.vr
+	dbName := encodeString(vr.dbClient.DBName())
+	keys := make(map[string]*targetKeys)
+	tableKeys := func(table string) *targetKeys {
+		if keys[table] == nil {
+			keys[table] = &targetKeys{uniqueKeys: make(map[string][]string)}
+		}
+		return keys[table]
+	}
+
+	query := fmt.Sprintf(sqlSelectUniqueKeys, dbName)
+	qr, err := vr.mysqld.FetchSuperQuery(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range qr.Rows {
+		tk := tableKeys(row[0].ToString())
+		if row[2].IsNull() {
+			tk.serial = true
+			continue
+		}
+		index := row[1].ToString()
+		tk.uniqueKeys[index] = append(tk.uniqueKeys[index], row[2].ToString())
+	}
+
+	query = fmt.Sprintf(sqlSelectForeignKeyTables, dbName, dbName)
+	qr, err = vr.mysqld.FetchSuperQuery(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range qr.Rows {
+		tableKeys(row[0].ToString()).serial = true
+	}
+	return keys, nil
+}
+
+// start finds the dependencies of the transaction, and starts applying it
+// as soon as a connection is available.
+func (pa *parallelApplier) start(ctx context.Context, txn *parallelTxn) error {
+	dbClient, err := pa.worker(ctx)
+	if err != nil {
+		return err
+	}
+	pa.trackDependencies(txn)
+	txn.prev = pa.last
+	pa.last = txn
+	// The transaction saves a later position than any unsaved event.
+	pa.vp.unsavedEvent = nil
+
+	pa.running.Add(1)
+	go func() {
+		defer pa.running.Done()
+		defer func() { pa.workers <- dbClient }()
+		txn.err = pa.apply(ctx, dbClient, txn)
+		if txn.err != nil {
+			_ = dbClient.Rollback()
+			pa.fail(txn.err)
+		}
+		close(txn.done)
+	}()
+	return nil
+}
+
+// trackDependencies sets the dependencies of the transaction to the last
+// earlier transactions that changed the same rows, and to the last earlier
+// transaction it depends on according to the logical clock of the source.
+// Since transactions commit in order, waiting for the last one is enough.
+func (pa *parallelApplier) trackDependencies(txn *parallelTxn) {
+	if len(pa.lastWriters) > maxWritesetHistory {
+		for key, writer := range pa.lastWriters {
+			if writer.isDone() {
+				delete(pa.lastWriters, key)
+			}
+		}
+	}
+	seen := make(map[*parallelTxn]bool)
+	addDep := func(dep *parallelTxn) {
+		if dep != nil && !seen[dep] {
+			seen[dep] = true
+			txn.deps = append(txn.deps, dep)
+		}
+	}
+	for _, key := range txn.writeset {
+		addDep(pa.lastWriters[key])
+		pa.lastWriters[key] = txn
+	}
+
+	for len(pa.clocked) > 0 && pa.clocked[0].isDone() {
+		pa.clocked = pa.clocked[1:]
+	}
+	switch {
+	case txn.sequenceNumber == 0:
+		// Without a clock, only the writesets tell the dependencies apart,
+		// and they don't cover the rows of the unkeyed transactions.
+		addDep(pa.unkeyed)
+	case txn.clockReset:
+		// The clock started over, so it can't be compared with the clock
+		// of the running transactions.
+		addDep(pa.last)
+		pa.clocked = nil
+	default:
+		for i := len(pa.clocked) - 1; i >= 0; i-- {
+			if pa.clocked[i].sequenceNumber <= txn.lastCommitted {
+				addDep(pa.clocked[i])
+				break
+			}
+		}
+	}
+	if txn.sequenceNumber != 0 {
+		pa.clocked = append(pa.clocked, txn)
+	}
+	if txn.unkeyed {
+		pa.unkeyed = txn
+	}
+}
+
+// worker returns an idle connection, or opens a new one if there are fewer
+// than the configured number of connections.
+func (pa *parallelApplier) worker(ctx context.Context) (*vdbClient, error) {
+	select {
+	case dbClient := <-pa.workers:
+		return dbClient, nil
+	default:
+	}
+	if pa.created < pa.parallelism {
+		dbClient, err := pa.vp.vr.newClientConnection(ctx)
+		if err != nil {
+			return nil, err
+		}
+		pa.created++
+		pa.conns = append(pa.conns, dbClient)
+		return dbClient, nil
+	}
+	select {
+	case dbClient := <-pa.workers:
+		return dbClient, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// apply applies the rows of the transaction once its dependencies are
+// committed, and commits it along with its position after the previous
+// transaction.
+func (pa *parallelApplier) apply(ctx context.Context, dbClient *vdbClient, txn *parallelTxn) error {
+	for _, dep := range txn.deps {
+		if err := dep.wait(ctx); err != nil {
+			return err
+		}
+	}
+	if err := dbClient.Begin(); err != nil {
+		return err
+	}
+	// Lock errors are not retried: a lock held by a later transaction that
+	// waits for this one to commit would never be released. The stream is
+	// restarted from the saved position instead.
+	for _, rows := range txn.rows {
+		if err := pa.vp.vr.schedule.waitForRowChanges(ctx, rows.rowEvent); err != nil {
+			return err
+		}
+		if err := pa.vp.applyRowChanges(rows.plan, rows.rowEvent, dbClient.Execute); err != nil {
+			return err
+		}
+	}
+	if txn.prev != nil {
+		if err := txn.prev.wait(ctx); err != nil {
+			return err
+		}
+	}
+	vr := pa.vp.vr
+	update := binlogplayer.GenerateUpdatePos(vr.id, txn.pos, time.Now().Unix(), txn.timestamp, vr.stats.CopyRowCount.Get(), vreplicationStoreCompressedGTID)
+	if _, err := dbClient.Execute(update); err != nil {
+		return fmt.Errorf("error %v updating position", err)
+	}
+	if err := dbClient.Commit(); err != nil {
+		return err
+	}
+	vr.stats.SetLastPosition(txn.pos)
+	return nil
+}
+
+// drain waits for the running transactions to commit, and brings the
+// position of the vplayer up to date.
+func (pa *parallelApplier) drain(ctx context.Context) error {
+	if pa.last == nil {
+		return nil
+	}
+	if err := pa.last.wait(ctx); err != nil {
+		return err
+	}
+	pa.vp.pos = pa.last.pos
+	pa.vp.unsavedEvent = nil
+	pa.vp.timeLastSaved = time.Now()
+	pa.last = nil
+	pa.lastWriters = make(map[string]*parallelTxn)
+	pa.clocked = nil
+	pa.unkeyed = nil
+	return nil
+}
+
+// close waits for the running transactions to end, and closes the
+// connections.
+func (pa *parallelApplier) close() {
+	pa.running.Wait()
+	for _, dbClient := range pa.conns {
+		dbClient.Close()
+	}
+}
+
+func (pa *parallelApplier) fail(err error) {
+	pa.errMu.Lock()
+	defer pa.errMu.Unlock()
+	if pa.err == nil {
+		pa.err = err
+	}
+}
+
+// failure returns the error of the first transaction that failed.
+func (pa *parallelApplier) failure() error {
+	pa.errMu.Lock()
+	defer pa.errMu.Unlock()
+	return pa.err
+}
+
+// wait waits for the transaction to be committed, and returns its error if
+// it failed.
+func (txn *parallelTxn) wait(ctx context.Context) error {
+	select {
+	case <-txn.done:
+		return txn.err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (txn *parallelTxn) isDone() bool {
+	select {
+	case <-txn.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// primaryKeyName is the name of the primary key in the writesets.
+const primaryKeyName = "PRIMARY"
+
+// rowEventWriteset returns the keys of the rows changed by the row event,
+// before and after the change: their primary key, and the values of the
+// unique keys of the target table. It returns false if the rows can't be
+// identified, because the table has no primary key or equivalent unique key,
+// a unique key column isn't replicated or the row images are partial.
+func rowEventWriteset(tplan *TablePlan, tkeys *targetKeys, rowEvent *binlogdatapb.RowEvent) ([]string, bool) {
+	// Rows that are keyed by all their columns are not necessarily unique,
+	// and are updated and deleted with table scans.
+	if len(tplan.PKReferences) == 0 || tplan.FullRowKey {
+		return nil, false
+	}
+	keyColumns := map[string][]string{primaryKeyName: tplan.PKReferences}
+	if tkeys != nil {
+		for name, columns := range tkeys.uniqueKeys {
+			keyColumns[name] = columns
+		}
+	}
+	keyIndices := make(map[string][]int, len(keyColumns))
+	for name, columns := range keyColumns {
+		indices := make([]int, 0, len(columns))
+		for _, column := range columns {
+			idx := -1
+			for i, field := range tplan.Fields {
+				if strings.EqualFold(field.Name, column) {
+					idx = i
+					break
+				}
+			}
+			if idx == -1 {
+				return nil, false
+			}
+			indices = append(indices, idx)
+		}
+		keyIndices[name] = indices
+	}
+
+	var keys []string
+	for _, change := range rowEvent.RowChanges {
+		if tplan.isPartial(change) {
+			return nil, false
+		}
+		for _, row := range []*querypb.Row{change.Before, change.After} {
+			if row == nil {
+				continue
+			}
+			values := sqltypes.MakeRowTrusted(tplan.Fields, row)
+			for name, indices := range keyIndices {
+				if key, ok := rowKey(tplan, name, indices, values); ok {
+					keys = append(keys, key)
+				}
+			}
+		}
+	}
+	return keys, true
+}
+
+// rowKey returns the key of a row in one of the keys of its table. Text
+// values are keyed by their weight strings, so that values that are equal in
+// their collation have the same key. It returns false if one of the values is
+// NULL, since such rows don't conflict with any other row in a unique key.
+func rowKey(tplan *TablePlan, keyName string, indices []int, values []sqltypes.Value) (string, bool) {
+	var key strings.Builder
+	writeKeyPart(&key, []byte(tplan.TargetName))
+	writeKeyPart(&key, []byte(keyName))
+	for _, idx := range indices {
+		if values[idx].IsNull() {
+			return "", false
+		}
+		raw := values[idx].Raw()
+		if values[idx].IsText() {
+			if coll := collations.ID(tplan.Fields[idx].Charset).Get(); coll != nil {
+				raw = coll.WeightString(nil, raw, 0)
+			}
+		}
+		writeKeyPart(&key, raw)
+	}
+	return key.String(), true
+}
+
+// tableKey returns the key of all the rows of a table.
+func tableKey(tplan *TablePlan) string {
+	var key strings.Builder
+	writeKeyPart(&key, []byte(tplan.TargetName))
+	return key.String()
+}
+
+// writeKeyPart writes a length-prefixed part of a row key, so that the parts
+// of different keys can't be confused.
+func writeKeyPart(key *strings.Builder, part []byte) {
+	key.WriteString(strconv.Itoa(len(part)))
+	key.WriteByte(':')
+	key.Write(part)
+}
@@ -0,0 +1,148 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vreplication
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/mysql/collations"
+	"vitess.io/vitess/go/sqltypes"
+
+	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
+)
+
+func TestRowEventWriteset(t *testing.T) {
+	fields := sqltypes.MakeTestFields("id|name|val", "int64|varchar|int64")
+	fields[1].Charset = uint32(collations.Local().LookupByName("utf8mb4_0900_ai_ci").ID())
+	tplan := &TablePlan{
+		TargetName:   "t1",
+		Fields:       fields,
+		PKReferences: []string{"id", "name"},
+	}
+	makeRow := func(id int64, name string, val int64) *binlogdatapb.RowChange {
+		return &binlogdatapb.RowChange{After: sqltypes.RowToProto3([]sqltypes.Value{
+			sqltypes.NewInt64(id), sqltypes.NewVarChar(name), sqltypes.NewInt64(val),
+		})}
+	}
+
+	keys, ok := rowEventWriteset(tplan, nil, &binlogdatapb.RowEvent{RowChanges: []*binlogdatapb.RowChange{
+		makeRow(1, "a", 10),
+		makeRow(1, "A", 20),
+		makeRow(2, "a", 10),
+	}})
+	require.True(t, ok)
+	require.Len(t, keys, 3)
+	assert.Equal(t, keys[0], keys[1], "values that are equal in their collation must have the same key")
+	assert.NotEqual(t, keys[0], keys[2])
+
+	// A row move changes the keys of the row before and after the change.
+	move := makeRow(3, "b", 0)
+	move.Before = makeRow(1, "a", 0).After
+	keys, ok = rowEventWriteset(tplan, nil, &binlogdatapb.RowEvent{RowChanges: []*binlogdatapb.RowChange{move}})
+	require.True(t, ok)
+	require.Len(t, keys, 2)
+
+	// Rows of tables without a primary key can't be identified.
+	_, ok = rowEventWriteset(&TablePlan{TargetName: "t2", Fields: fields}, nil, &binlogdatapb.RowEvent{
+		RowChanges: []*binlogdatapb.RowChange{makeRow(1, "a", 10)},
+	})
+	assert.False(t, ok)
+	_, ok = rowEventWriteset(&TablePlan{TargetName: "t2", Fields: fields, PKReferences: []string{"id", "name", "val"}, FullRowKey: true}, nil, &binlogdatapb.RowEvent{
+		RowChanges: []*binlogdatapb.RowChange{makeRow(1, "a", 10)},
+	})
+	assert.False(t, ok)
+
+	// Rows that have the same values in a unique key of the target table
+	// conflict, unless one of the values is NULL.
+	tkeys := &targetKeys{uniqueKeys: map[string][]string{"uk_val": {"val"}}}
+	keys, ok = rowEventWriteset(tplan, tkeys, &binlogdatapb.RowEvent{RowChanges: []*binlogdatapb.RowChange{
+		makeRow(1, "a", 10),
+	}})
+	require.True(t, ok)
+	other, ok := rowEventWriteset(tplan, tkeys, &binlogdatapb.RowEvent{RowChanges: []*binlogdatapb.RowChange{
+		makeRow(2, "b", 10),
+	}})
+	require.True(t, ok)
+	require.Len(t, keys, 2)
+	assert.Subset(t, keys, []string{other[0]}, "rows with the same unique key values must share a key")
+
+	nullVal := &binlogdatapb.RowChange{After: sqltypes.RowToProto3([]sqltypes.Value{
+		sqltypes.NewInt64(3), sqltypes.NewVarChar("c"), sqltypes.NULL,
+	})}
+	keys, ok = rowEventWriteset(tplan, tkeys, &binlogdatapb.RowEvent{RowChanges: []*binlogdatapb.RowChange{nullVal}})
+	require.True(t, ok)
+	assert.Len(t, keys, 1)
+
+	// Rows can't be identified if a unique key column isn't replicated.
+	_, ok = rowEventWriteset(tplan, &targetKeys{uniqueKeys: map[string][]string{"uk_other": {"other"}}}, &binlogdatapb.RowEvent{
+		RowChanges: []*binlogdatapb.RowChange{makeRow(1, "a", 10)},
+	})
+	assert.False(t, ok)
+}
+
+func TestParallelApplierDependencies(t *testing.T) {
+	pa := &parallelApplier{lastWriters: make(map[string]*parallelTxn)}
+	newTxn := func(writeset ...string) *parallelTxn {
+		txn := &parallelTxn{writeset: writeset, done: make(chan struct{})}
+		pa.trackDependencies(txn)
+		return txn
+	}
+
+	txn1 := newTxn("t1:1", "t1:2")
+	txn2 := newTxn("t1:3")
+	txn3 := newTxn("t1:2", "t1:3")
+	txn4 := newTxn("t1:2", "t1:4")
+
+	assert.Empty(t, txn1.deps)
+	assert.Empty(t, txn2.deps, "transactions that change different rows don't depend on each other")
+	assert.ElementsMatch(t, []*parallelTxn{txn1, txn2}, txn3.deps)
+	assert.ElementsMatch(t, []*parallelTxn{txn3}, txn4.deps, "only the last transaction that changed a row is a dependency")
+}
+
+func TestParallelApplierClockDependencies(t *testing.T) {
+	pa := &parallelApplier{lastWriters: make(map[string]*parallelTxn)}
+	newTxn := func(lastCommitted, sequenceNumber int64, clockReset, unkeyed bool, writeset ...string) *parallelTxn {
+		txn := &parallelTxn{
+			writeset:       writeset,
+			unkeyed:        unkeyed,
+			lastCommitted:  lastCommitted,
+			sequenceNumber: sequenceNumber,
+			clockReset:     clockReset,
+			done:           make(chan struct{}),
+		}
+		pa.trackDependencies(txn)
+		pa.last = txn
+		return txn
+	}
+
+	txn1 := newTxn(0, 1, true, false, "t1:1")
+	txn2 := newTxn(0, 2, false, true, "t2")
+	txn3 := newTxn(1, 3, false, false, "t1:3")
+	txn4 := newTxn(2, 4, false, false, "t1:1")
+	txn5 := newTxn(0, 1, true, false, "t1:5")
+	txn6 := newTxn(0, 0, true, false, "t1:6")
+
+	assert.Empty(t, txn1.deps)
+	assert.Empty(t, txn2.deps, "the clock allows unkeyed transactions to run in parallel")
+	assert.ElementsMatch(t, []*parallelTxn{txn1}, txn3.deps, "transactions depend on the last transaction their clock says they depend on")
+	assert.ElementsMatch(t, []*parallelTxn{txn1, txn2}, txn4.deps, "writeset and clock dependencies are combined")
+	assert.ElementsMatch(t, []*parallelTxn{txn4}, txn5.deps, "transactions depend on the previous one when the clock starts over")
+	assert.ElementsMatch(t, []*parallelTxn{txn2}, txn6.deps, "transactions without a clock depend on the last unkeyed transaction")
+}
@@ -113,6 +113,9 @@ type vreplicator struct {
 	WorkflowName string
 
 	throttleUpdatesRateLimiter *timer.RateLimiter
+
+	// schedule is the maintenance window and the rate limits of the stream.
+	schedule *streamSchedule
 }
 
 // newVReplicator creates a new vreplicator. The valid fields from the source are:
@@ -272,7 +275,17 @@ func (vr *vreplicator) replicate(ctx context.Context) error {
 				log.Warningf("Unable to clear FK check %v", err)
 				return err
 			}
-			if err := newVCopier(vr).copyNext(ctx, settings); err != nil {
+			copyCtx, cancel, err := vr.schedule.waitForWindow(ctx, true, vr.setMessage)
+			if err != nil {
+				return err
+			}
+			err = newVCopier(vr).copyNext(copyCtx, settings)
+			cancel()
+			if windowClosed(ctx, copyCtx) {
+				log.Infof("Maintenance window %s closed, pausing the copy of stream %d", vr.schedule, vr.id)
+				continue
+			}
+			if err != nil {
 				vr.stats.ErrorCounts.Add([]string{"Copy"}, 1)
 				return err
 			}
@@ -316,6 +329,10 @@ type ColumnInfo struct {
 	ColumnType  string
 	IsPK        bool
 	IsGenerated bool
+	// IsFullRowKey is set on the columns of a table that has no primary
+	// or unique key. Its rows can only be identified by the values of all
+	// of their columns, so all the columns are also marked as IsPK.
+	IsFullRowKey bool
 }
 
 func (vr *vreplicator) buildColInfoMap(ctx context.Context) (map[string][]*ColumnInfo, error) {
@@ -337,6 +354,7 @@ func (vr *vreplicator) buildColInfoMap(ctx context.Context) (map[string][]*Colum
 		}
 
 		var pks []string
+		fullRowKey := false
 		if len(td.PrimaryKeyColumns) != 0 {
 			// Use the PK
 			pks = td.PrimaryKeyColumns
@@ -348,6 +366,7 @@ func (vr *vreplicator) buildColInfoMap(ctx context.Context) (map[string][]*Colum
 			// Fall back to using every column in the table if there's no PK or PKE
 			if len(pks) == 0 {
 				pks = td.Columns
+				fullRowKey = true
 			}
 		}
 		var colInfo []*ColumnInfo
@@ -388,13 +407,14 @@ func (vr *vreplicator) buildColInfoMap(ctx context.Context) (map[string][]*Colum
 				isGenerated = true
 			}
 			colInfo = append(colInfo, &ColumnInfo{
-				Name:        columnName,
-				CharSet:     charSet,
-				Collation:   collation,
-				DataType:    dataType,
-				ColumnType:  columnType,
-				IsPK:        isPK,
-				IsGenerated: isGenerated,
+				Name:         columnName,
+				CharSet:      charSet,
+				Collation:    collation,
+				DataType:     dataType,
+				ColumnType:   columnType,
+				IsPK:         isPK,
+				IsGenerated:  isGenerated,
+				IsFullRowKey: fullRowKey,
 			})
 		}
 		colInfoMap[td.Name] = colInfo
@@ -44,6 +44,7 @@ const (
 	RowStreamerName       Name = "rowstreamer"
 	ExternalConnectorName Name = "external-connector"
 	ReplicaConnectorName  Name = "replica-connector"
+	VDiffName             Name = "vdiff"
 
 	BinlogWatcherName Name = "binlog-watcher"
 	MessagerName      Name = "messager"
@@ -94,6 +94,7 @@ type Engine struct {
 	resultStreamerNumPackets               *stats.Counter
 	rowStreamerNumRows                     *stats.Counter
 	rowStreamerNumPackets                  *stats.Counter
+	rowStreamerDuplicateRows               *stats.Counter
 	rowStreamerWaits                       *servenv.TimingsWrapper
 	errorCounts                            *stats.CountersWithSingleLabel
 	vstreamersCreated                      *stats.Counter
@@ -133,6 +134,7 @@ func NewEngine(env tabletenv.Env, ts srvtopo.Server, se *schema.Engine, lagThrot
 		resultStreamerNumRows:                  env.Exporter().NewCounter("ResultStreamerNumRows", "Number of rows sent in result streamer"),
 		rowStreamerNumPackets:                  env.Exporter().NewCounter("RowStreamerNumPackets", "Number of packets in row streamer"),
 		rowStreamerNumRows:                     env.Exporter().NewCounter("RowStreamerNumRows", "Number of rows sent in row streamer"),
+		rowStreamerDuplicateRows:               env.Exporter().NewCounter("RowStreamerDuplicateRows", "Number of duplicate rows found by the row streamer in tables without a primary or unique key"),
 		rowStreamerWaits:                       env.Exporter().NewTimings("RowStreamerWaits", "Total counts and time we've waited when streaming rows in the vstream copy phase", "copy-phase-waits"),
 		vstreamersCreated:                      env.Exporter().NewCounter("VStreamersCreated", "Count of vstreamers created"),
 		vstreamersEndedWithErrors:              env.Exporter().NewCounter("VStreamersEndedWithErrors", "Count of vstreamers that ended with errors"),
@@ -59,6 +59,10 @@ type Plan struct {
 	// Filters is the list of filters to be applied to the columns
 	// of the table.
 	Filters []Filter
+
+	// env is used to evaluate the IsTrue filters. It's reused across
+	// rows, since a plan is only used by a single stream at a time.
+	env *evalengine.ExpressionEnv
 }
 
 // Opcode enumerates the operators supported in a where clause
@@ -79,6 +83,9 @@ const (
 	GreaterThanEqual
 	// NotEqual is used to filter a comparable column if != specific value
 	NotEqual
+	// IsTrue is used to filter on any boolean expression supported by the
+	// evalengine, like IN lists, LIKE, IS NULL or JSON functions
+	IsTrue
 )
 
 // Filter contains opcodes for filtering.
@@ -87,6 +94,9 @@ type Filter struct {
 	ColNum int
 	Value  sqltypes.Value
 
+	// Expr is the expression evaluated against the row for IsTrue.
+	Expr evalengine.Expr
+
 	// Parameters for VindexMatch.
 	// Vindex, VindexColumns and KeyRange, if set, will be used
 	// to filter the row.
@@ -224,6 +234,15 @@ func (plan *Plan) filter(values, result []sqltypes.Value, charsets []collations.
 			if !key.KeyRangeContains(filter.KeyRange, ksid) {
 				return false, nil
 			}
+		case IsTrue:
+			plan.env.Row = values
+			res, err := plan.env.Evaluate(filter.Expr)
+			if err != nil {
+				return false, err
+			}
+			if !res.ToBoolean() {
+				return false, nil
+			}
 		default:
 			match, err := compare(filter.Opcode, values[filter.ColNum], filter.Value, charsets[filter.ColNum])
 			if err != nil {
@@ -506,57 +525,122 @@ func (plan *Plan) analyzeWhere(vschema *localVSchema, where *sqlparser.Where) er
 	for _, expr := range exprs {
 		switch expr := expr.(type) {
 		case *sqlparser.ComparisonExpr:
-			opcode, err := getOpcode(expr)
-			if err != nil {
-				return err
-			}
-			qualifiedName, ok := expr.Left.(*sqlparser.ColName)
-			if !ok {
-				return fmt.Errorf("unexpected: %v", sqlparser.String(expr))
-			}
-			if !qualifiedName.Qualifier.IsEmpty() {
-				return fmt.Errorf("unsupported qualifier for column: %v", sqlparser.String(qualifiedName))
-			}
-			colnum, err := findColumn(plan.Table, qualifiedName.Name)
+			filter, ok, err := plan.analyzeComparison(expr)
 			if err != nil {
 				return err
 			}
-			val, ok := expr.Right.(*sqlparser.Literal)
 			if !ok {
-				return fmt.Errorf("unexpected: %v", sqlparser.String(expr))
-			}
-			//StrVal is varbinary, we do not support varchar since we would have to implement all collation types
-			if val.Type != sqlparser.IntVal && val.Type != sqlparser.StrVal {
-				return fmt.Errorf("unexpected: %v", sqlparser.String(expr))
-			}
-			pv, err := evalengine.Translate(val, nil)
-			if err != nil {
-				return err
-			}
-			env := evalengine.EmptyExpressionEnv()
-			resolved, err := env.Evaluate(pv)
-			if err != nil {
-				return err
+				if err := plan.analyzeExpression(expr); err != nil {
+					return err
+				}
+				continue
 			}
-			plan.Filters = append(plan.Filters, Filter{
-				Opcode: opcode,
-				ColNum: colnum,
-				Value:  resolved.Value(),
-			})
+			plan.Filters = append(plan.Filters, filter)
 		case *sqlparser.FuncExpr:
 			if !expr.Name.EqualString("in_keyrange") {
-				return fmt.Errorf("unsupported constraint: %v", sqlparser.String(expr))
+				if err := plan.analyzeExpression(expr); err != nil {
+					return err
+				}
+				continue
 			}
 			if err := plan.analyzeInKeyRange(vschema, expr.Exprs); err != nil {
 				return err
 			}
 		default:
-			return fmt.Errorf("unsupported constraint: %v", sqlparser.String(expr))
+			if err := plan.analyzeExpression(expr); err != nil {
+				return err
+			}
 		}
 	}
 	return nil
 }
 
+// analyzeComparison builds the filter of a comparison between a column and
+// an integer or string literal. It returns false if the comparison has any
+// other form, in which case it has to be evaluated as an expression.
+func (plan *Plan) analyzeComparison(expr *sqlparser.ComparisonExpr) (Filter, bool, error) {
+	opcode, err := getOpcode(expr)
+	if err != nil {
+		return Filter{}, false, nil
+	}
+	qualifiedName, ok := expr.Left.(*sqlparser.ColName)
+	if !ok || !qualifiedName.Qualifier.IsEmpty() {
+		return Filter{}, false, nil
+	}
+	val, ok := expr.Right.(*sqlparser.Literal)
+	//StrVal is varbinary, we do not support varchar since we would have to implement all collation types
+	if !ok || (val.Type != sqlparser.IntVal && val.Type != sqlparser.StrVal) {
+		return Filter{}, false, nil
+	}
+	colnum, err := findColumn(plan.Table, qualifiedName.Name)
+	if err != nil {
+		return Filter{}, false, err
+	}
+	pv, err := evalengine.Translate(val, nil)
+	if err != nil {
+		return Filter{}, false, err
+	}
+	env := evalengine.EmptyExpressionEnv()
+	resolved, err := env.Evaluate(pv)
+	if err != nil {
+		return Filter{}, false, err
+	}
+	return Filter{
+		Opcode: opcode,
+		ColNum: colnum,
+		Value:  resolved.Value(),
+	}, true, nil
+}
+
+// analyzeExpression builds a filter that evaluates a boolean expression
+// against the columns of the table
+func (plan *Plan) analyzeExpression(expr sqlparser.Expr) error {
+	var columnErr error
+	resolveColumn := func(col *sqlparser.ColName) (int, error) {
+		if !col.Qualifier.IsEmpty() && col.Qualifier.Name.String() != plan.Table.Name {
+			columnErr = fmt.Errorf("unsupported qualifier for column: %v", sqlparser.String(col))
+			return 0, columnErr
+		}
+		colnum, err := findColumn(plan.Table, col.Name)
+		if err != nil {
+			columnErr = err
+		}
+		return colnum, err
+	}
+	resolveType := func(expr sqlparser.Expr) (sqltypes.Type, collations.ID, bool) {
+		col, ok := expr.(*sqlparser.ColName)
+		if !ok {
+			return 0, 0, false
+		}
+		colnum := plan.Table.FindColumn(col.Name)
+		if colnum < 0 {
+			return 0, 0, false
+		}
+		field := plan.Table.Fields[colnum]
+		return field.Type, collations.ID(field.Charset), true
+	}
+
+	evalExpr, err := evalengine.Translate(expr, &evalengine.Config{
+		ResolveColumn: resolveColumn,
+		ResolveType:   resolveType,
+		Collation:     collations.Default(),
+	})
+	if columnErr != nil {
+		return columnErr
+	}
+	if err != nil {
+		return fmt.Errorf("unsupported constraint: %v", sqlparser.String(expr))
+	}
+	plan.Filters = append(plan.Filters, Filter{
+		Opcode: IsTrue,
+		Expr:   evalExpr,
+	})
+	if plan.env == nil {
+		plan.env = evalengine.EmptyExpressionEnv()
+	}
+	return nil
+}
+
 // splitAndExpression breaks up the Expr into AND-separated conditions
 // and appends them to filters, which can be shuffled and recombined
 // as needed.
@@ -663,6 +663,98 @@ func TestPlanBuilderFilterComparison(t *testing.T) {
 	}
 }
 
+func TestPlanBuilderFilterExpression(t *testing.T) {
+	t1 := &Table{
+		Name: "t1",
+		Fields: []*querypb.Field{{
+			Name: "id",
+			Type: sqltypes.Int64,
+		}, {
+			Name: "val",
+			Type: sqltypes.VarBinary,
+		}, {
+			Name: "tenant",
+			Type: sqltypes.Int64,
+		}, {
+			Name: "doc",
+			Type: sqltypes.TypeJSON,
+		}},
+	}
+	row := func(id int64, val any, tenant int64, doc string) []sqltypes.Value {
+		v := sqltypes.NULL
+		if val != nil {
+			v = sqltypes.NewVarBinary(val.(string))
+		}
+		return []sqltypes.Value{sqltypes.NewInt64(id), v, sqltypes.NewInt64(tenant), sqltypes.MakeTrusted(sqltypes.TypeJSON, []byte(doc))}
+	}
+	charsets := []collations.ID{collations.CollationBinaryID, collations.CollationBinaryID, collations.CollationBinaryID, collations.CollationUtf8mb4ID}
+
+	testcases := []struct {
+		name    string
+		filter  string
+		matches []bool
+		outErr  string
+	}{{
+		name:    "in list",
+		filter:  "select * from t1 where tenant in (1, 3)",
+		matches: []bool{true, false, true},
+	}, {
+		name:    "like",
+		filter:  "select * from t1 where val like 'new%'",
+		matches: []bool{true, false, false},
+	}, {
+		name:    "is null",
+		filter:  "select * from t1 where val is null",
+		matches: []bool{false, false, true},
+	}, {
+		name:    "json extract",
+		filter:  "select * from t1 where json_extract(doc, '$.region') = 'eu'",
+		matches: []bool{false, true, true},
+	}, {
+		name:    "or with comparison and in_keyrange",
+		filter:  "select * from t1 where (tenant = 1 or id > 2) and in_keyrange(id, 'hash', '-')",
+		matches: []bool{true, false, true},
+	}, {
+		name:    "qualified column",
+		filter:  "select * from t1 where t1.tenant != 2",
+		matches: []bool{true, false, true},
+	}, {
+		name:   "unknown column",
+		filter: "select * from t1 where none in (1, 2)",
+		outErr: "column `none` not found in table t1",
+	}, {
+		name:   "other table",
+		filter: "select * from t1 where t2.tenant in (1, 2)",
+		outErr: "unsupported qualifier for column: t2.tenant",
+	}}
+
+	rows := [][]sqltypes.Value{
+		row(1, "newton", 1, `{"region": "us"}`),
+		row(2, "kepler", 2, `{"region": "eu"}`),
+		row(3, nil, 3, `{"region": "eu"}`),
+	}
+	for _, tcase := range testcases {
+		t.Run(tcase.name, func(t *testing.T) {
+			plan, err := buildPlan(t1, testLocalVSchema, &binlogdatapb.Filter{
+				Rules: []*binlogdatapb.Rule{{Match: "t1", Filter: tcase.filter}},
+			})
+			if tcase.outErr != "" {
+				assert.Nil(t, plan)
+				assert.EqualError(t, err, tcase.outErr)
+				return
+			}
+			require.NoError(t, err)
+
+			result := make([]sqltypes.Value, len(plan.ColExprs))
+			for i, values := range rows {
+				ok, err := plan.filter(values, result, charsets)
+				require.NoError(t, err)
+				assert.Equal(t, tcase.matches[i], ok, "row %d", i)
+			}
+		})
+	}
+}
+
 func TestCompare(t *testing.T) {
 	type testcase struct {
 		opcode                   Opcode
@@ -17,6 +17,7 @@ limitations under the License.
 package vstreamer
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"sync"
@@ -76,9 +77,12 @@ type rowStreamer struct {
 	plan          *Plan
 	pkColumns     []int
 	ukColumnNames []string
-	sendQuery     string
-	vse           *Engine
-	pktsize       PacketSizer
+	// fullRowKey is set if the table has no primary or unique key, and its
+	// rows are ordered by all of their columns.
+	fullRowKey bool
+	sendQuery  string
+	vse        *Engine
+	pktsize    PacketSizer
 }
 
 func newRowStreamer(ctx context.Context, cp dbconfigs.Connector, se *schema.Engine, query string, lastpk []sqltypes.Value, vschema *localVSchema, send func(*binlogdatapb.VStreamRowsResponse) error, vse *Engine) *rowStreamer {
@@ -210,6 +214,7 @@ func (rs *rowStreamer) buildPKColumns(st *binlogdatapb.MinimalTable) ([]int, err
 		}
 
 		// Fall back to using every column in the table if there's no PK or PKE
+		rs.fullRowKey = true
 		pkColumns = make([]int, len(st.Fields))
 		for i := range st.Fields {
 			pkColumns[i] = i
@@ -342,6 +347,8 @@ func (rs *rowStreamer) streamQuery(conn *snapshotConn, send func(*binlogdatapb.V
 	filtered := make([]sqltypes.Value, len(rs.plan.ColExprs))
 	lastpk := make([]sqltypes.Value, len(rs.pkColumns))
 	byteCount := 0
+	duplicateRows := 0
+	haveLastpk := false
 	for {
 		if rs.ctx.Err() != nil {
 			log.Infof("Stream ended because of ctx.Done")
@@ -366,11 +373,17 @@ func (rs *rowStreamer) streamQuery(conn *snapshotConn, send func(*binlogdatapb.V
 		if mysqlrow == nil {
 			break
 		}
+		// The rows of a table without a key are ordered by all of their
+		// columns, so duplicate rows come one after the other.
+		if rs.fullRowKey && haveLastpk && rowsEqual(lastpk, mysqlrow) {
+			duplicateRows++
+		}
 		// Compute lastpk here, because we'll need it
 		// at the end after the loop exits.
 		for i, pk := range rs.pkColumns {
 			lastpk[i] = mysqlrow[pk]
 		}
+		haveLastpk = true
 		// Reuse the vstreamer's filter.
 		ok, err := rs.plan.filter(mysqlrow, filtered, charsets)
 		if err != nil {
@@ -401,6 +414,11 @@ func (rs *rowStreamer) streamQuery(conn *snapshotConn, send func(*binlogdatapb.V
 		}
 	}
 
+	if duplicateRows > 0 {
+		log.Warningf("Table %s has no primary or unique key, and %d of its rows are duplicates of the previous row", rs.plan.Table.Name, duplicateRows)
+		rs.vse.rowStreamerDuplicateRows.Add(int64(duplicateRows))
+	}
+
 	if rowCount > 0 {
 		response.Rows = rows[:rowCount]
 		response.Lastpk = sqltypes.RowToProto3(lastpk)
@@ -414,3 +432,16 @@ func (rs *rowStreamer) streamQuery(conn *snapshotConn, send func(*binlogdatapb.V
 
 	return nil
 }
+
+// rowsEqual returns true if the values of the two rows are identical.
+func rowsEqual(row1, row2 []sqltypes.Value) bool {
+	if len(row1) != len(row2) {
+		return false
+	}
+	for i := range row1 {
+		if row1[i].Type() != row2[i].Type() || !bytes.Equal(row1[i].Raw(), row2[i].Raw()) {
+			return false
+		}
+	}
+	return true
+}
@@ -29,6 +29,7 @@ import (
 	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
 
 	"vitess.io/vitess/go/mysql"
+	"vitess.io/vitess/go/vt/binlog"
 	"vitess.io/vitess/go/vt/dbconfigs"
 	"vitess.io/vitess/go/vt/key"
 	"vitess.io/vitess/go/vt/log"
@@ -358,6 +359,15 @@ func (uvs *uvstreamer) setStreamStartPosition() error {
 		}
 		return nil
 	}
+	if timestamp, ok, err := binlog.ParseTimestampPosition(uvs.startPos); ok {
+		if err != nil {
+			return vterrors.Wrap(err, "could not decode position")
+		}
+		if uvs.pos, err = uvs.positionAtTimestamp(timestamp); err != nil {
+			return vterrors.Wrap(err, "could not find the position at the requested time")
+		}
+		return uvs.sendEventsForCurrentPos()
+	}
 	pos, err := mysql.DecodePosition(uvs.startPos)
 	if err != nil {
 		return vterrors.Wrap(err, "could not decode position")
@@ -372,6 +382,17 @@ func (uvs *uvstreamer) setStreamStartPosition() error {
 	return nil
 }
 
+// positionAtTimestamp walks the binlogs to find the position of the first
+// transaction committed at or after the timestamp
+func (uvs *uvstreamer) positionAtTimestamp(timestamp int64) (mysql.Position, error) {
+	conn, err := binlog.NewBinlogConnection(uvs.cp)
+	if err != nil {
+		return mysql.Position{}, err
+	}
+	defer conn.Close()
+	return conn.PositionAtTimestamp(uvs.ctx, timestamp)
+}
+
 func (uvs *uvstreamer) currentPosition() (mysql.Position, error) {
 	conn, err := uvs.cp.Connect(uvs.ctx)
 	if err != nil {
@@ -382,7 +403,7 @@ func (uvs *uvstreamer) currentPosition() (mysql.Position, error) {
 }
 
 // Possible states:
-// 1. TablePKs nil, startPos set to gtid or "current" => start replicating from pos
+// 1. TablePKs nil, startPos set to gtid, "current" or a timestamp => start replicating from pos
 // 2. TablePKs nil, startPos empty => full table copy of tables matching filter
 // 3. TablePKs not nil, startPos empty => table copy (for pks > lastPK)
 // 4. TablePKs not nil, startPos set => run catchup from startPos, then table copy  (for pks > lastPK)
@@ -80,6 +80,11 @@ type vstreamer struct {
 	pos     mysql.Position
 	stopPos string
 
+	// lastCommitted and sequenceNumber are the logical clock of the
+	// current transaction, if the source has one.
+	lastCommitted  int64
+	sequenceNumber int64
+
 	phase string
 	vse   *Engine
 }
@@ -109,8 +114,12 @@ type streamerPlan struct {
 //	"select * from t where in_keyrange(col1, 'hash', '-80')",
 //	"select col1, col2 from t where...",
 //	"select col1, keyspace_id() from t where...".
-//	Only "in_keyrange" and limited comparison operators (see enum Opcode in planbuilder.go) are supported in the where clause.
-//	Other constructs like joins, group by, etc. are not supported.
+//	"select * from t where col1 in (1, 2) and col2 like 'a%'",
+//	"select * from t where json_extract(col1, '$.a') is not null".
+//	The where clause can use "in_keyrange" and any boolean expression supported by the evalengine
+//	that only references columns of the table, like comparisons, IN lists, LIKE, IS NULL or JSON
+//	functions (see enum Opcode in planbuilder.go). Rows are sent when the expression is true.
+//	Other constructs like joins, group by, subqueries, etc. are not supported.
 //
 // vschema: the current vschema. This value can later be changed through the SetVSchema method.
 // send: callback function to send events.
@@ -448,10 +457,13 @@ func (vs *vstreamer) parseEvent(ev mysql.BinlogEvent) ([]*binlogdatapb.VEvent, e
 			})
 		}
 		vs.pos = mysql.AppendGTID(vs.pos, gtid)
+		vs.lastCommitted, vs.sequenceNumber, _ = ev.LogicalClock(vs.format)
 	case ev.IsXID():
 		vevents = append(vevents, &binlogdatapb.VEvent{
-			Type: binlogdatapb.VEventType_GTID,
-			Gtid: mysql.EncodePosition(vs.pos),
+			Type:           binlogdatapb.VEventType_GTID,
+			Gtid:           mysql.EncodePosition(vs.pos),
+			LastCommitted:  vs.lastCommitted,
+			SequenceNumber: vs.sequenceNumber,
 		}, &binlogdatapb.VEvent{
 			Type: binlogdatapb.VEventType_COMMIT,
 		})
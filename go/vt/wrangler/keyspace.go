@@ -24,15 +24,10 @@ import (
 	"sync"
 	"time"
 
-	"vitess.io/vitess/go/vt/proto/binlogdata"
-
 	"vitess.io/vitess/go/sqltypes"
 	"vitess.io/vitess/go/vt/concurrency"
-	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
 	"vitess.io/vitess/go/vt/topo"
 	"vitess.io/vitess/go/vt/topo/topoproto"
-	"vitess.io/vitess/go/vt/topotools"
-	"vitess.io/vitess/go/vt/vterrors"
 )
 
 const (
@@ -42,57 +37,6 @@ const (
 
 // keyspace related methods for Wrangler
 
-// validateNewWorkflow ensures that the specified workflow doesn't already exist
-// in the keyspace.
-func (wr *Wrangler) validateNewWorkflow(ctx context.Context, keyspace, workflow string) error {
-	allshards, err := wr.ts.FindAllShardsInKeyspace(ctx, keyspace)
-	if err != nil {
-		return err
-	}
-	var wg sync.WaitGroup
-	allErrors := &concurrency.AllErrorRecorder{}
-	for _, si := range allshards {
-		if si.PrimaryAlias == nil {
-			allErrors.RecordError(fmt.Errorf("shard has no primary: %v", si.ShardName()))
-			continue
-		}
-		wg.Add(1)
-		go func(si *topo.ShardInfo) {
-			defer wg.Done()
-
-			primary, err := wr.ts.GetTablet(ctx, si.PrimaryAlias)
-			if err != nil {
-				allErrors.RecordError(vterrors.Wrap(err, "validateWorkflowName.GetTablet"))
-				return
-			}
-			validations := []struct {
-				query string
-				msg   string
-			}{{
-				fmt.Sprintf("select 1 from _vt.vreplication where db_name=%s and workflow=%s", encodeString(primary.DbName()), encodeString(workflow)),
-				fmt.Sprintf("workflow %s already exists in keyspace %s on tablet %d", workflow, keyspace, primary.Alias.Uid),
-			}, {
-				fmt.Sprintf("select 1 from _vt.vreplication where db_name=%s and message='FROZEN' and workflow_sub_type != %d", encodeString(primary.DbName()), binlogdata.VReplicationWorkflowSubType_Partial),
-				fmt.Sprintf("found previous frozen workflow on tablet %d, please review and delete it first before creating a new workflow",
-					primary.Alias.Uid),
-			}}
-			for _, validation := range validations {
-				p3qr, err := wr.tmc.VReplicationExec(ctx, primary.Tablet, validation.query)
-				if err != nil {
-					allErrors.RecordError(vterrors.Wrap(err, "validateWorkflowName.VReplicationExec"))
-					return
-				}
-				if p3qr != nil && len(p3qr.Rows) != 0 {
-					allErrors.RecordError(vterrors.Wrap(fmt.Errorf(validation.msg), "validateWorkflowName.VReplicationExec"))
-					return
-				}
-			}
-		}(si)
-	}
-	wg.Wait()
-	return allErrors.AggrError(vterrors.Aggregate)
-}
-
 func (wr *Wrangler) printShards(ctx context.Context, si []*topo.ShardInfo) error {
 	for _, si := range si {
 		wr.Logger().Printf("    Shard: %v\n", si.ShardName())
@@ -199,64 +143,6 @@ func (wr *Wrangler) waitForFilteredReplication(ctx context.Context, sourcePositi
 	return rec.Error()
 }
 
-// refreshPrimaryTablets will just RPC-ping all the primary tablets with RefreshState
-func (wr *Wrangler) refreshPrimaryTablets(ctx context.Context, shards []*topo.ShardInfo) error {
-	wg := sync.WaitGroup{}
-	rec := concurrency.AllErrorRecorder{}
-	for _, si := range shards {
-		wg.Add(1)
-		go func(si *topo.ShardInfo) {
-			defer wg.Done()
-			wr.Logger().Infof("RefreshState primary %v", topoproto.TabletAliasString(si.PrimaryAlias))
-			ti, err := wr.ts.GetTablet(ctx, si.PrimaryAlias)
-			if err != nil {
-				rec.RecordError(err)
-				return
-			}
-
-			if err := wr.tmc.RefreshState(ctx, ti.Tablet); err != nil {
-				rec.RecordError(err)
-			} else {
-				wr.Logger().Infof("%v responded", topoproto.TabletAliasString(si.PrimaryAlias))
-			}
-		}(si)
-	}
-	wg.Wait()
-	return rec.Error()
-}
-
-// updateShardRecords updates the shard records based on 'from' or 'to' direction.
-func (wr *Wrangler) updateShardRecords(ctx context.Context, keyspace string, shards []*topo.ShardInfo, cells []string, servedType topodatapb.TabletType, isFrom bool, clearSourceShards bool) (err error) {
-	return topotools.UpdateShardRecords(ctx, wr.ts, wr.tmc, keyspace, shards, cells, servedType, isFrom, clearSourceShards, wr.Logger())
-}
-
-// updateFrozenFlag sets or unsets the Frozen flag for primary migration. This is performed
-// for all primary tablet control records.
-func (wr *Wrangler) updateFrozenFlag(ctx context.Context, shards []*topo.ShardInfo, value bool) (err error) {
-	for i, si := range shards {
-		updatedShard, err := wr.ts.UpdateShardFields(ctx, si.Keyspace(), si.ShardName(), func(si *topo.ShardInfo) error {
-			tc := si.GetTabletControl(topodatapb.TabletType_PRIMARY)
-			if tc != nil {
-				tc.Frozen = value
-				return nil
-			}
-			// This shard does not have a tablet control record, adding one to set frozen flag
-			tc = &topodatapb.Shard_TabletControl{
-				TabletType: topodatapb.TabletType_PRIMARY,
-				Frozen:     value,
-			}
-			si.TabletControls = append(si.TabletControls, tc)
-			return nil
-		})
-		if err != nil {
-			return err
-		}
-
-		shards[i] = updatedShard
-	}
-	return nil
-}
-
 func encodeString(in string) string {
 	buf := bytes.NewBuffer(nil)
 	sqltypes.NewVarChar(in).EncodeSQL(buf)
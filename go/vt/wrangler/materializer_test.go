@@ -25,7 +25,6 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/require"
-	"google.golang.org/protobuf/proto"
 
 	"vitess.io/vitess/go/sqltypes"
 	"vitess.io/vitess/go/test/utils"
@@ -37,7 +36,6 @@ import (
 	vschemapb "vitess.io/vitess/go/vt/proto/vschema"
 	vtctldatapb "vitess.io/vitess/go/vt/proto/vtctldata"
 	"vitess.io/vitess/go/vt/topo/memorytopo"
-	"vitess.io/vitess/go/vt/vtgate/vindexes"
 )
 
 const mzUpdateQuery = "update _vt.vreplication set state='Running' where db_name='vt_targetks' and workflow='workflow'"
@@ -67,7 +65,7 @@ func TestMigrateTables(t *testing.T) {
 	env.tmc.expectVRQuery(200, mzUpdateQuery, &sqltypes.Result{})
 
 	ctx := context.Background()
-	err := env.wr.MoveTables(ctx, "workflow", "sourceks", "targetks", "t1", "", "", false, "", true, false, "", false, false, "", defaultOnDDL, nil)
+	err := env.wr.MoveTables(ctx, "workflow", "sourceks", "targetks", "t1", "", "", false, "", true, false, "", false, false, "", defaultOnDDL, nil, "", "")
 	require.NoError(t, err)
 	vschema, err := env.wr.ts.GetSrvVSchema(ctx, env.cell)
 	require.NoError(t, err)
@@ -82,6 +80,50 @@ func TestMigrateTables(t *testing.T) {
 	}
 }
 
+func TestMoveTablesTenant(t *testing.T) {
+	ms := &vtctldatapb.MaterializeSettings{
+		Workflow:       "workflow",
+		SourceKeyspace: "sourceks",
+		TargetKeyspace: "targetks",
+		TableSettings: []*vtctldatapb.TableMaterializeSettings{{
+			TargetTable:      "t1",
+			SourceExpression: "select * from t1",
+		}},
+	}
+	env := newTestMaterializerEnv(t, ms, []string{"0"}, []string{"0"})
+	defer env.close()
+
+	env.tmc.expectVRQuery(100, mzCheckJournal, &sqltypes.Result{})
+	env.tmc.expectVRQuery(200, mzSelectFrozenQuery, &sqltypes.Result{})
+	env.tmc.expectVRQuery(200, `/insert into _vt.vreplication\(workflow, source.*select \* from t1 where tenant_id = 42.*tenant_column:\\"tenant_id\\" tenant_value:\\"42\\"`, &sqltypes.Result{})
+	env.tmc.expectVRQuery(200, mzSelectIDQuery, &sqltypes.Result{})
+	env.tmc.expectVRQuery(200, mzUpdateQuery, &sqltypes.Result{})
+
+	ctx := context.Background()
+	err := env.wr.MoveTables(ctx, "workflow", "sourceks", "targetks", "t1", "", "", false, "", true, false, "", false, false, "", defaultOnDDL, nil, "tenant_id", "")
+	require.EqualError(t, err, "both the tenant column and the tenant value must be specified to move a single tenant")
+
+	err = env.wr.MoveTables(ctx, "workflow", "sourceks", "targetks", "t1", "", "", false, "", true, false, "", false, false, "", defaultOnDDL, nil, "tenant_id", "42")
+	require.EqualError(t, err, "table t1 of keyspace sourceks does not have the tenant column tenant_id")
+
+	// The tenant is bound with the type of its column.
+	env.tmc.schema["sourceks.t1"].TableDefinitions[0].Fields = []*querypb.Field{{
+		Name: "tenant_id",
+		Type: sqltypes.Int64,
+	}}
+	err = env.wr.MoveTables(ctx, "workflow", "sourceks", "targetks", "t1", "", "", false, "", true, false, "", false, false, "", defaultOnDDL, nil, "tenant_id", "acme")
+	require.ErrorContains(t, err, "invalid value acme for the tenant column tenant_id of table t1")
+
+	err = env.wr.MoveTables(ctx, "workflow", "sourceks", "targetks", "t1", "", "", false, "", true, false, "", false, false, "", defaultOnDDL, nil, "tenant_id", "42")
+	require.NoError(t, err)
+	vschema, err := env.wr.ts.GetSrvVSchema(ctx, env.cell)
+	require.NoError(t, err)
+	got := fmt.Sprintf("%v", vschema)
+	require.Contains(t, got, `rules:{from_table:"t1" to_tables:"sourceks.t1"}`)
+	require.NotContains(t, got, `rules:{from_table:"targetks.t1" to_tables:"sourceks.t1"}`)
+	env.tmc.verifyQueries(t)
+}
+
 func TestMissingTables(t *testing.T) {
 	ms := &vtctldatapb.MaterializeSettings{
 		Workflow:       "workflow",
@@ -108,11 +150,11 @@ func TestMissingTables(t *testing.T) {
 	env.tmc.expectVRQuery(200, mzUpdateQuery, &sqltypes.Result{})
 
 	ctx := context.Background()
-	err := env.wr.MoveTables(ctx, "workflow", "sourceks", "targetks", "t1,tyt", "", "", false, "", true, false, "", false, false, "", defaultOnDDL, nil)
+	err := env.wr.MoveTables(ctx, "workflow", "sourceks", "targetks", "t1,tyt", "", "", false, "", true, false, "", false, false, "", defaultOnDDL, nil, "", "")
 	require.EqualError(t, err, "table(s) not found in source keyspace sourceks: tyt")
-	err = env.wr.MoveTables(ctx, "workflow", "sourceks", "targetks", "t1,tyt,t2,txt", "", "", false, "", true, false, "", false, false, "", defaultOnDDL, nil)
+	err = env.wr.MoveTables(ctx, "workflow", "sourceks", "targetks", "t1,tyt,t2,txt", "", "", false, "", true, false, "", false, false, "", defaultOnDDL, nil, "", "")
 	require.EqualError(t, err, "table(s) not found in source keyspace sourceks: tyt,txt")
-	err = env.wr.MoveTables(ctx, "workflow", "sourceks", "targetks", "t1", "", "", false, "", true, false, "", false, false, "", defaultOnDDL, nil)
+	err = env.wr.MoveTables(ctx, "workflow", "sourceks", "targetks", "t1", "", "", false, "", true, false, "", false, false, "", defaultOnDDL, nil, "", "")
 	require.NoError(t, err)
 }
 
@@ -168,7 +210,7 @@ func TestMoveTablesAllAndExclude(t *testing.T) {
 			env.tmc.expectVRQuery(200, insertPrefix, &sqltypes.Result{})
 			env.tmc.expectVRQuery(200, mzSelectIDQuery, &sqltypes.Result{})
 			env.tmc.expectVRQuery(200, mzUpdateQuery, &sqltypes.Result{})
-			err = env.wr.MoveTables(ctx, "workflow", "sourceks", "targetks", "", "", "", tcase.allTables, tcase.excludeTables, true, false, "", false, false, "", defaultOnDDL, nil)
+			err = env.wr.MoveTables(ctx, "workflow", "sourceks", "targetks", "", "", "", tcase.allTables, tcase.excludeTables, true, false, "", false, false, "", defaultOnDDL, nil, "", "")
 			require.NoError(t, err)
 			require.EqualValues(t, tcase.want, targetTables(env))
 		})
@@ -176,1001 +218,78 @@ func TestMoveTablesAllAndExclude(t *testing.T) {
 	}
 
 }
-
-func TestMoveTablesStopFlags(t *testing.T) {
-	ms := &vtctldatapb.MaterializeSettings{
-		Workflow:       "workflow",
-		SourceKeyspace: "sourceks",
-		TargetKeyspace: "targetks",
-		TableSettings: []*vtctldatapb.TableMaterializeSettings{{
-			TargetTable:      "t1",
-			SourceExpression: "select * from t1",
-		}},
-	}
-
-	ctx := context.Background()
-	var err error
-	t.Run("StopStartedAndStopAfterCopyFlags", func(t *testing.T) {
-		env := newTestMaterializerEnv(t, ms, []string{"0"}, []string{"0"})
-		defer env.close()
-		env.tmc.expectVRQuery(100, mzCheckJournal, &sqltypes.Result{})
-		env.tmc.expectVRQuery(200, mzSelectFrozenQuery, &sqltypes.Result{})
-		// insert expects flag stop_after_copy to be true
-		insert := `/insert into _vt.vreplication\(workflow, source, pos, max_tps, max_replication_lag, cell, tablet_types.*stop_after_copy:true.*`
-
-		env.tmc.expectVRQuery(200, insert, &sqltypes.Result{})
-		env.tmc.expectVRQuery(200, mzSelectIDQuery, &sqltypes.Result{})
-		// -auto_start=false is tested by NOT expecting the update query which sets state to RUNNING
-		err = env.wr.MoveTables(ctx, "workflow", "sourceks", "targetks", "t1", "",
-			"", false, "", false, true, "", false, false, "", defaultOnDDL, nil)
-		require.NoError(t, err)
-		env.tmc.verifyQueries(t)
-	})
-}
-
-func TestMigrateVSchema(t *testing.T) {
-	ms := &vtctldatapb.MaterializeSettings{
-		Workflow:       "workflow",
-		SourceKeyspace: "sourceks",
-		TargetKeyspace: "targetks",
-		TableSettings: []*vtctldatapb.TableMaterializeSettings{{
-			TargetTable:      "t1",
-			SourceExpression: "select * from t1",
-		}},
-	}
-	env := newTestMaterializerEnv(t, ms, []string{"0"}, []string{"0"})
-	defer env.close()
-
-	env.tmc.expectVRQuery(100, mzCheckJournal, &sqltypes.Result{})
-	env.tmc.expectVRQuery(200, mzSelectFrozenQuery, &sqltypes.Result{})
-	env.tmc.expectVRQuery(200, insertPrefix, &sqltypes.Result{})
-	env.tmc.expectVRQuery(200, mzSelectIDQuery, &sqltypes.Result{})
-	env.tmc.expectVRQuery(200, mzUpdateQuery, &sqltypes.Result{})
-
-	ctx := context.Background()
-	err := env.wr.MoveTables(ctx, "workflow", "sourceks", "targetks", `{"t1":{}}`, "", "", false, "", true, false, "", false, false, "", defaultOnDDL, nil)
-	require.NoError(t, err)
-	vschema, err := env.wr.ts.GetSrvVSchema(ctx, env.cell)
-	require.NoError(t, err)
-	got := fmt.Sprintf("%v", vschema)
-	want := []string{`keyspaces:{key:"sourceks" value:{}}`,
-		`keyspaces:{key:"sourceks" value:{}} keyspaces:{key:"targetks" value:{tables:{key:"t1" value:{}}}}`,
-		`rules:{from_table:"t1" to_tables:"sourceks.t1"}`,
-		`rules:{from_table:"targetks.t1" to_tables:"sourceks.t1"}`,
-	}
-	for _, wantstr := range want {
-		require.Contains(t, got, wantstr)
-	}
-}
-
-func TestCreateLookupVindexFull(t *testing.T) {
-	ms := &vtctldatapb.MaterializeSettings{
-		Workflow:       "lkp_vdx",
-		SourceKeyspace: "sourceks",
-		TargetKeyspace: "targetks",
-	}
-	env := newTestMaterializerEnv(t, ms, []string{"0"}, []string{"0"})
-	defer env.close()
-
-	specs := &vschemapb.Keyspace{
-		Vindexes: map[string]*vschemapb.Vindex{
-			"v": {
-				Type: "lookup_unique",
-				Params: map[string]string{
-					"table": "targetks.lkp",
-					"from":  "c1",
-					"to":    "c2",
-				},
-				Owner: "t1",
-			},
-		},
-		Tables: map[string]*vschemapb.Table{
-			"t1": {
-				ColumnVindexes: []*vschemapb.ColumnVindex{{
-					Name:   "v",
-					Column: "col2",
-				}},
-			},
-		},
-	}
-	// Dummy sourceSchema
-	sourceSchema := "CREATE TABLE `t1` (\n" +
-		"  `col1` int(11) NOT NULL AUTO_INCREMENT,\n" +
-		"  `col2` int(11) DEFAULT NULL,\n" +
-		"  PRIMARY KEY (`id`)\n" +
-		") ENGINE=InnoDB AUTO_INCREMENT=3 DEFAULT CHARSET=latin1"
-
-	sourceVSchema := &vschemapb.Keyspace{
-		Sharded: true,
-		Vindexes: map[string]*vschemapb.Vindex{
-			"hash": {
-				Type: "hash",
-			},
-		},
-		Tables: map[string]*vschemapb.Table{
-			"t1": {
-				ColumnVindexes: []*vschemapb.ColumnVindex{{
-					Name:   "hash",
-					Column: "col1",
-				}},
-			},
-		},
-	}
-	env.tmc.schema[ms.SourceKeyspace+".t1"] = &tabletmanagerdatapb.SchemaDefinition{
-		TableDefinitions: []*tabletmanagerdatapb.TableDefinition{{
-			Fields: []*querypb.Field{{
-				Name: "col1",
-				Type: querypb.Type_INT64,
-			}, {
-				Name: "col2",
-				Type: querypb.Type_INT64,
-			}},
-			Schema: sourceSchema,
-		}},
-	}
-	if err := env.topoServ.SaveVSchema(context.Background(), ms.TargetKeyspace, &vschemapb.Keyspace{}); err != nil {
-		t.Fatal(err)
-	}
-	if err := env.topoServ.SaveVSchema(context.Background(), ms.SourceKeyspace, sourceVSchema); err != nil {
-		t.Fatal(err)
-	}
-
-	env.tmc.expectVRQuery(100, mzCheckJournal, &sqltypes.Result{})
-	env.tmc.expectVRQuery(200, mzSelectFrozenQuery, &sqltypes.Result{})
-	env.tmc.expectVRQuery(200, "/CREATE TABLE `lkp`", &sqltypes.Result{})
-	env.tmc.expectVRQuery(200, insertPrefix, &sqltypes.Result{})
-	env.tmc.expectVRQuery(200, "update _vt.vreplication set state='Running' where db_name='vt_targetks' and workflow='lkp_vdx'", &sqltypes.Result{})
-
-	ctx := context.Background()
-	err := env.wr.CreateLookupVindex(ctx, ms.SourceKeyspace, specs, "cell", "PRIMARY", false)
-	require.NoError(t, err)
-
-	wantvschema := &vschemapb.Keyspace{
-		Sharded: true,
-		Vindexes: map[string]*vschemapb.Vindex{
-			"hash": {
-				Type: "hash",
-			},
-			"v": {
-				Type: "lookup_unique",
-				Params: map[string]string{
-					"table":      "targetks.lkp",
-					"from":       "c1",
-					"to":         "c2",
-					"write_only": "true",
-				},
-				Owner: "t1",
-			},
-		},
-		Tables: map[string]*vschemapb.Table{
-			"t1": {
-				ColumnVindexes: []*vschemapb.ColumnVindex{{
-					Name:   "hash",
-					Column: "col1",
-				}, {
-					Name:   "v",
-					Column: "col2",
-				}},
-			},
-		},
-	}
-	vschema, err := env.topoServ.GetVSchema(ctx, ms.SourceKeyspace)
-	require.NoError(t, err)
-	utils.MustMatch(t, wantvschema, vschema)
-
-	wantvschema = &vschemapb.Keyspace{
-		Tables: map[string]*vschemapb.Table{
-			"lkp": {},
-		},
-	}
-	vschema, err = env.topoServ.GetVSchema(ctx, ms.TargetKeyspace)
-	require.NoError(t, err)
-	utils.MustMatch(t, wantvschema, vschema)
-}
-
-func TestCreateLookupVindexCreateDDL(t *testing.T) {
-	ms := &vtctldatapb.MaterializeSettings{
-		SourceKeyspace: "sourceks",
-		TargetKeyspace: "targetks",
-	}
-	env := newTestMaterializerEnv(t, ms, []string{"0"}, []string{"0"})
-	defer env.close()
-	vs := &vschemapb.Keyspace{
-		Sharded: true,
-		Vindexes: map[string]*vschemapb.Vindex{
-			"hash": {
-				Type: "hash",
-			},
-		},
-		Tables: map[string]*vschemapb.Table{
-			"t1": {
-				ColumnVindexes: []*vschemapb.ColumnVindex{{
-					Column: "col1",
-					Name:   "hash",
-				}},
-			},
-		},
-	}
-	if err := env.topoServ.SaveVSchema(context.Background(), ms.SourceKeyspace, vs); err != nil {
-		t.Fatal(err)
-	}
-
-	testcases := []struct {
-		description  string
-		specs        *vschemapb.Keyspace
-		sourceSchema string
-		out          string
-		err          string
-	}{{
-		description: "unique lookup",
-		specs: &vschemapb.Keyspace{
-			Vindexes: map[string]*vschemapb.Vindex{
-				"v": {
-					Type: "lookup_unique",
-					Params: map[string]string{
-						"table": fmt.Sprintf("%s.lkp", ms.TargetKeyspace),
-						"from":  "c1",
-						"to":    "c2",
-					},
-					Owner: "t1",
-				},
-			},
-			Tables: map[string]*vschemapb.Table{
-				"t1": {
-					ColumnVindexes: []*vschemapb.ColumnVindex{{
-						Name:   "v",
-						Column: "col2",
-					}},
-				},
-			},
-		},
-		sourceSchema: "CREATE TABLE `t1` (\n" +
-			"  `col1` int(11) NOT NULL AUTO_INCREMENT,\n" +
-			"  `col2` int(11) DEFAULT NULL,\n" +
-			"  `col3` int(11) DEFAULT NULL,\n" +
-			"  PRIMARY KEY (`id`)\n" +
-			") ENGINE=InnoDB AUTO_INCREMENT=3 DEFAULT CHARSET=latin1",
-		out: "CREATE TABLE `lkp` (\n" +
-			"  `c1` int(11),\n" +
-			"  `c2` varbinary(128),\n" +
-			"  PRIMARY KEY (`c1`)\n" +
-			")",
-	}, {
-		description: "unique lookup, also pk",
-		specs: &vschemapb.Keyspace{
-			Vindexes: map[string]*vschemapb.Vindex{
-				"v": {
-					Type: "lookup_unique",
-					Params: map[string]string{
-						"table": fmt.Sprintf("%s.lkp", ms.TargetKeyspace),
-						"from":  "c1",
-						"to":    "c2",
-					},
-					Owner: "t1",
-				},
-			},
-			Tables: map[string]*vschemapb.Table{
-				"t1": {
-					ColumnVindexes: []*vschemapb.ColumnVindex{{
-						Name:   "v",
-						Column: "col2",
-					}},
-				},
-			},
-		},
-		sourceSchema: "CREATE TABLE `t1` (\n" +
-			"  `col2` int(11) NOT NULL AUTO_INCREMENT,\n" +
-			"  `col1` int(11) DEFAULT NULL,\n" +
-			"  `col4` int(11) DEFAULT NULL,\n" +
-			"  PRIMARY KEY (`id`)\n" +
-			") ENGINE=InnoDB AUTO_INCREMENT=3 DEFAULT CHARSET=latin1",
-		out: "CREATE TABLE `lkp` (\n" +
-			"  `c1` int(11) NOT NULL,\n" +
-			"  `c2` varbinary(128),\n" +
-			"  PRIMARY KEY (`c1`)\n" +
-			")",
-	}, {
-		description: "non-unique lookup, also pk",
-		specs: &vschemapb.Keyspace{
-			Vindexes: map[string]*vschemapb.Vindex{
-				"v": {
-					Type: "lookup",
-					Params: map[string]string{
-						"table": fmt.Sprintf("%s.lkp", ms.TargetKeyspace),
-						"from":  "c1,c2",
-						"to":    "c3",
-					},
-					Owner: "t1",
-				},
-			},
-			Tables: map[string]*vschemapb.Table{
-				"t1": {
-					ColumnVindexes: []*vschemapb.ColumnVindex{{
-						Name:    "v",
-						Columns: []string{"col2", "col1"},
-					}},
-				},
-			},
-		},
-		sourceSchema: "CREATE TABLE `t1` (\n" +
-			"  `col1` int(11) NOT NULL AUTO_INCREMENT,\n" +
-			"  `col2` int(11) NOT NULL,\n" +
-			"  `col3` int(11) DEFAULT NULL,\n" +
-			"  PRIMARY KEY (`id`)\n" +
-			") ENGINE=InnoDB AUTO_INCREMENT=3 DEFAULT CHARSET=latin1",
-		out: "CREATE TABLE `lkp` (\n" +
-			"  `c1` int(11) NOT NULL,\n" +
-			"  `c2` int(11) NOT NULL,\n" +
-			"  `c3` varbinary(128),\n" +
-			"  PRIMARY KEY (`c1`, `c2`)\n" +
-			")",
-	}, {
-		description: "column missing",
-		specs: &vschemapb.Keyspace{
-			Vindexes: map[string]*vschemapb.Vindex{
-				"v": {
-					Type: "lookup_unique",
-					Params: map[string]string{
-						"table": fmt.Sprintf("%s.lkp", ms.TargetKeyspace),
-						"from":  "c1",
-						"to":    "c2",
-					},
-					Owner: "t1",
-				},
-			},
-			Tables: map[string]*vschemapb.Table{
-				"t1": {
-					ColumnVindexes: []*vschemapb.ColumnVindex{{
-						Name:   "v",
-						Column: "nocol",
-					}},
-				},
-			},
-		},
-		sourceSchema: "CREATE TABLE `t1` (\n" +
-			"  `col1` int(11) NOT NULL AUTO_INCREMENT,\n" +
-			"  `col2` int(11) NOT NULL,\n" +
-			"  `col3` int(11) DEFAULT NULL,\n" +
-			"  PRIMARY KEY (`id`)\n" +
-			") ENGINE=InnoDB AUTO_INCREMENT=3 DEFAULT CHARSET=latin1",
-		err: "column nocol not found in schema",
-	}, {
-		description: "no table in schema",
-		specs: &vschemapb.Keyspace{
-			Vindexes: map[string]*vschemapb.Vindex{
-				"v": {
-					Type: "lookup_unique",
-					Params: map[string]string{
-						"table": fmt.Sprintf("%s.lkp", ms.TargetKeyspace),
-						"from":  "c1",
-						"to":    "c2",
-					},
-					Owner: "t1",
-				},
-			},
-			Tables: map[string]*vschemapb.Table{
-				"t1": {
-					ColumnVindexes: []*vschemapb.ColumnVindex{{
-						Name:   "v",
-						Column: "nocol",
-					}},
-				},
-			},
-		},
-		sourceSchema: "",
-		err:          "unexpected number of tables returned from schema",
-	}}
-	for _, tcase := range testcases {
-		if tcase.sourceSchema != "" {
-			env.tmc.schema[ms.SourceKeyspace+".t1"] = &tabletmanagerdatapb.SchemaDefinition{
-				TableDefinitions: []*tabletmanagerdatapb.TableDefinition{{
-					Schema: tcase.sourceSchema,
-				}},
-			}
-		} else {
-			delete(env.tmc.schema, ms.SourceKeyspace+".t1")
-		}
-
-		outms, _, _, err := env.wr.prepareCreateLookup(context.Background(), ms.SourceKeyspace, tcase.specs, false)
-		if tcase.err != "" {
-			if err == nil || !strings.Contains(err.Error(), tcase.err) {
-				t.Errorf("prepareCreateLookup(%s) err: %v, must contain %v", tcase.description, err, tcase.err)
-			}
-			continue
-		}
-		require.NoError(t, err)
-		want := strings.Split(tcase.out, "\n")
-		got := strings.Split(outms.TableSettings[0].CreateDdl, "\n")
-		require.Equal(t, want, got, tcase.description)
-	}
-}
-
-func TestCreateLookupVindexSourceVSchema(t *testing.T) {
-	ms := &vtctldatapb.MaterializeSettings{
-		SourceKeyspace: "sourceks",
-		TargetKeyspace: "targetks",
-	}
-	env := newTestMaterializerEnv(t, ms, []string{"0"}, []string{"0"})
-	defer env.close()
-
-	specs := &vschemapb.Keyspace{
-		Vindexes: map[string]*vschemapb.Vindex{
-			"v": {
-				Type: "lookup_unique",
-				Params: map[string]string{
-					"table": "targetks.lkp",
-					"from":  "c1",
-					"to":    "c2",
-				},
-				Owner: "t1",
-			},
-		},
-		Tables: map[string]*vschemapb.Table{
-			"t1": {
-				ColumnVindexes: []*vschemapb.ColumnVindex{{
-					Name:   "v",
-					Column: "col2",
-				}},
-			},
-		},
-	}
-	// Dummy sourceSchema
-	sourceSchema := "CREATE TABLE `t1` (\n" +
-		"  `col1` int(11) NOT NULL AUTO_INCREMENT,\n" +
-		"  `col2` int(11) DEFAULT NULL,\n" +
-		"  PRIMARY KEY (`id`)\n" +
-		") ENGINE=InnoDB AUTO_INCREMENT=3 DEFAULT CHARSET=latin1"
-
-	testcases := []struct {
-		description   string
-		sourceVSchema *vschemapb.Keyspace
-		out           *vschemapb.Keyspace
-	}{{
-		description: "source vschema has no prior info",
-		sourceVSchema: &vschemapb.Keyspace{
-			Sharded: true,
-			Vindexes: map[string]*vschemapb.Vindex{
-				"hash": {
-					Type: "hash",
-				},
-			},
-			Tables: map[string]*vschemapb.Table{
-				"t1": {
-					ColumnVindexes: []*vschemapb.ColumnVindex{{
-						Name:   "hash",
-						Column: "col1",
-					}},
-				},
-			},
-		},
-		out: &vschemapb.Keyspace{
-			Sharded: true,
-			Vindexes: map[string]*vschemapb.Vindex{
-				"hash": {
-					Type: "hash",
-				},
-				"v": {
-					Type: "lookup_unique",
-					Params: map[string]string{
-						"table":      "targetks.lkp",
-						"from":       "c1",
-						"to":         "c2",
-						"write_only": "true",
-					},
-					Owner: "t1",
-				},
-			},
-			Tables: map[string]*vschemapb.Table{
-				"t1": {
-					ColumnVindexes: []*vschemapb.ColumnVindex{{
-						Name:   "hash",
-						Column: "col1",
-					}, {
-						Name:   "v",
-						Column: "col2",
-					}},
-				},
-			},
-		},
-	}, {
-		description: "source vschema has the lookup vindex",
-		sourceVSchema: &vschemapb.Keyspace{
-			Sharded: true,
-			Vindexes: map[string]*vschemapb.Vindex{
-				"hash": {
-					Type: "hash",
-				},
-				"v": {
-					Type: "lookup_unique",
-					Params: map[string]string{
-						"table":      "targetks.lkp",
-						"from":       "c1",
-						"to":         "c2",
-						"write_only": "true",
-					},
-					Owner: "t1",
-				},
-			},
-			Tables: map[string]*vschemapb.Table{
-				"t1": {
-					ColumnVindexes: []*vschemapb.ColumnVindex{{
-						Name:   "hash",
-						Column: "col1",
-					}},
-				},
-			},
-		},
-		out: &vschemapb.Keyspace{
-			Sharded: true,
-			Vindexes: map[string]*vschemapb.Vindex{
-				"hash": {
-					Type: "hash",
-				},
-				"v": {
-					Type: "lookup_unique",
-					Params: map[string]string{
-						"table":      "targetks.lkp",
-						"from":       "c1",
-						"to":         "c2",
-						"write_only": "true",
-					},
-					Owner: "t1",
-				},
-			},
-			Tables: map[string]*vschemapb.Table{
-				"t1": {
-					ColumnVindexes: []*vschemapb.ColumnVindex{{
-						Name:   "hash",
-						Column: "col1",
-					}, {
-						Name:   "v",
-						Column: "col2",
-					}},
-				},
-			},
-		},
-	}, {
-		description: "source vschema table has a different vindex on same column",
-		sourceVSchema: &vschemapb.Keyspace{
-			Sharded: true,
-			Vindexes: map[string]*vschemapb.Vindex{
-				"hash": {
-					Type: "hash",
-				},
-				"v": {
-					Type: "lookup_unique",
-					Params: map[string]string{
-						"table":      "targetks.lkp",
-						"from":       "c1",
-						"to":         "c2",
-						"write_only": "true",
-					},
-					Owner: "t1",
-				},
-			},
-			Tables: map[string]*vschemapb.Table{
-				"t1": {
-					ColumnVindexes: []*vschemapb.ColumnVindex{{
-						Name:   "hash",
-						Column: "col1",
-					}, {
-						Name:   "hash",
-						Column: "col2",
-					}},
-				},
-			},
-		},
-		out: &vschemapb.Keyspace{
-			Sharded: true,
-			Vindexes: map[string]*vschemapb.Vindex{
-				"hash": {
-					Type: "hash",
-				},
-				"v": {
-					Type: "lookup_unique",
-					Params: map[string]string{
-						"table":      "targetks.lkp",
-						"from":       "c1",
-						"to":         "c2",
-						"write_only": "true",
-					},
-					Owner: "t1",
-				},
-			},
-			Tables: map[string]*vschemapb.Table{
-				"t1": {
-					ColumnVindexes: []*vschemapb.ColumnVindex{{
-						Name:   "hash",
-						Column: "col1",
-					}, {
-						Name:   "hash",
-						Column: "col2",
-					}, {
-						Name:   "v",
-						Column: "col2",
-					}},
-				},
-			},
-		},
-	}}
-	for _, tcase := range testcases {
-		env.tmc.schema[ms.SourceKeyspace+".t1"] = &tabletmanagerdatapb.SchemaDefinition{
-			TableDefinitions: []*tabletmanagerdatapb.TableDefinition{{
-				Fields: []*querypb.Field{{
-					Name: "col1",
-					Type: querypb.Type_INT64,
-				}, {
-					Name: "col2",
-					Type: querypb.Type_INT64,
-				}},
-				Schema: sourceSchema,
-			}},
-		}
-		if err := env.topoServ.SaveVSchema(context.Background(), ms.TargetKeyspace, &vschemapb.Keyspace{}); err != nil {
-			t.Fatal(err)
-		}
-		if err := env.topoServ.SaveVSchema(context.Background(), ms.SourceKeyspace, tcase.sourceVSchema); err != nil {
-			t.Fatal(err)
-		}
-
-		_, got, _, err := env.wr.prepareCreateLookup(context.Background(), ms.SourceKeyspace, specs, false)
-		require.NoError(t, err)
-		if !proto.Equal(got, tcase.out) {
-			t.Errorf("%s: got:\n%v, want\n%v", tcase.description, got, tcase.out)
-		}
-	}
-}
-
-func TestCreateLookupVindexTargetVSchema(t *testing.T) {
-	ms := &vtctldatapb.MaterializeSettings{
-		SourceKeyspace: "sourceks",
-		TargetKeyspace: "targetks",
-	}
-	env := newTestMaterializerEnv(t, ms, []string{"0"}, []string{"0"})
-	defer env.close()
-	sourcevs := &vschemapb.Keyspace{
-		Sharded: true,
-		Vindexes: map[string]*vschemapb.Vindex{
-			"hash": {
-				Type: "hash",
-			},
-		},
-		Tables: map[string]*vschemapb.Table{
-			"t1": {
-				ColumnVindexes: []*vschemapb.ColumnVindex{{
-					Column: "col1",
-					Name:   "hash",
-				}},
-			},
-		},
-	}
-	if err := env.topoServ.SaveVSchema(context.Background(), ms.SourceKeyspace, sourcevs); err != nil {
-		t.Fatal(err)
-	}
-
-	// withTable is a target vschema with a pre-existing table.
-	withTable := &vschemapb.Keyspace{
-		Sharded: true,
-		Vindexes: map[string]*vschemapb.Vindex{
-			"hash": {
-				Type: "hash",
-			},
-		},
-		Tables: map[string]*vschemapb.Table{
-			"t2": {
-				ColumnVindexes: []*vschemapb.ColumnVindex{{
-					Column: "c1",
-					Name:   "hash",
-				}},
-			},
-		},
-	}
-
-	specs := &vschemapb.Keyspace{
-		Vindexes: map[string]*vschemapb.Vindex{
-			"v": {
-				Type: "lookup_unique",
-				Params: map[string]string{
-					"table": "will be set by the test case",
-					"from":  "c1",
-					"to":    "c2",
-				},
-				Owner: "t1",
-			},
-		},
-		Tables: map[string]*vschemapb.Table{
-			"t1": {
-				ColumnVindexes: []*vschemapb.ColumnVindex{{
-					Name:   "v",
-					Column: "col2",
-				}},
-			},
-		},
-	}
-	// Dummy sourceSchema
-	sourceSchema := "CREATE TABLE `t1` (\n" +
-		"  `col1` int(11) NOT NULL AUTO_INCREMENT,\n" +
-		"  `col2` int(11) DEFAULT NULL,\n" +
-		"  PRIMARY KEY (`id`)\n" +
-		") ENGINE=InnoDB AUTO_INCREMENT=3 DEFAULT CHARSET=latin1"
-
-	testcases := []struct {
-		description     string
-		targetTable     string
-		sourceFieldType querypb.Type
-		targetVSchema   *vschemapb.Keyspace
-		out             *vschemapb.Keyspace
-		err             string
-	}{{
-		description:     "sharded, int64, empty target",
-		targetTable:     "lkp",
-		sourceFieldType: querypb.Type_INT64,
-		targetVSchema:   &vschemapb.Keyspace{Sharded: true},
-		out: &vschemapb.Keyspace{
-			Sharded: true,
-			Vindexes: map[string]*vschemapb.Vindex{
-				"hash": {
-					Type: "hash",
-				},
-			},
-			Tables: map[string]*vschemapb.Table{
-				"lkp": {
-					ColumnVindexes: []*vschemapb.ColumnVindex{{
-						Column: "c1",
-						Name:   "hash",
-					}},
-				},
-			},
-		},
-	}, {
-		description:     "sharded, varchar, empty target",
-		targetTable:     "lkp",
-		sourceFieldType: querypb.Type_VARCHAR,
-		targetVSchema:   &vschemapb.Keyspace{Sharded: true},
-		out: &vschemapb.Keyspace{
-			Sharded: true,
-			Vindexes: map[string]*vschemapb.Vindex{
-				"unicode_loose_md5": {
-					Type: "unicode_loose_md5",
-				},
-			},
-			Tables: map[string]*vschemapb.Table{
-				"lkp": {
-					ColumnVindexes: []*vschemapb.ColumnVindex{{
-						Column: "c1",
-						Name:   "unicode_loose_md5",
-					}},
-				},
-			},
-		},
-	}, {
-		description:     "sharded, int64, good vindex",
-		targetTable:     "lkp",
-		sourceFieldType: querypb.Type_INT64,
-		targetVSchema: &vschemapb.Keyspace{
-			Sharded: true,
-			Vindexes: map[string]*vschemapb.Vindex{
-				// Create a misleading vindex name.
-				"hash": {
-					Type: "hash",
-				},
-			},
-		},
-		out: &vschemapb.Keyspace{
-			Sharded: true,
-			Vindexes: map[string]*vschemapb.Vindex{
-				"hash": {
-					Type: "hash",
-				},
-			},
-			Tables: map[string]*vschemapb.Table{
-				"lkp": {
-					ColumnVindexes: []*vschemapb.ColumnVindex{{
-						Column: "c1",
-						Name:   "hash",
-					}},
-				},
-			},
-		},
-	}, {
-		description:     "sharded, int64, bad vindex",
-		targetTable:     "lkp",
-		sourceFieldType: querypb.Type_INT64,
-		targetVSchema: &vschemapb.Keyspace{
-			Sharded: true,
-			Vindexes: map[string]*vschemapb.Vindex{
-				// Create a misleading vindex name.
-				"hash": {
-					Type: "unicode_loose_md5",
-				},
-			},
-		},
-		err: "a conflicting vindex named hash already exists in the target vschema",
-	}, {
-		description:     "sharded, int64, good table",
-		targetTable:     "t2",
-		sourceFieldType: querypb.Type_INT64,
-		targetVSchema:   withTable,
-		out: &vschemapb.Keyspace{
-			Sharded: true,
-			Vindexes: map[string]*vschemapb.Vindex{
-				"hash": {
-					Type: "hash",
-				},
-			},
-			Tables: map[string]*vschemapb.Table{
-				"t2": {
-					ColumnVindexes: []*vschemapb.ColumnVindex{{
-						Column: "c1",
-						Name:   "hash",
-					}},
-				},
-			},
-		},
-	}, {
-		description:     "sharded, int64, table mismatch",
-		targetTable:     "t2",
-		sourceFieldType: querypb.Type_VARCHAR,
-		targetVSchema:   withTable,
-		err:             "a conflicting table named t2 already exists in the target vschema",
-	}, {
-		description:     "unsharded",
-		targetTable:     "lkp",
-		sourceFieldType: querypb.Type_INT64,
-		targetVSchema:   &vschemapb.Keyspace{},
-		out: &vschemapb.Keyspace{
-			Vindexes: map[string]*vschemapb.Vindex{},
-			Tables: map[string]*vschemapb.Table{
-				"lkp": {},
-			},
-		},
-	}, {
-		description:     "invalid column type",
-		targetTable:     "lkp",
-		sourceFieldType: querypb.Type_SET,
-		targetVSchema:   &vschemapb.Keyspace{Sharded: true},
-		err:             "type SET is not recommended for a vindex",
-	}}
-	for _, tcase := range testcases {
-		env.tmc.schema[ms.SourceKeyspace+".t1"] = &tabletmanagerdatapb.SchemaDefinition{
-			TableDefinitions: []*tabletmanagerdatapb.TableDefinition{{
-				Fields: []*querypb.Field{{
-					Name: "col2",
-					Type: tcase.sourceFieldType,
-				}},
-				Schema: sourceSchema,
-			}},
-		}
-		specs.Vindexes["v"].Params["table"] = fmt.Sprintf("%s.%s", ms.TargetKeyspace, tcase.targetTable)
-		if err := env.topoServ.SaveVSchema(context.Background(), ms.TargetKeyspace, tcase.targetVSchema); err != nil {
-			t.Fatal(err)
-		}
-
-		_, _, got, err := env.wr.prepareCreateLookup(context.Background(), ms.SourceKeyspace, specs, false)
-		if tcase.err != "" {
-			if err == nil || !strings.Contains(err.Error(), tcase.err) {
-				t.Errorf("prepareCreateLookup(%s) err: %v, must contain %v", tcase.description, err, tcase.err)
-			}
-			continue
-		}
-		require.NoError(t, err)
-		utils.MustMatch(t, tcase.out, got, tcase.description)
-	}
-}
-
-func TestCreateLookupVindexSameKeyspace(t *testing.T) {
-	ms := &vtctldatapb.MaterializeSettings{
-		SourceKeyspace: "ks",
-		TargetKeyspace: "ks",
-	}
-	env := newTestMaterializerEnv(t, ms, []string{"0"}, []string{"0"})
-	defer env.close()
-
-	specs := &vschemapb.Keyspace{
-		Vindexes: map[string]*vschemapb.Vindex{
-			"v": {
-				Type: "lookup_unique",
-				Params: map[string]string{
-					"table": "ks.lkp",
-					"from":  "c1",
-					"to":    "c2",
-				},
-				Owner: "t1",
-			},
-		},
-		Tables: map[string]*vschemapb.Table{
-			"t1": {
-				ColumnVindexes: []*vschemapb.ColumnVindex{{
-					Name:   "v",
-					Column: "col2",
-				}},
-			},
-		},
-	}
-	// Dummy sourceSchema
-	sourceSchema := "CREATE TABLE `t1` (\n" +
-		"  `col1` int(11) NOT NULL AUTO_INCREMENT,\n" +
-		"  `col2` int(11) DEFAULT NULL,\n" +
-		"  PRIMARY KEY (`id`)\n" +
-		") ENGINE=InnoDB AUTO_INCREMENT=3 DEFAULT CHARSET=latin1"
-
-	vschema := &vschemapb.Keyspace{
-		Sharded: true,
-		Vindexes: map[string]*vschemapb.Vindex{
-			"hash": {
-				Type: "hash",
-			},
-		},
-		Tables: map[string]*vschemapb.Table{
-			"t1": {
-				ColumnVindexes: []*vschemapb.ColumnVindex{{
-					Name:   "hash",
-					Column: "col1",
-				}},
-			},
-		},
-	}
-	want := &vschemapb.Keyspace{
-		Sharded: true,
-		Vindexes: map[string]*vschemapb.Vindex{
-			"hash": {
-				Type: "hash",
-			},
-			"v": {
-				Type: "lookup_unique",
-				Params: map[string]string{
-					"table":      "ks.lkp",
-					"from":       "c1",
-					"to":         "c2",
-					"write_only": "true",
-				},
-				Owner: "t1",
-			},
-		},
-		Tables: map[string]*vschemapb.Table{
-			"t1": {
-				ColumnVindexes: []*vschemapb.ColumnVindex{{
-					Name:   "hash",
-					Column: "col1",
-				}, {
-					Name:   "v",
-					Column: "col2",
-				}},
-			},
-			"lkp": {
-				ColumnVindexes: []*vschemapb.ColumnVindex{{
-					Column: "c1",
-					Name:   "hash",
-				}},
-			},
-		},
-	}
-	env.tmc.schema[ms.SourceKeyspace+".t1"] = &tabletmanagerdatapb.SchemaDefinition{
-		TableDefinitions: []*tabletmanagerdatapb.TableDefinition{{
-			Fields: []*querypb.Field{{
-				Name: "col1",
-				Type: querypb.Type_INT64,
-			}, {
-				Name: "col2",
-				Type: querypb.Type_INT64,
-			}},
-			Schema: sourceSchema,
+
+func TestMoveTablesStopFlags(t *testing.T) {
+	ms := &vtctldatapb.MaterializeSettings{
+		Workflow:       "workflow",
+		SourceKeyspace: "sourceks",
+		TargetKeyspace: "targetks",
+		TableSettings: []*vtctldatapb.TableMaterializeSettings{{
+			TargetTable:      "t1",
+			SourceExpression: "select * from t1",
 		}},
 	}
-	if err := env.topoServ.SaveVSchema(context.Background(), ms.SourceKeyspace, vschema); err != nil {
-		t.Fatal(err)
+
+	ctx := context.Background()
+	var err error
+	t.Run("StopStartedAndStopAfterCopyFlags", func(t *testing.T) {
+		env := newTestMaterializerEnv(t, ms, []string{"0"}, []string{"0"})
+		defer env.close()
+		env.tmc.expectVRQuery(100, mzCheckJournal, &sqltypes.Result{})
+		env.tmc.expectVRQuery(200, mzSelectFrozenQuery, &sqltypes.Result{})
+		// insert expects flag stop_after_copy to be true
+		insert := `/insert into _vt.vreplication\(workflow, source, pos, max_tps, max_replication_lag, cell, tablet_types.*stop_after_copy:true.*`
+
+		env.tmc.expectVRQuery(200, insert, &sqltypes.Result{})
+		env.tmc.expectVRQuery(200, mzSelectIDQuery, &sqltypes.Result{})
+		// -auto_start=false is tested by NOT expecting the update query which sets state to RUNNING
+		err = env.wr.MoveTables(ctx, "workflow", "sourceks", "targetks", "t1", "",
+			"", false, "", false, true, "", false, false, "", defaultOnDDL, nil, "", "")
+		require.NoError(t, err)
+		env.tmc.verifyQueries(t)
+	})
+}
+
+func TestMigrateVSchema(t *testing.T) {
+	ms := &vtctldatapb.MaterializeSettings{
+		Workflow:       "workflow",
+		SourceKeyspace: "sourceks",
+		TargetKeyspace: "targetks",
+		TableSettings: []*vtctldatapb.TableMaterializeSettings{{
+			TargetTable:      "t1",
+			SourceExpression: "select * from t1",
+		}},
 	}
+	env := newTestMaterializerEnv(t, ms, []string{"0"}, []string{"0"})
+	defer env.close()
+
+	env.tmc.expectVRQuery(100, mzCheckJournal, &sqltypes.Result{})
+	env.tmc.expectVRQuery(200, mzSelectFrozenQuery, &sqltypes.Result{})
+	env.tmc.expectVRQuery(200, insertPrefix, &sqltypes.Result{})
+	env.tmc.expectVRQuery(200, mzSelectIDQuery, &sqltypes.Result{})
+	env.tmc.expectVRQuery(200, mzUpdateQuery, &sqltypes.Result{})
 
-	_, got, _, err := env.wr.prepareCreateLookup(context.Background(), ms.SourceKeyspace, specs, false)
+	ctx := context.Background()
+	err := env.wr.MoveTables(ctx, "workflow", "sourceks", "targetks", `{"t1":{}}`, "", "", false, "", true, false, "", false, false, "", defaultOnDDL, nil, "", "")
 	require.NoError(t, err)
-	if !proto.Equal(got, want) {
-		t.Errorf("same keyspace: got:\n%v, want\n%v", got, want)
+	vschema, err := env.wr.ts.GetSrvVSchema(ctx, env.cell)
+	require.NoError(t, err)
+	got := fmt.Sprintf("%v", vschema)
+	want := []string{`keyspaces:{key:"sourceks" value:{}}`,
+		`keyspaces:{key:"sourceks" value:{}} keyspaces:{key:"targetks" value:{tables:{key:"t1" value:{}}}}`,
+		`rules:{from_table:"t1" to_tables:"sourceks.t1"}`,
+		`rules:{from_table:"targetks.t1" to_tables:"sourceks.t1"}`,
+	}
+	for _, wantstr := range want {
+		require.Contains(t, got, wantstr)
 	}
 }
-func TestCreateCustomizedVindex(t *testing.T) {
+
+func TestCreateLookupVindexFull(t *testing.T) {
 	ms := &vtctldatapb.MaterializeSettings{
-		SourceKeyspace: "ks",
-		TargetKeyspace: "ks",
+		Workflow:       "lkp_vdx",
+		SourceKeyspace: "sourceks",
+		TargetKeyspace: "targetks",
 	}
 	env := newTestMaterializerEnv(t, ms, []string{"0"}, []string{"0"})
 	defer env.close()
@@ -1180,10 +299,9 @@ func TestCreateCustomizedVindex(t *testing.T) {
 			"v": {
 				Type: "lookup_unique",
 				Params: map[string]string{
-					"table":     "ks.lkp",
-					"from":      "c1",
-					"to":        "col2",
-					"data_type": "bigint(20)",
+					"table": "targetks.lkp",
+					"from":  "c1",
+					"to":    "c2",
 				},
 				Owner: "t1",
 			},
@@ -1204,54 +322,18 @@ func TestCreateCustomizedVindex(t *testing.T) {
 		"  PRIMARY KEY (`id`)\n" +
 		") ENGINE=InnoDB AUTO_INCREMENT=3 DEFAULT CHARSET=latin1"
 
-	vschema := &vschemapb.Keyspace{
-		Sharded: true,
-		Vindexes: map[string]*vschemapb.Vindex{
-			"hash": {
-				Type: "hash",
-			},
-		},
-		Tables: map[string]*vschemapb.Table{
-			"t1": {
-				ColumnVindexes: []*vschemapb.ColumnVindex{{
-					Name:   "hash",
-					Column: "col1",
-				}},
-			},
-		},
-	}
-	want := &vschemapb.Keyspace{
+	sourceVSchema := &vschemapb.Keyspace{
 		Sharded: true,
 		Vindexes: map[string]*vschemapb.Vindex{
 			"hash": {
 				Type: "hash",
 			},
-			"v": {
-				Type: "lookup_unique",
-				Params: map[string]string{
-					"table":      "ks.lkp",
-					"from":       "c1",
-					"to":         "col2",
-					"data_type":  "bigint(20)",
-					"write_only": "true",
-				},
-				Owner: "t1",
-			},
 		},
 		Tables: map[string]*vschemapb.Table{
 			"t1": {
 				ColumnVindexes: []*vschemapb.ColumnVindex{{
 					Name:   "hash",
 					Column: "col1",
-				}, {
-					Name:   "v",
-					Column: "col2",
-				}},
-			},
-			"lkp": {
-				ColumnVindexes: []*vschemapb.ColumnVindex{{
-					Column: "c1",
-					Name:   "hash",
 				}},
 			},
 		},
@@ -1268,32 +350,36 @@ func TestCreateCustomizedVindex(t *testing.T) {
 			Schema: sourceSchema,
 		}},
 	}
-	if err := env.topoServ.SaveVSchema(context.Background(), ms.SourceKeyspace, vschema); err != nil {
+	if err := env.topoServ.SaveVSchema(context.Background(), ms.TargetKeyspace, &vschemapb.Keyspace{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := env.topoServ.SaveVSchema(context.Background(), ms.SourceKeyspace, sourceVSchema); err != nil {
 		t.Fatal(err)
 	}
 
-	_, got, _, err := env.wr.prepareCreateLookup(context.Background(), ms.SourceKeyspace, specs, false)
+	env.tmc.expectVRQuery(100, mzCheckJournal, &sqltypes.Result{})
+	env.tmc.expectVRQuery(200, mzSelectFrozenQuery, &sqltypes.Result{})
+	env.tmc.expectVRQuery(200, "/CREATE TABLE `lkp`", &sqltypes.Result{})
+	env.tmc.expectVRQuery(200, insertPrefix, &sqltypes.Result{})
+	env.tmc.expectVRQuery(200, "update _vt.vreplication set state='Running' where db_name='vt_targetks' and workflow='lkp_vdx'", &sqltypes.Result{})
+
+	ctx := context.Background()
+	err := env.wr.CreateLookupVindex(ctx, ms.SourceKeyspace, specs, "cell", "PRIMARY", false)
 	require.NoError(t, err)
-	if !proto.Equal(got, want) {
-		t.Errorf("customize create lookup error same: got:\n%v, want\n%v", got, want)
-	}
-}
 
-func TestStopAfterCopyFlag(t *testing.T) {
-	ms := &vtctldatapb.MaterializeSettings{
-		SourceKeyspace: "ks",
-		TargetKeyspace: "ks",
-	}
-	env := newTestMaterializerEnv(t, ms, []string{"0"}, []string{"0"})
-	defer env.close()
-	specs := &vschemapb.Keyspace{
+	wantvschema := &vschemapb.Keyspace{
+		Sharded: true,
 		Vindexes: map[string]*vschemapb.Vindex{
+			"hash": {
+				Type: "hash",
+			},
 			"v": {
 				Type: "lookup_unique",
 				Params: map[string]string{
-					"table": "ks.lkp",
-					"from":  "c1",
-					"to":    "col2",
+					"table":      "targetks.lkp",
+					"from":       "c1",
+					"to":         "c2",
+					"write_only": "true",
 				},
 				Owner: "t1",
 			},
@@ -1301,58 +387,27 @@ func TestStopAfterCopyFlag(t *testing.T) {
 		Tables: map[string]*vschemapb.Table{
 			"t1": {
 				ColumnVindexes: []*vschemapb.ColumnVindex{{
+					Name:   "hash",
+					Column: "col1",
+				}, {
 					Name:   "v",
 					Column: "col2",
 				}},
 			},
 		},
 	}
-	// Dummy sourceSchema
-	sourceSchema := "CREATE TABLE `t1` (\n" +
-		"  `col1` int(11) NOT NULL AUTO_INCREMENT,\n" +
-		"  `col2` int(11) DEFAULT NULL,\n" +
-		"  PRIMARY KEY (`id`)\n" +
-		") ENGINE=InnoDB AUTO_INCREMENT=3 DEFAULT CHARSET=latin1"
+	vschema, err := env.topoServ.GetVSchema(ctx, ms.SourceKeyspace)
+	require.NoError(t, err)
+	utils.MustMatch(t, wantvschema, vschema)
 
-	vschema := &vschemapb.Keyspace{
-		Sharded: true,
-		Vindexes: map[string]*vschemapb.Vindex{
-			"hash": {
-				Type: "hash",
-			},
-		},
+	wantvschema = &vschemapb.Keyspace{
 		Tables: map[string]*vschemapb.Table{
-			"t1": {
-				ColumnVindexes: []*vschemapb.ColumnVindex{{
-					Name:   "hash",
-					Column: "col1",
-				}},
-			},
+			"lkp": {},
 		},
 	}
-	env.tmc.schema[ms.SourceKeyspace+".t1"] = &tabletmanagerdatapb.SchemaDefinition{
-		TableDefinitions: []*tabletmanagerdatapb.TableDefinition{{
-			Fields: []*querypb.Field{{
-				Name: "col1",
-				Type: querypb.Type_INT64,
-			}, {
-				Name: "col2",
-				Type: querypb.Type_INT64,
-			}},
-			Schema: sourceSchema,
-		}},
-	}
-	if err := env.topoServ.SaveVSchema(context.Background(), ms.SourceKeyspace, vschema); err != nil {
-		t.Fatal(err)
-	}
-
-	ms1, _, _, err := env.wr.prepareCreateLookup(context.Background(), ms.SourceKeyspace, specs, false)
-	require.NoError(t, err)
-	require.Equal(t, ms1.StopAfterCopy, true)
-
-	ms2, _, _, err := env.wr.prepareCreateLookup(context.Background(), ms.SourceKeyspace, specs, true)
+	vschema, err = env.topoServ.GetVSchema(ctx, ms.TargetKeyspace)
 	require.NoError(t, err)
-	require.Equal(t, ms2.StopAfterCopy, false)
+	utils.MustMatch(t, wantvschema, vschema)
 }
 
 func TestCreateLookupVindexFailures(t *testing.T) {
@@ -2555,145 +1610,6 @@ func TestMaterializerNoVindexInExpression(t *testing.T) {
 	require.EqualError(t, err, "could not find vindex column c1")
 }
 
-func TestStripForeignKeys(t *testing.T) {
-	tcs := []struct {
-		desc string
-		ddl  string
-
-		hasErr bool
-		newDDL string
-	}{
-		{
-			desc: "has FK constraints",
-			ddl: "CREATE TABLE `table1` (\n" +
-				"`id` int(11) NOT NULL AUTO_INCREMENT,\n" +
-				"`foreign_id` int(11) CHECK (foreign_id>10),\n" +
-				"PRIMARY KEY (`id`),\n" +
-				"KEY `fk_table1_ref_foreign_id` (`foreign_id`),\n" +
-				"CONSTRAINT `fk_table1_ref_foreign_id` FOREIGN KEY (`foreign_id`) REFERENCES `foreign` (`id`)\n" +
-				") ENGINE=InnoDB DEFAULT CHARSET=latin1;",
-
-			newDDL: "create table table1 (\n" +
-				"\tid int(11) not null auto_increment,\n" +
-				"\tforeign_id int(11),\n" +
-				"\tPRIMARY KEY (id),\n" +
-				"\tKEY fk_table1_ref_foreign_id (foreign_id),\n" +
-				"\tcheck (foreign_id > 10)\n" +
-				") ENGINE InnoDB,\n" +
-				"  CHARSET latin1",
-
-			hasErr: false,
-		},
-		{
-			desc: "no FK constraints",
-			ddl: "CREATE TABLE `table1` (\n" +
-				"`id` int(11) NOT NULL AUTO_INCREMENT,\n" +
-				"`foreign_id` int(11) NOT NULL  CHECK (foreign_id>10),\n" +
-				"`user_id` int(11) NOT NULL,\n" +
-				"PRIMARY KEY (`id`),\n" +
-				"KEY `fk_table1_ref_foreign_id` (`foreign_id`),\n" +
-				"KEY `fk_table1_ref_user_id` (`user_id`)\n" +
-				") ENGINE=InnoDB DEFAULT CHARSET=latin1;",
-
-			newDDL: "create table table1 (\n" +
-				"\tid int(11) not null auto_increment,\n" +
-				"\tforeign_id int(11) not null,\n" +
-				"\tuser_id int(11) not null,\n" +
-				"\tPRIMARY KEY (id),\n" +
-				"\tKEY fk_table1_ref_foreign_id (foreign_id),\n" +
-				"\tKEY fk_table1_ref_user_id (user_id),\n" +
-				"\tcheck (foreign_id > 10)\n" +
-				") ENGINE InnoDB,\n" +
-				"  CHARSET latin1",
-		},
-	}
-
-	for _, tc := range tcs {
-		newDDL, err := stripTableForeignKeys(tc.ddl)
-		if tc.hasErr != (err != nil) {
-			t.Fatalf("hasErr does not match: err: %v, tc: %+v", err, tc)
-		}
-
-		if newDDL != tc.newDDL {
-			utils.MustMatch(t, tc.newDDL, newDDL, fmt.Sprintf("newDDL does not match. tc: %+v", tc))
-		}
-	}
-}
-
-func TestStripConstraints(t *testing.T) {
-	tcs := []struct {
-		desc string
-		ddl  string
-
-		hasErr bool
-		newDDL string
-	}{
-		{
-			desc: "constraints",
-			ddl: "CREATE TABLE `table1` (\n" +
-				"`id` int(11) NOT NULL AUTO_INCREMENT,\n" +
-				"`foreign_id` int(11) NOT NULL,\n" +
-				"`user_id` int(11) NOT NULL,\n" +
-				"PRIMARY KEY (`id`),\n" +
-				"KEY `fk_table1_ref_foreign_id` (`foreign_id`),\n" +
-				"KEY `fk_table1_ref_user_id` (`user_id`),\n" +
-				"CONSTRAINT `fk_table1_ref_foreign_id` FOREIGN KEY (`foreign_id`) REFERENCES `foreign` (`id`),\n" +
-				"CONSTRAINT `fk_table1_ref_user_id` FOREIGN KEY (`user_id`) REFERENCES `core_user` (`id`)\n" +
-				") ENGINE=InnoDB DEFAULT CHARSET=latin1;",
-
-			newDDL: "create table table1 (\n" +
-				"\tid int(11) not null auto_increment,\n" +
-				"\tforeign_id int(11) not null,\n" +
-				"\tuser_id int(11) not null,\n" +
-				"\tPRIMARY KEY (id),\n" +
-				"\tKEY fk_table1_ref_foreign_id (foreign_id),\n" +
-				"\tKEY fk_table1_ref_user_id (user_id)\n" +
-				") ENGINE InnoDB,\n" +
-				"  CHARSET latin1",
-
-			hasErr: false,
-		},
-		{
-			desc: "no constraints",
-			ddl: "CREATE TABLE `table1` (\n" +
-				"`id` int(11) NOT NULL AUTO_INCREMENT,\n" +
-				"`foreign_id` int(11) NOT NULL,\n" +
-				"`user_id` int(11) NOT NULL,\n" +
-				"PRIMARY KEY (`id`),\n" +
-				"KEY `fk_table1_ref_foreign_id` (`foreign_id`),\n" +
-				"KEY `fk_table1_ref_user_id` (`user_id`)\n" +
-				") ENGINE=InnoDB DEFAULT CHARSET=latin1;",
-
-			newDDL: "create table table1 (\n" +
-				"\tid int(11) not null auto_increment,\n" +
-				"\tforeign_id int(11) not null,\n" +
-				"\tuser_id int(11) not null,\n" +
-				"\tPRIMARY KEY (id),\n" +
-				"\tKEY fk_table1_ref_foreign_id (foreign_id),\n" +
-				"\tKEY fk_table1_ref_user_id (user_id)\n" +
-				") ENGINE InnoDB,\n" +
-				"  CHARSET latin1",
-		},
-		{
-			desc: "bad ddl has error",
-			ddl:  "bad ddl",
-
-			hasErr: true,
-		},
-	}
-
-	for _, tc := range tcs {
-		newDDL, err := stripTableConstraints(tc.ddl)
-		if tc.hasErr != (err != nil) {
-			t.Fatalf("hasErr does not match: err: %v, tc: %+v", err, tc)
-		}
-
-		if newDDL != tc.newDDL {
-			utils.MustMatch(t, tc.newDDL, newDDL, fmt.Sprintf("newDDL does not match. tc: %+v", tc))
-		}
-	}
-}
-
 func TestMaterializerManyToManySomeUnreachable(t *testing.T) {
 	ms := &vtctldatapb.MaterializeSettings{
 		Workflow:       "workflow",
@@ -2829,200 +1745,8 @@ func TestMoveTablesDDLFlag(t *testing.T) {
 			env.tmc.expectVRQuery(200, mzUpdateQuery, &sqltypes.Result{})
 
 			err := env.wr.MoveTables(ctx, "workflow", "sourceks", "targetks", "t1", "",
-				"", false, "", false, true, "", false, false, "", onDDLAction, nil)
-			require.NoError(t, err)
-		})
-	}
-}
-
-func TestAddTablesToVSchema(t *testing.T) {
-	ctx := context.Background()
-	ts := memorytopo.NewServer("zone1")
-	srcks := "source"
-	wr := &Wrangler{
-		logger:   logutil.NewMemoryLogger(),
-		ts:       ts,
-		sourceTs: ts,
-	}
-	tests := []struct {
-		name              string
-		sourceVSchema     *vschemapb.Keyspace
-		inTargetVSchema   *vschemapb.Keyspace
-		tables            []string
-		copyVSchema       bool
-		wantTargetVSchema *vschemapb.Keyspace
-	}{
-		{
-			name: "no target vschema; copy source vschema",
-			sourceVSchema: &vschemapb.Keyspace{
-				Tables: map[string]*vschemapb.Table{
-					"t1": {
-						Type: vindexes.TypeReference,
-					},
-					"t2": {
-						Type: vindexes.TypeSequence,
-					},
-					"t3": {
-						AutoIncrement: &vschemapb.AutoIncrement{
-							Column:   "c1",
-							Sequence: "t2",
-						},
-					},
-				},
-			},
-			inTargetVSchema: &vschemapb.Keyspace{},
-			tables:          []string{"t1", "t2", "t3", "t4"},
-			copyVSchema:     true,
-			wantTargetVSchema: &vschemapb.Keyspace{
-				Tables: map[string]*vschemapb.Table{
-					"t1": {
-						Type: vindexes.TypeReference,
-					},
-					"t2": {
-						Type: vindexes.TypeSequence,
-					},
-					"t3": {
-						AutoIncrement: &vschemapb.AutoIncrement{
-							Column:   "c1",
-							Sequence: "t2",
-						},
-					},
-					"t4": {},
-				},
-			},
-		},
-		{
-			name: "no target vschema; copy source vschema; sharded source",
-			sourceVSchema: &vschemapb.Keyspace{
-				Sharded: true,
-				Tables: map[string]*vschemapb.Table{
-					"t1": {
-						Type: vindexes.TypeReference,
-					},
-					"t2": {
-						Type: vindexes.TypeSequence,
-					},
-					"t3": {
-						AutoIncrement: &vschemapb.AutoIncrement{
-							Column:   "c1",
-							Sequence: "t2",
-						},
-					},
-					"t4": {
-						ColumnVindexes: []*vschemapb.ColumnVindex{ // Should be stripped on target
-							{
-								Column: "c1",
-								Name:   "hash",
-							},
-						},
-					},
-				},
-			},
-			inTargetVSchema: &vschemapb.Keyspace{},
-			tables:          []string{"t1", "t2", "t3", "t4"},
-			copyVSchema:     true,
-			wantTargetVSchema: &vschemapb.Keyspace{
-				Tables: map[string]*vschemapb.Table{
-					"t1": {
-						Type: vindexes.TypeReference,
-					},
-					"t2": {
-						Type: vindexes.TypeSequence,
-					},
-					"t3": {
-						AutoIncrement: &vschemapb.AutoIncrement{
-							Column:   "c1",
-							Sequence: "t2",
-						},
-					},
-					"t4": {},
-				},
-			},
-		},
-		{
-			name: "target vschema; copy source vschema",
-			sourceVSchema: &vschemapb.Keyspace{
-				Tables: map[string]*vschemapb.Table{
-					"t1": {
-						Type: vindexes.TypeReference,
-					},
-					"t2": {
-						Type: vindexes.TypeSequence,
-					},
-					"t3": {
-						AutoIncrement: &vschemapb.AutoIncrement{
-							Column:   "c1",
-							Sequence: "t2",
-						},
-					},
-					"t4": {
-						ColumnVindexes: []*vschemapb.ColumnVindex{ // Should be stripped on target
-							{
-								Column: "c1",
-								Name:   "hash",
-							},
-						},
-					},
-				},
-			},
-			inTargetVSchema: &vschemapb.Keyspace{
-				Tables: map[string]*vschemapb.Table{
-					"t1": {
-						Type: vindexes.TypeReference,
-					},
-					"t2": {},
-					"t3": {},
-					"t4": {},
-				},
-			},
-			tables:      []string{"t1", "t2", "t3", "t4"},
-			copyVSchema: true,
-			wantTargetVSchema: &vschemapb.Keyspace{
-				Tables: map[string]*vschemapb.Table{
-					"t1": {
-						Type: vindexes.TypeReference,
-					},
-					"t2": {},
-					"t3": {},
-					"t4": {},
-				},
-			},
-		},
-		{
-			name: "no target vschema; do not copy source vschema",
-			sourceVSchema: &vschemapb.Keyspace{
-				Tables: map[string]*vschemapb.Table{
-					"t1": {
-						Type: vindexes.TypeReference,
-					},
-					"t2": {
-						Type: vindexes.TypeSequence,
-					},
-					"t3": {
-						AutoIncrement: &vschemapb.AutoIncrement{
-							Column:   "c1",
-							Sequence: "t2",
-						},
-					},
-				},
-			},
-			inTargetVSchema: &vschemapb.Keyspace{},
-			tables:          []string{"t1", "t2"},
-			copyVSchema:     false,
-			wantTargetVSchema: &vschemapb.Keyspace{
-				Tables: map[string]*vschemapb.Table{
-					"t1": {},
-					"t2": {},
-				},
-			},
-		},
-	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			ts.SaveVSchema(ctx, srcks, tt.sourceVSchema)
-			err := wr.addTablesToVSchema(ctx, srcks, tt.inTargetVSchema, tt.tables, tt.copyVSchema)
+				"", false, "", false, true, "", false, false, "", onDDLAction, nil, "", "")
 			require.NoError(t, err)
-			require.Equal(t, tt.wantTargetVSchema, tt.inTargetVSchema)
 		})
 	}
 }
@@ -17,22 +17,16 @@ limitations under the License.
 package wrangler
 
 import (
-	"bytes"
 	"context"
 	"fmt"
 	"sync"
-	"text/template"
 	"time"
 
 	"vitess.io/vitess/go/vt/concurrency"
 	"vitess.io/vitess/go/vt/log"
-	"vitess.io/vitess/go/vt/logutil"
 	"vitess.io/vitess/go/vt/mysqlctl/tmutils"
-	"vitess.io/vitess/go/vt/schema"
-	"vitess.io/vitess/go/vt/topo"
 	"vitess.io/vitess/go/vt/topo/topoproto"
 	"vitess.io/vitess/go/vt/vtctl/schematools"
-	"vitess.io/vitess/go/vt/vttablet/tabletmanager/vreplication"
 
 	tabletmanagerdatapb "vitess.io/vitess/go/vt/proto/tabletmanagerdata"
 	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
@@ -132,50 +126,7 @@ func (wr *Wrangler) ValidateSchemaKeyspace(ctx context.Context, keyspace string,
 
 // ValidateVSchema compares the schema of each primary tablet in "keyspace/shards..." to the vschema and errs if there are differences
 func (wr *Wrangler) ValidateVSchema(ctx context.Context, keyspace string, shards []string, excludeTables []string, includeViews bool) error {
-	vschm, err := wr.ts.GetVSchema(ctx, keyspace)
-	if err != nil {
-		return fmt.Errorf("GetVSchema(%s) failed: %v", keyspace, err)
-	}
-
-	shardFailures := concurrency.AllErrorRecorder{}
-	var wg sync.WaitGroup
-	wg.Add(len(shards))
-
-	for _, shard := range shards {
-		go func(shard string) {
-			defer wg.Done()
-			notFoundTables := []string{}
-			si, err := wr.ts.GetShard(ctx, keyspace, shard)
-			if err != nil {
-				shardFailures.RecordError(fmt.Errorf("GetShard(%v, %v) failed: %v", keyspace, shard, err))
-				return
-			}
-			req := &tabletmanagerdatapb.GetSchemaRequest{ExcludeTables: excludeTables, IncludeViews: includeViews}
-			primarySchema, err := schematools.GetSchema(ctx, wr.ts, wr.tmc, si.PrimaryAlias, req)
-			if err != nil {
-				shardFailures.RecordError(fmt.Errorf("GetSchema(%s, nil, %v, %v) (%v/%v) failed: %v", si.PrimaryAlias.String(),
-					excludeTables, includeViews, keyspace, shard, err,
-				))
-				return
-			}
-			for _, tableDef := range primarySchema.TableDefinitions {
-				if _, ok := vschm.Tables[tableDef.Name]; !ok {
-					if !schema.IsInternalOperationTableName(tableDef.Name) {
-						notFoundTables = append(notFoundTables, tableDef.Name)
-					}
-				}
-			}
-			if len(notFoundTables) > 0 {
-				shardFailure := fmt.Errorf("%v/%v has tables that are not in the vschema: %v", keyspace, shard, notFoundTables)
-				shardFailures.RecordError(shardFailure)
-			}
-		}(shard)
-	}
-	wg.Wait()
-	if shardFailures.HasErrors() {
-		return fmt.Errorf("ValidateVSchema(%v, %v, %v, %v) failed: %v", keyspace, shards, excludeTables, includeViews, shardFailures.Error().Error())
-	}
-	return nil
+	return schematools.ValidateVSchema(ctx, wr.ts, wr.tmc, keyspace, shards, excludeTables, includeViews)
 }
 
 // PreflightSchema will try a schema change on the remote tablet.
@@ -206,115 +157,5 @@ func (wr *Wrangler) CopySchemaShardFromShard(ctx context.Context, tables, exclud
 // the destination shard, and is propagated to the replicas through
 // binlogs.
 func (wr *Wrangler) CopySchemaShard(ctx context.Context, sourceTabletAlias *topodatapb.TabletAlias, tables, excludeTables []string, includeViews bool, destKeyspace, destShard string, waitReplicasTimeout time.Duration, skipVerify bool) error {
-	destShardInfo, err := wr.ts.GetShard(ctx, destKeyspace, destShard)
-	if err != nil {
-		return fmt.Errorf("GetShard(%v, %v) failed: %v", destKeyspace, destShard, err)
-	}
-
-	if destShardInfo.PrimaryAlias == nil {
-		return fmt.Errorf("no primary in shard record %v/%v. Consider running 'vtctl InitShardPrimary' in case of a new shard or reparenting the shard to fix the topology data", destKeyspace, destShard)
-	}
-
-	diffs, err := schematools.CompareSchemas(ctx, wr.ts, wr.tmc, sourceTabletAlias, destShardInfo.PrimaryAlias, tables, excludeTables, includeViews)
-	if err != nil {
-		return fmt.Errorf("CopySchemaShard failed because schemas could not be compared initially: %v", err)
-	}
-	if diffs == nil {
-		// Return early because dest has already the same schema as source.
-		return nil
-	}
-
-	req := &tabletmanagerdatapb.GetSchemaRequest{Tables: tables, ExcludeTables: excludeTables, IncludeViews: includeViews}
-	sourceSd, err := schematools.GetSchema(ctx, wr.ts, wr.tmc, sourceTabletAlias, req)
-	if err != nil {
-		return fmt.Errorf("GetSchema(%v, %v, %v, %v) failed: %v", sourceTabletAlias, tables, excludeTables, includeViews, err)
-	}
-
-	createSQLstmts := tmutils.SchemaDefinitionToSQLStrings(sourceSd)
-
-	destTabletInfo, err := wr.ts.GetTablet(ctx, destShardInfo.PrimaryAlias)
-	if err != nil {
-		return fmt.Errorf("GetTablet(%v) failed: %v", destShardInfo.PrimaryAlias, err)
-	}
-	for _, createSQL := range createSQLstmts {
-		err = wr.applySQLShard(ctx, destTabletInfo, createSQL)
-		if err != nil {
-			return fmt.Errorf("creating a table failed."+
-				" Most likely some tables already exist on the destination and differ from the source."+
-				" Please remove all to be copied tables from the destination manually and run this command again."+
-				" Full error: %v", err)
-		}
-	}
-
-	// Remember the replication position after all the above were applied.
-	destPrimaryPos, err := wr.tmc.PrimaryPosition(ctx, destTabletInfo.Tablet)
-	if err != nil {
-		return fmt.Errorf("CopySchemaShard: can't get replication position after schema applied: %v", err)
-	}
-
-	// Although the copy was successful, we have to verify it to catch the case
-	// where the database already existed on the destination, but with different
-	// options e.g. a different character set.
-	// In that case, MySQL would have skipped our CREATE DATABASE IF NOT EXISTS
-	// statement.
-	if !skipVerify {
-		diffs, err = schematools.CompareSchemas(ctx, wr.ts, wr.tmc, sourceTabletAlias, destShardInfo.PrimaryAlias, tables, excludeTables, includeViews)
-		if err != nil {
-			return fmt.Errorf("CopySchemaShard failed because schemas could not be compared finally: %v", err)
-		}
-		if diffs != nil {
-			return fmt.Errorf("CopySchemaShard was not successful because the schemas between the two tablets %v and %v differ: %v", sourceTabletAlias, destShardInfo.PrimaryAlias, diffs)
-		}
-	}
-
-	// Notify Replicass to reload schema. This is best-effort.
-	reloadCtx, cancel := context.WithTimeout(ctx, waitReplicasTimeout)
-	defer cancel()
-	resp, err := wr.VtctldServer().ReloadSchemaShard(reloadCtx, &vtctldatapb.ReloadSchemaShardRequest{
-		Keyspace:       destKeyspace,
-		Shard:          destShard,
-		WaitPosition:   destPrimaryPos,
-		Concurrency:    10,
-		IncludePrimary: true,
-	})
-	if resp != nil {
-		for _, e := range resp.Events {
-			logutil.LogEvent(wr.Logger(), e)
-		}
-	}
-	return err
-}
-
-// applySQLShard applies a given SQL change on a given tablet alias. It allows executing arbitrary
-// SQL statements, but doesn't return any results, so it's only useful for SQL statements
-// that would be run for their effects (e.g., CREATE).
-// It works by applying the SQL statement on the shard's primary tablet with replication turned on.
-// Thus it should be used only for changes that can be applied on a live instance without causing issues;
-// it shouldn't be used for anything that will require a pivot.
-// The SQL statement string is expected to have {{.DatabaseName}} in place of the actual db name.
-func (wr *Wrangler) applySQLShard(ctx context.Context, tabletInfo *topo.TabletInfo, change string) error {
-	filledChange, err := fillStringTemplate(change, map[string]string{"DatabaseName": tabletInfo.DbName()})
-	if err != nil {
-		return fmt.Errorf("fillStringTemplate failed: %v", err)
-	}
-	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
-	defer cancel()
-	// Need to make sure that replication is enabled since we're only applying the statement on primaries
-	_, err = wr.tmc.ApplySchema(ctx, tabletInfo.Tablet, &tmutils.SchemaChange{
-		SQL:              filledChange,
-		Force:            false,
-		AllowReplication: true,
-		SQLMode:          vreplication.SQLMode,
-	})
-	return err
-}
-
-// fillStringTemplate returns the string template filled
-func fillStringTemplate(tmpl string, vars any) (string, error) {
-	myTemplate := template.Must(template.New("").Parse(tmpl))
-	data := new(bytes.Buffer)
-	if err := myTemplate.Execute(data, vars); err != nil {
-		return "", err
-	}
-	return data.String(), nil
+	return schematools.CopySchemaShard(ctx, wr.ts, wr.tmc, wr.Logger(), sourceTabletAlias, tables, excludeTables, includeViews, destKeyspace, destShard, waitReplicasTimeout, skipVerify)
 }
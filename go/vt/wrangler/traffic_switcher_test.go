@@ -939,8 +939,8 @@ func testTableMigrateOneToMany(t *testing.T, keepData, keepRoutingRules bool) {
 		tme.dbTargetClients[0].addQuery("select 1 from _vt.vreplication where db_name='vt_ks2' and workflow='test' and message!='FROZEN'", &sqltypes.Result{}, nil)
 		tme.dbTargetClients[1].addQuery("select 1 from _vt.vreplication where db_name='vt_ks2' and workflow='test' and message!='FROZEN'", &sqltypes.Result{}, nil)
 		tme.dbSourceClients[0].addQuery("select id from _vt.vreplication where db_name = 'vt_ks1' and workflow = 'test_reverse'", &sqltypes.Result{}, nil)
-		tme.tmeDB.AddQuery(fmt.Sprintf("rename table `vt_ks1`.`t1` TO `vt_ks1`.`%s`", getRenameFileName("t1")), &sqltypes.Result{})
-		tme.tmeDB.AddQuery(fmt.Sprintf("rename table `vt_ks1`.`t2` TO `vt_ks1`.`%s`", getRenameFileName("t2")), &sqltypes.Result{})
+		tme.tmeDB.AddQuery("rename table `vt_ks1`.`t1` TO `vt_ks1`.`_t1_old`", &sqltypes.Result{})
+		tme.tmeDB.AddQuery("rename table `vt_ks1`.`t2` TO `vt_ks1`.`_t2_old`", &sqltypes.Result{})
 		tme.dbTargetClients[0].addQuery("select id from _vt.vreplication where db_name = 'vt_ks2' and workflow = 'test'", &sqltypes.Result{}, nil) //
 		tme.dbTargetClients[1].addQuery("select id from _vt.vreplication where db_name = 'vt_ks2' and workflow = 'test'", &sqltypes.Result{}, nil)
 	}
@@ -1748,51 +1748,6 @@ func TestMigrateNoTableWildcards(t *testing.T) {
 	}
 }
 
-func TestReverseVReplicationUpdateQuery(t *testing.T) {
-	ts := &trafficSwitcher{
-		reverseWorkflow: "wf",
-	}
-	dbname := "db"
-	type tCase struct {
-		optCells       string
-		optTabletTypes string
-		targetCell     string
-		sourceCell     string
-		want           string
-	}
-	updateQuery := "update _vt.vreplication set cell = '%s', tablet_types = '%s' where workflow = 'wf' and db_name = 'db'"
-	tCases := []tCase{
-		{
-			targetCell: "cell1", sourceCell: "cell1", optCells: "cell1", optTabletTypes: "",
-			want: fmt.Sprintf(updateQuery, "cell1", ""),
-		},
-		{
-			targetCell: "cell1", sourceCell: "cell2", optCells: "cell1", optTabletTypes: "",
-			want: fmt.Sprintf(updateQuery, "cell2", ""),
-		},
-		{
-			targetCell: "cell1", sourceCell: "cell2", optCells: "cell2", optTabletTypes: "",
-			want: fmt.Sprintf(updateQuery, "cell2", ""),
-		},
-		{
-			targetCell: "cell1", sourceCell: "cell1", optCells: "cell1,cell2", optTabletTypes: "replica,primary",
-			want: fmt.Sprintf(updateQuery, "cell1,cell2", "replica,primary"),
-		},
-		{
-			targetCell: "cell1", sourceCell: "cell1", optCells: "", optTabletTypes: "replica,primary",
-			want: fmt.Sprintf(updateQuery, "", "replica,primary"),
-		},
-	}
-	for _, tc := range tCases {
-		t.Run("", func(t *testing.T) {
-			ts.optCells = tc.optCells
-			ts.optTabletTypes = tc.optTabletTypes
-			got := ts.getReverseVReplicationUpdateQuery(tc.targetCell, tc.sourceCell, dbname)
-			require.Equal(t, tc.want, got)
-		})
-	}
-}
-
 func TestShardMigrateNoAvailableTabletsForReverseReplication(t *testing.T) {
 	ctx := context.Background()
 	tme := newTestShardMigrater(ctx, t, []string{"-40", "40-"}, []string{"-80", "80-"})
@@ -2093,56 +2048,6 @@ func TestShardMigrateNoAvailableTabletsForReverseReplication(t *testing.T) {
 	verifyQueries(t, tme.allDBClients)
 }
 
-func TestIsPartialMoveTables(t *testing.T) {
-	ts := &trafficSwitcher{}
-	type testCase struct {
-		name                       string
-		sourceShards, targetShards []string
-		want                       bool
-	}
-	testCases := []testCase{
-		{
-			name:         "-80",
-			sourceShards: []string{"-80"},
-			targetShards: []string{"-80"},
-			want:         true,
-		},
-		{
-			name:         "80-",
-			sourceShards: []string{"80-"},
-			targetShards: []string{"80-"},
-			want:         true,
-		},
-		{
-			name:         "-80,80-",
-			sourceShards: []string{"-80", "80-"},
-			targetShards: []string{"-80", "80-"},
-			want:         false,
-		},
-		{
-			name:         "mismatch",
-			sourceShards: []string{"-c0", "c0-"},
-			targetShards: []string{"-80", "80-"},
-			want:         false,
-		},
-		{
-			name:         "different number of shards",
-			sourceShards: []string{"-a0", "a0-c0", "c0-"},
-			targetShards: []string{"-80", "80-"},
-			want:         false,
-		},
-	}
-
-	for _, tc := range testCases {
-		t.Run(tc.name, func(t *testing.T) {
-			got, err := ts.isPartialMoveTables(tc.sourceShards, tc.targetShards)
-			require.NoError(t, err)
-			require.Equal(t, tc.want, got)
-		})
-
-	}
-}
-
 func checkRouting(t *testing.T, wr *Wrangler, want map[string][]string) {
 	t.Helper()
 	ctx := context.Background()
@@ -90,7 +90,7 @@ type RowDiff struct {
 
 // vdiff contains the metadata for performing vdiff for one workflow.
 type vdiff struct {
-	ts             *trafficSwitcher
+	ts             *workflow.TrafficSwitcher
 	sourceCell     string
 	targetCell     string
 	tabletTypesStr string
@@ -190,11 +190,11 @@ func (wr *Wrangler) VDiff(ctx context.Context, targetKeyspace, workflowName, sou
 		wr.Logger().Errorf("buildTrafficSwitcher: %v", err)
 		return nil, err
 	}
-	if ts.frozen {
+	if ts.IsFrozen() {
 		return nil, fmt.Errorf("invalid VDiff run: writes have been already been switched for workflow %s.%s",
 			targetKeyspace, workflowName)
 	}
-	if err := ts.validate(ctx); err != nil {
+	if err := ts.Validate(ctx); err != nil {
 		ts.Logger().Errorf("validate: %v", err)
 		return nil, err
 	}
@@ -214,8 +214,8 @@ func (wr *Wrangler) VDiff(ctx context.Context, targetKeyspace, workflowName, sou
 		workflow:       workflowName,
 		targetKeyspace: targetKeyspace,
 		tables:         includeTables,
-		sourceTimeZone: ts.sourceTimeZone,
-		targetTimeZone: ts.targetTimeZone,
+		sourceTimeZone: ts.SourceTimeZone(),
+		targetTimeZone: ts.TargetTimeZone(),
 	}
 	for shard, source := range ts.Sources() {
 		df.sources[shard] = &shardStreamer{
@@ -796,7 +796,7 @@ func newMergeSorter(participants map[string]*shardStreamer, comparePKs []compare
 }
 
 // selectTablets selects the tablets that will be used for the diff.
-func (df *vdiff) selectTablets(ctx context.Context, ts *trafficSwitcher) error {
+func (df *vdiff) selectTablets(ctx context.Context, ts *workflow.TrafficSwitcher) error {
 	var wg sync.WaitGroup
 	var err1, err2 error
 
@@ -226,8 +226,9 @@ func (vx *vexec) exec() (map[*topo.TabletInfo]*querypb.QueryResult, error) {
 				// If we deleted a workflow then let's make a best effort attempt to clean
 				// up any related data.
 				if vx.query == sqlVReplicationDelete {
-					vx.wr.deleteWorkflowVDiffData(ctx, primary.Tablet, vx.workflow)
-					vx.wr.optimizeCopyStateTable(primary.Tablet)
+					ws := vx.wr.workflowServer()
+					ws.DeleteWorkflowVDiffData(ctx, primary.Tablet, vx.workflow)
+					ws.OptimizeCopyStateTable(primary.Tablet)
 				}
 				mu.Lock()
 				results[primary] = qr
@@ -412,8 +413,12 @@ func (wr *Wrangler) execWorkflowAction(ctx context.Context, workflow, keyspace,
 				changes = true
 				dryRunChanges.WriteString(fmt.Sprintf("  on_ddl=%q\n", binlogdatapb.OnDDLAction_name[int32(rpcReq.OnDdl)]))
 			}
+			if rpcReq.Schedule != nil {
+				changes = true
+				dryRunChanges.WriteString(fmt.Sprintf("  schedule=%q\n", prototext.Format(rpcReq.Schedule)))
+			}
 			if !changes {
-				return nil, fmt.Errorf("no updates were provided; use --cells, --tablet-types, or --on-ddl to specify new values")
+				return nil, fmt.Errorf("no updates were provided; use --cells, --tablet-types, --on-ddl, or --schedule to specify new values")
 			}
 			wr.Logger().Printf("The following workflow fields will be updated:\n%s", dryRunChanges.String())
 			wr.Logger().Printf("On the following tablets in the %s keyspace for workflow %s:\n",
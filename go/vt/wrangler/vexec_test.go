@@ -482,7 +482,7 @@ func TestWorkflowUpdate(t *testing.T) {
 			cells:       nullSlice,
 			tabletTypes: nullSlice,
 			onDDL:       nullOnDDL,
-			wantErr:     "no updates were provided; use --cells, --tablet-types, or --on-ddl to specify new values",
+			wantErr:     "no updates were provided; use --cells, --tablet-types, --on-ddl, or --schedule to specify new values",
 		},
 		{
 			name:        "only cells",
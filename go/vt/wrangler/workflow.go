@@ -8,7 +8,6 @@ import (
 	"sync"
 	"time"
 
-	"vitess.io/vitess/go/mysql"
 	"vitess.io/vitess/go/sqltypes"
 	"vitess.io/vitess/go/vt/discovery"
 	"vitess.io/vitess/go/vt/log"
@@ -69,6 +68,9 @@ type VReplicationWorkflowParams struct {
 	// MoveTables/Migrate and Reshard specific
 	DeferSecondaryKeys bool
 
+	// MoveTables specific, moves the rows of a single tenant
+	TenantColumn, TenantValue string
+
 	// Migrate specific
 	ExternalCluster string
 }
@@ -79,7 +81,7 @@ type VReplicationWorkflow struct {
 	ctx          context.Context
 	wr           *Wrangler
 	params       *VReplicationWorkflowParams
-	ts           *trafficSwitcher
+	ts           *workflow.TrafficSwitcher
 	ws           *workflow.State
 }
 
@@ -103,9 +105,9 @@ func (wr *Wrangler) NewVReplicationWorkflow(ctx context.Context, workflowType VR
 	}
 	log.Infof("Workflow state is %+v", ws)
 	if ts != nil { //Other than on create we need to get SourceKeyspace from the workflow
-		vrw.params.TargetKeyspace = ts.targetKeyspace
-		vrw.params.Workflow = ts.workflow
-		vrw.params.SourceKeyspace = ts.sourceKeyspace
+		vrw.params.TargetKeyspace = ts.TargetKeyspaceName()
+		vrw.params.Workflow = ts.WorkflowName()
+		vrw.params.SourceKeyspace = ts.SourceKeyspaceName()
 	}
 	vrw.ts = ts
 	vrw.ws = ws
@@ -148,7 +150,15 @@ func (vrw *VReplicationWorkflow) stateAsString(ws *workflow.State) string {
 	if !vrw.Exists() {
 		stateInfo = append(stateInfo, WorkflowStateNotCreated)
 	} else {
-		if !ws.IsPartialMigration { // shard level traffic switching is all or nothing
+		if ws.IsTenantMigration {
+			// The reads and writes of the tenant are switched together by
+			// the tenant routing rule.
+			if ws.WritesSwitched {
+				stateInfo = append(stateInfo, "All Reads Switched")
+			} else {
+				stateInfo = append(stateInfo, "Reads Not Switched")
+			}
+		} else if !ws.IsPartialMigration { // shard level traffic switching is all or nothing
 			if len(ws.RdonlyCellsNotSwitched) == 0 && len(ws.ReplicaCellsNotSwitched) == 0 && len(ws.ReplicaCellsSwitched) > 0 {
 				s = "All Reads Switched"
 			} else if len(ws.RdonlyCellsSwitched) == 0 && len(ws.ReplicaCellsSwitched) == 0 {
@@ -433,7 +443,8 @@ func (vrw *VReplicationWorkflow) initMoveTables() error {
 	return vrw.wr.MoveTables(vrw.ctx, vrw.params.Workflow, vrw.params.SourceKeyspace, vrw.params.TargetKeyspace,
 		vrw.params.Tables, vrw.params.Cells, vrw.params.TabletTypes, vrw.params.AllTables, vrw.params.ExcludeTables,
 		vrw.params.AutoStart, vrw.params.StopAfterCopy, vrw.params.ExternalCluster, vrw.params.DropForeignKeys,
-		vrw.params.DeferSecondaryKeys, vrw.params.SourceTimeZone, vrw.params.OnDDL, vrw.params.SourceShards)
+		vrw.params.DeferSecondaryKeys, vrw.params.SourceTimeZone, vrw.params.OnDDL, vrw.params.SourceShards,
+		vrw.params.TenantColumn, vrw.params.TenantValue)
 }
 
 func (vrw *VReplicationWorkflow) initReshard() error {
@@ -576,7 +587,7 @@ func (vrw *VReplicationWorkflow) GetCopyProgress() (*CopyProgress, error) {
 	tables := make(map[string]bool)
 	const MaxRows = 1000
 	sourcePrimaries := make(map[*topodatapb.TabletAlias]bool)
-	for _, target := range vrw.ts.targets {
+	for _, target := range vrw.ts.Targets() {
 		for id, bls := range target.Sources {
 			query := fmt.Sprintf(getTablesQuery, id)
 			p3qr, err := vrw.wr.tmc.ExecuteFetchAsDba(ctx, target.GetPrimary().Tablet, true, &tabletmanagerdatapb.ExecuteFetchAsDbaRequest{
@@ -650,7 +661,7 @@ func (vrw *VReplicationWorkflow) GetCopyProgress() (*CopyProgress, error) {
 		return nil
 	}
 	sourceDbName := ""
-	for _, tsSource := range vrw.ts.sources {
+	for _, tsSource := range vrw.ts.Sources() {
 		sourceDbName = tsSource.GetPrimary().DbName()
 		break
 	}
@@ -658,7 +669,7 @@ func (vrw *VReplicationWorkflow) GetCopyProgress() (*CopyProgress, error) {
 		return nil, fmt.Errorf("no sources found for workflow %s.%s", vrw.ws.TargetKeyspace, vrw.ws.Workflow)
 	}
 	targetDbName := ""
-	for _, tsTarget := range vrw.ts.targets {
+	for _, tsTarget := range vrw.ts.Targets() {
 		targetDbName = tsTarget.GetPrimary().DbName()
 		break
 	}
@@ -668,7 +679,7 @@ func (vrw *VReplicationWorkflow) GetCopyProgress() (*CopyProgress, error) {
 	sort.Strings(tableList) // sort list for repeatability for mocking in tests
 	tablesStr := strings.Join(tableList, ",")
 	query := fmt.Sprintf(getRowCountQuery, encodeString(targetDbName), tablesStr)
-	for _, target := range vrw.ts.targets {
+	for _, target := range vrw.ts.Targets() {
 		tablet := target.GetPrimary().Tablet
 		if err := getTableMetrics(tablet, query, &targetRowCounts, &targetTableSizes); err != nil {
 			return nil, err
@@ -700,75 +711,3 @@ func (vrw *VReplicationWorkflow) GetCopyProgress() (*CopyProgress, error) {
 }
 
 // endregion
-
-// region Workflow related utility functions
-
-// deleteWorkflowVDiffData cleans up any potential VDiff related data associated with the workflow on the given tablet
-func (wr *Wrangler) deleteWorkflowVDiffData(ctx context.Context, tablet *topodatapb.Tablet, workflow string) {
-	sqlDeleteVDiffs := `delete from vd, vdt, vdl using _vt.vdiff as vd inner join _vt.vdiff_table as vdt on (vd.id = vdt.vdiff_id)
-						inner join _vt.vdiff_log as vdl on (vd.id = vdl.vdiff_id)
-						where vd.keyspace = %s and vd.workflow = %s`
-	query := fmt.Sprintf(sqlDeleteVDiffs, encodeString(tablet.Keyspace), encodeString(workflow))
-	rows := -1
-	if _, err := wr.tmc.ExecuteFetchAsDba(ctx, tablet, false, &tabletmanagerdatapb.ExecuteFetchAsDbaRequest{
-		Query:   []byte(query),
-		MaxRows: uint64(rows),
-	}); err != nil {
-		if sqlErr, ok := err.(*mysql.SQLError); ok && sqlErr.Num != mysql.ERNoSuchTable { // the tables may not exist if no vdiffs have been run
-			wr.Logger().Errorf("Error deleting vdiff data for %s.%s workflow: %v", tablet.Keyspace, workflow, err)
-		}
-	}
-}
-
-// optimizeCopyStateTable rebuilds the copy_state table to ensure the on-disk
-// structures are minimal and optimized and resets the auto-inc value for
-// subsequent inserts.
-// This helps to ensure that the size, storage, and performance related factors
-// for the table remain optimal over time and that we don't ever exhaust the
-// available auto-inc values for the table.
-// Note: it's not critical that this executes successfully any given time, it's
-// only important that we try to do this periodically so that things stay in an
-// optimal state over long periods of time. For this reason, the work is done
-// asynchronously in the background on the given tablet and any failures are
-// logged as warnings. Because it's done in the background we use the AllPrivs
-// account to be sure that we don't execute the writes if READ_ONLY is set on
-// the MySQL instance.
-func (wr *Wrangler) optimizeCopyStateTable(tablet *topodatapb.Tablet) {
-	if wr.sem != nil {
-		if !wr.sem.TryAcquire(1) {
-			log.Warningf("Deferring work to optimize the copy_state table on %q due to hitting the maximum concurrent background job limit.",
-				tablet.Alias.String())
-			return
-		}
-	}
-	go func() {
-		defer func() {
-			if wr.sem != nil {
-				wr.sem.Release(1)
-			}
-		}()
-		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Minute)
-		defer cancel()
-		sqlOptimizeTable := "optimize table _vt.copy_state"
-		if _, err := wr.tmc.ExecuteFetchAsAllPrivs(ctx, tablet, &tabletmanagerdatapb.ExecuteFetchAsAllPrivsRequest{
-			Query:   []byte(sqlOptimizeTable),
-			MaxRows: uint64(100), // always produces 1+rows with notes and status
-		}); err != nil {
-			if sqlErr, ok := err.(*mysql.SQLError); ok && sqlErr.Num == mysql.ERNoSuchTable { // the table may not exist
-				return
-			}
-			log.Warningf("Failed to optimize the copy_state table on %q: %v", tablet.Alias.String(), err)
-		}
-		// This will automatically set the value to 1 or the current max value in the table, whichever is greater
-		sqlResetAutoInc := "alter table _vt.copy_state auto_increment = 1"
-		if _, err := wr.tmc.ExecuteFetchAsAllPrivs(ctx, tablet, &tabletmanagerdatapb.ExecuteFetchAsAllPrivsRequest{
-			Query:   []byte(sqlResetAutoInc),
-			MaxRows: uint64(0),
-		}); err != nil {
-			log.Warningf("Failed to reset the auto_increment value for the copy_state table on %q: %v",
-				tablet.Alias.String(), err)
-		}
-	}()
-}
-
-// endregion
@@ -27,6 +27,7 @@ import (
 	"vitess.io/vitess/go/vt/logutil"
 	"vitess.io/vitess/go/vt/topo"
 	"vitess.io/vitess/go/vt/vtctl/grpcvtctldserver"
+	"vitess.io/vitess/go/vt/vtctl/workflow"
 	"vitess.io/vitess/go/vt/vttablet/tmclient"
 
 	vtctlservicepb "vitess.io/vitess/go/vt/proto/vtctlservice"
@@ -47,11 +48,10 @@ var (
 // Multiple go routines can use the same Wrangler at the same time,
 // provided they want to share the same logger / topo server / lock timeout.
 type Wrangler struct {
-	logger   logutil.Logger
-	ts       *topo.Server
-	tmc      tmclient.TabletManagerClient
-	vtctld   vtctlservicepb.VtctldServer
-	sourceTs *topo.Server
+	logger logutil.Logger
+	ts     *topo.Server
+	tmc    tmclient.TabletManagerClient
+	vtctld vtctlservicepb.VtctldServer
 	// VExecFunc is a test-only fixture that allows us to short circuit vexec commands.
 	// DO NOT USE in production code.
 	VExecFunc func(ctx context.Context, workflow, keyspace, query string, dryRun bool) (map[*topo.TabletInfo]*sqltypes.Result, error)
@@ -62,11 +62,10 @@ type Wrangler struct {
 // New creates a new Wrangler object.
 func New(logger logutil.Logger, ts *topo.Server, tmc tmclient.TabletManagerClient) *Wrangler {
 	return &Wrangler{
-		logger:   logger,
-		ts:       ts,
-		tmc:      tmc,
-		vtctld:   grpcvtctldserver.NewVtctldServer(ts),
-		sourceTs: ts,
+		logger: logger,
+		ts:     ts,
+		tmc:    tmc,
+		vtctld: grpcvtctldserver.NewVtctldServer(ts),
 	}
 }
 
@@ -74,11 +73,10 @@ func New(logger logutil.Logger, ts *topo.Server, tmc tmclient.TabletManagerClien
 // in production.
 func NewTestWrangler(logger logutil.Logger, ts *topo.Server, tmc tmclient.TabletManagerClient) *Wrangler {
 	return &Wrangler{
-		logger:   logger,
-		ts:       ts,
-		tmc:      tmc,
-		vtctld:   grpcvtctldserver.NewTestVtctldServer(ts, tmc),
-		sourceTs: ts,
+		logger: logger,
+		ts:     ts,
+		tmc:    tmc,
+		vtctld: grpcvtctldserver.NewTestVtctldServer(ts, tmc),
 	}
 }
 
@@ -109,3 +107,13 @@ func (wr *Wrangler) SetLogger(logger logutil.Logger) {
 func (wr *Wrangler) Logger() logutil.Logger {
 	return wr.logger
 }
+
+// workflowServer returns a workflow.Server that shares the topo server,
+// tablet manager client and logger of this wrangler. The vreplication
+// workflows are implemented there.
+func (wr *Wrangler) workflowServer() *workflow.Server {
+	s := workflow.NewServer(wr.ts, wr.tmc)
+	s.SetLogger(wr.logger)
+	s.SetSemaphore(wr.sem)
+	return s
+}